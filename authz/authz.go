@@ -81,11 +81,21 @@ p, built-in, *, *, *, *, *
 p, app, *, *, *, *, *
 p, *, *, POST, /api/signup, *, *
 p, *, *, GET, /api/get-email-and-phone, *, *
+p, *, *, GET, /api/get-user-avatar, *, *
 p, *, *, POST, /api/login, *, *
+p, *, *, POST, /api/certificate-login, *, *
 p, *, *, GET, /api/get-app-login, *, *
+p, *, *, GET, /api/get-error-codes, *, *
 p, *, *, POST, /api/logout, *, *
 p, *, *, GET, /api/logout, *, *
 p, *, *, GET, /api/get-account, *, *
+p, *, *, GET, /api/get-security-activity, *, *
+p, *, *, POST, /api/report-security-event, *, *
+p, *, *, GET, /api/get-login-alerts, *, *
+p, *, *, POST, /api/confirm-login-alert, *, *
+p, *, *, GET, /api/health/live, *, *
+p, *, *, GET, /api/health/ready, *, *
+p, *, *, GET, /api/get-maintenance-notice, *, *
 p, *, *, GET, /api/userinfo, *, *
 p, *, *, GET, /api/user, *, *
 p, *, *, POST, /api/webhook, *, *
@@ -95,20 +105,53 @@ p, *, *, GET, /api/get-application, *, *
 p, *, *, GET, /api/get-organization-applications, *, *
 p, *, *, GET, /api/get-user, *, *
 p, *, *, GET, /api/get-user-application, *, *
+p, *, *, GET, /api/check-application-sso, *, *
+p, *, *, *, /api/forward-auth, *, *
+p, *, *, GET, /api/forward-auth/callback, *, *
 p, *, *, GET, /api/get-resources, *, *
 p, *, *, GET, /api/get-records, *, *
+p, *, *, GET, /api/export-records, *, *
 p, *, *, GET, /api/get-product, *, *
 p, *, *, POST, /api/buy-product, *, *
 p, *, *, GET, /api/get-payment, *, *
 p, *, *, POST, /api/update-payment, *, *
 p, *, *, POST, /api/invoice-payment, *, *
 p, *, *, POST, /api/notify-payment, *, *
+p, *, *, GET, /api/get-plans, *, *
+p, *, *, GET, /api/get-plan, *, *
+p, *, *, POST, /api/subscribe, *, *
+p, *, *, GET, /api/get-user-subscriptions, *, *
+p, *, *, GET, /api/get-subscription, *, *
+p, *, *, GET, /api/get-subscription-proration, *, *
+p, *, *, POST, /api/change-subscription-plan, *, *
+p, *, *, POST, /api/cancel-subscription, *, *
+p, *, *, GET, /api/get-organization-usage, *, *
+p, *, *, GET, /api/get-usages, *, *
+p, *, *, GET, /api/get-login-stats, *, *
+p, *, *, GET, /api/export-login-stats, *, *
+p, *, *, GET, /api/get-token-stats, *, *
+p, *, *, GET, /api/get-token-anomalies, *, *
+p, *, *, POST, /api/review-token-anomaly, *, *
+p, *, *, POST, /api/subscription-webhook, *, *
+p, *, *, GET, /api/get-user-identity-verifications, *, *
+p, *, *, POST, /api/start-identity-verification, *, *
+p, *, *, POST, /api/identity-verification-webhook, *, *
+p, *, *, POST, /api/email-webhook, *, *
+p, *, *, POST, /api/issue-ssh-certificate, *, *
 p, *, *, POST, /api/unlink, *, *
 p, *, *, POST, /api/set-password, *, *
 p, *, *, POST, /api/send-verification-code, *, *
 p, *, *, GET, /api/get-captcha, *, *
 p, *, *, POST, /api/verify-captcha, *, *
 p, *, *, POST, /api/reset-email-or-phone, *, *
+p, *, *, POST, /api/verify-account-recovery, *, *
+p, *, *, POST, /api/reset-password-by-recovery, *, *
+p, *, *, POST, /api/set-security-answers, *, *
+p, *, *, POST, /api/create-account-recovery-link, *, *
+p, *, *, GET, /api/get-account-recoveries, *, *
+p, *, *, POST, /api/confirm-contact-change, *, *
+p, *, *, POST, /api/undo-contact-change, *, *
+p, *, *, GET, /api/get-contact-changes, *, *
 p, *, *, POST, /api/upload-resource, *, *
 p, *, *, GET, /.well-known/openid-configuration, *, *
 p, *, *, *, /.well-known/jwks, *, *
@@ -119,6 +162,23 @@ p, *, *, *, /cas, *, *
 p, *, *, *, /api/webauthn, *, *
 p, *, *, GET, /api/get-release, *, *
 p, *, *, GET, /api/get-default-application, *, *
+p, *, *, GET, /api/get-branding, *, *
+p, *, *, GET, /api/get-email-domain-application, *, *
+p, *, *, GET, /api/get-model-templates, *, *
+p, *, *, GET, /api/get-user-access-requests, *, *
+p, *, *, POST, /api/add-access-request, *, *
+p, *, *, POST, /api/approve-access-request, *, *
+p, *, *, POST, /api/deny-access-request, *, *
+p, *, *, GET, /api/get-user-access-review-items, *, *
+p, *, *, POST, /api/decide-access-review-item, *, *
+p, *, *, GET, /api/get-user-api-keys, *, *
+p, *, *, POST, /api/add-api-key, *, *
+p, *, *, POST, /api/revoke-api-key, *, *
+p, *, *, POST, /api/delete-api-key, *, *
+p, *, *, GET, /api/get-capabilities, *, *
+p, *, *, GET, /api/get-user-organizations, *, *
+p, *, *, POST, /api/set-active-organization, *, *
+p, *, *, *, /api/graphql, *, *
 `
 
 		sa := stringadapter.NewAdapter(ruleText)
@@ -151,11 +211,21 @@ func IsAllowed(subOwner string, subName string, method string, urlPath string, o
 		return true
 	}
 
+	// the admins of a parent organization manage its descendants
+	if user != nil && user.IsAdmin && (object.IsOrganizationAncestor(subOwner, objOwner) || (objOwner == "admin" && object.IsOrganizationAncestor(subOwner, objName))) {
+		return true
+	}
+
 	res, err := Enforcer.Enforce(subOwner, subName, method, urlPath, objOwner, objName)
 	if err != nil {
 		panic(err)
 	}
 
+	// operators can be granted single capabilities instead of being admins
+	if !res && user != nil {
+		res = object.HasEndpointCapability(userId, urlPath, objOwner, objName)
+	}
+
 	return res
 }
 
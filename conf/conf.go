@@ -19,8 +19,10 @@ import (
 	"fmt"
 	"os"
 	"runtime"
+	"sort"
 	"strconv"
 	"strings"
+	"sync"
 
 	"github.com/beego/beego"
 )
@@ -34,6 +36,38 @@ type Quota struct {
 
 var quota = &Quota{-1, -1, -1, -1}
 
+// RuntimeSettingTypes lists the keys of app.conf that can be changed at
+// runtime and the type of their values: "string", "bool", "int" or "json".
+// Only the keys read on every use are listed, the database, Redis, ports and
+// the like need a restart. SMTP isn't in app.conf, the email providers can
+// already be changed at runtime.
+var RuntimeSettingTypes = map[string]string{
+	"origin":                    "string",
+	"allowedOrigins":            "string",
+	"staticBaseUrl":             "string",
+	"authState":                 "string",
+	"languages":                 "string",
+	"socks5Proxy":               "string",
+	"geoHeaders":                "string",
+	"isDemoMode":                "bool",
+	"isCloudIntranet":           "bool",
+	"logPostOnly":               "bool",
+	"disableClientSecretAccess": "bool",
+	"batchSize":                 "int",
+	"initScore":                 "int",
+	"verificationCodeTimeout":   "int",
+	"sessionExpireInHours":      "int",
+	"quota":                     "json",
+	"retention":                 "json",
+	"maintenanceMode":           "bool",
+	"maintenanceNotice":         "string",
+}
+
+var (
+	runtimeSettings     = map[string]string{}
+	runtimeSettingsLock sync.RWMutex
+)
+
 func init() {
 	// this array contains the beego configuration items that may be modified via env
 	presetConfigItems := []string{"httpport", "appname"}
@@ -58,7 +92,43 @@ func initQuota() {
 	}
 }
 
+// SetRuntimeSettings replaces the values set at runtime, they take
+// precedence over the environment and app.conf.
+func SetRuntimeSettings(settings map[string]string) {
+	newQuota := &Quota{-1, -1, -1, -1}
+	res := settings["quota"]
+	if res == "" {
+		res = beego.AppConfig.String("quota")
+	}
+	if res != "" {
+		_ = json.Unmarshal([]byte(res), newQuota)
+	}
+
+	runtimeSettingsLock.Lock()
+	defer runtimeSettingsLock.Unlock()
+
+	runtimeSettings = settings
+	quota = newQuota
+}
+
+// GetStaticConfigString returns the value of the key without the runtime
+// settings, from the environment or app.conf.
+func GetStaticConfigString(key string) string {
+	if value, ok := os.LookupEnv(key); ok {
+		return value
+	}
+
+	return beego.AppConfig.String(key)
+}
+
 func GetConfigString(key string) string {
+	runtimeSettingsLock.RLock()
+	value, ok := runtimeSettings[key]
+	runtimeSettingsLock.RUnlock()
+	if ok {
+		return value
+	}
+
 	if value, ok := os.LookupEnv(key); ok {
 		return value
 	}
@@ -105,27 +175,94 @@ func GetConfigDataSourceName() string {
 	return dataSourceName
 }
 
+// GetLanguage returns the language negotiated from an Accept-Language
+// header, "en" when none of its languages is supported.
 func GetLanguage(language string) string {
-	if language == "" || language == "*" {
-		return "en"
+	if res := GetSupportedLanguage(language); res != "" {
+		return res
+	}
+	return "en"
+}
+
+// GetSupportedLanguage returns the supported language with the highest
+// quality in an Accept-Language header, or "" when there is none. Only the
+// primary subtags are compared, "fr-CH" matches "fr".
+func GetSupportedLanguage(acceptLanguage string) string {
+	supported := strings.Split(GetConfigString("languages"), ",")
+	for _, language := range ParseAcceptLanguage(acceptLanguage) {
+		for _, item := range supported {
+			if strings.TrimSpace(item) == language {
+				return language
+			}
+		}
 	}
+	return ""
+}
 
-	if len(language) < 2 {
-		return "en"
+// ParseAcceptLanguage returns the primary subtags of an Accept-Language
+// header ordered by quality, languages with a quality of 0 are left out.
+func ParseAcceptLanguage(acceptLanguage string) []string {
+	type weightedLanguage struct {
+		language string
+		quality  float64
 	}
 
-	language = language[0:2]
-	if strings.Contains(GetConfigString("languages"), language) {
-		return language
-	} else {
-		return "en"
+	weightedLanguages := []weightedLanguage{}
+	for _, part := range strings.Split(acceptLanguage, ",") {
+		params := strings.Split(strings.TrimSpace(part), ";")
+		language := strings.ToLower(strings.TrimSpace(params[0]))
+		if index := strings.Index(language, "-"); index >= 0 {
+			language = language[:index]
+		}
+		if language == "" || language == "*" {
+			continue
+		}
+
+		quality := 1.0
+		for _, param := range params[1:] {
+			param = strings.TrimSpace(param)
+			if strings.HasPrefix(param, "q=") {
+				if value, err := strconv.ParseFloat(param[2:], 64); err == nil {
+					quality = value
+				}
+			}
+		}
+		if quality <= 0 {
+			continue
+		}
+		weightedLanguages = append(weightedLanguages, weightedLanguage{language, quality})
+	}
+
+	sort.SliceStable(weightedLanguages, func(i, j int) bool {
+		return weightedLanguages[i].quality > weightedLanguages[j].quality
+	})
+
+	res := []string{}
+	for _, weightedLanguage := range weightedLanguages {
+		if !containsString(res, weightedLanguage.language) {
+			res = append(res, weightedLanguage.language)
+		}
 	}
+	return res
+}
+
+func containsString(values []string, value string) bool {
+	for _, item := range values {
+		if item == value {
+			return true
+		}
+	}
+	return false
 }
 
 func IsDemoMode() bool {
 	return strings.ToLower(GetConfigString("isDemoMode")) == "true"
 }
 
+func IsMaintenanceMode() bool {
+	return strings.ToLower(GetConfigString("maintenanceMode")) == "true"
+}
+
 func GetConfigBatchSize() int {
 	res, err := strconv.Atoi(GetConfigString("batchSize"))
 	if err != nil {
@@ -134,7 +271,20 @@ func GetConfigBatchSize() int {
 	return res
 }
 
+// GetConfigSessionExpireInHours returns how long a login without auto
+// sign-in lasts, 24 hours by default.
+func GetConfigSessionExpireInHours() int64 {
+	res, err := strconv.ParseInt(GetConfigString("sessionExpireInHours"), 10, 64)
+	if err != nil || res <= 0 {
+		res = 24
+	}
+	return res
+}
+
 func GetConfigQuota() *Quota {
+	runtimeSettingsLock.RLock()
+	defer runtimeSettingsLock.RUnlock()
+
 	return quota
 }
 
@@ -109,3 +109,66 @@ func TestGetConfigQuota(t *testing.T) {
 		assert.Equal(t, scenery.expected, quota)
 	}
 }
+
+func TestRuntimeSettings(t *testing.T) {
+	scenarios := []struct {
+		description string
+		input       string
+		expected    interface{}
+	}{
+		{"Should be return the runtime value", "batchSize", "50"},
+		{"Should be return the runtime value over env", "isDemoMode", "true"},
+		{"Should be return the app.conf value", "dbName", "casdoor"},
+	}
+
+	os.Setenv("isDemoMode", "false")
+
+	err := beego.LoadAppConfig("ini", "app.conf")
+	assert.Nil(t, err)
+
+	SetRuntimeSettings(map[string]string{"batchSize": "50", "isDemoMode": "true", "quota": `{"user": 10}`})
+	defer SetRuntimeSettings(map[string]string{})
+
+	for _, scenery := range scenarios {
+		t.Run(scenery.description, func(t *testing.T) {
+			actual := GetConfigString(scenery.input)
+			assert.Equal(t, scenery.expected, actual)
+		})
+	}
+
+	assert.Equal(t, int64(24), GetConfigSessionExpireInHours())
+	SetRuntimeSettings(map[string]string{"sessionExpireInHours": "2"})
+	assert.Equal(t, int64(2), GetConfigSessionExpireInHours())
+	SetRuntimeSettings(map[string]string{"batchSize": "50", "isDemoMode": "true", "quota": `{"user": 10}`})
+
+	assert.Equal(t, 10, GetConfigQuota().User)
+	assert.Equal(t, -1, GetConfigQuota().Organization)
+	assert.Equal(t, "false", GetStaticConfigString("isDemoMode"))
+}
+
+func TestGetLanguage(t *testing.T) {
+	scenarios := []struct {
+		description string
+		input       string
+		expected    string
+	}{
+		{"Should be return en for an empty header", "", "en"},
+		{"Should be return en for any language", "*", "en"},
+		{"Should be return the primary subtag", "fr-CH", "fr"},
+		{"Should be return the highest quality", "en;q=0.5,de-DE;q=0.8,ja;q=0.7", "de"},
+		{"Should be skip unsupported languages", "pt-BR,pt;q=0.9,ko;q=0.8", "ko"},
+		{"Should be skip languages of quality 0", "zh;q=0,es", "es"},
+		{"Should be return en when nothing is supported", "pt-BR", "en"},
+	}
+
+	err := beego.LoadAppConfig("ini", "app.conf")
+	assert.Nil(t, err)
+
+	for _, scenery := range scenarios {
+		t.Run(scenery.description, func(t *testing.T) {
+			assert.Equal(t, scenery.expected, GetLanguage(scenery.input))
+		})
+	}
+
+	assert.Equal(t, "", GetSupportedLanguage("pt-BR"))
+}
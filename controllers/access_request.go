@@ -0,0 +1,163 @@
+// Copyright 2023 The Casdoor Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers
+
+import (
+	"encoding/json"
+
+	"github.com/beego/beego/utils/pagination"
+	"github.com/casdoor/casdoor/object"
+	"github.com/casdoor/casdoor/util"
+)
+
+type AccessRequestDecision struct {
+	Owner   string `json:"owner"`
+	Name    string `json:"name"`
+	Comment string `json:"comment"`
+}
+
+// GetAccessRequests
+// @Title GetAccessRequests
+// @Tag Access Request API
+// @Description get the access requests of an organization
+// @Param   owner     query    string  true        "The owner of access requests"
+// @Success 200 {array} object.AccessRequest The Response object
+// @router /get-access-requests [get]
+func (c *ApiController) GetAccessRequests() {
+	owner := c.Input().Get("owner")
+	limit := c.Input().Get("pageSize")
+	page := c.Input().Get("p")
+	field := c.Input().Get("field")
+	value := c.Input().Get("value")
+	sortField := c.Input().Get("sortField")
+	sortOrder := c.Input().Get("sortOrder")
+	if limit == "" || page == "" {
+		c.Data["json"] = object.GetAccessRequests(owner)
+		c.ServeJSON()
+	} else {
+		limit := util.ParseInt(limit)
+		paginator := pagination.SetPaginator(c.Ctx, limit, int64(object.GetAccessRequestCount(owner, field, value)))
+		accessRequests := object.GetPaginationAccessRequests(owner, paginator.Offset(), limit, field, value, sortField, sortOrder)
+		c.ResponseOk(accessRequests, paginator.Nums())
+	}
+}
+
+// GetAccessRequest
+// @Title GetAccessRequest
+// @Tag Access Request API
+// @Description get access request
+// @Param   id     query    string  true        "The id ( owner/name ) of the access request"
+// @Success 200 {object} object.AccessRequest The Response object
+// @router /get-access-request [get]
+func (c *ApiController) GetAccessRequest() {
+	id := c.Input().Get("id")
+
+	c.Data["json"] = object.GetAccessRequest(id)
+	c.ServeJSON()
+}
+
+// GetUserAccessRequests
+// @Title GetUserAccessRequests
+// @Tag Access Request API
+// @Description get the access requests made by the signed-in user and the ones waiting for the user's decision
+// @Success 200 {array} object.AccessRequest The Response object
+// @router /get-user-access-requests [get]
+func (c *ApiController) GetUserAccessRequests() {
+	userId, ok := c.RequireSignedIn()
+	if !ok {
+		return
+	}
+
+	c.ResponseOk(object.GetUserAccessRequests(userId))
+}
+
+// AddAccessRequest
+// @Title AddAccessRequest
+// @Tag Access Request API
+// @Description request a role or a permission (e.g. the access to an application) for the signed-in user
+// @Param   body    body   object.AccessRequest  true        "The owner, type (Role or Permission), target, reason and durationHours of the request"
+// @Success 200 {object} controllers.Response The Response object
+// @router /add-access-request [post]
+func (c *ApiController) AddAccessRequest() {
+	userId, ok := c.RequireSignedIn()
+	if !ok {
+		return
+	}
+
+	var accessRequest object.AccessRequest
+	err := json.Unmarshal(c.Ctx.Input.RequestBody, &accessRequest)
+	if err != nil {
+		c.ResponseError(err.Error())
+		return
+	}
+
+	// admins can request on behalf of a user of their organization
+	if accessRequest.Requester == "" || !c.IsAdminOf(accessRequest.Owner) {
+		accessRequest.Requester = userId
+	}
+
+	msg := object.AddAccessRequest(&accessRequest, c.GetAcceptLanguage())
+	if msg != "" {
+		c.ResponseError(msg)
+		return
+	}
+
+	c.ResponseOk(accessRequest.GetId())
+}
+
+// ApproveAccessRequest
+// @Title ApproveAccessRequest
+// @Tag Access Request API
+// @Description approve a pending access request, the role or the permission is granted right away
+// @Param   body    body   controllers.AccessRequestDecision  true        "The owner and name of the request with an optional comment"
+// @Success 200 {object} controllers.Response The Response object
+// @router /approve-access-request [post]
+func (c *ApiController) ApproveAccessRequest() {
+	c.decideAccessRequest(true)
+}
+
+// DenyAccessRequest
+// @Title DenyAccessRequest
+// @Tag Access Request API
+// @Description deny a pending access request
+// @Param   body    body   controllers.AccessRequestDecision  true        "The owner and name of the request with an optional comment"
+// @Success 200 {object} controllers.Response The Response object
+// @router /deny-access-request [post]
+func (c *ApiController) DenyAccessRequest() {
+	c.decideAccessRequest(false)
+}
+
+func (c *ApiController) decideAccessRequest(approve bool) {
+	userId, ok := c.RequireSignedIn()
+	if !ok {
+		return
+	}
+
+	var decision AccessRequestDecision
+	err := json.Unmarshal(c.Ctx.Input.RequestBody, &decision)
+	if err != nil {
+		c.ResponseError(err.Error())
+		return
+	}
+
+	id := util.GetId(decision.Owner, decision.Name)
+	msg := object.DecideAccessRequest(id, userId, c.IsAdminOf(decision.Owner), approve, decision.Comment, c.GetAcceptLanguage())
+	if msg != "" {
+		c.ResponseError(msg)
+		return
+	}
+
+	c.ResponseOk()
+}
@@ -0,0 +1,253 @@
+// Copyright 2023 The Casdoor Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/beego/beego/utils/pagination"
+	"github.com/casdoor/casdoor/object"
+	"github.com/casdoor/casdoor/util"
+)
+
+type AccessReviewDecision struct {
+	Owner    string `json:"owner"`
+	Name     string `json:"name"`
+	Decision string `json:"decision"`
+	Comment  string `json:"comment"`
+}
+
+// GetAccessReviews
+// @Title GetAccessReviews
+// @Tag Access Review API
+// @Description get the access review campaigns of an organization
+// @Param   owner     query    string  true        "The owner of access reviews"
+// @Success 200 {array} object.AccessReview The Response object
+// @router /get-access-reviews [get]
+func (c *ApiController) GetAccessReviews() {
+	owner := c.Input().Get("owner")
+	limit := c.Input().Get("pageSize")
+	page := c.Input().Get("p")
+	field := c.Input().Get("field")
+	value := c.Input().Get("value")
+	sortField := c.Input().Get("sortField")
+	sortOrder := c.Input().Get("sortOrder")
+	if limit == "" || page == "" {
+		c.Data["json"] = object.GetAccessReviews(owner)
+		c.ServeJSON()
+	} else {
+		limit := util.ParseInt(limit)
+		paginator := pagination.SetPaginator(c.Ctx, limit, int64(object.GetAccessReviewCount(owner, field, value)))
+		accessReviews := object.GetPaginationAccessReviews(owner, paginator.Offset(), limit, field, value, sortField, sortOrder)
+		c.ResponseOk(accessReviews, paginator.Nums())
+	}
+}
+
+// GetAccessReview
+// @Title GetAccessReview
+// @Tag Access Review API
+// @Description get access review
+// @Param   id     query    string  true        "The id ( owner/name ) of the access review"
+// @Success 200 {object} object.AccessReview The Response object
+// @router /get-access-review [get]
+func (c *ApiController) GetAccessReview() {
+	id := c.Input().Get("id")
+
+	c.Data["json"] = object.GetAccessReview(id)
+	c.ServeJSON()
+}
+
+// UpdateAccessReview
+// @Title UpdateAccessReview
+// @Tag Access Review API
+// @Description update access review
+// @Param   id     query    string  true        "The id ( owner/name ) of the access review"
+// @Param   body    body   object.AccessReview  true        "The details of the access review"
+// @Success 200 {object} controllers.Response The Response object
+// @router /update-access-review [post]
+func (c *ApiController) UpdateAccessReview() {
+	id := c.Input().Get("id")
+
+	var accessReview object.AccessReview
+	err := json.Unmarshal(c.Ctx.Input.RequestBody, &accessReview)
+	if err != nil {
+		c.ResponseError(err.Error())
+		return
+	}
+
+	msg := object.CheckAccessReview(&accessReview, c.GetAcceptLanguage())
+	if msg != "" {
+		c.ResponseError(msg)
+		return
+	}
+
+	c.Data["json"] = wrapActionResponse(object.UpdateAccessReview(id, &accessReview))
+	c.ServeJSON()
+}
+
+// AddAccessReview
+// @Title AddAccessReview
+// @Tag Access Review API
+// @Description schedule an access review campaign
+// @Param   body    body   object.AccessReview  true        "The details of the access review"
+// @Success 200 {object} controllers.Response The Response object
+// @router /add-access-review [post]
+func (c *ApiController) AddAccessReview() {
+	var accessReview object.AccessReview
+	err := json.Unmarshal(c.Ctx.Input.RequestBody, &accessReview)
+	if err != nil {
+		c.ResponseError(err.Error())
+		return
+	}
+
+	msg := object.CheckAccessReview(&accessReview, c.GetAcceptLanguage())
+	if msg != "" {
+		c.ResponseError(msg)
+		return
+	}
+
+	c.Data["json"] = wrapActionResponse(object.AddAccessReview(&accessReview))
+	c.ServeJSON()
+}
+
+// DeleteAccessReview
+// @Title DeleteAccessReview
+// @Tag Access Review API
+// @Description delete access review with its items
+// @Param   body    body   object.AccessReview  true        "The details of the access review"
+// @Success 200 {object} controllers.Response The Response object
+// @router /delete-access-review [post]
+func (c *ApiController) DeleteAccessReview() {
+	var accessReview object.AccessReview
+	err := json.Unmarshal(c.Ctx.Input.RequestBody, &accessReview)
+	if err != nil {
+		c.ResponseError(err.Error())
+		return
+	}
+
+	c.Data["json"] = wrapActionResponse(object.DeleteAccessReview(&accessReview))
+	c.ServeJSON()
+}
+
+// CompleteAccessReview
+// @Title CompleteAccessReview
+// @Tag Access Review API
+// @Description complete an active campaign before its end time, the decisions are applied right away
+// @Param   body    body   object.AccessReview  true        "The owner and name of the access review"
+// @Success 200 {object} controllers.Response The Response object
+// @router /complete-access-review [post]
+func (c *ApiController) CompleteAccessReview() {
+	var body object.AccessReview
+	err := json.Unmarshal(c.Ctx.Input.RequestBody, &body)
+	if err != nil {
+		c.ResponseError(err.Error())
+		return
+	}
+
+	accessReview := object.GetAccessReview(util.GetId(body.Owner, body.Name))
+	if accessReview == nil || accessReview.State != object.AccessReviewStateActive {
+		c.ResponseError(c.T("access:The access review is not active"))
+		return
+	}
+
+	object.CompleteAccessReview(accessReview)
+	c.ResponseOk()
+}
+
+// GetAccessReviewItems
+// @Title GetAccessReviewItems
+// @Tag Access Review API
+// @Description get the items of an access review
+// @Param   id     query    string  true        "The id ( owner/name ) of the access review"
+// @Success 200 {array} object.AccessReviewItem The Response object
+// @router /get-access-review-items [get]
+func (c *ApiController) GetAccessReviewItems() {
+	id := c.Input().Get("id")
+	owner, name := util.GetOwnerAndNameFromId(id)
+
+	c.ResponseOk(object.GetAccessReviewItems(owner, name))
+}
+
+// GetUserAccessReviewItems
+// @Title GetUserAccessReviewItems
+// @Tag Access Review API
+// @Description get the undecided items of the active campaigns reviewed by the signed-in user
+// @Success 200 {array} object.AccessReviewItem The Response object
+// @router /get-user-access-review-items [get]
+func (c *ApiController) GetUserAccessReviewItems() {
+	userId, ok := c.RequireSignedIn()
+	if !ok {
+		return
+	}
+
+	c.ResponseOk(object.GetUserAccessReviewItems(userId))
+}
+
+// DecideAccessReviewItem
+// @Title DecideAccessReviewItem
+// @Tag Access Review API
+// @Description keep or revoke the membership of an item, the decision is applied when the campaign completes
+// @Param   body    body   controllers.AccessReviewDecision  true        "The owner and name of the item, the decision (Keep or Revoke) and an optional comment"
+// @Success 200 {object} controllers.Response The Response object
+// @router /decide-access-review-item [post]
+func (c *ApiController) DecideAccessReviewItem() {
+	userId, ok := c.RequireSignedIn()
+	if !ok {
+		return
+	}
+
+	var decision AccessReviewDecision
+	err := json.Unmarshal(c.Ctx.Input.RequestBody, &decision)
+	if err != nil {
+		c.ResponseError(err.Error())
+		return
+	}
+
+	id := util.GetId(decision.Owner, decision.Name)
+	msg := object.DecideAccessReviewItem(id, userId, c.IsAdminOf(decision.Owner), decision.Decision, decision.Comment, c.GetAcceptLanguage())
+	if msg != "" {
+		c.ResponseError(msg)
+		return
+	}
+
+	c.ResponseOk()
+}
+
+// ExportAccessReview
+// @Title ExportAccessReview
+// @Tag Access Review API
+// @Description export the items and decisions of an access review as CSV
+// @Param   id     query    string  true        "The id ( owner/name ) of the access review"
+// @Success 200 {string} string "The CSV file"
+// @router /export-access-review [get]
+func (c *ApiController) ExportAccessReview() {
+	id := c.Input().Get("id")
+	accessReview := object.GetAccessReview(id)
+	if accessReview == nil {
+		c.ResponseError(fmt.Sprintf(c.T("access:The access review: %s does not exist"), id))
+		return
+	}
+
+	data, err := object.ExportAccessReview(accessReview)
+	if err != nil {
+		c.ResponseError(err.Error())
+		return
+	}
+
+	c.Ctx.Output.Header("Content-Type", "text/csv; charset=utf-8")
+	c.Ctx.Output.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%s.csv", accessReview.Name))
+	c.Ctx.Output.Body(data)
+}
@@ -70,15 +70,20 @@ type RequestForm struct {
 	CaptchaType  string `json:"captchaType"`
 	CaptchaToken string `json:"captchaToken"`
 	ClientSecret string `json:"clientSecret"`
+
+	ActiveOrganization string `json:"activeOrganization"`
+	GuestToken         string `json:"guestToken"`
 }
 
 type Response struct {
-	Status string      `json:"status"`
-	Msg    string      `json:"msg"`
-	Sub    string      `json:"sub"`
-	Name   string      `json:"name"`
-	Data   interface{} `json:"data"`
-	Data2  interface{} `json:"data2"`
+	Status  string      `json:"status"`
+	Msg     string      `json:"msg"`
+	Code    string      `json:"code,omitempty"`
+	HelpUrl string      `json:"helpUrl,omitempty"`
+	Sub     string      `json:"sub"`
+	Name    string      `json:"name"`
+	Data    interface{} `json:"data"`
+	Data2   interface{} `json:"data2"`
 }
 
 type Captcha struct {
@@ -97,7 +102,7 @@ type Captcha struct {
 // Signup
 // @Tag Login API
 // @Title Signup
-// @Description sign up a new user
+// @Description sign up a new user, data2 is the URL of the identity verification when the application requires it on signup
 // @Param   username     formData    string  true        "The username to sign up"
 // @Param   password     formData    string  true        "The password"
 // @Success 200 {object} controllers.Response The Response object
@@ -114,6 +119,7 @@ func (c *ApiController) Signup() {
 		c.ResponseError(err.Error())
 		return
 	}
+	defer c.addLoginStat(&form, getLoginStatProvider(&form), true)
 
 	application := object.GetApplication(fmt.Sprintf("admin/%s", form.Application))
 	if !application.EnableSignUp {
@@ -128,6 +134,16 @@ func (c *ApiController) Signup() {
 		return
 	}
 
+	// a guest signs up with its access token to keep its identity
+	var guest *object.User
+	if form.GuestToken != "" {
+		guest = object.GetGuestUserByAccessToken(form.GuestToken, form.Organization)
+		if guest == nil {
+			c.ResponseError(c.T("account:The guest token is invalid or expired"))
+			return
+		}
+	}
+
 	if application.IsSignupItemVisible("Email") && application.GetSignupItemRule("Email") != "No verification" && form.Email != "" {
 		checkResult := object.CheckVerificationCode(form.Email, form.EmailCode, c.GetAcceptLanguage())
 		if checkResult.Code != object.VerificationSuccess {
@@ -146,6 +162,14 @@ func (c *ApiController) Signup() {
 		}
 	}
 
+	// a verified email of a domain claimed by an organization joins that organization
+	if guest == nil && application.IsSignupItemVisible("Email") && application.GetSignupItemRule("Email") != "No verification" && form.Email != "" {
+		if autoJoinOrganization := object.GetAutoJoinOrganization(form.Organization, form.Email); autoJoinOrganization != nil {
+			organization = autoJoinOrganization
+			form.Organization = autoJoinOrganization.Name
+		}
+	}
+
 	id := util.GenerateId()
 	if application.GetSignupItemRule("ID") == "Incremental" {
 		lastUser := object.GetLastUser(form.Organization)
@@ -157,6 +181,9 @@ func (c *ApiController) Signup() {
 
 		id = strconv.Itoa(lastIdInt + 1)
 	}
+	if guest != nil {
+		id = guest.Id
+	}
 
 	username := form.Username
 	if !application.IsSignupItemVisible("Username") {
@@ -210,19 +237,37 @@ func (c *ApiController) Signup() {
 		}
 	}
 
-	affected := object.AddUser(user)
+	if !c.checkSeatLimit(user.Owner, object.SeatResourceUser, 1) {
+		return
+	}
+
+	hookResult := object.RunLoginHooks(c.getLoginHookContext(object.LoginHookEventPreSignup, application, user))
+	if hookResult.Action != object.LoginHookActionAllow {
+		c.ResponseError(hookResult.Message)
+		return
+	}
+	hookResult.ApplyClaimsToUser(user)
+
+	var affected bool
+	if guest != nil {
+		affected = object.PromoteGuestUser(guest, user)
+	} else {
+		affected = object.AddUser(user)
+	}
 	if !affected {
 		c.ResponseError(c.T("account:Failed to add user"), util.StructToJson(user))
 		return
 	}
 
-	object.AddUserToOriginalDatabase(user)
+	object.EnqueueSignupTasks(application, user)
 
 	if application.HasPromptPage() {
 		// The prompt page needs the user to be signed in
 		c.SetSessionUsername(user.GetId())
 	}
 
+	verificationUrl := object.StartSignupIdentityVerification(application, user, c.Ctx.Request.Host)
+
 	object.DisableVerificationCode(form.Email)
 	object.DisableVerificationCode(checkPhone)
 
@@ -234,7 +279,7 @@ func (c *ApiController) Signup() {
 	userId := user.GetId()
 	util.LogInfo(c.Ctx, "API: [%s] is signed up as new user", userId)
 
-	c.ResponseOk(userId)
+	c.ResponseOk(userId, verificationUrl)
 }
 
 // Logout
@@ -295,7 +340,7 @@ func (c *ApiController) Logout() {
 			return
 		}
 
-		if application.IsRedirectUriValid(redirectUri) {
+		if reason := application.CheckRedirectUri(redirectUri); reason == "" {
 			if user == "" {
 				user = util.GetId(token.Organization, token.User)
 			}
@@ -309,7 +354,7 @@ func (c *ApiController) Logout() {
 
 			c.Ctx.Redirect(http.StatusFound, fmt.Sprintf("%s?state=%s", strings.TrimRight(redirectUri, "/"), state))
 		} else {
-			c.ResponseError(fmt.Sprintf(c.T("token:Redirect URI: %s doesn't exist in the allowed Redirect URI list"), redirectUri))
+			c.ResponseError(fmt.Sprintf(c.T("token:Redirect URI: %s is rejected: %s"), redirectUri, object.TranslateRedirectUriReason(c.GetAcceptLanguage(), reason)))
 			return
 		}
 	}
@@ -332,6 +377,7 @@ func (c *ApiController) GetAccount() {
 		user = object.ExtendManagedAccountsWithUser(user)
 	}
 
+	user = object.ApplyMembership(user, c.GetSessionActiveOrganization())
 	object.ExtendUserWithRolesAndPermissions(user)
 
 	user.Permissions = object.GetMaskedPermissions(user.Permissions)
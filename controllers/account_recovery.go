@@ -0,0 +1,179 @@
+// Copyright 2023 The Casdoor Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/casdoor/casdoor/object"
+	"github.com/casdoor/casdoor/util"
+)
+
+type AccountRecoveryForm struct {
+	Organization string            `json:"organization"`
+	Username     string            `json:"username"`
+	Method       string            `json:"method"`
+	Code         string            `json:"code"`
+	Answers      map[string]string `json:"answers"`
+}
+
+// addAccountRecoveryRecord records a recovery step of the user, the request
+// filter leaves these requests out as they are made before signing in.
+func (c *ApiController) addAccountRecoveryRecord(user *object.User) {
+	record := object.NewRecord(c.Ctx)
+	record.Organization = user.Owner
+	record.User = user.Name
+	util.SafeGoroutine(func() { object.AddRecord(record) })
+}
+
+// VerifyAccountRecovery
+// @Title VerifyAccountRecovery
+// @Tag Account API
+// @Description verify the code sent by email or SMS or the answers to the security questions of a user who forgot the password
+// @Param   body    body   controllers.AccountRecoveryForm  true        "The user, the method and the code or the answers"
+// @Success 200 {object} controllers.Response The Response object, data is the recovery token
+// @router /verify-account-recovery [post]
+func (c *ApiController) VerifyAccountRecovery() {
+	var form AccountRecoveryForm
+	err := json.Unmarshal(c.Ctx.Input.RequestBody, &form)
+	if err != nil {
+		c.ResponseError(err.Error())
+		return
+	}
+
+	user := object.GetUserByFields(form.Organization, form.Username)
+	if user == nil {
+		c.ResponseError(fmt.Sprintf(c.T("general:The user: %s doesn't exist"), util.GetId(form.Organization, form.Username)))
+		return
+	}
+	if user.IsForbidden {
+		c.ResponseError(c.T("check:The user is forbidden to sign in, please contact the administrator"))
+		return
+	}
+
+	token, err := object.VerifyAccountRecovery(user, form.Method, form.Code, form.Answers, util.GetIPFromRequest(c.Ctx.Request), c.GetAcceptLanguage())
+	c.addAccountRecoveryRecord(user)
+	if err != nil {
+		c.ResponseError(err.Error())
+		return
+	}
+
+	c.ResponseOk(token)
+}
+
+// ResetPasswordByRecovery
+// @Title ResetPasswordByRecovery
+// @Tag Account API
+// @Description set a new password with a recovery token, the token can only be used once
+// @Param   recoveryToken   formData    string  true        "The recovery token"
+// @Param   newPassword     formData    string  true        "The new password of the user"
+// @Success 200 {object} controllers.Response The Response object
+// @router /reset-password-by-recovery [post]
+func (c *ApiController) ResetPasswordByRecovery() {
+	recoveryToken := c.Ctx.Request.Form.Get("recoveryToken")
+	newPassword := c.Ctx.Request.Form.Get("newPassword")
+
+	if msg := c.checkNewPassword(newPassword); msg != "" {
+		c.ResponseError(msg)
+		return
+	}
+
+	user, err := object.ResetPasswordByRecovery(recoveryToken, newPassword, c.GetAcceptLanguage())
+	if err != nil {
+		c.ResponseError(err.Error())
+		return
+	}
+
+	c.addAccountRecoveryRecord(user)
+	c.ResponseOk()
+}
+
+// SetSecurityAnswers
+// @Title SetSecurityAnswers
+// @Tag Account API
+// @Description set the answers of the signed-in user to the security questions of the organization
+// @Param   body    body   map[string]string  true        "The answers by question"
+// @Success 200 {object} controllers.Response The Response object
+// @router /set-security-answers [post]
+func (c *ApiController) SetSecurityAnswers() {
+	user, ok := c.RequireSignedInUser()
+	if !ok {
+		return
+	}
+
+	var answers map[string]string
+	err := json.Unmarshal(c.Ctx.Input.RequestBody, &answers)
+	if err != nil {
+		c.ResponseError(err.Error())
+		return
+	}
+
+	err = object.SetSecurityAnswers(user, answers, c.GetAcceptLanguage())
+	if err != nil {
+		c.ResponseError(err.Error())
+		return
+	}
+
+	c.ResponseOk()
+}
+
+// CreateAccountRecoveryLink
+// @Title CreateAccountRecoveryLink
+// @Tag User API
+// @Description issue a reset link that expires for a user of an organization the signed-in user is an admin of
+// @Param   id     query    string  true        "The id ( owner/name ) of the user"
+// @Success 200 {object} controllers.Response The Response object, data is the link and data2 its expire time
+// @router /create-account-recovery-link [post]
+func (c *ApiController) CreateAccountRecoveryLink() {
+	id := c.Input().Get("id")
+
+	user := object.GetUser(id)
+	if user == nil {
+		c.ResponseError(fmt.Sprintf(c.T("general:The user: %s doesn't exist"), id))
+		return
+	}
+	if !c.isOrganizationAdmin(user.Owner) || c.GetSessionUsername() == id {
+		c.ResponseError(c.T("auth:Unauthorized operation"))
+		return
+	}
+
+	link, expireTime, err := object.CreateAccountRecoveryLink(user, c.GetSessionUsername(), util.GetIPFromRequest(c.Ctx.Request), c.Ctx.Request.Host)
+	if err != nil {
+		c.ResponseError(err.Error())
+		return
+	}
+
+	c.ResponseOk(link, expireTime)
+}
+
+// GetAccountRecoveries
+// @Title GetAccountRecoveries
+// @Tag User API
+// @Description get the recovery attempts and reset links of a user
+// @Param   id     query    string  true        "The id ( owner/name ) of the user"
+// @Success 200 {array} object.AccountRecovery The Response object
+// @router /get-account-recoveries [get]
+func (c *ApiController) GetAccountRecoveries() {
+	id := c.Input().Get("id")
+
+	owner, name := util.GetOwnerAndNameFromIdNoCheck(id)
+	if !c.isOrganizationAdmin(owner) {
+		c.ResponseError(c.T("auth:Unauthorized operation"))
+		return
+	}
+
+	c.ResponseOk(object.GetAccountRecoveries(owner, name))
+}
@@ -0,0 +1,185 @@
+// Copyright 2023 The Casdoor Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers
+
+import (
+	"encoding/json"
+
+	"github.com/beego/beego/utils/pagination"
+	"github.com/casdoor/casdoor/object"
+	"github.com/casdoor/casdoor/util"
+)
+
+// GetApiKeys
+// @Title GetApiKeys
+// @Tag Api Key API
+// @Description get the API keys of an organization
+// @Param   owner     query    string  true        "The owner of API keys"
+// @Success 200 {array} object.ApiKey The Response object
+// @router /get-api-keys [get]
+func (c *ApiController) GetApiKeys() {
+	owner := c.Input().Get("owner")
+	limit := c.Input().Get("pageSize")
+	page := c.Input().Get("p")
+	field := c.Input().Get("field")
+	value := c.Input().Get("value")
+	sortField := c.Input().Get("sortField")
+	sortOrder := c.Input().Get("sortOrder")
+	if limit == "" || page == "" {
+		c.Data["json"] = object.GetApiKeys(owner)
+		c.ServeJSON()
+	} else {
+		limit := util.ParseInt(limit)
+		paginator := pagination.SetPaginator(c.Ctx, limit, int64(object.GetApiKeyCount(owner, field, value)))
+		apiKeys := object.GetPaginationApiKeys(owner, paginator.Offset(), limit, field, value, sortField, sortOrder)
+		c.ResponseOk(apiKeys, paginator.Nums())
+	}
+}
+
+// GetApiKey
+// @Title GetApiKey
+// @Tag Api Key API
+// @Description get API key
+// @Param   id     query    string  true        "The id ( owner/name ) of the API key"
+// @Success 200 {object} object.ApiKey The Response object
+// @router /get-api-key [get]
+func (c *ApiController) GetApiKey() {
+	id := c.Input().Get("id")
+
+	c.Data["json"] = object.GetApiKey(id)
+	c.ServeJSON()
+}
+
+// GetUserApiKeys
+// @Title GetUserApiKeys
+// @Tag Api Key API
+// @Description get the personal access tokens of the signed-in user
+// @Success 200 {array} object.ApiKey The Response object
+// @router /get-user-api-keys [get]
+func (c *ApiController) GetUserApiKeys() {
+	userId, ok := c.RequireSignedIn()
+	if !ok {
+		return
+	}
+
+	c.ResponseOk(object.GetUserApiKeys(userId))
+}
+
+// AddApiKey
+// @Title AddApiKey
+// @Tag Api Key API
+// @Description add a personal access token, or a service key when the application is set (admin only). The key is only returned in this response
+// @Param   body    body   object.ApiKey  true        "The details of the API key"
+// @Success 200 {object} controllers.Response The Response object
+// @router /add-api-key [post]
+func (c *ApiController) AddApiKey() {
+	userId, ok := c.RequireSignedIn()
+	if !ok {
+		return
+	}
+
+	var apiKey object.ApiKey
+	err := json.Unmarshal(c.Ctx.Input.RequestBody, &apiKey)
+	if err != nil {
+		c.ResponseError(err.Error())
+		return
+	}
+
+	if apiKey.Application == "" && apiKey.User == "" {
+		apiKey.User = userId
+		apiKey.Owner, _ = util.GetOwnerAndNameFromId(userId)
+	}
+	if (apiKey.Application != "" || apiKey.User != userId) && !c.IsAdminOf(apiKey.Owner) {
+		c.ResponseError(c.T("auth:Unauthorized operation"))
+		return
+	}
+
+	msg := object.CheckApiKey(&apiKey, c.GetAcceptLanguage())
+	if msg != "" {
+		c.ResponseError(msg)
+		return
+	}
+
+	if apiKey.Name == "" {
+		apiKey.Name = util.GenerateId()
+	}
+	apiKey.CreatedTime = util.GetCurrentTime()
+	key, affected := object.AddApiKey(&apiKey)
+	if !affected {
+		c.Data["json"] = wrapActionResponse(false)
+		c.ServeJSON()
+		return
+	}
+
+	c.ResponseOk(key, apiKey)
+}
+
+// RevokeApiKey
+// @Title RevokeApiKey
+// @Tag Api Key API
+// @Description revoke API key, the key is kept for auditing
+// @Param   body    body   object.ApiKey  true        "The owner and name of the API key"
+// @Success 200 {object} controllers.Response The Response object
+// @router /revoke-api-key [post]
+func (c *ApiController) RevokeApiKey() {
+	apiKey, ok := c.getManagedApiKey()
+	if !ok {
+		return
+	}
+
+	c.Data["json"] = wrapActionResponse(object.RevokeApiKey(apiKey))
+	c.ServeJSON()
+}
+
+// DeleteApiKey
+// @Title DeleteApiKey
+// @Tag Api Key API
+// @Description delete API key
+// @Param   body    body   object.ApiKey  true        "The owner and name of the API key"
+// @Success 200 {object} controllers.Response The Response object
+// @router /delete-api-key [post]
+func (c *ApiController) DeleteApiKey() {
+	apiKey, ok := c.getManagedApiKey()
+	if !ok {
+		return
+	}
+
+	c.Data["json"] = wrapActionResponse(object.DeleteApiKey(apiKey))
+	c.ServeJSON()
+}
+
+// getManagedApiKey returns the API key of the request body if the session
+// user owns it or is an admin of its organization.
+func (c *ApiController) getManagedApiKey() (*object.ApiKey, bool) {
+	userId, ok := c.RequireSignedIn()
+	if !ok {
+		return nil, false
+	}
+
+	var body object.ApiKey
+	err := json.Unmarshal(c.Ctx.Input.RequestBody, &body)
+	if err != nil {
+		c.ResponseError(err.Error())
+		return nil, false
+	}
+
+	apiKey := object.GetApiKey(util.GetId(body.Owner, body.Name))
+	if apiKey == nil || (apiKey.User != userId && !c.IsAdminOf(apiKey.Owner)) {
+		c.ResponseError(c.T("auth:Unauthorized operation"))
+		return nil, false
+	}
+
+	return apiKey, true
+}
@@ -70,7 +70,10 @@ func (c *ApiController) GetApplication() {
 	userId := c.GetSessionUsername()
 	id := c.Input().Get("id")
 
-	c.Data["json"] = object.GetMaskedApplication(object.GetApplication(id), userId)
+	application := object.GetApplication(id)
+	c.setETag(application)
+
+	c.Data["json"] = object.GetMaskedApplication(application, userId)
 	c.ServeJSON()
 }
 
@@ -148,8 +151,7 @@ func (c *ApiController) UpdateApplication() {
 		return
 	}
 
-	c.Data["json"] = wrapActionResponse(object.UpdateApplication(id, &application))
-	c.ServeJSON()
+	c.responseConditionalWrite(object.UpdateApplicationWithPrecondition(id, &application, c.getPrecondition()))
 }
 
 // AddApplication
@@ -172,6 +174,9 @@ func (c *ApiController) AddApplication() {
 		c.ResponseError(err.Error())
 		return
 	}
+	if !c.checkSeatLimit(application.Organization, object.SeatResourceApplication, 1) {
+		return
+	}
 
 	c.Data["json"] = wrapActionResponse(object.AddApplication(&application))
 	c.ServeJSON()
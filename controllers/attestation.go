@@ -0,0 +1,93 @@
+// Copyright 2023 The Casdoor Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers
+
+import (
+	"fmt"
+
+	"github.com/casdoor/casdoor/object"
+)
+
+func (c *ApiController) getAttestationRequest() *object.AttestationRequest {
+	return &object.AttestationRequest{
+		Type:      c.Input().Get("attestation_type"),
+		Challenge: c.Input().Get("attestation_challenge"),
+		KeyId:     c.Input().Get("attestation_key_id"),
+		Token:     c.Input().Get("attestation"),
+	}
+}
+
+func (tokenRequest *TokenRequest) getAttestationRequest() *object.AttestationRequest {
+	return &object.AttestationRequest{
+		Type:      tokenRequest.AttestationType,
+		Challenge: tokenRequest.AttestationChallenge,
+		KeyId:     tokenRequest.AttestationKeyId,
+		Token:     tokenRequest.Attestation,
+	}
+}
+
+// GetAttestationChallenge
+// @Title GetAttestationChallenge
+// @Tag Token API
+// @Description get a challenge for the App Attest or Play Integrity attestation of a mobile app
+// @Param   client_id     query    string  true        "OAuth client id"
+// @Success 200 {object} controllers.Response The Response object
+// @router /login/oauth/attestation-challenge [get]
+func (c *ApiController) GetAttestationChallenge() {
+	clientId := c.Input().Get("client_id")
+
+	application := object.GetApplicationByClientId(clientId)
+	if application == nil {
+		c.ResponseError(c.T("token:Invalid client_id"))
+		return
+	}
+	if application.Attestation == nil {
+		c.ResponseError(fmt.Sprintf(c.T("token:The application: %s has no mobile app attestation"), application.Name))
+		return
+	}
+
+	c.ResponseOk(object.GetAttestationChallenge(application))
+}
+
+// RegisterAppAttestKey
+// @Title RegisterAppAttestKey
+// @Tag Token API
+// @Description register an App Attest key of an iOS app with its attestation
+// @Param   client_id     query    string  true        "OAuth client id"
+// @Param   key_id     query    string  true        "The key ID returned by generateKey()"
+// @Param   attestation     query    string  true        "The attestation object returned by attestKey(), in base64"
+// @Param   challenge     query    string  true        "The challenge of the attestation"
+// @Success 200 {object} controllers.Response The Response object
+// @router /login/oauth/register-attest-key [post]
+func (c *ApiController) RegisterAppAttestKey() {
+	clientId := c.Input().Get("client_id")
+	keyId := c.Input().Get("key_id")
+	attestation := c.Input().Get("attestation")
+	challenge := c.Input().Get("challenge")
+
+	application := object.GetApplicationByClientId(clientId)
+	if application == nil {
+		c.ResponseError(c.T("token:Invalid client_id"))
+		return
+	}
+
+	err := object.RegisterAppAttestKey(application, keyId, attestation, challenge)
+	if err != nil {
+		c.ResponseError(err.Error())
+		return
+	}
+
+	c.ResponseOk()
+}
@@ -42,7 +42,7 @@ var (
 
 func codeToResponse(code *object.Code) *Response {
 	if code.Code == "" {
-		return &Response{Status: "error", Msg: code.Message, Data: code.Code}
+		return &Response{Status: "error", Msg: code.Message, Code: code.ErrorCode, Data: code.Code}
 	}
 
 	return &Response{Status: "ok", Msg: "", Data: code.Code}
@@ -55,24 +55,87 @@ func tokenToResponse(token *object.Token) *Response {
 	return &Response{Status: "ok", Msg: "", Data: token.AccessToken, Data2: token.RefreshToken}
 }
 
+// getActiveOrganization returns the organization the user signs in to. It's
+// the one chosen in the form, or the organization of the application when the
+// user is a member of it, otherwise the home organization of the user. The
+// organizations to choose from are returned when the application asks the
+// user to pick one, only password sign-ins can be repeated with the choice.
+func (c *ApiController) getActiveOrganization(application *object.Application, user *object.User, form *RequestForm) (string, []*object.Organization, error) {
+	if form.ActiveOrganization != "" {
+		if !object.IsUserInOrganization(user, form.ActiveOrganization) {
+			return "", nil, fmt.Errorf(c.T("membership:The user is not a member of the organization: %s"), form.ActiveOrganization)
+		}
+		return form.ActiveOrganization, nil, nil
+	}
+
+	if application.EnableOrgPicker && form.Password != "" {
+		organizations := object.GetUserOrganizations(user)
+		if len(organizations) > 1 {
+			return "", organizations, nil
+		}
+	}
+
+	if application.Organization != user.Owner && object.IsUserInOrganization(user, application.Organization) {
+		return application.Organization, nil, nil
+	}
+	return "", nil, nil
+}
+
+func (c *ApiController) getLoginHookContext(event string, application *object.Application, user *object.User) *object.LoginHookContext {
+	return &object.LoginHookContext{
+		Event:        event,
+		Organization: application.Organization,
+		Application:  application.Name,
+		User:         user,
+		Ip:           util.GetIPFromRequest(c.Ctx.Request),
+		UserAgent:    c.Ctx.Request.UserAgent(),
+	}
+}
+
 // HandleLoggedIn ...
 func (c *ApiController) HandleLoggedIn(application *object.Application, user *object.User, form *RequestForm) (resp *Response) {
 	userId := user.GetId()
 
 	allowed, err := object.CheckAccessPermission(userId, application)
 	if err != nil {
-		c.ResponseError(err.Error(), nil)
+		c.ResponseServerError(application.Organization, err, nil)
 		return
 	}
 	if !allowed {
-		c.ResponseError(c.T("auth:Unauthorized operation"))
+		c.ResponseErrorCode(object.ErrorCodeAccessDenied, application.Organization, c.T("auth:Unauthorized operation"))
+		return
+	}
+
+	hookResult := object.RunLoginHooks(c.getLoginHookContext(object.LoginHookEventPostAuthentication, application, user))
+	if hookResult.Action == object.LoginHookActionDeny {
+		c.ResponseErrorCode(object.ErrorCodeAccessDenied, application.Organization, hookResult.Message)
+		return
+	}
+	if hookResult.Action == object.LoginHookActionRequire {
+		resp = &Response{Status: "ok", Msg: hookResult.Message, Data: "RequiredSteps", Data2: hookResult}
+		return
+	}
+	if len(hookResult.Claims) > 0 {
+		hookResult.ApplyClaimsToUser(user)
+		object.UpdateUser(userId, user, []string{"properties"}, false)
+	}
+
+	activeOrganization, organizations, err := c.getActiveOrganization(application, user, form)
+	if err != nil {
+		c.ResponseErrorCode(object.ErrorCodeAccessDenied, application.Organization, err.Error())
 		return
 	}
+	if organizations != nil {
+		resp = &Response{Status: "ok", Msg: "", Data: "SelectOrganization", Data2: object.GetMaskedOrganizations(organizations)}
+		return
+	}
+	user = object.ApplyMembership(user, activeOrganization)
 
 	if form.Type == ResponseTypeLogin {
 		c.SetSessionUsername(userId)
+		c.SetSessionActiveOrganization(activeOrganization)
 		util.LogInfo(c.Ctx, "API: [%s] signed in", userId)
-		resp = &Response{Status: "ok", Msg: "", Data: userId}
+		resp = &Response{Status: "ok", Msg: "", Data: userId, Data2: object.GetPostLoginUrl(application, user, c.Ctx.Request.UserAgent())}
 	} else if form.Type == ResponseTypeCode {
 		clientId := c.Input().Get("clientId")
 		responseType := c.Input().Get("responseType")
@@ -84,28 +147,37 @@ func (c *ApiController) HandleLoggedIn(application *object.Application, user *ob
 		codeChallenge := c.Input().Get("code_challenge")
 
 		if challengeMethod != "S256" && challengeMethod != "null" && challengeMethod != "" {
-			c.ResponseError(c.T("auth:Challenge method should be S256"))
+			c.ResponseErrorCode(object.ErrorCodeInvalidRequest, application.Organization, c.T("auth:Challenge method should be S256"))
 			return
 		}
-		code := object.GetOAuthCode(userId, clientId, responseType, redirectUri, scope, state, nonce, codeChallenge, c.Ctx.Request.Host, c.GetAcceptLanguage())
-		resp = codeToResponse(code)
+		code := object.GetOAuthCode(userId, clientId, responseType, redirectUri, scope, state, nonce, codeChallenge, c.Ctx.Request.Host, activeOrganization, c.GetAcceptLanguage())
+		if code.ErrorCode != "" {
+			resp = c.getErrorCodeResponse(code.ErrorCode, application.Organization, code.Message)
+		} else {
+			resp = codeToResponse(code)
+		}
 
 		if application.EnableSigninSession || application.HasPromptPage() {
 			// The prompt page needs the user to be signed in
 			c.SetSessionUsername(userId)
+			c.SetSessionActiveOrganization(activeOrganization)
 		}
 	} else if form.Type == ResponseTypeToken || form.Type == ResponseTypeIdToken { // implicit flow
 		if !object.IsGrantTypeValid(form.Type, application.GrantTypes) {
-			resp = &Response{Status: "error", Msg: fmt.Sprintf("error: grant_type: %s is not supported in this application", form.Type), Data: ""}
+			resp = c.getErrorCodeResponse(object.ErrorCodeUnsupportedResponseType, application.Organization, fmt.Sprintf(c.T("token:Grant_type: %s is not supported in this application"), form.Type))
 		} else {
 			scope := c.Input().Get("scope")
-			token, _ := object.GetTokenByUser(application, user, scope, c.Ctx.Request.Host)
+			token, err := object.GetTokenByUser(application, user, scope, c.Ctx.Request.Host)
+			if err != nil {
+				c.ResponseServerError(application.Organization, err)
+				return
+			}
 			resp = tokenToResponse(token)
 		}
 	} else if form.Type == ResponseTypeSaml { // saml flow
 		res, redirectUrl, method, err := object.GetSamlResponse(application, user, form.SamlRequest, c.Ctx.Request.Host)
 		if err != nil {
-			c.ResponseError(err.Error(), nil)
+			c.ResponseErrorCode(object.ErrorCodeInvalidRequest, application.Organization, err.Error(), nil)
 			return
 		}
 		resp = &Response{Status: "ok", Msg: "", Data: res, Data2: map[string]string{"redirectUrl": redirectUrl, "method": method}}
@@ -114,9 +186,15 @@ func (c *ApiController) HandleLoggedIn(application *object.Application, user *ob
 		service := c.Input().Get("service")
 		resp = wrapErrorResponse(nil)
 		if service != "" {
+			if reason := application.CheckServiceUri(service); reason != "" {
+				c.ResponseErrorCode(object.ErrorCodeInvalidRedirectUri, application.Organization, fmt.Sprintf(c.T("auth:Service: %s is rejected: %s"), service, object.TranslateRedirectUriReason(c.GetAcceptLanguage(), reason)))
+				return
+			}
+
 			st, err := object.GenerateCasToken(userId, service)
 			if err != nil {
-				resp = wrapErrorResponse(err)
+				c.ResponseServerError(application.Organization, err)
+				return
 			} else {
 				resp.Data = st
 			}
@@ -127,13 +205,13 @@ func (c *ApiController) HandleLoggedIn(application *object.Application, user *ob
 		}
 
 	} else {
-		resp = wrapErrorResponse(fmt.Errorf("unknown response type: %s", form.Type))
+		resp = c.getErrorCodeResponse(object.ErrorCodeUnsupportedResponseType, application.Organization, "")
 	}
 
 	// if user did not check auto signin
 	if resp.Status == "ok" && !form.AutoSignin {
 		timestamp := time.Now().Unix()
-		timestamp += 3600 * 24
+		timestamp += 3600 * conf.GetConfigSessionExpireInHours()
 		c.SetSessionData(&SessionData{
 			ExpireTime: timestamp,
 		})
@@ -148,6 +226,10 @@ func (c *ApiController) HandleLoggedIn(application *object.Application, user *ob
 		})
 	}
 
+	if resp.Status == "ok" {
+		c.checkLoginAlerts(userId)
+	}
+
 	return resp
 }
 
@@ -169,10 +251,14 @@ func (c *ApiController) GetApplicationLogin() {
 	scope := c.Input().Get("scope")
 	state := c.Input().Get("state")
 
-	msg, application := object.CheckOAuthLogin(clientId, responseType, redirectUri, scope, state, c.GetAcceptLanguage())
+	codedError, application := object.CheckOAuthLogin(clientId, responseType, redirectUri, scope, state, c.GetAcceptLanguage())
 	application = object.GetMaskedApplication(application, "")
-	if msg != "" {
-		c.ResponseError(msg, application)
+	if codedError != nil {
+		organization := ""
+		if application != nil {
+			organization = application.Organization
+		}
+		c.ResponseErrorCode(codedError.Code, organization, codedError.Message, application)
 	} else {
 		c.ResponseOk(application)
 	}
@@ -230,14 +316,17 @@ func (c *ApiController) Login() {
 	var form RequestForm
 	err := json.Unmarshal(c.Ctx.Input.RequestBody, &form)
 	if err != nil {
-		c.ResponseError(err.Error())
+		c.ResponseErrorCode(object.ErrorCodeInvalidRequest, "", "")
 		return
 	}
 
+	isSignup := false
+	defer func() { c.addLoginStat(&form, getLoginStatProvider(&form), isSignup) }()
+
 	if form.Username != "" {
 		if form.Type == ResponseTypeLogin {
 			if c.GetSessionUsername() != "" {
-				c.ResponseError(c.T("account:Please sign out first"), c.GetSessionUsername())
+				c.ResponseErrorCode(object.ErrorCodeInvalidRequest, form.Organization, c.T("account:Please sign out first"), c.GetSessionUsername())
 				return
 			}
 		}
@@ -269,21 +358,21 @@ func (c *ApiController) Login() {
 			}
 
 			if user = object.GetUserByFields(form.Organization, form.Username); user == nil {
-				c.ResponseError(fmt.Sprintf(c.T("general:The user: %s doesn't exist"), util.GetId(form.Organization, form.Username)))
+				c.ResponseErrorCode(object.ErrorCodeUserNotFound, form.Organization, fmt.Sprintf(c.T("general:The user: %s doesn't exist"), util.GetId(form.Organization, form.Username)))
 				return
 			}
 			if verificationCodeType == "phone" {
 				form.CountryCode = user.GetCountryCode(form.CountryCode)
 				var ok bool
 				if checkDest, ok = util.GetE164Number(form.Username, form.CountryCode); !ok {
-					c.ResponseError(fmt.Sprintf(c.T("verification:Phone number is invalid in your region %s"), form.CountryCode))
+					c.ResponseErrorCode(object.ErrorCodeInvalidRequest, form.Organization, fmt.Sprintf(c.T("verification:Phone number is invalid in your region %s"), form.CountryCode))
 					return
 				}
 			}
 
 			checkResult = object.CheckSigninCode(user, checkDest, form.Code, c.GetAcceptLanguage())
 			if len(checkResult) != 0 {
-				c.ResponseError(fmt.Sprintf("%s - %s", verificationCodeType, checkResult))
+				c.ResponseErrorCode(object.ErrorCodeInvalidCredentials, form.Organization, fmt.Sprintf("%s - %s", verificationCodeType, checkResult))
 				return
 			}
 
@@ -292,37 +381,45 @@ func (c *ApiController) Login() {
 		} else {
 			application := object.GetApplication(fmt.Sprintf("admin/%s", form.Application))
 			if application == nil {
-				c.ResponseError(fmt.Sprintf(c.T("auth:The application: %s does not exist"), form.Application))
+				c.ResponseErrorCode(object.ErrorCodeApplicationNotFound, form.Organization, fmt.Sprintf(c.T("auth:The application: %s does not exist"), form.Application))
 				return
 			}
 			if !application.EnablePassword {
-				c.ResponseError(c.T("auth:The login method: login with password is not enabled for the application"))
+				c.ResponseErrorCode(object.ErrorCodeLoginMethodDisabled, application.Organization, c.T("auth:The login method: login with password is not enabled for the application"))
 				return
 			}
 
 			if object.CheckToEnableCaptcha(application) {
 				isHuman, err := captcha.VerifyCaptchaByCaptchaType(form.CaptchaType, form.CaptchaToken, form.ClientSecret)
 				if err != nil {
-					c.ResponseError(err.Error())
+					c.ResponseServerError(application.Organization, err)
 					return
 				}
 
 				if !isHuman {
-					c.ResponseError(c.T("verification:Turing test failed."))
+					c.ResponseErrorCode(object.ErrorCodeCaptchaFailed, application.Organization, c.T("verification:Turing test failed."))
 					return
 				}
 			}
 
 			password := form.Password
-			user, msg = object.CheckUserPassword(form.Organization, form.Username, password, c.GetAcceptLanguage())
+			organization, username := form.Organization, form.Username
+			// a member from another organization signs in as "organization/username"
+			if tokens := strings.SplitN(username, "/", 2); len(tokens) == 2 && tokens[0] != "" && tokens[1] != "" {
+				organization, username = tokens[0], tokens[1]
+			}
+			user, msg = object.CheckUserPassword(organization, username, password, c.GetAcceptLanguage())
+			if msg == "" && !object.IsUserInOrganization(user, form.Organization) {
+				msg = fmt.Sprintf(c.T("membership:The user is not a member of the organization: %s"), form.Organization)
+			}
 		}
 
 		if msg != "" {
-			resp = &Response{Status: "error", Msg: msg}
+			resp = c.getErrorCodeResponse(object.ErrorCodeInvalidCredentials, form.Organization, msg)
 		} else {
 			application := object.GetApplication(fmt.Sprintf("admin/%s", form.Application))
 			if application == nil {
-				c.ResponseError(fmt.Sprintf(c.T("auth:The application: %s does not exist"), form.Application))
+				c.ResponseErrorCode(object.ErrorCodeApplicationNotFound, form.Organization, fmt.Sprintf(c.T("auth:The application: %s does not exist"), form.Application))
 				return
 			}
 
@@ -342,7 +439,7 @@ func (c *ApiController) Login() {
 		}
 
 		if application == nil {
-			c.ResponseError(fmt.Sprintf(c.T("auth:The application: %s does not exist"), form.Application))
+			c.ResponseErrorCode(object.ErrorCodeApplicationNotFound, form.Organization, fmt.Sprintf(c.T("auth:The application: %s does not exist"), form.Application))
 			return
 		}
 
@@ -350,7 +447,7 @@ func (c *ApiController) Login() {
 		provider := object.GetProvider(util.GetId("admin", form.Provider))
 		providerItem := application.GetProviderItem(provider.Name)
 		if !providerItem.IsProviderVisible() {
-			c.ResponseError(fmt.Sprintf(c.T("auth:The provider: %s is not enabled for the application"), provider.Name))
+			c.ResponseErrorCode(object.ErrorCodeLoginMethodDisabled, application.Organization, fmt.Sprintf(c.T("auth:The provider: %s is not enabled for the application"), provider.Name))
 			return
 		}
 
@@ -359,7 +456,7 @@ func (c *ApiController) Login() {
 			// SAML
 			userInfo.Id, err = object.ParseSamlResponse(form.SamlResponse, provider.Type)
 			if err != nil {
-				c.ResponseError(err.Error())
+				c.ResponseErrorCode(object.ErrorCodeInvalidCredentials, application.Organization, err.Error())
 				return
 			}
 		} else if provider.Category == "OAuth" {
@@ -374,32 +471,32 @@ func (c *ApiController) Login() {
 
 			idProvider := idp.GetIdProvider(provider.Type, provider.SubType, clientId, clientSecret, provider.AppId, form.RedirectUri, provider.Domain, provider.CustomAuthUrl, provider.CustomTokenUrl, provider.CustomUserInfoUrl)
 			if idProvider == nil {
-				c.ResponseError(fmt.Sprintf(c.T("storage:The provider type: %s is not supported"), provider.Type))
+				c.ResponseErrorCode(object.ErrorCodeInvalidRequest, application.Organization, fmt.Sprintf(c.T("storage:The provider type: %s is not supported"), provider.Type))
 				return
 			}
 
 			setHttpClient(idProvider, provider.Type)
 
 			if form.State != conf.GetConfigString("authState") && form.State != application.Name {
-				c.ResponseError(fmt.Sprintf(c.T("auth:State expected: %s, but got: %s"), conf.GetConfigString("authState"), form.State))
+				c.ResponseErrorCode(object.ErrorCodeInvalidRequest, application.Organization, fmt.Sprintf(c.T("auth:State expected: %s, but got: %s"), conf.GetConfigString("authState"), form.State))
 				return
 			}
 
 			// https://github.com/golang/oauth2/issues/123#issuecomment-103715338
 			token, err := idProvider.GetToken(form.Code)
 			if err != nil {
-				c.ResponseError(err.Error())
+				c.ResponseErrorCode(object.ErrorCodeInvalidCredentials, application.Organization, err.Error())
 				return
 			}
 
 			if !token.Valid() {
-				c.ResponseError(c.T("auth:Invalid token"))
+				c.ResponseErrorCode(object.ErrorCodeInvalidCredentials, application.Organization, c.T("auth:Invalid token"))
 				return
 			}
 
 			userInfo, err = idProvider.GetUserInfo(token)
 			if err != nil {
-				c.ResponseError(fmt.Sprintf(c.T("auth:Failed to login in: %s"), err.Error()))
+				c.ResponseErrorCode(object.ErrorCodeInvalidCredentials, application.Organization, fmt.Sprintf(c.T("auth:Failed to login in: %s"), err.Error()))
 				return
 			}
 		}
@@ -416,7 +513,8 @@ func (c *ApiController) Login() {
 				// Sign in via OAuth (want to sign up but already have account)
 
 				if user.IsForbidden {
-					c.ResponseError(c.T("check:The user is forbidden to sign in, please contact the administrator"))
+					c.ResponseErrorCode(object.ErrorCodeUserForbidden, application.Organization, c.T("check:The user is forbidden to sign in, please contact the administrator"))
+					return
 				}
 
 				resp = c.HandleLoggedIn(application, user, &form)
@@ -428,12 +526,12 @@ func (c *ApiController) Login() {
 			} else if provider.Category == "OAuth" {
 				// Sign up via OAuth
 				if !application.EnableSignUp {
-					c.ResponseError(fmt.Sprintf(c.T("auth:The account for provider: %s and username: %s (%s) does not exist and is not allowed to sign up as new account, please contact your IT support"), provider.Type, userInfo.Username, userInfo.DisplayName))
+					c.ResponseErrorCode(object.ErrorCodeSignupNotAllowed, application.Organization, fmt.Sprintf(c.T("auth:The account for provider: %s and username: %s (%s) does not exist and is not allowed to sign up as new account, please contact your IT support"), provider.Type, userInfo.Username, userInfo.DisplayName))
 					return
 				}
 
 				if !providerItem.CanSignUp {
-					c.ResponseError(fmt.Sprintf(c.T("auth:The account for provider: %s and username: %s (%s) does not exist and is not allowed to sign up as new account via %%s, please use another way to sign up"), provider.Type, userInfo.Username, userInfo.DisplayName, provider.Type))
+					c.ResponseErrorCode(object.ErrorCodeSignupNotAllowed, application.Organization, fmt.Sprintf(c.T("auth:The account for provider: %s and username: %s (%s) does not exist and is not allowed to sign up as new account via %%s, please use another way to sign up"), provider.Type, userInfo.Username, userInfo.DisplayName, provider.Type))
 					return
 				}
 
@@ -448,7 +546,7 @@ func (c *ApiController) Login() {
 					if tmpUser != nil {
 						uid, err := uuid.NewRandom()
 						if err != nil {
-							c.ResponseError(err.Error())
+							c.ResponseServerError(application.Organization, err)
 							return
 						}
 
@@ -460,7 +558,7 @@ func (c *ApiController) Login() {
 					properties["no"] = strconv.Itoa(object.GetUserCount(application.Organization, "", "") + 2)
 					initScore, err := getInitScore(organization)
 					if err != nil {
-						c.ResponseError(fmt.Errorf(c.T("account:Get init score failed, error: %w"), err).Error())
+						c.ResponseServerError(application.Organization, fmt.Errorf(c.T("account:Get init score failed, error: %w"), err))
 						return
 					}
 
@@ -483,9 +581,13 @@ func (c *ApiController) Login() {
 						Properties:        properties,
 					}
 
+					if !c.checkSeatLimit(user.Owner, object.SeatResourceUser, 1) {
+						return
+					}
+
 					affected := object.AddUser(user)
 					if !affected {
-						c.ResponseError(fmt.Sprintf(c.T("auth:Failed to create user, user information is invalid: %s"), util.StructToJson(user)))
+						c.ResponseServerError(application.Organization, fmt.Errorf(c.T("auth:Failed to create user, user information is invalid: %s"), util.StructToJson(user)))
 						return
 					}
 				}
@@ -500,6 +602,7 @@ func (c *ApiController) Login() {
 				record.Organization = application.Organization
 				record.User = user.Name
 				util.SafeGoroutine(func() { object.AddRecord(record) })
+				isSignup = true
 
 				record2 := object.NewRecord(c.Ctx)
 				record2.Action = "signup"
@@ -507,19 +610,19 @@ func (c *ApiController) Login() {
 				record2.User = user.Name
 				util.SafeGoroutine(func() { object.AddRecord(record2) })
 			} else if provider.Category == "SAML" {
-				resp = &Response{Status: "error", Msg: fmt.Sprintf(c.T("general:The user: %s doesn't exist"), util.GetId(application.Organization, userInfo.Id))}
+				resp = c.getErrorCodeResponse(object.ErrorCodeUserNotFound, application.Organization, fmt.Sprintf(c.T("general:The user: %s doesn't exist"), util.GetId(application.Organization, userInfo.Id)))
 			}
 			// resp = &Response{Status: "ok", Msg: "", Data: res}
 		} else { // form.Method != "signup"
 			userId := c.GetSessionUsername()
 			if userId == "" {
-				c.ResponseError(fmt.Sprintf(c.T("general:The user: %s doesn't exist"), util.GetId(application.Organization, userInfo.Id)), userInfo)
+				c.ResponseErrorCode(object.ErrorCodeUserNotFound, application.Organization, fmt.Sprintf(c.T("general:The user: %s doesn't exist"), util.GetId(application.Organization, userInfo.Id)), userInfo)
 				return
 			}
 
 			oldUser := object.GetUserByField(application.Organization, provider.Type, userInfo.Id)
 			if oldUser != nil {
-				c.ResponseError(fmt.Sprintf(c.T("auth:The account for provider: %s and username: %s (%s) is already linked to another account: %s (%s)"), provider.Type, userInfo.Username, userInfo.DisplayName, oldUser.Name, oldUser.DisplayName))
+				c.ResponseErrorCode(object.ErrorCodeAccessDenied, application.Organization, fmt.Sprintf(c.T("auth:The account for provider: %s and username: %s (%s) is already linked to another account: %s (%s)"), provider.Type, userInfo.Username, userInfo.DisplayName, oldUser.Name, oldUser.DisplayName))
 				return
 			}
 
@@ -532,7 +635,8 @@ func (c *ApiController) Login() {
 			if isLinked {
 				resp = &Response{Status: "ok", Msg: "", Data: isLinked}
 			} else {
-				resp = &Response{Status: "error", Msg: "Failed to link user account", Data: isLinked}
+				resp = c.getErrorCodeResponse(object.ErrorCodeServerError, application.Organization, "")
+				resp.Data = isLinked
 			}
 		}
 	} else {
@@ -540,7 +644,7 @@ func (c *ApiController) Login() {
 			// user already signed in to Casdoor, so let the user click the avatar button to do the quick sign-in
 			application := object.GetApplication(fmt.Sprintf("admin/%s", form.Application))
 			if application == nil {
-				c.ResponseError(fmt.Sprintf(c.T("auth:The application: %s does not exist"), form.Application))
+				c.ResponseErrorCode(object.ErrorCodeApplicationNotFound, form.Organization, fmt.Sprintf(c.T("auth:The application: %s does not exist"), form.Application))
 				return
 			}
 
@@ -552,7 +656,7 @@ func (c *ApiController) Login() {
 			record.User = user.Name
 			util.SafeGoroutine(func() { object.AddRecord(record) })
 		} else {
-			c.ResponseError(fmt.Sprintf(c.T("auth:Unknown authentication type (not password or provider), form = %s"), util.StructToJson(form)))
+			c.ResponseErrorCode(object.ErrorCodeInvalidRequest, form.Organization, c.T("auth:Unknown authentication type (not password or provider)"))
 			return
 		}
 	}
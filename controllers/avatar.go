@@ -0,0 +1,77 @@
+// Copyright 2023 The Casdoor Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/casdoor/casdoor/object"
+)
+
+// GetUserAvatar
+// @Title GetUserAvatar
+// @Tag User API
+// @Description redirect to the avatar of a user in a size, for the src of an img element
+// @Param   id     query    string  true        "The id ( owner/name ) of the user"
+// @Param   size     query    int  false        "The width in pixels, the largest stored size by default"
+// @Success 302 The avatar
+// @router /get-user-avatar [get]
+func (c *ApiController) GetUserAvatar() {
+	id := c.Input().Get("id")
+	size, _ := strconv.Atoi(c.Input().Get("size"))
+
+	user := object.GetUserNoCheck(id)
+	if user == nil || user.IsDeleted {
+		c.ResponseError(fmt.Sprintf(c.T("general:The user: %s doesn't exist"), id))
+		return
+	}
+
+	avatarUrl := object.GetUserAvatarUrl(user, size)
+	if avatarUrl == "" {
+		c.Ctx.Output.SetStatus(http.StatusNotFound)
+		return
+	}
+
+	// the URLs are cache-busted, only the redirect itself is cached shortly
+	c.Ctx.Output.Header("Cache-Control", "public, max-age=300")
+	c.Redirect(avatarUrl, http.StatusFound)
+}
+
+// RefreshUserAvatar
+// @Title RefreshUserAvatar
+// @Tag User API
+// @Description run the avatar pipeline of the organization for a user again, e.g. after the display name or the email changed
+// @Param   id     query    string  true        "The id ( owner/name ) of the user"
+// @Success 200 {object} controllers.Response The permanent avatar in data
+// @router /refresh-user-avatar [post]
+func (c *ApiController) RefreshUserAvatar() {
+	id := c.Input().Get("id")
+
+	user := object.GetUserNoCheck(id)
+	if user == nil {
+		c.ResponseError(fmt.Sprintf(c.T("general:The user: %s doesn't exist"), id))
+		return
+	}
+
+	_, err := object.ProcessUserAvatar(user)
+	if err != nil {
+		c.ResponseError(err.Error())
+		return
+	}
+
+	c.ResponseOk(user.PermanentAvatar, user.Avatar)
+}
@@ -0,0 +1,60 @@
+// Copyright 2023 The Casdoor Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/casdoor/casdoor/object"
+)
+
+// CreateBackup
+// @Title CreateBackup
+// @Tag Backup API
+// @Description download an encrypted backup of all tables, encrypted with the backupPassphrase of app.conf
+// @Param   excludeRecords     query    string  false        "true to leave out the records"
+// @Success 200 {string} string "The backup archive"
+// @router /create-backup [get]
+func (c *ApiController) CreateBackup() {
+	excludeRecords := c.Input().Get("excludeRecords") == "true"
+
+	data, _, err := object.CreateEncryptedBackup(excludeRecords)
+	if err != nil {
+		c.ResponseError(err.Error())
+		return
+	}
+
+	c.Ctx.Output.Header("Content-Type", "application/octet-stream")
+	c.Ctx.Output.Header("Content-Disposition", fmt.Sprintf("attachment; filename=casdoor-%s.bak", time.Now().UTC().Format("20060102-150405")))
+	c.Ctx.Output.Body(data)
+}
+
+// RestoreBackup
+// @Title RestoreBackup
+// @Tag Backup API
+// @Description replace the tables of the backup with its rows, the backup must come from a server with the same migrations
+// @Param   body    body   string  true        "The backup archive"
+// @Success 200 {object} object.BackupSummary The Response object
+// @router /restore-backup [post]
+func (c *ApiController) RestoreBackup() {
+	summary, err := object.RestoreEncryptedBackup(c.Ctx.Input.RequestBody)
+	if err != nil {
+		c.ResponseError(err.Error())
+		return
+	}
+
+	c.ResponseOk(summary)
+}
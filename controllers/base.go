@@ -55,6 +55,18 @@ func (c *ApiController) IsGlobalAdmin() bool {
 	return user.Owner == "built-in" || user.IsGlobalAdmin
 }
 
+// IsAdminOf returns whether the session user is a global admin or an admin
+// of the organization.
+func (c *ApiController) IsAdminOf(organization string) bool {
+	if c.IsGlobalAdmin() {
+		return true
+	}
+
+	// the admins of an organization also manage its sub-organizations
+	user := object.GetUser(c.GetSessionUsername())
+	return user != nil && user.IsAdmin && (user.Owner == organization || object.IsOrganizationAncestor(user.Owner, organization))
+}
+
 // GetSessionUsername ...
 func (c *ApiController) GetSessionUsername() string {
 	// check if user session expired
@@ -113,6 +125,24 @@ func (c *ApiController) GetSessionOidc() (string, string) {
 // SetSessionUsername ...
 func (c *ApiController) SetSessionUsername(user string) {
 	c.SetSession("username", user)
+	// a sign-in replaces the session of an API key
+	c.DelSession("apiKey")
+	c.DelSession("activeOrganization")
+}
+
+// GetSessionActiveOrganization returns the organization chosen at sign-in,
+// empty for the home organization of the user.
+func (c *ApiController) GetSessionActiveOrganization() string {
+	activeOrganization, _ := c.GetSession("activeOrganization").(string)
+	return activeOrganization
+}
+
+func (c *ApiController) SetSessionActiveOrganization(activeOrganization string) {
+	if activeOrganization == "" {
+		c.DelSession("activeOrganization")
+	} else {
+		c.SetSession("activeOrganization", activeOrganization)
+	}
 }
 
 // GetSessionData ...
@@ -0,0 +1,233 @@
+// Copyright 2023 The Casdoor Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers
+
+import (
+	"encoding/json"
+
+	"github.com/casdoor/casdoor/object"
+)
+
+func (c *ApiController) responseBatch(results []*object.BatchResult, ok bool) {
+	if ok {
+		c.ResponseOk(results)
+	} else {
+		c.ResponseError(c.T("batch:The batch is rolled back because some items failed"), results)
+	}
+}
+
+// BatchAddUsers
+// @Title BatchAddUsers
+// @Tag User API
+// @Description add users in a single transaction
+// @Param   body    body   []object.User  true        "The details of the users"
+// @Success 200 {array} object.BatchResult The Response object
+// @router /batch-add-users [post]
+func (c *ApiController) BatchAddUsers() {
+	var users []*object.User
+	err := json.Unmarshal(c.Ctx.Input.RequestBody, &users)
+	if err != nil {
+		c.ResponseError(err.Error())
+		return
+	}
+
+	count := object.GetUserCount("", "", "")
+	if err := checkQuotaForUser(count + len(users) - 1); err != nil {
+		c.ResponseError(err.Error())
+		return
+	}
+
+	err = object.CheckUsersSeatLimit(users, c.GetAcceptLanguage())
+	if err != nil {
+		c.ResponseError(err.Error())
+		return
+	}
+
+	c.responseBatch(object.BatchAddUsers(users, c.GetAcceptLanguage()))
+}
+
+// BatchUpdateUsers
+// @Title BatchUpdateUsers
+// @Tag User API
+// @Description update users in a single transaction
+// @Param   body    body   []object.User  true        "The details of the users"
+// @Success 200 {array} object.BatchResult The Response object
+// @router /batch-update-users [post]
+func (c *ApiController) BatchUpdateUsers() {
+	var users []*object.User
+	err := json.Unmarshal(c.Ctx.Input.RequestBody, &users)
+	if err != nil {
+		c.ResponseError(err.Error())
+		return
+	}
+
+	// the same checks as UpdateUser, the users that don't exist are reported
+	// by the batch
+	for _, user := range users {
+		oldUser := object.GetUser(user.GetId())
+		if oldUser == nil {
+			continue
+		}
+
+		if pass, err := checkPermissionForUpdateUser(user.GetId(), *user, c); !pass {
+			c.ResponseError(err)
+			return
+		}
+		if !c.IsAdminOf(oldUser.Owner) && (oldUser.Email != user.Email || oldUser.Phone != user.Phone) {
+			c.ResponseError(c.T("verification:The email and phone can only be changed with a verification code"))
+			return
+		}
+	}
+
+	c.responseBatch(object.BatchUpdateUsers(users, c.IsGlobalAdmin(), c.GetAcceptLanguage()))
+}
+
+// BatchDeleteUsers
+// @Title BatchDeleteUsers
+// @Tag User API
+// @Description delete users in a single transaction
+// @Param   body    body   []object.User  true        "The users to delete"
+// @Success 200 {array} object.BatchResult The Response object
+// @router /batch-delete-users [post]
+func (c *ApiController) BatchDeleteUsers() {
+	var users []*object.User
+	err := json.Unmarshal(c.Ctx.Input.RequestBody, &users)
+	if err != nil {
+		c.ResponseError(err.Error())
+		return
+	}
+
+	c.responseBatch(object.BatchDeleteUsers(users))
+}
+
+// BatchAddRoles
+// @Title BatchAddRoles
+// @Tag Role API
+// @Description add roles in a single transaction
+// @Param   body    body   []object.Role  true        "The details of the roles"
+// @Success 200 {array} object.BatchResult The Response object
+// @router /batch-add-roles [post]
+func (c *ApiController) BatchAddRoles() {
+	var roles []*object.Role
+	err := json.Unmarshal(c.Ctx.Input.RequestBody, &roles)
+	if err != nil {
+		c.ResponseError(err.Error())
+		return
+	}
+
+	for _, role := range roles {
+		if !c.checkRoleCapabilitiesChange(role, nil) {
+			return
+		}
+	}
+
+	c.responseBatch(object.BatchAddRoles(roles, c.GetAcceptLanguage()))
+}
+
+// BatchUpdateRoles
+// @Title BatchUpdateRoles
+// @Tag Role API
+// @Description update roles in a single transaction
+// @Param   body    body   []object.Role  true        "The details of the roles"
+// @Success 200 {array} object.BatchResult The Response object
+// @router /batch-update-roles [post]
+func (c *ApiController) BatchUpdateRoles() {
+	var roles []*object.Role
+	err := json.Unmarshal(c.Ctx.Input.RequestBody, &roles)
+	if err != nil {
+		c.ResponseError(err.Error())
+		return
+	}
+
+	for _, role := range roles {
+		if !c.checkRoleCapabilitiesChange(role, object.GetRole(role.GetId())) {
+			return
+		}
+	}
+
+	c.responseBatch(object.BatchUpdateRoles(roles, c.GetAcceptLanguage()))
+}
+
+// BatchDeleteRoles
+// @Title BatchDeleteRoles
+// @Tag Role API
+// @Description delete roles in a single transaction
+// @Param   body    body   []object.Role  true        "The roles to delete"
+// @Success 200 {array} object.BatchResult The Response object
+// @router /batch-delete-roles [post]
+func (c *ApiController) BatchDeleteRoles() {
+	var roles []*object.Role
+	err := json.Unmarshal(c.Ctx.Input.RequestBody, &roles)
+	if err != nil {
+		c.ResponseError(err.Error())
+		return
+	}
+
+	c.responseBatch(object.BatchDeleteRoles(roles))
+}
+
+// BatchAddPermissions
+// @Title BatchAddPermissions
+// @Tag Permission API
+// @Description add permissions in a single transaction
+// @Param   body    body   []object.Permission  true        "The details of the permissions"
+// @Success 200 {array} object.BatchResult The Response object
+// @router /batch-add-permissions [post]
+func (c *ApiController) BatchAddPermissions() {
+	var permissions []*object.Permission
+	err := json.Unmarshal(c.Ctx.Input.RequestBody, &permissions)
+	if err != nil {
+		c.ResponseError(err.Error())
+		return
+	}
+
+	c.responseBatch(object.BatchAddPermissions(permissions))
+}
+
+// BatchUpdatePermissions
+// @Title BatchUpdatePermissions
+// @Tag Permission API
+// @Description update permissions in a single transaction
+// @Param   body    body   []object.Permission  true        "The details of the permissions"
+// @Success 200 {array} object.BatchResult The Response object
+// @router /batch-update-permissions [post]
+func (c *ApiController) BatchUpdatePermissions() {
+	var permissions []*object.Permission
+	err := json.Unmarshal(c.Ctx.Input.RequestBody, &permissions)
+	if err != nil {
+		c.ResponseError(err.Error())
+		return
+	}
+
+	c.responseBatch(object.BatchUpdatePermissions(permissions))
+}
+
+// BatchDeletePermissions
+// @Title BatchDeletePermissions
+// @Tag Permission API
+// @Description delete permissions in a single transaction
+// @Param   body    body   []object.Permission  true        "The permissions to delete"
+// @Success 200 {array} object.BatchResult The Response object
+// @router /batch-delete-permissions [post]
+func (c *ApiController) BatchDeletePermissions() {
+	var permissions []*object.Permission
+	err := json.Unmarshal(c.Ctx.Input.RequestBody, &permissions)
+	if err != nil {
+		c.ResponseError(err.Error())
+		return
+	}
+
+	c.responseBatch(object.BatchDeletePermissions(permissions))
+}
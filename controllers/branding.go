@@ -0,0 +1,52 @@
+// Copyright 2023 The Casdoor Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers
+
+import (
+	"fmt"
+
+	"github.com/casdoor/casdoor/object"
+)
+
+// GetBranding
+// @Title GetBranding
+// @Tag Branding API
+// @Description get the branding of the login pages for an application or an organization, in the language of Accept-Language
+// @Param   application     query    string  false        "The id ( owner/name ) of the application"
+// @Param   organization    query    string  false        "The id ( owner/name ) of the organization"
+// @Success 200 {object} object.Branding The Response object
+// @router /get-branding [get]
+func (c *ApiController) GetBranding() {
+	applicationId := c.Input().Get("application")
+	organizationId := c.Input().Get("organization")
+	language := c.GetAcceptLanguage()
+
+	var branding *object.Branding
+	if applicationId != "" {
+		branding = object.GetApplicationBranding(applicationId, language)
+		if branding == nil {
+			c.ResponseError(fmt.Sprintf(c.T("auth:The application: %s does not exist"), applicationId))
+			return
+		}
+	} else {
+		branding = object.GetOrganizationBranding(organizationId, language)
+		if branding == nil {
+			c.ResponseError(c.T("check:Organization does not exist"))
+			return
+		}
+	}
+
+	c.ResponseOk(branding)
+}
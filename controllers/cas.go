@@ -91,11 +91,11 @@ func (c *RootController) CasP3ServiceAndProxyValidate() {
 	// find the token
 	if ok {
 		// check whether service is the one for which we previously issued token
-		if strings.HasPrefix(service, issuedService) {
+		if reason := object.CheckCasService(issuedService, service); reason == "" {
 			serviceResponse.Success = response
 		} else {
 			// service not match
-			c.sendCasAuthenticationResponseErr(InvalidService, fmt.Sprintf("service %s and %s does not match", service, issuedService), format)
+			c.sendCasAuthenticationResponseErr(InvalidService, fmt.Sprintf("service %s and %s does not match: %s", service, issuedService, object.TranslateRedirectUriReason(c.GetAcceptLanguage(), reason)), format)
 			return
 		}
 	} else {
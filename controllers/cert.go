@@ -58,7 +58,10 @@ func (c *ApiController) GetCerts() {
 func (c *ApiController) GetCert() {
 	id := c.Input().Get("id")
 
-	c.Data["json"] = object.GetMaskedCert(object.GetCert(id))
+	cert := object.GetCert(id)
+	c.setETag(cert)
+
+	c.Data["json"] = object.GetMaskedCert(cert)
 	c.ServeJSON()
 }
 
@@ -80,8 +83,12 @@ func (c *ApiController) UpdateCert() {
 		return
 	}
 
-	c.Data["json"] = wrapActionResponse(object.UpdateCert(id, &cert))
-	c.ServeJSON()
+	if msg := c.checkCertOrganization(object.GetCert(id), &cert); msg != "" {
+		c.ResponseError(msg)
+		return
+	}
+
+	c.responseConditionalWrite(object.UpdateCertWithPrecondition(id, &cert, c.getPrecondition()))
 }
 
 // AddCert
@@ -99,6 +106,11 @@ func (c *ApiController) AddCert() {
 		return
 	}
 
+	if msg := c.checkCertOrganization(nil, &cert); msg != "" {
+		c.ResponseError(msg)
+		return
+	}
+
 	c.Data["json"] = wrapActionResponse(object.AddCert(&cert))
 	c.ServeJSON()
 }
@@ -121,3 +133,67 @@ func (c *ApiController) DeleteCert() {
 	c.Data["json"] = wrapActionResponse(object.DeleteCert(&cert))
 	c.ServeJSON()
 }
+
+// GenerateCertCsr
+// @Title GenerateCertCsr
+// @Tag Cert API
+// @Description generate a new key pair for the cert and return its certificate signing request, the cert keeps its current key until the signed certificate is uploaded
+// @Param   id     query    string  true        "The id ( owner/name ) of the cert"
+// @Param   body    body   object.CertCsrForm  true        "The subject of the CSR"
+// @Success 200 {object} controllers.Response The Response object
+// @router /generate-cert-csr [post]
+func (c *ApiController) GenerateCertCsr() {
+	id := c.Input().Get("id")
+
+	var form object.CertCsrForm
+	err := json.Unmarshal(c.Ctx.Input.RequestBody, &form)
+	if err != nil {
+		c.ResponseError(err.Error())
+		return
+	}
+
+	cert, err := object.GenerateCertCsr(id, &form)
+	if err != nil {
+		c.ResponseError(err.Error())
+		return
+	}
+
+	c.ResponseOk(cert.Csr)
+}
+
+// UploadCertCertificate
+// @Title UploadCertCertificate
+// @Tag Cert API
+// @Description upload the certificate issued by the CA for the pending CSR (or for the current key) of the cert, as a PEM chain with the certificate first
+// @Param   id     query    string  true        "The id ( owner/name ) of the cert"
+// @Param   body    body   string  true        "The PEM certificate chain"
+// @Success 200 {object} controllers.Response The Response object
+// @router /upload-cert-certificate [post]
+func (c *ApiController) UploadCertCertificate() {
+	id := c.Input().Get("id")
+
+	cert, err := object.UploadCertCertificate(id, string(c.Ctx.Input.RequestBody))
+	if err != nil {
+		c.ResponseError(err.Error())
+		return
+	}
+
+	c.ResponseOk(cert.ExpireTime)
+}
+
+// checkCertOrganization lets only the admins of the organization bind a CA
+// to it or unbind it, as the organization trusts the certificates the CA
+// signs.
+func (c *ApiController) checkCertOrganization(oldCert *object.Cert, cert *object.Cert) string {
+	oldOrganization := ""
+	if oldCert != nil {
+		oldOrganization = oldCert.Organization
+	}
+
+	if cert.Organization != oldOrganization {
+		if (oldOrganization != "" && !c.IsAdminOf(oldOrganization)) || (cert.Organization != "" && !c.IsAdminOf(cert.Organization)) {
+			return c.T("auth:Unauthorized operation")
+		}
+	}
+	return ""
+}
@@ -0,0 +1,68 @@
+// Copyright 2023 The Casdoor Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/casdoor/casdoor/object"
+	"github.com/casdoor/casdoor/util"
+)
+
+// CertificateLogin
+// @Title CertificateLogin
+// @Tag Login API
+// @Description sign in with the TLS client certificate of the request, or the one forwarded by a proxy
+// @Param   form     body    controllers.RequestForm  true    "the application and response type"
+// @Success 200 {object} controllers.Response The Response object
+// @router /certificate-login [post]
+func (c *ApiController) CertificateLogin() {
+	var form RequestForm
+	err := json.Unmarshal(c.Ctx.Input.RequestBody, &form)
+	if err != nil {
+		c.ResponseErrorCode(object.ErrorCodeInvalidRequest, "", "")
+		return
+	}
+	defer func() { c.addLoginStat(&form, "Certificate", false) }()
+
+	application := object.GetApplication(fmt.Sprintf("admin/%s", form.Application))
+	if application == nil {
+		c.ResponseErrorCode(object.ErrorCodeApplicationNotFound, form.Organization, fmt.Sprintf(c.T("auth:The application: %s does not exist"), form.Application))
+		return
+	}
+	form.Organization = application.Organization
+
+	user, err := object.CheckClientCertLogin(application, c.Ctx.Request)
+	if err != nil {
+		c.ResponseErrorCode(object.ErrorCodeInvalidCredentials, form.Organization, err.Error())
+		return
+	}
+	form.Username = user.Name
+	util.LogInfo(c.Ctx, "API: [%s] signed in with a client certificate", user.GetId())
+
+	resp := c.HandleLoggedIn(application, user, &form)
+	if resp == nil {
+		return
+	}
+
+	record := object.NewRecord(c.Ctx)
+	record.Organization = application.Organization
+	record.User = user.Name
+	util.SafeGoroutine(func() { object.AddRecord(record) })
+
+	c.Data["json"] = resp
+	c.ServeJSON()
+}
@@ -0,0 +1,78 @@
+// Copyright 2023 The Casdoor Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers
+
+import (
+	"encoding/json"
+
+	"github.com/casdoor/casdoor/object"
+)
+
+// ExportConfig
+// @Title ExportConfig
+// @Tag Config API
+// @Description export all organizations, certs, providers, applications, models, roles and permissions as a bundle
+// @Param   format     query    string  false        "yaml (default) or json"
+// @Param   includeSecrets     query    string  false        "true to export secrets instead of ***"
+// @Success 200 {object} object.ConfigBundle The Response object
+// @router /export-config [get]
+func (c *ApiController) ExportConfig() {
+	format := c.Input().Get("format")
+	includeSecrets := c.Input().Get("includeSecrets") == "true"
+
+	bundle := object.ExportConfigBundle(includeSecrets)
+
+	var data []byte
+	var err error
+	if format == "json" {
+		c.Ctx.Output.Header("Content-Type", "application/json; charset=utf-8")
+		data, err = json.MarshalIndent(bundle, "", "  ")
+	} else {
+		c.Ctx.Output.Header("Content-Type", "application/yaml; charset=utf-8")
+		data, err = bundle.ToYaml()
+	}
+	if err != nil {
+		c.ResponseError(err.Error())
+		return
+	}
+
+	c.Ctx.Output.Body(data)
+}
+
+// ImportConfig
+// @Title ImportConfig
+// @Tag Config API
+// @Description create or update the objects of a YAML or JSON bundle, objects that already match are skipped
+// @Param   dryRun     query    string  false        "true to only return the plan"
+// @Param   body    body   object.ConfigBundle  true        "The bundle"
+// @Success 200 {array} object.ConfigChange The Response object
+// @router /import-config [post]
+func (c *ApiController) ImportConfig() {
+	dryRun := c.Input().Get("dryRun") == "true"
+
+	bundle, err := object.ParseConfigBundle(c.Ctx.Input.RequestBody)
+	if err != nil {
+		c.ResponseError(err.Error())
+		return
+	}
+
+	changes, err := object.ApplyConfigBundle(bundle, dryRun)
+	if err != nil {
+		c.ResponseError(err.Error(), changes)
+		return
+	}
+
+	c.ResponseOk(changes)
+}
@@ -0,0 +1,77 @@
+// Copyright 2023 The Casdoor Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers
+
+import (
+	"github.com/casdoor/casdoor/object"
+	"github.com/casdoor/casdoor/util"
+)
+
+// ConfirmContactChange
+// @Title ConfirmContactChange
+// @Tag Account API
+// @Description apply a pending change of the email or phone with the link sent to the old address
+// @Param   token   formData    string  true        "The confirm token"
+// @Success 200 {object} controllers.Response The Response object
+// @router /confirm-contact-change [post]
+func (c *ApiController) ConfirmContactChange() {
+	token := c.Ctx.Request.Form.Get("token")
+
+	_, err := object.ConfirmContactChange(token, util.GetIPFromRequest(c.Ctx.Request), c.GetAcceptLanguage())
+	if err != nil {
+		c.ResponseError(err.Error())
+		return
+	}
+
+	c.ResponseOk()
+}
+
+// UndoContactChange
+// @Title UndoContactChange
+// @Tag Account API
+// @Description cancel a pending change of the email or phone or restore the old value with the link sent to the old address, the user is signed out everywhere
+// @Param   token   formData    string  true        "The undo token"
+// @Success 200 {object} controllers.Response The Response object
+// @router /undo-contact-change [post]
+func (c *ApiController) UndoContactChange() {
+	token := c.Ctx.Request.Form.Get("token")
+
+	_, err := object.UndoContactChange(token, util.GetIPFromRequest(c.Ctx.Request), c.GetAcceptLanguage())
+	if err != nil {
+		c.ResponseError(err.Error())
+		return
+	}
+
+	c.ResponseOk()
+}
+
+// GetContactChanges
+// @Title GetContactChanges
+// @Tag User API
+// @Description get the changes of the email and phone of a user
+// @Param   id     query    string  true        "The id ( owner/name ) of the user"
+// @Success 200 {array} object.ContactChange The Response object
+// @router /get-contact-changes [get]
+func (c *ApiController) GetContactChanges() {
+	id := c.Input().Get("id")
+
+	owner, name := util.GetOwnerAndNameFromIdNoCheck(id)
+	if !c.IsAdminOf(owner) && c.GetSessionUsername() != id {
+		c.ResponseError(c.T("auth:Unauthorized operation"))
+		return
+	}
+
+	c.ResponseOk(object.GetContactChanges(owner, name))
+}
@@ -0,0 +1,171 @@
+// Copyright 2023 The Casdoor Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers
+
+import (
+	"encoding/json"
+
+	"github.com/beego/beego/utils/pagination"
+	"github.com/casdoor/casdoor/object"
+	"github.com/casdoor/casdoor/util"
+)
+
+func (c *ApiController) getDeviceCredentials(secret string) *object.DeviceCredentials {
+	return &object.DeviceCredentials{
+		Secret:  secret,
+		Request: c.Ctx.Request,
+	}
+}
+
+// GetDevices
+// @Title GetDevices
+// @Tag Device API
+// @Description get the devices of an organization
+// @Param   owner     query    string  true        "The organization"
+// @Success 200 {array} object.Device The Response object
+// @router /get-devices [get]
+func (c *ApiController) GetDevices() {
+	owner := c.Input().Get("owner")
+	limit := c.Input().Get("pageSize")
+	page := c.Input().Get("p")
+	field := c.Input().Get("field")
+	value := c.Input().Get("value")
+	sortField := c.Input().Get("sortField")
+	sortOrder := c.Input().Get("sortOrder")
+	if limit == "" || page == "" {
+		c.ResponseOk(object.GetDevices(owner))
+	} else {
+		limit := util.ParseInt(limit)
+		paginator := pagination.SetPaginator(c.Ctx, limit, int64(object.GetDeviceCount(owner, field, value)))
+		devices := object.GetPaginationDevices(owner, paginator.Offset(), limit, field, value, sortField, sortOrder)
+		c.ResponseOk(devices, paginator.Nums())
+	}
+}
+
+// GetDevice
+// @Title GetDevice
+// @Tag Device API
+// @Description get device
+// @Param   id     query    string  true        "The id ( owner/name ) of the device"
+// @Success 200 {object} object.Device The Response object
+// @router /get-device [get]
+func (c *ApiController) GetDevice() {
+	id := c.Input().Get("id")
+
+	c.ResponseOk(object.GetDevice(id))
+}
+
+// UpdateDevice
+// @Title UpdateDevice
+// @Tag Device API
+// @Description update the display name, type, application, serial number and tags of a device
+// @Param   id     query    string  true        "The id ( owner/name ) of the device"
+// @Param   body    body   object.Device  true        "The details of the device"
+// @Success 200 {object} controllers.Response The Response object
+// @router /update-device [post]
+func (c *ApiController) UpdateDevice() {
+	id := c.Input().Get("id")
+
+	var device object.Device
+	err := json.Unmarshal(c.Ctx.Input.RequestBody, &device)
+	if err != nil {
+		c.ResponseError(err.Error())
+		return
+	}
+
+	c.Data["json"] = wrapActionResponse(object.UpdateDevice(id, &device))
+	c.ServeJSON()
+}
+
+// DeleteDevice
+// @Title DeleteDevice
+// @Tag Device API
+// @Description delete a device and expire its tokens
+// @Param   body    body   object.Device  true        "The details of the device"
+// @Success 200 {object} controllers.Response The Response object
+// @router /delete-device [post]
+func (c *ApiController) DeleteDevice() {
+	var device object.Device
+	err := json.Unmarshal(c.Ctx.Input.RequestBody, &device)
+	if err != nil {
+		c.ResponseError(err.Error())
+		return
+	}
+
+	c.Data["json"] = wrapActionResponse(object.DeleteDevice(&device))
+	c.ServeJSON()
+}
+
+// RegisterDevices
+// @Title RegisterDevices
+// @Tag Device API
+// @Description register a batch of devices of an organization and issue their credentials, which are only returned here
+// @Param   owner     query    string  true        "The organization"
+// @Param   body    body   object.DeviceRegistrationForm  true        "The names, the application and the credential type of the devices"
+// @Success 200 {array} object.DeviceCredential The Response object
+// @router /register-devices [post]
+func (c *ApiController) RegisterDevices() {
+	owner := c.Input().Get("owner")
+
+	var form object.DeviceRegistrationForm
+	err := json.Unmarshal(c.Ctx.Input.RequestBody, &form)
+	if err != nil {
+		c.ResponseError(err.Error())
+		return
+	}
+
+	credentials, err := object.RegisterDevices(owner, &form)
+	if err != nil {
+		c.ResponseError(err.Error())
+		return
+	}
+
+	c.ResponseOk(credentials)
+}
+
+// IssueDeviceCredential
+// @Title IssueDeviceCredential
+// @Tag Device API
+// @Description issue a new secret or certificate to a device, the previous one stops working and its tokens expire
+// @Param   id     query    string  true        "The id ( owner/name ) of the device"
+// @Param   credentialType     query    string  true        "Secret or Certificate"
+// @Success 200 {object} object.DeviceCredential The Response object
+// @router /issue-device-credential [post]
+func (c *ApiController) IssueDeviceCredential() {
+	id := c.Input().Get("id")
+	credentialType := c.Input().Get("credentialType")
+
+	credential, err := object.IssueDeviceCredential(id, credentialType)
+	if err != nil {
+		c.ResponseError(err.Error())
+		return
+	}
+
+	c.ResponseOk(credential)
+}
+
+// RevokeDevice
+// @Title RevokeDevice
+// @Tag Device API
+// @Description revoke a device for good and expire all its tokens
+// @Param   id     query    string  true        "The id ( owner/name ) of the device"
+// @Success 200 {object} controllers.Response The Response object
+// @router /revoke-device [post]
+func (c *ApiController) RevokeDevice() {
+	id := c.Input().Get("id")
+
+	c.Data["json"] = wrapActionResponse(object.RevokeDevice(id))
+	c.ServeJSON()
+}
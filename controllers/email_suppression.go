@@ -0,0 +1,119 @@
+// Copyright 2023 The Casdoor Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/beego/beego/utils/pagination"
+	"github.com/casdoor/casdoor/object"
+	"github.com/casdoor/casdoor/util"
+)
+
+// GetEmailSuppressions
+// @Title GetEmailSuppressions
+// @Tag Email Suppression API
+// @Description get the addresses no email is sent to with the providers of the owner
+// @Param   owner     query    string  true        "The owner of the email providers"
+// @Success 200 {array} object.EmailSuppression The Response object
+// @router /get-email-suppressions [get]
+func (c *ApiController) GetEmailSuppressions() {
+	owner := c.Input().Get("owner")
+	limit := c.Input().Get("pageSize")
+	page := c.Input().Get("p")
+	field := c.Input().Get("field")
+	value := c.Input().Get("value")
+	sortField := c.Input().Get("sortField")
+	sortOrder := c.Input().Get("sortOrder")
+	if limit == "" || page == "" {
+		c.ResponseOk(object.GetEmailSuppressions(owner))
+	} else {
+		limit := util.ParseInt(limit)
+		paginator := pagination.SetPaginator(c.Ctx, limit, int64(object.GetEmailSuppressionCount(owner, field, value)))
+		emailSuppressions := object.GetPaginationEmailSuppressions(owner, paginator.Offset(), limit, field, value, sortField, sortOrder)
+		c.ResponseOk(emailSuppressions, paginator.Nums())
+	}
+}
+
+// AddEmailSuppression
+// @Title AddEmailSuppression
+// @Tag Email Suppression API
+// @Description add an address to the suppression list, the name is the address
+// @Param   body    body   object.EmailSuppression  true        "The details of the email suppression"
+// @Success 200 {object} controllers.Response The Response object
+// @router /add-email-suppression [post]
+func (c *ApiController) AddEmailSuppression() {
+	var emailSuppression object.EmailSuppression
+	err := json.Unmarshal(c.Ctx.Input.RequestBody, &emailSuppression)
+	if err != nil {
+		c.ResponseError(err.Error())
+		return
+	}
+
+	if !util.IsEmailValid(emailSuppression.Name) {
+		c.ResponseError(c.T("check:Email is invalid"))
+		return
+	}
+	if object.IsEmailSuppressed(emailSuppression.Owner, emailSuppression.Name) {
+		c.ResponseError(c.T("provider:The email address is already on the suppression list"))
+		return
+	}
+
+	emailSuppression.Type = object.EmailSuppressionTypeManual
+	emailSuppression.CreatedTime = ""
+	c.Data["json"] = wrapActionResponse(object.AddEmailSuppression(&emailSuppression))
+	c.ServeJSON()
+}
+
+// DeleteEmailSuppression
+// @Title DeleteEmailSuppression
+// @Tag Email Suppression API
+// @Description remove an address from the suppression list
+// @Param   body    body   object.EmailSuppression  true        "The details of the email suppression"
+// @Success 200 {object} controllers.Response The Response object
+// @router /delete-email-suppression [post]
+func (c *ApiController) DeleteEmailSuppression() {
+	var emailSuppression object.EmailSuppression
+	err := json.Unmarshal(c.Ctx.Input.RequestBody, &emailSuppression)
+	if err != nil {
+		c.ResponseError(err.Error())
+		return
+	}
+
+	c.Data["json"] = wrapActionResponse(object.DeleteEmailSuppression(&emailSuppression))
+	c.ServeJSON()
+}
+
+// EmailWebhook
+// @Title EmailWebhook
+// @Tag Email Suppression API
+// @Description receive the bounces and complaints of a SendGrid, Mailgun or AWS SES provider, the URL is /api/email-webhook/{owner}/{provider}
+// @Success 200 {object} controllers.Response The Response object
+// @router /email-webhook [post]
+func (c *ApiController) EmailWebhook() {
+	owner := c.Ctx.Input.Param(":owner")
+	providerName := c.Ctx.Input.Param(":provider")
+
+	err := object.HandleEmailWebhook(c.Ctx.Request, c.Ctx.Input.RequestBody, owner, providerName)
+	if err != nil {
+		// a failed webhook is retried by the provider
+		c.Ctx.Output.SetStatus(http.StatusBadRequest)
+		c.ResponseError(err.Error())
+		return
+	}
+
+	c.ResponseOk()
+}
@@ -32,6 +32,14 @@ func (c *ApiController) Enforce() {
 	c.ServeJSON()
 }
 
+// BatchEnforce
+// @Title BatchEnforce
+// @Tag Enforcer API
+// @Description enforce many requests in one call, each with the permission of its ID. With explain=true every result has the matched policy and the errors of single requests are returned instead of failing the call
+// @Param   explain    query    string  false        "true to explain the decisions"
+// @Param   body    body   []object.PermissionRule  true        "The requests with the permission IDs"
+// @Success 200 {array} object.EnforceResult The Response object
+// @router /batch-enforce [post]
 func (c *ApiController) BatchEnforce() {
 	var permissionRules []object.PermissionRule
 	err := json.Unmarshal(c.Ctx.Input.RequestBody, &permissionRules)
@@ -40,6 +48,18 @@ func (c *ApiController) BatchEnforce() {
 		return
 	}
 
+	if c.Input().Get("explain") == "true" {
+		c.Data["json"] = object.BatchEnforceEx(permissionRules)
+		c.ServeJSON()
+		return
+	}
+
+	if len(permissionRules) == 0 {
+		c.Data["json"] = []bool{}
+		c.ServeJSON()
+		return
+	}
+
 	c.Data["json"] = object.BatchEnforce(permissionRules)
 	c.ServeJSON()
 }
@@ -76,3 +96,13 @@ func (c *ApiController) GetAllRoles() {
 	c.Data["json"] = object.GetAllRoles(userId)
 	c.ServeJSON()
 }
+
+// GetEnforcerMetrics
+// @Title GetEnforcerMetrics
+// @Tag Enforcer API
+// @Description get the hit rate of the enforcer and decision caches and the decision latency (in milliseconds) of this node
+// @Success 200 {object} object.EnforcerMetrics The Response object
+// @router /get-enforcer-metrics [get]
+func (c *ApiController) GetEnforcerMetrics() {
+	c.ResponseOk(object.GetEnforcerMetrics())
+}
@@ -0,0 +1,30 @@
+// Copyright 2023 The Casdoor Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers
+
+import "github.com/casdoor/casdoor/object"
+
+// GetErrorCodes
+// @Title GetErrorCodes
+// @Tag Login API
+// @Description get the error code catalog with the messages of the organization
+// @Param   organization     query    string  false        "The organization, empty for the default messages"
+// @Success 200 {array} object.ErrorCodeInfo The Response object
+// @router /get-error-codes [get]
+func (c *ApiController) GetErrorCodes() {
+	organization := c.Input().Get("organization")
+
+	c.ResponseOk(object.GetErrorCodeInfos(organization, c.GetAcceptLanguage()))
+}
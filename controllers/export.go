@@ -0,0 +1,153 @@
+// Copyright 2023 The Casdoor Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers
+
+import (
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/beego/beego/logs"
+	"github.com/casdoor/casdoor/object"
+)
+
+// exportResponseWriter tells whether the export has started to be sent, an
+// error before that is still returned as JSON.
+type exportResponseWriter struct {
+	io.Writer
+	written bool
+}
+
+func (w *exportResponseWriter) Write(p []byte) (int, error) {
+	w.written = true
+	return w.Writer.Write(p)
+}
+
+func (c *ApiController) getExportFilter() *object.ExportFilter {
+	return &object.ExportFilter{
+		Field:     c.Input().Get("field"),
+		Value:     c.Input().Get("value"),
+		StartTime: c.Input().Get("startTime"),
+		EndTime:   c.Input().Get("endTime"),
+	}
+}
+
+// serveExport streams the export as an attachment, the rows are flushed to
+// the client as they are read instead of being built in memory.
+func (c *ApiController) serveExport(name string, format string, export func(w io.Writer, flush func()) (int, error)) {
+	c.Ctx.Output.Header("Content-Type", object.GetExportContentType(format))
+	c.Ctx.Output.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%s-%s.%s", name, time.Now().UTC().Format("20060102-150405"), format))
+	c.Ctx.Output.Header("X-Content-Type-Options", "nosniff")
+
+	w := &exportResponseWriter{Writer: c.Ctx.ResponseWriter}
+	count, err := export(w, c.Ctx.ResponseWriter.Flush)
+	if err != nil {
+		if !w.written {
+			c.ResponseError(err.Error())
+			return
+		}
+
+		// the status is already sent, the client gets a truncated file
+		logs.Error(fmt.Sprintf("the export of %s stopped after %d rows, %s", name, count, err.Error()))
+	}
+}
+
+// ExportUsers
+// @Title ExportUsers
+// @Tag User API
+// @Description export the users of an organization as NDJSON or CSV, streamed as they are read so that exports of any size don't build up in memory
+// @Param   owner     query    string  true        "The owner of the users, empty for all organizations"
+// @Param   format    query    string  false       "ndjson (default) or csv"
+// @Param   field     query    string  false       "The field to filter on"
+// @Param   value     query    string  false       "The value the field contains"
+// @Param   startTime query    string  false       "The earliest created time (RFC3339)"
+// @Param   endTime   query    string  false       "The latest created time (RFC3339)"
+// @Success 200 {string} string "The NDJSON or CSV file"
+// @router /export-users [get]
+func (c *ApiController) ExportUsers() {
+	owner := c.Input().Get("owner")
+	format := c.Input().Get("format")
+	if format == "" {
+		format = object.ExportFormatNdjson
+	}
+	if !object.IsExportFormatValid(format) {
+		c.ResponseError(fmt.Sprintf(c.T("general:Unknown format: %s"), format))
+		return
+	}
+	if owner == "" && !c.IsGlobalAdmin() {
+		c.ResponseError(c.T("auth:Unauthorized operation"))
+		return
+	}
+
+	filter := c.getExportFilter()
+	name := owner
+	if name == "" {
+		name = "users"
+	}
+	c.serveExport(name, format, func(w io.Writer, flush func()) (int, error) {
+		return object.ExportUsers(w, flush, format, owner, filter)
+	})
+}
+
+// ExportRecords
+// @Title ExportRecords
+// @Tag Record API
+// @Description export the audit records as NDJSON or CSV, streamed as they are read, the admins of an organization get the records of their organization
+// @Param   organization query    string  false       "The organization of the records, for the global admins"
+// @Param   user         query    string  false       "The user of the records"
+// @Param   action       query    string  false       "The action of the records, like login or update-user"
+// @Param   format       query    string  false       "ndjson (default) or csv"
+// @Param   field        query    string  false       "The field to filter on"
+// @Param   value        query    string  false       "The value the field contains"
+// @Param   startTime    query    string  false       "The earliest created time (RFC3339)"
+// @Param   endTime      query    string  false       "The latest created time (RFC3339)"
+// @Success 200 {string} string "The NDJSON or CSV file"
+// @router /export-records [get]
+func (c *ApiController) ExportRecords() {
+	organization, ok := c.RequireAdmin()
+	if !ok {
+		return
+	}
+
+	format := c.Input().Get("format")
+	if format == "" {
+		format = object.ExportFormatNdjson
+	}
+	if !object.IsExportFormatValid(format) {
+		c.ResponseError(fmt.Sprintf(c.T("general:Unknown format: %s"), format))
+		return
+	}
+	if organization == "" {
+		organization = c.Input().Get("organization")
+	}
+	if !c.IsAdminOf(organization) {
+		c.ResponseError(c.T("auth:Unauthorized operation"))
+		return
+	}
+
+	filterRecord := &object.Record{
+		Organization: organization,
+		User:         c.Input().Get("user"),
+		Action:       c.Input().Get("action"),
+	}
+	filter := c.getExportFilter()
+	name := "records"
+	if organization != "" {
+		name = fmt.Sprintf("records-%s", organization)
+	}
+	c.serveExport(name, format, func(w io.Writer, flush func()) (int, error) {
+		return object.ExportRecords(w, flush, format, filterRecord, filter)
+	})
+}
@@ -0,0 +1,143 @@
+// Copyright 2023 The Casdoor Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/casdoor/casdoor/object"
+	"github.com/casdoor/casdoor/util"
+)
+
+// ForwardAuth
+// @Title ForwardAuth
+// @Tag Login API
+// @Description forward-auth endpoint for Traefik, Caddy and nginx auth_request. It returns 200 with the X-User, X-Email and X-Roles headers for a signed-in user. Otherwise it redirects to the login page when the proxy sends X-Forwarded-Uri, and returns 401 with the login page in the X-Login-Url header for nginx. The proxy must route /api/forward-auth/callback of the protected host to Casdoor and the callback URL must be a redirect URL of the application. The login sets a nonce cookie for the callback, nginx must copy the Set-Cookie header of the 401 response to its redirect.
+// @Param   application     query    string  true        "The id ( owner/name ) of the application"
+// @Success 200 {string} string
+// @router /forward-auth [get]
+func (c *ApiController) ForwardAuth() {
+	applicationId := c.Input().Get("application")
+	application := object.GetApplication(applicationId)
+	if application == nil {
+		c.Ctx.Output.SetStatus(http.StatusBadRequest)
+		c.ResponseError(fmt.Sprintf(c.T("auth:The application: %s does not exist"), applicationId))
+		return
+	}
+
+	userId := c.GetSessionUsername()
+	var err error
+	if accessToken := object.GetForwardAuthAccessToken(c.Ctx.Request); accessToken != "" {
+		userId, err = object.GetForwardAuthUserIdByToken(application, accessToken)
+	}
+
+	if userId != "" && err == nil {
+		var user *object.User
+		user, err = object.CheckForwardAuthUser(application, userId)
+		if err == nil {
+			for key, value := range object.GetForwardAuthHeaders(user) {
+				c.Ctx.Output.Header(key, value)
+			}
+			c.ResponseOk()
+			return
+		}
+
+		// a signed-in user without access is not sent to the login page again
+		c.Ctx.Output.SetStatus(http.StatusForbidden)
+		c.ResponseError(err.Error())
+		return
+	}
+
+	forwardedUrl := object.GetForwardedUrl(c.Ctx.Request.Header)
+	loginUrl, nonce, urlErr := object.GetForwardAuthLoginUrl(application, forwardedUrl, c.Ctx.Request.Host)
+	if urlErr != nil {
+		c.Ctx.Output.SetStatus(http.StatusUnauthorized)
+		c.ResponseError(urlErr.Error())
+		return
+	}
+	c.Ctx.SetCookie(object.ForwardAuthNonceCookie, nonce, object.ForwardAuthNonceMaxAge, object.ForwardAuthCallbackPath, "", strings.HasPrefix(forwardedUrl, "https://"), true)
+
+	if c.Ctx.Request.Header.Get("X-Forwarded-Uri") != "" {
+		c.Redirect(loginUrl, http.StatusFound)
+		return
+	}
+
+	c.Ctx.Output.Header("X-Login-Url", loginUrl)
+	c.Ctx.Output.SetStatus(http.StatusUnauthorized)
+	if err != nil {
+		c.ResponseError(err.Error())
+	} else {
+		c.ResponseError(c.T("general:Please login first"))
+	}
+}
+
+// ForwardAuthCallback
+// @Title ForwardAuthCallback
+// @Tag Login API
+// @Description redirect URL of the forward-auth login, served under the protected host. It exchanges the code for an access token, keeps it in a cookie of that host and returns to the page that was asked for
+// @Param   code     query    string  true        "The authorization code"
+// @Param   state     query    string  true        "The state"
+// @Success 302 {string} string
+// @router /forward-auth/callback [get]
+func (c *ApiController) ForwardAuthCallback() {
+	state, err := object.DecodeForwardAuthState(c.Input().Get("state"))
+	if err != nil {
+		c.ResponseError(err.Error())
+		return
+	}
+
+	application := object.GetApplication(state.Application)
+	if application == nil {
+		c.ResponseError(fmt.Sprintf(c.T("auth:The application: %s does not exist"), state.Application))
+		return
+	}
+
+	err = object.CheckForwardAuthState(c.Input().Get("state"), state, application, c.Ctx.GetCookie(object.ForwardAuthNonceCookie))
+	if err != nil {
+		c.ResponseError(err.Error())
+		return
+	}
+	c.Ctx.SetCookie(object.ForwardAuthNonceCookie, "", -1, object.ForwardAuthCallbackPath)
+
+	host := c.Ctx.Request.Header.Get("X-Forwarded-Host")
+	if host == "" {
+		host = c.Ctx.Request.Host
+	}
+	if !object.IsForwardAuthRedirectAllowed(state.Url, host) {
+		c.ResponseError(fmt.Sprintf(c.T("auth:Redirect URI: %s doesn't exist in the allowed Redirect URI list"), state.Url))
+		return
+	}
+
+	callbackUrl, err := object.GetForwardAuthCallbackUrl(state.Url)
+	if err != nil {
+		c.ResponseError(err.Error())
+		return
+	}
+
+	res := object.GetOAuthToken("authorization_code", application.ClientId, application.ClientSecret, c.Input().Get("code"), "", callbackUrl, "", "", "", c.Ctx.Request.Host, "", "", "", "", "", nil, nil, c.GetAcceptLanguage())
+	tokenWrapper, ok := res.(*object.TokenWrapper)
+	if !ok {
+		c.Ctx.Output.SetStatus(http.StatusUnauthorized)
+		c.Data["json"] = res
+		c.ServeJSON()
+		return
+	}
+	util.SafeGoroutine(func() { object.AddTokenStat(application.ClientId, object.TokenStatEventIssue) })
+
+	c.Ctx.SetCookie(object.ForwardAuthCookie, tokenWrapper.AccessToken, tokenWrapper.ExpiresIn, "/", "", strings.HasPrefix(state.Url, "https://"), true)
+	c.Redirect(state.Url, http.StatusFound)
+}
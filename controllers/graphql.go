@@ -0,0 +1,52 @@
+// Copyright 2023 The Casdoor Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers
+
+import (
+	"encoding/json"
+
+	"github.com/casdoor/casdoor/graph"
+)
+
+// GraphQL
+// @Title GraphQL
+// @Tag GraphQL API
+// @Description run a GraphQL query against the management plane, each field is authorized like its REST endpoint
+// @Param   body    body   graph.Request  true        "The query, operationName and variables"
+// @Success 200 {object} graphql.Result The Response object
+// @router /graphql [post]
+func (c *ApiController) GraphQL() {
+	var request graph.Request
+	if c.Ctx.Request.Method == "GET" {
+		request.Query = c.Input().Get("query")
+		request.OperationName = c.Input().Get("operationName")
+		if variables := c.Input().Get("variables"); variables != "" {
+			err := json.Unmarshal([]byte(variables), &request.Variables)
+			if err != nil {
+				c.ResponseError(err.Error())
+				return
+			}
+		}
+	} else {
+		err := json.Unmarshal(c.Ctx.Input.RequestBody, &request)
+		if err != nil {
+			c.ResponseError(err.Error())
+			return
+		}
+	}
+
+	c.Data["json"] = graph.Execute(&request, c.GetSessionUsername(), c.GetAcceptLanguage())
+	c.ServeJSON()
+}
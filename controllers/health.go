@@ -0,0 +1,57 @@
+// Copyright 2023 The Casdoor Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers
+
+import (
+	"net/http"
+
+	"github.com/casdoor/casdoor/object"
+)
+
+// GetLiveness
+// @Title GetLiveness
+// @Tag Health API
+// @Description liveness probe, succeeds as long as the process serves requests
+// @Success 200 {object} object.Health The Response object
+// @router /health/live [get]
+func (c *ApiController) GetLiveness() {
+	c.Data["json"] = &object.Health{Status: object.HealthStatusUp, Node: object.GetNodeName(), Components: []*object.HealthComponent{}}
+	c.ServeJSON()
+}
+
+// GetReadiness
+// @Title GetReadiness
+// @Tag Health API
+// @Description readiness probe, checks the database, the session store, the certs in use and whether the node drains, 503 when any of them is down, the messages are only shown to global admins
+// @Success 200 {object} object.Health The Response object
+// @Failure 503 {object} object.Health The Response object
+// @router /health/ready [get]
+func (c *ApiController) GetReadiness() {
+	health := object.GetReadiness()
+	if health.Status == object.HealthStatusDown {
+		c.Ctx.Output.SetStatus(http.StatusServiceUnavailable)
+	}
+
+	// the messages tell the errors of the database, the names of the certs
+	// and the like
+	if !c.IsGlobalAdmin() {
+		for _, component := range health.Components {
+			component.Message = ""
+		}
+	}
+
+	c.Data["json"] = health
+	c.ServeJSON()
+}
@@ -0,0 +1,137 @@
+// Copyright 2023 The Casdoor Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers
+
+import (
+	"net/http"
+
+	"github.com/beego/beego/utils/pagination"
+	"github.com/casdoor/casdoor/object"
+	"github.com/casdoor/casdoor/util"
+)
+
+// GetIdentityVerifications
+// @Title GetIdentityVerifications
+// @Tag Identity Verification API
+// @Description get the identity verifications of an organization
+// @Param   owner     query    string  true        "The owner of identity verifications"
+// @Success 200 {array} object.IdentityVerification The Response object
+// @router /get-identity-verifications [get]
+func (c *ApiController) GetIdentityVerifications() {
+	owner := c.Input().Get("owner")
+	limit := c.Input().Get("pageSize")
+	page := c.Input().Get("p")
+	field := c.Input().Get("field")
+	value := c.Input().Get("value")
+	sortField := c.Input().Get("sortField")
+	sortOrder := c.Input().Get("sortOrder")
+	if limit == "" || page == "" {
+		c.Data["json"] = object.GetIdentityVerifications(owner)
+		c.ServeJSON()
+	} else {
+		limit := util.ParseInt(limit)
+		paginator := pagination.SetPaginator(c.Ctx, limit, int64(object.GetIdentityVerificationCount(owner, field, value)))
+		verifications := object.GetPaginationIdentityVerifications(owner, paginator.Offset(), limit, field, value, sortField, sortOrder)
+		c.ResponseOk(verifications, paginator.Nums())
+	}
+}
+
+// GetIdentityVerification
+// @Title GetIdentityVerification
+// @Tag Identity Verification API
+// @Description get an identity verification
+// @Param   id     query    string  true        "The id ( owner/name ) of the identity verification"
+// @Success 200 {object} object.IdentityVerification The Response object
+// @router /get-identity-verification [get]
+func (c *ApiController) GetIdentityVerification() {
+	id := c.Input().Get("id")
+
+	c.ResponseOk(object.GetIdentityVerification(id))
+}
+
+// GetUserIdentityVerifications
+// @Title GetUserIdentityVerifications
+// @Tag Identity Verification API
+// @Description get the identity verifications of the signed-in user
+// @Success 200 {array} object.IdentityVerification The Response object
+// @router /get-user-identity-verifications [get]
+func (c *ApiController) GetUserIdentityVerifications() {
+	user, ok := c.RequireSignedInUser()
+	if !ok {
+		return
+	}
+
+	c.ResponseOk(object.GetUserIdentityVerifications(user))
+}
+
+// StartIdentityVerification
+// @Title StartIdentityVerification
+// @Tag Identity Verification API
+// @Description start an identity verification of the signed-in user, data is the URL to send the user to
+// @Param   application     query    string  false       "The id ( owner/name ) of the application, the signup application of the user by default"
+// @Param   provider     query    string  false       "The id ( owner/name ) of the provider, the one of the application by default"
+// @Param   returnUrl     query    string  true        "Where the user comes back to"
+// @Success 200 {object} controllers.Response The Response object
+// @router /start-identity-verification [post]
+func (c *ApiController) StartIdentityVerification() {
+	user, ok := c.RequireSignedInUser()
+	if !ok {
+		return
+	}
+
+	applicationId := c.Input().Get("application")
+	providerId := c.Input().Get("provider")
+	returnUrl := c.Input().Get("returnUrl")
+
+	if applicationId == "" {
+		applicationId = util.GetId("admin", user.SignupApplication)
+	}
+	application := object.GetApplication(applicationId)
+
+	provider := object.GetIdentityVerificationProvider(application, user, providerId)
+	if provider == nil {
+		c.ResponseError(c.T("identity:No identity verification provider is configured"))
+		return
+	}
+
+	verification, err := object.StartIdentityVerification(user, application, provider, returnUrl, c.Ctx.Request.Host)
+	if err != nil {
+		c.ResponseError(err.Error())
+		return
+	}
+
+	c.ResponseOk(verification.Url, verification.Name)
+}
+
+// IdentityVerificationWebhook
+// @Title IdentityVerificationWebhook
+// @Tag Identity Verification API
+// @Description receive the results of an identity verification provider, the URL is /api/identity-verification-webhook/{owner}/{provider}
+// @Success 200 {object} controllers.Response The Response object
+// @router /identity-verification-webhook [post]
+func (c *ApiController) IdentityVerificationWebhook() {
+	owner := c.Ctx.Input.Param(":owner")
+	providerName := c.Ctx.Input.Param(":provider")
+
+	err := object.HandleIdentityVerificationWebhook(c.Ctx.Request, c.Ctx.Input.RequestBody, owner, providerName)
+	if err != nil {
+		// a failed webhook is retried by the provider
+		c.Ctx.Output.SetStatus(http.StatusBadRequest)
+		c.ResponseError(err.Error())
+		return
+	}
+
+	c.ResponseOk()
+}
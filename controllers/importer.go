@@ -0,0 +1,57 @@
+// Copyright 2023 The Casdoor Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers
+
+import (
+	"fmt"
+
+	"github.com/casdoor/casdoor/object"
+)
+
+// ImportFromIdp
+// @Title ImportFromIdp
+// @Tag User API
+// @Description import the users, roles and applications of a Keycloak realm export, an Auth0 user export or an Okta users export, existing objects are skipped
+// @Param   type     query    string  true        "Keycloak, Auth0 or Okta"
+// @Param   organization     query    string  false        "The target organization, defaults to the realm for Keycloak"
+// @Param   dryRun     query    string  false        "true to only return the summary"
+// @Param   body    body   string  true        "The export file"
+// @Success 200 {object} object.ImportSummary The Response object
+// @router /import-from-idp [post]
+func (c *ApiController) ImportFromIdp() {
+	typ := c.Input().Get("type")
+	organization := c.Input().Get("organization")
+	dryRun := c.Input().Get("dryRun") == "true"
+
+	importer := object.GetImporter(typ)
+	if importer == nil {
+		c.ResponseError(fmt.Sprintf(c.T("user_upload:The import type: %s is not supported"), typ))
+		return
+	}
+
+	result, err := importer.Import(c.Ctx.Input.RequestBody, organization)
+	if err != nil {
+		c.ResponseError(err.Error())
+		return
+	}
+
+	summary, err := object.ApplyImportResult(result, dryRun, c.GetAcceptLanguage())
+	if err != nil {
+		c.ResponseError(err.Error())
+		return
+	}
+
+	c.ResponseOk(summary)
+}
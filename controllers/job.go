@@ -0,0 +1,45 @@
+// Copyright 2023 The Casdoor Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers
+
+import (
+	"github.com/casdoor/casdoor/object"
+)
+
+// GetJobs
+// @Title GetJobs
+// @Tag Job API
+// @Description get the cluster-wide status of the background jobs, data2 is the name of the node that answered
+// @Param   owner     query    string  true        "The owner of jobs"
+// @Success 200 {array} object.Job The Response object
+// @router /get-jobs [get]
+func (c *ApiController) GetJobs() {
+	owner := c.Input().Get("owner")
+
+	c.ResponseOk(object.GetJobs(owner), object.GetNodeName())
+}
+
+// GetJob
+// @Title GetJob
+// @Tag Job API
+// @Description get the status of a background job
+// @Param   id     query    string  true        "The id ( owner/name ) of the job"
+// @Success 200 {object} object.Job The Response object
+// @router /get-job [get]
+func (c *ApiController) GetJob() {
+	id := c.Input().Get("id")
+
+	c.ResponseOk(object.GetJob(id))
+}
@@ -0,0 +1,89 @@
+// Copyright 2023 The Casdoor Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers
+
+import (
+	"github.com/casdoor/casdoor/conf"
+	"github.com/casdoor/casdoor/object"
+	"github.com/casdoor/casdoor/util"
+)
+
+const (
+	loginDeviceCookie       = "casdoor_device"
+	loginDeviceCookieMaxAge = 400 * 24 * 3600
+)
+
+// getLoginDeviceId returns the device cookie of the browser, a new one is
+// set for browsers that don't have it yet.
+func (c *ApiController) getLoginDeviceId() string {
+	deviceId := c.Ctx.GetCookie(loginDeviceCookie)
+	if deviceId == "" {
+		deviceId = util.GenerateId()
+	}
+
+	c.Ctx.SetCookie(loginDeviceCookie, deviceId, loginDeviceCookieMaxAge, "/", "", c.Ctx.Input.IsSecure(), true)
+	return deviceId
+}
+
+// checkLoginAlerts records the device of a successful login, the alerts are
+// sent in the background.
+func (c *ApiController) checkLoginAlerts(userId string) {
+	loginContext := &object.LoginContext{
+		DeviceId:  c.getLoginDeviceId(),
+		UserAgent: c.Ctx.Request.UserAgent(),
+		ClientIp:  util.GetIPFromRequest(c.Ctx.Request),
+		Location:  util.GetLocationFromRequest(c.Ctx.Request, conf.GetConfigString("geoHeaders")),
+	}
+	util.SafeGoroutine(func() { object.CheckLoginAlerts(userId, loginContext) })
+}
+
+// GetLoginAlerts
+// @Title GetLoginAlerts
+// @Tag Account API
+// @Description get the alerts of logins from new devices or after an impossible travel of the signed-in user
+// @Success 200 {array} object.LoginAlert The Response object
+// @router /get-login-alerts [get]
+func (c *ApiController) GetLoginAlerts() {
+	user, ok := c.RequireSignedInUser()
+	if !ok {
+		return
+	}
+
+	c.ResponseOk(object.GetUserLoginAlerts(user))
+}
+
+// ConfirmLoginAlert
+// @Title ConfirmLoginAlert
+// @Tag Account API
+// @Description confirm that a login alert of the signed-in user was the user, the device raises no more alerts
+// @Param   id     query    string  true        "The id ( owner/name ) of the login alert"
+// @Success 200 {object} object.LoginAlert The Response object
+// @router /confirm-login-alert [post]
+func (c *ApiController) ConfirmLoginAlert() {
+	id := c.Input().Get("id")
+
+	user, ok := c.RequireSignedInUser()
+	if !ok {
+		return
+	}
+
+	loginAlert, err := object.ConfirmLoginAlert(user, id, c.GetAcceptLanguage())
+	if err != nil {
+		c.ResponseError(err.Error())
+		return
+	}
+
+	c.ResponseOk(loginAlert)
+}
@@ -0,0 +1,135 @@
+// Copyright 2023 The Casdoor Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers
+
+import (
+	"encoding/json"
+
+	"github.com/beego/beego/utils/pagination"
+	"github.com/casdoor/casdoor/object"
+	"github.com/casdoor/casdoor/util"
+)
+
+// GetLoginHooks
+// @Title GetLoginHooks
+// @Tag Login Hook API
+// @Description get login hooks
+// @Param   owner     query    string  true        "The owner of login hooks"
+// @Success 200 {array} object.LoginHook The Response object
+// @router /get-login-hooks [get]
+func (c *ApiController) GetLoginHooks() {
+	owner := c.Input().Get("owner")
+	limit := c.Input().Get("pageSize")
+	page := c.Input().Get("p")
+	field := c.Input().Get("field")
+	value := c.Input().Get("value")
+	sortField := c.Input().Get("sortField")
+	sortOrder := c.Input().Get("sortOrder")
+	if limit == "" || page == "" {
+		c.Data["json"] = object.GetLoginHooks(owner)
+		c.ServeJSON()
+	} else {
+		limit := util.ParseInt(limit)
+		paginator := pagination.SetPaginator(c.Ctx, limit, int64(object.GetLoginHookCount(owner, field, value)))
+		hooks := object.GetPaginationLoginHooks(owner, paginator.Offset(), limit, field, value, sortField, sortOrder)
+		c.ResponseOk(hooks, paginator.Nums())
+	}
+}
+
+// GetLoginHook
+// @Title GetLoginHook
+// @Tag Login Hook API
+// @Description get login hook
+// @Param   id     query    string  true        "The id ( owner/name ) of the login hook"
+// @Success 200 {object} object.LoginHook The Response object
+// @router /get-login-hook [get]
+func (c *ApiController) GetLoginHook() {
+	id := c.Input().Get("id")
+
+	c.Data["json"] = object.GetLoginHook(id)
+	c.ServeJSON()
+}
+
+// UpdateLoginHook
+// @Title UpdateLoginHook
+// @Tag Login Hook API
+// @Description update login hook
+// @Param   id     query    string  true        "The id ( owner/name ) of the login hook"
+// @Param   body    body   object.LoginHook  true        "The details of the login hook"
+// @Success 200 {object} controllers.Response The Response object
+// @router /update-login-hook [post]
+func (c *ApiController) UpdateLoginHook() {
+	id := c.Input().Get("id")
+
+	var hook object.LoginHook
+	err := json.Unmarshal(c.Ctx.Input.RequestBody, &hook)
+	if err != nil {
+		c.ResponseError(err.Error())
+		return
+	}
+
+	affected, err := object.UpdateLoginHook(id, &hook)
+	if err != nil {
+		c.ResponseError(err.Error())
+		return
+	}
+
+	c.Data["json"] = wrapActionResponse(affected)
+	c.ServeJSON()
+}
+
+// AddLoginHook
+// @Title AddLoginHook
+// @Tag Login Hook API
+// @Description add login hook
+// @Param   body    body   object.LoginHook  true        "The details of the login hook"
+// @Success 200 {object} controllers.Response The Response object
+// @router /add-login-hook [post]
+func (c *ApiController) AddLoginHook() {
+	var hook object.LoginHook
+	err := json.Unmarshal(c.Ctx.Input.RequestBody, &hook)
+	if err != nil {
+		c.ResponseError(err.Error())
+		return
+	}
+
+	affected, err := object.AddLoginHook(&hook)
+	if err != nil {
+		c.ResponseError(err.Error())
+		return
+	}
+
+	c.Data["json"] = wrapActionResponse(affected)
+	c.ServeJSON()
+}
+
+// DeleteLoginHook
+// @Title DeleteLoginHook
+// @Tag Login Hook API
+// @Description delete login hook
+// @Param   body    body   object.LoginHook  true        "The details of the login hook"
+// @Success 200 {object} controllers.Response The Response object
+// @router /delete-login-hook [post]
+func (c *ApiController) DeleteLoginHook() {
+	var hook object.LoginHook
+	err := json.Unmarshal(c.Ctx.Input.RequestBody, &hook)
+	if err != nil {
+		c.ResponseError(err.Error())
+		return
+	}
+
+	c.Data["json"] = wrapActionResponse(object.DeleteLoginHook(&hook))
+	c.ServeJSON()
+}
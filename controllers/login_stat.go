@@ -0,0 +1,118 @@
+// Copyright 2023 The Casdoor Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers
+
+import (
+	"fmt"
+
+	"github.com/casdoor/casdoor/conf"
+	"github.com/casdoor/casdoor/object"
+	"github.com/casdoor/casdoor/util"
+)
+
+// getLoginStatProvider names what a login was made with, the provider for
+// logins with a provider.
+func getLoginStatProvider(form *RequestForm) string {
+	if form.Provider != "" {
+		return form.Provider
+	} else if form.Username == "" {
+		return "Session"
+	} else if form.Password == "" {
+		return "Verification code"
+	}
+	return "Password"
+}
+
+// addLoginStat counts the outcome of a login or signup in the login stats,
+// from the response that was served. Logins that still require steps or an
+// organization to be picked aren't counted.
+func (c *ApiController) addLoginStat(form *RequestForm, provider string, isSignup bool) {
+	resp, ok := c.Data["json"].(*Response)
+	if !ok || resp.Data == "RequiredSteps" || resp.Data == "SelectOrganization" {
+		return
+	}
+	event := object.LoginStatEventLogin
+	if resp.Status != "ok" {
+		event = object.LoginStatEventFailure
+	} else if isSignup {
+		event = object.LoginStatEventSignup
+	}
+
+	country := ""
+	if location := util.GetLocationFromRequest(c.Ctx.Request, conf.GetConfigString("geoHeaders")); location != nil {
+		country = location.Country
+	}
+
+	organization, application, clientId := form.Organization, form.Application, form.ClientId
+	util.SafeGoroutine(func() { object.AddLoginStat(organization, application, clientId, provider, country, event) })
+}
+
+func (c *ApiController) getLoginStatParams() (string, []string, bool) {
+	organization, ok := c.requireOrganizationAdmin(c.Input().Get("id"))
+	if !ok {
+		return "", nil, false
+	}
+
+	groupBy, err := object.ParseLoginStatGroupBy(c.Input().Get("groupBy"))
+	if err != nil {
+		c.ResponseError(err.Error())
+		return "", nil, false
+	}
+	return organization, groupBy, true
+}
+
+// GetLoginStats
+// @Title GetLoginStats
+// @Tag Login API
+// @Description get the logins, signups and failed attempts of an organization grouped by day, application, provider or country
+// @Param   id     query    string  true        "The id ( owner/name ) of the organization"
+// @Param   startDate     query    string  false        "The first day, as 2006-01-02"
+// @Param   endDate     query    string  false        "The last day, as 2006-01-02"
+// @Param   groupBy     query    string  false        "The comma-separated dimensions: date, application, provider and country, default is date"
+// @Success 200 {array} object.LoginStatReport The Response object
+// @router /get-login-stats [get]
+func (c *ApiController) GetLoginStats() {
+	organization, groupBy, ok := c.getLoginStatParams()
+	if !ok {
+		return
+	}
+
+	c.ResponseOk(object.GetLoginStats(organization, c.Input().Get("startDate"), c.Input().Get("endDate"), groupBy))
+}
+
+// ExportLoginStats
+// @Title ExportLoginStats
+// @Tag Login API
+// @Description export the login stats of an organization as CSV, with the parameters of get-login-stats
+// @Param   id     query    string  true        "The id ( owner/name ) of the organization"
+// @Success 200 {string} string "The CSV file"
+// @router /export-login-stats [get]
+func (c *ApiController) ExportLoginStats() {
+	organization, groupBy, ok := c.getLoginStatParams()
+	if !ok {
+		return
+	}
+
+	reports := object.GetLoginStats(organization, c.Input().Get("startDate"), c.Input().Get("endDate"), groupBy)
+	data, err := object.ExportLoginStats(reports, groupBy)
+	if err != nil {
+		c.ResponseError(err.Error())
+		return
+	}
+
+	c.Ctx.Output.Header("Content-Type", "text/csv; charset=utf-8")
+	c.Ctx.Output.Header("Content-Disposition", fmt.Sprintf("attachment; filename=login-stats-%s.csv", organization))
+	c.Ctx.Output.Body(data)
+}
@@ -0,0 +1,99 @@
+// Copyright 2023 The Casdoor Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/casdoor/casdoor/object"
+)
+
+// GetMaintenanceStatus
+// @Title GetMaintenanceStatus
+// @Tag Maintenance API
+// @Description get the maintenance mode and the drain state of the node serving the request
+// @Success 200 {object} object.MaintenanceStatus The Response object
+// @router /get-maintenance-status [get]
+func (c *ApiController) GetMaintenanceStatus() {
+	c.ResponseOk(object.GetMaintenanceStatus())
+}
+
+// GetMaintenanceNotice
+// @Title GetMaintenanceNotice
+// @Tag Maintenance API
+// @Description get the notice shown on the login page, empty when not in maintenance mode
+// @Success 200 {string} string The Response object
+// @router /get-maintenance-notice [get]
+func (c *ApiController) GetMaintenanceNotice() {
+	if !object.IsMaintenanceMode() {
+		c.ResponseOk("")
+		return
+	}
+
+	notice := object.GetMaintenanceNotice()
+	if notice == "" {
+		notice = c.T("general:The service is under maintenance, please try again later")
+	}
+	c.ResponseOk(notice)
+}
+
+// SetMaintenanceMode
+// @Title SetMaintenanceMode
+// @Tag Maintenance API
+// @Description turn the maintenance mode of all nodes on or off, new logins are refused with the notice and the background jobs pause
+// @Param   enabled     query    string  true        "true or false"
+// @Param   notice     query    string  false        "The notice, empty for the one of app.conf"
+// @Success 200 {object} object.MaintenanceStatus The Response object
+// @router /set-maintenance-mode [post]
+func (c *ApiController) SetMaintenanceMode() {
+	enabled := c.Input().Get("enabled") == "true"
+	notice := c.Input().Get("notice")
+
+	err := object.SetMaintenanceMode(enabled, notice, c.GetSessionUsername())
+	if err != nil {
+		c.ResponseError(err.Error())
+		return
+	}
+
+	c.ResponseOk(object.GetMaintenanceStatus())
+}
+
+// DrainInstance
+// @Title DrainInstance
+// @Tag Maintenance API
+// @Description let the node serving the request fail its readiness probe and wait for its requests in flight, the node can be shut down once isDrained is true
+// @Param   timeout     query    string  false        "The seconds to wait, the drainTimeout of app.conf by default"
+// @Success 200 {object} object.MaintenanceStatus The Response object
+// @router /drain-instance [post]
+func (c *ApiController) DrainInstance() {
+	timeout := object.GetDrainTimeout()
+	if value := c.Input().Get("timeout"); value != "" {
+		seconds, err := strconv.Atoi(value)
+		if err != nil {
+			c.ResponseError(err.Error())
+			return
+		}
+		timeout = time.Duration(seconds) * time.Second
+	}
+
+	object.StartDrain()
+	// this request is in flight itself
+	isDrained := object.WaitForDrain(timeout, 1)
+
+	status := object.GetMaintenanceStatus()
+	status.IsDrained = isDrained
+	c.ResponseOk(status)
+}
@@ -0,0 +1,182 @@
+// Copyright 2023 The Casdoor Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/beego/beego/utils/pagination"
+	"github.com/casdoor/casdoor/object"
+	"github.com/casdoor/casdoor/util"
+)
+
+// GetMemberships
+// @Title GetMemberships
+// @Tag Membership API
+// @Description get the memberships of users from other organizations in an organization
+// @Param   owner     query    string  true        "The owner of memberships"
+// @Success 200 {array} object.Membership The Response object
+// @router /get-memberships [get]
+func (c *ApiController) GetMemberships() {
+	owner := c.Input().Get("owner")
+	limit := c.Input().Get("pageSize")
+	page := c.Input().Get("p")
+	field := c.Input().Get("field")
+	value := c.Input().Get("value")
+	sortField := c.Input().Get("sortField")
+	sortOrder := c.Input().Get("sortOrder")
+	if limit == "" || page == "" {
+		c.Data["json"] = object.GetMemberships(owner)
+		c.ServeJSON()
+	} else {
+		limit := util.ParseInt(limit)
+		paginator := pagination.SetPaginator(c.Ctx, limit, int64(object.GetMembershipCount(owner, field, value)))
+		memberships := object.GetPaginationMemberships(owner, paginator.Offset(), limit, field, value, sortField, sortOrder)
+		c.ResponseOk(memberships, paginator.Nums())
+	}
+}
+
+// GetMembership
+// @Title GetMembership
+// @Tag Membership API
+// @Description get membership
+// @Param   id     query    string  true        "The id ( owner/name ) of the membership"
+// @Success 200 {object} object.Membership The Response object
+// @router /get-membership [get]
+func (c *ApiController) GetMembership() {
+	id := c.Input().Get("id")
+
+	c.Data["json"] = object.GetMembership(id)
+	c.ServeJSON()
+}
+
+// UpdateMembership
+// @Title UpdateMembership
+// @Tag Membership API
+// @Description update membership
+// @Param   id     query    string  true        "The id ( owner/name ) of the membership"
+// @Param   body    body   object.Membership  true        "The details of the membership"
+// @Success 200 {object} controllers.Response The Response object
+// @router /update-membership [post]
+func (c *ApiController) UpdateMembership() {
+	id := c.Input().Get("id")
+
+	var membership object.Membership
+	err := json.Unmarshal(c.Ctx.Input.RequestBody, &membership)
+	if err != nil {
+		c.ResponseError(err.Error())
+		return
+	}
+
+	msg := object.CheckMembership(&membership, c.GetAcceptLanguage())
+	if msg != "" {
+		c.ResponseError(msg)
+		return
+	}
+
+	c.Data["json"] = wrapActionResponse(object.UpdateMembership(id, &membership))
+	c.ServeJSON()
+}
+
+// AddMembership
+// @Title AddMembership
+// @Tag Membership API
+// @Description add a user of another organization as a member of the organization
+// @Param   body    body   object.Membership  true        "The details of the membership"
+// @Success 200 {object} controllers.Response The Response object
+// @router /add-membership [post]
+func (c *ApiController) AddMembership() {
+	var membership object.Membership
+	err := json.Unmarshal(c.Ctx.Input.RequestBody, &membership)
+	if err != nil {
+		c.ResponseError(err.Error())
+		return
+	}
+
+	msg := object.CheckMembership(&membership, c.GetAcceptLanguage())
+	if msg != "" {
+		c.ResponseError(msg)
+		return
+	}
+
+	c.Data["json"] = wrapActionResponse(object.AddMembership(&membership))
+	c.ServeJSON()
+}
+
+// DeleteMembership
+// @Title DeleteMembership
+// @Tag Membership API
+// @Description delete membership
+// @Param   body    body   object.Membership  true        "The details of the membership"
+// @Success 200 {object} controllers.Response The Response object
+// @router /delete-membership [post]
+func (c *ApiController) DeleteMembership() {
+	var membership object.Membership
+	err := json.Unmarshal(c.Ctx.Input.RequestBody, &membership)
+	if err != nil {
+		c.ResponseError(err.Error())
+		return
+	}
+
+	c.Data["json"] = wrapActionResponse(object.DeleteMembership(&membership))
+	c.ServeJSON()
+}
+
+// GetUserOrganizations
+// @Title GetUserOrganizations
+// @Tag Membership API
+// @Description get the organizations the signed-in user belongs to, with the active one in data2
+// @Success 200 {array} object.Organization The Response object
+// @router /get-user-organizations [get]
+func (c *ApiController) GetUserOrganizations() {
+	user, ok := c.RequireSignedInUser()
+	if !ok {
+		return
+	}
+
+	activeOrganization := c.GetSessionActiveOrganization()
+	if activeOrganization == "" {
+		activeOrganization = user.Owner
+	}
+
+	c.ResponseOk(object.GetMaskedOrganizations(object.GetUserOrganizations(user)), activeOrganization)
+}
+
+// SetActiveOrganization
+// @Title SetActiveOrganization
+// @Tag Membership API
+// @Description switch the organization of the current session of the signed-in user
+// @Param   organization     query    string  true        "The name of the organization"
+// @Success 200 {object} controllers.Response The Response object
+// @router /set-active-organization [post]
+func (c *ApiController) SetActiveOrganization() {
+	user, ok := c.RequireSignedInUser()
+	if !ok {
+		return
+	}
+
+	organization := c.Input().Get("organization")
+	if !object.IsUserInOrganization(user, organization) {
+		c.ResponseError(fmt.Sprintf(c.T("membership:The user is not a member of the organization: %s"), organization))
+		return
+	}
+
+	if organization == user.Owner {
+		organization = ""
+	}
+	c.SetSessionActiveOrganization(organization)
+	c.ResponseOk()
+}
@@ -121,3 +121,31 @@ func (c *ApiController) DeleteModel() {
 	c.Data["json"] = wrapActionResponse(object.DeleteModel(&model))
 	c.ServeJSON()
 }
+
+// ValidateModel
+// @Title ValidateModel
+// @Tag Model API
+// @Description validate a model with policies and requests, including the ABAC functions userAttr(), attr() and timeInRange()
+// @Param   body    body   object.ModelValidation  true        "The model, the policies and the requests"
+// @Success 200 {object} object.ModelValidationReport The Response object
+// @router /validate-model [post]
+func (c *ApiController) ValidateModel() {
+	var validation object.ModelValidation
+	err := json.Unmarshal(c.Ctx.Input.RequestBody, &validation)
+	if err != nil {
+		c.ResponseError(err.Error())
+		return
+	}
+
+	c.ResponseOk(object.ValidateModel(&validation))
+}
+
+// GetModelTemplates
+// @Title GetModelTemplates
+// @Tag Model API
+// @Description get the example models with their policies and requests
+// @Success 200 {array} object.ModelTemplate The Response object
+// @router /get-model-templates [get]
+func (c *ApiController) GetModelTemplates() {
+	c.ResponseOk(object.GetModelTemplates())
+}
@@ -0,0 +1,43 @@
+// Copyright 2023 The Casdoor Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers
+
+import "github.com/casdoor/casdoor/object"
+
+// GetObjectCacheMetrics
+// @Title GetObjectCacheMetrics
+// @Tag Cache API
+// @Description get the hit rate and the size of the object cache of this node
+// @Success 200 {object} object.ObjectCacheMetrics The Response object
+// @router /get-object-cache-metrics [get]
+func (c *ApiController) GetObjectCacheMetrics() {
+	c.ResponseOk(object.GetObjectCacheMetrics())
+}
+
+// FlushObjectCache
+// @Title FlushObjectCache
+// @Tag Cache API
+// @Description empty the object caches of all nodes and the shared cache in Redis, after the database was changed by hand
+// @Success 200 {object} controllers.Response The Response object
+// @router /flush-object-cache [post]
+func (c *ApiController) FlushObjectCache() {
+	err := object.FlushObjectCache()
+	if err != nil {
+		c.ResponseError(err.Error())
+		return
+	}
+
+	c.ResponseOk()
+}
@@ -16,6 +16,7 @@ package controllers
 
 import (
 	"encoding/json"
+	"fmt"
 
 	"github.com/beego/beego/utils/pagination"
 	"github.com/casdoor/casdoor/object"
@@ -58,7 +59,10 @@ func (c *ApiController) GetOrganizations() {
 func (c *ApiController) GetOrganization() {
 	id := c.Input().Get("id")
 
-	c.Data["json"] = object.GetMaskedOrganization(object.GetOrganization(id))
+	organization := object.GetOrganization(id)
+	c.setETag(organization)
+
+	c.Data["json"] = object.GetMaskedOrganization(organization)
 	c.ServeJSON()
 }
 
@@ -80,8 +84,16 @@ func (c *ApiController) UpdateOrganization() {
 		return
 	}
 
-	c.Data["json"] = wrapActionResponse(object.UpdateOrganization(id, &organization))
-	c.ServeJSON()
+	if msg := c.checkOrganizationParent(id, &organization); msg != "" {
+		c.ResponseError(msg)
+		return
+	}
+	if msg := object.CheckOrganizationCa(&organization, object.GetOrganization(id), c.GetAcceptLanguage()); msg != "" {
+		c.ResponseError(msg)
+		return
+	}
+
+	c.responseConditionalWrite(object.UpdateOrganizationWithPrecondition(id, &organization, c.getPrecondition()))
 }
 
 // AddOrganization ...
@@ -105,6 +117,20 @@ func (c *ApiController) AddOrganization() {
 		return
 	}
 
+	if msg := c.checkOrganizationParent("", &organization); msg != "" {
+		c.ResponseError(msg)
+		return
+	}
+	if msg := object.CheckOrganizationCa(&organization, nil, c.GetAcceptLanguage()); msg != "" {
+		c.ResponseError(msg)
+		return
+	}
+
+	if !object.IsShardConfigured(organization.Shard) {
+		c.ResponseError(fmt.Sprintf(c.T("organization:The shard: %s is not configured"), organization.Shard))
+		return
+	}
+
 	c.Data["json"] = wrapActionResponse(object.AddOrganization(&organization))
 	c.ServeJSON()
 }
@@ -148,3 +174,102 @@ func (c *ApiController) GetDefaultApplication() {
 	maskedApplication := object.GetMaskedApplication(application, userId)
 	c.ResponseOk(maskedApplication)
 }
+
+// VerifyOrganizationDomain ...
+// @Title VerifyOrganizationDomain
+// @Tag Organization API
+// @Description verify the domain of the organization with the TXT record "_casdoor-challenge.<domain>"
+// @Param   id     query    string  true        "organization id"
+// @Success 200 {object}  Response The Response object
+// @router /verify-organization-domain [post]
+func (c *ApiController) VerifyOrganizationDomain() {
+	id := c.Input().Get("id")
+
+	organization := object.GetOrganization(id)
+	if organization == nil {
+		c.ResponseError(c.T("check:Organization does not exist"))
+		return
+	}
+
+	err := object.VerifyOrganizationDomain(organization)
+	if err != nil {
+		c.ResponseError(err.Error())
+		return
+	}
+
+	c.ResponseOk(organization.IsDomainVerified)
+}
+
+// VerifyEmailDomain ...
+// @Title VerifyEmailDomain
+// @Tag Organization API
+// @Description verify an email domain claimed by the organization with the TXT record "_casdoor-challenge.<domain>"
+// @Param   id     query    string  true        "organization id"
+// @Param   domain     query    string  true        "the email domain"
+// @Success 200 {object}  Response The Response object
+// @router /verify-email-domain [post]
+func (c *ApiController) VerifyEmailDomain() {
+	id := c.Input().Get("id")
+	domain := c.Input().Get("domain")
+
+	organization := object.GetOrganization(id)
+	if organization == nil {
+		c.ResponseError(c.T("check:Organization does not exist"))
+		return
+	}
+
+	err := object.VerifyEmailDomain(organization, domain)
+	if err != nil {
+		c.ResponseError(err.Error())
+		return
+	}
+
+	c.ResponseOk(true)
+}
+
+// GetEmailDomainApplication ...
+// @Title GetEmailDomainApplication
+// @Tag Organization API
+// @Description home realm discovery, get the default application of the organization that has verified the domain of the email
+// @Param   email     query    string  true        "the email"
+// @Success 200 {object}  Response The Response object
+// @router /get-email-domain-application [get]
+func (c *ApiController) GetEmailDomainApplication() {
+	userId := c.GetSessionUsername()
+	email := c.Input().Get("email")
+
+	organization, _ := object.GetOrganizationByEmail(email)
+	if organization == nil {
+		c.ResponseOk(nil)
+		return
+	}
+
+	application, err := object.GetDefaultApplication(util.GetId(organization.Owner, organization.Name))
+	if err != nil {
+		c.ResponseError(err.Error())
+		return
+	}
+
+	c.ResponseOk(object.GetMaskedApplication(application, userId), object.GetHomeRealmLoginUrl(organization))
+}
+
+// checkOrganizationParent checks the parent of an added or updated
+// organization. Moving an organization in the hierarchy needs to be an admin
+// of both the old and the new parent, so that the admins of an organization
+// can't detach it from its parent or attach it elsewhere.
+func (c *ApiController) checkOrganizationParent(id string, organization *object.Organization) string {
+	oldParent := ""
+	if id != "" {
+		if oldOrganization := object.GetOrganization(id); oldOrganization != nil {
+			oldParent = oldOrganization.ParentOrganization
+		}
+	}
+
+	if organization.ParentOrganization != oldParent {
+		if (oldParent != "" && !c.IsAdminOf(oldParent)) || (organization.ParentOrganization != "" && !c.IsAdminOf(organization.ParentOrganization)) {
+			return c.T("auth:Unauthorized operation")
+		}
+	}
+
+	return object.CheckOrganizationParent(organization, c.GetAcceptLanguage())
+}
@@ -0,0 +1,235 @@
+// Copyright 2023 The Casdoor Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/casdoor/casdoor/object"
+	"github.com/casdoor/casdoor/util"
+)
+
+func (c *ApiController) getPrecondition() *object.Precondition {
+	return &object.Precondition{
+		IfMatch:     c.Ctx.Input.Header("If-Match"),
+		IfNoneMatch: c.Ctx.Input.Header("If-None-Match"),
+	}
+}
+
+// setETag must be called before the object is masked, so that the ETag
+// reflects the stored secrets rather than "***".
+func (c *ApiController) setETag(obj interface{}) {
+	if eTag := object.GetETag(obj); eTag != "" {
+		c.Ctx.Output.Header("ETag", eTag)
+	}
+}
+
+// responseConditionalWrite answers a write made with a precondition
+// or an upsert, using 412 when the If-Match or If-None-Match check fails.
+func (c *ApiController) responseConditionalWrite(affected bool, err error) {
+	if err == object.ErrPreconditionFailed {
+		c.Ctx.Output.SetStatus(http.StatusPreconditionFailed)
+		c.ResponseError(c.T("general:The object has been changed, please get it again and retry"))
+		return
+	}
+	if err != nil {
+		c.ResponseError(err.Error())
+		return
+	}
+
+	c.Data["json"] = wrapActionResponse(affected)
+	c.ServeJSON()
+}
+
+func (c *ApiController) checkUpsertId(id string, owner string, name string) bool {
+	if id != util.GetId(owner, name) {
+		c.ResponseError(fmt.Sprintf(c.T("general:The id: %s doesn't match the owner and name in the body"), id))
+		return false
+	}
+	return true
+}
+
+// responseUpsert returns the stored object, get is expected to set the ETag
+// and mask the object.
+func (c *ApiController) responseUpsert(created bool, err error, get func() interface{}) {
+	if err != nil {
+		c.responseConditionalWrite(false, err)
+		return
+	}
+
+	obj := get()
+	if created {
+		c.Ctx.Output.SetStatus(http.StatusCreated)
+	}
+	c.ResponseOk(obj, created)
+}
+
+// UpsertOrganization
+// @Title UpsertOrganization
+// @Tag Organization API
+// @Description create or fully replace an organization, honoring If-Match and If-None-Match
+// @Param   id     query    string  true        "The id ( owner/name ) of the organization"
+// @Param   body    body   object.Organization  true        "The details of the organization"
+// @Success 200 {object} object.Organization The Response object
+// @router /upsert-organization [post]
+func (c *ApiController) UpsertOrganization() {
+	id := c.Input().Get("id")
+
+	var organization object.Organization
+	err := json.Unmarshal(c.Ctx.Input.RequestBody, &organization)
+	if err != nil {
+		c.ResponseError(err.Error())
+		return
+	}
+
+	if !c.checkUpsertId(id, organization.Owner, organization.Name) {
+		return
+	}
+
+	oldOrganization := object.GetOrganization(id)
+	if oldOrganization == nil {
+		count := object.GetOrganizationCount("", "", "")
+		if err := checkQuotaForOrganization(count); err != nil {
+			c.ResponseError(err.Error())
+			return
+		}
+	}
+	if msg := object.CheckOrganizationCa(&organization, oldOrganization, c.GetAcceptLanguage()); msg != "" {
+		c.ResponseError(msg)
+		return
+	}
+
+	created, err := object.UpsertOrganization(id, &organization, c.getPrecondition())
+	c.responseUpsert(created, err, func() interface{} {
+		organization := object.GetOrganization(id)
+		c.setETag(organization)
+		return object.GetMaskedOrganization(organization)
+	})
+}
+
+// UpsertApplication
+// @Title UpsertApplication
+// @Tag Application API
+// @Description create or fully replace an application, honoring If-Match and If-None-Match
+// @Param   id     query    string  true        "The id ( owner/name ) of the application"
+// @Param   body    body   object.Application  true        "The details of the application"
+// @Success 200 {object} object.Application The Response object
+// @router /upsert-application [post]
+func (c *ApiController) UpsertApplication() {
+	id := c.Input().Get("id")
+
+	var application object.Application
+	err := json.Unmarshal(c.Ctx.Input.RequestBody, &application)
+	if err != nil {
+		c.ResponseError(err.Error())
+		return
+	}
+
+	if !c.checkUpsertId(id, application.Owner, application.Name) {
+		return
+	}
+
+	if object.GetApplication(id) == nil {
+		count := object.GetApplicationCount("", "", "")
+		if err := checkQuotaForApplication(count); err != nil {
+			c.ResponseError(err.Error())
+			return
+		}
+		if !c.checkSeatLimit(application.Organization, object.SeatResourceApplication, 1) {
+			return
+		}
+	}
+
+	created, err := object.UpsertApplication(id, &application, c.getPrecondition())
+	c.responseUpsert(created, err, func() interface{} {
+		application := object.GetApplication(id)
+		c.setETag(application)
+		return object.GetMaskedApplication(application, c.GetSessionUsername())
+	})
+}
+
+// UpsertProvider
+// @Title UpsertProvider
+// @Tag Provider API
+// @Description create or fully replace a provider, honoring If-Match and If-None-Match
+// @Param   id     query    string  true        "The id ( owner/name ) of the provider"
+// @Param   body    body   object.Provider  true        "The details of the provider"
+// @Success 200 {object} object.Provider The Response object
+// @router /upsert-provider [post]
+func (c *ApiController) UpsertProvider() {
+	id := c.Input().Get("id")
+
+	var provider object.Provider
+	err := json.Unmarshal(c.Ctx.Input.RequestBody, &provider)
+	if err != nil {
+		c.ResponseError(err.Error())
+		return
+	}
+
+	if !c.checkUpsertId(id, provider.Owner, provider.Name) {
+		return
+	}
+
+	if object.GetProvider(id) == nil {
+		count := object.GetProviderCount("", "", "")
+		if err := checkQuotaForProvider(count); err != nil {
+			c.ResponseError(err.Error())
+			return
+		}
+	}
+
+	created, err := object.UpsertProvider(id, &provider, c.getPrecondition())
+	c.responseUpsert(created, err, func() interface{} {
+		provider := object.GetProvider(id)
+		c.setETag(provider)
+		return object.GetMaskedProvider(provider)
+	})
+}
+
+// UpsertCert
+// @Title UpsertCert
+// @Tag Cert API
+// @Description create or fully replace a cert, honoring If-Match and If-None-Match
+// @Param   id     query    string  true        "The id ( owner/name ) of the cert"
+// @Param   body    body   object.Cert  true        "The details of the cert"
+// @Success 200 {object} object.Cert The Response object
+// @router /upsert-cert [post]
+func (c *ApiController) UpsertCert() {
+	id := c.Input().Get("id")
+
+	var cert object.Cert
+	err := json.Unmarshal(c.Ctx.Input.RequestBody, &cert)
+	if err != nil {
+		c.ResponseError(err.Error())
+		return
+	}
+
+	if !c.checkUpsertId(id, cert.Owner, cert.Name) {
+		return
+	}
+	if msg := c.checkCertOrganization(object.GetCert(id), &cert); msg != "" {
+		c.ResponseError(msg)
+		return
+	}
+
+	created, err := object.UpsertCert(id, &cert, c.getPrecondition())
+	c.responseUpsert(created, err, func() interface{} {
+		cert := object.GetCert(id)
+		c.setETag(cert)
+		return object.GetMaskedCert(cert)
+	})
+}
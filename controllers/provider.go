@@ -81,7 +81,10 @@ func (c *ApiController) GetGlobalProviders() {
 // @router /get-provider [get]
 func (c *ApiController) GetProvider() {
 	id := c.Input().Get("id")
-	c.Data["json"] = object.GetMaskedProvider(object.GetProvider(id))
+	provider := object.GetProvider(id)
+	c.setETag(provider)
+
+	c.Data["json"] = object.GetMaskedProvider(provider)
 	c.ServeJSON()
 }
 
@@ -103,8 +106,7 @@ func (c *ApiController) UpdateProvider() {
 		return
 	}
 
-	c.Data["json"] = wrapActionResponse(object.UpdateProvider(id, &provider))
-	c.ServeJSON()
+	c.responseConditionalWrite(object.UpdateProviderWithPrecondition(id, &provider, c.getPrecondition()))
 }
 
 // AddProvider
@@ -0,0 +1,110 @@
+// Copyright 2023 The Casdoor Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers
+
+import (
+	"encoding/json"
+
+	"github.com/casdoor/casdoor/object"
+)
+
+// GetRelationTuples
+// @Title GetRelationTuples
+// @Tag ReBAC API
+// @Description get the relation tuples of an application, the empty filters match all tuples
+// @Param   owner     query    string  true        "The organization of the application"
+// @Param   application     query    string  true        "The name of the application"
+// @Param   object     query    string  false        "The object, e.g. doc:123"
+// @Param   relation     query    string  false        "The relation, e.g. editor"
+// @Param   subject     query    string  false        "The subject, e.g. user:alice or group:eng#member"
+// @Success 200 {array} object.RelationTuple The Response object
+// @router /get-relation-tuples [get]
+func (c *ApiController) GetRelationTuples() {
+	owner := c.Input().Get("owner")
+	application := c.Input().Get("application")
+	objectId := c.Input().Get("object")
+	relation := c.Input().Get("relation")
+	subject := c.Input().Get("subject")
+
+	c.ResponseOk(object.GetRelationTuples(owner, application, objectId, relation, subject))
+}
+
+// AddRelationTuple
+// @Title AddRelationTuple
+// @Tag ReBAC API
+// @Description add a relation tuple, the relations must be defined in the ReBAC namespaces of the application
+// @Param   body    body   object.RelationTuple  true        "The tuple with owner, application, object, relation and subject"
+// @Success 200 {object} controllers.Response The Response object
+// @router /add-relation-tuple [post]
+func (c *ApiController) AddRelationTuple() {
+	var tuple object.RelationTuple
+	err := json.Unmarshal(c.Ctx.Input.RequestBody, &tuple)
+	if err != nil {
+		c.ResponseError(err.Error())
+		return
+	}
+
+	affected, err := object.AddRelationTuple(&tuple)
+	if err != nil {
+		c.ResponseError(err.Error())
+		return
+	}
+
+	c.Data["json"] = wrapActionResponse(affected)
+	c.ServeJSON()
+}
+
+// DeleteRelationTuple
+// @Title DeleteRelationTuple
+// @Tag ReBAC API
+// @Description delete a relation tuple
+// @Param   body    body   object.RelationTuple  true        "The tuple with owner, application, object, relation and subject"
+// @Success 200 {object} controllers.Response The Response object
+// @router /delete-relation-tuple [post]
+func (c *ApiController) DeleteRelationTuple() {
+	var tuple object.RelationTuple
+	err := json.Unmarshal(c.Ctx.Input.RequestBody, &tuple)
+	if err != nil {
+		c.ResponseError(err.Error())
+		return
+	}
+
+	c.Data["json"] = wrapActionResponse(object.DeleteRelationTuple(&tuple))
+	c.ServeJSON()
+}
+
+// CheckRelation
+// @Title CheckRelation
+// @Tag ReBAC API
+// @Description check whether the subject has the relation to the object, following the usersets and the inherited relations
+// @Param   body    body   object.RelationCheck  true        "The check with owner, application, object, relation and subject"
+// @Success 200 {object} controllers.Response The Response object
+// @router /check-relation [post]
+func (c *ApiController) CheckRelation() {
+	var relationCheck object.RelationCheck
+	err := json.Unmarshal(c.Ctx.Input.RequestBody, &relationCheck)
+	if err != nil {
+		c.ResponseError(err.Error())
+		return
+	}
+
+	allowed, err := object.CheckRelation(&relationCheck)
+	if err != nil {
+		c.ResponseError(err.Error())
+		return
+	}
+
+	c.ResponseOk(allowed)
+}
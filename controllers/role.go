@@ -16,6 +16,7 @@ package controllers
 
 import (
 	"encoding/json"
+	"strings"
 
 	"github.com/beego/beego/utils/pagination"
 	"github.com/casdoor/casdoor/object"
@@ -80,6 +81,22 @@ func (c *ApiController) UpdateRole() {
 		return
 	}
 
+	if !c.checkRoleCapabilitiesChange(&role, object.GetRole(id)) {
+		return
+	}
+
+	msg := object.CheckRoleInheritance(id, &role, c.GetAcceptLanguage())
+	if msg == "" {
+		msg = object.CheckRoleAssignments(&role, c.GetAcceptLanguage())
+	}
+	if msg == "" {
+		msg = object.CheckRoleCapabilities(&role, c.GetAcceptLanguage())
+	}
+	if msg != "" {
+		c.ResponseError(msg)
+		return
+	}
+
 	c.Data["json"] = wrapActionResponse(object.UpdateRole(id, &role))
 	c.ServeJSON()
 }
@@ -99,6 +116,22 @@ func (c *ApiController) AddRole() {
 		return
 	}
 
+	if !c.checkRoleCapabilitiesChange(&role, nil) {
+		return
+	}
+
+	msg := object.CheckRoleInheritance(role.GetId(), &role, c.GetAcceptLanguage())
+	if msg == "" {
+		msg = object.CheckRoleAssignments(&role, c.GetAcceptLanguage())
+	}
+	if msg == "" {
+		msg = object.CheckRoleCapabilities(&role, c.GetAcceptLanguage())
+	}
+	if msg != "" {
+		c.ResponseError(msg)
+		return
+	}
+
 	c.Data["json"] = wrapActionResponse(object.AddRole(&role))
 	c.ServeJSON()
 }
@@ -121,3 +154,46 @@ func (c *ApiController) DeleteRole() {
 	c.Data["json"] = wrapActionResponse(object.DeleteRole(&role))
 	c.ServeJSON()
 }
+
+// GetCapabilities
+// @Title GetCapabilities
+// @Tag Role API
+// @Description get the capabilities of the management endpoints that roles can grant
+// @Success 200 {array} string The Response object
+// @router /get-capabilities [get]
+func (c *ApiController) GetCapabilities() {
+	c.ResponseOk(object.GetCapabilities())
+}
+
+// checkRoleCapabilitiesChange only lets the admins of the organization grant
+// capabilities, a role:write capability alone can't be used to get more,
+// neither by changing the capabilities of a role nor by joining a role that
+// grants some.
+func (c *ApiController) checkRoleCapabilitiesChange(role *object.Role, oldRole *object.Role) bool {
+	if c.IsAdminOf(role.Owner) && (oldRole == nil || c.IsAdminOf(oldRole.Owner)) {
+		return true
+	}
+
+	allowed := false
+	if oldRole == nil {
+		allowed = !object.IsRoleGrantingCapabilities(role)
+	} else if strings.Join(role.Capabilities, ",") == strings.Join(oldRole.Capabilities, ",") {
+		allowed = isSameRoleMembers(role, oldRole) || (!object.IsRoleGrantingCapabilities(role) && !object.IsRoleGrantingCapabilities(oldRole))
+	}
+	if allowed {
+		return true
+	}
+
+	c.ResponseError(c.T("auth:Unauthorized operation"))
+	return false
+}
+
+func isSameRoleMembers(role *object.Role, oldRole *object.Role) bool {
+	if role.GetId() != oldRole.GetId() || strings.Join(role.Users, ",") != strings.Join(oldRole.Users, ",") || strings.Join(role.Roles, ",") != strings.Join(oldRole.Roles, ",") {
+		return false
+	}
+
+	assignments, _ := json.Marshal(role.Assignments)
+	oldAssignments, _ := json.Marshal(oldRole.Assignments)
+	return string(assignments) == string(oldAssignments)
+}
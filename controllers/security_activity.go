@@ -0,0 +1,57 @@
+// Copyright 2023 The Casdoor Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers
+
+import "github.com/casdoor/casdoor/object"
+
+// GetSecurityActivity
+// @Title GetSecurityActivity
+// @Tag Account API
+// @Description get the recent security events of the signed-in user: logins, password, MFA and contact changes and new application authorizations
+// @Success 200 {array} object.SecurityEvent The Response object
+// @router /get-security-activity [get]
+func (c *ApiController) GetSecurityActivity() {
+	user, ok := c.RequireSignedInUser()
+	if !ok {
+		return
+	}
+
+	c.ResponseOk(object.GetSecurityActivity(user))
+}
+
+// ReportSecurityEvent
+// @Title ReportSecurityEvent
+// @Tag Account API
+// @Description report a security event of the signed-in user as "this wasn't me", which signs the user out everywhere and notifies the admins of the organization
+// @Param   id     query    string  true        "The id of the security event"
+// @Success 200 {object} object.SecurityEvent The Response object
+// @router /report-security-event [post]
+func (c *ApiController) ReportSecurityEvent() {
+	id := c.Input().Get("id")
+
+	user, ok := c.RequireSignedInUser()
+	if !ok {
+		return
+	}
+
+	event, err := object.ReportSecurityEvent(user, id, c.GetAcceptLanguage())
+	if err != nil {
+		c.ResponseError(err.Error())
+		return
+	}
+
+	c.ClearUserSession()
+	c.ResponseOk(event)
+}
@@ -0,0 +1,79 @@
+// Copyright 2023 The Casdoor Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers
+
+import (
+	"encoding/json"
+
+	"github.com/casdoor/casdoor/object"
+	"github.com/casdoor/casdoor/util"
+)
+
+// GetSettings
+// @Title GetSettings
+// @Tag Setting API
+// @Description get the settings that can be changed at runtime, with their current and app.conf values
+// @Success 200 {array} object.SettingItem The Response object
+// @router /get-settings [get]
+func (c *ApiController) GetSettings() {
+	c.ResponseOk(object.GetSettingItems())
+}
+
+// UpdateSettings
+// @Title UpdateSettings
+// @Tag Setting API
+// @Description set runtime settings, they take effect on all nodes without a restart, null resets a setting to app.conf
+// @Param   body    body   object.Setting  true        "The map from setting names to values"
+// @Success 200 {object} controllers.Response The Response object
+// @router /update-settings [post]
+func (c *ApiController) UpdateSettings() {
+	var values map[string]*string
+	err := json.Unmarshal(c.Ctx.Input.RequestBody, &values)
+	if err != nil {
+		c.ResponseError(err.Error())
+		return
+	}
+
+	err = object.UpdateSettings(values, c.GetSessionUsername())
+	if err != nil {
+		c.ResponseError(err.Error())
+		return
+	}
+
+	c.ResponseOk(object.GetSettingItems())
+}
+
+// GetSettingChanges
+// @Title GetSettingChanges
+// @Tag Setting API
+// @Description get the audit log of the runtime settings, newest first
+// @Param   limit     query    string  false        "The number of changes, all by default"
+// @Success 200 {array} object.SettingChange The Response object
+// @router /get-setting-changes [get]
+func (c *ApiController) GetSettingChanges() {
+	limit := util.ParseInt(c.Input().Get("limit"))
+
+	c.ResponseOk(object.GetSettingChanges(limit))
+}
+
+// GetRetentionMetrics
+// @Title GetRetentionMetrics
+// @Tag Setting API
+// @Description get the retention policy, the row counts of the purged tables and what the purge job deleted on this node
+// @Success 200 {object} object.RetentionMetrics The Response object
+// @router /get-retention-metrics [get]
+func (c *ApiController) GetRetentionMetrics() {
+	c.ResponseOk(object.GetRetentionMetrics())
+}
@@ -0,0 +1,52 @@
+// Copyright 2023 The Casdoor Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers
+
+import (
+	"github.com/casdoor/casdoor/object"
+	"github.com/casdoor/casdoor/util"
+)
+
+// GetShards
+// @Title GetShards
+// @Tag Shard API
+// @Description get the main database and the shards of app.conf with their organizations
+// @Success 200 {array} object.ShardInfo The Response object
+// @router /get-shards [get]
+func (c *ApiController) GetShards() {
+	c.ResponseOk(object.GetShards())
+}
+
+// MoveOrganizationShard
+// @Title MoveOrganizationShard
+// @Tag Shard API
+// @Description move the users of the organization to the shard, empty for the main database
+// @Param   id     query    string  true        "The id ( owner/name ) of the organization"
+// @Param   shard     query    string  false        "The name of the shard"
+// @Success 200 {object} object.ShardMoveResult The Response object
+// @router /move-organization-shard [post]
+func (c *ApiController) MoveOrganizationShard() {
+	id := c.Input().Get("id")
+	shard := c.Input().Get("shard")
+
+	_, name := util.GetOwnerAndNameFromId(id)
+	result, err := object.MoveOrganizationShard(name, shard)
+	if err != nil {
+		c.ResponseError(err.Error())
+		return
+	}
+
+	c.ResponseOk(result)
+}
@@ -0,0 +1,82 @@
+// Copyright 2023 The Casdoor Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers
+
+import (
+	"encoding/json"
+
+	"github.com/beego/beego/utils/pagination"
+	"github.com/casdoor/casdoor/object"
+	"github.com/casdoor/casdoor/util"
+)
+
+type SshCertificateForm struct {
+	PublicKey       string `json:"publicKey"`
+	ExpireInMinutes int    `json:"expireInMinutes"`
+}
+
+// IssueSshCertificate
+// @Title IssueSshCertificate
+// @Tag SSH CA API
+// @Description sign an SSH public key of the signed-in user with the SSH CA of the organization, usually called with the access token of the user. The principals are the role names of the user, after the user name if the CA allows it
+// @Param   body    body   controllers.SshCertificateForm  true        "The public key in the authorized_keys format and the requested lifetime, the lifetime of the CA by default"
+// @Success 200 {object} controllers.Response The certificate in data and the issued certificate in data2
+// @router /issue-ssh-certificate [post]
+func (c *ApiController) IssueSshCertificate() {
+	user, ok := c.RequireSignedInUser()
+	if !ok {
+		return
+	}
+
+	var form SshCertificateForm
+	err := json.Unmarshal(c.Ctx.Input.RequestBody, &form)
+	if err != nil {
+		c.ResponseError(err.Error())
+		return
+	}
+
+	certificate, sshCertificate, err := object.IssueSshCertificate(user, form.PublicKey, form.ExpireInMinutes, util.GetIPFromRequest(c.Ctx.Request))
+	if err != nil {
+		c.ResponseError(err.Error())
+		return
+	}
+
+	c.ResponseOk(certificate, sshCertificate)
+}
+
+// GetSshCertificates
+// @Title GetSshCertificates
+// @Tag SSH CA API
+// @Description get the SSH certificates issued to the users of an organization
+// @Param   owner     query    string  true        "The organization"
+// @Success 200 {array} object.SshCertificate The Response object
+// @router /get-ssh-certificates [get]
+func (c *ApiController) GetSshCertificates() {
+	owner := c.Input().Get("owner")
+	limit := c.Input().Get("pageSize")
+	page := c.Input().Get("p")
+	field := c.Input().Get("field")
+	value := c.Input().Get("value")
+	sortField := c.Input().Get("sortField")
+	sortOrder := c.Input().Get("sortOrder")
+	if limit == "" || page == "" {
+		c.ResponseOk(object.GetSshCertificates(owner))
+	} else {
+		limit := util.ParseInt(limit)
+		paginator := pagination.SetPaginator(c.Ctx, limit, int64(object.GetSshCertificateCount(owner, field, value)))
+		sshCertificates := object.GetPaginationSshCertificates(owner, paginator.Offset(), limit, field, value, sortField, sortOrder)
+		c.ResponseOk(sshCertificates, paginator.Nums())
+	}
+}
@@ -0,0 +1,59 @@
+// Copyright 2023 The Casdoor Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers
+
+import (
+	"fmt"
+
+	"github.com/casdoor/casdoor/object"
+)
+
+// CheckApplicationSso
+// @Title CheckApplicationSso
+// @Tag Application API
+// @Description sign the current user in to the application as a dummy SAML SP or OIDC RP and report every step of the round trip
+// @Param   id     query    string  true        "The id ( owner/name ) of the application"
+// @Param   type     query    string  true        "saml or oidc"
+// @Param   redirectUri     query    string  false        "The redirect URI to use, the first one of the application by default"
+// @Success 200 {object} object.SsoCheckResult The Response object
+// @router /check-application-sso [get]
+func (c *ApiController) CheckApplicationSso() {
+	id := c.Input().Get("id")
+	typ := c.Input().Get("type")
+	redirectUri := c.Input().Get("redirectUri")
+
+	user, ok := c.RequireSignedInUser()
+	if !ok {
+		return
+	}
+
+	application := object.GetApplication(id)
+	if application == nil {
+		c.ResponseError(fmt.Sprintf(c.T("auth:The application: %s does not exist"), id))
+		return
+	}
+	if !c.isOrganizationAdmin(application.Organization) {
+		c.ResponseError(c.T("auth:Unauthorized operation"))
+		return
+	}
+
+	result, err := object.CheckApplicationSso(application, user, typ, redirectUri, c.Ctx.Request.Host, c.GetAcceptLanguage())
+	if err != nil {
+		c.ResponseError(err.Error())
+		return
+	}
+
+	c.ResponseOk(result)
+}
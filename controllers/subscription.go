@@ -0,0 +1,308 @@
+// Copyright 2023 The Casdoor Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/beego/beego/utils/pagination"
+	"github.com/casdoor/casdoor/object"
+	"github.com/casdoor/casdoor/util"
+)
+
+// GetSubscriptions
+// @Title GetSubscriptions
+// @Tag Subscription API
+// @Description get subscriptions
+// @Param   owner     query    string  true        "The owner of subscriptions"
+// @Success 200 {array} object.Subscription The Response object
+// @router /get-subscriptions [get]
+func (c *ApiController) GetSubscriptions() {
+	owner := c.Input().Get("owner")
+	limit := c.Input().Get("pageSize")
+	page := c.Input().Get("p")
+	field := c.Input().Get("field")
+	value := c.Input().Get("value")
+	sortField := c.Input().Get("sortField")
+	sortOrder := c.Input().Get("sortOrder")
+	if limit == "" || page == "" {
+		c.Data["json"] = object.GetSubscriptions(owner)
+		c.ServeJSON()
+	} else {
+		limit := util.ParseInt(limit)
+		paginator := pagination.SetPaginator(c.Ctx, limit, int64(object.GetSubscriptionCount(owner, field, value)))
+		subscriptions := object.GetPaginationSubscriptions(owner, paginator.Offset(), limit, field, value, sortField, sortOrder)
+		c.ResponseOk(subscriptions, paginator.Nums())
+	}
+}
+
+// GetUserSubscriptions
+// @Title GetUserSubscriptions
+// @Tag Subscription API
+// @Description get the subscriptions of the signed-in user
+// @Param   owner     query    string  true        "The owner of subscriptions"
+// @Success 200 {array} object.Subscription The Response object
+// @router /get-user-subscriptions [get]
+func (c *ApiController) GetUserSubscriptions() {
+	owner := c.Input().Get("owner")
+
+	user, ok := c.RequireSignedInUser()
+	if !ok {
+		return
+	}
+
+	c.ResponseOk(object.GetUserSubscriptions(owner, user.Owner, user.Name))
+}
+
+// getUserSubscription returns the subscription if it belongs to the
+// signed-in user or the user is an admin of its organization.
+func (c *ApiController) getUserSubscription(id string) (*object.Subscription, bool) {
+	user, ok := c.RequireSignedInUser()
+	if !ok {
+		return nil, false
+	}
+
+	subscription := object.GetSubscription(id)
+	if subscription == nil {
+		c.ResponseError(fmt.Sprintf(c.T("subscription:The subscription: %s does not exist"), id))
+		return nil, false
+	}
+
+	if (subscription.Organization != user.Owner || subscription.User != user.Name) && !c.IsAdminOf(subscription.Organization) {
+		c.ResponseError(c.T("auth:Unauthorized operation"))
+		return nil, false
+	}
+	return subscription, true
+}
+
+// GetSubscription
+// @Title GetSubscription
+// @Tag Subscription API
+// @Description get subscription
+// @Param   id     query    string  true        "The id ( owner/name ) of the subscription"
+// @Success 200 {object} object.Subscription The Response object
+// @router /get-subscription [get]
+func (c *ApiController) GetSubscription() {
+	id := c.Input().Get("id")
+
+	subscription, ok := c.getUserSubscription(id)
+	if !ok {
+		return
+	}
+
+	c.Data["json"] = subscription
+	c.ServeJSON()
+}
+
+// UpdateSubscription
+// @Title UpdateSubscription
+// @Tag Subscription API
+// @Description update subscription
+// @Param   id     query    string  true        "The id ( owner/name ) of the subscription"
+// @Param   body    body   object.Subscription  true        "The details of the subscription"
+// @Success 200 {object} controllers.Response The Response object
+// @router /update-subscription [post]
+func (c *ApiController) UpdateSubscription() {
+	id := c.Input().Get("id")
+
+	var subscription object.Subscription
+	err := json.Unmarshal(c.Ctx.Input.RequestBody, &subscription)
+	if err != nil {
+		c.ResponseError(err.Error())
+		return
+	}
+
+	c.Data["json"] = wrapActionResponse(object.UpdateSubscription(id, &subscription))
+	c.ServeJSON()
+}
+
+// AddSubscription
+// @Title AddSubscription
+// @Tag Subscription API
+// @Description add subscription
+// @Param   body    body   object.Subscription  true        "The details of the subscription"
+// @Success 200 {object} controllers.Response The Response object
+// @router /add-subscription [post]
+func (c *ApiController) AddSubscription() {
+	var subscription object.Subscription
+	err := json.Unmarshal(c.Ctx.Input.RequestBody, &subscription)
+	if err != nil {
+		c.ResponseError(err.Error())
+		return
+	}
+
+	c.Data["json"] = wrapActionResponse(object.AddSubscription(&subscription))
+	c.ServeJSON()
+}
+
+// DeleteSubscription
+// @Title DeleteSubscription
+// @Tag Subscription API
+// @Description delete subscription
+// @Param   body    body   object.Subscription  true        "The details of the subscription"
+// @Success 200 {object} controllers.Response The Response object
+// @router /delete-subscription [post]
+func (c *ApiController) DeleteSubscription() {
+	var subscription object.Subscription
+	err := json.Unmarshal(c.Ctx.Input.RequestBody, &subscription)
+	if err != nil {
+		c.ResponseError(err.Error())
+		return
+	}
+
+	c.Data["json"] = wrapActionResponse(object.DeleteSubscription(&subscription))
+	c.ServeJSON()
+}
+
+// Subscribe
+// @Title Subscribe
+// @Tag Subscription API
+// @Description subscribe to a plan, the response has the URL to approve the subscription at the payment provider
+// @Param   id     query    string  true        "The id ( owner/name ) of the plan"
+// @Success 200 {object} controllers.Response The Response object
+// @router /subscribe [post]
+func (c *ApiController) Subscribe() {
+	id := c.Input().Get("id")
+	host := c.Ctx.Request.Host
+
+	user, ok := c.RequireSignedInUser()
+	if !ok {
+		return
+	}
+
+	plan := object.GetPlan(id)
+	if plan == nil {
+		c.ResponseError(fmt.Sprintf(c.T("subscription:The plan: %s does not exist"), id))
+		return
+	}
+
+	payUrl, err := object.Subscribe(plan, user, host)
+	if err != nil {
+		c.ResponseError(err.Error())
+		return
+	}
+
+	c.ResponseOk(payUrl)
+}
+
+func (c *ApiController) getSubscriptionAndPlan() (*object.Subscription, *object.Plan, bool) {
+	id := c.Input().Get("id")
+	planId := c.Input().Get("plan")
+
+	subscription, ok := c.getUserSubscription(id)
+	if !ok {
+		return nil, nil, false
+	}
+
+	plan := object.GetPlan(planId)
+	if plan == nil {
+		c.ResponseError(fmt.Sprintf(c.T("subscription:The plan: %s does not exist"), planId))
+		return nil, nil, false
+	}
+	return subscription, plan, true
+}
+
+// GetSubscriptionProration
+// @Title GetSubscriptionProration
+// @Tag Subscription API
+// @Description preview the prorated amount of changing the plan of a subscription
+// @Param   id     query    string  true        "The id ( owner/name ) of the subscription"
+// @Param   plan     query    string  true        "The id ( owner/name ) of the new plan"
+// @Success 200 {object} object.SubscriptionProration The Response object
+// @router /get-subscription-proration [get]
+func (c *ApiController) GetSubscriptionProration() {
+	subscription, plan, ok := c.getSubscriptionAndPlan()
+	if !ok {
+		return
+	}
+
+	proration, err := object.GetSubscriptionProration(subscription, plan)
+	if err != nil {
+		c.ResponseError(err.Error())
+		return
+	}
+
+	c.ResponseOk(proration)
+}
+
+// ChangeSubscriptionPlan
+// @Title ChangeSubscriptionPlan
+// @Tag Subscription API
+// @Description change the plan of a subscription, prorated for the rest of the period, the response has a URL if the change has to be approved at the payment provider
+// @Param   id     query    string  true        "The id ( owner/name ) of the subscription"
+// @Param   plan     query    string  true        "The id ( owner/name ) of the new plan"
+// @Success 200 {object} controllers.Response The Response object
+// @router /change-subscription-plan [post]
+func (c *ApiController) ChangeSubscriptionPlan() {
+	subscription, plan, ok := c.getSubscriptionAndPlan()
+	if !ok {
+		return
+	}
+
+	approveUrl, err := object.ChangeSubscriptionPlan(subscription, plan)
+	if err != nil {
+		c.ResponseError(err.Error())
+		return
+	}
+
+	c.ResponseOk(approveUrl)
+}
+
+// CancelSubscription
+// @Title CancelSubscription
+// @Tag Subscription API
+// @Description cancel a subscription
+// @Param   id     query    string  true        "The id ( owner/name ) of the subscription"
+// @Success 200 {object} controllers.Response The Response object
+// @router /cancel-subscription [post]
+func (c *ApiController) CancelSubscription() {
+	id := c.Input().Get("id")
+
+	subscription, ok := c.getUserSubscription(id)
+	if !ok {
+		return
+	}
+
+	err := object.CancelSubscription(subscription)
+	if err != nil {
+		c.ResponseError(err.Error())
+		return
+	}
+
+	c.ResponseOk()
+}
+
+// SubscriptionWebhook
+// @Title SubscriptionWebhook
+// @Tag Subscription API
+// @Description receive the subscription webhooks of Stripe or PayPal, the URL is /api/subscription-webhook/{owner}/{provider}
+// @Success 200 {object} controllers.Response The Response object
+// @router /subscription-webhook [post]
+func (c *ApiController) SubscriptionWebhook() {
+	owner := c.Ctx.Input.Param(":owner")
+	providerName := c.Ctx.Input.Param(":provider")
+
+	err := object.HandleSubscriptionWebhook(c.Ctx.Request, c.Ctx.Input.RequestBody, owner, providerName)
+	if err != nil {
+		// a failed webhook is retried by the provider
+		c.Ctx.Output.SetStatus(http.StatusBadRequest)
+		c.ResponseError(err.Error())
+		return
+	}
+
+	c.ResponseOk()
+}
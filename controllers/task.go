@@ -0,0 +1,74 @@
+// Copyright 2023 The Casdoor Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers
+
+import (
+	"github.com/beego/beego/utils/pagination"
+	"github.com/casdoor/casdoor/object"
+	"github.com/casdoor/casdoor/util"
+)
+
+// GetTasks
+// @Title GetTasks
+// @Tag Task API
+// @Description get the queued tasks (webhook calls, emails, provisioning) of an organization
+// @Param   owner     query    string  true        "The owner of tasks"
+// @Success 200 {array} object.Task The Response object
+// @router /get-tasks [get]
+func (c *ApiController) GetTasks() {
+	owner := c.Input().Get("owner")
+	limit := c.Input().Get("pageSize")
+	page := c.Input().Get("p")
+	field := c.Input().Get("field")
+	value := c.Input().Get("value")
+	sortField := c.Input().Get("sortField")
+	sortOrder := c.Input().Get("sortOrder")
+	if limit == "" || page == "" {
+		c.Data["json"] = object.GetTasks(owner)
+		c.ServeJSON()
+	} else {
+		limit := util.ParseInt(limit)
+		paginator := pagination.SetPaginator(c.Ctx, limit, int64(object.GetTaskCount(owner, field, value)))
+		tasks := object.GetPaginationTasks(owner, paginator.Offset(), limit, field, value, sortField, sortOrder)
+		c.ResponseOk(tasks, paginator.Nums())
+	}
+}
+
+// GetTask
+// @Title GetTask
+// @Tag Task API
+// @Description get a queued task
+// @Param   id     query    string  true        "The id ( owner/name ) of the task"
+// @Success 200 {object} object.Task The Response object
+// @router /get-task [get]
+func (c *ApiController) GetTask() {
+	id := c.Input().Get("id")
+
+	c.ResponseOk(object.GetTask(id))
+}
+
+// RetryTask
+// @Title RetryTask
+// @Tag Task API
+// @Description run a failed task again, with all its attempts
+// @Param   id     query    string  true        "The id ( owner/name ) of the task"
+// @Success 200 {object} controllers.Response The Response object
+// @router /retry-task [post]
+func (c *ApiController) RetryTask() {
+	id := c.Input().Get("id")
+
+	c.Data["json"] = wrapActionResponse(object.RetryTask(id))
+	c.ServeJSON()
+}
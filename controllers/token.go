@@ -134,6 +134,7 @@ func (c *ApiController) DeleteToken() {
 // @Param   redirect_uri     query    string  true        "OAuth redirect URI"
 // @Param   scope     query    string  true        "OAuth scope"
 // @Param   state     query    string  true        "OAuth state"
+// @Param   active_organization     query    string  false        "The organization to sign in to, for a member of other organizations"
 // @Success 200 {object} object.TokenWrapper The Response object
 // @router /login/oauth/code [post]
 func (c *ApiController) GetOAuthCode() {
@@ -154,7 +155,9 @@ func (c *ApiController) GetOAuthCode() {
 	}
 	host := c.Ctx.Request.Host
 
-	c.Data["json"] = object.GetOAuthCode(userId, clientId, responseType, redirectUri, scope, state, nonce, codeChallenge, host, c.GetAcceptLanguage())
+	activeOrganization := c.Input().Get("active_organization")
+
+	c.Data["json"] = object.GetOAuthCode(userId, clientId, responseType, redirectUri, scope, state, nonce, codeChallenge, host, activeOrganization, c.GetAcceptLanguage())
 	c.ServeJSON()
 }
 
@@ -166,6 +169,10 @@ func (c *ApiController) GetOAuthCode() {
 // @Param   client_id     query    string  true        "OAuth client id"
 // @Param   client_secret     query    string  true        "OAuth client secret"
 // @Param   code     query    string  true        "OAuth code"
+// @Param   redirect_uri     query    string  false        "The redirect URI of the authorization request, checked when given"
+// @Param   device_id     query    string  false        "The device ID of a guest, for the guest grant type"
+// @Param   attestation_type     query    string  false        "The attestation of a mobile app: apple-appattest or play-integrity"
+// @Param   attestation     query    string  false        "The App Attest assertion or the Play Integrity token"
 // @Success 200 {object} object.TokenWrapper The Response object
 // @Success 400 {object} object.TokenError The Response object
 // @Success 401 {object} object.TokenError The Response object
@@ -177,11 +184,15 @@ func (c *ApiController) GetOAuthToken() {
 	clientSecret := c.Input().Get("client_secret")
 	code := c.Input().Get("code")
 	verifier := c.Input().Get("code_verifier")
+	redirectUri := c.Input().Get("redirect_uri")
 	scope := c.Input().Get("scope")
 	username := c.Input().Get("username")
 	password := c.Input().Get("password")
 	tag := c.Input().Get("tag")
 	avatar := c.Input().Get("avatar")
+	deviceId := c.Input().Get("device_id")
+	deviceSecret := c.Input().Get("device_secret")
+	attestationRequest := c.getAttestationRequest()
 
 	if clientId == "" && clientSecret == "" {
 		clientId, clientSecret, _ = c.Ctx.Request.BasicAuth()
@@ -196,16 +207,23 @@ func (c *ApiController) GetOAuthToken() {
 			refreshToken = tokenRequest.RefreshToken
 			code = tokenRequest.Code
 			verifier = tokenRequest.Verifier
+			redirectUri = tokenRequest.RedirectUri
 			scope = tokenRequest.Scope
 			username = tokenRequest.Username
 			password = tokenRequest.Password
 			tag = tokenRequest.Tag
 			avatar = tokenRequest.Avatar
+			deviceId = tokenRequest.DeviceId
+			deviceSecret = tokenRequest.DeviceSecret
+			if tokenRequest.AttestationType != "" {
+				attestationRequest = tokenRequest.getAttestationRequest()
+			}
 		}
 	}
 	host := c.Ctx.Request.Host
 
-	c.Data["json"] = object.GetOAuthToken(grantType, clientId, clientSecret, code, verifier, scope, username, password, host, refreshToken, tag, avatar, c.GetAcceptLanguage())
+	c.Data["json"] = object.GetOAuthToken(grantType, clientId, clientSecret, code, verifier, redirectUri, scope, username, password, host, util.GetIPFromRequest(c.Ctx.Request), refreshToken, tag, avatar, deviceId, c.getDeviceCredentials(deviceSecret), attestationRequest, c.GetAcceptLanguage())
+	c.addTokenStat(clientId, grantType)
 	c.SetTokenErrorHttpStatus()
 	c.ServeJSON()
 }
@@ -219,6 +237,8 @@ func (c *ApiController) GetOAuthToken() {
 // @Param   scope     query    string  true        "OAuth scope"
 // @Param   client_id     query    string  true        "OAuth client id"
 // @Param   client_secret     query    string  false        "OAuth client secret"
+// @Param   attestation_type     query    string  false        "The attestation of a mobile app: apple-appattest or play-integrity"
+// @Param   attestation     query    string  false        "The App Attest assertion or the Play Integrity token"
 // @Success 200 {object} object.TokenWrapper The Response object
 // @Success 400 {object} object.TokenError The Response object
 // @Success 401 {object} object.TokenError The Response object
@@ -229,6 +249,7 @@ func (c *ApiController) RefreshToken() {
 	scope := c.Input().Get("scope")
 	clientId := c.Input().Get("client_id")
 	clientSecret := c.Input().Get("client_secret")
+	attestationRequest := c.getAttestationRequest()
 	host := c.Ctx.Request.Host
 
 	if clientId == "" {
@@ -240,10 +261,14 @@ func (c *ApiController) RefreshToken() {
 			grantType = tokenRequest.GrantType
 			scope = tokenRequest.Scope
 			refreshToken = tokenRequest.RefreshToken
+			if tokenRequest.AttestationType != "" {
+				attestationRequest = tokenRequest.getAttestationRequest()
+			}
 		}
 	}
 
-	c.Data["json"] = object.RefreshToken(grantType, refreshToken, scope, clientId, clientSecret, host)
+	c.Data["json"] = object.RefreshToken(grantType, refreshToken, scope, clientId, clientSecret, host, attestationRequest)
+	c.addTokenStat(clientId, grantType)
 	c.SetTokenErrorHttpStatus()
 	c.ServeJSON()
 }
@@ -294,10 +319,8 @@ func (c *ApiController) IntrospectToken() {
 		return
 	}
 	jwtToken, err := object.ParseJwtTokenByApplication(tokenValue, application)
-	if err != nil || jwtToken.Valid() != nil {
-		// and token revoked case. but we not implement
-		// TODO: 2022-03-03 add token revoked check, when we implemented the Token Revocation(rfc7009) Specs.
-		// refs: https://tools.ietf.org/html/rfc7009
+	// a token expired in the database is revoked, e.g. with its device or the sessions of its user
+	if err != nil || jwtToken.Valid() != nil || util.IsTokenExpired(token.CreatedTime, token.ExpiresIn) {
 		c.Data["json"] = &object.IntrospectionResponse{Active: false}
 		c.ServeJSON()
 		return
@@ -316,6 +339,7 @@ func (c *ApiController) IntrospectToken() {
 		Aud:       jwtToken.Audience,
 		Iss:       jwtToken.Issuer,
 		Jti:       jwtToken.Id,
+		DeviceId:  token.DeviceId,
 	}
 	c.ServeJSON()
 }
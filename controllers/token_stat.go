@@ -0,0 +1,102 @@
+// Copyright 2023 The Casdoor Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers
+
+import (
+	"strings"
+
+	"github.com/casdoor/casdoor/object"
+	"github.com/casdoor/casdoor/util"
+)
+
+// addTokenStat counts the token served to the application in the token
+// stats, failed requests aren't counted.
+func (c *ApiController) addTokenStat(clientId string, grantType string) {
+	if _, ok := c.Data["json"].(*object.TokenWrapper); !ok {
+		return
+	}
+
+	event := object.TokenStatEventIssue
+	if grantType == "refresh_token" {
+		event = object.TokenStatEventRefresh
+	}
+	util.SafeGoroutine(func() { object.AddTokenStat(clientId, event) })
+}
+
+// GetTokenStats
+// @Title GetTokenStats
+// @Tag Token API
+// @Description get the tokens issued and refreshed and the userinfo calls of the applications of an organization per day
+// @Param   id     query    string  true        "The id ( owner/name ) of the organization"
+// @Param   startDate     query    string  false        "The first day, as 2006-01-02"
+// @Param   endDate     query    string  false        "The last day, as 2006-01-02"
+// @Param   application     query    string  false        "The name of the application, default is all"
+// @Success 200 {array} object.TokenStat The Response object
+// @router /get-token-stats [get]
+func (c *ApiController) GetTokenStats() {
+	organization, ok := c.requireOrganizationAdmin(c.Input().Get("id"))
+	if !ok {
+		return
+	}
+
+	c.ResponseOk(object.GetTokenStats(organization, c.Input().Get("startDate"), c.Input().Get("endDate"), c.Input().Get("application")))
+}
+
+// GetTokenAnomalies
+// @Title GetTokenAnomalies
+// @Tag Token API
+// @Description get the spikes and the tokens used from many IPs flagged for an organization
+// @Param   id     query    string  true        "The id ( owner/name ) of the organization"
+// @Param   all     query    string  false        "Also return the reviewed anomalies when true"
+// @Success 200 {array} object.TokenAnomaly The Response object
+// @router /get-token-anomalies [get]
+func (c *ApiController) GetTokenAnomalies() {
+	organization, ok := c.requireOrganizationAdmin(c.Input().Get("id"))
+	if !ok {
+		return
+	}
+
+	c.ResponseOk(object.GetTokenAnomalies(organization, c.Input().Get("all") == "true"))
+}
+
+// ReviewTokenAnomaly
+// @Title ReviewTokenAnomaly
+// @Tag Token API
+// @Description mark a token anomaly as reviewed
+// @Param   id     query    string  true        "The id ( organization/name ) of the anomaly"
+// @Success 200 {object} controllers.Response The Response object
+// @router /review-token-anomaly [post]
+func (c *ApiController) ReviewTokenAnomaly() {
+	id := c.Input().Get("id")
+	if !strings.Contains(id, "/") {
+		c.ResponseError(c.T("general:Missing parameter") + ": id")
+		return
+	}
+
+	organization, _ := util.GetOwnerAndNameFromIdNoCheck(id)
+	if !c.isOrganizationAdmin(organization) {
+		c.ResponseError(c.T("auth:Unauthorized operation"))
+		return
+	}
+
+	tokenAnomaly := object.GetTokenAnomaly(id)
+	if tokenAnomaly == nil {
+		c.ResponseError(c.T("token:The token anomaly doesn't exist"))
+		return
+	}
+
+	c.Data["json"] = wrapActionResponse(object.ReviewTokenAnomaly(tokenAnomaly, c.GetSessionUsername()))
+	c.ServeJSON()
+}
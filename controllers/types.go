@@ -20,10 +20,18 @@ type TokenRequest struct {
 	ClientId     string `json:"client_id"`
 	ClientSecret string `json:"client_secret"`
 	Verifier     string `json:"code_verifier"`
+	RedirectUri  string `json:"redirect_uri"`
 	Scope        string `json:"scope"`
 	Username     string `json:"username"`
 	Password     string `json:"password"`
 	Tag          string `json:"tag"`
 	Avatar       string `json:"avatar"`
 	RefreshToken string `json:"refresh_token"`
+	DeviceId     string `json:"device_id"`
+	DeviceSecret string `json:"device_secret"`
+
+	AttestationType      string `json:"attestation_type"`
+	AttestationChallenge string `json:"attestation_challenge"`
+	AttestationKeyId     string `json:"attestation_key_id"`
+	Attestation          string `json:"attestation"`
 }
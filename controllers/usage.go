@@ -0,0 +1,85 @@
+// Copyright 2023 The Casdoor Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers
+
+import (
+	"github.com/casdoor/casdoor/object"
+	"github.com/casdoor/casdoor/util"
+)
+
+// checkSeatLimit responds with an error when adding to the organization
+// exceeds its seat-based plan.
+func (c *ApiController) checkSeatLimit(organization string, resource string, adding int) bool {
+	err := object.CheckSeatLimit(organization, resource, adding, c.GetAcceptLanguage())
+	if err != nil {
+		c.ResponseError(err.Error())
+		return false
+	}
+	return true
+}
+
+// isOrganizationAdmin tells whether the user is an admin of the organization
+// or has the capability of the endpoint in it.
+func (c *ApiController) isOrganizationAdmin(organization string) bool {
+	return c.IsAdminOf(organization) || object.HasEndpointCapability(c.GetSessionUsername(), c.Ctx.Request.URL.Path, organization, "")
+}
+
+// requireOrganizationAdmin responds with an error unless the user is an admin
+// of the organization of id or has the capability of the endpoint in it.
+func (c *ApiController) requireOrganizationAdmin(id string) (string, bool) {
+	_, organization := util.GetOwnerAndNameFromId(id)
+	if !c.isOrganizationAdmin(organization) {
+		c.ResponseError(c.T("auth:Unauthorized operation"))
+		return "", false
+	}
+	return organization, true
+}
+
+// GetOrganizationUsage
+// @Title GetOrganizationUsage
+// @Tag Subscription API
+// @Description get the current users and applications of an organization and the limits of its seat-based plan
+// @Param   id     query    string  true        "The id ( owner/name ) of the organization"
+// @Success 200 {object} object.Usage The Response object
+// @router /get-organization-usage [get]
+func (c *ApiController) GetOrganizationUsage() {
+	organization, ok := c.requireOrganizationAdmin(c.Input().Get("id"))
+	if !ok {
+		return
+	}
+
+	c.ResponseOk(object.GetOrganizationUsage(organization))
+}
+
+// GetUsages
+// @Title GetUsages
+// @Tag Subscription API
+// @Description get the daily usage of an organization for billing reconciliation
+// @Param   id     query    string  true        "The id ( owner/name ) of the organization"
+// @Param   startDate     query    string  false        "The first day, as 2006-01-02"
+// @Param   endDate     query    string  false        "The last day, as 2006-01-02"
+// @Success 200 {array} object.Usage The Response object
+// @router /get-usages [get]
+func (c *ApiController) GetUsages() {
+	startDate := c.Input().Get("startDate")
+	endDate := c.Input().Get("endDate")
+
+	organization, ok := c.requireOrganizationAdmin(c.Input().Get("id"))
+	if !ok {
+		return
+	}
+
+	c.ResponseOk(object.GetUsages(organization, startDate, endDate))
+}
@@ -166,6 +166,18 @@ func (c *ApiController) UpdateUser() {
 		return
 	}
 
+	if msg := c.checkAdminUserColumns(id, columns); msg != "" {
+		c.ResponseError(msg)
+		return
+	}
+
+	// users change their own email and phone with a verification code, see
+	// ResetEmailOrPhone
+	if oldUser := object.GetUser(id); oldUser != nil && !c.IsAdminOf(oldUser.Owner) && (oldUser.Email != user.Email || oldUser.Phone != user.Phone) {
+		c.ResponseError(c.T("verification:The email and phone can only be changed with a verification code"))
+		return
+	}
+
 	affected := object.UpdateUser(id, &user, columns, isGlobalAdmin)
 	if affected {
 		object.UpdateUserToOriginalDatabase(&user)
@@ -195,8 +207,14 @@ func (c *ApiController) AddUser() {
 		c.ResponseError(err.Error())
 		return
 	}
+	if !c.checkSeatLimit(user.Owner, object.SeatResourceUser, 1) {
+		return
+	}
 
 	msg := object.CheckUsername(user.Name, c.GetAcceptLanguage())
+	if msg == "" {
+		msg = object.CheckAdminEmailDomain(&user, c.GetAcceptLanguage())
+	}
 	if msg != "" {
 		c.ResponseError(msg)
 		return
@@ -257,6 +275,7 @@ func (c *ApiController) GetEmailAndPhone() {
 		respUser.Email = util.GetMaskedEmail(user.Email)
 		respUser.Phone = util.GetMaskedPhone(user.Phone)
 	}
+	object.FilterAccountRecoveryOptions(user, &respUser)
 
 	c.ResponseOk(respUser, contentType)
 }
@@ -296,13 +315,8 @@ func (c *ApiController) SetPassword() {
 		}
 	}
 
-	if strings.Contains(newPassword, " ") {
-		c.ResponseError(c.T("user:New password cannot contain blank space."))
-		return
-	}
-
-	if len(newPassword) <= 5 {
-		c.ResponseError(c.T("user:New password must have at least 6 characters"))
+	if msg := c.checkNewPassword(newPassword); msg != "" {
+		c.ResponseError(msg)
 		return
 	}
 
@@ -311,6 +325,17 @@ func (c *ApiController) SetPassword() {
 	c.ResponseOk()
 }
 
+func (c *ApiController) checkNewPassword(newPassword string) string {
+	if strings.Contains(newPassword, " ") {
+		return c.T("user:New password cannot contain blank space.")
+	}
+
+	if len(newPassword) <= 5 {
+		return c.T("user:New password must have at least 6 characters")
+	}
+	return ""
+}
+
 // CheckUserPassword
 // @Title CheckUserPassword
 // @router /check-user-password [post]
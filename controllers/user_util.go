@@ -16,10 +16,25 @@ package controllers
 
 import (
 	"encoding/json"
+	"fmt"
 
 	"github.com/casdoor/casdoor/object"
 )
 
+// checkAdminUserColumns refuses the columns that only the admins of the
+// organization of the user can update.
+func (c *ApiController) checkAdminUserColumns(userId string, columns []string) string {
+	oldUser := object.GetUser(userId)
+	if oldUser == nil || c.IsAdminOf(oldUser.Owner) {
+		return ""
+	}
+
+	if column := object.GetAdminUserColumn(columns); column != "" {
+		return fmt.Sprintf(c.T("user:The column: %s can only be updated by admins"), column)
+	}
+	return ""
+}
+
 func checkPermissionForUpdateUser(userId string, newUser object.User, c *ApiController) (bool, string) {
 	oldUser := object.GetUser(userId)
 	organization := object.GetOrganizationByUser(oldUser)
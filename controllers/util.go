@@ -49,14 +49,72 @@ func (c *ApiController) ResponseError(error string, data ...interface{}) {
 	c.ResponseJsonData(resp, data...)
 }
 
+func (c *ApiController) getErrorCodeResponse(code string, organization string, msg string) *Response {
+	message, helpUrl := object.GetErrorMessage(organization, code, msg, c.GetAcceptLanguage())
+	return &Response{Status: "error", Msg: message, Code: code, HelpUrl: helpUrl}
+}
+
+// ResponseErrorCode responds with a code of the error catalog, the message is
+// the one the organization set for the code, otherwise msg, otherwise the
+// default message of the code.
+func (c *ApiController) ResponseErrorCode(code string, organization string, msg string, data ...interface{}) {
+	c.ResponseJsonData(c.getErrorCodeResponse(code, organization, msg), data...)
+}
+
+// ResponseServerError keeps the code and message of an object.CodedError,
+// other errors are logged and answered with the message of server_error.
+func (c *ApiController) ResponseServerError(organization string, err error, data ...interface{}) {
+	if codedError, ok := err.(*object.CodedError); ok {
+		c.ResponseErrorCode(codedError.Code, organization, codedError.Message, data...)
+		return
+	}
+
+	util.LogWarning(c.Ctx, "API: %s failed: %s", c.Ctx.Request.URL.Path, err.Error())
+	c.ResponseErrorCode(object.ErrorCodeServerError, organization, "", data...)
+}
+
 func (c *ApiController) T(error string) string {
 	return i18n.Translate(c.GetAcceptLanguage(), error)
 }
 
-// GetAcceptLanguage ...
+// GetAcceptLanguage negotiates the language of the messages from the
+// Accept-Language header, falling back to the default language of the
+// organization of the request.
 func (c *ApiController) GetAcceptLanguage() string {
-	language := c.Ctx.Request.Header.Get("Accept-Language")
-	return conf.GetLanguage(language)
+	if language, ok := c.Data["language"].(string); ok {
+		return language
+	}
+
+	language := conf.GetSupportedLanguage(c.Ctx.Request.Header.Get("Accept-Language"))
+	if language == "" {
+		language = object.GetOrganizationLanguage(c.getRequestOrganization())
+	}
+	c.Data["language"] = language
+	return language
+}
+
+// getRequestOrganization returns the organization given by the parameters of
+// the request, otherwise the one of the signed-in user.
+func (c *ApiController) getRequestOrganization() string {
+	if organization := c.Input().Get("organization"); organization != "" {
+		return organization
+	}
+
+	clientId := c.Input().Get("clientId")
+	if clientId == "" {
+		clientId = c.Input().Get("client_id")
+	}
+	if clientId != "" {
+		if application := object.GetApplicationByClientId(clientId); application != nil {
+			return application.Organization
+		}
+	}
+
+	if userId := c.GetSessionUsername(); userId != "" {
+		owner, _ := util.GetOwnerAndNameFromId(userId)
+		return owner
+	}
+	return ""
 }
 
 // SetTokenErrorHttpStatus ...
@@ -229,20 +229,15 @@ func (c *ApiController) ResetEmailOrPhone() {
 		return
 	}
 
-	switch destType {
-	case "email":
-		user.Email = dest
-		object.SetUserField(user, "email", user.Email)
-	case "phone":
-		user.Phone = dest
-		object.SetUserField(user, "phone", user.Phone)
-	default:
+	if destType != "email" && destType != "phone" {
 		c.ResponseError(c.T("verification:Unknown type"))
 		return
 	}
 
+	// the old value stays active until the change is confirmed
+	contactChange := object.RequestContactChange(user, destType, dest, util.GetIPFromRequest(c.Ctx.Request), c.Ctx.Request.Host)
 	object.DisableVerificationCode(checkDest)
-	c.ResponseOk()
+	c.ResponseOk(contactChange.State, contactChange.ApplyTime)
 }
 
 // VerifyCaptcha ...
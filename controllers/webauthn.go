@@ -128,6 +128,9 @@ func (c *ApiController) WebAuthnSigninBegin() {
 // @router /webauthn/signin/finish [post]
 func (c *ApiController) WebAuthnSigninFinish() {
 	responseType := c.Input().Get("responseType")
+	var form RequestForm
+	defer func() { c.addLoginStat(&form, "WebAuthn", false) }()
+
 	webauthnObj := object.GetWebAuthnObject(c.Ctx.Request.Host)
 	sessionObj := c.GetSession("authentication")
 	sessionData, ok := sessionObj.(webauthn.SessionData)
@@ -147,7 +150,10 @@ func (c *ApiController) WebAuthnSigninFinish() {
 	util.LogInfo(c.Ctx, "API: [%s] signed in", userId)
 
 	application := object.GetApplicationByUser(user)
-	var form RequestForm
+	form.Organization = user.Owner
+	if application != nil {
+		form.Application = application.Name
+	}
 	form.Type = responseType
 	resp := c.HandleLoggedIn(application, user, &form)
 	c.Data["json"] = resp
@@ -0,0 +1,92 @@
+// Copyright 2023 The Casdoor Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cred
+
+import (
+	"crypto/subtle"
+	"encoding/base64"
+	"fmt"
+	"strings"
+
+	"golang.org/x/crypto/argon2"
+)
+
+type argon2Hash struct {
+	variant     string
+	memory      uint32
+	iterations  uint32
+	parallelism uint8
+	salt        []byte
+	key         []byte
+}
+
+// parseArgon2Hash parses a hash in the PHC string format, like
+// "$argon2id$v=19$m=65536,t=3,p=2$<salt>$<hash>".
+func parseArgon2Hash(hash string) *argon2Hash {
+	parts := strings.Split(hash, "$")
+	if len(parts) != 6 || parts[0] != "" {
+		return nil
+	}
+
+	var version int
+	if _, err := fmt.Sscanf(parts[2], "v=%d", &version); err != nil || version != argon2.Version {
+		return nil
+	}
+
+	res := &argon2Hash{variant: parts[1]}
+	if _, err := fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &res.memory, &res.iterations, &res.parallelism); err != nil {
+		return nil
+	}
+
+	var err error
+	res.salt, err = decodePhcBase64(parts[4])
+	if err != nil {
+		return nil
+	}
+	res.key, err = decodePhcBase64(parts[5])
+	if err != nil || len(res.key) == 0 {
+		return nil
+	}
+
+	return res
+}
+
+// compareArgon2Hash checks a password against a PHC string, both argon2id
+// and argon2i are supported.
+func compareArgon2Hash(password string, hash string) bool {
+	h := parseArgon2Hash(hash)
+	if h == nil {
+		return false
+	}
+
+	var otherKey []byte
+	switch h.variant {
+	case "argon2id":
+		otherKey = argon2.IDKey([]byte(password), h.salt, h.iterations, h.memory, h.parallelism, uint32(len(h.key)))
+	case "argon2i":
+		otherKey = argon2.Key([]byte(password), h.salt, h.iterations, h.memory, h.parallelism, uint32(len(h.key)))
+	default:
+		return false
+	}
+
+	return subtle.ConstantTimeCompare(h.key, otherKey) == 1
+}
+
+// decodePhcBase64 decodes the B64 of the PHC string format, which is base64
+// without padding, padded values and the "." of passlib are accepted as well.
+func decodePhcBase64(s string) ([]byte, error) {
+	s = strings.TrimRight(strings.ReplaceAll(s, ".", "+"), "=")
+	return base64.RawStdEncoding.DecodeString(s)
+}
@@ -16,15 +16,34 @@ package cred
 
 import "github.com/alexedwards/argon2id"
 
-type Argon2idCredManager struct{}
+type Argon2idCredManager struct {
+	params *argon2id.Params
+}
 
 func NewArgon2idCredManager() *Argon2idCredManager {
-	cm := &Argon2idCredManager{}
+	return NewArgon2idCredManagerWithParams(0, 0, 0)
+}
+
+// NewArgon2idCredManagerWithParams uses the memory (in KiB), iterations and
+// parallelism for new hashes, zero values keep the defaults.
+func NewArgon2idCredManagerWithParams(memory int, iterations int, parallelism int) *Argon2idCredManager {
+	params := *argon2id.DefaultParams
+	if memory > 0 {
+		params.Memory = uint32(memory)
+	}
+	if iterations > 0 {
+		params.Iterations = uint32(iterations)
+	}
+	if parallelism > 0 && parallelism <= 255 {
+		params.Parallelism = uint8(parallelism)
+	}
+
+	cm := &Argon2idCredManager{params: &params}
 	return cm
 }
 
 func (cm *Argon2idCredManager) GetHashedPassword(password string, userSalt string, organizationSalt string) string {
-	hash, err := argon2id.CreateHash(password, argon2id.DefaultParams)
+	hash, err := argon2id.CreateHash(password, cm.params)
 	if err != nil {
 		return ""
 	}
@@ -32,6 +51,17 @@ func (cm *Argon2idCredManager) GetHashedPassword(password string, userSalt strin
 }
 
 func (cm *Argon2idCredManager) IsPasswordCorrect(plainPwd string, hashedPwd string, userSalt string, organizationSalt string) bool {
-	match, _ := argon2id.ComparePasswordAndHash(plainPwd, hashedPwd)
-	return match
+	return compareArgon2Hash(plainPwd, hashedPwd)
+}
+
+// NeedsRehash returns true for argon2i hashes and for hashes made with less
+// memory, iterations or parallelism than configured.
+func (cm *Argon2idCredManager) NeedsRehash(passwordHash string) bool {
+	h := parseArgon2Hash(passwordHash)
+	if h == nil {
+		return false
+	}
+
+	return h.variant != "argon2id" || h.memory < cm.params.Memory || h.iterations < cm.params.Iterations ||
+		h.parallelism < cm.params.Parallelism || uint32(len(h.key)) < cm.params.KeyLength
 }
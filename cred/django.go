@@ -0,0 +1,88 @@
+// Copyright 2023 The Casdoor Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cred
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"golang.org/x/crypto/bcrypt"
+	"golang.org/x/crypto/pbkdf2"
+)
+
+// DjangoCredManager verifies the hashes of the Django password hashers,
+// "<algorithm>$<parameters>". https://docs.djangoproject.com/en/4.2/topics/auth/passwords/
+type DjangoCredManager struct{}
+
+const djangoSaltChars = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789"
+
+func NewDjangoCredManager() *DjangoCredManager {
+	cm := &DjangoCredManager{}
+	return cm
+}
+
+func (cm *DjangoCredManager) GetHashedPassword(password string, userSalt string, organizationSalt string) string {
+	salt := []byte{}
+	for _, b := range getRandomSalt(22) {
+		salt = append(salt, djangoSaltChars[int(b)%len(djangoSaltChars)])
+	}
+
+	key := pbkdf2.Key([]byte(password), salt, pbkdf2Iterations, sha256.Size, sha256.New)
+	return fmt.Sprintf("pbkdf2_sha256$%d$%s$%s", pbkdf2Iterations, salt, base64.StdEncoding.EncodeToString(key))
+}
+
+func (cm *DjangoCredManager) IsPasswordCorrect(plainPwd string, hashedPwd string, userSalt string, organizationSalt string) bool {
+	parts := strings.SplitN(hashedPwd, "$", 2)
+	if len(parts) != 2 {
+		return false
+	}
+
+	algorithm, params := parts[0], parts[1]
+	switch algorithm {
+	case "pbkdf2_sha256", "pbkdf2_sha1":
+		fields := strings.Split(params, "$")
+		if len(fields) != 3 {
+			return false
+		}
+
+		iterations, err := strconv.Atoi(fields[0])
+		if err != nil || iterations <= 0 {
+			return false
+		}
+		key, err := base64.StdEncoding.DecodeString(fields[2])
+		if err != nil || len(key) == 0 {
+			return false
+		}
+
+		digest := getPbkdf2Digest(strings.TrimPrefix(algorithm, "pbkdf2_"))
+		otherKey := pbkdf2.Key([]byte(plainPwd), []byte(fields[1]), iterations, len(key), digest)
+		return subtle.ConstantTimeCompare(key, otherKey) == 1
+	case "argon2":
+		return compareArgon2Hash(plainPwd, "$"+params)
+	case "bcrypt":
+		return bcrypt.CompareHashAndPassword([]byte(params), []byte(plainPwd)) == nil
+	case "bcrypt_sha256":
+		// bcrypt of the hex SHA-256, for passwords longer than 72 bytes
+		digest := sha256.Sum256([]byte(plainPwd))
+		return bcrypt.CompareHashAndPassword([]byte(params), []byte(hex.EncodeToString(digest[:]))) == nil
+	default:
+		return false
+	}
+}
@@ -0,0 +1,125 @@
+// Copyright 2023 The Casdoor Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cred
+
+import (
+	"crypto/md5"
+	"crypto/sha512"
+	"crypto/subtle"
+	"hash"
+	"strings"
+)
+
+// DrupalCredManager verifies the salted and stretched hashes of Drupal 7
+// ("$S$") and of phpass ("$P$", "$H$"), along with the "U$S$" hashes of
+// passwords that were migrated from Drupal 6 MD5. Drupal 8 and later use
+// bcrypt. https://api.drupal.org/api/drupal/includes%21password.inc/7.x
+type DrupalCredManager struct{}
+
+const (
+	drupalItoa64     = "./0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz"
+	drupalHashCount  = 15
+	drupalHashLength = 55
+)
+
+func NewDrupalCredManager() *DrupalCredManager {
+	cm := &DrupalCredManager{}
+	return cm
+}
+
+// drupalBase64 is the _password_base64_encode() of Drupal.
+func drupalBase64(input []byte) string {
+	var sb strings.Builder
+	count := len(input)
+	i := 0
+	for i < count {
+		value := int(input[i])
+		i++
+		sb.WriteByte(drupalItoa64[value&0x3f])
+		if i < count {
+			value |= int(input[i]) << 8
+		}
+		sb.WriteByte(drupalItoa64[(value>>6)&0x3f])
+		if i >= count {
+			break
+		}
+		i++
+		if i < count {
+			value |= int(input[i]) << 16
+		}
+		sb.WriteByte(drupalItoa64[(value>>12)&0x3f])
+		if i >= count {
+			break
+		}
+		i++
+		sb.WriteByte(drupalItoa64[(value>>18)&0x3f])
+	}
+	return sb.String()
+}
+
+// drupalCrypt is the _password_crypt() of Drupal, setting holds the prefix,
+// the log2 of the iteration count and the salt.
+func drupalCrypt(password string, setting string) string {
+	if len(setting) < 12 {
+		return ""
+	}
+
+	var newHash func() hash.Hash
+	switch setting[:3] {
+	case "$S$":
+		newHash = sha512.New
+	case "$P$", "$H$":
+		newHash = md5.New
+	default:
+		return ""
+	}
+
+	countLog2 := strings.IndexByte(drupalItoa64, setting[3])
+	if countLog2 < 7 || countLog2 > 30 {
+		return ""
+	}
+	salt := setting[4:12]
+
+	h := newHash()
+	h.Write([]byte(salt + password))
+	sum := h.Sum(nil)
+	for count := 1 << countLog2; count > 0; count-- {
+		h.Reset()
+		h.Write(sum)
+		h.Write([]byte(password))
+		sum = h.Sum(nil)
+	}
+
+	output := setting[:12] + drupalBase64(sum)
+	if setting[:3] == "$S$" && len(output) > drupalHashLength {
+		output = output[:drupalHashLength]
+	}
+	return output
+}
+
+func (cm *DrupalCredManager) GetHashedPassword(password string, userSalt string, organizationSalt string) string {
+	setting := "$S$" + string(drupalItoa64[drupalHashCount]) + drupalBase64(getRandomSalt(6))
+	return drupalCrypt(password, setting)
+}
+
+func (cm *DrupalCredManager) IsPasswordCorrect(plainPwd string, hashedPwd string, userSalt string, organizationSalt string) bool {
+	if strings.HasPrefix(hashedPwd, "U$") {
+		hashedPwd = hashedPwd[1:]
+		plainPwd = getMd5HexDigest(plainPwd)
+	}
+
+	otherHash := drupalCrypt(plainPwd, hashedPwd)
+	return otherHash != "" && subtle.ConstantTimeCompare([]byte(hashedPwd), []byte(otherHash)) == 1
+}
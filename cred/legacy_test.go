@@ -0,0 +1,72 @@
+// Copyright 2023 The Casdoor Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cred
+
+import (
+	"testing"
+
+	"github.com/alexedwards/argon2id"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLegacyPasswordHashes(t *testing.T) {
+	argon2idHash, _ := argon2id.CreateHash("123456", argon2id.DefaultParams)
+
+	scenarios := []struct {
+		description  string
+		passwordType string
+		password     string
+		hash         string
+		expected     bool
+	}{
+		{"Should accept a Django PBKDF2 hash", "django", "123456", "pbkdf2_sha256$1000$seasalt$wxsI9J+cuhi3pYmUMv3D1TyYQ5enFJPYcR+hMgJj7UU=", true},
+		{"Should reject a wrong password for a Django PBKDF2 hash", "django", "654321", "pbkdf2_sha256$1000$seasalt$wxsI9J+cuhi3pYmUMv3D1TyYQ5enFJPYcR+hMgJj7UU=", false},
+		{"Should accept a Django argon2 hash", "django", "123456", "argon2" + argon2idHash, true},
+		{"Should accept a PHC PBKDF2 hash", "pbkdf2", "123456", "$pbkdf2-sha512$i=1000,l=64$MDEyMzQ1Njc4OWFiY2RlZg$5aNprX7YLONvwHFLNcLCrwddOqa75yN2CObLScePzP8/11aMOk28nO7grgwgTGqhND1lj5eikvHi43UWINRaow", true},
+		{"Should reject a malformed PHC PBKDF2 hash", "pbkdf2", "123456", "$pbkdf2-md4$i=1000$MDEy$5aNp", false},
+		{"Should accept a phpass hash", "drupal", "test12345", "$P$9IQRaTwmfeRo7ud9Fh4E2PdI0S3r.L0", true},
+		{"Should reject a wrong password for a phpass hash", "drupal", "test12346", "$P$9IQRaTwmfeRo7ud9Fh4E2PdI0S3r.L0", false},
+		{"Should accept an argon2id hash", "argon2id", "123456", argon2idHash, true},
+	}
+
+	for _, scenery := range scenarios {
+		t.Run(scenery.description, func(t *testing.T) {
+			cm := GetCredManager(scenery.passwordType)
+			actual := cm.IsPasswordCorrect(scenery.password, scenery.hash, "", "")
+			assert.Equal(t, scenery.expected, actual)
+		})
+	}
+}
+
+func TestLegacyPasswordRoundTrip(t *testing.T) {
+	for _, passwordType := range []string{"pbkdf2", "django", "drupal"} {
+		t.Run(passwordType, func(t *testing.T) {
+			cm := GetCredManager(passwordType)
+			hash := cm.GetHashedPassword("123456", "", "")
+			assert.True(t, cm.IsPasswordCorrect("123456", hash, "", ""))
+			assert.False(t, cm.IsPasswordCorrect("1234567", hash, "", ""))
+		})
+	}
+}
+
+func TestArgon2idNeedsRehash(t *testing.T) {
+	weakHash := NewArgon2idCredManagerWithParams(16*1024, 1, 1).GetHashedPassword("123456", "", "")
+
+	cm := NewArgon2idCredManagerWithParams(32*1024, 2, 1)
+	assert.True(t, cm.IsPasswordCorrect("123456", weakHash, "", ""))
+	assert.True(t, cm.NeedsRehash(weakHash))
+	assert.False(t, cm.NeedsRehash(cm.GetHashedPassword("123456", "", "")))
+	assert.False(t, NewArgon2idCredManagerWithParams(8*1024, 1, 1).NeedsRehash(weakHash))
+}
@@ -14,11 +14,20 @@
 
 package cred
 
+import "strings"
+
 type CredManager interface {
 	GetHashedPassword(password string, userSalt string, organizationSalt string) string
 	IsPasswordCorrect(password string, passwordHash string, userSalt string, organizationSalt string) bool
 }
 
+// RehashCredManager is implemented by the managers with tunable parameters,
+// NeedsRehash tells whether a hash was made with weaker parameters than the
+// current ones.
+type RehashCredManager interface {
+	NeedsRehash(passwordHash string) bool
+}
+
 func GetCredManager(passwordType string) CredManager {
 	if passwordType == "plain" {
 		return NewPlainCredManager()
@@ -32,6 +41,32 @@ func GetCredManager(passwordType string) CredManager {
 		return NewPbkdf2SaltCredManager()
 	} else if passwordType == "argon2id" {
 		return NewArgon2idCredManager()
+	} else if passwordType == "pbkdf2" {
+		return NewPbkdf2CredManager()
+	} else if passwordType == "django" {
+		return NewDjangoCredManager()
+	} else if passwordType == "drupal" {
+		return NewDrupalCredManager()
 	}
 	return nil
 }
+
+// GetPasswordTypeByHash returns the password type of a hash that describes
+// its own algorithm, or "" when it can't be told from the hash alone.
+func GetPasswordTypeByHash(hash string) string {
+	switch {
+	case strings.HasPrefix(hash, "$2a$"), strings.HasPrefix(hash, "$2b$"), strings.HasPrefix(hash, "$2y$"):
+		return "bcrypt"
+	case strings.HasPrefix(hash, "$argon2id$"), strings.HasPrefix(hash, "$argon2i$"):
+		return "argon2id"
+	case strings.HasPrefix(hash, "$pbkdf2-"):
+		return "pbkdf2"
+	case strings.HasPrefix(hash, "pbkdf2_sha256$"), strings.HasPrefix(hash, "pbkdf2_sha1$"), strings.HasPrefix(hash, "argon2$"),
+		strings.HasPrefix(hash, "bcrypt$"), strings.HasPrefix(hash, "bcrypt_sha256$"):
+		return "django"
+	case strings.HasPrefix(hash, "$S$"), strings.HasPrefix(hash, "U$S$"), strings.HasPrefix(hash, "$P$"), strings.HasPrefix(hash, "$H$"):
+		return "drupal"
+	default:
+		return ""
+	}
+}
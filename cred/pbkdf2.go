@@ -0,0 +1,104 @@
+// Copyright 2023 The Casdoor Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cred
+
+import (
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"crypto/subtle"
+	"encoding/base64"
+	"fmt"
+	"hash"
+	"strings"
+
+	"golang.org/x/crypto/pbkdf2"
+)
+
+// Pbkdf2CredManager stores PBKDF2 hashes in the PHC string format used by
+// Auth0 and passlib, "$pbkdf2-sha256$i=<iterations>,l=<key length>$<salt>$<hash>",
+// so that hashes with any digest and iteration count can be imported.
+type Pbkdf2CredManager struct{}
+
+const pbkdf2Iterations = 600000
+
+func NewPbkdf2CredManager() *Pbkdf2CredManager {
+	cm := &Pbkdf2CredManager{}
+	return cm
+}
+
+func getPbkdf2Digest(name string) func() hash.Hash {
+	switch name {
+	case "sha1":
+		return sha1.New
+	case "sha256":
+		return sha256.New
+	case "sha512":
+		return sha512.New
+	default:
+		return nil
+	}
+}
+
+func getRandomSalt(size int) []byte {
+	salt := make([]byte, size)
+	_, err := rand.Read(salt)
+	if err != nil {
+		panic(err)
+	}
+	return salt
+}
+
+func (cm *Pbkdf2CredManager) GetHashedPassword(password string, userSalt string, organizationSalt string) string {
+	salt := getRandomSalt(16)
+	key := pbkdf2.Key([]byte(password), salt, pbkdf2Iterations, 32, sha256.New)
+	return fmt.Sprintf("$pbkdf2-sha256$i=%d,l=%d$%s$%s", pbkdf2Iterations, len(key), base64.RawStdEncoding.EncodeToString(salt), base64.RawStdEncoding.EncodeToString(key))
+}
+
+func (cm *Pbkdf2CredManager) IsPasswordCorrect(plainPwd string, hashedPwd string, userSalt string, organizationSalt string) bool {
+	parts := strings.Split(hashedPwd, "$")
+	if len(parts) != 5 || parts[0] != "" || !strings.HasPrefix(parts[1], "pbkdf2-") {
+		return false
+	}
+
+	digest := getPbkdf2Digest(strings.TrimPrefix(parts[1], "pbkdf2-"))
+	if digest == nil {
+		return false
+	}
+
+	// the key length is optional, it defaults to the length of the hash
+	iterations := 0
+	for _, param := range strings.Split(parts[2], ",") {
+		if strings.HasPrefix(param, "i=") {
+			_, _ = fmt.Sscanf(param, "i=%d", &iterations)
+		}
+	}
+	if iterations <= 0 {
+		return false
+	}
+
+	salt, err := decodePhcBase64(parts[3])
+	if err != nil {
+		return false
+	}
+	key, err := decodePhcBase64(parts[4])
+	if err != nil || len(key) == 0 {
+		return false
+	}
+
+	otherKey := pbkdf2.Key([]byte(plainPwd), salt, iterations, len(key), digest)
+	return subtle.ConstantTimeCompare(key, otherKey) == 1
+}
@@ -0,0 +1,259 @@
+// Copyright 2023 The Casdoor Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package email
+
+import (
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"sync"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/ses"
+)
+
+var snsCertHostRegex = regexp.MustCompile(`^sns\.[a-z0-9-]+\.amazonaws\.com(\.cn)?$`)
+
+// AwsSesEmailProvider uses the SendEmail API of Amazon SES. The bounces and
+// complaints come from the SNS topic of TopicArn subscribed to the webhook,
+// the messages are signed by SNS and the subscription is confirmed
+// automatically. Any AWS account can sign messages of its own topics, so the
+// messages of other topics are refused.
+type AwsSesEmailProvider struct {
+	AccessKey string
+	SecretKey string
+	Region    string
+	TopicArn  string
+}
+
+type snsMessage struct {
+	Type             string `json:"Type"`
+	MessageId        string `json:"MessageId"`
+	Token            string `json:"Token"`
+	TopicArn         string `json:"TopicArn"`
+	Subject          string `json:"Subject"`
+	Message          string `json:"Message"`
+	Timestamp        string `json:"Timestamp"`
+	SignatureVersion string `json:"SignatureVersion"`
+	Signature        string `json:"Signature"`
+	SigningCertURL   string `json:"SigningCertURL"`
+	SubscribeURL     string `json:"SubscribeURL"`
+}
+
+type sesRecipient struct {
+	EmailAddress   string `json:"emailAddress"`
+	DiagnosticCode string `json:"diagnosticCode"`
+}
+
+type sesNotification struct {
+	NotificationType string `json:"notificationType"`
+	EventType        string `json:"eventType"`
+	Bounce           *struct {
+		BounceType        string          `json:"bounceType"`
+		BounceSubType     string          `json:"bounceSubType"`
+		BouncedRecipients []*sesRecipient `json:"bouncedRecipients"`
+	} `json:"bounce"`
+	Complaint *struct {
+		ComplaintFeedbackType string          `json:"complaintFeedbackType"`
+		ComplainedRecipients  []*sesRecipient `json:"complainedRecipients"`
+	} `json:"complaint"`
+}
+
+var (
+	snsCertMap     = map[string]*x509.Certificate{}
+	snsCertMapLock sync.Mutex
+)
+
+// getSnsCertificate is a variable so that the tests can sign with their own
+// certificate.
+var getSnsCertificate = func(certUrl string) (*x509.Certificate, error) {
+	snsCertMapLock.Lock()
+	defer snsCertMapLock.Unlock()
+	if cert, ok := snsCertMap[certUrl]; ok {
+		return cert, nil
+	}
+
+	resp, err := httpClient.Get(certUrl)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("the SNS signing certificate is not in PEM")
+	}
+	cert, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+
+	snsCertMap[certUrl] = cert
+	return cert, nil
+}
+
+func NewAwsSesEmailProvider(accessKey string, secretKey string, region string, topicArn string) *AwsSesEmailProvider {
+	return &AwsSesEmailProvider{
+		AccessKey: accessKey,
+		SecretKey: secretKey,
+		Region:    region,
+		TopicArn:  topicArn,
+	}
+}
+
+func (p *AwsSesEmailProvider) Send(fromAddress string, fromName string, toAddress string, subject string, content string) error {
+	sess, err := session.NewSession(&aws.Config{
+		Region:      aws.String(p.Region),
+		Credentials: credentials.NewStaticCredentials(p.AccessKey, p.SecretKey, ""),
+	})
+	if err != nil {
+		return err
+	}
+
+	_, err = ses.New(sess).SendEmail(&ses.SendEmailInput{
+		Source:      aws.String(getAddress(fromAddress, fromName)),
+		Destination: &ses.Destination{ToAddresses: []*string{aws.String(toAddress)}},
+		Message: &ses.Message{
+			Subject: &ses.Content{Charset: aws.String("UTF-8"), Data: aws.String(subject)},
+			Body: &ses.Body{
+				Html: &ses.Content{Charset: aws.String("UTF-8"), Data: aws.String(content)},
+			},
+		},
+	})
+	return err
+}
+
+// getSnsStringToSign builds the string signed by SNS, per
+// https://docs.aws.amazon.com/sns/latest/dg/sns-verify-signature-of-message.html
+func getSnsStringToSign(message *snsMessage) string {
+	fields := []string{"Message", message.Message, "MessageId", message.MessageId}
+	if message.Type == "Notification" {
+		if message.Subject != "" {
+			fields = append(fields, "Subject", message.Subject)
+		}
+		fields = append(fields, "Timestamp", message.Timestamp, "TopicArn", message.TopicArn, "Type", message.Type)
+	} else {
+		fields = append(fields, "SubscribeURL", message.SubscribeURL, "Timestamp", message.Timestamp, "Token", message.Token, "TopicArn", message.TopicArn, "Type", message.Type)
+	}
+	return strings.Join(fields, "\n") + "\n"
+}
+
+func verifySnsMessage(message *snsMessage) error {
+	certUrl, err := url.Parse(message.SigningCertURL)
+	if err != nil {
+		return err
+	}
+	if certUrl.Scheme != "https" || !snsCertHostRegex.MatchString(certUrl.Host) || !strings.HasSuffix(certUrl.Path, ".pem") {
+		return fmt.Errorf("the SNS signing certificate URL: %s is not from AWS", message.SigningCertURL)
+	}
+
+	cert, err := getSnsCertificate(message.SigningCertURL)
+	if err != nil {
+		return err
+	}
+	signature, err := base64.StdEncoding.DecodeString(message.Signature)
+	if err != nil {
+		return err
+	}
+
+	algorithm := x509.SHA1WithRSA
+	if message.SignatureVersion == "2" {
+		algorithm = x509.SHA256WithRSA
+	}
+	err = cert.CheckSignature(algorithm, []byte(getSnsStringToSign(message)), signature)
+	if err != nil {
+		return fmt.Errorf("the signature of the webhook is invalid: %s", err.Error())
+	}
+	return nil
+}
+
+// ParseWebhook confirms the subscription of the SNS topic and handles the
+// notifications of SES, only the permanent bounces are returned.
+func (p *AwsSesEmailProvider) ParseWebhook(request *http.Request, body []byte) ([]*Bounce, error) {
+	if p.TopicArn == "" {
+		return nil, fmt.Errorf("the topic ARN of the provider is empty")
+	}
+
+	message := snsMessage{}
+	err := json.Unmarshal(body, &message)
+	if err != nil {
+		return nil, err
+	}
+
+	err = verifySnsMessage(&message)
+	if err != nil {
+		return nil, err
+	}
+	if message.TopicArn != p.TopicArn {
+		return nil, fmt.Errorf("the SNS topic: %s is not the topic of the provider", message.TopicArn)
+	}
+
+	if message.Type == "SubscriptionConfirmation" {
+		resp, err := httpClient.Get(message.SubscribeURL)
+		if err != nil {
+			return nil, err
+		}
+		resp.Body.Close()
+		return []*Bounce{}, nil
+	} else if message.Type != "Notification" {
+		return []*Bounce{}, nil
+	}
+
+	return parseSesNotification(message.Message)
+}
+
+func parseSesNotification(data string) ([]*Bounce, error) {
+	notification := sesNotification{}
+	err := json.Unmarshal([]byte(data), &notification)
+	if err != nil {
+		return nil, err
+	}
+
+	// "notificationType" for the feedback notifications and "eventType" for
+	// the events of a configuration set
+	typ := notification.NotificationType
+	if typ == "" {
+		typ = notification.EventType
+	}
+
+	bounces := []*Bounce{}
+	if typ == "Bounce" && notification.Bounce != nil && notification.Bounce.BounceType == "Permanent" {
+		for _, recipient := range notification.Bounce.BouncedRecipients {
+			reason := recipient.DiagnosticCode
+			if reason == "" {
+				reason = notification.Bounce.BounceSubType
+			}
+			bounces = append(bounces, &Bounce{Email: recipient.EmailAddress, Type: BounceTypeBounce, Reason: reason})
+		}
+	} else if typ == "Complaint" && notification.Complaint != nil {
+		for _, recipient := range notification.Complaint.ComplainedRecipients {
+			bounces = append(bounces, &Bounce{Email: recipient.EmailAddress, Type: BounceTypeComplaint, Reason: notification.Complaint.ComplaintFeedbackType})
+		}
+	}
+	return bounces, nil
+}
@@ -0,0 +1,120 @@
+// Copyright 2023 The Casdoor Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package email
+
+import (
+	"crypto/hmac"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+const mailgunApiBase = "https://api.mailgun.net"
+
+// MailgunEmailProvider uses the messages API of Mailgun for the sending
+// domain, the host is "api.eu.mailgun.net" for the EU region. The webhooks
+// are signed with the HTTP webhook signing key.
+type MailgunEmailProvider struct {
+	ApiKey     string
+	SigningKey string
+	Endpoint   string
+	Domain     string
+}
+
+type mailgunWebhook struct {
+	Signature struct {
+		Timestamp string `json:"timestamp"`
+		Token     string `json:"token"`
+		Signature string `json:"signature"`
+	} `json:"signature"`
+	EventData struct {
+		Event          string `json:"event"`
+		Severity       string `json:"severity"`
+		Recipient      string `json:"recipient"`
+		Reason         string `json:"reason"`
+		DeliveryStatus struct {
+			Description string `json:"description"`
+			Message     string `json:"message"`
+		} `json:"delivery-status"`
+	} `json:"event-data"`
+}
+
+func NewMailgunEmailProvider(apiKey string, signingKey string, host string, domain string) *MailgunEmailProvider {
+	return &MailgunEmailProvider{
+		ApiKey:     apiKey,
+		SigningKey: signingKey,
+		Endpoint:   getApiBase(host, mailgunApiBase),
+		Domain:     domain,
+	}
+}
+
+func (p *MailgunEmailProvider) Send(fromAddress string, fromName string, toAddress string, subject string, content string) error {
+	if p.Domain == "" {
+		return fmt.Errorf("the sending domain of the Mailgun provider is empty")
+	}
+
+	form := url.Values{}
+	form.Set("from", getAddress(fromAddress, fromName))
+	form.Set("to", toAddress)
+	form.Set("subject", subject)
+	form.Set("html", content)
+
+	req, err := http.NewRequest("POST", fmt.Sprintf("%s/v3/%s/messages", p.Endpoint, p.Domain), strings.NewReader(form.Encode()))
+	if err != nil {
+		return err
+	}
+	req.SetBasicAuth("api", p.ApiKey)
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	_, err = doRequest(req)
+	return err
+}
+
+// ParseWebhook handles the "failed" and "complained" webhooks, only the
+// permanent failures are bounces.
+func (p *MailgunEmailProvider) ParseWebhook(request *http.Request, body []byte) ([]*Bounce, error) {
+	if p.SigningKey == "" {
+		return nil, fmt.Errorf("the webhook signing key of the provider is empty")
+	}
+
+	webhook := mailgunWebhook{}
+	err := json.Unmarshal(body, &webhook)
+	if err != nil {
+		return nil, err
+	}
+
+	expected := getHmacSignature([]byte(webhook.Signature.Timestamp+webhook.Signature.Token), p.SigningKey)
+	if !hmac.Equal([]byte(strings.ToLower(webhook.Signature.Signature)), []byte(expected)) {
+		return nil, fmt.Errorf("the signature of the webhook is invalid")
+	}
+
+	eventData := webhook.EventData
+	reason := eventData.DeliveryStatus.Description
+	if reason == "" {
+		reason = eventData.DeliveryStatus.Message
+	}
+	if reason == "" {
+		reason = eventData.Reason
+	}
+
+	if eventData.Event == "failed" && eventData.Severity == "permanent" {
+		return []*Bounce{{Email: eventData.Recipient, Type: BounceTypeBounce, Reason: reason}}, nil
+	} else if eventData.Event == "complained" {
+		return []*Bounce{{Email: eventData.Recipient, Type: BounceTypeComplaint}}, nil
+	}
+	return []*Bounce{}, nil
+}
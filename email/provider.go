@@ -0,0 +1,51 @@
+// Copyright 2023 The Casdoor Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package email
+
+import "net/http"
+
+const (
+	BounceTypeBounce    = "Bounce"
+	BounceTypeComplaint = "Complaint"
+)
+
+// Bounce is an address reported by a webhook of the provider, either as
+// permanently undeliverable or as having marked an email as spam.
+type Bounce struct {
+	Email  string
+	Type   string
+	Reason string
+}
+
+// EmailProvider sends the emails through the HTTP API of a provider instead
+// of SMTP, and reports the bounces and complaints with a webhook.
+type EmailProvider interface {
+	Send(fromAddress string, fromName string, toAddress string, subject string, content string) error
+	// ParseWebhook returns the permanent bounces and the complaints of a
+	// webhook event, the transient failures and other events are left out
+	ParseWebhook(request *http.Request, body []byte) ([]*Bounce, error)
+}
+
+// GetEmailProvider returns nil for the types that send with SMTP.
+func GetEmailProvider(typ string, clientId string, clientSecret string, webhookSecret string, host string, domain string, regionId string) EmailProvider {
+	if typ == "SendGrid" {
+		return NewSendgridEmailProvider(clientSecret, webhookSecret, host)
+	} else if typ == "Mailgun" {
+		return NewMailgunEmailProvider(clientSecret, webhookSecret, host, domain)
+	} else if typ == "AWS SES" {
+		return NewAwsSesEmailProvider(clientId, clientSecret, regionId, webhookSecret)
+	}
+	return nil
+}
@@ -0,0 +1,166 @@
+// Copyright 2023 The Casdoor Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package email
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSendgridParseWebhook(t *testing.T) {
+	privateKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	publicKey, _ := x509.MarshalPKIXPublicKey(&privateKey.PublicKey)
+	provider := NewSendgridEmailProvider("key", base64.StdEncoding.EncodeToString(publicKey), "")
+
+	body := []byte(`[{"email":"a@example.com","event":"bounce","type":"bounce","reason":"550 No such user"},` +
+		`{"email":"b@example.com","event":"bounce","type":"blocked"},` +
+		`{"email":"c@example.com","event":"spamreport"},` +
+		`{"email":"d@example.com","event":"delivered"}]`)
+	hash := sha256.Sum256(append([]byte("1600000000"), body...))
+	signature, _ := ecdsa.SignASN1(rand.Reader, privateKey, hash[:])
+
+	request, _ := http.NewRequest("POST", "/api/email-webhook/admin/provider", nil)
+	request.Header.Set("X-Twilio-Email-Event-Webhook-Timestamp", "1600000000")
+	request.Header.Set("X-Twilio-Email-Event-Webhook-Signature", base64.StdEncoding.EncodeToString(signature))
+
+	bounces, err := provider.ParseWebhook(request, body)
+	assert.Nil(t, err)
+	assert.Equal(t, []*Bounce{
+		{Email: "a@example.com", Type: BounceTypeBounce, Reason: "550 No such user"},
+		{Email: "c@example.com", Type: BounceTypeComplaint},
+	}, bounces)
+
+	request.Header.Set("X-Twilio-Email-Event-Webhook-Timestamp", "1600000001")
+	_, err = provider.ParseWebhook(request, body)
+	assert.NotNil(t, err)
+}
+
+func getMailgunWebhook(eventData string, signingKey string) []byte {
+	signature := getHmacSignature([]byte("1600000000token"), signingKey)
+	return []byte(fmt.Sprintf(`{"signature":{"timestamp":"1600000000","token":"token","signature":"%s"},"event-data":%s}`, signature, eventData))
+}
+
+func TestMailgunParseWebhook(t *testing.T) {
+	provider := NewMailgunEmailProvider("key", "secret", "api.eu.mailgun.net", "mg.example.com")
+	assert.Equal(t, "https://api.eu.mailgun.net", provider.Endpoint)
+
+	scenarios := []struct {
+		description string
+		eventData   string
+		expected    []*Bounce
+	}{
+		{"permanent failure", `{"event":"failed","severity":"permanent","recipient":"a@example.com","delivery-status":{"description":"No such mailbox"}}`, []*Bounce{{Email: "a@example.com", Type: BounceTypeBounce, Reason: "No such mailbox"}}},
+		{"temporary failure", `{"event":"failed","severity":"temporary","recipient":"a@example.com"}`, []*Bounce{}},
+		{"complaint", `{"event":"complained","recipient":"a@example.com"}`, []*Bounce{{Email: "a@example.com", Type: BounceTypeComplaint}}},
+	}
+
+	for _, scenery := range scenarios {
+		t.Run(scenery.description, func(t *testing.T) {
+			bounces, err := provider.ParseWebhook(nil, getMailgunWebhook(scenery.eventData, "secret"))
+			assert.Nil(t, err)
+			assert.Equal(t, scenery.expected, bounces)
+		})
+	}
+
+	_, err := provider.ParseWebhook(nil, getMailgunWebhook(`{"event":"complained"}`, "wrong"))
+	assert.NotNil(t, err)
+}
+
+func getSignedSnsMessage(t *testing.T, privateKey *rsa.PrivateKey, message *snsMessage) []byte {
+	hash := sha256.Sum256([]byte(getSnsStringToSign(message)))
+	signature, err := rsa.SignPKCS1v15(rand.Reader, privateKey, crypto.SHA256, hash[:])
+	assert.Nil(t, err)
+	message.Signature = base64.StdEncoding.EncodeToString(signature)
+
+	body, err := json.Marshal(message)
+	assert.Nil(t, err)
+	return body
+}
+
+func TestAwsSesParseWebhook(t *testing.T) {
+	privateKey, _ := rsa.GenerateKey(rand.Reader, 2048)
+	template := &x509.Certificate{SerialNumber: big.NewInt(1), Subject: pkix.Name{CommonName: "sns.amazonaws.com"}, NotBefore: time.Now(), NotAfter: time.Now().Add(time.Hour)}
+	certBytes, _ := x509.CreateCertificate(rand.Reader, template, template, &privateKey.PublicKey, privateKey)
+	cert, _ := x509.ParseCertificate(certBytes)
+
+	oldGetSnsCertificate := getSnsCertificate
+	getSnsCertificate = func(certUrl string) (*x509.Certificate, error) { return cert, nil }
+	defer func() { getSnsCertificate = oldGetSnsCertificate }()
+
+	topicArn := "arn:aws:sns:us-east-1:123456789012:ses"
+	provider := NewAwsSesEmailProvider("id", "secret", "us-east-1", topicArn)
+	message := &snsMessage{
+		Type:             "Notification",
+		MessageId:        "m1",
+		TopicArn:         topicArn,
+		Message:          `{"notificationType":"Bounce","bounce":{"bounceType":"Permanent","bouncedRecipients":[{"emailAddress":"a@example.com","diagnosticCode":"smtp; 550 5.1.1"}]}}`,
+		Timestamp:        "2023-06-01T00:00:00.000Z",
+		SignatureVersion: "2",
+		SigningCertURL:   "https://sns.us-east-1.amazonaws.com/SimpleNotificationService-1.pem",
+	}
+
+	bounces, err := provider.ParseWebhook(nil, getSignedSnsMessage(t, privateKey, message))
+	assert.Nil(t, err)
+	assert.Equal(t, []*Bounce{{Email: "a@example.com", Type: BounceTypeBounce, Reason: "smtp; 550 5.1.1"}}, bounces)
+
+	// a topic of another account, signed by SNS as well
+	message.TopicArn = "arn:aws:sns:us-east-1:999999999999:ses"
+	_, err = provider.ParseWebhook(nil, getSignedSnsMessage(t, privateKey, message))
+	assert.NotNil(t, err)
+	message.TopicArn = topicArn
+
+	_, err = NewAwsSesEmailProvider("id", "secret", "us-east-1", "").ParseWebhook(nil, getSignedSnsMessage(t, privateKey, message))
+	assert.NotNil(t, err)
+
+	message.SigningCertURL = "https://sns.example.com/SimpleNotificationService-1.pem"
+	_, err = provider.ParseWebhook(nil, getSignedSnsMessage(t, privateKey, message))
+	assert.NotNil(t, err)
+}
+
+func TestParseSesNotification(t *testing.T) {
+	scenarios := []struct {
+		description  string
+		notification string
+		expected     []*Bounce
+	}{
+		{"transient bounce", `{"notificationType":"Bounce","bounce":{"bounceType":"Transient","bouncedRecipients":[{"emailAddress":"a@example.com"}]}}`, []*Bounce{}},
+		{"complaint", `{"notificationType":"Complaint","complaint":{"complaintFeedbackType":"abuse","complainedRecipients":[{"emailAddress":"a@example.com"}]}}`, []*Bounce{{Email: "a@example.com", Type: BounceTypeComplaint, Reason: "abuse"}}},
+		{"event of a configuration set", `{"eventType":"Bounce","bounce":{"bounceType":"Permanent","bounceSubType":"General","bouncedRecipients":[{"emailAddress":"a@example.com"}]}}`, []*Bounce{{Email: "a@example.com", Type: BounceTypeBounce, Reason: "General"}}},
+		{"delivery", `{"notificationType":"Delivery"}`, []*Bounce{}},
+	}
+
+	for _, scenery := range scenarios {
+		t.Run(scenery.description, func(t *testing.T) {
+			bounces, err := parseSesNotification(scenery.notification)
+			assert.Nil(t, err)
+			assert.Equal(t, scenery.expected, bounces)
+		})
+	}
+}
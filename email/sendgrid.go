@@ -0,0 +1,131 @@
+// Copyright 2023 The Casdoor Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package email
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+)
+
+const sendgridApiBase = "https://api.sendgrid.com"
+
+// SendgridEmailProvider uses the v3 mail send API of SendGrid. The event
+// webhook is signed with the ECDSA key of the "Signed Event Webhook"
+// setting, whose public key is the webhook secret.
+type SendgridEmailProvider struct {
+	ApiKey          string
+	VerificationKey string
+	Endpoint        string
+}
+
+type sendgridEvent struct {
+	Email  string `json:"email"`
+	Event  string `json:"event"`
+	Type   string `json:"type"`
+	Reason string `json:"reason"`
+}
+
+func NewSendgridEmailProvider(apiKey string, verificationKey string, host string) *SendgridEmailProvider {
+	return &SendgridEmailProvider{
+		ApiKey:          apiKey,
+		VerificationKey: verificationKey,
+		Endpoint:        getApiBase(host, sendgridApiBase),
+	}
+}
+
+func (p *SendgridEmailProvider) Send(fromAddress string, fromName string, toAddress string, subject string, content string) error {
+	data := map[string]interface{}{
+		"personalizations": []interface{}{
+			map[string]interface{}{"to": []interface{}{map[string]string{"email": toAddress}}},
+		},
+		"from":    map[string]string{"email": fromAddress, "name": fromName},
+		"subject": subject,
+		"content": []interface{}{map[string]string{"type": "text/html", "value": content}},
+	}
+	body, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest("POST", p.Endpoint+"/v3/mail/send", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+p.ApiKey)
+	req.Header.Set("Content-Type", "application/json")
+
+	_, err = doRequest(req)
+	return err
+}
+
+func (p *SendgridEmailProvider) verifySignature(request *http.Request, body []byte) error {
+	if p.VerificationKey == "" {
+		return fmt.Errorf("the webhook verification key of the provider is empty")
+	}
+
+	keyBytes, err := base64.StdEncoding.DecodeString(p.VerificationKey)
+	if err != nil {
+		return err
+	}
+	key, err := x509.ParsePKIXPublicKey(keyBytes)
+	if err != nil {
+		return err
+	}
+	publicKey, ok := key.(*ecdsa.PublicKey)
+	if !ok {
+		return fmt.Errorf("the webhook verification key of the provider is not an ECDSA key")
+	}
+
+	signature, err := base64.StdEncoding.DecodeString(request.Header.Get("X-Twilio-Email-Event-Webhook-Signature"))
+	if err != nil {
+		return err
+	}
+	hash := sha256.Sum256(append([]byte(request.Header.Get("X-Twilio-Email-Event-Webhook-Timestamp")), body...))
+	if !ecdsa.VerifyASN1(publicKey, hash[:], signature) {
+		return fmt.Errorf("the signature of the webhook is invalid")
+	}
+	return nil
+}
+
+// ParseWebhook handles the event webhook, the "blocked" bounces are
+// transient and the "dropped" events are already suppressed by SendGrid.
+func (p *SendgridEmailProvider) ParseWebhook(request *http.Request, body []byte) ([]*Bounce, error) {
+	err := p.verifySignature(request, body)
+	if err != nil {
+		return nil, err
+	}
+
+	events := []*sendgridEvent{}
+	err = json.Unmarshal(body, &events)
+	if err != nil {
+		return nil, err
+	}
+
+	bounces := []*Bounce{}
+	for _, event := range events {
+		if event.Event == "bounce" && event.Type != "blocked" {
+			bounces = append(bounces, &Bounce{Email: event.Email, Type: BounceTypeBounce, Reason: event.Reason})
+		} else if event.Event == "spamreport" {
+			bounces = append(bounces, &Bounce{Email: event.Email, Type: BounceTypeComplaint})
+		}
+	}
+	return bounces, nil
+}
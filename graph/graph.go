@@ -0,0 +1,61 @@
+// Copyright 2023 The Casdoor Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package graph
+
+import (
+	"context"
+	"sync"
+
+	"github.com/graphql-go/graphql"
+)
+
+type Request struct {
+	Query         string                 `json:"query"`
+	OperationName string                 `json:"operationName"`
+	Variables     map[string]interface{} `json:"variables"`
+}
+
+var (
+	schema     graphql.Schema
+	schemaErr  error
+	schemaOnce sync.Once
+)
+
+func getSchema() (graphql.Schema, error) {
+	schemaOnce.Do(func() {
+		schema, schemaErr = newSchema()
+	})
+	return schema, schemaErr
+}
+
+// Execute runs a GraphQL query on behalf of username, which is the session
+// username like "built-in/admin" or "app/app-built-in", or empty for anonymous.
+func Execute(request *Request, username string, lang string) *graphql.Result {
+	s, err := getSchema()
+	if err != nil {
+		panic(err)
+	}
+
+	ctx := context.WithValue(context.Background(), usernameKey, username)
+	ctx = context.WithValue(ctx, langKey, lang)
+
+	return graphql.Do(graphql.Params{
+		Schema:         s,
+		RequestString:  request.Query,
+		OperationName:  request.OperationName,
+		VariableValues: request.Variables,
+		Context:        ctx,
+	})
+}
@@ -0,0 +1,40 @@
+// Copyright 2023 The Casdoor Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package graph
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExecute(t *testing.T) {
+	scenarios := []struct {
+		description string
+		query       string
+		expected    string
+	}{
+		{"Should reject an id without owner", `{ organization(id: "built-in") { name } }`, "invalid id: built-in"},
+		{"Should reject an unknown field", `{ organization(id: "admin/built-in") { secret } }`, `Cannot query field "secret" on type "Organization".`},
+		{"Should reject a missing argument", `{ users { name } }`, `Field "users" argument "owner" of type "String!" is required but not provided.`},
+	}
+	for _, scenery := range scenarios {
+		t.Run(scenery.description, func(t *testing.T) {
+			result := Execute(&Request{Query: scenery.query}, "", "en")
+			assert.NotEmpty(t, result.Errors)
+			assert.Equal(t, scenery.expected, result.Errors[0].Message)
+		})
+	}
+}
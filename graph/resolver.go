@@ -0,0 +1,254 @@
+// Copyright 2023 The Casdoor Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package graph
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/casdoor/casdoor/authz"
+	"github.com/casdoor/casdoor/i18n"
+	"github.com/casdoor/casdoor/object"
+	"github.com/casdoor/casdoor/util"
+	"github.com/graphql-go/graphql"
+)
+
+type contextKey string
+
+const (
+	usernameKey contextKey = "username"
+	langKey     contextKey = "lang"
+)
+
+func getUsername(p graphql.ResolveParams) string {
+	username, _ := p.Context.Value(usernameKey).(string)
+	return username
+}
+
+func getLang(p graphql.ResolveParams) string {
+	lang, _ := p.Context.Value(langKey).(string)
+	return lang
+}
+
+// authorize applies the policy of the REST GET endpoint that returns the same
+// object, so every field is visible exactly when the REST API would show it.
+func authorize(p graphql.ResolveParams, urlPath string, objOwner string, objName string) error {
+	subOwner, subName := "anonymous", "anonymous"
+	if username := getUsername(p); username != "" {
+		subOwner, subName = util.GetOwnerAndNameFromId(username)
+	}
+
+	if !authz.IsAllowed(subOwner, subName, http.MethodGet, urlPath, objOwner, objName) {
+		return fmt.Errorf(i18n.Translate(getLang(p), "auth:Unauthorized operation"))
+	}
+	return nil
+}
+
+func getIdArg(p graphql.ResolveParams) (string, string, string, error) {
+	id, _ := p.Args["id"].(string)
+	if strings.Count(id, "/") != 1 {
+		return "", "", "", fmt.Errorf("invalid id: %s", id)
+	}
+
+	owner, name := util.GetOwnerAndNameFromId(id)
+	return id, owner, name, nil
+}
+
+func getOwnerArg(p graphql.ResolveParams) string {
+	owner, _ := p.Args["owner"].(string)
+	return owner
+}
+
+func resolveOrganization(p graphql.ResolveParams) (interface{}, error) {
+	id, owner, name, err := getIdArg(p)
+	if err != nil {
+		return nil, err
+	}
+	if err = authorize(p, "/api/get-organization", owner, name); err != nil {
+		return nil, err
+	}
+
+	return object.GetMaskedOrganization(object.GetOrganization(id)), nil
+}
+
+func resolveOrganizations(p graphql.ResolveParams) (interface{}, error) {
+	owner := getOwnerArg(p)
+	if err := authorize(p, "/api/get-organizations", owner, ""); err != nil {
+		return nil, err
+	}
+
+	return object.GetMaskedOrganizations(object.GetOrganizations(owner)), nil
+}
+
+func resolveOrganizationApplications(p graphql.ResolveParams) (interface{}, error) {
+	organization := p.Source.(*object.Organization)
+	if err := authorize(p, "/api/get-organization-applications", "admin", ""); err != nil {
+		return nil, err
+	}
+
+	return object.GetMaskedApplications(object.GetOrganizationApplications("admin", organization.Name), getUsername(p)), nil
+}
+
+func resolveOrganizationUsers(p graphql.ResolveParams) (interface{}, error) {
+	organization := p.Source.(*object.Organization)
+	if err := authorize(p, "/api/get-users", organization.Name, ""); err != nil {
+		return nil, err
+	}
+
+	return object.GetMaskedUsers(object.GetUsers(organization.Name)), nil
+}
+
+func resolveApplication(p graphql.ResolveParams) (interface{}, error) {
+	id, owner, name, err := getIdArg(p)
+	if err != nil {
+		return nil, err
+	}
+	if err = authorize(p, "/api/get-application", owner, name); err != nil {
+		return nil, err
+	}
+
+	return object.GetMaskedApplication(object.GetApplication(id), getUsername(p)), nil
+}
+
+func resolveApplications(p graphql.ResolveParams) (interface{}, error) {
+	owner := getOwnerArg(p)
+	if err := authorize(p, "/api/get-applications", owner, ""); err != nil {
+		return nil, err
+	}
+
+	return object.GetMaskedApplications(object.GetApplications(owner), getUsername(p)), nil
+}
+
+func resolveApplicationOrganization(p graphql.ResolveParams) (interface{}, error) {
+	application := p.Source.(*object.Application)
+	if err := authorize(p, "/api/get-organization", "admin", application.Organization); err != nil {
+		return nil, err
+	}
+
+	return object.GetMaskedOrganization(object.GetOrganization(util.GetId("admin", application.Organization))), nil
+}
+
+func resolveApplicationProviders(p graphql.ResolveParams) (interface{}, error) {
+	application := p.Source.(*object.Application)
+	return application.Providers, nil
+}
+
+func resolveApplicationCert(p graphql.ResolveParams) (interface{}, error) {
+	application := p.Source.(*object.Application)
+	if application.Cert == "" {
+		return nil, nil
+	}
+	if err := authorize(p, "/api/get-cert", "admin", application.Cert); err != nil {
+		return nil, err
+	}
+
+	return object.GetMaskedCert(object.GetCert(util.GetId("admin", application.Cert))), nil
+}
+
+func resolveProviderItemProvider(p graphql.ResolveParams) (interface{}, error) {
+	providerItem := p.Source.(*object.ProviderItem)
+	if providerItem.Provider == nil {
+		return nil, nil
+	}
+	if err := authorize(p, "/api/get-provider", providerItem.Provider.Owner, providerItem.Provider.Name); err != nil {
+		return nil, err
+	}
+
+	return object.GetMaskedProvider(providerItem.Provider), nil
+}
+
+func resolveProvider(p graphql.ResolveParams) (interface{}, error) {
+	id, owner, name, err := getIdArg(p)
+	if err != nil {
+		return nil, err
+	}
+	if err = authorize(p, "/api/get-provider", owner, name); err != nil {
+		return nil, err
+	}
+
+	return object.GetMaskedProvider(object.GetProvider(id)), nil
+}
+
+func resolveProviders(p graphql.ResolveParams) (interface{}, error) {
+	owner := getOwnerArg(p)
+	if err := authorize(p, "/api/get-providers", owner, ""); err != nil {
+		return nil, err
+	}
+
+	return object.GetMaskedProviders(object.GetProviders(owner)), nil
+}
+
+func resolveProviderCert(p graphql.ResolveParams) (interface{}, error) {
+	provider := p.Source.(*object.Provider)
+	if provider.Cert == "" {
+		return nil, nil
+	}
+	if err := authorize(p, "/api/get-cert", provider.Owner, provider.Cert); err != nil {
+		return nil, err
+	}
+
+	return object.GetMaskedCert(object.GetCert(util.GetId(provider.Owner, provider.Cert))), nil
+}
+
+func resolveCert(p graphql.ResolveParams) (interface{}, error) {
+	id, owner, name, err := getIdArg(p)
+	if err != nil {
+		return nil, err
+	}
+	if err = authorize(p, "/api/get-cert", owner, name); err != nil {
+		return nil, err
+	}
+
+	return object.GetMaskedCert(object.GetCert(id)), nil
+}
+
+func resolveCerts(p graphql.ResolveParams) (interface{}, error) {
+	owner := getOwnerArg(p)
+	if err := authorize(p, "/api/get-certs", owner, ""); err != nil {
+		return nil, err
+	}
+
+	return object.GetMaskedCerts(object.GetCerts(owner)), nil
+}
+
+func resolveUser(p graphql.ResolveParams) (interface{}, error) {
+	id, owner, name, err := getIdArg(p)
+	if err != nil {
+		return nil, err
+	}
+	if err = authorize(p, "/api/get-user", owner, name); err != nil {
+		return nil, err
+	}
+
+	organization := object.GetOrganization(util.GetId("admin", owner))
+	if organization == nil || !organization.IsProfilePublic {
+		hasPermission, err := object.CheckUserPermission(getUsername(p), id, owner, false, getLang(p))
+		if !hasPermission {
+			return nil, err
+		}
+	}
+
+	return object.GetMaskedUser(object.GetUser(id)), nil
+}
+
+func resolveUsers(p graphql.ResolveParams) (interface{}, error) {
+	owner := getOwnerArg(p)
+	if err := authorize(p, "/api/get-users", owner, ""); err != nil {
+		return nil, err
+	}
+
+	return object.GetMaskedUsers(object.GetUsers(owner)), nil
+}
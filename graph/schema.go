@@ -0,0 +1,210 @@
+// Copyright 2023 The Casdoor Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package graph
+
+import (
+	"github.com/graphql-go/graphql"
+)
+
+// Scalar fields are resolved by graphql-go from the json tags of the object
+// structs, so their names here must match the REST JSON field names.
+func getScalarFields(fields map[string]graphql.Output) graphql.Fields {
+	res := graphql.Fields{}
+	for name, typ := range fields {
+		res[name] = &graphql.Field{Type: typ}
+	}
+	return res
+}
+
+func addFields(fields graphql.Fields, extraFields graphql.Fields) graphql.Fields {
+	for name, field := range extraFields {
+		fields[name] = field
+	}
+	return fields
+}
+
+var stringList = graphql.NewList(graphql.String)
+
+var idArgs = graphql.FieldConfigArgument{
+	"id": &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.String)},
+}
+
+var ownerArgs = graphql.FieldConfigArgument{
+	"owner": &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.String)},
+}
+
+var certType = graphql.NewObject(graphql.ObjectConfig{
+	Name: "Cert",
+	Fields: getScalarFields(map[string]graphql.Output{
+		"owner":           graphql.String,
+		"name":            graphql.String,
+		"createdTime":     graphql.String,
+		"displayName":     graphql.String,
+		"scope":           graphql.String,
+		"type":            graphql.String,
+		"cryptoAlgorithm": graphql.String,
+		"bitSize":         graphql.Int,
+		"expireInYears":   graphql.Int,
+		"certificate":     graphql.String,
+		"privateKey":      graphql.String,
+	}),
+})
+
+var providerType = graphql.NewObject(graphql.ObjectConfig{
+	Name: "Provider",
+	Fields: addFields(getScalarFields(map[string]graphql.Output{
+		"owner":        graphql.String,
+		"name":         graphql.String,
+		"createdTime":  graphql.String,
+		"displayName":  graphql.String,
+		"category":     graphql.String,
+		"type":         graphql.String,
+		"subType":      graphql.String,
+		"method":       graphql.String,
+		"clientId":     graphql.String,
+		"clientSecret": graphql.String,
+		"cert":         graphql.String,
+		"host":         graphql.String,
+		"port":         graphql.Int,
+		"endpoint":     graphql.String,
+		"domain":       graphql.String,
+		"bucket":       graphql.String,
+		"issuerUrl":    graphql.String,
+		"providerUrl":  graphql.String,
+	}), graphql.Fields{
+		"certObj": &graphql.Field{Type: certType, Resolve: resolveProviderCert},
+	}),
+})
+
+var providerItemType = graphql.NewObject(graphql.ObjectConfig{
+	Name: "ProviderItem",
+	Fields: addFields(getScalarFields(map[string]graphql.Output{
+		"owner":     graphql.String,
+		"name":      graphql.String,
+		"canSignUp": graphql.Boolean,
+		"canSignIn": graphql.Boolean,
+		"canUnlink": graphql.Boolean,
+		"prompted":  graphql.Boolean,
+		"alertType": graphql.String,
+		"rule":      graphql.String,
+	}), graphql.Fields{
+		"provider": &graphql.Field{Type: providerType, Resolve: resolveProviderItemProvider},
+	}),
+})
+
+var userType = graphql.NewObject(graphql.ObjectConfig{
+	Name: "User",
+	Fields: getScalarFields(map[string]graphql.Output{
+		"owner":             graphql.String,
+		"name":              graphql.String,
+		"createdTime":       graphql.String,
+		"updatedTime":       graphql.String,
+		"id":                graphql.String,
+		"type":              graphql.String,
+		"displayName":       graphql.String,
+		"avatar":            graphql.String,
+		"email":             graphql.String,
+		"emailVerified":     graphql.Boolean,
+		"phone":             graphql.String,
+		"countryCode":       graphql.String,
+		"region":            graphql.String,
+		"affiliation":       graphql.String,
+		"tag":               graphql.String,
+		"signupApplication": graphql.String,
+		"isAdmin":           graphql.Boolean,
+		"isGlobalAdmin":     graphql.Boolean,
+		"isForbidden":       graphql.Boolean,
+		"isDeleted":         graphql.Boolean,
+	}),
+})
+
+var organizationType *graphql.Object
+
+var applicationType = graphql.NewObject(graphql.ObjectConfig{
+	Name: "Application",
+	Fields: graphql.FieldsThunk(func() graphql.Fields {
+		return addFields(getScalarFields(map[string]graphql.Output{
+			"owner":                graphql.String,
+			"name":                 graphql.String,
+			"createdTime":          graphql.String,
+			"displayName":          graphql.String,
+			"logo":                 graphql.String,
+			"homepageUrl":          graphql.String,
+			"description":          graphql.String,
+			"organization":         graphql.String,
+			"cert":                 graphql.String,
+			"enablePassword":       graphql.Boolean,
+			"enableSignUp":         graphql.Boolean,
+			"clientId":             graphql.String,
+			"clientSecret":         graphql.String,
+			"redirectUris":         stringList,
+			"tokenFormat":          graphql.String,
+			"expireInHours":        graphql.Int,
+			"refreshExpireInHours": graphql.Int,
+		}), graphql.Fields{
+			"organizationObj": &graphql.Field{Type: organizationType, Resolve: resolveApplicationOrganization},
+			"providers":       &graphql.Field{Type: graphql.NewList(providerItemType), Resolve: resolveApplicationProviders},
+			"certObj":         &graphql.Field{Type: certType, Resolve: resolveApplicationCert},
+		})
+	}),
+})
+
+func init() {
+	organizationType = graphql.NewObject(graphql.ObjectConfig{
+		Name: "Organization",
+		Fields: graphql.FieldsThunk(func() graphql.Fields {
+			return addFields(getScalarFields(map[string]graphql.Output{
+				"owner":              graphql.String,
+				"name":               graphql.String,
+				"createdTime":        graphql.String,
+				"displayName":        graphql.String,
+				"websiteUrl":         graphql.String,
+				"favicon":            graphql.String,
+				"passwordType":       graphql.String,
+				"countryCodes":       stringList,
+				"defaultAvatar":      graphql.String,
+				"defaultApplication": graphql.String,
+				"tags":               stringList,
+				"languages":          stringList,
+				"initScore":          graphql.Int,
+				"enableSoftDeletion": graphql.Boolean,
+				"isProfilePublic":    graphql.Boolean,
+			}), graphql.Fields{
+				"applications": &graphql.Field{Type: graphql.NewList(applicationType), Resolve: resolveOrganizationApplications},
+				"users":        &graphql.Field{Type: graphql.NewList(userType), Resolve: resolveOrganizationUsers},
+			})
+		}),
+	})
+}
+
+func newSchema() (graphql.Schema, error) {
+	query := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Query",
+		Fields: graphql.Fields{
+			"organization":  &graphql.Field{Type: organizationType, Args: idArgs, Resolve: resolveOrganization},
+			"organizations": &graphql.Field{Type: graphql.NewList(organizationType), Args: ownerArgs, Resolve: resolveOrganizations},
+			"application":   &graphql.Field{Type: applicationType, Args: idArgs, Resolve: resolveApplication},
+			"applications":  &graphql.Field{Type: graphql.NewList(applicationType), Args: ownerArgs, Resolve: resolveApplications},
+			"provider":      &graphql.Field{Type: providerType, Args: idArgs, Resolve: resolveProvider},
+			"providers":     &graphql.Field{Type: graphql.NewList(providerType), Args: ownerArgs, Resolve: resolveProviders},
+			"cert":          &graphql.Field{Type: certType, Args: idArgs, Resolve: resolveCert},
+			"certs":         &graphql.Field{Type: graphql.NewList(certType), Args: ownerArgs, Resolve: resolveCerts},
+			"user":          &graphql.Field{Type: userType, Args: idArgs, Resolve: resolveUser},
+			"users":         &graphql.Field{Type: graphql.NewList(userType), Args: ownerArgs, Resolve: resolveUsers},
+		},
+	})
+
+	return graphql.NewSchema(graphql.SchemaConfig{Query: query})
+}
@@ -0,0 +1,109 @@
+// Copyright 2023 The Casdoor Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package idv
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// CustomIdvProvider talks to a verification service of its own: the
+// endpoint is posted {"reference", "returnUrl", "notifyUrl"} with the secret
+// as bearer token and returns {"id", "url"}. The result is posted back to
+// notifyUrl as {"id", "reference", "status", "level", "reason"}, signed in
+// the "X-Signature" header with the hex HMAC-SHA256 of the body.
+type CustomIdvProvider struct {
+	Secret   string
+	Endpoint string
+}
+
+type customIdvResult struct {
+	Id        string `json:"id"`
+	Reference string `json:"reference"`
+	Status    string `json:"status"`
+	Level     int    `json:"level"`
+	Reason    string `json:"reason"`
+}
+
+func NewCustomIdvProvider(secret string, endpoint string) *CustomIdvProvider {
+	return &CustomIdvProvider{
+		Secret:   secret,
+		Endpoint: endpoint,
+	}
+}
+
+func (idv *CustomIdvProvider) CreateSession(reference string, returnUrl string, notifyUrl string) (*VerificationSession, error) {
+	if idv.Endpoint == "" {
+		return nil, fmt.Errorf("the endpoint of the provider is empty")
+	}
+
+	data := map[string]string{
+		"reference": reference,
+		"returnUrl": returnUrl,
+		"notifyUrl": notifyUrl,
+	}
+
+	resp := struct {
+		Id  string `json:"id"`
+		Url string `json:"url"`
+	}{}
+	err := postJson(idv.Endpoint, map[string]string{"Authorization": "Bearer " + idv.Secret}, data, &resp)
+	if err != nil {
+		return nil, err
+	}
+	if resp.Url == "" {
+		return nil, fmt.Errorf("the verification session has no URL")
+	}
+
+	return &VerificationSession{Id: resp.Id, Url: resp.Url}, nil
+}
+
+func (idv *CustomIdvProvider) ParseWebhook(request *http.Request, body []byte) (*VerificationResult, error) {
+	err := verifyHmacSignature(request.Header.Get("X-Signature"), body, idv.Secret)
+	if err != nil {
+		return nil, err
+	}
+
+	result := customIdvResult{}
+	err = json.Unmarshal(body, &result)
+	if err != nil {
+		return nil, err
+	}
+
+	status := ""
+	for _, s := range []string{VerificationStatusPending, VerificationStatusApproved, VerificationStatusDeclined, VerificationStatusExpired} {
+		if strings.EqualFold(result.Status, s) {
+			status = s
+		}
+	}
+
+	// an approval without level is of the document only
+	level := result.Level
+	if status != VerificationStatusApproved {
+		level = VerificationLevelNone
+	} else if level <= VerificationLevelNone {
+		level = VerificationLevelDocument
+	}
+
+	return &VerificationResult{
+		SessionId: result.Id,
+		Reference: result.Reference,
+		Status:    status,
+		Level:     level,
+		Reason:    result.Reason,
+	}, nil
+}
@@ -0,0 +1,70 @@
+// Copyright 2023 The Casdoor Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package idv
+
+import "net/http"
+
+const (
+	VerificationStatusPending  = "Pending"
+	VerificationStatusApproved = "Approved"
+	VerificationStatusDeclined = "Declined"
+	VerificationStatusExpired  = "Expired"
+)
+
+// The verification levels of a user, a higher level includes the lower ones.
+const (
+	VerificationLevelNone     = 0
+	VerificationLevelDocument = 1
+	VerificationLevelLiveness = 2
+)
+
+// VerificationSession is a session of the provider where the user submits
+// the documents, Url is where the user is sent to.
+type VerificationSession struct {
+	Id  string
+	Url string
+}
+
+// VerificationResult is a webhook event of the provider, normalized to the
+// status of the session. Status is empty for the events that don't change
+// it.
+type VerificationResult struct {
+	SessionId string
+	// Reference is the name of the verification in Casdoor when the event
+	// carries it
+	Reference string
+	Status    string
+	Level     int
+	Reason    string
+}
+
+// IdvProvider is an external identity verification (KYC) provider which
+// checks an ID document and usually the liveness of the user.
+type IdvProvider interface {
+	// CreateSession starts a session for the verification named reference,
+	// the user comes back to returnUrl and the result is posted to notifyUrl
+	// unless the webhook is configured at the provider
+	CreateSession(reference string, returnUrl string, notifyUrl string) (*VerificationSession, error)
+	ParseWebhook(request *http.Request, body []byte) (*VerificationResult, error)
+}
+
+func GetIdvProvider(typ string, clientId string, clientSecret string, endpoint string) IdvProvider {
+	if typ == "Veriff" {
+		return NewVeriffIdvProvider(clientId, clientSecret, endpoint)
+	} else if typ == "Custom HTTP" {
+		return NewCustomIdvProvider(clientSecret, endpoint)
+	}
+	return nil
+}
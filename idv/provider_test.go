@@ -0,0 +1,84 @@
+// Copyright 2023 The Casdoor Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package idv
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func getSignedRequest(header string, body []byte, secret string) *http.Request {
+	request, _ := http.NewRequest("POST", "/api/identity-verification-webhook/admin/provider", nil)
+	request.Header.Set(header, getHmacSignature(body, secret))
+	return request
+}
+
+func TestVeriffParseWebhook(t *testing.T) {
+	provider := NewVeriffIdvProvider("key", "secret", "")
+
+	scenarios := []struct {
+		description string
+		body        string
+		status      string
+		level       int
+	}{
+		{"approved", `{"status":"success","verification":{"id":"s1","status":"approved","vendorData":"org/v1"}}`, VerificationStatusApproved, VerificationLevelLiveness},
+		{"declined", `{"status":"success","verification":{"id":"s1","status":"declined","reason":"Document expired"}}`, VerificationStatusDeclined, VerificationLevelNone},
+		{"resubmission", `{"status":"success","verification":{"id":"s1","status":"resubmission_requested"}}`, VerificationStatusPending, VerificationLevelNone},
+		{"event webhook", `{"id":"s1","action":"submitted","vendorData":"org/v1"}`, "", VerificationLevelNone},
+	}
+
+	for _, scenery := range scenarios {
+		t.Run(scenery.description, func(t *testing.T) {
+			body := []byte(scenery.body)
+			result, err := provider.ParseWebhook(getSignedRequest("X-HMAC-SIGNATURE", body, "secret"), body)
+			assert.Nil(t, err)
+			assert.Equal(t, scenery.status, result.Status)
+			assert.Equal(t, scenery.level, result.Level)
+		})
+	}
+
+	body := []byte(`{"verification":{"id":"s1","status":"approved"}}`)
+	_, err := provider.ParseWebhook(getSignedRequest("X-HMAC-SIGNATURE", body, "wrong"), body)
+	assert.NotNil(t, err)
+}
+
+func TestCustomParseWebhook(t *testing.T) {
+	provider := NewCustomIdvProvider("secret", "https://kyc.example.com/sessions")
+
+	scenarios := []struct {
+		description string
+		body        string
+		status      string
+		level       int
+	}{
+		{"approved with liveness", `{"id":"s1","status":"approved","level":2}`, VerificationStatusApproved, VerificationLevelLiveness},
+		{"approved without level", `{"id":"s1","status":"Approved"}`, VerificationStatusApproved, VerificationLevelDocument},
+		{"declined keeps no level", `{"id":"s1","status":"declined","level":2}`, VerificationStatusDeclined, VerificationLevelNone},
+		{"unknown status", `{"id":"s1","status":"processing"}`, "", VerificationLevelNone},
+	}
+
+	for _, scenery := range scenarios {
+		t.Run(scenery.description, func(t *testing.T) {
+			body := []byte(scenery.body)
+			result, err := provider.ParseWebhook(getSignedRequest("X-Signature", body, "secret"), body)
+			assert.Nil(t, err)
+			assert.Equal(t, scenery.status, result.Status)
+			assert.Equal(t, scenery.level, result.Level)
+		})
+	}
+}
@@ -0,0 +1,125 @@
+// Copyright 2023 The Casdoor Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package idv
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+const veriffApiBase = "https://stationapi.veriff.com"
+
+// VeriffIdvProvider uses the sessions API of Veriff, which always checks
+// the document together with a selfie. The decision webhook is configured
+// in the Veriff integration and signed with its shared secret.
+type VeriffIdvProvider struct {
+	ApiKey       string
+	SharedSecret string
+	Endpoint     string
+}
+
+type veriffSessionResponse struct {
+	Status       string `json:"status"`
+	Verification struct {
+		Id  string `json:"id"`
+		Url string `json:"url"`
+	} `json:"verification"`
+}
+
+type veriffDecision struct {
+	Status       string `json:"status"`
+	Verification *struct {
+		Id         string `json:"id"`
+		Status     string `json:"status"`
+		Reason     string `json:"reason"`
+		VendorData string `json:"vendorData"`
+	} `json:"verification"`
+}
+
+func NewVeriffIdvProvider(apiKey string, sharedSecret string, endpoint string) *VeriffIdvProvider {
+	if endpoint == "" {
+		endpoint = veriffApiBase
+	}
+
+	return &VeriffIdvProvider{
+		ApiKey:       apiKey,
+		SharedSecret: sharedSecret,
+		Endpoint:     strings.TrimSuffix(endpoint, "/"),
+	}
+}
+
+func (idv *VeriffIdvProvider) CreateSession(reference string, returnUrl string, notifyUrl string) (*VerificationSession, error) {
+	data := map[string]interface{}{
+		"verification": map[string]interface{}{
+			"callback":   returnUrl,
+			"vendorData": reference,
+		},
+	}
+
+	resp := veriffSessionResponse{}
+	err := postJson(idv.Endpoint+"/v1/sessions", map[string]string{"X-AUTH-CLIENT": idv.ApiKey}, data, &resp)
+	if err != nil {
+		return nil, err
+	}
+	if resp.Verification.Url == "" {
+		return nil, fmt.Errorf("the Veriff session has no URL, status: %s", resp.Status)
+	}
+
+	return &VerificationSession{Id: resp.Verification.Id, Url: resp.Verification.Url}, nil
+}
+
+func getVeriffStatus(status string) (string, int) {
+	switch status {
+	case "approved":
+		return VerificationStatusApproved, VerificationLevelLiveness
+	case "declined":
+		return VerificationStatusDeclined, VerificationLevelNone
+	case "expired", "abandoned":
+		return VerificationStatusExpired, VerificationLevelNone
+	case "resubmission_requested", "review":
+		return VerificationStatusPending, VerificationLevelNone
+	default:
+		return "", VerificationLevelNone
+	}
+}
+
+// ParseWebhook handles the decision webhook, the event webhook (a session
+// was started or submitted) changes nothing.
+func (idv *VeriffIdvProvider) ParseWebhook(request *http.Request, body []byte) (*VerificationResult, error) {
+	err := verifyHmacSignature(request.Header.Get("X-HMAC-SIGNATURE"), body, idv.SharedSecret)
+	if err != nil {
+		return nil, err
+	}
+
+	decision := veriffDecision{}
+	err = json.Unmarshal(body, &decision)
+	if err != nil {
+		return nil, err
+	}
+	if decision.Verification == nil {
+		return &VerificationResult{}, nil
+	}
+
+	status, level := getVeriffStatus(decision.Verification.Status)
+	return &VerificationResult{
+		SessionId: decision.Verification.Id,
+		Reference: decision.Verification.VendorData,
+		Status:    status,
+		Level:     level,
+		Reason:    decision.Verification.Reason,
+	}, nil
+}
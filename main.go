@@ -15,12 +15,16 @@
 package main
 
 import (
+	"context"
 	"flag"
 	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
 
 	"github.com/beego/beego"
 	"github.com/beego/beego/logs"
-	_ "github.com/beego/beego/session/redis"
 	"github.com/casdoor/casdoor/authz"
 	"github.com/casdoor/casdoor/conf"
 	"github.com/casdoor/casdoor/controllers"
@@ -28,18 +32,44 @@ import (
 	"github.com/casdoor/casdoor/proxy"
 	"github.com/casdoor/casdoor/routers"
 	_ "github.com/casdoor/casdoor/routers"
+	"github.com/casdoor/casdoor/rpc"
 	"github.com/casdoor/casdoor/util"
 )
 
 func main() {
 	createDatabase := flag.Bool("createDatabase", false, "true if you need Casdoor to create database")
+	exportConfig := flag.String("exportConfig", "", "export the configuration bundle to the YAML or JSON file and exit")
+	importConfig := flag.String("importConfig", "", "apply the configuration bundle from the YAML or JSON file and exit")
+	includeSecrets := flag.Bool("includeSecrets", false, "true if -exportConfig should export secrets instead of ***")
+	dryRun := flag.Bool("dryRun", false, "true if -importConfig should only print the plan")
+	backup := flag.String("backup", "", "write an encrypted backup of all tables to the file and exit")
+	restore := flag.String("restore", "", "restore the encrypted backup from the file and exit")
+	excludeRecords := flag.Bool("excludeRecords", false, "true if -backup should leave out the records")
+	moveOrganization := flag.String("moveOrganization", "", "move the users of the organization to the shard of -toShard and exit, needs the maintenance mode")
+	toShard := flag.String("toShard", "", "the shard of app.conf for -moveOrganization, empty for the main database")
 	flag.Parse()
 
 	object.InitAdapter()
 	object.DoMigration()
 	object.CreateTables(*createDatabase)
 
+	if *exportConfig != "" || *importConfig != "" {
+		runConfigCommand(*exportConfig, *importConfig, *includeSecrets, *dryRun)
+		return
+	}
+
+	if *backup != "" || *restore != "" {
+		runBackupCommand(*backup, *restore, *excludeRecords)
+		return
+	}
+
+	if *moveOrganization != "" {
+		runShardCommand(*moveOrganization, *toShard)
+		return
+	}
+
 	object.InitDb()
+	object.InitRuntimeSettings()
 	object.InitFromFile()
 	object.InitDefaultStorageProvider()
 	object.InitLdapAutoSynchronizer()
@@ -47,6 +77,16 @@ func main() {
 	authz.InitAuthz()
 
 	util.SafeGoroutine(func() { object.RunSyncUsersJob() })
+	util.SafeGoroutine(func() { object.RunRoleAssignmentJob() })
+	util.SafeGoroutine(func() { object.RunUsageJob() })
+	util.SafeGoroutine(func() { object.RunAccessRequestJob() })
+	util.SafeGoroutine(func() { object.RunAccessReviewJob() })
+	util.SafeGoroutine(func() { object.RunRetentionJob() })
+	util.SafeGoroutine(func() { object.RunBackupJob() })
+	util.SafeGoroutine(func() { object.RunCertExpiryJob() })
+	util.SafeGoroutine(func() { object.RunContactChangeJob() })
+	util.SafeGoroutine(func() { object.RunTokenAnomalyJob() })
+	object.StartTaskWorkers()
 
 	// beego.DelStaticPath("/static")
 	// beego.SetStaticPath("/static", "web/build/static")
@@ -56,6 +96,7 @@ func main() {
 	beego.SetStaticPath("/files", "files")
 	// https://studygolang.com/articles/2303
 	beego.InsertFilter("*", beego.BeforeRouter, routers.StaticFilter)
+	beego.InsertFilter("*", beego.BeforeRouter, routers.MaintenanceFilter)
 	beego.InsertFilter("*", beego.BeforeRouter, routers.AutoSigninFilter)
 	beego.InsertFilter("*", beego.BeforeRouter, routers.CorsFilter)
 	beego.InsertFilter("*", beego.BeforeRouter, routers.AuthzFilter)
@@ -67,7 +108,7 @@ func main() {
 		beego.BConfig.WebConfig.Session.SessionProvider = "file"
 		beego.BConfig.WebConfig.Session.SessionProviderConfig = "./tmp"
 	} else {
-		beego.BConfig.WebConfig.Session.SessionProvider = "redis"
+		beego.BConfig.WebConfig.Session.SessionProvider = object.RedisSessionProvider
 		beego.BConfig.WebConfig.Session.SessionProviderConfig = conf.GetConfigString("redisEndpoint")
 	}
 	beego.BConfig.WebConfig.Session.SessionCookieLifeTime = 3600 * 24 * 30
@@ -82,6 +123,88 @@ func main() {
 	logs.SetLogFuncCall(false)
 
 	go controllers.StartLdapServer()
+	go object.StartAcmeServer(beego.BeeApp.Handlers)
+	go object.StartEnforcerWatcher()
+	go object.StartObjectCacheWatcher()
+	go rpc.StartGrpcServer()
+
+	util.SafeGoroutine(drainOnShutdownSignal)
+	beego.RunWithMiddleWares(fmt.Sprintf(":%v", port), routers.DrainMiddleware)
+}
+
+// drainOnShutdownSignal lets the readiness probe fail on SIGTERM or SIGINT,
+// waits for the requests in flight and then stops the server, beego.Run
+// returns after that.
+func drainOnShutdownSignal() {
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGTERM, os.Interrupt)
+	<-signals
+
+	timeout := object.GetDrainTimeout()
+	object.StartDrain()
+	logs.Info(fmt.Sprintf("shutting down, draining the requests in flight for up to %s", timeout))
+	if !object.WaitForDrain(timeout, 0) {
+		logs.Warning(fmt.Sprintf("the requests in flight didn't finish within %s", timeout))
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	err := beego.BeeApp.Server.Shutdown(ctx)
+	if err != nil {
+		logs.Warning(fmt.Sprintf("failed to shut down the server, %s", err.Error()))
+	}
+}
+
+func runConfigCommand(exportPath string, importPath string, includeSecrets bool, dryRun bool) {
+	if exportPath != "" {
+		err := object.ExportConfigBundleToFile(exportPath, includeSecrets)
+		if err != nil {
+			panic(err)
+		}
+
+		fmt.Printf("Exported the configuration to: %s\n", exportPath)
+		return
+	}
+
+	changes, err := object.ApplyConfigBundleFromFile(importPath, dryRun)
+	for _, change := range changes {
+		fmt.Printf("%s %s %s %v\n", change.Action, change.Type, change.Id, change.Fields)
+	}
+	if err != nil {
+		panic(err)
+	}
+}
+
+func runBackupCommand(backupPath string, restorePath string, excludeRecords bool) {
+	var summary *object.BackupSummary
+	var err error
+	if backupPath != "" {
+		summary, err = object.CreateBackupFile(backupPath, excludeRecords)
+	} else {
+		summary, err = object.RestoreBackupFile(restorePath)
+	}
+	if err != nil {
+		panic(err)
+	}
+
+	for table, rows := range summary.Rows {
+		fmt.Printf("%s: %d rows\n", table, rows)
+	}
+	if backupPath != "" {
+		fmt.Printf("Backed up to: %s\n", backupPath)
+	} else {
+		fmt.Printf("Restored from: %s\n", restorePath)
+	}
+}
+
+func runShardCommand(organization string, shard string) {
+	// the maintenance mode can be enabled in the settings instead of app.conf
+	object.ReloadSettings()
+
+	result, err := object.MoveOrganizationShard(organization, shard)
+	if err != nil {
+		panic(err)
+	}
 
-	beego.Run(fmt.Sprintf(":%v", port))
+	fmt.Printf("Moved %d users of %s from \"%s\" to \"%s\"\n", result.Users, result.Organization, result.From, result.To)
 }
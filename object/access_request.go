@@ -0,0 +1,386 @@
+// Copyright 2023 The Casdoor Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package object
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/beego/beego/logs"
+	"github.com/casdoor/casdoor/i18n"
+	"github.com/casdoor/casdoor/util"
+	"github.com/xorm-io/core"
+)
+
+const (
+	AccessRequestTypeRole       = "Role"
+	AccessRequestTypePermission = "Permission"
+
+	AccessRequestStatePending  = "Pending"
+	AccessRequestStateApproved = "Approved"
+	AccessRequestStateDenied   = "Denied"
+	AccessRequestStateExpired  = "Expired"
+)
+
+const accessRequestJobInterval = time.Minute
+
+type AccessRequestEvent struct {
+	Time    string `json:"time"`
+	User    string `json:"user"`
+	Action  string `json:"action"`
+	Comment string `json:"comment"`
+}
+
+// AccessRequest is the request of a user to be added to a role or to a
+// permission (e.g. the one giving access to an application), the grant
+// ends DurationHours after the approval unless it's 0.
+type AccessRequest struct {
+	Owner       string `xorm:"varchar(100) notnull pk" json:"owner"`
+	Name        string `xorm:"varchar(100) notnull pk" json:"name"`
+	CreatedTime string `xorm:"varchar(100)" json:"createdTime"`
+
+	Requester     string   `xorm:"varchar(100) index" json:"requester"`
+	Type          string   `xorm:"varchar(100)" json:"type"`
+	Target        string   `xorm:"varchar(100)" json:"target"`
+	Reason        string   `xorm:"mediumtext" json:"reason"`
+	DurationHours int      `json:"durationHours"`
+	Approvers     []string `xorm:"mediumtext" json:"approvers"`
+
+	State        string                `xorm:"varchar(100)" json:"state"`
+	Approver     string                `xorm:"varchar(100)" json:"approver"`
+	DecisionTime string                `xorm:"varchar(100)" json:"decisionTime"`
+	ExpireTime   string                `xorm:"varchar(100)" json:"expireTime"`
+	Events       []*AccessRequestEvent `xorm:"mediumtext" json:"events"`
+}
+
+func GetAccessRequestCount(owner, field, value string) int {
+	session := GetSession(owner, -1, -1, field, value, "", "")
+	count, err := session.Count(&AccessRequest{})
+	if err != nil {
+		panic(err)
+	}
+
+	return int(count)
+}
+
+func GetAccessRequests(owner string) []*AccessRequest {
+	accessRequests := []*AccessRequest{}
+	err := adapter.Engine.Desc("created_time").Find(&accessRequests, &AccessRequest{Owner: owner})
+	if err != nil {
+		panic(err)
+	}
+
+	return accessRequests
+}
+
+func GetPaginationAccessRequests(owner string, offset, limit int, field, value, sortField, sortOrder string) []*AccessRequest {
+	accessRequests := []*AccessRequest{}
+	session := GetSession(owner, offset, limit, field, value, sortField, sortOrder)
+	err := session.Find(&accessRequests)
+	if err != nil {
+		panic(err)
+	}
+
+	return accessRequests
+}
+
+// GetUserAccessRequests returns the requests made by the user and the ones
+// the user can decide on.
+func GetUserAccessRequests(userId string) []*AccessRequest {
+	accessRequests := []*AccessRequest{}
+	err := adapter.Engine.Where("requester = ? or approvers like ?", userId, "%"+userId+"%").Desc("created_time").Find(&accessRequests)
+	if err != nil {
+		panic(err)
+	}
+
+	res := []*AccessRequest{}
+	for _, accessRequest := range accessRequests {
+		if accessRequest.Requester == userId || util.ContainsString(accessRequest.Approvers, userId) {
+			res = append(res, accessRequest)
+		}
+	}
+	return res
+}
+
+func getAccessRequest(owner string, name string) *AccessRequest {
+	if owner == "" || name == "" {
+		return nil
+	}
+
+	accessRequest := AccessRequest{Owner: owner, Name: name}
+	existed, err := adapter.Engine.Get(&accessRequest)
+	if err != nil {
+		panic(err)
+	}
+
+	if existed {
+		return &accessRequest
+	} else {
+		return nil
+	}
+}
+
+func GetAccessRequest(id string) *AccessRequest {
+	owner, name := util.GetOwnerAndNameFromId(id)
+	return getAccessRequest(owner, name)
+}
+
+func updateAccessRequest(accessRequest *AccessRequest) {
+	_, err := adapter.Engine.ID(core.PK{accessRequest.Owner, accessRequest.Name}).AllCols().Update(accessRequest)
+	if err != nil {
+		panic(err)
+	}
+}
+
+func (accessRequest *AccessRequest) GetId() string {
+	return fmt.Sprintf("%s/%s", accessRequest.Owner, accessRequest.Name)
+}
+
+func (accessRequest *AccessRequest) addEvent(user string, action string, comment string) {
+	accessRequest.Events = append(accessRequest.Events, &AccessRequestEvent{
+		Time:    util.GetCurrentTime(),
+		User:    user,
+		Action:  action,
+		Comment: comment,
+	})
+}
+
+// getAccessRequestApprovers returns the approvers of the role or the
+// permission, or the admins of the organization when it has none.
+func getAccessRequestApprovers(owner string, approvers []string) []string {
+	if len(approvers) > 0 {
+		return approvers
+	}
+
+	users := []*User{}
+	err := getUserEngine(owner).Where("owner = ? and is_admin = ?", owner, true).Find(&users)
+	if err != nil {
+		panic(err)
+	}
+
+	res := []string{}
+	for _, user := range users {
+		res = append(res, user.GetId())
+	}
+	return res
+}
+
+// AddAccessRequest checks the requested role or permission and creates the
+// pending request, the approvers are notified by email.
+func AddAccessRequest(accessRequest *AccessRequest, lang string) string {
+	if GetUser(accessRequest.Requester) == nil {
+		return fmt.Sprintf(i18n.Translate(lang, "general:The user: %s doesn't exist"), accessRequest.Requester)
+	}
+
+	targetOwner, _ := util.GetOwnerAndNameFromId(accessRequest.Target)
+	var approvers []string
+	switch accessRequest.Type {
+	case AccessRequestTypeRole:
+		role := GetRole(accessRequest.Target)
+		if role == nil || targetOwner != accessRequest.Owner {
+			return fmt.Sprintf(i18n.Translate(lang, "access:The role or permission: %s does not exist"), accessRequest.Target)
+		}
+		approvers = role.Approvers
+	case AccessRequestTypePermission:
+		permission := GetPermission(accessRequest.Target)
+		if permission == nil || targetOwner != accessRequest.Owner {
+			return fmt.Sprintf(i18n.Translate(lang, "access:The role or permission: %s does not exist"), accessRequest.Target)
+		}
+		approvers = permission.Approvers
+	default:
+		return fmt.Sprintf(i18n.Translate(lang, "access:Unknown access request type: %s"), accessRequest.Type)
+	}
+
+	for _, existing := range GetUserAccessRequests(accessRequest.Requester) {
+		if existing.Requester == accessRequest.Requester && existing.Target == accessRequest.Target && existing.State == AccessRequestStatePending {
+			return fmt.Sprintf(i18n.Translate(lang, "access:The user already has a pending request for: %s"), accessRequest.Target)
+		}
+	}
+
+	accessRequest.Name = util.GenerateId()
+	accessRequest.CreatedTime = util.GetCurrentTime()
+	accessRequest.Approvers = util.DeleteVal(getAccessRequestApprovers(accessRequest.Owner, approvers), accessRequest.Requester)
+	accessRequest.State = AccessRequestStatePending
+	accessRequest.Approver = ""
+	accessRequest.DecisionTime = ""
+	accessRequest.ExpireTime = ""
+	accessRequest.Events = []*AccessRequestEvent{}
+	accessRequest.addEvent(accessRequest.Requester, "request", accessRequest.Reason)
+
+	_, err := adapter.Engine.Insert(accessRequest)
+	if err != nil {
+		panic(err)
+	}
+
+	util.SafeGoroutine(func() {
+		title := fmt.Sprintf("Access request of %s for %s", accessRequest.Requester, accessRequest.Target)
+		content := fmt.Sprintf("%s requests the %s: %s.<br/>Reason: %s", accessRequest.Requester, strings.ToLower(accessRequest.Type), accessRequest.Target, accessRequest.Reason)
+		notifyAccessRequestUsers(accessRequest, accessRequest.Approvers, title, content)
+	})
+	return ""
+}
+
+// DecideAccessRequest approves or denies the pending request, an approval
+// grants the role or the permission right away.
+func DecideAccessRequest(id string, approver string, isAdmin bool, approve bool, comment string, lang string) string {
+	accessRequest := GetAccessRequest(id)
+	if accessRequest == nil || accessRequest.State != AccessRequestStatePending {
+		return i18n.Translate(lang, "access:The access request is not pending")
+	}
+	if approver == accessRequest.Requester || (!isAdmin && !util.ContainsString(accessRequest.Approvers, approver)) {
+		return i18n.Translate(lang, "access:You are not an approver of the access request")
+	}
+
+	accessRequest.Approver = approver
+	accessRequest.DecisionTime = util.GetCurrentTime()
+	if approve {
+		expireTime := time.Time{}
+		if accessRequest.DurationHours > 0 {
+			expireTime = time.Now().Add(time.Duration(accessRequest.DurationHours) * time.Hour)
+			accessRequest.ExpireTime = expireTime.Format(time.RFC3339)
+		}
+
+		if !grantAccessRequest(accessRequest, expireTime) {
+			return fmt.Sprintf(i18n.Translate(lang, "access:The role or permission: %s does not exist"), accessRequest.Target)
+		}
+		accessRequest.State = AccessRequestStateApproved
+		accessRequest.addEvent(approver, "approve", comment)
+	} else {
+		accessRequest.State = AccessRequestStateDenied
+		accessRequest.addEvent(approver, "deny", comment)
+	}
+	updateAccessRequest(accessRequest)
+
+	util.SafeGoroutine(func() {
+		title := fmt.Sprintf("Your access request for %s is %s", accessRequest.Target, strings.ToLower(accessRequest.State))
+		content := fmt.Sprintf("%s %s your request for the %s: %s.<br/>Comment: %s", approver, strings.ToLower(accessRequest.State), strings.ToLower(accessRequest.Type), accessRequest.Target, comment)
+		notifyAccessRequestUsers(accessRequest, []string{accessRequest.Requester}, title, content)
+	})
+	return ""
+}
+
+// grantAccessRequest adds the user to the role with a time-bound assignment
+// when the grant expires, or to the users of the permission.
+func grantAccessRequest(accessRequest *AccessRequest, expireTime time.Time) bool {
+	switch accessRequest.Type {
+	case AccessRequestTypeRole:
+		role := GetRole(accessRequest.Target)
+		if role == nil {
+			return false
+		}
+
+		if expireTime.IsZero() {
+			if !util.ContainsString(role.Users, accessRequest.Requester) {
+				role.Users = append(role.Users, accessRequest.Requester)
+			}
+		} else {
+			role.Assignments = append(role.Assignments, &RoleAssignment{User: accessRequest.Requester, EndTime: expireTime.Format(time.RFC3339)})
+		}
+		UpdateRole(role.GetId(), role)
+	case AccessRequestTypePermission:
+		permission := GetPermission(accessRequest.Target)
+		if permission == nil {
+			return false
+		}
+
+		if !util.ContainsString(permission.Users, accessRequest.Requester) {
+			permission.Users = append(permission.Users, accessRequest.Requester)
+			UpdatePermission(permission.GetId(), permission)
+		}
+	}
+	return true
+}
+
+// revokeAccessRequest removes the user from the permission, the users of
+// roles are removed by their assignments.
+func revokeAccessRequest(accessRequest *AccessRequest) {
+	if accessRequest.Type != AccessRequestTypePermission {
+		return
+	}
+
+	permission := GetPermission(accessRequest.Target)
+	if permission != nil && util.ContainsString(permission.Users, accessRequest.Requester) {
+		permission.Users = util.DeleteVal(permission.Users, accessRequest.Requester)
+		UpdatePermission(permission.GetId(), permission)
+	}
+}
+
+func notifyAccessRequestUsers(accessRequest *AccessRequest, userIds []string, title string, content string) {
+	application, err := GetDefaultApplication(util.GetId("admin", accessRequest.Owner))
+	if err != nil {
+		return
+	}
+	provider := application.GetEmailProvider()
+	if provider == nil {
+		return
+	}
+
+	for _, userId := range userIds {
+		user := GetUser(userId)
+		if user == nil || user.Email == "" {
+			continue
+		}
+
+		err = SendEmail(provider, title, content, user.Email, provider.DisplayName)
+		if err != nil {
+			logs.Warning(fmt.Sprintf("failed to notify %s of the access request: %s, %s", userId, accessRequest.GetId(), err.Error()))
+		}
+	}
+}
+
+func expireAccessRequests() error {
+	accessRequests := []*AccessRequest{}
+	err := adapter.Engine.Where("state = ? and expire_time != ?", AccessRequestStateApproved, "").Find(&accessRequests)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	for _, accessRequest := range accessRequests {
+		expireTime, ok := parseAssignmentTime(accessRequest.ExpireTime)
+		if !ok || now.Before(expireTime) {
+			continue
+		}
+
+		revokeAccessRequest(accessRequest)
+		accessRequest.State = AccessRequestStateExpired
+		accessRequest.addEvent("", "expire", "")
+		updateAccessRequest(accessRequest)
+
+		owner, name := util.GetOwnerAndNameFromId(accessRequest.Requester)
+		AddRecord(&Record{
+			Name:         util.GenerateId(),
+			CreatedTime:  util.GetCurrentTime(),
+			Organization: owner,
+			User:         name,
+			Method:       "POST",
+			RequestUri:   fmt.Sprintf("/api/get-access-request?id=%s", accessRequest.GetId()),
+			Action:       "expire-access-request",
+		})
+	}
+	return nil
+}
+
+// RunAccessRequestJob expires the approved requests every minute, once per
+// interval cluster-wide.
+func RunAccessRequestJob() {
+	ticker := time.NewTicker(accessRequestJobInterval)
+	defer ticker.Stop()
+	for {
+		RunClusterJob("access-request", accessRequestJobInterval, expireAccessRequests)
+		<-ticker.C
+	}
+}
@@ -0,0 +1,491 @@
+// Copyright 2023 The Casdoor Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package object
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/casdoor/casdoor/i18n"
+	"github.com/casdoor/casdoor/util"
+	"github.com/xorm-io/core"
+)
+
+const (
+	AccessReviewStateScheduled = "Scheduled"
+	AccessReviewStateActive    = "Active"
+	AccessReviewStateCompleted = "Completed"
+
+	AccessReviewDecisionKeep   = "Keep"
+	AccessReviewDecisionRevoke = "Revoke"
+)
+
+const accessReviewJobInterval = time.Minute
+
+// AccessReview is a certification campaign: when it starts, the members of
+// the roles and permissions in scope are snapshotted into items, the
+// reviewers decide to keep or revoke each of them, and when it ends the
+// revoked (and, with DefaultDecision "Revoke", the undecided) memberships
+// are removed. With IntervalDays the next campaign is scheduled on
+// completion.
+type AccessReview struct {
+	Owner       string `xorm:"varchar(100) notnull pk" json:"owner"`
+	Name        string `xorm:"varchar(100) notnull pk" json:"name"`
+	CreatedTime string `xorm:"varchar(100)" json:"createdTime"`
+	DisplayName string `xorm:"varchar(100)" json:"displayName"`
+
+	Roles           []string `xorm:"mediumtext" json:"roles"`
+	Permissions     []string `xorm:"mediumtext" json:"permissions"`
+	Reviewers       []string `xorm:"mediumtext" json:"reviewers"`
+	StartTime       string   `xorm:"varchar(100)" json:"startTime"`
+	EndTime         string   `xorm:"varchar(100)" json:"endTime"`
+	DefaultDecision string   `xorm:"varchar(100)" json:"defaultDecision"`
+	IntervalDays    int      `json:"intervalDays"`
+
+	State         string `xorm:"varchar(100)" json:"state"`
+	CompletedTime string `xorm:"varchar(100)" json:"completedTime"`
+}
+
+type AccessReviewItem struct {
+	Owner       string `xorm:"varchar(100) notnull pk" json:"owner"`
+	Name        string `xorm:"varchar(100) notnull pk" json:"name"`
+	CreatedTime string `xorm:"varchar(100)" json:"createdTime"`
+
+	Review    string   `xorm:"varchar(100) index" json:"review"`
+	User      string   `xorm:"varchar(100)" json:"user"`
+	Type      string   `xorm:"varchar(100)" json:"type"`
+	Target    string   `xorm:"varchar(100)" json:"target"`
+	Reviewers []string `xorm:"mediumtext" json:"reviewers"`
+
+	Decision     string `xorm:"varchar(100)" json:"decision"`
+	Reviewer     string `xorm:"varchar(100)" json:"reviewer"`
+	DecisionTime string `xorm:"varchar(100)" json:"decisionTime"`
+	Comment      string `xorm:"mediumtext" json:"comment"`
+	IsRevoked    bool   `json:"isRevoked"`
+}
+
+func GetAccessReviewCount(owner, field, value string) int {
+	session := GetSession(owner, -1, -1, field, value, "", "")
+	count, err := session.Count(&AccessReview{})
+	if err != nil {
+		panic(err)
+	}
+
+	return int(count)
+}
+
+func GetAccessReviews(owner string) []*AccessReview {
+	accessReviews := []*AccessReview{}
+	err := adapter.Engine.Desc("created_time").Find(&accessReviews, &AccessReview{Owner: owner})
+	if err != nil {
+		panic(err)
+	}
+
+	return accessReviews
+}
+
+func GetPaginationAccessReviews(owner string, offset, limit int, field, value, sortField, sortOrder string) []*AccessReview {
+	accessReviews := []*AccessReview{}
+	session := GetSession(owner, offset, limit, field, value, sortField, sortOrder)
+	err := session.Find(&accessReviews)
+	if err != nil {
+		panic(err)
+	}
+
+	return accessReviews
+}
+
+func getAccessReview(owner string, name string) *AccessReview {
+	if owner == "" || name == "" {
+		return nil
+	}
+
+	accessReview := AccessReview{Owner: owner, Name: name}
+	existed, err := adapter.Engine.Get(&accessReview)
+	if err != nil {
+		panic(err)
+	}
+
+	if existed {
+		return &accessReview
+	} else {
+		return nil
+	}
+}
+
+func GetAccessReview(id string) *AccessReview {
+	owner, name := util.GetOwnerAndNameFromId(id)
+	return getAccessReview(owner, name)
+}
+
+func CheckAccessReview(accessReview *AccessReview, lang string) string {
+	startTime, hasStartTime := parseAssignmentTime(accessReview.StartTime)
+	endTime, hasEndTime := parseAssignmentTime(accessReview.EndTime)
+	if !hasStartTime || !hasEndTime || !startTime.Before(endTime) {
+		return i18n.Translate(lang, "access:The access review needs a start time before its end time")
+	}
+	if accessReview.DefaultDecision != AccessReviewDecisionKeep && accessReview.DefaultDecision != AccessReviewDecisionRevoke {
+		return fmt.Sprintf(i18n.Translate(lang, "access:Unknown decision: %s"), accessReview.DefaultDecision)
+	}
+	return ""
+}
+
+func UpdateAccessReview(id string, accessReview *AccessReview) bool {
+	owner, name := util.GetOwnerAndNameFromId(id)
+	oldAccessReview := getAccessReview(owner, name)
+	if oldAccessReview == nil {
+		return false
+	}
+
+	// the state only changes by starting and completing the campaign
+	accessReview.State = oldAccessReview.State
+	accessReview.CompletedTime = oldAccessReview.CompletedTime
+
+	affected, err := adapter.Engine.ID(core.PK{owner, name}).AllCols().Update(accessReview)
+	if err != nil {
+		panic(err)
+	}
+
+	return affected != 0
+}
+
+func AddAccessReview(accessReview *AccessReview) bool {
+	accessReview.State = AccessReviewStateScheduled
+	accessReview.CompletedTime = ""
+
+	affected, err := adapter.Engine.Insert(accessReview)
+	if err != nil {
+		panic(err)
+	}
+
+	return affected != 0
+}
+
+func DeleteAccessReview(accessReview *AccessReview) bool {
+	affected, err := adapter.Engine.ID(core.PK{accessReview.Owner, accessReview.Name}).Delete(&AccessReview{})
+	if err != nil {
+		panic(err)
+	}
+
+	_, err = adapter.Engine.Delete(&AccessReviewItem{Owner: accessReview.Owner, Review: accessReview.Name})
+	if err != nil {
+		panic(err)
+	}
+
+	return affected != 0
+}
+
+func (accessReview *AccessReview) GetId() string {
+	return fmt.Sprintf("%s/%s", accessReview.Owner, accessReview.Name)
+}
+
+func GetAccessReviewItems(owner string, review string) []*AccessReviewItem {
+	items := []*AccessReviewItem{}
+	err := adapter.Engine.Asc("target").Find(&items, &AccessReviewItem{Owner: owner, Review: review})
+	if err != nil {
+		panic(err)
+	}
+
+	return items
+}
+
+// GetUserAccessReviewItems returns the undecided items of the active
+// campaigns the user reviews.
+func GetUserAccessReviewItems(userId string) []*AccessReviewItem {
+	items := []*AccessReviewItem{}
+	err := adapter.Engine.Where("reviewers like ? and decision = ?", "%"+userId+"%", "").Find(&items)
+	if err != nil {
+		panic(err)
+	}
+
+	res := []*AccessReviewItem{}
+	for _, item := range items {
+		accessReview := getAccessReview(item.Owner, item.Review)
+		if accessReview != nil && accessReview.State == AccessReviewStateActive && util.ContainsString(item.Reviewers, userId) {
+			res = append(res, item)
+		}
+	}
+	return res
+}
+
+func getAccessReviewItem(owner string, name string) *AccessReviewItem {
+	if owner == "" || name == "" {
+		return nil
+	}
+
+	item := AccessReviewItem{Owner: owner, Name: name}
+	existed, err := adapter.Engine.Get(&item)
+	if err != nil {
+		panic(err)
+	}
+
+	if existed {
+		return &item
+	} else {
+		return nil
+	}
+}
+
+func updateAccessReviewItem(item *AccessReviewItem) {
+	_, err := adapter.Engine.ID(core.PK{item.Owner, item.Name}).AllCols().Update(item)
+	if err != nil {
+		panic(err)
+	}
+}
+
+// DecideAccessReviewItem records the decision of a reviewer, it can be
+// changed until the campaign is completed.
+func DecideAccessReviewItem(id string, reviewer string, isAdmin bool, decision string, comment string, lang string) string {
+	owner, name := util.GetOwnerAndNameFromId(id)
+	item := getAccessReviewItem(owner, name)
+	if item == nil {
+		return fmt.Sprintf(i18n.Translate(lang, "access:The access review item: %s does not exist"), id)
+	}
+
+	accessReview := getAccessReview(item.Owner, item.Review)
+	if accessReview == nil || accessReview.State != AccessReviewStateActive {
+		return i18n.Translate(lang, "access:The access review is not active")
+	}
+	if reviewer == item.User || (!isAdmin && !util.ContainsString(item.Reviewers, reviewer)) {
+		return i18n.Translate(lang, "access:You are not a reviewer of the access review item")
+	}
+	if decision != AccessReviewDecisionKeep && decision != AccessReviewDecisionRevoke {
+		return fmt.Sprintf(i18n.Translate(lang, "access:Unknown decision: %s"), decision)
+	}
+
+	item.Decision = decision
+	item.Reviewer = reviewer
+	item.DecisionTime = util.GetCurrentTime()
+	item.Comment = comment
+	updateAccessReviewItem(item)
+	return ""
+}
+
+func (accessReview *AccessReview) newItem(user string, typ string, target string, approvers []string) *AccessReviewItem {
+	reviewers := accessReview.Reviewers
+	if len(reviewers) == 0 {
+		reviewers = getAccessRequestApprovers(accessReview.Owner, approvers)
+	}
+
+	return &AccessReviewItem{
+		Owner:       accessReview.Owner,
+		Name:        util.GenerateId(),
+		CreatedTime: util.GetCurrentTime(),
+		Review:      accessReview.Name,
+		User:        user,
+		Type:        typ,
+		Target:      target,
+		Reviewers:   util.DeleteVal(reviewers, user),
+	}
+}
+
+// StartAccessReview snapshots the memberships in scope, the campaign covers
+// all roles and permissions of the organization when the scope is empty.
+func StartAccessReview(accessReview *AccessReview) {
+	roles := []*Role{}
+	permissions := []*Permission{}
+	if len(accessReview.Roles) == 0 && len(accessReview.Permissions) == 0 {
+		roles = GetRoles(accessReview.Owner)
+		permissions = GetPermissions(accessReview.Owner)
+	} else {
+		for _, roleId := range accessReview.Roles {
+			if role := GetRole(roleId); role != nil {
+				roles = append(roles, role)
+			}
+		}
+		for _, permissionId := range accessReview.Permissions {
+			if permission := GetPermission(permissionId); permission != nil {
+				permissions = append(permissions, permission)
+			}
+		}
+	}
+
+	items := []*AccessReviewItem{}
+	for _, role := range roles {
+		for _, user := range role.Users {
+			items = append(items, accessReview.newItem(user, AccessRequestTypeRole, role.GetId(), role.Approvers))
+		}
+	}
+	for _, permission := range permissions {
+		for _, user := range permission.Users {
+			items = append(items, accessReview.newItem(user, AccessRequestTypePermission, permission.GetId(), permission.Approvers))
+		}
+	}
+
+	if len(items) > 0 {
+		_, err := adapter.Engine.Insert(items)
+		if err != nil {
+			panic(err)
+		}
+	}
+
+	accessReview.State = AccessReviewStateActive
+	_, err := adapter.Engine.ID(core.PK{accessReview.Owner, accessReview.Name}).Cols("state").Update(accessReview)
+	if err != nil {
+		panic(err)
+	}
+}
+
+func revokeAccessReviewItems(items []*AccessReviewItem) {
+	roles := map[string]*Role{}
+	permissions := map[string]*Permission{}
+	for _, item := range items {
+		switch item.Type {
+		case AccessRequestTypeRole:
+			role, ok := roles[item.Target]
+			if !ok {
+				role = GetRole(item.Target)
+				roles[item.Target] = role
+			}
+			if role == nil {
+				continue
+			}
+
+			role.Users = util.DeleteVal(role.Users, item.User)
+			assignments := []*RoleAssignment{}
+			for _, assignment := range role.Assignments {
+				if assignment.User != item.User {
+					assignments = append(assignments, assignment)
+				}
+			}
+			role.Assignments = assignments
+		case AccessRequestTypePermission:
+			permission, ok := permissions[item.Target]
+			if !ok {
+				permission = GetPermission(item.Target)
+				permissions[item.Target] = permission
+			}
+			if permission == nil {
+				continue
+			}
+
+			permission.Users = util.DeleteVal(permission.Users, item.User)
+		}
+
+		item.IsRevoked = true
+		updateAccessReviewItem(item)
+	}
+
+	for id, role := range roles {
+		if role != nil {
+			UpdateRole(id, role)
+		}
+	}
+	for id, permission := range permissions {
+		if permission != nil {
+			UpdatePermission(id, permission)
+		}
+	}
+}
+
+// CompleteAccessReview applies the decisions, the undecided items get the
+// default decision of the campaign.
+func CompleteAccessReview(accessReview *AccessReview) {
+	revokedItems := []*AccessReviewItem{}
+	for _, item := range GetAccessReviewItems(accessReview.Owner, accessReview.Name) {
+		if item.Decision == "" {
+			item.Decision = accessReview.DefaultDecision
+			item.DecisionTime = util.GetCurrentTime()
+			updateAccessReviewItem(item)
+		}
+		if item.Decision == AccessReviewDecisionRevoke {
+			revokedItems = append(revokedItems, item)
+		}
+	}
+	revokeAccessReviewItems(revokedItems)
+
+	accessReview.State = AccessReviewStateCompleted
+	accessReview.CompletedTime = util.GetCurrentTime()
+	_, err := adapter.Engine.ID(core.PK{accessReview.Owner, accessReview.Name}).Cols("state", "completed_time").Update(accessReview)
+	if err != nil {
+		panic(err)
+	}
+
+	if accessReview.IntervalDays > 0 {
+		scheduleNextAccessReview(accessReview)
+	}
+}
+
+func scheduleNextAccessReview(accessReview *AccessReview) {
+	startTime, _ := parseAssignmentTime(accessReview.StartTime)
+	endTime, _ := parseAssignmentTime(accessReview.EndTime)
+	interval := time.Duration(accessReview.IntervalDays) * 24 * time.Hour
+
+	next := *accessReview
+	next.Name = fmt.Sprintf("%s_next_%s", strings.SplitN(accessReview.Name, "_next_", 2)[0], util.GenerateId()[:8])
+	next.CreatedTime = util.GetCurrentTime()
+	next.StartTime = startTime.Add(interval).Format(time.RFC3339)
+	next.EndTime = endTime.Add(interval).Format(time.RFC3339)
+	AddAccessReview(&next)
+}
+
+func runAccessReviews() error {
+	accessReviews := []*AccessReview{}
+	err := adapter.Engine.In("state", AccessReviewStateScheduled, AccessReviewStateActive).Find(&accessReviews)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	for _, accessReview := range accessReviews {
+		startTime, _ := parseAssignmentTime(accessReview.StartTime)
+		endTime, _ := parseAssignmentTime(accessReview.EndTime)
+		if accessReview.State == AccessReviewStateScheduled && !now.Before(startTime) {
+			StartAccessReview(accessReview)
+		}
+		if accessReview.State == AccessReviewStateActive && !now.Before(endTime) {
+			CompleteAccessReview(accessReview)
+		}
+	}
+	return nil
+}
+
+// RunAccessReviewJob starts and completes the campaigns every minute, once
+// per interval cluster-wide.
+func RunAccessReviewJob() {
+	ticker := time.NewTicker(accessReviewJobInterval)
+	defer ticker.Stop()
+	for {
+		RunClusterJob("access-review", accessReviewJobInterval, runAccessReviews)
+		<-ticker.C
+	}
+}
+
+// ExportAccessReview returns the items with their decisions as CSV for the
+// auditors.
+func ExportAccessReview(accessReview *AccessReview) ([]byte, error) {
+	buf := &bytes.Buffer{}
+	writer := csv.NewWriter(buf)
+	err := writer.Write([]string{"review", "state", "user", "type", "target", "reviewers", "decision", "reviewer", "decisionTime", "comment", "isRevoked"})
+	if err != nil {
+		return nil, err
+	}
+
+	for _, item := range GetAccessReviewItems(accessReview.Owner, accessReview.Name) {
+		err = writer.Write([]string{
+			accessReview.GetId(), accessReview.State, item.User, item.Type, item.Target, strings.Join(item.Reviewers, " "),
+			item.Decision, item.Reviewer, item.DecisionTime, item.Comment, fmt.Sprintf("%t", item.IsRevoked),
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	writer.Flush()
+	return buf.Bytes(), writer.Error()
+}
@@ -0,0 +1,45 @@
+// Copyright 2023 The Casdoor Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package object
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckAccessReview(t *testing.T) {
+	scenarios := []struct {
+		description     string
+		startTime       string
+		endTime         string
+		defaultDecision string
+		valid           bool
+	}{
+		{"valid campaign", "2023-06-01T00:00:00Z", "2023-06-15T00:00:00Z", AccessReviewDecisionKeep, true},
+		{"revoke by default", "2023-06-01T00:00:00Z", "2023-06-15T00:00:00Z", AccessReviewDecisionRevoke, true},
+		{"end before start", "2023-06-15T00:00:00Z", "2023-06-01T00:00:00Z", AccessReviewDecisionKeep, false},
+		{"missing end time", "2023-06-01T00:00:00Z", "", AccessReviewDecisionKeep, false},
+		{"unknown decision", "2023-06-01T00:00:00Z", "2023-06-15T00:00:00Z", "Maybe", false},
+	}
+
+	for _, scenery := range scenarios {
+		t.Run(scenery.description, func(t *testing.T) {
+			accessReview := &AccessReview{StartTime: scenery.startTime, EndTime: scenery.endTime, DefaultDecision: scenery.defaultDecision}
+			msg := CheckAccessReview(accessReview, "en")
+			assert.Equal(t, scenery.valid, msg == "", msg)
+		})
+	}
+}
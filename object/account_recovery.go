@@ -0,0 +1,374 @@
+// Copyright 2023 The Casdoor Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package object
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/casdoor/casdoor/i18n"
+	"github.com/casdoor/casdoor/util"
+	"github.com/thanhpk/randstr"
+	"github.com/xorm-io/core"
+)
+
+const (
+	AccountRecoveryMethodEmail             = "Email"
+	AccountRecoveryMethodSms               = "SMS"
+	AccountRecoveryMethodSecurityQuestions = "Security questions"
+	AccountRecoveryMethodAdmin             = "Admin"
+
+	AccountRecoveryStateFailed    = "Failed"
+	AccountRecoveryStateVerified  = "Verified"
+	AccountRecoveryStateCompleted = "Completed"
+
+	defaultRequiredAnswers          = 2
+	defaultRecoveryLinkMinutes      = 60
+	defaultMaxRecoveryAttempts      = 5
+	accountRecoveryTokenMinutes     = 10
+	accountRecoveryAttemptsInterval = time.Hour
+)
+
+// AccountRecoveryConfig is how the users of an organization can recover
+// their accounts. Methods are "Email", "SMS" and "Security questions", a
+// user can only answer the questions of SecurityQuestions and needs
+// RequiredAnswers of them right. LinkExpireMinutes is the lifetime of the
+// reset links issued by admins, MaxAttempts the failed attempts allowed per
+// hour for a user or an IP.
+type AccountRecoveryConfig struct {
+	Methods           []string `json:"methods"`
+	SecurityQuestions []string `json:"securityQuestions"`
+	RequiredAnswers   int      `json:"requiredAnswers"`
+	LinkExpireMinutes int      `json:"linkExpireMinutes"`
+	MaxAttempts       int      `json:"maxAttempts"`
+}
+
+// SecurityAnswer is the answer of a user to a security question, only the
+// salted hash of the normalized answer is stored.
+type SecurityAnswer struct {
+	Question string `json:"question"`
+	Salt     string `json:"salt"`
+	Hash     string `json:"hash"`
+}
+
+// AccountRecovery is an attempt to recover an account, the verified ones
+// hold the hash of the token that allows to reset the password once.
+type AccountRecovery struct {
+	Owner       string `xorm:"varchar(100) notnull pk" json:"owner"`
+	Name        string `xorm:"varchar(100) notnull pk" json:"name"`
+	CreatedTime string `xorm:"varchar(100)" json:"createdTime"`
+
+	User       string `xorm:"varchar(100) index" json:"user"`
+	Method     string `xorm:"varchar(100)" json:"method"`
+	State      string `xorm:"varchar(100)" json:"state"`
+	TokenHash  string `xorm:"varchar(100) index" json:"-"`
+	ExpireTime string `xorm:"varchar(100)" json:"expireTime"`
+	ClientIp   string `xorm:"varchar(100) index" json:"clientIp"`
+	Operator   string `xorm:"varchar(100)" json:"operator"`
+}
+
+func (organization *Organization) getAccountRecoveryConfig() *AccountRecoveryConfig {
+	config := &AccountRecoveryConfig{}
+	if organization != nil && organization.AccountRecovery != nil {
+		*config = *organization.AccountRecovery
+	}
+
+	if config.Methods == nil {
+		config.Methods = []string{AccountRecoveryMethodEmail, AccountRecoveryMethodSms}
+	}
+	if config.RequiredAnswers <= 0 {
+		config.RequiredAnswers = defaultRequiredAnswers
+	}
+	if config.LinkExpireMinutes <= 0 {
+		config.LinkExpireMinutes = defaultRecoveryLinkMinutes
+	}
+	if config.MaxAttempts <= 0 {
+		config.MaxAttempts = defaultMaxRecoveryAttempts
+	}
+	return config
+}
+
+func (config *AccountRecoveryConfig) isMethodEnabled(method string) bool {
+	return util.ContainsString(config.Methods, method)
+}
+
+func normalizeSecurityAnswer(answer string) string {
+	return strings.Join(strings.Fields(strings.ToLower(answer)), " ")
+}
+
+func getSecurityAnswerHash(salt string, answer string) string {
+	hash := sha256.Sum256([]byte(salt + normalizeSecurityAnswer(answer)))
+	return hex.EncodeToString(hash[:])
+}
+
+// checkSecurityAnswers tells whether at least required of the answers match
+// the stored ones, users with fewer stored answers can't use the questions.
+func checkSecurityAnswers(securityAnswers []*SecurityAnswer, answers map[string]string, required int) bool {
+	if len(securityAnswers) < required {
+		return false
+	}
+
+	correct := 0
+	for _, securityAnswer := range securityAnswers {
+		answer, ok := answers[securityAnswer.Question]
+		if ok && normalizeSecurityAnswer(answer) != "" && getSecurityAnswerHash(securityAnswer.Salt, answer) == securityAnswer.Hash {
+			correct++
+		}
+	}
+	return correct >= required
+}
+
+// FilterAccountRecoveryOptions masks what the user can't use to recover the
+// account: the email and phone of disabled methods, and the questions when
+// they are disabled or not answered enough. Hashes are never returned.
+func FilterAccountRecoveryOptions(user *User, respUser *User) {
+	config := GetOrganizationByUser(user).getAccountRecoveryConfig()
+	if !config.isMethodEnabled(AccountRecoveryMethodEmail) {
+		respUser.Email = ""
+	}
+	if !config.isMethodEnabled(AccountRecoveryMethodSms) {
+		respUser.Phone = ""
+	}
+
+	respUser.SecurityAnswers = []*SecurityAnswer{}
+	if config.isMethodEnabled(AccountRecoveryMethodSecurityQuestions) && len(user.SecurityAnswers) >= config.RequiredAnswers {
+		for _, securityAnswer := range user.SecurityAnswers {
+			respUser.SecurityAnswers = append(respUser.SecurityAnswers, &SecurityAnswer{Question: securityAnswer.Question})
+		}
+	}
+}
+
+// SetSecurityAnswers replaces the answers of the user, the questions must be
+// ones of the organization.
+func SetSecurityAnswers(user *User, answers map[string]string, lang string) error {
+	config := GetOrganizationByUser(user).getAccountRecoveryConfig()
+	if !config.isMethodEnabled(AccountRecoveryMethodSecurityQuestions) {
+		return fmt.Errorf(i18n.Translate(lang, "recovery:The recovery method: %s is not enabled"), AccountRecoveryMethodSecurityQuestions)
+	}
+	if len(answers) < config.RequiredAnswers {
+		return fmt.Errorf(i18n.Translate(lang, "recovery:At least %d security questions should be answered"), config.RequiredAnswers)
+	}
+
+	securityAnswers := []*SecurityAnswer{}
+	for _, question := range config.SecurityQuestions {
+		answer, ok := answers[question]
+		if !ok {
+			continue
+		}
+		if normalizeSecurityAnswer(answer) == "" {
+			return fmt.Errorf(i18n.Translate(lang, "recovery:The answer to: %s is empty"), question)
+		}
+
+		salt := randstr.Hex(8)
+		securityAnswers = append(securityAnswers, &SecurityAnswer{Question: question, Salt: salt, Hash: getSecurityAnswerHash(salt, answer)})
+	}
+	if len(securityAnswers) != len(answers) {
+		return fmt.Errorf(i18n.Translate(lang, "recovery:Only the security questions of the organization can be answered"))
+	}
+
+	user.SecurityAnswers = securityAnswers
+	_, err := getUserEngine(user.Owner).ID(core.PK{user.Owner, user.Name}).Cols("security_answers").Update(user)
+	if err != nil {
+		panic(err)
+	}
+	return nil
+}
+
+func getAccountRecoveryTokenHash(token string) string {
+	hash := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(hash[:])
+}
+
+// addAccountRecovery records an attempt, the token of a verified attempt is
+// returned and only its hash is kept.
+func addAccountRecovery(user *User, method string, state string, clientIp string, operator string, expireMinutes int) string {
+	now := time.Now()
+	accountRecovery := &AccountRecovery{
+		Owner:       user.Owner,
+		Name:        util.GenerateId(),
+		CreatedTime: now.Format(time.RFC3339),
+		User:        user.Name,
+		Method:      method,
+		State:       state,
+		ClientIp:    clientIp,
+		Operator:    operator,
+	}
+
+	token := ""
+	if state == AccountRecoveryStateVerified {
+		token = randstr.Hex(32)
+		accountRecovery.TokenHash = getAccountRecoveryTokenHash(token)
+		accountRecovery.ExpireTime = now.Add(time.Duration(expireMinutes) * time.Minute).Format(time.RFC3339)
+	}
+
+	_, err := adapter.Engine.Insert(accountRecovery)
+	if err != nil {
+		panic(err)
+	}
+	return token
+}
+
+// checkAccountRecoveryAttempts rejects the attempts of a user or an IP with
+// too many failed attempts in the last hour.
+func checkAccountRecoveryAttempts(user *User, clientIp string, config *AccountRecoveryConfig, lang string) error {
+	since := time.Now().Add(-accountRecoveryAttemptsInterval).Format(time.RFC3339)
+
+	userCount, err := adapter.Engine.Where("created_time >= ?", since).Count(&AccountRecovery{Owner: user.Owner, User: user.Name, State: AccountRecoveryStateFailed})
+	if err != nil {
+		panic(err)
+	}
+	ipCount, err := adapter.Engine.Where("created_time >= ?", since).Count(&AccountRecovery{ClientIp: clientIp, State: AccountRecoveryStateFailed})
+	if err != nil {
+		panic(err)
+	}
+
+	if int(userCount) >= config.MaxAttempts || int(ipCount) >= config.MaxAttempts {
+		return fmt.Errorf(i18n.Translate(lang, "recovery:Too many recovery attempts, please try again later"))
+	}
+	return nil
+}
+
+// VerifyAccountRecovery checks the code sent by email or SMS, or the answers
+// to the security questions. It returns a token that allows to reset the
+// password within a few minutes.
+func VerifyAccountRecovery(user *User, method string, code string, answers map[string]string, clientIp string, lang string) (string, error) {
+	config := GetOrganizationByUser(user).getAccountRecoveryConfig()
+	if !config.isMethodEnabled(method) {
+		return "", fmt.Errorf(i18n.Translate(lang, "recovery:The recovery method: %s is not enabled"), method)
+	}
+
+	err := checkAccountRecoveryAttempts(user, clientIp, config, lang)
+	if err != nil {
+		return "", err
+	}
+
+	dest := ""
+	switch method {
+	case AccountRecoveryMethodEmail:
+		dest = user.Email
+	case AccountRecoveryMethodSms:
+		dest, _ = util.GetE164Number(user.Phone, user.GetCountryCode(""))
+	case AccountRecoveryMethodSecurityQuestions:
+		if !checkSecurityAnswers(user.SecurityAnswers, answers, config.RequiredAnswers) {
+			addAccountRecovery(user, method, AccountRecoveryStateFailed, clientIp, "", 0)
+			return "", fmt.Errorf(i18n.Translate(lang, "recovery:The answers to the security questions are wrong"))
+		}
+	}
+
+	if method == AccountRecoveryMethodEmail || method == AccountRecoveryMethodSms {
+		if dest == "" {
+			return "", fmt.Errorf(i18n.Translate(lang, "recovery:The recovery method: %s is not enabled"), method)
+		}
+
+		result := CheckVerificationCode(dest, code, lang)
+		if result.Code != VerificationSuccess {
+			if result.Code == wrongCodeError {
+				addAccountRecovery(user, method, AccountRecoveryStateFailed, clientIp, "", 0)
+			}
+			return "", errors.New(result.Msg)
+		}
+		DisableVerificationCode(dest)
+	}
+
+	return addAccountRecovery(user, method, AccountRecoveryStateVerified, clientIp, "", accountRecoveryTokenMinutes), nil
+}
+
+// CreateAccountRecoveryLink issues a reset link to the forget page of the
+// default application for the user on behalf of an admin, it expires after
+// the lifetime set for the organization.
+func CreateAccountRecoveryLink(user *User, operator string, clientIp string, host string) (string, string, error) {
+	application, err := GetDefaultApplication(util.GetId("admin", user.Owner))
+	if err != nil {
+		return "", "", err
+	}
+
+	config := GetOrganizationByUser(user).getAccountRecoveryConfig()
+	token := addAccountRecovery(user, AccountRecoveryMethodAdmin, AccountRecoveryStateVerified, clientIp, operator, config.LinkExpireMinutes)
+	expireTime := time.Now().Add(time.Duration(config.LinkExpireMinutes) * time.Minute).Format(time.RFC3339)
+
+	originFrontend, _ := getOriginFromHost(host)
+	link := fmt.Sprintf("%s/forget/%s?recoveryToken=%s", originFrontend, application.Name, token)
+	return link, expireTime, nil
+}
+
+func getAccountRecoveryByToken(token string) *AccountRecovery {
+	if token == "" {
+		return nil
+	}
+
+	accountRecovery := AccountRecovery{TokenHash: getAccountRecoveryTokenHash(token)}
+	existed, err := adapter.Engine.Get(&accountRecovery)
+	if err != nil {
+		panic(err)
+	}
+
+	if existed {
+		return &accountRecovery
+	}
+	return nil
+}
+
+// GetAccountRecoveryUser returns the user of a token that can still be used.
+func GetAccountRecoveryUser(token string, lang string) (*User, error) {
+	accountRecovery := getAccountRecoveryByToken(token)
+	if accountRecovery == nil || accountRecovery.State != AccountRecoveryStateVerified || accountRecovery.ExpireTime < time.Now().Format(time.RFC3339) {
+		return nil, fmt.Errorf(i18n.Translate(lang, "recovery:The recovery link is invalid or has expired"))
+	}
+
+	user := getUser(accountRecovery.Owner, accountRecovery.User)
+	if user == nil {
+		return nil, fmt.Errorf(i18n.Translate(lang, "general:The user: %s doesn't exist"), util.GetId(accountRecovery.Owner, accountRecovery.User))
+	}
+	return user, nil
+}
+
+// ResetPasswordByRecovery sets the password of the user of the token, the
+// token can't be used again. The user is signed out everywhere.
+func ResetPasswordByRecovery(token string, password string, lang string) (*User, error) {
+	user, err := GetAccountRecoveryUser(token, lang)
+	if err != nil {
+		return nil, err
+	}
+
+	accountRecovery := getAccountRecoveryByToken(token)
+	accountRecovery.State = AccountRecoveryStateCompleted
+	affected, err := adapter.Engine.ID(core.PK{accountRecovery.Owner, accountRecovery.Name}).Where("state = ?", AccountRecoveryStateVerified).Cols("state").Update(accountRecovery)
+	if err != nil {
+		panic(err)
+	}
+	if affected == 0 {
+		return nil, fmt.Errorf(i18n.Translate(lang, "recovery:The recovery link is invalid or has expired"))
+	}
+
+	user.Password = password
+	SetUserField(user, "password", user.Password)
+	resetUserSigninErrorTimes(user)
+	RevokeUserSessions(user)
+	return user, nil
+}
+
+func GetAccountRecoveries(owner string, user string) []*AccountRecovery {
+	accountRecoveries := []*AccountRecovery{}
+	err := adapter.Engine.Desc("created_time").Find(&accountRecoveries, &AccountRecovery{Owner: owner, User: user})
+	if err != nil {
+		panic(err)
+	}
+
+	return accountRecoveries
+}
@@ -0,0 +1,65 @@
+// Copyright 2023 The Casdoor Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package object
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckSecurityAnswers(t *testing.T) {
+	securityAnswers := []*SecurityAnswer{
+		{Question: "First pet?", Salt: "a1", Hash: getSecurityAnswerHash("a1", "Rex")},
+		{Question: "Birth city?", Salt: "b2", Hash: getSecurityAnswerHash("b2", "New York")},
+		{Question: "First school?", Salt: "c3", Hash: getSecurityAnswerHash("c3", "Lincoln")},
+	}
+
+	scenarios := []struct {
+		description string
+		answers     map[string]string
+		required    int
+		expected    bool
+	}{
+		{"all right", map[string]string{"First pet?": "Rex", "Birth city?": "New York", "First school?": "Lincoln"}, 3, true},
+		{"normalized", map[string]string{"First pet?": " rex ", "Birth city?": "new   YORK"}, 2, true},
+		{"enough right", map[string]string{"First pet?": "Rex", "Birth city?": "Boston", "First school?": "Lincoln"}, 2, true},
+		{"not enough right", map[string]string{"First pet?": "Rex", "Birth city?": "Boston"}, 2, false},
+		{"unknown question", map[string]string{"First pet?": "Rex", "Mother's name?": "Ann"}, 2, false},
+		{"empty answer", map[string]string{"First pet?": "", "Birth city?": "New York"}, 2, false},
+		{"fewer answers stored than required", map[string]string{"First pet?": "Rex", "Birth city?": "New York", "First school?": "Lincoln"}, 4, false},
+	}
+
+	for _, scenery := range scenarios {
+		t.Run(scenery.description, func(t *testing.T) {
+			assert.Equal(t, scenery.expected, checkSecurityAnswers(securityAnswers, scenery.answers, scenery.required))
+		})
+	}
+}
+
+func TestGetAccountRecoveryConfig(t *testing.T) {
+	config := (*Organization)(nil).getAccountRecoveryConfig()
+	assert.Equal(t, []string{AccountRecoveryMethodEmail, AccountRecoveryMethodSms}, config.Methods)
+	assert.Equal(t, defaultRequiredAnswers, config.RequiredAnswers)
+	assert.Equal(t, defaultRecoveryLinkMinutes, config.LinkExpireMinutes)
+	assert.Equal(t, defaultMaxRecoveryAttempts, config.MaxAttempts)
+
+	organization := &Organization{AccountRecovery: &AccountRecoveryConfig{Methods: []string{AccountRecoveryMethodSecurityQuestions}, RequiredAnswers: 3}}
+	config = organization.getAccountRecoveryConfig()
+	assert.True(t, config.isMethodEnabled(AccountRecoveryMethodSecurityQuestions))
+	assert.False(t, config.isMethodEnabled(AccountRecoveryMethodEmail))
+	assert.Equal(t, 3, config.RequiredAnswers)
+	assert.Equal(t, 0, organization.AccountRecovery.MaxAttempts)
+}
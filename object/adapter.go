@@ -47,6 +47,7 @@ func InitConfig() {
 
 func InitAdapter() {
 	adapter = NewAdapter(conf.GetConfigString("driverName"), conf.GetConfigDataSourceName(), conf.GetConfigString("dbName"))
+	initShards()
 }
 
 func CreateTables(createDatabase bool) {
@@ -54,6 +55,7 @@ func CreateTables(createDatabase bool) {
 		adapter.CreateDatabase()
 	}
 	adapter.createTable()
+	createShardTables(createDatabase)
 }
 
 // Adapter represents the MySQL adapter for policy storage.
@@ -118,13 +120,17 @@ func (a *Adapter) close() {
 	a.Engine = nil
 }
 
-func (a *Adapter) createTable() {
+func (a *Adapter) setEngineOptions() {
 	showSql, _ := conf.GetConfigBool("showSql")
 	a.Engine.ShowSQL(showSql)
 
 	tableNamePrefix := conf.GetConfigString("tableNamePrefix")
 	tbMapper := core.NewPrefixMapper(core.SnakeMapper{}, tableNamePrefix)
 	a.Engine.SetTableMapper(tbMapper)
+}
+
+func (a *Adapter) createTable() {
+	a.setEngineOptions()
 
 	err := a.Engine.Sync2(new(Organization))
 	if err != nil {
@@ -230,10 +236,149 @@ func (a *Adapter) createTable() {
 	if err != nil {
 		panic(err)
 	}
+
+	err = a.Engine.Sync2(new(Job))
+	if err != nil {
+		panic(err)
+	}
+
+	err = a.Engine.Sync2(new(Setting))
+	if err != nil {
+		panic(err)
+	}
+
+	err = a.Engine.Sync2(new(SettingChange))
+	if err != nil {
+		panic(err)
+	}
+
+	err = a.Engine.Sync2(new(RelationTuple))
+	if err != nil {
+		panic(err)
+	}
+
+	err = a.Engine.Sync2(new(AccessRequest))
+	if err != nil {
+		panic(err)
+	}
+
+	err = a.Engine.Sync2(new(AccessReview))
+	if err != nil {
+		panic(err)
+	}
+
+	err = a.Engine.Sync2(new(AccessReviewItem))
+	if err != nil {
+		panic(err)
+	}
+
+	err = a.Engine.Sync2(new(ApiKey))
+	if err != nil {
+		panic(err)
+	}
+
+	err = a.Engine.Sync2(new(Membership))
+	if err != nil {
+		panic(err)
+	}
+
+	err = a.Engine.Sync2(new(AppAttestKey))
+	if err != nil {
+		panic(err)
+	}
+
+	err = a.Engine.Sync2(new(Plan))
+	if err != nil {
+		panic(err)
+	}
+
+	err = a.Engine.Sync2(new(Subscription))
+	if err != nil {
+		panic(err)
+	}
+
+	err = a.Engine.Sync2(new(Usage))
+	if err != nil {
+		panic(err)
+	}
+
+	err = a.Engine.Sync2(new(LoginDevice))
+	if err != nil {
+		panic(err)
+	}
+
+	err = a.Engine.Sync2(new(LoginAlert))
+	if err != nil {
+		panic(err)
+	}
+
+	err = a.Engine.Sync2(new(Task))
+	if err != nil {
+		panic(err)
+	}
+
+	err = a.Engine.Sync2(new(IdentityVerification))
+	if err != nil {
+		panic(err)
+	}
+
+	err = a.Engine.Sync2(new(LoginHook))
+	if err != nil {
+		panic(err)
+	}
+
+	err = a.Engine.Sync2(new(AccountRecovery))
+	if err != nil {
+		panic(err)
+	}
+
+	err = a.Engine.Sync2(new(ContactChange))
+	if err != nil {
+		panic(err)
+	}
+
+	err = a.Engine.Sync2(new(LoginStat))
+	if err != nil {
+		panic(err)
+	}
+
+	err = a.Engine.Sync2(new(TokenStat))
+	if err != nil {
+		panic(err)
+	}
+
+	err = a.Engine.Sync2(new(TokenUsage))
+	if err != nil {
+		panic(err)
+	}
+
+	err = a.Engine.Sync2(new(TokenAnomaly))
+	if err != nil {
+		panic(err)
+	}
+
+	err = a.Engine.Sync2(new(EmailSuppression))
+	if err != nil {
+		panic(err)
+	}
+
+	err = a.Engine.Sync2(new(SshCertificate))
+	if err != nil {
+		panic(err)
+	}
+
+	err = a.Engine.Sync2(new(Device))
+	if err != nil {
+		panic(err)
+	}
 }
 
 func GetSession(owner string, offset, limit int, field, value, sortField, sortOrder string) *xorm.Session {
-	session := adapter.Engine.Prepare()
+	return getEngineSession(adapter.Engine, owner, offset, limit, field, value, sortField, sortOrder)
+}
+
+func getEngineSession(engine *xorm.Engine, owner string, offset, limit int, field, value, sortField, sortOrder string) *xorm.Session {
+	session := engine.Prepare()
 	if offset != -1 && limit != -1 {
 		session.Limit(limit, offset)
 	}
@@ -0,0 +1,343 @@
+// Copyright 2023 The Casdoor Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package object
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/casdoor/casdoor/i18n"
+	"github.com/casdoor/casdoor/util"
+	"github.com/thanhpk/randstr"
+	"github.com/xorm-io/core"
+)
+
+const (
+	ApiKeyPrefix = "cak_"
+
+	ApiKeyScopeEnforce = "enforce"
+)
+
+// apiKeyEndpointScopes holds the endpoints whose scope can't be derived from
+// their name, see getApiKeyScope.
+var apiKeyEndpointScopes = map[string]string{
+	"/api/enforce":           ApiKeyScopeEnforce,
+	"/api/batch-enforce":     ApiKeyScopeEnforce,
+	"/api/check-relation":    ApiKeyScopeEnforce,
+	"/api/get-api-keys":      "read:api-key",
+	"/api/get-api-key":       "read:api-key",
+	"/api/get-user-api-keys": "read:api-key",
+	"/api/add-api-key":       "write:api-key",
+	"/api/revoke-api-key":    "write:api-key",
+	"/api/delete-api-key":    "write:api-key",
+}
+
+// ApiKey is a personal access token of a user, or a service key of an
+// application when Application is set. Only the hash of the key is stored,
+// the key itself is shown once when it's created.
+type ApiKey struct {
+	Owner       string `xorm:"varchar(100) notnull pk" json:"owner"`
+	Name        string `xorm:"varchar(100) notnull pk" json:"name"`
+	CreatedTime string `xorm:"varchar(100)" json:"createdTime"`
+	DisplayName string `xorm:"varchar(100)" json:"displayName"`
+
+	User        string   `xorm:"varchar(100) index" json:"user"`
+	Application string   `xorm:"varchar(100)" json:"application"`
+	Scopes      []string `xorm:"mediumtext" json:"scopes"`
+	ExpireTime  string   `xorm:"varchar(100)" json:"expireTime"`
+	KeyPrefix   string   `xorm:"varchar(100)" json:"keyPrefix"`
+	KeyHash     string   `xorm:"varchar(100) index" json:"-"`
+
+	LastUsedTime string `xorm:"varchar(100)" json:"lastUsedTime"`
+	LastUsedIp   string `xorm:"varchar(100)" json:"lastUsedIp"`
+	IsRevoked    bool   `json:"isRevoked"`
+	RevokedTime  string `xorm:"varchar(100)" json:"revokedTime"`
+}
+
+func GetApiKeyCount(owner, field, value string) int {
+	session := GetSession(owner, -1, -1, field, value, "", "")
+	count, err := session.Count(&ApiKey{})
+	if err != nil {
+		panic(err)
+	}
+
+	return int(count)
+}
+
+func GetApiKeys(owner string) []*ApiKey {
+	apiKeys := []*ApiKey{}
+	err := adapter.Engine.Desc("created_time").Find(&apiKeys, &ApiKey{Owner: owner})
+	if err != nil {
+		panic(err)
+	}
+
+	return apiKeys
+}
+
+func GetPaginationApiKeys(owner string, offset, limit int, field, value, sortField, sortOrder string) []*ApiKey {
+	apiKeys := []*ApiKey{}
+	session := GetSession(owner, offset, limit, field, value, sortField, sortOrder)
+	err := session.Find(&apiKeys)
+	if err != nil {
+		panic(err)
+	}
+
+	return apiKeys
+}
+
+// GetUserApiKeys returns the personal access tokens of the user.
+func GetUserApiKeys(userId string) []*ApiKey {
+	apiKeys := []*ApiKey{}
+	err := adapter.Engine.Desc("created_time").Find(&apiKeys, &ApiKey{User: userId})
+	if err != nil {
+		panic(err)
+	}
+
+	return apiKeys
+}
+
+func getApiKey(owner string, name string) *ApiKey {
+	if owner == "" || name == "" {
+		return nil
+	}
+
+	apiKey := ApiKey{Owner: owner, Name: name}
+	existed, err := adapter.Engine.Get(&apiKey)
+	if err != nil {
+		panic(err)
+	}
+
+	if existed {
+		return &apiKey
+	} else {
+		return nil
+	}
+}
+
+func GetApiKey(id string) *ApiKey {
+	owner, name := util.GetOwnerAndNameFromId(id)
+	return getApiKey(owner, name)
+}
+
+func getApiKeyHash(key string) string {
+	hash := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(hash[:])
+}
+
+// IsApiKey tells API keys apart from the access tokens sent as bearer tokens.
+func IsApiKey(key string) bool {
+	return strings.HasPrefix(key, ApiKeyPrefix)
+}
+
+// GetApiKeyByKey returns the API key matching key, revoked and expired
+// keys included.
+func GetApiKeyByKey(key string) *ApiKey {
+	if !IsApiKey(key) {
+		return nil
+	}
+
+	apiKey := ApiKey{KeyHash: getApiKeyHash(key)}
+	existed, err := adapter.Engine.Get(&apiKey)
+	if err != nil {
+		panic(err)
+	}
+
+	if existed {
+		return &apiKey
+	} else {
+		return nil
+	}
+}
+
+func (apiKey *ApiKey) GetId() string {
+	return fmt.Sprintf("%s/%s", apiKey.Owner, apiKey.Name)
+}
+
+// GetSubject returns the user the requests made with the key act as.
+func (apiKey *ApiKey) GetSubject() string {
+	if apiKey.Application != "" {
+		return fmt.Sprintf("app/%s", apiKey.Application)
+	}
+	return apiKey.User
+}
+
+func isValidApiKeyScope(scope string) bool {
+	if scope == ApiKeyScopeEnforce {
+		return true
+	}
+
+	tokens := strings.SplitN(scope, ":", 2)
+	return len(tokens) == 2 && (tokens[0] == "read" || tokens[0] == "write") && tokens[1] != ""
+}
+
+// CheckApiKey checks the scopes, the expire time and the user or the
+// application of a new key.
+func CheckApiKey(apiKey *ApiKey, lang string) string {
+	if len(apiKey.Scopes) == 0 {
+		return i18n.Translate(lang, "auth:The API key needs at least one scope")
+	}
+	for _, scope := range apiKey.Scopes {
+		if !isValidApiKeyScope(scope) {
+			return fmt.Sprintf(i18n.Translate(lang, "auth:The scope: %s is invalid"), scope)
+		}
+	}
+
+	if apiKey.ExpireTime != "" {
+		expireTime, err := time.Parse(time.RFC3339, apiKey.ExpireTime)
+		if err != nil || expireTime.Before(time.Now()) {
+			return i18n.Translate(lang, "auth:The expire time of the API key is invalid")
+		}
+	}
+
+	if apiKey.Application != "" {
+		application := getApplication("admin", apiKey.Application)
+		if application == nil || application.Organization != apiKey.Owner {
+			return fmt.Sprintf(i18n.Translate(lang, "auth:The application: %s does not exist"), apiKey.Application)
+		}
+		apiKey.User = ""
+	} else {
+		user := GetUser(apiKey.User)
+		if user == nil || user.Owner != apiKey.Owner {
+			return fmt.Sprintf(i18n.Translate(lang, "general:The user: %s doesn't exist"), apiKey.User)
+		}
+	}
+
+	return ""
+}
+
+// AddApiKey generates the key and stores its hash, the returned key can't
+// be read again afterwards.
+func AddApiKey(apiKey *ApiKey) (string, bool) {
+	key := ApiKeyPrefix + randstr.Hex(24)
+	apiKey.KeyPrefix = key[:len(ApiKeyPrefix)+6]
+	apiKey.KeyHash = getApiKeyHash(key)
+	apiKey.LastUsedTime = ""
+	apiKey.LastUsedIp = ""
+	apiKey.IsRevoked = false
+	apiKey.RevokedTime = ""
+
+	affected, err := adapter.Engine.Insert(apiKey)
+	if err != nil {
+		panic(err)
+	}
+
+	if affected == 0 {
+		return "", false
+	}
+	return key, true
+}
+
+func RevokeApiKey(apiKey *ApiKey) bool {
+	apiKey.IsRevoked = true
+	apiKey.RevokedTime = util.GetCurrentTime()
+	affected, err := adapter.Engine.ID(core.PK{apiKey.Owner, apiKey.Name}).Cols("is_revoked", "revoked_time").Update(apiKey)
+	if err != nil {
+		panic(err)
+	}
+
+	return affected != 0
+}
+
+func DeleteApiKey(apiKey *ApiKey) bool {
+	affected, err := adapter.Engine.ID(core.PK{apiKey.Owner, apiKey.Name}).Delete(&ApiKey{})
+	if err != nil {
+		panic(err)
+	}
+
+	return affected != 0
+}
+
+// getApiKeyScope returns the scope needed to call an endpoint. It's derived
+// from the name of the endpoint: "GET /api/get-users" needs "read:user" and
+// "POST /api/update-application" needs "write:application".
+func getApiKeyScope(method string, urlPath string) string {
+	if scope, ok := apiKeyEndpointScopes[urlPath]; ok {
+		return scope
+	}
+	if !strings.HasPrefix(urlPath, "/api/") {
+		return ""
+	}
+
+	tokens := strings.Split(strings.TrimPrefix(urlPath, "/api/"), "-")
+	resource := tokens[len(tokens)-1]
+	if strings.HasSuffix(resource, "ies") {
+		resource = strings.TrimSuffix(resource, "ies") + "y"
+	} else if strings.HasSuffix(resource, "s") && !strings.HasSuffix(resource, "ss") {
+		resource = strings.TrimSuffix(resource, "s")
+	}
+
+	if method == "GET" {
+		return "read:" + resource
+	}
+	return "write:" + resource
+}
+
+// hasApiKeyScope allows "read:*" and "write:*", a write scope includes the
+// read scope of the same resource.
+func hasApiKeyScope(scopes []string, scope string) bool {
+	tokens := strings.SplitN(scope, ":", 2)
+	for _, s := range scopes {
+		if s == scope {
+			return true
+		}
+		if len(tokens) != 2 {
+			continue
+		}
+
+		if s == tokens[0]+":*" || s == "write:*" || (tokens[0] == "read" && s == "write:"+tokens[1]) {
+			return true
+		}
+	}
+	return false
+}
+
+// CheckApiKeyRequest checks that the key is still valid and carries the
+// scope of the endpoint, and records its use.
+func CheckApiKeyRequest(id string, method string, urlPath string, ip string, lang string) string {
+	apiKey := GetApiKey(id)
+	if apiKey == nil {
+		return i18n.Translate(lang, "auth:The API key is invalid")
+	}
+	if apiKey.IsRevoked {
+		return i18n.Translate(lang, "auth:The API key has been revoked")
+	}
+	if apiKey.ExpireTime != "" {
+		expireTime, err := time.Parse(time.RFC3339, apiKey.ExpireTime)
+		if err != nil || expireTime.Before(time.Now()) {
+			return i18n.Translate(lang, "auth:The API key has expired")
+		}
+	}
+
+	scope := getApiKeyScope(method, urlPath)
+	if scope == "" || !hasApiKeyScope(apiKey.Scopes, scope) {
+		return fmt.Sprintf(i18n.Translate(lang, "auth:The API key doesn't have the scope: %s"), scope)
+	}
+
+	// the last use is kept to the minute to avoid a write on every request
+	lastUsedTime, err := time.Parse(time.RFC3339, apiKey.LastUsedTime)
+	if err != nil || time.Since(lastUsedTime) > time.Minute || apiKey.LastUsedIp != ip {
+		apiKey.LastUsedTime = util.GetCurrentTime()
+		apiKey.LastUsedIp = ip
+		_, err = adapter.Engine.ID(core.PK{apiKey.Owner, apiKey.Name}).Cols("last_used_time", "last_used_ip").Update(apiKey)
+		if err != nil {
+			panic(err)
+		}
+	}
+
+	return ""
+}
@@ -0,0 +1,69 @@
+// Copyright 2023 The Casdoor Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package object
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetApiKeyScope(t *testing.T) {
+	scenarios := []struct {
+		description string
+		method      string
+		urlPath     string
+		expected    string
+	}{
+		{"list users", "GET", "/api/get-users", "read:user"},
+		{"get application", "GET", "/api/get-application", "read:application"},
+		{"update application", "POST", "/api/update-application", "write:application"},
+		{"plural with ies", "GET", "/api/get-policies", "read:policy"},
+		{"enforce", "POST", "/api/enforce", "enforce"},
+		{"api keys", "POST", "/api/add-api-key", "write:api-key"},
+		{"not an api endpoint", "GET", "/cas/app/login", ""},
+	}
+
+	for _, scenery := range scenarios {
+		t.Run(scenery.description, func(t *testing.T) {
+			assert.Equal(t, scenery.expected, getApiKeyScope(scenery.method, scenery.urlPath))
+		})
+	}
+}
+
+func TestHasApiKeyScope(t *testing.T) {
+	scenarios := []struct {
+		description string
+		scopes      []string
+		scope       string
+		expected    bool
+	}{
+		{"exact scope", []string{"read:user"}, "read:user", true},
+		{"write includes read", []string{"write:user"}, "read:user", true},
+		{"read doesn't include write", []string{"read:user"}, "write:user", false},
+		{"read wildcard", []string{"read:*"}, "read:application", true},
+		{"read wildcard doesn't write", []string{"read:*"}, "write:application", false},
+		{"write wildcard", []string{"write:*"}, "read:application", true},
+		{"enforce", []string{"enforce"}, "enforce", true},
+		{"wildcards don't enforce", []string{"write:*"}, "enforce", false},
+		{"other resource", []string{"write:user"}, "write:application", false},
+	}
+
+	for _, scenery := range scenarios {
+		t.Run(scenery.description, func(t *testing.T) {
+			assert.Equal(t, scenery.expected, hasApiKeyScope(scenery.scopes, scenery.scope))
+		})
+	}
+}
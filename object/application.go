@@ -16,9 +16,10 @@ package object
 
 import (
 	"fmt"
-	"regexp"
+	"net/url"
 	"strings"
 
+	"github.com/casdoor/casdoor/conf"
 	"github.com/casdoor/casdoor/idp"
 	"github.com/casdoor/casdoor/util"
 	"github.com/xorm-io/core"
@@ -36,6 +37,7 @@ type Application struct {
 	Owner       string `xorm:"varchar(100) notnull pk" json:"owner"`
 	Name        string `xorm:"varchar(100) notnull pk" json:"name"`
 	CreatedTime string `xorm:"varchar(100)" json:"createdTime"`
+	Version     int    `xorm:"int notnull default 0" json:"version"`
 
 	DisplayName         string          `xorm:"varchar(100)" json:"displayName"`
 	Logo                string          `xorm:"varchar(100)" json:"logo"`
@@ -51,6 +53,8 @@ type Application struct {
 	EnableSamlCompress  bool            `json:"enableSamlCompress"`
 	EnableWebAuthn      bool            `json:"enableWebAuthn"`
 	EnableLinkWithEmail bool            `json:"enableLinkWithEmail"`
+	EnableOrgPicker     bool            `json:"enableOrgPicker"`
+	EnableWelcomeEmail  bool            `json:"enableWelcomeEmail"`
 	SamlReplyUrl        string          `xorm:"varchar(100)" json:"samlReplyUrl"`
 	Providers           []*ProviderItem `xorm:"mediumtext" json:"providers"`
 	SignupItems         []*SignupItem   `xorm:"varchar(1000)" json:"signupItems"`
@@ -60,6 +64,7 @@ type Application struct {
 	ClientId             string     `xorm:"varchar(100)" json:"clientId"`
 	ClientSecret         string     `xorm:"varchar(100)" json:"clientSecret"`
 	RedirectUris         []string   `xorm:"varchar(1000)" json:"redirectUris"`
+	RedirectUriPolicy    string     `xorm:"varchar(100)" json:"redirectUriPolicy"`
 	TokenFormat          string     `xorm:"varchar(100)" json:"tokenFormat"`
 	ExpireInHours        int        `json:"expireInHours"`
 	RefreshExpireInHours int        `json:"refreshExpireInHours"`
@@ -71,10 +76,16 @@ type Application struct {
 	SignupHtml           string     `xorm:"mediumtext" json:"signupHtml"`
 	SigninHtml           string     `xorm:"mediumtext" json:"signinHtml"`
 	ThemeData            *ThemeData `xorm:"json" json:"themeData"`
+	Branding             *Branding  `xorm:"json" json:"branding"`
 	FormCss              string     `xorm:"text" json:"formCss"`
 	FormOffset           int        `json:"formOffset"`
 	FormSideHtml         string     `xorm:"mediumtext" json:"formSideHtml"`
 	FormBackgroundUrl    string     `xorm:"varchar(200)" json:"formBackgroundUrl"`
+
+	RebacNamespaces []*RebacNamespace      `xorm:"mediumtext" json:"rebacNamespaces"`
+	RoutingRules    []*RoutingRule         `xorm:"mediumtext" json:"routingRules"`
+	Attestation     *AttestationConfig     `xorm:"json" json:"attestation"`
+	ClientCertLogin *ClientCertLoginConfig `xorm:"json" json:"clientCertLogin"`
 }
 
 func GetApplicationCount(owner, field, value string) int {
@@ -155,7 +166,7 @@ func getProviderMap(owner string) map[string]*Provider {
 }
 
 func extendApplicationWithProviders(application *Application) {
-	m := getProviderMap(application.Organization)
+	m := getInheritedProviderMap(application.Organization)
 	for _, providerItem := range application.Providers {
 		if provider, ok := m[providerItem.Name]; ok {
 			providerItem.Provider = provider
@@ -165,7 +176,7 @@ func extendApplicationWithProviders(application *Application) {
 
 func extendApplicationWithOrg(application *Application) {
 	organization := getOrganization(application.Owner, application.Organization)
-	application.OrganizationObj = organization
+	application.OrganizationObj = getInheritedOrganization(organization)
 }
 
 func getApplication(owner string, name string) *Application {
@@ -174,18 +185,22 @@ func getApplication(owner string, name string) *Application {
 	}
 
 	application := Application{Owner: owner, Name: name}
-	existed, err := adapter.Engine.Get(&application)
-	if err != nil {
-		panic(err)
-	}
+	key := getSharedCacheKey("application", application.GetId())
+	if !getCachedObject(key, &application) {
+		existed, err := adapter.Engine.Get(&application)
+		if err != nil {
+			panic(err)
+		}
+		if !existed {
+			return nil
+		}
 
-	if existed {
-		extendApplicationWithProviders(&application)
-		extendApplicationWithOrg(&application)
-		return &application
-	} else {
-		return nil
+		setCachedObject(key, &application)
 	}
+
+	extendApplicationWithProviders(&application)
+	extendApplicationWithOrg(&application)
+	return &application
 }
 
 func GetApplicationByOrganizationName(organization string) *Application {
@@ -227,7 +242,17 @@ func GetApplicationByUserId(userId string) (*Application, *User) {
 	return application, user
 }
 
+// GetApplicationByClientId caches the ID of the client ID, a cached ID that
+// no longer has the client ID is looked up again.
 func GetApplicationByClientId(clientId string) *Application {
+	key := getSharedCacheKey("application-client", clientId)
+	id := ""
+	if getCachedObject(key, &id) {
+		if application := GetApplication(id); application != nil && application.ClientId == clientId {
+			return application
+		}
+	}
+
 	application := Application{}
 	existed, err := adapter.Engine.Where("client_id=?", clientId).Get(&application)
 	if err != nil {
@@ -235,6 +260,7 @@ func GetApplicationByClientId(clientId string) *Application {
 	}
 
 	if existed {
+		setCachedObject(key, application.GetId())
 		extendApplicationWithProviders(&application)
 		extendApplicationWithOrg(&application)
 		return &application
@@ -260,6 +286,7 @@ func GetMaskedApplication(application *Application, userId string) *Application
 	if application.ClientSecret != "" {
 		application.ClientSecret = "***"
 	}
+	maskAttestationConfig(application.Attestation)
 
 	if application.OrganizationObj != nil {
 		if application.OrganizationObj.MasterPassword != "" {
@@ -287,10 +314,20 @@ func GetMaskedApplications(applications []*Application, userId string) []*Applic
 }
 
 func UpdateApplication(id string, application *Application) bool {
+	affected, _ := UpdateApplicationWithPrecondition(id, application, nil)
+	return affected
+}
+
+// UpdateApplicationWithPrecondition updates the application only if it
+// satisfies precondition, otherwise it returns ErrPreconditionFailed.
+func UpdateApplicationWithPrecondition(id string, application *Application, precondition *Precondition) (bool, error) {
 	owner, name := util.GetOwnerAndNameFromId(id)
 	oldApplication := getApplication(owner, name)
+	if !precondition.isSatisfiedBy(oldApplication) {
+		return false, ErrPreconditionFailed
+	}
 	if oldApplication == nil {
-		return false
+		return false, nil
 	}
 
 	if name == "app-built-in" {
@@ -298,30 +335,39 @@ func UpdateApplication(id string, application *Application) bool {
 	}
 
 	if name != application.Name {
-		err := applicationChangeTrigger(name, application.Name)
+		err := precondition.checkRename(name, application.Name)
 		if err != nil {
-			return false
+			return false, err
+		}
+		err = applicationChangeTrigger(name, application.Name)
+		if err != nil {
+			return false, nil
 		}
 	}
 
 	if oldApplication.ClientId != application.ClientId && GetApplicationByClientId(application.ClientId) != nil {
-		return false
+		return false, nil
 	}
 
 	for _, providerItem := range application.Providers {
 		providerItem.Provider = nil
 	}
 
+	if application.Attestation != nil && application.Attestation.PlayDecryptionKey == "***" && oldApplication.Attestation != nil {
+		application.Attestation.PlayDecryptionKey = oldApplication.Attestation.PlayDecryptionKey
+	}
+
 	session := adapter.Engine.ID(core.PK{owner, name}).AllCols()
 	if application.ClientSecret == "***" {
 		session.Omit("client_secret")
 	}
-	affected, err := session.Update(application)
+	affected, err := updateWithVersion(session, application, oldApplication.Version, precondition)
 	if err != nil {
-		panic(err)
+		return false, err
 	}
 
-	return affected != 0
+	deleteCachedObjects(getSharedCacheKey("application", id), getSharedCacheKey("application", application.GetId()), getSharedCacheKey("application-client", oldApplication.ClientId))
+	return affected, nil
 }
 
 func AddApplication(application *Application) bool {
@@ -356,6 +402,7 @@ func DeleteApplication(application *Application) bool {
 		panic(err)
 	}
 
+	deleteCachedObjects(getSharedCacheKey("application", application.GetId()))
 	return affected != 0
 }
 
@@ -363,22 +410,22 @@ func (application *Application) GetId() string {
 	return fmt.Sprintf("%s/%s", application.Owner, application.Name)
 }
 
-func (application *Application) IsRedirectUriValid(redirectUri string) bool {
-	isValid := false
-	for _, targetUri := range application.RedirectUris {
-		targetUriRegex := regexp.MustCompile(targetUri)
-		if targetUriRegex.MatchString(redirectUri) || strings.Contains(redirectUri, targetUri) {
-			isValid = true
-			break
+func IsOriginAllowed(origin string) bool {
+	// allowedOrigins is a comma-separated allowlist on top of the redirect URIs
+	for _, allowedOrigin := range strings.Split(conf.GetConfigString("allowedOrigins"), ",") {
+		if allowedOrigin = strings.TrimSpace(allowedOrigin); allowedOrigin != "" && allowedOrigin == origin {
+			return true
 		}
 	}
-	return isValid
-}
 
-func IsOriginAllowed(origin string) bool {
+	// the login pages are also served on the verified domains of organizations
+	if u, err := url.Parse(origin); err == nil && u.Scheme == "https" && GetOrganizationByDomain(u.Host) != nil {
+		return true
+	}
+
 	applications := GetApplications("")
 	for _, application := range applications {
-		if application.IsRedirectUriValid(origin) {
+		if application.isOriginValid(origin) {
 			return true
 		}
 	}
@@ -427,7 +474,7 @@ func applicationChangeTrigger(oldName string, newName string) error {
 
 	organization := new(Organization)
 	organization.DefaultApplication = newName
-	_, err = session.Where("default_application=?", oldName).Update(organization)
+	_, err = session.Where("default_application=?", oldName).Incr("version").Update(organization)
 	if err != nil {
 		return err
 	}
@@ -438,6 +485,12 @@ func applicationChangeTrigger(oldName string, newName string) error {
 	if err != nil {
 		return err
 	}
+	for _, engine := range getUserEngines()[1:] {
+		_, err = engine.Where("signup_application=?", oldName).Update(user)
+		if err != nil {
+			return err
+		}
+	}
 
 	resource := new(Resource)
 	resource.Application = newName
@@ -465,5 +518,11 @@ func applicationChangeTrigger(oldName string, newName string) error {
 		}
 	}
 
-	return session.Commit()
+	err = session.Commit()
+	if err != nil {
+		return err
+	}
+
+	deleteCachedObjectsByQuery("organization", "default_application=?", newName)
+	return nil
 }
@@ -0,0 +1,162 @@
+// Copyright 2023 The Casdoor Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package object
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"fmt"
+	"time"
+
+	"github.com/thanhpk/randstr"
+)
+
+const (
+	AttestationTypeAppAttest     = "apple-appattest"
+	AttestationTypePlayIntegrity = "play-integrity"
+
+	attestationChallengeExpireIn = 5 * time.Minute
+)
+
+// AttestationConfig is the mobile app attestation of an application. With
+// Required set, the tokens of the user-facing grants are only issued to
+// requests that prove to come from one of the listed apps, so that a
+// phishing proxy holding the client ID of a public client can't get them.
+type AttestationConfig struct {
+	Required bool `json:"required"`
+
+	// AppleAppIds are "<team ID>.<bundle ID>"
+	AppleAppIds      []string `json:"appleAppIds"`
+	AppleDevelopment bool     `json:"appleDevelopment"`
+
+	PlayPackageNames []string `json:"playPackageNames"`
+	// PlayCertDigests are the SHA-256 digests of the signing certificates,
+	// as reported in the verdicts, an empty list accepts any certificate
+	PlayCertDigests            []string `json:"playCertDigests"`
+	PlayDecryptionKey          string   `json:"playDecryptionKey"`
+	PlayVerificationKey        string   `json:"playVerificationKey"`
+	PlayRequireDeviceIntegrity bool     `json:"playRequireDeviceIntegrity"`
+}
+
+// AttestationRequest is what a mobile client sends with a token request:
+// an App Attest assertion made with a registered key or a Play Integrity
+// token, both over a challenge of GetAttestationChallenge.
+type AttestationRequest struct {
+	Type      string
+	Challenge string
+	KeyId     string
+	Token     string
+}
+
+func signAttestationChallenge(application *Application, payload []byte) []byte {
+	mac := hmac.New(sha256.New, []byte(application.ClientSecret))
+	mac.Write([]byte(application.GetId()))
+	mac.Write(payload)
+	return mac.Sum(nil)
+}
+
+// GetAttestationChallenge returns a challenge that is valid for five minutes.
+// It is signed with the client secret instead of being stored, and is URL-safe
+// base64 as Play Integrity requires for the nonce.
+func GetAttestationChallenge(application *Application) string {
+	return getAttestationChallenge(application, time.Now())
+}
+
+func getAttestationChallenge(application *Application, now time.Time) string {
+	payload := make([]byte, 8, 56)
+	binary.BigEndian.PutUint64(payload, uint64(now.Unix()))
+	payload = append(payload, []byte(randstr.String(16))...)
+	payload = append(payload, signAttestationChallenge(application, payload)...)
+	return base64.RawURLEncoding.EncodeToString(payload)
+}
+
+func checkAttestationChallenge(application *Application, challenge string, now time.Time) error {
+	data, err := base64.RawURLEncoding.DecodeString(challenge)
+	if err != nil || len(data) != 56 {
+		return fmt.Errorf("the attestation challenge is invalid")
+	}
+
+	payload := data[:24]
+	if !hmac.Equal(data[24:], signAttestationChallenge(application, payload)) {
+		return fmt.Errorf("the attestation challenge is invalid")
+	}
+
+	issuedTime := time.Unix(int64(binary.BigEndian.Uint64(payload)), 0)
+	if now.Sub(issuedTime) > attestationChallengeExpireIn || issuedTime.After(now.Add(time.Minute)) {
+		return fmt.Errorf("the attestation challenge has expired")
+	}
+	return nil
+}
+
+// VerifyAttestation checks the attestation of a request and returns what the
+// issued token is bound to: the attested app as "<type>:<app ID>".
+func VerifyAttestation(application *Application, request *AttestationRequest) (string, error) {
+	config := application.Attestation
+	if config == nil {
+		return "", fmt.Errorf("the application: %s has no mobile app attestation", application.Name)
+	}
+
+	err := checkAttestationChallenge(application, request.Challenge, time.Now())
+	if err != nil {
+		return "", err
+	}
+
+	var appId string
+	switch request.Type {
+	case AttestationTypeAppAttest:
+		appId, err = checkAppAttestAssertion(application, request.KeyId, request.Token, request.Challenge)
+	case AttestationTypePlayIntegrity:
+		appId, err = verifyPlayIntegrityToken(config, request.Token, request.Challenge, time.Now())
+	default:
+		err = fmt.Errorf("the attestation type: %s is not supported", request.Type)
+	}
+	if err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("%s:%s", request.Type, appId), nil
+}
+
+// checkTokenAttestation verifies the attestation of a token request if there
+// is one, and requires it for the grants of users when the application does.
+func checkTokenAttestation(application *Application, grantType string, request *AttestationRequest) (string, *TokenError) {
+	hasAttestation := request != nil && request.Type != ""
+	if !hasAttestation {
+		if application.Attestation != nil && application.Attestation.Required && grantType != "client_credentials" {
+			return "", &TokenError{
+				Error:            InvalidClient,
+				ErrorDescription: "the application requires the attestation of the mobile app",
+			}
+		}
+		return "", nil
+	}
+
+	attestation, err := VerifyAttestation(application, request)
+	if err != nil {
+		return "", &TokenError{
+			Error:            InvalidClient,
+			ErrorDescription: fmt.Sprintf("attestation error: %s", err.Error()),
+		}
+	}
+	return attestation, nil
+}
+
+func maskAttestationConfig(config *AttestationConfig) {
+	if config != nil && config.PlayDecryptionKey != "" {
+		config.PlayDecryptionKey = "***"
+	}
+}
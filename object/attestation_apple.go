@@ -0,0 +1,372 @@
+// Copyright 2023 The Casdoor Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package object
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/asn1"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/pem"
+	"fmt"
+	"time"
+
+	"github.com/casdoor/casdoor/util"
+	"github.com/go-webauthn/webauthn/protocol/webauthncbor"
+	"github.com/xorm-io/core"
+)
+
+// appleAppAttestRootCa is the "Apple App Attestation Root CA" from
+// https://www.apple.com/certificateauthority/private/
+const appleAppAttestRootCa = `-----BEGIN CERTIFICATE-----
+MIICITCCAaegAwIBAgIQC/O+DvHN0uD7jG5yH2IXmDAKBggqhkjOPQQDAzBSMSYw
+JAYDVQQDDB1BcHBsZSBBcHAgQXR0ZXN0YXRpb24gUm9vdCBDQTETMBEGA1UECgwK
+QXBwbGUgSW5jLjETMBEGA1UECAwKQ2FsaWZvcm5pYTAeFw0yMDAzMTgxODMyNTNa
+Fw00NTAzMTUwMDAwMDBaMFIxJjAkBgNVBAMMHUFwcGxlIEFwcCBBdHRlc3RhdGlv
+biBSb290IENBMRMwEQYDVQQKDApBcHBsZSBJbmMuMRMwEQYDVQQIDApDYWxpZm9y
+bmlhMHYwEAYHKoZIzj0CAQYFK4EEACIDYgAERTHhmLW07ATaFQIEVwTtT4dyctdh
+NbJhFs/Ii2FdCgAHGbpphY3+d8qjuDngIN3WVhQUBHAoMeQ/cLiP1sOUtgjqK9au
+Yen1mMEvRq9Sk3Jm5X8U62H+xTD3FE9TgS41o0IwQDAPBgNVHRMBAf8EBTADAQH/
+MB0GA1UdDgQWBBSskRBTM72+aEH/pwyp5frq5eWKoTAOBgNVHQ8BAf8EBAMCAQYw
+CgYIKoZIzj0EAwMDaAAwZQIwQgFGnByvsiVbpTKwSga0kP0e8EeDS4+sQmTvb7vn
+53O5+FRXgeLhpJ06ysC5PrOyAjEAp5U4xDgEgllF7En3VcE3iexZZtKeYnpqtijV
+oyFraWVIyd/dganmrduC1bmTBGwD
+-----END CERTIFICATE-----`
+
+var (
+	appAttestNonceOid = asn1.ObjectIdentifier{1, 2, 840, 113635, 100, 8, 2}
+
+	appAttestProductionAaguid  = []byte("appattest\x00\x00\x00\x00\x00\x00\x00")
+	appAttestDevelopmentAaguid = []byte("appattestdevelop")
+)
+
+// AppAttestKey is a key of the App Attest service of an iOS app, registered
+// once with its attestation and then used to sign the assertions.
+type AppAttestKey struct {
+	Owner       string `xorm:"varchar(100) notnull pk" json:"owner"`
+	Name        string `xorm:"varchar(100) notnull pk" json:"name"`
+	CreatedTime string `xorm:"varchar(100)" json:"createdTime"`
+	UpdatedTime string `xorm:"varchar(100)" json:"updatedTime"`
+
+	Application string `xorm:"varchar(100) index" json:"application"`
+	AppId       string `xorm:"varchar(200)" json:"appId"`
+	PublicKey   string `xorm:"text" json:"publicKey"`
+	Counter     int64  `json:"counter"`
+}
+
+type appAttestObject struct {
+	Fmt     string `cbor:"fmt"`
+	AttStmt struct {
+		X5c     [][]byte `cbor:"x5c"`
+		Receipt []byte   `cbor:"receipt"`
+	} `cbor:"attStmt"`
+	AuthData []byte `cbor:"authData"`
+}
+
+type appAttestAssertion struct {
+	Signature         []byte `cbor:"signature"`
+	AuthenticatorData []byte `cbor:"authenticatorData"`
+}
+
+type appAttestNonce struct {
+	Nonce []byte `asn1:"tag:1,explicit"`
+}
+
+type appAttestAuthData struct {
+	RpIdHash     []byte
+	Counter      uint32
+	Aaguid       []byte
+	CredentialId []byte
+}
+
+func parseAppAttestAuthData(data []byte, hasCredential bool) (*appAttestAuthData, error) {
+	if len(data) < 37 {
+		return nil, fmt.Errorf("the authenticator data is too short")
+	}
+
+	authData := &appAttestAuthData{
+		RpIdHash: data[:32],
+		Counter:  binary.BigEndian.Uint32(data[33:37]),
+	}
+	if !hasCredential {
+		return authData, nil
+	}
+
+	if len(data) < 55 {
+		return nil, fmt.Errorf("the authenticator data has no attested credential")
+	}
+	authData.Aaguid = data[37:53]
+	credentialIdLength := int(binary.BigEndian.Uint16(data[53:55]))
+	if len(data) < 55+credentialIdLength {
+		return nil, fmt.Errorf("the authenticator data has no attested credential")
+	}
+	authData.CredentialId = data[55 : 55+credentialIdLength]
+	return authData, nil
+}
+
+// getAppAttestAppId returns the app whose ID hashes to rpIdHash.
+func getAppAttestAppId(config *AttestationConfig, rpIdHash []byte) string {
+	for _, appId := range config.AppleAppIds {
+		hash := sha256.Sum256([]byte(appId))
+		if bytes.Equal(hash[:], rpIdHash) {
+			return appId
+		}
+	}
+	return ""
+}
+
+func getAppAttestNonce(authData []byte, challenge string) []byte {
+	clientDataHash := sha256.Sum256([]byte(challenge))
+	nonce := sha256.Sum256(append(append([]byte{}, authData...), clientDataHash[:]...))
+	return nonce[:]
+}
+
+func getAppAttestKeyName(keyId []byte) string {
+	return hex.EncodeToString(keyId)
+}
+
+// verifyAppAttestation checks the attestation object of a new key as Apple
+// describes in "Validating apps that connect to your server" and returns the
+// key to store.
+func verifyAppAttestation(config *AttestationConfig, keyId []byte, attestation []byte, challenge string) (*AppAttestKey, error) {
+	object := appAttestObject{}
+	err := webauthncbor.Unmarshal(attestation, &object)
+	if err != nil {
+		return nil, fmt.Errorf("the attestation object is invalid: %s", err.Error())
+	}
+	if object.Fmt != AttestationTypeAppAttest || len(object.AttStmt.X5c) < 2 {
+		return nil, fmt.Errorf("the attestation object is not of App Attest")
+	}
+
+	certs := []*x509.Certificate{}
+	for _, der := range object.AttStmt.X5c {
+		cert, err := x509.ParseCertificate(der)
+		if err != nil {
+			return nil, err
+		}
+		certs = append(certs, cert)
+	}
+
+	roots := x509.NewCertPool()
+	block, _ := pem.Decode([]byte(appleAppAttestRootCa))
+	rootCert, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+	roots.AddCert(rootCert)
+	intermediates := x509.NewCertPool()
+	for _, cert := range certs[1:] {
+		intermediates.AddCert(cert)
+	}
+	credentialCert := certs[0]
+	_, err = credentialCert.Verify(x509.VerifyOptions{Roots: roots, Intermediates: intermediates, KeyUsages: []x509.ExtKeyUsage{x509.ExtKeyUsageAny}})
+	if err != nil {
+		return nil, fmt.Errorf("the certificate of the attestation is not issued by Apple: %s", err.Error())
+	}
+
+	var nonce []byte
+	for _, extension := range credentialCert.Extensions {
+		if extension.Id.Equal(appAttestNonceOid) {
+			appNonce := appAttestNonce{}
+			if _, err = asn1.Unmarshal(extension.Value, &appNonce); err != nil {
+				return nil, err
+			}
+			nonce = appNonce.Nonce
+		}
+	}
+	if !bytes.Equal(nonce, getAppAttestNonce(object.AuthData, challenge)) {
+		return nil, fmt.Errorf("the nonce of the attestation doesn't match the challenge")
+	}
+
+	publicKey, ok := credentialCert.PublicKey.(*ecdsa.PublicKey)
+	if !ok {
+		return nil, fmt.Errorf("the key of the attestation is not an EC key")
+	}
+	publicKeyHash := sha256.Sum256(elliptic.Marshal(publicKey.Curve, publicKey.X, publicKey.Y))
+	if !bytes.Equal(publicKeyHash[:], keyId) {
+		return nil, fmt.Errorf("the key ID doesn't match the attested key")
+	}
+
+	authData, err := parseAppAttestAuthData(object.AuthData, true)
+	if err != nil {
+		return nil, err
+	}
+	appId := getAppAttestAppId(config, authData.RpIdHash)
+	if appId == "" {
+		return nil, fmt.Errorf("the attested app is not allowed for the application")
+	}
+	if authData.Counter != 0 {
+		return nil, fmt.Errorf("the counter of a new key should be 0")
+	}
+	if !bytes.Equal(authData.Aaguid, appAttestProductionAaguid) && !(config.AppleDevelopment && bytes.Equal(authData.Aaguid, appAttestDevelopmentAaguid)) {
+		return nil, fmt.Errorf("the environment of the attestation is not allowed")
+	}
+	if !bytes.Equal(authData.CredentialId, keyId) {
+		return nil, fmt.Errorf("the credential ID doesn't match the key ID")
+	}
+
+	publicKeyDer, err := x509.MarshalPKIXPublicKey(publicKey)
+	if err != nil {
+		return nil, err
+	}
+
+	return &AppAttestKey{
+		Name:      getAppAttestKeyName(keyId),
+		AppId:     appId,
+		PublicKey: base64.StdEncoding.EncodeToString(publicKeyDer),
+	}, nil
+}
+
+// verifyAppAttestAssertion checks an assertion of a registered key and
+// returns its counter, which must be bigger than the one of the last use.
+func verifyAppAttestAssertion(key *AppAttestKey, assertion []byte, challenge string) (uint32, error) {
+	object := appAttestAssertion{}
+	err := webauthncbor.Unmarshal(assertion, &object)
+	if err != nil {
+		return 0, fmt.Errorf("the assertion is invalid: %s", err.Error())
+	}
+
+	publicKeyDer, err := base64.StdEncoding.DecodeString(key.PublicKey)
+	if err != nil {
+		return 0, err
+	}
+	publicKey, err := x509.ParsePKIXPublicKey(publicKeyDer)
+	if err != nil {
+		return 0, err
+	}
+	ecdsaPublicKey, ok := publicKey.(*ecdsa.PublicKey)
+	if !ok {
+		return 0, fmt.Errorf("the key is not an EC key")
+	}
+
+	digest := sha256.Sum256(getAppAttestNonce(object.AuthenticatorData, challenge))
+	if !ecdsa.VerifyASN1(ecdsaPublicKey, digest[:], object.Signature) {
+		return 0, fmt.Errorf("the signature of the assertion is invalid")
+	}
+
+	authData, err := parseAppAttestAuthData(object.AuthenticatorData, false)
+	if err != nil {
+		return 0, err
+	}
+	rpIdHash := sha256.Sum256([]byte(key.AppId))
+	if !bytes.Equal(authData.RpIdHash, rpIdHash[:]) {
+		return 0, fmt.Errorf("the assertion is not made by the app: %s", key.AppId)
+	}
+	if int64(authData.Counter) <= key.Counter {
+		return 0, fmt.Errorf("the assertion has been used")
+	}
+	return authData.Counter, nil
+}
+
+func decodeAppAttestKeyId(keyId string) ([]byte, error) {
+	data, err := base64.StdEncoding.DecodeString(keyId)
+	if err != nil || len(data) != sha256.Size {
+		return nil, fmt.Errorf("the key ID is invalid")
+	}
+	return data, nil
+}
+
+func getAppAttestKey(owner string, name string) *AppAttestKey {
+	key := AppAttestKey{Owner: owner, Name: name}
+	existed, err := adapter.Engine.Get(&key)
+	if err != nil {
+		panic(err)
+	}
+
+	if existed {
+		return &key
+	}
+	return nil
+}
+
+// RegisterAppAttestKey stores the key of an attestation made over a challenge
+// of GetAttestationChallenge, keyId and attestation are base64 as returned by
+// DCAppAttestService.
+func RegisterAppAttestKey(application *Application, keyId string, attestation string, challenge string) error {
+	if application.Attestation == nil || len(application.Attestation.AppleAppIds) == 0 {
+		return fmt.Errorf("the application: %s has no iOS apps for App Attest", application.Name)
+	}
+
+	err := checkAttestationChallenge(application, challenge, time.Now())
+	if err != nil {
+		return err
+	}
+
+	keyIdBytes, err := decodeAppAttestKeyId(keyId)
+	if err != nil {
+		return err
+	}
+	attestationBytes, err := base64.StdEncoding.DecodeString(attestation)
+	if err != nil {
+		return fmt.Errorf("the attestation object is invalid: %s", err.Error())
+	}
+
+	key, err := verifyAppAttestation(application.Attestation, keyIdBytes, attestationBytes, challenge)
+	if err != nil {
+		return err
+	}
+	if getAppAttestKey(application.Owner, key.Name) != nil {
+		return fmt.Errorf("the key has been registered")
+	}
+
+	key.Owner = application.Owner
+	key.CreatedTime = util.GetCurrentTime()
+	key.UpdatedTime = key.CreatedTime
+	key.Application = application.Name
+	_, err = adapter.Engine.Insert(key)
+	if err != nil {
+		panic(err)
+	}
+	return nil
+}
+
+// checkAppAttestAssertion verifies an assertion and moves the counter of the
+// key forward, so that the same assertion can't be used twice.
+func checkAppAttestAssertion(application *Application, keyId string, assertion string, challenge string) (string, error) {
+	keyIdBytes, err := decodeAppAttestKeyId(keyId)
+	if err != nil {
+		return "", err
+	}
+	key := getAppAttestKey(application.Owner, getAppAttestKeyName(keyIdBytes))
+	if key == nil || key.Application != application.Name {
+		return "", fmt.Errorf("the key is not registered for the application")
+	}
+	if application.Attestation == nil || !util.ContainsString(application.Attestation.AppleAppIds, key.AppId) {
+		return "", fmt.Errorf("the app: %s is no longer allowed for the application", key.AppId)
+	}
+
+	assertionBytes, err := base64.StdEncoding.DecodeString(assertion)
+	if err != nil {
+		return "", fmt.Errorf("the assertion is invalid: %s", err.Error())
+	}
+	counter, err := verifyAppAttestAssertion(key, assertionBytes, challenge)
+	if err != nil {
+		return "", err
+	}
+
+	affected, err := adapter.Engine.ID(core.PK{key.Owner, key.Name}).Where("counter < ?", counter).Cols("counter", "updated_time").Update(&AppAttestKey{Counter: int64(counter), UpdatedTime: util.GetCurrentTime()})
+	if err != nil {
+		panic(err)
+	}
+	if affected == 0 {
+		return "", fmt.Errorf("the assertion has been used")
+	}
+	return key.AppId, nil
+}
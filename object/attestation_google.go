@@ -0,0 +1,141 @@
+// Copyright 2023 The Casdoor Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package object
+
+import (
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/casdoor/casdoor/util"
+	"gopkg.in/square/go-jose.v2"
+)
+
+const playIntegrityTokenExpireIn = 5 * time.Minute
+
+type playIntegrityVerdict struct {
+	RequestDetails struct {
+		RequestPackageName string `json:"requestPackageName"`
+		Nonce              string `json:"nonce"`
+		TimestampMillis    string `json:"timestampMillis"`
+	} `json:"requestDetails"`
+	AppIntegrity struct {
+		AppRecognitionVerdict   string   `json:"appRecognitionVerdict"`
+		PackageName             string   `json:"packageName"`
+		CertificateSha256Digest []string `json:"certificateSha256Digest"`
+	} `json:"appIntegrity"`
+	DeviceIntegrity struct {
+		DeviceRecognitionVerdict []string `json:"deviceRecognitionVerdict"`
+	} `json:"deviceIntegrity"`
+}
+
+// decryptPlayIntegrityToken decrypts and verifies an integrity token locally
+// with the keys that Google manages for the app ("Manage and download my
+// response encryption keys" in the Play Console), both given in base64.
+func decryptPlayIntegrityToken(config *AttestationConfig, integrityToken string) (*playIntegrityVerdict, error) {
+	decryptionKey, err := base64.StdEncoding.DecodeString(config.PlayDecryptionKey)
+	if err != nil {
+		return nil, fmt.Errorf("the decryption key of Play Integrity is invalid: %s", err.Error())
+	}
+	verificationKeyDer, err := base64.StdEncoding.DecodeString(config.PlayVerificationKey)
+	if err != nil {
+		return nil, fmt.Errorf("the verification key of Play Integrity is invalid: %s", err.Error())
+	}
+	verificationKey, err := x509.ParsePKIXPublicKey(verificationKeyDer)
+	if err != nil {
+		return nil, fmt.Errorf("the verification key of Play Integrity is invalid: %s", err.Error())
+	}
+
+	encrypted, err := jose.ParseEncrypted(integrityToken)
+	if err != nil {
+		return nil, fmt.Errorf("the integrity token is invalid: %s", err.Error())
+	}
+	signed, err := encrypted.Decrypt(decryptionKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt the integrity token: %s", err.Error())
+	}
+
+	jws, err := jose.ParseSigned(string(signed))
+	if err != nil {
+		return nil, fmt.Errorf("the integrity token is invalid: %s", err.Error())
+	}
+	payload, err := jws.Verify(verificationKey)
+	if err != nil {
+		return nil, fmt.Errorf("the signature of the integrity token is invalid: %s", err.Error())
+	}
+
+	verdict := &playIntegrityVerdict{}
+	err = json.Unmarshal(payload, verdict)
+	if err != nil {
+		return nil, err
+	}
+	return verdict, nil
+}
+
+// checkPlayIntegrityVerdict returns the package name of a verdict that
+// recognizes one of the apps of config, for a request made over challenge.
+func checkPlayIntegrityVerdict(config *AttestationConfig, verdict *playIntegrityVerdict, challenge string, now time.Time) (string, error) {
+	// the nonce may come back with padding
+	if strings.TrimRight(verdict.RequestDetails.Nonce, "=") != challenge {
+		return "", fmt.Errorf("the nonce of the integrity token doesn't match the challenge")
+	}
+
+	timestampMillis, err := strconv.ParseInt(verdict.RequestDetails.TimestampMillis, 10, 64)
+	if err != nil || now.Sub(time.Unix(0, timestampMillis*int64(time.Millisecond))) > playIntegrityTokenExpireIn {
+		return "", fmt.Errorf("the integrity token has expired")
+	}
+
+	packageName := verdict.AppIntegrity.PackageName
+	if verdict.RequestDetails.RequestPackageName != packageName || !util.ContainsString(config.PlayPackageNames, packageName) {
+		return "", fmt.Errorf("the app: %s is not allowed for the application", packageName)
+	}
+	if verdict.AppIntegrity.AppRecognitionVerdict != "PLAY_RECOGNIZED" {
+		return "", fmt.Errorf("the app is not recognized by Google Play: %s", verdict.AppIntegrity.AppRecognitionVerdict)
+	}
+
+	if len(config.PlayCertDigests) != 0 {
+		matched := false
+		for _, digest := range verdict.AppIntegrity.CertificateSha256Digest {
+			if util.ContainsString(config.PlayCertDigests, digest) {
+				matched = true
+			}
+		}
+		if !matched {
+			return "", fmt.Errorf("the signing certificate of the app is not allowed")
+		}
+	}
+
+	if config.PlayRequireDeviceIntegrity && !util.ContainsString(verdict.DeviceIntegrity.DeviceRecognitionVerdict, "MEETS_DEVICE_INTEGRITY") {
+		return "", fmt.Errorf("the device doesn't meet the device integrity")
+	}
+
+	return packageName, nil
+}
+
+func verifyPlayIntegrityToken(config *AttestationConfig, integrityToken string, challenge string, now time.Time) (string, error) {
+	if len(config.PlayPackageNames) == 0 {
+		return "", fmt.Errorf("the application has no Android apps for Play Integrity")
+	}
+
+	verdict, err := decryptPlayIntegrityToken(config, integrityToken)
+	if err != nil {
+		return "", err
+	}
+	return checkPlayIntegrityVerdict(config, verdict, challenge, now)
+}
@@ -0,0 +1,127 @@
+// Copyright 2023 The Casdoor Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package object
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/binary"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/go-webauthn/webauthn/protocol/webauthncbor"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckAttestationChallenge(t *testing.T) {
+	application := &Application{Owner: "admin", Name: "app", ClientSecret: "secret"}
+	now := time.Now()
+	challenge := getAttestationChallenge(application, now)
+
+	assert.Nil(t, checkAttestationChallenge(application, challenge, now.Add(time.Minute)))
+	assert.NotNil(t, checkAttestationChallenge(application, challenge, now.Add(10*time.Minute)), "an old challenge must expire")
+	assert.NotNil(t, checkAttestationChallenge(&Application{Owner: "admin", Name: "other", ClientSecret: "secret"}, challenge, now), "a challenge is only valid for its application")
+	assert.NotNil(t, checkAttestationChallenge(application, challenge[:len(challenge)-2]+"AA", now))
+	assert.NotNil(t, checkAttestationChallenge(application, "invalid", now))
+}
+
+func newAppAttestAssertion(t *testing.T, privateKey *ecdsa.PrivateKey, appId string, counter uint32, challenge string) []byte {
+	rpIdHash := sha256.Sum256([]byte(appId))
+	authData := append(rpIdHash[:], 0x40, 0, 0, 0, 0)
+	binary.BigEndian.PutUint32(authData[33:], counter)
+
+	digest := sha256.Sum256(getAppAttestNonce(authData, challenge))
+	signature, err := ecdsa.SignASN1(rand.Reader, privateKey, digest[:])
+	assert.Nil(t, err)
+
+	assertion, err := webauthncbor.Marshal(&appAttestAssertion{Signature: signature, AuthenticatorData: authData})
+	assert.Nil(t, err)
+	return assertion
+}
+
+func TestVerifyAppAttestAssertion(t *testing.T) {
+	privateKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.Nil(t, err)
+	publicKeyDer, err := x509.MarshalPKIXPublicKey(&privateKey.PublicKey)
+	assert.Nil(t, err)
+	key := &AppAttestKey{AppId: "TEAMID.com.example.app", PublicKey: base64.StdEncoding.EncodeToString(publicKeyDer), Counter: 3}
+
+	scenarios := []struct {
+		description string
+		appId       string
+		counter     uint32
+		challenge   string
+		valid       bool
+	}{
+		{"valid", key.AppId, 4, "challenge", true},
+		{"used counter", key.AppId, 3, "challenge", false},
+		{"other app", "TEAMID.com.example.other", 4, "challenge", false},
+		{"other challenge", key.AppId, 4, "other", false},
+	}
+
+	for _, scenery := range scenarios {
+		t.Run(scenery.description, func(t *testing.T) {
+			assertion := newAppAttestAssertion(t, privateKey, scenery.appId, scenery.counter, scenery.challenge)
+			counter, err := verifyAppAttestAssertion(key, assertion, "challenge")
+			if scenery.valid {
+				assert.Nil(t, err)
+				assert.Equal(t, scenery.counter, counter)
+			} else {
+				assert.NotNil(t, err)
+			}
+		})
+	}
+}
+
+func TestCheckPlayIntegrityVerdict(t *testing.T) {
+	config := &AttestationConfig{PlayPackageNames: []string{"com.example.app"}, PlayCertDigests: []string{"digest"}}
+	now := time.Now()
+
+	newVerdict := func() *playIntegrityVerdict {
+		verdict := &playIntegrityVerdict{}
+		verdict.RequestDetails.RequestPackageName = "com.example.app"
+		verdict.RequestDetails.Nonce = "challenge"
+		verdict.RequestDetails.TimestampMillis = strconv.FormatInt(now.UnixNano()/int64(time.Millisecond), 10)
+		verdict.AppIntegrity.AppRecognitionVerdict = "PLAY_RECOGNIZED"
+		verdict.AppIntegrity.PackageName = "com.example.app"
+		verdict.AppIntegrity.CertificateSha256Digest = []string{"digest"}
+		return verdict
+	}
+
+	packageName, err := checkPlayIntegrityVerdict(config, newVerdict(), "challenge", now)
+	assert.Nil(t, err)
+	assert.Equal(t, "com.example.app", packageName)
+
+	verdict := newVerdict()
+	verdict.AppIntegrity.AppRecognitionVerdict = "UNRECOGNIZED_VERSION"
+	_, err = checkPlayIntegrityVerdict(config, verdict, "challenge", now)
+	assert.NotNil(t, err, "a sideloaded app must be rejected")
+
+	verdict = newVerdict()
+	verdict.AppIntegrity.CertificateSha256Digest = []string{"other"}
+	_, err = checkPlayIntegrityVerdict(config, verdict, "challenge", now)
+	assert.NotNil(t, err, "a re-signed app must be rejected")
+
+	_, err = checkPlayIntegrityVerdict(config, newVerdict(), "other", now)
+	assert.NotNil(t, err)
+
+	_, err = checkPlayIntegrityVerdict(config, newVerdict(), "challenge", now.Add(10*time.Minute))
+	assert.NotNil(t, err)
+}
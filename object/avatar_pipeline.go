@@ -0,0 +1,461 @@
+// Copyright 2023 The Casdoor Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package object
+
+import (
+	"bytes"
+	"crypto/md5"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"hash/fnv"
+	"html"
+	"image"
+	"image/color"
+	_ "image/gif"
+	_ "image/jpeg"
+	"image/png"
+	"io"
+	"net"
+	"net/http"
+	"sort"
+	"strings"
+	"syscall"
+	"time"
+	"unicode"
+
+	"github.com/beego/beego/logs"
+	"github.com/casdoor/casdoor/proxy"
+	"github.com/casdoor/casdoor/util"
+	"github.com/xorm-io/core"
+)
+
+const (
+	maxAvatarFileSize = 5 * 1024 * 1024
+	// maxAvatarPixels keeps a small file of huge dimensions from being
+	// decoded, the image takes 4 bytes per pixel in memory
+	maxAvatarPixels = 4096 * 4096
+
+	AvatarSourceUpload   = "Upload"
+	AvatarSourceGravatar = "Gravatar"
+	AvatarSourceInitials = "Initials"
+)
+
+var (
+	defaultAvatarSizes = []int{64, 128, 256}
+	avatarColors       = []string{"#f56a00", "#7265e6", "#ffbf00", "#00a2ae", "#87d068", "#1677ff", "#eb2f96", "#722ed1", "#13c2c2", "#fa541c"}
+
+	// publicAvatarHttpClient downloads the avatars that users link to, it
+	// only connects to public IPs so that the links can't reach the internal
+	// network, redirects included
+	publicAvatarHttpClient = &http.Client{
+		Timeout: 10 * time.Second,
+		Transport: &http.Transport{
+			DialContext: (&net.Dialer{Timeout: 10 * time.Second, Control: checkPublicAvatarAddress}).DialContext,
+		},
+	}
+	nonPublicAvatarNetworks = getAvatarNetworks("0.0.0.0/8", "10.0.0.0/8", "100.64.0.0/10", "172.16.0.0/12", "192.168.0.0/16", "198.18.0.0/15", "fc00::/7")
+)
+
+func getAvatarNetworks(cidrs ...string) []*net.IPNet {
+	res := []*net.IPNet{}
+	for _, cidr := range cidrs {
+		_, network, err := net.ParseCIDR(cidr)
+		if err != nil {
+			panic(err)
+		}
+		res = append(res, network)
+	}
+	return res
+}
+
+func isPublicAvatarIp(ip net.IP) bool {
+	if ip.IsLoopback() || ip.IsUnspecified() || ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() || ip.IsInterfaceLocalMulticast() || ip.IsMulticast() {
+		return false
+	}
+	for _, network := range nonPublicAvatarNetworks {
+		if network.Contains(ip) {
+			return false
+		}
+	}
+	return true
+}
+
+func checkPublicAvatarAddress(network string, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	if ip := net.ParseIP(host); ip == nil || !isPublicAvatarIp(ip) {
+		return fmt.Errorf("the avatar host: %s isn't public", host)
+	}
+	return nil
+}
+
+// getAvatarHttpClient trusts the avatars stored by the default storage
+// provider, which may be in the internal network like Casdoor itself.
+func getAvatarHttpClient(avatarUrl string) *http.Client {
+	storageUrl, _ := GetUploadFileUrl(defaultStorageProvider, "/avatar/", false)
+	if strings.HasPrefix(avatarUrl, storageUrl) && !strings.Contains(avatarUrl, "..") {
+		return proxy.GetHttpClient(avatarUrl)
+	}
+	return publicAvatarHttpClient
+}
+
+// AvatarConfig of an organization turns on the avatar pipeline, which stores
+// the avatars of the users with the default storage provider in each of Sizes
+// and gives them cache-busted URLs.
+type AvatarConfig struct {
+	// EnableInitials generates an avatar with the initials of users without one
+	EnableInitials bool `json:"enableInitials"`
+	// EnableGravatar uses the Gravatar of the verified email first
+	EnableGravatar bool  `json:"enableGravatar"`
+	Sizes          []int `json:"sizes"`
+}
+
+func (config *AvatarConfig) isEnabled() bool {
+	return config != nil && defaultStorageProvider != nil
+}
+
+func (config *AvatarConfig) getSizes() []int {
+	sizes := []int{}
+	for _, size := range config.Sizes {
+		if size > 0 && size <= 1024 {
+			sizes = append(sizes, size)
+		}
+	}
+	if len(sizes) == 0 {
+		sizes = defaultAvatarSizes
+	}
+
+	sort.Ints(sizes)
+	return sizes
+}
+
+// getAvatarInitials returns up to two initials of the display name, or of
+// the name when there is no display name.
+func getAvatarInitials(displayName string, name string) string {
+	text := strings.TrimSpace(displayName)
+	if text == "" {
+		text = name
+	}
+
+	initials := []rune{}
+	for _, word := range strings.FieldsFunc(text, func(r rune) bool { return unicode.IsSpace(r) || r == '_' || r == '-' || r == '.' }) {
+		runes := []rune(word)
+		initials = append(initials, unicode.ToUpper(runes[0]))
+		// CJK names have no spaces, one character is enough
+		if len(initials) == 2 || unicode.Is(unicode.Han, runes[0]) {
+			break
+		}
+	}
+	if len(initials) == 0 {
+		return "?"
+	}
+	return string(initials)
+}
+
+func getAvatarColor(seed string) string {
+	hash := fnv.New32a()
+	_, _ = hash.Write([]byte(seed))
+	return avatarColors[hash.Sum32()%uint32(len(avatarColors))]
+}
+
+// getInitialsAvatar returns an SVG avatar, it scales and so has only one size.
+func getInitialsAvatar(user *User) []byte {
+	initials := getAvatarInitials(user.DisplayName, user.Name)
+	fontSize := 56
+	if len([]rune(initials)) > 1 {
+		fontSize = 44
+	}
+
+	svg := fmt.Sprintf(`<svg xmlns="http://www.w3.org/2000/svg" width="128" height="128" viewBox="0 0 128 128">`+
+		`<rect width="128" height="128" fill="%s"/>`+
+		`<text x="50%%" y="50%%" dy=".35em" fill="#ffffff" font-family="Helvetica, Arial, sans-serif" font-size="%d" text-anchor="middle">%s</text>`+
+		`</svg>`, getAvatarColor(user.Owner+"/"+user.Name), fontSize, html.EscapeString(initials))
+	return []byte(svg)
+}
+
+func getGravatarUrl(email string, size int) string {
+	hash := md5.Sum([]byte(strings.ToLower(strings.TrimSpace(email))))
+	return fmt.Sprintf("https://www.gravatar.com/avatar/%s?s=%d&d=404", hex.EncodeToString(hash[:]), size)
+}
+
+// downloadAvatar returns nil when there is no image at the URL, like for an
+// email without Gravatar.
+func downloadAvatar(client *http.Client, url string) ([]byte, error) {
+	resp, err := client.Get(url)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, nil
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to download the avatar: %s, status: %s", url, resp.Status)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxAvatarFileSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(data) > maxAvatarFileSize {
+		return nil, fmt.Errorf("the avatar: %s is larger than %d bytes", url, maxAvatarFileSize)
+	}
+	return data, nil
+}
+
+// resizeAvatar crops the center square of the image and scales it to the
+// size, each pixel is the average of the pixels it covers.
+func resizeAvatar(src image.Image, size int) *image.RGBA {
+	bounds := src.Bounds()
+	side := bounds.Dx()
+	if bounds.Dy() < side {
+		side = bounds.Dy()
+	}
+	x0 := bounds.Min.X + (bounds.Dx()-side)/2
+	y0 := bounds.Min.Y + (bounds.Dy()-side)/2
+
+	dst := image.NewRGBA(image.Rect(0, 0, size, size))
+	for y := 0; y < size; y++ {
+		sy0 := y0 + y*side/size
+		sy1 := y0 + (y+1)*side/size
+		if sy1 <= sy0 {
+			sy1 = sy0 + 1
+		}
+
+		for x := 0; x < size; x++ {
+			sx0 := x0 + x*side/size
+			sx1 := x0 + (x+1)*side/size
+			if sx1 <= sx0 {
+				sx1 = sx0 + 1
+			}
+
+			var r, g, b, a, n uint64
+			for sy := sy0; sy < sy1; sy++ {
+				for sx := sx0; sx < sx1; sx++ {
+					cr, cg, cb, ca := src.At(sx, sy).RGBA()
+					r, g, b, a = r+uint64(cr), g+uint64(cg), b+uint64(cb), a+uint64(ca)
+					n++
+				}
+			}
+			dst.Set(x, y, color.RGBA64{R: uint16(r / n), G: uint16(g / n), B: uint16(b / n), A: uint16(a / n)})
+		}
+	}
+	return dst
+}
+
+func getAvatarVersion(data []byte) string {
+	hash := sha256.Sum256(data)
+	return hex.EncodeToString(hash[:])[:12]
+}
+
+func uploadAvatarFile(fullFilePath string, data []byte) error {
+	_, _, err := UploadFileSafe(defaultStorageProvider, fullFilePath, bytes.NewBuffer(data), "en")
+	return err
+}
+
+// getAvatarSizeUrl returns the URL of the avatar in the size, the sizes are
+// stored next to the avatar as "name_size.png" with the same version.
+func getAvatarSizeUrl(permanentAvatar string, size int) string {
+	path, query := permanentAvatar, ""
+	if i := strings.Index(permanentAvatar, "?"); i != -1 {
+		path, query = permanentAvatar[:i], permanentAvatar[i:]
+	}
+	if !strings.HasSuffix(path, ".png") {
+		return permanentAvatar
+	}
+
+	return fmt.Sprintf("%s_%d.png%s", strings.TrimSuffix(path, ".png"), size, query)
+}
+
+// storeAvatar stores the image in its resolution and in all the sizes, and
+// returns its permanent URL.
+func storeAvatar(user *User, data []byte, sizes []int) (string, error) {
+	config, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return "", fmt.Errorf("the avatar of the user: %s isn't a PNG, JPEG or GIF image, %s", user.GetId(), err.Error())
+	}
+	if config.Width <= 0 || config.Height <= 0 || config.Width*config.Height > maxAvatarPixels {
+		return "", fmt.Errorf("the avatar of the user: %s has %dx%d pixels, more than %d", user.GetId(), config.Width, config.Height, maxAvatarPixels)
+	}
+
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return "", fmt.Errorf("the avatar of the user: %s isn't a PNG, JPEG or GIF image, %s", user.GetId(), err.Error())
+	}
+
+	basePath := fmt.Sprintf("/avatar/%s/%s", user.Owner, user.Name)
+	images := map[string]image.Image{basePath + ".png": img}
+	for _, size := range sizes {
+		images[fmt.Sprintf("%s_%d.png", basePath, size)] = resizeAvatar(img, size)
+	}
+
+	for fullFilePath, img := range images {
+		buffer := bytes.NewBuffer(nil)
+		err = png.Encode(buffer, img)
+		if err != nil {
+			return "", err
+		}
+
+		err = uploadAvatarFile(fullFilePath, buffer.Bytes())
+		if err != nil {
+			return "", err
+		}
+	}
+
+	fileUrl, _ := GetUploadFileUrl(defaultStorageProvider, basePath+".png", false)
+	return fmt.Sprintf("%s?v=%s", fileUrl, getAvatarVersion(data)), nil
+}
+
+// getAvatarSource returns the image of the avatar of the user: the uploaded
+// one, the Gravatar of the verified email, or the initials, in this order.
+func getAvatarSource(user *User, organization *Organization) ([]byte, string, error) {
+	config := organization.AvatarSettings
+	// a generated avatar is no upload, it is generated again
+	isGenerated := user.IsDefaultAvatar && user.Avatar == user.PermanentAvatar
+	if user.Avatar != "" && user.Avatar != organization.DefaultAvatar && !isGenerated {
+		data, err := downloadAvatar(getAvatarHttpClient(user.Avatar), user.Avatar)
+		if err != nil || data == nil {
+			return nil, "", fmt.Errorf("failed to download the avatar of the user: %s, %v", user.GetId(), err)
+		}
+		return data, AvatarSourceUpload, nil
+	}
+
+	if config.EnableGravatar && user.Email != "" && user.EmailVerified {
+		sizes := config.getSizes()
+		gravatarUrl := getGravatarUrl(user.Email, sizes[len(sizes)-1])
+		data, err := downloadAvatar(proxy.GetHttpClient(gravatarUrl), gravatarUrl)
+		if err != nil {
+			return nil, "", err
+		}
+		if data != nil {
+			return data, AvatarSourceGravatar, nil
+		}
+	}
+
+	if config.EnableInitials {
+		return getInitialsAvatar(user), AvatarSourceInitials, nil
+	}
+	return nil, "", nil
+}
+
+// ProcessUserAvatar runs the avatar pipeline of the organization of the user
+// and saves the permanent avatar. A generated avatar also becomes the avatar
+// of the user, marked as default so that an upload replaces it.
+func ProcessUserAvatar(user *User) (bool, error) {
+	organization := getOrganization("admin", user.Owner)
+	if organization == nil || !organization.AvatarSettings.isEnabled() {
+		return false, nil
+	}
+
+	data, source, err := getAvatarSource(user, organization)
+	if err != nil || data == nil {
+		return false, err
+	}
+
+	var permanentAvatar string
+	if source == AvatarSourceInitials {
+		fullFilePath := fmt.Sprintf("/avatar/%s/%s.svg", user.Owner, user.Name)
+		err = uploadAvatarFile(fullFilePath, data)
+		if err == nil {
+			fileUrl, _ := GetUploadFileUrl(defaultStorageProvider, fullFilePath, false)
+			permanentAvatar = fmt.Sprintf("%s?v=%s", fileUrl, getAvatarVersion(data))
+		}
+	} else {
+		permanentAvatar, err = storeAvatar(user, data, organization.AvatarSettings.getSizes())
+	}
+	if err != nil {
+		return false, err
+	}
+
+	// the avatar may have been changed while it was processed
+	current := getUser(user.Owner, user.Name)
+	if current == nil || current.Avatar != user.Avatar {
+		return false, nil
+	}
+
+	user.PermanentAvatar = permanentAvatar
+	user.IsDefaultAvatar = source != AvatarSourceUpload
+	if user.IsDefaultAvatar {
+		user.Avatar = permanentAvatar
+	}
+	affected, err := getUserEngine(user.Owner).ID(core.PK{user.Owner, user.Name}).Cols("avatar", "permanent_avatar", "is_default_avatar").Update(user)
+	if err != nil {
+		panic(err)
+	}
+
+	return affected != 0, nil
+}
+
+// processUserAvatarAsync runs the pipeline on a copy of the user, as it
+// downloads and uploads files.
+func processUserAvatarAsync(user *User) {
+	userCopy := *user
+	util.SafeGoroutine(func() {
+		_, err := ProcessUserAvatar(&userCopy)
+		if err != nil {
+			logs.Warning(fmt.Sprintf("failed to process the avatar of the user: %s, %s", userCopy.GetId(), err.Error()))
+		}
+	})
+}
+
+// processUsersAvatarsAsync runs the pipeline for the users of a bulk insert
+// one after another, instead of downloading all their avatars at once.
+func processUsersAvatarsAsync(users []*User) {
+	userCopies := []*User{}
+	for _, user := range users {
+		organization := getOrganization("admin", user.Owner)
+		if organization != nil && organization.AvatarSettings.isEnabled() {
+			userCopy := *user
+			userCopies = append(userCopies, &userCopy)
+		}
+	}
+	if len(userCopies) == 0 {
+		return
+	}
+
+	util.SafeGoroutine(func() {
+		for _, user := range userCopies {
+			_, err := ProcessUserAvatar(user)
+			if err != nil {
+				logs.Warning(fmt.Sprintf("failed to process the avatar of the user: %s, %s", user.GetId(), err.Error()))
+			}
+		}
+	})
+}
+
+// GetUserAvatarUrl returns the URL of the avatar of the user in the smallest
+// stored size that isn't smaller than size, the largest one otherwise.
+func GetUserAvatarUrl(user *User, size int) string {
+	if user.PermanentAvatar == "" || user.PermanentAvatar == "*" {
+		return user.Avatar
+	}
+
+	organization := getOrganization("admin", user.Owner)
+	if organization == nil || organization.AvatarSettings == nil || size <= 0 {
+		return user.PermanentAvatar
+	}
+
+	sizes := organization.AvatarSettings.getSizes()
+	for _, stored := range sizes {
+		if stored >= size {
+			return getAvatarSizeUrl(user.PermanentAvatar, stored)
+		}
+	}
+	return getAvatarSizeUrl(user.PermanentAvatar, sizes[len(sizes)-1])
+}
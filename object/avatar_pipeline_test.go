@@ -0,0 +1,144 @@
+// Copyright 2023 The Casdoor Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package object
+
+import (
+	"image"
+	"image/color"
+	"net"
+	"strings"
+	"testing"
+)
+
+func TestGetAvatarInitials(t *testing.T) {
+	cases := []struct {
+		displayName string
+		name        string
+		expected    string
+	}{
+		{"Alice Smith", "alice", "AS"},
+		{"  bob  ", "bob", "B"},
+		{"", "jane_doe", "JD"},
+		{"Jean-Luc Picard Enterprise", "jl", "JL"},
+		{"张三", "zhangsan", "张"},
+		{"", "", "?"},
+	}
+
+	for _, c := range cases {
+		if res := getAvatarInitials(c.displayName, c.name); res != c.expected {
+			t.Errorf("getAvatarInitials(%q, %q) = %q, expected %q", c.displayName, c.name, res, c.expected)
+		}
+	}
+}
+
+func TestGetInitialsAvatar(t *testing.T) {
+	svg := string(getInitialsAvatar(&User{Owner: "built-in", Name: "x", DisplayName: "<b> &"}))
+	if !strings.HasPrefix(svg, "<svg") || !strings.Contains(svg, ">&lt;&amp;</text>") {
+		t.Fatalf("unexpected SVG: %s", svg)
+	}
+	if getAvatarColor("built-in/x") != getAvatarColor("built-in/x") {
+		t.Fatal("the color isn't stable")
+	}
+}
+
+func TestGetGravatarUrl(t *testing.T) {
+	expected := "https://www.gravatar.com/avatar/0bc83cb571cd1c50ba6f3e8a78ef1346?s=128&d=404"
+	if res := getGravatarUrl(" MyEmailAddress@example.com ", 128); res != expected {
+		t.Fatalf("got %s, expected %s", res, expected)
+	}
+}
+
+func TestGetAvatarSizeUrl(t *testing.T) {
+	if res := getAvatarSizeUrl("https://cdn.example.com/avatar/built-in/admin.png?v=abc", 64); res != "https://cdn.example.com/avatar/built-in/admin_64.png?v=abc" {
+		t.Fatalf("unexpected URL: %s", res)
+	}
+	if res := getAvatarSizeUrl("https://cdn.example.com/avatar/built-in/admin.svg?v=abc", 64); res != "https://cdn.example.com/avatar/built-in/admin.svg?v=abc" {
+		t.Fatalf("unexpected URL: %s", res)
+	}
+
+	config := &AvatarConfig{Sizes: []int{256, 0, 32, 5000}}
+	sizes := config.getSizes()
+	if len(sizes) != 2 || sizes[0] != 32 || sizes[1] != 256 {
+		t.Fatalf("unexpected sizes: %v", sizes)
+	}
+}
+
+func TestResizeAvatar(t *testing.T) {
+	// a 40x20 image, the left half is black and the right half white
+	src := image.NewRGBA(image.Rect(0, 0, 40, 20))
+	for y := 0; y < 20; y++ {
+		for x := 0; x < 40; x++ {
+			if x >= 20 {
+				src.Set(x, y, color.White)
+			} else {
+				src.Set(x, y, color.Black)
+			}
+		}
+	}
+
+	// the center square covers half black and half white
+	dst := resizeAvatar(src, 2)
+	if dst.Bounds().Dx() != 2 || dst.Bounds().Dy() != 2 {
+		t.Fatalf("unexpected bounds: %v", dst.Bounds())
+	}
+	if r, _, _, _ := dst.At(0, 0).RGBA(); r != 0 {
+		t.Fatalf("the left pixel is %d", r)
+	}
+	if r, _, _, _ := dst.At(1, 1).RGBA(); r != 0xffff {
+		t.Fatalf("the right pixel is %d", r)
+	}
+
+	if dst = resizeAvatar(src, 64); dst.Bounds().Dx() != 64 {
+		t.Fatalf("unexpected bounds: %v", dst.Bounds())
+	}
+}
+
+func TestStoreAvatarPixelLimit(t *testing.T) {
+	// the header of a 65535x65535 GIF, only a few bytes in size
+	data := []byte("GIF89a\xff\xff\xff\xff\x00\x00\x00\x3b")
+	_, err := storeAvatar(&User{Owner: "built-in", Name: "alice"}, data, defaultAvatarSizes)
+	if err == nil || !strings.Contains(err.Error(), "pixels") {
+		t.Fatalf("the avatar isn't rejected for its size: %v", err)
+	}
+}
+
+func TestIsPublicAvatarIp(t *testing.T) {
+	scenarios := []struct {
+		ip       string
+		isPublic bool
+	}{
+		{"8.8.8.8", true},
+		{"2606:4700:4700::1111", true},
+		{"127.0.0.1", false},
+		{"10.1.2.3", false},
+		{"172.16.0.1", false},
+		{"192.168.1.1", false},
+		{"169.254.169.254", false},
+		{"100.64.0.1", false},
+		{"0.0.0.0", false},
+		{"::1", false},
+		{"fd00::1", false},
+		{"fe80::1", false},
+	}
+	for _, scenery := range scenarios {
+		if isPublicAvatarIp(net.ParseIP(scenery.ip)) != scenery.isPublic {
+			t.Fatalf("the IP: %s must be public: %v", scenery.ip, scenery.isPublic)
+		}
+	}
+
+	if err := checkPublicAvatarAddress("tcp", "169.254.169.254:80", nil); err == nil {
+		t.Fatalf("the metadata address is allowed")
+	}
+}
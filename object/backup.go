@@ -0,0 +1,441 @@
+// Copyright 2023 The Casdoor Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package object
+
+import (
+	"bytes"
+	"compress/gzip"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/gob"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/casdoor/casdoor/conf"
+	"github.com/casdoor/casdoor/util"
+	"golang.org/x/crypto/scrypt"
+)
+
+const (
+	backupVersion        = 1
+	backupMagic          = "CASDOOR-BACKUP-1\n"
+	backupSaltSize       = 16
+	backupInsertBatch    = 100
+	backupMigrationTable = "migration"
+)
+
+// Backup is the content of a backup archive: the rows of all tables with
+// the migrations of the schema they were read from.
+type Backup struct {
+	Version     int
+	CreatedTime string
+	Migrations  []string
+	Tables      []*BackupTable
+}
+
+type BackupTable struct {
+	Name    string
+	Columns []string
+	Rows    []map[string]interface{}
+}
+
+// BackupSummary tells what a backup holds or what a restore wrote.
+type BackupSummary struct {
+	CreatedTime string           `json:"createdTime"`
+	Migrations  []string         `json:"migrations"`
+	Rows        map[string]int64 `json:"rows"`
+}
+
+func init() {
+	for _, value := range []interface{}{"", []byte{}, int64(0), float64(0), false, time.Time{}} {
+		gob.Register(value)
+	}
+}
+
+func getBackupPassphrase() (string, error) {
+	passphrase := conf.GetConfigString("backupPassphrase")
+	if passphrase == "" {
+		return "", fmt.Errorf("backupPassphrase of app.conf is required to encrypt and decrypt backups")
+	}
+	return passphrase, nil
+}
+
+func getMigrations() ([]string, error) {
+	rows, err := adapter.Engine.QueryString(fmt.Sprintf("SELECT id FROM %s", adapter.Engine.Quote(backupMigrationTable)))
+	if err != nil {
+		return nil, err
+	}
+
+	migrations := []string{}
+	for _, row := range rows {
+		migrations = append(migrations, row["id"])
+	}
+	sort.Strings(migrations)
+	return migrations, nil
+}
+
+// normalizeBackupValue keeps the values gob can carry in an interface.
+func normalizeBackupValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case nil, string, []byte, int64, float64, bool, time.Time:
+		return v
+	case int:
+		return int64(v)
+	case int32:
+		return int64(v)
+	case float32:
+		return float64(v)
+	default:
+		return fmt.Sprintf("%v", v)
+	}
+}
+
+// CreateBackup reads all tables but the migrations in a single transaction,
+// so that the backup is consistent. The records (the audit log) can be left
+// out, they are usually the largest table. Only the main database is backed
+// up, the users in shards stay in their jurisdiction.
+func CreateBackup(excludeRecords bool) (*Backup, error) {
+	migrations, err := getMigrations()
+	if err != nil {
+		return nil, err
+	}
+
+	tables, err := adapter.Engine.DBMetas()
+	if err != nil {
+		return nil, err
+	}
+
+	session := adapter.Engine.NewSession()
+	defer session.Close()
+	err = session.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = session.Rollback()
+	}()
+
+	backup := &Backup{
+		Version:     backupVersion,
+		CreatedTime: util.GetCurrentTime(),
+		Migrations:  migrations,
+		Tables:      []*BackupTable{},
+	}
+	recordTable := adapter.Engine.TableName(&Record{})
+	tableNamePrefix := conf.GetConfigString("tableNamePrefix")
+	for _, table := range tables {
+		if table.Name == backupMigrationTable || !strings.HasPrefix(table.Name, tableNamePrefix) {
+			continue
+		}
+		if excludeRecords && table.Name == recordTable {
+			continue
+		}
+
+		rows, err := session.QueryInterface(fmt.Sprintf("SELECT * FROM %s", adapter.Engine.Quote(table.Name)))
+		if err != nil {
+			return nil, err
+		}
+		for _, row := range rows {
+			for key, value := range row {
+				row[key] = normalizeBackupValue(value)
+			}
+		}
+
+		backup.Tables = append(backup.Tables, &BackupTable{
+			Name:    table.Name,
+			Columns: table.ColumnsSeq(),
+			Rows:    rows,
+		})
+	}
+
+	return backup, nil
+}
+
+func (backup *Backup) GetSummary() *BackupSummary {
+	summary := &BackupSummary{
+		CreatedTime: backup.CreatedTime,
+		Migrations:  backup.Migrations,
+		Rows:        map[string]int64{},
+	}
+	for _, table := range backup.Tables {
+		summary.Rows[table.Name] = int64(len(table.Rows))
+	}
+	return summary
+}
+
+func getBackupKey(passphrase string, salt []byte) (cipher.AEAD, error) {
+	key, err := scrypt.Key([]byte(passphrase), salt, 32768, 8, 1, 32)
+	if err != nil {
+		return nil, err
+	}
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// EncryptBackup writes the backup gzipped and encrypted with AES-256-GCM,
+// the key is derived from the passphrase with scrypt.
+func EncryptBackup(backup *Backup, passphrase string) ([]byte, error) {
+	var plain bytes.Buffer
+	gzipWriter := gzip.NewWriter(&plain)
+	err := gob.NewEncoder(gzipWriter).Encode(backup)
+	if err != nil {
+		return nil, err
+	}
+	err = gzipWriter.Close()
+	if err != nil {
+		return nil, err
+	}
+
+	salt := make([]byte, backupSaltSize)
+	_, err = rand.Read(salt)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := getBackupKey(passphrase, salt)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, aead.NonceSize())
+	_, err = rand.Read(nonce)
+	if err != nil {
+		return nil, err
+	}
+
+	res := append([]byte(backupMagic), salt...)
+	res = append(res, nonce...)
+	return aead.Seal(res, nonce, plain.Bytes(), []byte(backupMagic)), nil
+}
+
+func DecryptBackup(data []byte, passphrase string) (*Backup, error) {
+	if !bytes.HasPrefix(data, []byte(backupMagic)) {
+		return nil, fmt.Errorf("the file is not a Casdoor backup")
+	}
+	data = data[len(backupMagic):]
+	if len(data) < backupSaltSize {
+		return nil, fmt.Errorf("the backup is truncated")
+	}
+
+	aead, err := getBackupKey(passphrase, data[:backupSaltSize])
+	if err != nil {
+		return nil, err
+	}
+	data = data[backupSaltSize:]
+	if len(data) < aead.NonceSize() {
+		return nil, fmt.Errorf("the backup is truncated")
+	}
+
+	plain, err := aead.Open(nil, data[:aead.NonceSize()], data[aead.NonceSize():], []byte(backupMagic))
+	if err != nil {
+		return nil, fmt.Errorf("the backup can't be decrypted, the passphrase may be wrong")
+	}
+
+	gzipReader, err := gzip.NewReader(bytes.NewReader(plain))
+	if err != nil {
+		return nil, err
+	}
+	backup := &Backup{}
+	err = gob.NewDecoder(gzipReader).Decode(backup)
+	if err != nil && err != io.EOF {
+		return nil, err
+	}
+	return backup, nil
+}
+
+// checkBackupCompatibility only accepts a backup of the same schema: the
+// same migrations have been applied and its columns exist here.
+func checkBackupCompatibility(backup *Backup) error {
+	if backup.Version != backupVersion {
+		return fmt.Errorf("the backup version: %d is not supported", backup.Version)
+	}
+
+	migrations, err := getMigrations()
+	if err != nil {
+		return err
+	}
+	if strings.Join(backup.Migrations, ",") != strings.Join(migrations, ",") {
+		return fmt.Errorf("the backup was made with the migrations: %v, this server has: %v", backup.Migrations, migrations)
+	}
+
+	tables, err := adapter.Engine.DBMetas()
+	if err != nil {
+		return err
+	}
+	columnMap := map[string][]string{}
+	for _, table := range tables {
+		columnMap[table.Name] = table.ColumnsSeq()
+	}
+	for _, table := range backup.Tables {
+		columns, ok := columnMap[table.Name]
+		if !ok {
+			return fmt.Errorf("the table: %s of the backup doesn't exist", table.Name)
+		}
+		for _, column := range table.Columns {
+			if !util.ContainsString(columns, column) {
+				return fmt.Errorf("the column: %s.%s of the backup doesn't exist", table.Name, column)
+			}
+		}
+	}
+
+	return nil
+}
+
+// RestoreBackup replaces the rows of the tables of the backup in a single
+// transaction, the tables left out of the backup are kept as they are.
+func RestoreBackup(backup *Backup) (*BackupSummary, error) {
+	err := checkBackupCompatibility(backup)
+	if err != nil {
+		return nil, err
+	}
+
+	session := adapter.Engine.NewSession()
+	defer session.Close()
+	err = session.Begin()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, table := range backup.Tables {
+		_, err = session.Exec(fmt.Sprintf("DELETE FROM %s", adapter.Engine.Quote(table.Name)))
+		if err != nil {
+			_ = session.Rollback()
+			return nil, err
+		}
+
+		for i := 0; i < len(table.Rows); i += backupInsertBatch {
+			end := i + backupInsertBatch
+			if end > len(table.Rows) {
+				end = len(table.Rows)
+			}
+
+			_, err = session.Table(table.Name).Insert(table.Rows[i:end])
+			if err != nil {
+				_ = session.Rollback()
+				return nil, fmt.Errorf("failed to restore the table: %s, %s", table.Name, err.Error())
+			}
+		}
+	}
+
+	err = session.Commit()
+	if err != nil {
+		return nil, err
+	}
+
+	invalidateEnforcers()
+	ReloadSettings()
+	err = FlushObjectCache()
+	if err != nil {
+		return nil, err
+	}
+	return backup.GetSummary(), nil
+}
+
+func CreateEncryptedBackup(excludeRecords bool) ([]byte, *BackupSummary, error) {
+	passphrase, err := getBackupPassphrase()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	backup, err := CreateBackup(excludeRecords)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	data, err := EncryptBackup(backup, passphrase)
+	if err != nil {
+		return nil, nil, err
+	}
+	return data, backup.GetSummary(), nil
+}
+
+func RestoreEncryptedBackup(data []byte) (*BackupSummary, error) {
+	passphrase, err := getBackupPassphrase()
+	if err != nil {
+		return nil, err
+	}
+
+	backup, err := DecryptBackup(data, passphrase)
+	if err != nil {
+		return nil, err
+	}
+	return RestoreBackup(backup)
+}
+
+func CreateBackupFile(path string, excludeRecords bool) (*BackupSummary, error) {
+	data, summary, err := CreateEncryptedBackup(excludeRecords)
+	if err != nil {
+		return nil, err
+	}
+
+	err = ioutil.WriteFile(path, data, 0o600)
+	if err != nil {
+		return nil, err
+	}
+	return summary, nil
+}
+
+func RestoreBackupFile(path string) (*BackupSummary, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	return RestoreEncryptedBackup(data)
+}
+
+// uploadBackup stores a backup with the storage provider of
+// backupStorageProvider, e.g. "provider_storage_s3".
+func uploadBackup() error {
+	providerName := conf.GetConfigString("backupStorageProvider")
+	provider := getProvider("admin", providerName)
+	if provider == nil || provider.Category != "Storage" {
+		return fmt.Errorf("the storage provider: %s for backups does not exist", providerName)
+	}
+
+	excludeRecords, _ := conf.GetConfigBool("backupExcludeRecords")
+	data, _, err := CreateEncryptedBackup(excludeRecords)
+	if err != nil {
+		return err
+	}
+
+	fullFilePath := fmt.Sprintf("backups/casdoor-%s.bak", time.Now().UTC().Format("20060102-150405"))
+	_, _, err = UploadFileSafe(provider, fullFilePath, bytes.NewBuffer(data), "en")
+	return err
+}
+
+// RunBackupJob uploads a backup every backupInterval hours, once per
+// interval cluster-wide. Nothing runs when backupInterval is 0.
+func RunBackupJob() {
+	hours, err := conf.GetConfigInt64("backupInterval")
+	if err != nil || hours <= 0 {
+		return
+	}
+
+	interval := time.Duration(hours) * time.Hour
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		<-ticker.C
+		RunClusterJob("backup", interval, uploadBackup)
+	}
+}
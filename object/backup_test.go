@@ -0,0 +1,51 @@
+// Copyright 2023 The Casdoor Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package object
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEncryptBackup(t *testing.T) {
+	backup := &Backup{
+		Version:     backupVersion,
+		CreatedTime: "2023-06-01T00:00:00Z",
+		Migrations:  []string{"20230209MigrateCasbinRule"},
+		Tables: []*BackupTable{
+			{
+				Name:    "user",
+				Columns: []string{"owner", "name", "score", "avatar"},
+				Rows: []map[string]interface{}{
+					{"owner": []byte("built-in"), "name": "admin", "score": int64(2000), "avatar": nil},
+				},
+			},
+		},
+	}
+
+	data, err := EncryptBackup(backup, "123")
+	assert.Nil(t, err)
+
+	res, err := DecryptBackup(data, "123")
+	assert.Nil(t, err)
+	assert.Equal(t, backup, res)
+
+	_, err = DecryptBackup(data, "456")
+	assert.NotNil(t, err)
+
+	_, err = DecryptBackup([]byte("not a backup"), "123")
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,488 @@
+// Copyright 2023 The Casdoor Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package object
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/casdoor/casdoor/i18n"
+	"github.com/casdoor/casdoor/util"
+	"github.com/xorm-io/core"
+	"github.com/xorm-io/xorm"
+)
+
+const (
+	BatchStatusOk      = "ok"
+	BatchStatusError   = "error"
+	BatchStatusSkipped = "skipped"
+)
+
+type BatchResult struct {
+	Id     string `json:"id"`
+	Status string `json:"status"`
+	Msg    string `json:"msg"`
+}
+
+func newBatchResults(ids []string) []*BatchResult {
+	results := []*BatchResult{}
+	for _, id := range ids {
+		results = append(results, &BatchResult{Id: id, Status: BatchStatusOk})
+	}
+	return results
+}
+
+func hasBatchError(results []*BatchResult) bool {
+	for _, result := range results {
+		if result.Status == BatchStatusError {
+			return true
+		}
+	}
+	return false
+}
+
+// runBatch applies fn to every item inside a single transaction. The batch is
+// all-or-nothing: the first failing item rolls back the transaction and the
+// remaining items are reported as skipped.
+func runBatch(results []*BatchResult, fn func(session *xorm.Session, i int) (bool, error)) bool {
+	return runEngineBatch(adapter.Engine, results, fn)
+}
+
+func runEngineBatch(engine *xorm.Engine, results []*BatchResult, fn func(session *xorm.Session, i int) (bool, error)) bool {
+	if hasBatchError(results) {
+		for _, result := range results {
+			if result.Status == BatchStatusOk {
+				result.Status = BatchStatusSkipped
+			}
+		}
+		return false
+	}
+
+	session := engine.NewSession()
+	defer session.Close()
+
+	err := session.Begin()
+	if err != nil {
+		panic(err)
+	}
+
+	for i, result := range results {
+		affected, err := fn(session, i)
+		if err == nil && !affected {
+			err = fmt.Errorf("unaffected")
+		}
+
+		if err != nil {
+			result.Status = BatchStatusError
+			result.Msg = err.Error()
+			for _, rest := range results[i+1:] {
+				rest.Status = BatchStatusSkipped
+			}
+
+			err = session.Rollback()
+			if err != nil {
+				panic(err)
+			}
+			return false
+		}
+	}
+
+	err = session.Commit()
+	if err != nil {
+		panic(err)
+	}
+
+	return true
+}
+
+func setBatchError(result *BatchResult, msg string) {
+	result.Status = BatchStatusError
+	result.Msg = msg
+}
+
+func BatchAddUsers(users []*User, lang string) ([]*BatchResult, bool) {
+	ids := []string{}
+	for _, user := range users {
+		ids = append(ids, user.GetId())
+	}
+	results := newBatchResults(ids)
+
+	rankings := map[string]int{}
+	for i, user := range users {
+		if msg := CheckUsername(user.Name, lang); msg != "" {
+			setBatchError(results[i], msg)
+			continue
+		}
+		if msg := CheckAdminEmailDomain(user, lang); msg != "" {
+			setBatchError(results[i], msg)
+			continue
+		}
+
+		organization := GetOrganizationByUser(user)
+		if organization == nil {
+			setBatchError(results[i], i18n.Translate(lang, "check:Organization does not exist"))
+			continue
+		}
+
+		if getUser(user.Owner, user.Name) != nil {
+			setBatchError(results[i], i18n.Translate(lang, "check:Username already exists"))
+			continue
+		}
+
+		if user.Id == "" {
+			user.Id = util.GenerateId()
+		}
+
+		user.UpdateUserPassword(organization)
+		user.UpdateUserHash()
+		user.PreHash = user.Hash
+		user.PermanentAvatar = getPermanentAvatarUrl(user.Owner, user.Name, user.Avatar, false)
+
+		if _, ok := rankings[user.Owner]; !ok {
+			rankings[user.Owner] = GetUserCount(user.Owner, "", "")
+		}
+		rankings[user.Owner]++
+		user.Ranking = rankings[user.Owner]
+	}
+
+	ok := runEngineBatch(getUsersEngine(users, results), results, func(session *xorm.Session, i int) (bool, error) {
+		affected, err := session.Insert(users[i])
+		return affected != 0, err
+	})
+	if ok {
+		processUsersAvatarsAsync(users)
+	}
+	return results, ok
+}
+
+// BatchUpdateUsers only changes the email, phone and admin flags of the users
+// when the caller is a global admin, like UpdateUser.
+func BatchUpdateUsers(users []*User, isGlobalAdmin bool, lang string) ([]*BatchResult, bool) {
+	ids := []string{}
+	for _, user := range users {
+		ids = append(ids, user.GetId())
+	}
+	results := newBatchResults(ids)
+
+	avatarUsers := []*User{}
+	for i, user := range users {
+		oldUser := getUser(user.Owner, user.Name)
+		if oldUser == nil {
+			setBatchError(results[i], fmt.Sprintf(i18n.Translate(lang, "general:The user: %s doesn't exist"), user.GetId()))
+			continue
+		}
+
+		if msg := CheckUpdateUser(oldUser, user, lang); msg != "" {
+			setBatchError(results[i], msg)
+			continue
+		}
+
+		if user.Password == "***" {
+			user.Password = oldUser.Password
+		}
+		user.UpdateUserHash()
+
+		if user.Avatar != oldUser.Avatar && user.Avatar != "" && user.PermanentAvatar != "*" {
+			user.PermanentAvatar = getPermanentAvatarUrl(user.Owner, user.Name, user.Avatar, false)
+		}
+		if user.Avatar != oldUser.Avatar {
+			avatarUsers = append(avatarUsers, user)
+		}
+	}
+
+	columns := []string{
+		"display_name", "avatar",
+		"location", "address", "country_code", "region", "language", "affiliation", "title", "homepage", "bio", "score", "tag", "signup_application",
+		"is_forbidden", "is_deleted", "hash", "is_default_avatar", "properties",
+	}
+	if isGlobalAdmin {
+		columns = append(columns, "email", "phone", "is_admin", "is_global_admin")
+	}
+	ok := runEngineBatch(getUsersEngine(users, results), results, func(session *xorm.Session, i int) (bool, error) {
+		affected, err := session.ID(core.PK{users[i].Owner, users[i].Name}).Cols(columns...).Update(users[i])
+		return affected != 0, err
+	})
+	if ok {
+		processUsersAvatarsAsync(avatarUsers)
+	}
+	return results, ok
+}
+
+func BatchDeleteUsers(users []*User) ([]*BatchResult, bool) {
+	ids := []string{}
+	for _, user := range users {
+		ids = append(ids, user.GetId())
+	}
+	results := newBatchResults(ids)
+
+	ok := runEngineBatch(getUsersEngine(users, results), results, func(session *xorm.Session, i int) (bool, error) {
+		affected, err := session.ID(core.PK{users[i].Owner, users[i].Name}).Delete(&User{})
+		return affected != 0, err
+	})
+
+	if ok {
+		for _, user := range users {
+			DeleteSession(util.GetSessionId(user.Owner, user.Name, CasdoorApplication))
+		}
+	}
+	return results, ok
+}
+
+func getUniquePermissions(permissions []*Permission) []*Permission {
+	res := []*Permission{}
+	m := map[string]bool{}
+	for _, permission := range permissions {
+		if m[permission.GetId()] {
+			continue
+		}
+
+		m[permission.GetId()] = true
+		res = append(res, permission)
+	}
+	return res
+}
+
+// detachPermissionPolicies removes the Casbin policies of the given permissions
+// before their rows are changed, because the grouping policies are derived from
+// the current state of the roles in the database.
+func detachPermissionPolicies(permissions []*Permission) []string {
+	ids := []string{}
+	for _, permission := range getUniquePermissions(permissions) {
+		removeGroupingPolicies(permission)
+		removePolicies(permission)
+		ids = append(ids, permission.GetId())
+	}
+	return ids
+}
+
+// attachPermissionPolicies regenerates the Casbin policies of the permissions
+// from whatever state was left in the database, so it heals the policies both
+// after a commit and after a rollback.
+func attachPermissionPolicies(ids []string) {
+	for _, id := range ids {
+		permission := GetPermission(id)
+		if permission == nil {
+			continue
+		}
+
+		addGroupingPolicies(permission)
+		addPolicies(permission)
+	}
+}
+
+// checkBatchRole runs the validation of AddRole and UpdateRole on a role of
+// the batch.
+func checkBatchRole(role *Role, lang string) string {
+	msg := CheckRoleInheritance(role.GetId(), role, lang)
+	if msg == "" {
+		msg = CheckRoleAssignments(role, lang)
+	}
+	if msg == "" {
+		msg = CheckRoleCapabilities(role, lang)
+	}
+	return msg
+}
+
+func BatchAddRoles(roles []*Role, lang string) ([]*BatchResult, bool) {
+	ids := []string{}
+	for _, role := range roles {
+		ids = append(ids, role.GetId())
+	}
+	results := newBatchResults(ids)
+
+	now := time.Now()
+	for i, role := range roles {
+		if getRole(role.Owner, role.Name) != nil {
+			setBatchError(results[i], fmt.Sprintf("the role: %s already exists", role.GetId()))
+			continue
+		}
+		if msg := checkBatchRole(role, lang); msg != "" {
+			setBatchError(results[i], msg)
+			continue
+		}
+
+		role.applyAssignments(now)
+	}
+
+	ok := runBatch(results, func(session *xorm.Session, i int) (bool, error) {
+		affected, err := session.Insert(roles[i])
+		return affected != 0, err
+	})
+	return results, ok
+}
+
+// BatchUpdateRoles applies the assignments and refreshes the policies of the
+// permissions of the roles and their ancestors, like UpdateRole.
+func BatchUpdateRoles(roles []*Role, lang string) ([]*BatchResult, bool) {
+	ids := []string{}
+	for _, role := range roles {
+		ids = append(ids, role.GetId())
+	}
+	results := newBatchResults(ids)
+
+	now := time.Now()
+	permissions := []*Permission{}
+	expiredUsers := map[int][]string{}
+	for i, role := range roles {
+		oldRole := getRole(role.Owner, role.Name)
+		if oldRole == nil {
+			setBatchError(results[i], fmt.Sprintf("the role: %s does not exist", role.GetId()))
+			continue
+		}
+		if msg := checkBatchRole(role, lang); msg != "" {
+			setBatchError(results[i], msg)
+			continue
+		}
+
+		_, expiredUsers[i] = role.applyAssignments(now)
+		permissions = append(permissions, getPermissionsByRoleAndAncestors(oldRole)...)
+	}
+
+	var permissionIds []string
+	if !hasBatchError(results) {
+		permissionIds = detachPermissionPolicies(permissions)
+	}
+
+	ok := runBatch(results, func(session *xorm.Session, i int) (bool, error) {
+		affected, err := session.ID(core.PK{roles[i].Owner, roles[i].Name}).AllCols().Update(roles[i])
+		return affected != 0, err
+	})
+
+	attachPermissionPolicies(permissionIds)
+	if ok {
+		for i, role := range roles {
+			for _, userId := range expiredUsers[i] {
+				addRoleAssignmentExpiredRecord(role, userId)
+			}
+		}
+	}
+	return results, ok
+}
+
+func BatchDeleteRoles(roles []*Role) ([]*BatchResult, bool) {
+	ids := []string{}
+	for _, role := range roles {
+		ids = append(ids, role.GetId())
+	}
+	results := newBatchResults(ids)
+
+	permissions := []*Permission{}
+	for _, role := range roles {
+		permissions = append(permissions, GetPermissionsByRole(role.GetId())...)
+	}
+	permissions = getUniquePermissions(permissions)
+	permissionIds := detachPermissionPolicies(permissions)
+
+	ok := runBatch(results, func(session *xorm.Session, i int) (bool, error) {
+		roleId := roles[i].GetId()
+		for _, permission := range permissions {
+			if !util.ContainsString(permission.Roles, roleId) {
+				continue
+			}
+
+			permission.Roles = util.DeleteVal(permission.Roles, roleId)
+			_, err := session.ID(core.PK{permission.Owner, permission.Name}).Cols("roles").Update(permission)
+			if err != nil {
+				return false, err
+			}
+		}
+
+		affected, err := session.ID(core.PK{roles[i].Owner, roles[i].Name}).Delete(&Role{})
+		return affected != 0, err
+	})
+
+	attachPermissionPolicies(permissionIds)
+	return results, ok
+}
+
+func BatchAddPermissions(permissions []*Permission) ([]*BatchResult, bool) {
+	ids := []string{}
+	for _, permission := range permissions {
+		ids = append(ids, permission.GetId())
+	}
+	results := newBatchResults(ids)
+
+	for i, permission := range permissions {
+		if getPermission(permission.Owner, permission.Name) != nil {
+			setBatchError(results[i], fmt.Sprintf("the permission: %s already exists", permission.GetId()))
+		}
+	}
+
+	ok := runBatch(results, func(session *xorm.Session, i int) (bool, error) {
+		affected, err := session.Insert(permissions[i])
+		return affected != 0, err
+	})
+
+	if ok {
+		attachPermissionPolicies(ids)
+	}
+	return results, ok
+}
+
+func BatchUpdatePermissions(permissions []*Permission) ([]*BatchResult, bool) {
+	ids := []string{}
+	for _, permission := range permissions {
+		ids = append(ids, permission.GetId())
+	}
+	results := newBatchResults(ids)
+
+	oldPermissions := []*Permission{}
+	for i, permission := range permissions {
+		oldPermission := getPermission(permission.Owner, permission.Name)
+		if oldPermission == nil {
+			setBatchError(results[i], fmt.Sprintf("the permission: %s does not exist", permission.GetId()))
+			continue
+		}
+		oldPermissions = append(oldPermissions, oldPermission)
+	}
+
+	var permissionIds []string
+	if !hasBatchError(results) {
+		permissionIds = detachPermissionPolicies(oldPermissions)
+	}
+
+	ok := runBatch(results, func(session *xorm.Session, i int) (bool, error) {
+		affected, err := session.ID(core.PK{permissions[i].Owner, permissions[i].Name}).AllCols().Update(permissions[i])
+		return affected != 0, err
+	})
+
+	attachPermissionPolicies(permissionIds)
+	return results, ok
+}
+
+func BatchDeletePermissions(permissions []*Permission) ([]*BatchResult, bool) {
+	ids := []string{}
+	for _, permission := range permissions {
+		ids = append(ids, permission.GetId())
+	}
+	results := newBatchResults(ids)
+
+	oldPermissions := []*Permission{}
+	for _, permission := range permissions {
+		oldPermission := getPermission(permission.Owner, permission.Name)
+		if oldPermission != nil {
+			oldPermissions = append(oldPermissions, oldPermission)
+		}
+	}
+	permissionIds := detachPermissionPolicies(oldPermissions)
+
+	ok := runBatch(results, func(session *xorm.Session, i int) (bool, error) {
+		affected, err := session.ID(core.PK{permissions[i].Owner, permissions[i].Name}).Delete(&Permission{})
+		return affected != 0, err
+	})
+
+	attachPermissionPolicies(permissionIds)
+	return results, ok
+}
@@ -0,0 +1,89 @@
+// Copyright 2023 The Casdoor Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package object
+
+import (
+	"fmt"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/xorm-io/xorm"
+)
+
+func TestRunEngineBatch(t *testing.T) {
+	engine, err := xorm.NewEngine("sqlite", "file:"+filepath.Join(t.TempDir(), "batch.db"))
+	assert.Nil(t, err)
+	defer engine.Close()
+	assert.Nil(t, engine.Sync2(new(Role)))
+
+	insertRoles := func(roles []*Role, results []*BatchResult) bool {
+		return runEngineBatch(engine, results, func(session *xorm.Session, i int) (bool, error) {
+			affected, err := session.Insert(roles[i])
+			return affected != 0, err
+		})
+	}
+	countRoles := func() int64 {
+		count, err := engine.Count(&Role{})
+		assert.Nil(t, err)
+		return count
+	}
+	getStatuses := func(results []*BatchResult) []string {
+		res := []string{}
+		for _, result := range results {
+			res = append(res, result.Status)
+		}
+		return res
+	}
+
+	t.Run("a failing item rolls back the others", func(t *testing.T) {
+		roles := []*Role{{Owner: "org", Name: "a"}, {Owner: "org", Name: "b"}, {Owner: "org", Name: "a"}, {Owner: "org", Name: "c"}}
+		results := newBatchResults([]string{"org/a", "org/b", "org/a", "org/c"})
+		assert.False(t, insertRoles(roles, results))
+		assert.Equal(t, []string{BatchStatusOk, BatchStatusOk, BatchStatusError, BatchStatusSkipped}, getStatuses(results))
+		assert.Equal(t, int64(0), countRoles())
+	})
+
+	t.Run("a failed check runs nothing", func(t *testing.T) {
+		roles := []*Role{{Owner: "org", Name: "a"}, {Owner: "org", Name: "b"}}
+		results := newBatchResults([]string{"org/a", "org/b"})
+		setBatchError(results[1], "invalid")
+		assert.False(t, insertRoles(roles, results))
+		assert.Equal(t, []string{BatchStatusSkipped, BatchStatusError}, getStatuses(results))
+		assert.Equal(t, int64(0), countRoles())
+	})
+
+	t.Run("an unaffected item rolls back the others", func(t *testing.T) {
+		results := newBatchResults([]string{"org/a", "org/b"})
+		ok := runEngineBatch(engine, results, func(session *xorm.Session, i int) (bool, error) {
+			if i == 1 {
+				return false, nil
+			}
+			affected, err := session.Insert(&Role{Owner: "org", Name: fmt.Sprintf("role%d", i)})
+			return affected != 0, err
+		})
+		assert.False(t, ok)
+		assert.Equal(t, "unaffected", results[1].Msg)
+		assert.Equal(t, int64(0), countRoles())
+	})
+
+	t.Run("a valid batch is committed", func(t *testing.T) {
+		roles := []*Role{{Owner: "org", Name: "a"}, {Owner: "org", Name: "b"}}
+		results := newBatchResults([]string{"org/a", "org/b"})
+		assert.True(t, insertRoles(roles, results))
+		assert.Equal(t, []string{BatchStatusOk, BatchStatusOk}, getStatuses(results))
+		assert.Equal(t, int64(2), countRoles())
+	})
+}
@@ -0,0 +1,135 @@
+// Copyright 2023 The Casdoor Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package object
+
+type BrandingLink struct {
+	Title string `json:"title"`
+	Url   string `json:"url"`
+}
+
+// Branding white-labels the login pages. Empty fields of an application fall
+// back to its organization. Strings overrides the texts of the login pages by
+// language and i18n key, e.g. {"en": {"login:Sign In": "Continue"}}.
+type Branding struct {
+	Logo            string                       `json:"logo"`
+	Favicon         string                       `json:"favicon"`
+	ColorPrimary    string                       `json:"colorPrimary"`
+	BackgroundColor string                       `json:"backgroundColor"`
+	CustomCss       string                       `json:"customCss"`
+	FooterLinks     []*BrandingLink              `json:"footerLinks"`
+	Strings         map[string]map[string]string `json:"strings"`
+}
+
+// mergeBranding returns a copy of branding with the non-empty fields of
+// override on top, the strings are merged by key.
+func mergeBranding(branding *Branding, override *Branding) *Branding {
+	res := *branding
+	res.Strings = map[string]map[string]string{}
+	for language, strings := range branding.Strings {
+		res.Strings[language] = map[string]string{}
+		for key, value := range strings {
+			res.Strings[language][key] = value
+		}
+	}
+
+	if override == nil {
+		return &res
+	}
+
+	if override.Logo != "" {
+		res.Logo = override.Logo
+	}
+	if override.Favicon != "" {
+		res.Favicon = override.Favicon
+	}
+	if override.ColorPrimary != "" {
+		res.ColorPrimary = override.ColorPrimary
+	}
+	if override.BackgroundColor != "" {
+		res.BackgroundColor = override.BackgroundColor
+	}
+	if override.CustomCss != "" {
+		res.CustomCss = override.CustomCss
+	}
+	if len(override.FooterLinks) != 0 {
+		res.FooterLinks = override.FooterLinks
+	}
+	for language, strings := range override.Strings {
+		if res.Strings[language] == nil {
+			res.Strings[language] = map[string]string{}
+		}
+		for key, value := range strings {
+			res.Strings[language][key] = value
+		}
+	}
+	return &res
+}
+
+// localize keeps only the strings of the language, falling back to
+// English for the keys it doesn't have.
+func (branding *Branding) localize(language string) {
+	strings := map[string]string{}
+	for key, value := range branding.Strings["en"] {
+		strings[key] = value
+	}
+	for key, value := range branding.Strings[language] {
+		strings[key] = value
+	}
+	branding.Strings = map[string]map[string]string{language: strings}
+}
+
+func getOrganizationBranding(organization *Organization) *Branding {
+	branding := &Branding{Favicon: organization.Favicon, FooterLinks: []*BrandingLink{}}
+	if organization.ThemeData != nil && organization.ThemeData.IsEnabled {
+		branding.ColorPrimary = organization.ThemeData.ColorPrimary
+	}
+	return mergeBranding(branding, organization.Branding)
+}
+
+// GetOrganizationBranding returns the branding of the organization for the
+// language, with the favicon and the theme color of the organization as the
+// defaults.
+func GetOrganizationBranding(id string, language string) *Branding {
+	organization := GetOrganization(id)
+	if organization == nil {
+		return nil
+	}
+
+	branding := getOrganizationBranding(getInheritedOrganization(organization))
+	branding.localize(language)
+	return branding
+}
+
+// GetApplicationBranding returns the branding of the application on top of
+// the one of its organization for the language.
+func GetApplicationBranding(id string, language string) *Branding {
+	application := GetApplication(id)
+	if application == nil {
+		return nil
+	}
+
+	branding := &Branding{FooterLinks: []*BrandingLink{}}
+	if organization := getOrganization("admin", application.Organization); organization != nil {
+		branding = getOrganizationBranding(getInheritedOrganization(organization))
+	}
+
+	defaults := &Branding{Logo: application.Logo}
+	if application.ThemeData != nil && application.ThemeData.IsEnabled {
+		defaults.ColorPrimary = application.ThemeData.ColorPrimary
+	}
+	branding = mergeBranding(mergeBranding(branding, defaults), application.Branding)
+	branding.localize(language)
+	return branding
+}
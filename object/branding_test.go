@@ -0,0 +1,74 @@
+// Copyright 2023 The Casdoor Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package object
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMergeBranding(t *testing.T) {
+	organizationBranding := &Branding{
+		Logo:         "https://acme.com/logo.png",
+		ColorPrimary: "#123456",
+		FooterLinks:  []*BrandingLink{{Title: "Privacy", Url: "https://acme.com/privacy"}},
+		Strings: map[string]map[string]string{
+			"en": {"login:Sign In": "Continue", "login:Password": "Passphrase"},
+			"fr": {"login:Sign In": "Continuer"},
+		},
+	}
+
+	scenarios := []struct {
+		description string
+		override    *Branding
+		language    string
+		expected    *Branding
+	}{
+		{
+			"Should keep the organization branding without override", nil, "en",
+			&Branding{
+				Logo:         "https://acme.com/logo.png",
+				ColorPrimary: "#123456",
+				FooterLinks:  []*BrandingLink{{Title: "Privacy", Url: "https://acme.com/privacy"}},
+				Strings:      map[string]map[string]string{"en": {"login:Sign In": "Continue", "login:Password": "Passphrase"}},
+			},
+		},
+		{
+			"Should override non-empty fields and fall back to English strings",
+			&Branding{
+				Logo:    "https://app.acme.com/logo.png",
+				Strings: map[string]map[string]string{"fr": {"login:Password": "Mot de passe"}},
+			},
+			"fr",
+			&Branding{
+				Logo:         "https://app.acme.com/logo.png",
+				ColorPrimary: "#123456",
+				FooterLinks:  []*BrandingLink{{Title: "Privacy", Url: "https://acme.com/privacy"}},
+				Strings:      map[string]map[string]string{"fr": {"login:Sign In": "Continuer", "login:Password": "Mot de passe"}},
+			},
+		},
+	}
+
+	for _, scenery := range scenarios {
+		t.Run(scenery.description, func(t *testing.T) {
+			branding := mergeBranding(organizationBranding, scenery.override)
+			branding.localize(scenery.language)
+			assert.Equal(t, scenery.expected, branding)
+		})
+	}
+
+	assert.Len(t, organizationBranding.Strings["fr"], 1, "the merge must not change the organization branding")
+}
@@ -0,0 +1,341 @@
+// Copyright 2023 The Casdoor Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package object
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/casdoor/casdoor/i18n"
+	"github.com/casdoor/casdoor/util"
+)
+
+// endpointCapabilities maps the management endpoints to the capability
+// needed to call them. Users get capabilities from the roles they are in:
+// the roles of an organization grant them on the objects of the
+// organization, the roles of "built-in" on all objects.
+var endpointCapabilities = map[string]string{
+	"/api/get-organizations":             "organization:read",
+	"/api/get-organization":              "organization:read",
+	"/api/update-organization":           "organization:write",
+	"/api/upsert-organization":           "organization:write",
+	"/api/add-organization":              "organization:write",
+	"/api/delete-organization":           "organization:write",
+	"/api/get-global-users":              "user:read",
+	"/api/get-users":                     "user:read",
+	"/api/get-sorted-users":              "user:read",
+	"/api/get-user-count":                "user:read",
+	"/api/export-users":                  "user:read",
+	"/api/get-user":                      "user:read",
+	"/api/update-user":                   "user:write",
+	"/api/add-user":                      "user:write",
+	"/api/delete-user":                   "user:write",
+	"/api/upload-users":                  "user:write",
+	"/api/batch-add-users":               "user:write",
+	"/api/batch-update-users":            "user:write",
+	"/api/batch-delete-users":            "user:write",
+	"/api/get-roles":                     "role:read",
+	"/api/get-role":                      "role:read",
+	"/api/update-role":                   "role:write",
+	"/api/add-role":                      "role:write",
+	"/api/delete-role":                   "role:write",
+	"/api/batch-add-roles":               "role:write",
+	"/api/batch-update-roles":            "role:write",
+	"/api/batch-delete-roles":            "role:write",
+	"/api/get-permissions":               "permission:read",
+	"/api/get-permissions-by-submitter":  "permission:read",
+	"/api/get-permissions-by-role":       "permission:read",
+	"/api/get-permission":                "permission:read",
+	"/api/update-permission":             "permission:write",
+	"/api/add-permission":                "permission:write",
+	"/api/delete-permission":             "permission:write",
+	"/api/batch-add-permissions":         "permission:write",
+	"/api/batch-update-permissions":      "permission:write",
+	"/api/batch-delete-permissions":      "permission:write",
+	"/api/get-enforcer-metrics":          "permission:read",
+	"/api/get-relation-tuples":           "relation-tuple:read",
+	"/api/add-relation-tuple":            "relation-tuple:write",
+	"/api/delete-relation-tuple":         "relation-tuple:write",
+	"/api/get-access-requests":           "access-request:read",
+	"/api/get-access-request":            "access-request:read",
+	"/api/approve-access-request":        "access-request:decide",
+	"/api/deny-access-request":           "access-request:decide",
+	"/api/get-access-reviews":            "access-review:read",
+	"/api/get-access-review":             "access-review:read",
+	"/api/update-access-review":          "access-review:write",
+	"/api/add-access-review":             "access-review:write",
+	"/api/delete-access-review":          "access-review:write",
+	"/api/complete-access-review":        "access-review:write",
+	"/api/get-access-review-items":       "access-review:read",
+	"/api/export-access-review":          "access-review:read",
+	"/api/get-api-keys":                  "api-key:read",
+	"/api/get-api-key":                   "api-key:read",
+	"/api/add-api-key":                   "api-key:write",
+	"/api/revoke-api-key":                "api-key:write",
+	"/api/delete-api-key":                "api-key:write",
+	"/api/get-memberships":               "membership:read",
+	"/api/get-membership":                "membership:read",
+	"/api/update-membership":             "membership:write",
+	"/api/add-membership":                "membership:write",
+	"/api/delete-membership":             "membership:write",
+	"/api/export-config":                 "config:export",
+	"/api/import-config":                 "config:import",
+	"/api/import-from-idp":               "config:import",
+	"/api/get-models":                    "model:read",
+	"/api/get-model":                     "model:read",
+	"/api/update-model":                  "model:write",
+	"/api/add-model":                     "model:write",
+	"/api/delete-model":                  "model:write",
+	"/api/validate-model":                "model:read",
+	"/api/get-adapters":                  "adapter:read",
+	"/api/get-adapter":                   "adapter:read",
+	"/api/update-adapter":                "adapter:write",
+	"/api/add-adapter":                   "adapter:write",
+	"/api/delete-adapter":                "adapter:write",
+	"/api/sync-policies":                 "adapter:sync",
+	"/api/update-policy":                 "adapter:write",
+	"/api/add-policy":                    "adapter:write",
+	"/api/remove-policy":                 "adapter:write",
+	"/api/set-password":                  "user:password",
+	"/api/create-account-recovery-link":  "user:password",
+	"/api/get-account-recoveries":        "user:read",
+	"/api/get-contact-changes":           "user:read",
+	"/api/get-ldap-users":                "ldap:read",
+	"/api/get-ldaps":                     "ldap:read",
+	"/api/get-ldap":                      "ldap:read",
+	"/api/add-ldap":                      "ldap:write",
+	"/api/update-ldap":                   "ldap:write",
+	"/api/delete-ldap":                   "ldap:write",
+	"/api/sync-ldap-users":               "ldap:sync",
+	"/api/get-providers":                 "provider:read",
+	"/api/get-provider":                  "provider:read",
+	"/api/get-global-providers":          "provider:read",
+	"/api/update-provider":               "provider:write",
+	"/api/upsert-provider":               "provider:write",
+	"/api/add-provider":                  "provider:write",
+	"/api/delete-provider":               "provider:write",
+	"/api/get-email-suppressions":        "provider:read",
+	"/api/add-email-suppression":         "provider:write",
+	"/api/delete-email-suppression":      "provider:write",
+	"/api/get-applications":              "application:read",
+	"/api/get-application":               "application:read",
+	"/api/get-organization-applications": "application:read",
+	"/api/update-application":            "application:write",
+	"/api/upsert-application":            "application:write",
+	"/api/add-application":               "application:write",
+	"/api/delete-application":            "application:write",
+	"/api/check-application-sso":         "application:write",
+	"/api/get-resources":                 "resource:read",
+	"/api/get-resource":                  "resource:read",
+	"/api/update-resource":               "resource:write",
+	"/api/add-resource":                  "resource:write",
+	"/api/delete-resource":               "resource:write",
+	"/api/upload-resource":               "resource:write",
+	"/api/get-tokens":                    "token:read",
+	"/api/get-token":                     "token:read",
+	"/api/update-token":                  "token:write",
+	"/api/add-token":                     "token:write",
+	"/api/delete-token":                  "token:write",
+	"/api/get-token-stats":               "token:read",
+	"/api/get-token-anomalies":           "token:read",
+	"/api/review-token-anomaly":          "token:write",
+	"/api/get-records":                   "record:read",
+	"/api/get-records-filter":            "record:read",
+	"/api/export-records":                "record:read",
+	"/api/get-login-stats":               "record:read",
+	"/api/export-login-stats":            "record:read",
+	"/api/get-sessions":                  "session:read",
+	"/api/get-session":                   "session:read",
+	"/api/update-session":                "session:write",
+	"/api/add-session":                   "session:write",
+	"/api/delete-session":                "session:write",
+	"/api/get-webhooks":                  "webhook:read",
+	"/api/get-webhook":                   "webhook:read",
+	"/api/update-webhook":                "webhook:write",
+	"/api/add-webhook":                   "webhook:write",
+	"/api/delete-webhook":                "webhook:write",
+	"/api/get-login-hooks":               "login-hook:read",
+	"/api/get-login-hook":                "login-hook:read",
+	"/api/update-login-hook":             "login-hook:write",
+	"/api/add-login-hook":                "login-hook:write",
+	"/api/delete-login-hook":             "login-hook:write",
+	"/api/get-syncers":                   "syncer:read",
+	"/api/get-syncer":                    "syncer:read",
+	"/api/update-syncer":                 "syncer:write",
+	"/api/add-syncer":                    "syncer:write",
+	"/api/delete-syncer":                 "syncer:write",
+	"/api/run-syncer":                    "syncer:run",
+	"/api/get-jobs":                      "job:read",
+	"/api/get-job":                       "job:read",
+	"/api/get-tasks":                     "task:read",
+	"/api/get-task":                      "task:read",
+	"/api/retry-task":                    "task:write",
+	"/api/get-settings":                  "setting:read",
+	"/api/update-settings":               "setting:write",
+	"/api/get-setting-changes":           "setting:read",
+	"/api/get-retention-metrics":         "setting:read",
+	"/api/get-certs":                     "cert:read",
+	"/api/get-cert":                      "cert:read",
+	"/api/update-cert":                   "cert:write",
+	"/api/upsert-cert":                   "cert:write",
+	"/api/add-cert":                      "cert:write",
+	"/api/delete-cert":                   "cert:write",
+	"/api/generate-cert-csr":             "cert:write",
+	"/api/upload-cert-certificate":       "cert:write",
+	"/api/get-ssh-certificates":          "cert:read",
+	"/api/get-products":                  "product:read",
+	"/api/get-product":                   "product:read",
+	"/api/update-product":                "product:write",
+	"/api/add-product":                   "product:write",
+	"/api/delete-product":                "product:write",
+	"/api/get-payments":                  "payment:read",
+	"/api/get-payment":                   "payment:read",
+	"/api/update-payment":                "payment:write",
+	"/api/add-payment":                   "payment:write",
+	"/api/delete-payment":                "payment:write",
+	"/api/get-plans":                     "plan:read",
+	"/api/get-plan":                      "plan:read",
+	"/api/update-plan":                   "plan:write",
+	"/api/add-plan":                      "plan:write",
+	"/api/delete-plan":                   "plan:write",
+	"/api/get-subscriptions":             "subscription:read",
+	"/api/get-subscription":              "subscription:read",
+	"/api/update-subscription":           "subscription:write",
+	"/api/add-subscription":              "subscription:write",
+	"/api/delete-subscription":           "subscription:write",
+	"/api/get-organization-usage":        "subscription:read",
+	"/api/get-usages":                    "subscription:read",
+	"/api/get-identity-verifications":    "identity-verification:read",
+	"/api/get-identity-verification":     "identity-verification:read",
+	"/api/send-email":                    "email:send",
+	"/api/send-sms":                      "sms:send",
+}
+
+// GetCapabilities returns all the capabilities of endpointCapabilities.
+func GetCapabilities() []string {
+	res := []string{}
+	for _, capability := range endpointCapabilities {
+		if !util.ContainsString(res, capability) {
+			res = append(res, capability)
+		}
+	}
+
+	sort.Strings(res)
+	return res
+}
+
+func GetEndpointCapability(urlPath string) string {
+	return endpointCapabilities[urlPath]
+}
+
+// hasCapability allows "user:*" for all the capabilities of a resource and
+// "*" for all capabilities.
+func hasCapability(capabilities []string, capability string) bool {
+	resource := strings.SplitN(capability, ":", 2)[0]
+	for _, c := range capabilities {
+		if c == capability || c == "*" || c == resource+":*" {
+			return true
+		}
+	}
+	return false
+}
+
+// getCapabilityOrganization returns the organization of the object of a
+// request, organizations and applications are owned by "admin".
+func getCapabilityOrganization(capability string, objOwner string, objName string) string {
+	if objOwner != "admin" || objName == "" {
+		return objOwner
+	}
+
+	switch strings.SplitN(capability, ":", 2)[0] {
+	case "organization":
+		return objName
+	case "application":
+		application := getApplication("admin", objName)
+		if application != nil {
+			return application.Organization
+		}
+	}
+	return objOwner
+}
+
+// HasEndpointCapability returns whether the roles of the user grant the
+// capability of the endpoint on the object of the request.
+func HasEndpointCapability(userId string, urlPath string, objOwner string, objName string) bool {
+	capability := GetEndpointCapability(urlPath)
+	if capability == "" {
+		return false
+	}
+
+	organization := getCapabilityOrganization(capability, objOwner, objName)
+	for _, role := range GetAllRolesByUser(userId) {
+		if !role.IsEnabled || len(role.Capabilities) == 0 {
+			continue
+		}
+		if role.Owner != "built-in" && (role.Owner != organization || organization == "") {
+			continue
+		}
+
+		if hasCapability(role.Capabilities, capability) {
+			return true
+		}
+	}
+	return false
+}
+
+// IsRoleGrantingCapabilities reports whether the users of a role get any
+// capability, from the role itself or from the roles it is a sub role of.
+func IsRoleGrantingCapabilities(role *Role) bool {
+	if len(role.Capabilities) != 0 {
+		return true
+	}
+
+	for _, ancestor := range getAncestorRoles([]*Role{role}) {
+		if len(ancestor.Capabilities) != 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// CheckRoleCapabilities checks that the capabilities of a role are known,
+// wildcards included.
+func CheckRoleCapabilities(role *Role, lang string) string {
+	capabilities := GetCapabilities()
+	for _, capability := range role.Capabilities {
+		if capability == "*" {
+			continue
+		}
+		if strings.HasSuffix(capability, ":*") {
+			resource := strings.TrimSuffix(capability, "*")
+			found := false
+			for _, c := range capabilities {
+				if strings.HasPrefix(c, resource) {
+					found = true
+					break
+				}
+			}
+			if found {
+				continue
+			}
+		} else if util.ContainsString(capabilities, capability) {
+			continue
+		}
+
+		return fmt.Sprintf(i18n.Translate(lang, "role:Unknown capability: %s"), capability)
+	}
+	return ""
+}
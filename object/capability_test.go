@@ -0,0 +1,63 @@
+// Copyright 2023 The Casdoor Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package object
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHasCapability(t *testing.T) {
+	scenarios := []struct {
+		description  string
+		capabilities []string
+		capability   string
+		expected     bool
+	}{
+		{"exact capability", []string{"user:read"}, "user:read", true},
+		{"other action", []string{"user:read"}, "user:write", false},
+		{"resource wildcard", []string{"provider:*"}, "provider:write", true},
+		{"resource wildcard of other resource", []string{"provider:*"}, "cert:write", false},
+		{"all capabilities", []string{"*"}, "syncer:run", true},
+		{"no capabilities", []string{}, "user:read", false},
+	}
+
+	for _, scenery := range scenarios {
+		t.Run(scenery.description, func(t *testing.T) {
+			assert.Equal(t, scenery.expected, hasCapability(scenery.capabilities, scenery.capability))
+		})
+	}
+}
+
+func TestCheckRoleCapabilities(t *testing.T) {
+	scenarios := []struct {
+		description  string
+		capabilities []string
+		valid        bool
+	}{
+		{"known capabilities", []string{"user:read", "provider:write", "syncer:run"}, true},
+		{"wildcards", []string{"*", "cert:*"}, true},
+		{"unknown capability", []string{"user:fly"}, false},
+		{"unknown resource wildcard", []string{"spaceship:*"}, false},
+	}
+
+	for _, scenery := range scenarios {
+		t.Run(scenery.description, func(t *testing.T) {
+			msg := CheckRoleCapabilities(&Role{Capabilities: scenery.capabilities}, "en")
+			assert.Equal(t, scenery.valid, msg == "", msg)
+		})
+	}
+}
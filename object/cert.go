@@ -25,6 +25,7 @@ type Cert struct {
 	Owner       string `xorm:"varchar(100) notnull pk" json:"owner"`
 	Name        string `xorm:"varchar(100) notnull pk" json:"name"`
 	CreatedTime string `xorm:"varchar(100)" json:"createdTime"`
+	Version     int    `xorm:"int notnull default 0" json:"version"`
 
 	DisplayName     string `xorm:"varchar(100)" json:"displayName"`
 	Scope           string `xorm:"varchar(100)" json:"scope"`
@@ -37,6 +38,24 @@ type Cert struct {
 	PrivateKey             string `xorm:"mediumtext" json:"privateKey"`
 	AuthorityPublicKey     string `xorm:"mediumtext" json:"authorityPublicKey"`
 	AuthorityRootPublicKey string `xorm:"mediumtext" json:"authorityRootPublicKey"`
+
+	// the key of a pending CSR is kept until its certificate is uploaded
+	Csr             string `xorm:"mediumtext" json:"csr"`
+	CsrPrivateKey   string `xorm:"mediumtext" json:"-"`
+	ExpireTime      string `xorm:"varchar(100)" json:"expireTime"`
+	ExpiryAlertDays int    `json:"expiryAlertDays"`
+
+	// the default and the longest lifetime of the certificates signed by an SSH CA
+	SshExpireInMinutes    int `json:"sshExpireInMinutes"`
+	SshMaxExpireInMinutes int `json:"sshMaxExpireInMinutes"`
+	// SshUserPrincipal adds the user name to the principals of the role names,
+	// users choose their names at signup, so only for CAs whose hosts don't
+	// have accounts like "root"
+	SshUserPrincipal bool `json:"sshUserPrincipal"`
+
+	// Organization is the only organization that can use an SSH CA or a
+	// device CA, as the certs are shared by all organizations
+	Organization string `xorm:"varchar(100)" json:"organization"`
 }
 
 func GetMaskedCert(cert *Cert) *Cert {
@@ -91,12 +110,18 @@ func getCert(owner string, name string) *Cert {
 	}
 
 	cert := Cert{Owner: owner, Name: name}
+	key := getSharedCacheKey("cert", cert.GetId())
+	if getCachedObject(key, &cert) {
+		return &cert
+	}
+
 	existed, err := adapter.Engine.Get(&cert)
 	if err != nil {
 		panic(err)
 	}
 
 	if existed {
+		setCachedObject(key, &cert)
 		return &cert
 	} else {
 		return nil
@@ -109,37 +134,75 @@ func GetCert(id string) *Cert {
 }
 
 func UpdateCert(id string, cert *Cert) bool {
+	affected, _ := UpdateCertWithPrecondition(id, cert, nil)
+	return affected
+}
+
+// UpdateCertWithPrecondition updates the cert only if it satisfies
+// precondition, otherwise it returns ErrPreconditionFailed.
+func UpdateCertWithPrecondition(id string, cert *Cert, precondition *Precondition) (bool, error) {
 	owner, name := util.GetOwnerAndNameFromId(id)
-	if getCert(owner, name) == nil {
-		return false
+	oldCert := getCert(owner, name)
+	if !precondition.isSatisfiedBy(oldCert) {
+		return false, ErrPreconditionFailed
+	}
+	if oldCert == nil {
+		return false, nil
+	}
+
+	cert.CsrPrivateKey = getCertCsrPrivateKey(owner, name)
+	cert.ExpireTime = getCertExpireTime(cert.Certificate)
+	if cert.ExpireTime != oldCert.ExpireTime {
+		cert.ExpiryAlertDays = 0
 	}
 
 	if name != cert.Name {
-		err := certChangeTrigger(name, cert.Name)
+		err := precondition.checkRename(name, cert.Name)
 		if err != nil {
-			return false
+			return false, err
+		}
+		err = certChangeTrigger(name, cert.Name)
+		if err != nil {
+			return false, nil
 		}
 	}
-	affected, err := adapter.Engine.ID(core.PK{owner, name}).AllCols().Update(cert)
+	affected, err := updateWithVersion(adapter.Engine.ID(core.PK{owner, name}).AllCols(), cert, oldCert.Version, precondition)
 	if err != nil {
-		panic(err)
+		return false, err
 	}
 
-	return affected != 0
+	deleteCachedObjects(getSharedCacheKey("cert", id), getSharedCacheKey("cert", cert.GetId()), sharedCacheJwksKey)
+	return affected, nil
 }
 
 func AddCert(cert *Cert) bool {
-	if cert.Certificate == "" || cert.PrivateKey == "" {
+	if cert.Type == CertTypeSshCa && (cert.Certificate == "" || cert.PrivateKey == "") {
+		publicKey, privateKey, err := generateSshCaKeys(cert.CryptoAlgorithm, cert.BitSize)
+		if err != nil {
+			panic(err)
+		}
+		cert.Certificate = publicKey
+		cert.PrivateKey = privateKey
+	} else if cert.Type == CertTypeDeviceCa && (cert.Certificate == "" || cert.PrivateKey == "") {
+		certificate, privateKey, err := generateDeviceCaKeys(cert.BitSize, cert.ExpireInYears, cert.Name, cert.Owner)
+		if err != nil {
+			panic(err)
+		}
+		cert.Certificate = certificate
+		cert.PrivateKey = privateKey
+	} else if cert.Certificate == "" || cert.PrivateKey == "" {
 		certificate, privateKey := generateRsaKeys(cert.BitSize, cert.ExpireInYears, cert.Name, cert.Owner)
 		cert.Certificate = certificate
 		cert.PrivateKey = privateKey
 	}
+	cert.ExpireTime = getCertExpireTime(cert.Certificate)
 
 	affected, err := adapter.Engine.Insert(cert)
 	if err != nil {
 		panic(err)
 	}
 
+	deleteCachedObjects(sharedCacheJwksKey)
 	return affected != 0
 }
 
@@ -149,6 +212,7 @@ func DeleteCert(cert *Cert) bool {
 		panic(err)
 	}
 
+	deleteCachedObjects(getSharedCacheKey("cert", cert.GetId()), sharedCacheJwksKey)
 	return affected != 0
 }
 
@@ -179,10 +243,16 @@ func certChangeTrigger(oldName string, newName string) error {
 
 	application := new(Application)
 	application.Cert = newName
-	_, err = session.Where("cert=?", oldName).Update(application)
+	_, err = session.Where("cert=?", oldName).Incr("version").Update(application)
+	if err != nil {
+		return err
+	}
+
+	err = session.Commit()
 	if err != nil {
 		return err
 	}
 
-	return session.Commit()
+	deleteCachedObjectsByQuery("application", "cert=?", newName)
+	return nil
 }
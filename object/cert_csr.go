@@ -0,0 +1,317 @@
+// Copyright 2023 The Casdoor Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package object
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/casdoor/casdoor/util"
+	"github.com/xorm-io/core"
+)
+
+const certExpiryJobInterval = 24 * time.Hour
+
+// certExpiryAlertDays are the days before expiry at which the admins are
+// alerted, once per threshold.
+var certExpiryAlertDays = []int{30, 14, 7, 1}
+
+// CertCsrForm is the subject of a certificate signing request.
+type CertCsrForm struct {
+	CommonName         string   `json:"commonName"`
+	Organization       string   `json:"organization"`
+	OrganizationalUnit string   `json:"organizationalUnit"`
+	Country            string   `json:"country"`
+	Province           string   `json:"province"`
+	Locality           string   `json:"locality"`
+	DnsNames           []string `json:"dnsNames"`
+}
+
+func parseCertificatePem(certificate string) (*x509.Certificate, error) {
+	block, _ := pem.Decode([]byte(certificate))
+	if block == nil || block.Type != "CERTIFICATE" {
+		return nil, fmt.Errorf("invalid certificate")
+	}
+
+	return x509.ParseCertificate(block.Bytes)
+}
+
+func parseRsaPrivateKeyPem(privateKey string) (*rsa.PrivateKey, error) {
+	block, _ := pem.Decode([]byte(privateKey))
+	if block == nil {
+		return nil, fmt.Errorf("invalid private key")
+	}
+
+	return x509.ParsePKCS1PrivateKey(block.Bytes)
+}
+
+// getCertCsrPrivateKey reads the key of the pending CSR from the database,
+// it isn't part of the JSON of the cert and so not of the cached certs.
+func getCertCsrPrivateKey(owner string, name string) string {
+	cert := Cert{Owner: owner, Name: name}
+	_, err := adapter.Engine.Cols("csr_private_key").Get(&cert)
+	if err != nil {
+		panic(err)
+	}
+
+	return cert.CsrPrivateKey
+}
+
+// getCertExpireTime returns the expiry of the certificate as stored in
+// ExpireTime, or "" when it can't be parsed.
+func getCertExpireTime(certificate string) string {
+	x509Certificate, err := parseCertificatePem(certificate)
+	if err != nil {
+		return ""
+	}
+
+	return x509Certificate.NotAfter.Format(time.RFC3339)
+}
+
+// generateCertCsr generates an RSA key and a certificate signing request for
+// it, both PEM encoded.
+func generateCertCsr(bitSize int, form *CertCsrForm) (string, string, error) {
+	if form.CommonName == "" {
+		return "", "", fmt.Errorf("the common name of the CSR is empty")
+	}
+	if bitSize <= 0 {
+		bitSize = 2048
+	}
+
+	key, err := rsa.GenerateKey(rand.Reader, bitSize)
+	if err != nil {
+		return "", "", err
+	}
+
+	subject := pkix.Name{CommonName: form.CommonName}
+	if form.Organization != "" {
+		subject.Organization = []string{form.Organization}
+	}
+	if form.OrganizationalUnit != "" {
+		subject.OrganizationalUnit = []string{form.OrganizationalUnit}
+	}
+	if form.Country != "" {
+		subject.Country = []string{form.Country}
+	}
+	if form.Province != "" {
+		subject.Province = []string{form.Province}
+	}
+	if form.Locality != "" {
+		subject.Locality = []string{form.Locality}
+	}
+
+	template := &x509.CertificateRequest{
+		Subject:            subject,
+		DNSNames:           form.DnsNames,
+		SignatureAlgorithm: x509.SHA256WithRSA,
+	}
+	csr, err := x509.CreateCertificateRequest(rand.Reader, template, key)
+	if err != nil {
+		return "", "", err
+	}
+
+	csrPem := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE REQUEST", Bytes: csr})
+	keyPem := pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)})
+	return string(csrPem), string(keyPem), nil
+}
+
+// checkCertificateKey tells whether the first certificate of the PEM chain
+// is the one of the private key.
+func checkCertificateKey(certificate string, privateKey string) (*x509.Certificate, error) {
+	x509Certificate, err := parseCertificatePem(certificate)
+	if err != nil {
+		return nil, err
+	}
+	key, err := parseRsaPrivateKeyPem(privateKey)
+	if err != nil {
+		return nil, err
+	}
+
+	publicKey, ok := x509Certificate.PublicKey.(*rsa.PublicKey)
+	if !ok || !publicKey.Equal(&key.PublicKey) {
+		return nil, fmt.Errorf("the certificate doesn't match the private key")
+	}
+	return x509Certificate, nil
+}
+
+// GenerateCertCsr starts the renewal of a cert by a CA: a new key pair is
+// generated and kept aside with its CSR, the cert goes on signing with its
+// current key until the certificate issued for the CSR is uploaded.
+func GenerateCertCsr(id string, form *CertCsrForm) (*Cert, error) {
+	cert := GetCert(id)
+	if cert == nil {
+		return nil, fmt.Errorf("the cert: %s does not exist", id)
+	}
+
+	csr, privateKey, err := generateCertCsr(cert.BitSize, form)
+	if err != nil {
+		return nil, err
+	}
+
+	cert.Csr = csr
+	cert.CsrPrivateKey = privateKey
+	_, err = adapter.Engine.ID(core.PK{cert.Owner, cert.Name}).Cols("csr", "csr_private_key").Incr("version").Update(cert)
+	if err != nil {
+		return nil, err
+	}
+
+	deleteCachedObjects(getSharedCacheKey("cert", cert.GetId()))
+	return cert, nil
+}
+
+// UploadCertCertificate replaces the certificate of the cert with the one
+// issued by the CA, as a PEM chain with the certificate first. It must be
+// issued for the key of the pending CSR, or else for the current key of the
+// cert (a renewal of the same key).
+func UploadCertCertificate(id string, certificate string) (*Cert, error) {
+	cert := GetCert(id)
+	if cert == nil {
+		return nil, fmt.Errorf("the cert: %s does not exist", id)
+	}
+
+	cert.CsrPrivateKey = getCertCsrPrivateKey(cert.Owner, cert.Name)
+	certificate = strings.TrimSpace(certificate) + "\n"
+	privateKey := cert.CsrPrivateKey
+	if privateKey == "" {
+		privateKey = cert.PrivateKey
+	}
+	x509Certificate, err := checkCertificateKey(certificate, privateKey)
+	if err != nil && cert.CsrPrivateKey != "" {
+		// a renewal of the current key is accepted while a CSR is pending
+		privateKey = cert.PrivateKey
+		x509Certificate, err = checkCertificateKey(certificate, privateKey)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if time.Now().After(x509Certificate.NotAfter) {
+		return nil, fmt.Errorf("the certificate expired at %s", x509Certificate.NotAfter.Format(time.RFC3339))
+	}
+
+	cert.Certificate = certificate
+	cert.PrivateKey = privateKey
+	cert.Csr = ""
+	cert.CsrPrivateKey = ""
+	cert.ExpireTime = x509Certificate.NotAfter.Format(time.RFC3339)
+	cert.ExpiryAlertDays = 0
+	_, err = adapter.Engine.ID(core.PK{cert.Owner, cert.Name}).
+		Cols("certificate", "private_key", "csr", "csr_private_key", "expire_time", "expiry_alert_days").Incr("version").Update(cert)
+	if err != nil {
+		return nil, err
+	}
+
+	deleteCachedObjects(getSharedCacheKey("cert", cert.GetId()), sharedCacheJwksKey)
+	return cert, nil
+}
+
+// getCertExpiryAlertDays returns the smallest threshold of
+// certExpiryAlertDays that the expiry is within, -1 for an expired
+// certificate and 0 when it's further away than all thresholds.
+func getCertExpiryAlertDays(expireTime time.Time, now time.Time) int {
+	remaining := expireTime.Sub(now)
+	if remaining <= 0 {
+		return -1
+	}
+
+	res := 0
+	for _, days := range certExpiryAlertDays {
+		if remaining <= time.Duration(days)*24*time.Hour && (res == 0 || days < res) {
+			res = days
+		}
+	}
+	return res
+}
+
+func getCertOrganization(cert *Cert) string {
+	if cert.Owner == "admin" {
+		return "built-in"
+	}
+	return cert.Owner
+}
+
+func alertCertExpiry(cert *Cert, expireTime time.Time, days int) {
+	organization := getCertOrganization(cert)
+	record := &Record{
+		Name:         util.GenerateId(),
+		CreatedTime:  util.GetCurrentTime(),
+		Organization: organization,
+		Method:       "POST",
+		RequestUri:   fmt.Sprintf("/api/get-cert?id=%s", cert.GetId()),
+		Action:       "cert-expiring",
+	}
+	AddRecord(record)
+
+	title := fmt.Sprintf("The cert %s expires on %s", cert.GetId(), expireTime.Format("2006-01-02"))
+	if days < 0 {
+		title = fmt.Sprintf("The cert %s expired on %s", cert.GetId(), expireTime.Format("2006-01-02"))
+	}
+	content := fmt.Sprintf("%s. Generate a CSR for the cert, have it signed by your CA and upload the certificate before it lapses, or the SAML and JWT signatures made with it will be rejected.", title)
+	notifyOrganizationAdmins(organization, title, content)
+}
+
+// checkCertExpiry alerts the admins of the certs that get close to their
+// expiry, once for each threshold.
+func checkCertExpiry() error {
+	now := time.Now()
+	for _, cert := range GetCerts("") {
+		x509Certificate, err := parseCertificatePem(cert.Certificate)
+		if err != nil {
+			continue
+		}
+
+		changed := false
+		expireTime := x509Certificate.NotAfter.Format(time.RFC3339)
+		if cert.ExpireTime != expireTime {
+			cert.ExpireTime = expireTime
+			cert.ExpiryAlertDays = 0
+			changed = true
+		}
+
+		days := getCertExpiryAlertDays(x509Certificate.NotAfter, now)
+		if days != 0 && (cert.ExpiryAlertDays == 0 || days < cert.ExpiryAlertDays) {
+			alertCertExpiry(cert, x509Certificate.NotAfter, days)
+			cert.ExpiryAlertDays = days
+			changed = true
+		}
+
+		if !changed {
+			continue
+		}
+		_, err = adapter.Engine.ID(core.PK{cert.Owner, cert.Name}).Cols("expire_time", "expiry_alert_days").Incr("version").Update(cert)
+		if err != nil {
+			return err
+		}
+		deleteCachedObjects(getSharedCacheKey("cert", cert.GetId()))
+	}
+
+	return nil
+}
+
+// RunCertExpiryJob checks the expiry of the certs every day, once per day
+// cluster-wide.
+func RunCertExpiryJob() {
+	ticker := time.NewTicker(certExpiryJobInterval)
+	defer ticker.Stop()
+	for {
+		RunClusterJob("cert-expiry", certExpiryJobInterval, checkCertExpiry)
+		<-ticker.C
+	}
+}
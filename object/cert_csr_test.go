@@ -0,0 +1,79 @@
+// Copyright 2023 The Casdoor Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package object
+
+import (
+	"crypto/x509"
+	"encoding/pem"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetCertExpiryAlertDays(t *testing.T) {
+	now := time.Now()
+	scenarios := []struct {
+		description string
+		expireTime  time.Time
+		expected    int
+	}{
+		{"far away", now.AddDate(1, 0, 0), 0},
+		{"within 30 days", now.AddDate(0, 0, 20), 30},
+		{"within 14 days", now.AddDate(0, 0, 10), 14},
+		{"within a day", now.Add(time.Hour), 1},
+		{"expired", now.Add(-time.Hour), -1},
+	}
+
+	for _, scenery := range scenarios {
+		t.Run(scenery.description, func(t *testing.T) {
+			assert.Equal(t, scenery.expected, getCertExpiryAlertDays(scenery.expireTime, now))
+		})
+	}
+}
+
+func TestGenerateCertCsr(t *testing.T) {
+	csr, privateKey, err := generateCertCsr(2048, &CertCsrForm{CommonName: "casdoor.example.com", Organization: "Casdoor", DnsNames: []string{"casdoor.example.com"}})
+	assert.Nil(t, err)
+
+	block, _ := pem.Decode([]byte(csr))
+	assert.NotNil(t, block)
+	request, err := x509.ParseCertificateRequest(block.Bytes)
+	assert.Nil(t, err)
+	assert.Nil(t, request.CheckSignature())
+	assert.Equal(t, "casdoor.example.com", request.Subject.CommonName)
+	assert.Equal(t, []string{"casdoor.example.com"}, request.DNSNames)
+
+	_, err = parseRsaPrivateKeyPem(privateKey)
+	assert.Nil(t, err)
+
+	_, _, err = generateCertCsr(2048, &CertCsrForm{})
+	assert.NotNil(t, err)
+}
+
+func TestCheckCertificateKey(t *testing.T) {
+	certificate, privateKey := generateRsaKeys(2048, 1, "casdoor", "casdoor")
+	_, otherPrivateKey := generateRsaKeys(2048, 1, "casdoor", "casdoor")
+
+	x509Certificate, err := checkCertificateKey(certificate, privateKey)
+	assert.Nil(t, err)
+	assert.Equal(t, "casdoor", x509Certificate.Subject.CommonName)
+
+	_, err = checkCertificateKey(certificate, otherPrivateKey)
+	assert.NotNil(t, err)
+
+	_, err = checkCertificateKey("not a certificate", privateKey)
+	assert.NotNil(t, err)
+}
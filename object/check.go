@@ -168,7 +168,7 @@ func CheckPassword(user *User, password string, lang string) string {
 		return i18n.Translate(lang, "check:Organization does not exist")
 	}
 
-	credManager := cred.GetCredManager(organization.PasswordType)
+	credManager := organization.getCredManager()
 	if credManager != nil {
 		if organization.MasterPassword != "" {
 			if credManager.IsPasswordCorrect(password, organization.MasterPassword, "", organization.PasswordSalt) {
@@ -177,8 +177,19 @@ func CheckPassword(user *User, password string, lang string) string {
 			}
 		}
 
+		passwordType := user.getPasswordType(organization)
+		if passwordType != organization.PasswordType {
+			credManager = cred.GetCredManager(passwordType)
+			if credManager == nil {
+				return fmt.Sprintf(i18n.Translate(lang, "check:unsupported password type: %s"), passwordType)
+			}
+		}
+
 		if credManager.IsPasswordCorrect(password, user.Password, user.PasswordSalt, organization.PasswordSalt) {
 			resetUserSigninErrorTimes(user)
+			if passwordType != organization.PasswordType || user.needsRehash(credManager) {
+				rehashUserPassword(user, organization, password)
+			}
 			return ""
 		}
 
@@ -363,6 +374,9 @@ func CheckUpdateUser(oldUser *User, user *User, lang string) string {
 			return i18n.Translate(lang, "check:Phone already exists")
 		}
 	}
+	if msg := CheckAdminEmailDomain(user, lang); msg != "" {
+		return msg
+	}
 
 	return ""
 }
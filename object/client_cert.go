@@ -0,0 +1,398 @@
+// Copyright 2023 The Casdoor Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package object
+
+import (
+	"bytes"
+	"crypto/x509"
+	"encoding/asn1"
+	"encoding/base64"
+	"encoding/pem"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"golang.org/x/crypto/ocsp"
+)
+
+const (
+	ClientCertFieldCommonName   = "Common name"
+	ClientCertFieldEmail        = "Email"
+	ClientCertFieldUpn          = "UPN"
+	ClientCertFieldSerialNumber = "Serial number"
+)
+
+var (
+	oidSubjectAltName     = asn1.ObjectIdentifier{2, 5, 29, 17}
+	oidUserPrincipalName  = asn1.ObjectIdentifier{1, 3, 6, 1, 4, 1, 311, 20, 2, 3}
+	oidEmailAddress       = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 9, 1}
+	clientCertHttpClient  = &http.Client{Timeout: 10 * time.Second}
+	clientCertUserColumns = map[string]string{"Name": "name", "Email": "email", "Phone": "phone", "ID card": "id_card"}
+)
+
+// ClientCertLoginConfig lets the users of an application sign in with a TLS
+// client certificate, like the one of a smart card.
+type ClientCertLoginConfig struct {
+	Enabled bool `json:"enabled"`
+	// CaCertificates are the PEM encoded CAs that the certificates must chain to
+	CaCertificates string `json:"caCertificates"`
+	// Header is the request header in which a TLS terminating proxy forwards
+	// the client certificate, only the TLS connection is used when empty
+	Header string `json:"header"`
+	// TrustedProxies are the comma separated IPs or CIDRs of the proxies
+	// whose Header is accepted, the Header of other peers is ignored
+	TrustedProxies string `json:"trustedProxies"`
+	CheckCrl       bool   `json:"checkCrl"`
+	CheckOcsp      bool   `json:"checkOcsp"`
+	// CertField of the certificate is matched with UserField of the users
+	CertField string `json:"certField"`
+	UserField string `json:"userField"`
+}
+
+// parseClientCertHeader parses the certificates forwarded by a proxy: URL
+// escaped PEM like nginx's $ssl_client_escaped_cert, plain PEM, or comma
+// separated base64 DER like Traefik's X-Forwarded-Tls-Client-Cert.
+func parseClientCertHeader(value string) ([]*x509.Certificate, error) {
+	if strings.Contains(value, "%") {
+		unescaped, err := url.QueryUnescape(value)
+		if err != nil {
+			return nil, err
+		}
+		value = unescaped
+	}
+
+	certificates := []*x509.Certificate{}
+	if strings.Contains(value, "-----BEGIN") {
+		// some proxies put the PEM on one line, with spaces or tabs for the newlines
+		data := []byte(value)
+		for {
+			var block *pem.Block
+			block, data = pem.Decode(data)
+			if block == nil {
+				break
+			}
+			certificate, err := x509.ParseCertificate(block.Bytes)
+			if err != nil {
+				return nil, err
+			}
+			certificates = append(certificates, certificate)
+		}
+		if len(certificates) == 0 {
+			return parseClientCertHeader(strings.NewReplacer("-----BEGIN CERTIFICATE-----", "", "-----END CERTIFICATE-----", ",", " ", "", "\t", "").Replace(value))
+		}
+		return certificates, nil
+	}
+
+	for _, item := range strings.Split(value, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		der, err := base64.StdEncoding.DecodeString(item)
+		if err != nil {
+			return nil, fmt.Errorf("the client certificate is not base64, %s", err.Error())
+		}
+		certificate, err := x509.ParseCertificate(der)
+		if err != nil {
+			return nil, err
+		}
+		certificates = append(certificates, certificate)
+	}
+	if len(certificates) == 0 {
+		return nil, fmt.Errorf("no client certificate is provided")
+	}
+	return certificates, nil
+}
+
+// getClientCertificates returns the certificate of the client first and the
+// intermediate certificates it sent after it.
+func getClientCertificates(config *ClientCertLoginConfig, request *http.Request) ([]*x509.Certificate, error) {
+	if request.TLS != nil && len(request.TLS.PeerCertificates) > 0 {
+		return request.TLS.PeerCertificates, nil
+	}
+
+	if config.Header != "" && isTrustedClientCertProxy(config, request.RemoteAddr) {
+		if value := request.Header.Get(config.Header); value != "" {
+			return parseClientCertHeader(value)
+		}
+	}
+	return nil, fmt.Errorf("no client certificate is provided")
+}
+
+// isTrustedClientCertProxy reports whether the peer of the connection, not
+// a forwarded address that the client can set, is one of the trusted proxies.
+func isTrustedClientCertProxy(config *ClientCertLoginConfig, remoteAddr string) bool {
+	host, _, err := net.SplitHostPort(remoteAddr)
+	if err != nil {
+		host = remoteAddr
+	}
+	ip := net.ParseIP(host)
+	if ip == nil {
+		return false
+	}
+
+	for _, proxy := range strings.Split(config.TrustedProxies, ",") {
+		proxy = strings.TrimSpace(proxy)
+		if proxy == "" {
+			continue
+		}
+		if strings.Contains(proxy, "/") {
+			if _, network, err := net.ParseCIDR(proxy); err == nil && network.Contains(ip) {
+				return true
+			}
+		} else if proxyIp := net.ParseIP(proxy); proxyIp != nil && proxyIp.Equal(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// verifyClientCertificate returns the issuer of the client certificate in
+// the chain that leads to one of the CAs.
+func verifyClientCertificate(config *ClientCertLoginConfig, certificates []*x509.Certificate, now time.Time) (*x509.Certificate, error) {
+	roots := x509.NewCertPool()
+	if !roots.AppendCertsFromPEM([]byte(config.CaCertificates)) {
+		return nil, fmt.Errorf("no CA certificate is configured for certificate login")
+	}
+	intermediates := x509.NewCertPool()
+	for _, certificate := range certificates[1:] {
+		intermediates.AddCert(certificate)
+	}
+
+	chains, err := certificates[0].Verify(x509.VerifyOptions{
+		Roots:         roots,
+		Intermediates: intermediates,
+		CurrentTime:   now,
+		KeyUsages:     []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	chain := chains[0]
+	if len(chain) < 2 {
+		// the client certificate is itself one of the CAs
+		return chain[0], nil
+	}
+	return chain[1], nil
+}
+
+func fetchClientCertRevocation(request *http.Request) ([]byte, error) {
+	resp, err := clientCertHttpClient.Do(request)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%s returned status: %d", request.URL.String(), resp.StatusCode)
+	}
+	return io.ReadAll(io.LimitReader(resp.Body, 10*1024*1024))
+}
+
+func checkClientCertOcsp(certificate *x509.Certificate, issuer *x509.Certificate) error {
+	if len(certificate.OCSPServer) == 0 {
+		return fmt.Errorf("the client certificate has no OCSP server")
+	}
+
+	ocspRequest, err := ocsp.CreateRequest(certificate, issuer, nil)
+	if err != nil {
+		return err
+	}
+	request, err := http.NewRequest("POST", certificate.OCSPServer[0], bytes.NewReader(ocspRequest))
+	if err != nil {
+		return err
+	}
+	request.Header.Set("Content-Type", "application/ocsp-request")
+	data, err := fetchClientCertRevocation(request)
+	if err != nil {
+		return fmt.Errorf("the OCSP check failed, %s", err.Error())
+	}
+
+	ocspResponse, err := ocsp.ParseResponseForCert(data, certificate, issuer)
+	if err != nil {
+		return fmt.Errorf("the OCSP response is invalid, %s", err.Error())
+	}
+	switch ocspResponse.Status {
+	case ocsp.Good:
+		return nil
+	case ocsp.Revoked:
+		return fmt.Errorf("the client certificate was revoked at %s", ocspResponse.RevokedAt.Format(time.RFC3339))
+	default:
+		return fmt.Errorf("the OCSP server doesn't know the client certificate")
+	}
+}
+
+// checkClientCertCrl checks the certificate against a CRL of the issuer,
+// the CRL must be signed by the issuer and current.
+func checkClientCertCrl(certificate *x509.Certificate, issuer *x509.Certificate, crlData []byte, now time.Time) error {
+	crl, err := x509.ParseCRL(crlData)
+	if err != nil {
+		return fmt.Errorf("the CRL is invalid, %s", err.Error())
+	}
+	err = issuer.CheckCRLSignature(crl)
+	if err != nil {
+		return fmt.Errorf("the CRL is not signed by the issuer, %s", err.Error())
+	}
+	if crl.HasExpired(now) {
+		return fmt.Errorf("the CRL has expired")
+	}
+
+	for _, revoked := range crl.TBSCertList.RevokedCertificates {
+		if revoked.SerialNumber.Cmp(certificate.SerialNumber) == 0 {
+			return fmt.Errorf("the client certificate was revoked at %s", revoked.RevocationTime.Format(time.RFC3339))
+		}
+	}
+	return nil
+}
+
+func checkClientCertRevocation(config *ClientCertLoginConfig, certificate *x509.Certificate, issuer *x509.Certificate) error {
+	if config.CheckOcsp {
+		err := checkClientCertOcsp(certificate, issuer)
+		if err != nil {
+			return err
+		}
+	}
+
+	if config.CheckCrl {
+		if len(certificate.CRLDistributionPoints) == 0 {
+			return fmt.Errorf("the client certificate has no CRL distribution point")
+		}
+
+		request, err := http.NewRequest("GET", certificate.CRLDistributionPoints[0], nil)
+		if err != nil {
+			return err
+		}
+		crlData, err := fetchClientCertRevocation(request)
+		if err != nil {
+			return fmt.Errorf("the CRL check failed, %s", err.Error())
+		}
+		err = checkClientCertCrl(certificate, issuer, crlData, time.Now())
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// getClientCertUpn returns the user principal name of the subject
+// alternative names, which Windows smart cards are mapped by.
+func getClientCertUpn(certificate *x509.Certificate) string {
+	for _, extension := range certificate.Extensions {
+		if !extension.Id.Equal(oidSubjectAltName) {
+			continue
+		}
+
+		var names asn1.RawValue
+		_, err := asn1.Unmarshal(extension.Value, &names)
+		if err != nil {
+			return ""
+		}
+		rest := names.Bytes
+		for len(rest) > 0 {
+			var name asn1.RawValue
+			rest, err = asn1.Unmarshal(rest, &name)
+			if err != nil {
+				return ""
+			}
+			// otherName [0] { type-id OID, value [0] EXPLICIT ANY }
+			if name.Class != asn1.ClassContextSpecific || name.Tag != 0 {
+				continue
+			}
+
+			var typeId asn1.ObjectIdentifier
+			valueBytes, err := asn1.Unmarshal(name.Bytes, &typeId)
+			if err != nil || !typeId.Equal(oidUserPrincipalName) {
+				continue
+			}
+			var value asn1.RawValue
+			_, err = asn1.Unmarshal(valueBytes, &value)
+			if err != nil {
+				continue
+			}
+			var upn string
+			_, err = asn1.UnmarshalWithParams(value.Bytes, &upn, "utf8")
+			if err == nil {
+				return upn
+			}
+		}
+	}
+	return ""
+}
+
+func getClientCertField(certificate *x509.Certificate, field string) string {
+	switch field {
+	case ClientCertFieldEmail:
+		if len(certificate.EmailAddresses) > 0 {
+			return certificate.EmailAddresses[0]
+		}
+		for _, name := range certificate.Subject.Names {
+			if value, ok := name.Value.(string); ok && name.Type.Equal(oidEmailAddress) {
+				return value
+			}
+		}
+		return ""
+	case ClientCertFieldUpn:
+		return getClientCertUpn(certificate)
+	case ClientCertFieldSerialNumber:
+		return certificate.Subject.SerialNumber
+	default:
+		return certificate.Subject.CommonName
+	}
+}
+
+// CheckClientCertLogin returns the user of the application that the client
+// certificate of the request is mapped to.
+func CheckClientCertLogin(application *Application, request *http.Request) (*User, error) {
+	config := application.ClientCertLogin
+	if config == nil || !config.Enabled {
+		return nil, fmt.Errorf("certificate login is not enabled for the application: %s", application.Name)
+	}
+
+	certificates, err := getClientCertificates(config, request)
+	if err != nil {
+		return nil, err
+	}
+	issuer, err := verifyClientCertificate(config, certificates, time.Now())
+	if err != nil {
+		return nil, fmt.Errorf("the client certificate is not trusted, %s", err.Error())
+	}
+	err = checkClientCertRevocation(config, certificates[0], issuer)
+	if err != nil {
+		return nil, err
+	}
+
+	value := getClientCertField(certificates[0], config.CertField)
+	if value == "" {
+		return nil, fmt.Errorf("the client certificate has no %s", config.CertField)
+	}
+	column, ok := clientCertUserColumns[config.UserField]
+	if !ok {
+		column = "name"
+	}
+
+	user := GetUserByField(application.Organization, column, value)
+	if user == nil || user.IsDeleted {
+		return nil, fmt.Errorf("no user of the organization: %s has the %s: %s", application.Organization, strings.ToLower(config.UserField), value)
+	}
+	if user.IsForbidden {
+		return nil, fmt.Errorf("the user: %s is forbidden to sign in", user.GetId())
+	}
+	return user, nil
+}
@@ -0,0 +1,178 @@
+// Copyright 2023 The Casdoor Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package object
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"encoding/base64"
+	"encoding/pem"
+	"math/big"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newClientCertTestCert(t *testing.T, template *x509.Certificate, parent *x509.Certificate, parentKey *ecdsa.PrivateKey) (*x509.Certificate, *ecdsa.PrivateKey) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.Nil(t, err)
+	if parent == nil {
+		parent, parentKey = template, key
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, parent, &key.PublicKey, parentKey)
+	assert.Nil(t, err)
+	certificate, err := x509.ParseCertificate(der)
+	assert.Nil(t, err)
+	return certificate, key
+}
+
+func newClientCertTestUpnExtension(t *testing.T, upn string) pkix.Extension {
+	value, err := asn1.MarshalWithParams(upn, "utf8")
+	assert.Nil(t, err)
+	explicitValue, err := asn1.Marshal(asn1.RawValue{Class: asn1.ClassContextSpecific, Tag: 0, IsCompound: true, Bytes: value})
+	assert.Nil(t, err)
+	typeId, err := asn1.Marshal(oidUserPrincipalName)
+	assert.Nil(t, err)
+	otherName, err := asn1.Marshal(asn1.RawValue{Class: asn1.ClassContextSpecific, Tag: 0, IsCompound: true, Bytes: append(typeId, explicitValue...)})
+	assert.Nil(t, err)
+	names, err := asn1.Marshal(asn1.RawValue{Class: asn1.ClassUniversal, Tag: asn1.TagSequence, IsCompound: true, Bytes: otherName})
+	assert.Nil(t, err)
+	return pkix.Extension{Id: oidSubjectAltName, Value: names}
+}
+
+func TestClientCertLogin(t *testing.T) {
+	now := time.Now()
+	ca, caKey := newClientCertTestCert(t, &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "Test CA"},
+		NotBefore:             now.Add(-time.Hour),
+		NotAfter:              now.Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageCRLSign,
+	}, nil, nil)
+	client, _ := newClientCertTestCert(t, &x509.Certificate{
+		SerialNumber:    big.NewInt(2),
+		Subject:         pkix.Name{CommonName: "alice", SerialNumber: "110101"},
+		NotBefore:       now.Add(-time.Hour),
+		NotAfter:        now.Add(time.Hour),
+		ExtKeyUsage:     []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+		ExtraExtensions: []pkix.Extension{newClientCertTestUpnExtension(t, "alice@corp.example.com")},
+	}, ca, caKey)
+	server, _ := newClientCertTestCert(t, &x509.Certificate{
+		SerialNumber: big.NewInt(3),
+		Subject:      pkix.Name{CommonName: "server"},
+		NotBefore:    now.Add(-time.Hour),
+		NotAfter:     now.Add(time.Hour),
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}, ca, caKey)
+
+	caPem := string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: ca.Raw}))
+	clientPem := string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: client.Raw}))
+	config := &ClientCertLoginConfig{CaCertificates: caPem}
+
+	t.Run("header", func(t *testing.T) {
+		scenarios := []struct {
+			description string
+			value       string
+		}{
+			{"Escaped PEM", url.QueryEscape(clientPem)},
+			{"PEM", clientPem},
+			{"One line PEM", strings.ReplaceAll(clientPem, "\n", " ")},
+			{"Base64 DER", base64.StdEncoding.EncodeToString(client.Raw) + "," + base64.StdEncoding.EncodeToString(ca.Raw)},
+		}
+		for _, scenery := range scenarios {
+			t.Run(scenery.description, func(t *testing.T) {
+				certificates, err := parseClientCertHeader(scenery.value)
+				assert.Nil(t, err)
+				assert.Equal(t, client.Raw, certificates[0].Raw)
+			})
+		}
+
+		_, err := parseClientCertHeader("not a certificate")
+		assert.NotNil(t, err)
+	})
+
+	t.Run("trusted proxies", func(t *testing.T) {
+		proxyConfig := &ClientCertLoginConfig{Header: "X-Ssl-Client-Cert", TrustedProxies: "10.0.0.1, 192.168.0.0/16"}
+		request := &http.Request{Header: http.Header{}}
+		request.Header.Set("X-Ssl-Client-Cert", url.QueryEscape(clientPem))
+
+		for _, remoteAddr := range []string{"10.0.0.1:4321", "192.168.3.4:4321"} {
+			request.RemoteAddr = remoteAddr
+			certificates, err := getClientCertificates(proxyConfig, request)
+			assert.Nil(t, err, remoteAddr)
+			assert.Equal(t, client.Raw, certificates[0].Raw, remoteAddr)
+		}
+
+		request.RemoteAddr = "10.0.0.2:4321"
+		_, err := getClientCertificates(proxyConfig, request)
+		assert.NotNil(t, err, "untrusted peer")
+		request.RemoteAddr = "10.0.0.1:4321"
+		_, err = getClientCertificates(&ClientCertLoginConfig{Header: "X-Ssl-Client-Cert"}, request)
+		assert.NotNil(t, err, "no trusted proxy")
+	})
+
+	t.Run("verify", func(t *testing.T) {
+		issuer, err := verifyClientCertificate(config, []*x509.Certificate{client}, now)
+		assert.Nil(t, err)
+		assert.Equal(t, ca.Raw, issuer.Raw)
+
+		_, err = verifyClientCertificate(config, []*x509.Certificate{client}, now.Add(2*time.Hour))
+		assert.NotNil(t, err, "expired")
+		_, err = verifyClientCertificate(config, []*x509.Certificate{server}, now)
+		assert.NotNil(t, err, "not for client auth")
+		_, err = verifyClientCertificate(&ClientCertLoginConfig{CaCertificates: clientPem}, []*x509.Certificate{ca}, now)
+		assert.NotNil(t, err, "untrusted")
+	})
+
+	t.Run("crl", func(t *testing.T) {
+		revokedCrl, err := ca.CreateCRL(rand.Reader, caKey, []pkix.RevokedCertificate{{SerialNumber: client.SerialNumber, RevocationTime: now}}, now, now.Add(time.Hour))
+		assert.Nil(t, err)
+		emptyCrl, err := ca.CreateCRL(rand.Reader, caKey, nil, now, now.Add(time.Hour))
+		assert.Nil(t, err)
+
+		assert.NotNil(t, checkClientCertCrl(client, ca, revokedCrl, now), "revoked")
+		assert.Nil(t, checkClientCertCrl(client, ca, emptyCrl, now))
+		assert.NotNil(t, checkClientCertCrl(client, ca, emptyCrl, now.Add(2*time.Hour)), "expired CRL")
+		assert.NotNil(t, checkClientCertCrl(client, server, emptyCrl, now), "wrong issuer")
+	})
+
+	t.Run("field", func(t *testing.T) {
+		scenarios := []struct {
+			field    string
+			expected string
+		}{
+			{ClientCertFieldCommonName, "alice"},
+			{ClientCertFieldUpn, "alice@corp.example.com"},
+			{ClientCertFieldSerialNumber, "110101"},
+			{ClientCertFieldEmail, ""},
+		}
+		for _, scenery := range scenarios {
+			t.Run(scenery.field, func(t *testing.T) {
+				assert.Equal(t, scenery.expected, getClientCertField(client, scenery.field))
+			})
+		}
+	})
+}
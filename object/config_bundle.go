@@ -0,0 +1,417 @@
+// Copyright 2023 The Casdoor Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package object
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/casdoor/casdoor/util"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	ConfigActionCreate    = "create"
+	ConfigActionUpdate    = "update"
+	ConfigActionUnchanged = "unchanged"
+)
+
+// ConfigBundle holds all configuration objects except users, in the order
+// they are applied so that referenced objects always exist first.
+type ConfigBundle struct {
+	Organizations []*Organization `json:"organizations"`
+	Certs         []*Cert         `json:"certs"`
+	Providers     []*Provider     `json:"providers"`
+	Applications  []*Application  `json:"applications"`
+	Models        []*Model        `json:"models"`
+	Roles         []*Role         `json:"roles"`
+	Permissions   []*Permission   `json:"permissions"`
+}
+
+type ConfigChange struct {
+	Type   string   `json:"type"`
+	Id     string   `json:"id"`
+	Action string   `json:"action"`
+	Fields []string `json:"fields"`
+}
+
+type configItem struct {
+	typ string
+	id  string
+	obj interface{}
+}
+
+// ExportConfigBundle exports the configuration of all organizations. Secrets
+// are replaced by "***" unless includeSecrets is set, and applying such a
+// bundle keeps the secrets that already exist in the target.
+func ExportConfigBundle(includeSecrets bool) *ConfigBundle {
+	bundle := &ConfigBundle{
+		Organizations: GetOrganizations(""),
+		Certs:         GetCerts(""),
+		Providers:     GetProviders(""),
+		Applications:  GetApplications(""),
+		Models:        GetModels(""),
+		Roles:         GetRoles(""),
+		Permissions:   GetPermissions(""),
+	}
+
+	for _, item := range bundle.getItems() {
+		sanitizeConfigObject(item.obj)
+		if !includeSecrets {
+			maskConfigObject(item.obj)
+		}
+
+		// the master password is stored hashed, applying the hash would hash it again
+		if organization, ok := item.obj.(*Organization); ok && organization.MasterPassword != "" {
+			organization.MasterPassword = "***"
+		}
+	}
+
+	return bundle
+}
+
+// ParseConfigBundle parses a bundle in either YAML or JSON.
+func ParseConfigBundle(data []byte) (*ConfigBundle, error) {
+	bundle := &ConfigBundle{}
+	err := yaml.Unmarshal(data, bundle)
+	if err != nil {
+		return nil, err
+	}
+
+	return bundle, nil
+}
+
+func (bundle *ConfigBundle) ToYaml() ([]byte, error) {
+	return yaml.Marshal(bundle)
+}
+
+// ExportConfigBundleToFile writes the bundle as JSON when the path ends with
+// ".json", otherwise as YAML.
+func ExportConfigBundleToFile(path string, includeSecrets bool) error {
+	bundle := ExportConfigBundle(includeSecrets)
+
+	var data []byte
+	var err error
+	if strings.HasSuffix(path, ".json") {
+		data, err = json.MarshalIndent(bundle, "", "  ")
+	} else {
+		data, err = bundle.ToYaml()
+	}
+	if err != nil {
+		return err
+	}
+
+	util.WriteStringToPath(string(data), path)
+	return nil
+}
+
+func ApplyConfigBundleFromFile(path string, dryRun bool) ([]*ConfigChange, error) {
+	bundle, err := ParseConfigBundle([]byte(util.ReadStringFromPath(path)))
+	if err != nil {
+		return nil, err
+	}
+
+	return ApplyConfigBundle(bundle, dryRun)
+}
+
+func (bundle *ConfigBundle) getItems() []*configItem {
+	items := []*configItem{}
+	for _, organization := range bundle.Organizations {
+		items = append(items, &configItem{"organization", util.GetId(organization.Owner, organization.Name), organization})
+	}
+	for _, cert := range bundle.Certs {
+		items = append(items, &configItem{"cert", util.GetId(cert.Owner, cert.Name), cert})
+	}
+	for _, provider := range bundle.Providers {
+		items = append(items, &configItem{"provider", util.GetId(provider.Owner, provider.Name), provider})
+	}
+	for _, application := range bundle.Applications {
+		items = append(items, &configItem{"application", util.GetId(application.Owner, application.Name), application})
+	}
+	for _, model := range bundle.Models {
+		items = append(items, &configItem{"model", util.GetId(model.Owner, model.Name), model})
+	}
+	for _, role := range bundle.Roles {
+		items = append(items, &configItem{"role", util.GetId(role.Owner, role.Name), role})
+	}
+	for _, permission := range bundle.Permissions {
+		items = append(items, &configItem{"permission", util.GetId(permission.Owner, permission.Name), permission})
+	}
+	return items
+}
+
+// ApplyConfigBundle creates or updates every object of the bundle that
+// differs from the database. Objects missing from the bundle are left as
+// they are. With dryRun set nothing is written and only the plan is returned.
+func ApplyConfigBundle(bundle *ConfigBundle, dryRun bool) ([]*ConfigChange, error) {
+	changes := []*ConfigChange{}
+	for _, item := range bundle.getItems() {
+		if strings.HasPrefix(item.id, "/") || strings.HasSuffix(item.id, "/") {
+			return changes, fmt.Errorf("the %s: %s must have both owner and name", item.typ, item.id)
+		}
+
+		existing := getConfigObject(item.typ, item.id)
+		if existing != nil {
+			sanitizeConfigObject(existing)
+		}
+		prepareConfigObject(item.obj, existing)
+
+		change := &ConfigChange{Type: item.typ, Id: item.id, Fields: []string{}}
+		if existing == nil {
+			change.Action = ConfigActionCreate
+		} else {
+			change.Fields = getChangedFields(existing, item.obj)
+			change.Action = ConfigActionUpdate
+			if len(change.Fields) == 0 {
+				change.Action = ConfigActionUnchanged
+			}
+		}
+		changes = append(changes, change)
+
+		if dryRun || change.Action == ConfigActionUnchanged {
+			continue
+		}
+
+		err := writeConfigObject(item.typ, item.id, item.obj, existing == nil)
+		if err != nil {
+			return changes, err
+		}
+	}
+
+	return changes, nil
+}
+
+func getConfigObject(typ string, id string) interface{} {
+	var obj interface{}
+	switch typ {
+	case "organization":
+		obj = GetOrganization(id)
+	case "cert":
+		obj = GetCert(id)
+	case "provider":
+		obj = GetProvider(id)
+	case "application":
+		obj = GetApplication(id)
+	case "model":
+		obj = GetModel(id)
+	case "role":
+		obj = GetRole(id)
+	case "permission":
+		obj = GetPermission(id)
+	}
+
+	if isNil(obj) {
+		return nil
+	}
+	return obj
+}
+
+func writeConfigObject(typ string, id string, obj interface{}, created bool) error {
+	var err error
+	switch typ {
+	case "organization":
+		_, err = UpsertOrganization(id, obj.(*Organization), nil)
+	case "cert":
+		_, err = UpsertCert(id, obj.(*Cert), nil)
+	case "provider":
+		_, err = UpsertProvider(id, obj.(*Provider), nil)
+	case "application":
+		_, err = UpsertApplication(id, obj.(*Application), nil)
+	case "model":
+		model := obj.(*Model)
+		if created {
+			model.CreatedTime = util.GetCurrentTime()
+			AddModel(model)
+		} else {
+			model.CreatedTime = GetModel(id).CreatedTime
+			err = UpdateModelWithCheck(id, model)
+		}
+	case "role":
+		role := obj.(*Role)
+		if created {
+			role.CreatedTime = util.GetCurrentTime()
+			AddRole(role)
+		} else {
+			role.CreatedTime = GetRole(id).CreatedTime
+			UpdateRole(id, role)
+		}
+	case "permission":
+		permission := obj.(*Permission)
+		if created {
+			permission.CreatedTime = util.GetCurrentTime()
+			AddPermission(permission)
+		} else {
+			permission.CreatedTime = GetPermission(id).CreatedTime
+			UpdatePermission(id, permission)
+		}
+	}
+
+	if err != nil {
+		return fmt.Errorf("failed to apply the %s: %s, %s", typ, id, err.Error())
+	}
+	return nil
+}
+
+// sanitizeConfigObject drops what is specific to a deployment or filled in
+// from other tables, so that bundles compare equal across environments.
+func sanitizeConfigObject(obj interface{}) {
+	switch o := obj.(type) {
+	case *Organization:
+		o.CreatedTime = ""
+		o.Version = 0
+		o.DomainToken = ""
+		o.IsDomainVerified = false
+		for _, emailDomain := range o.EmailDomains {
+			emailDomain.Token = ""
+			emailDomain.IsVerified = false
+		}
+	case *Cert:
+		o.CreatedTime = ""
+		o.Version = 0
+		o.Csr = ""
+		o.ExpireTime = ""
+		o.ExpiryAlertDays = 0
+	case *Provider:
+		o.CreatedTime = ""
+		o.Version = 0
+	case *Application:
+		o.CreatedTime = ""
+		o.Version = 0
+		o.OrganizationObj = nil
+		for _, providerItem := range o.Providers {
+			providerItem.Provider = nil
+		}
+	case *Model:
+		o.CreatedTime = ""
+	case *Role:
+		o.CreatedTime = ""
+	case *Permission:
+		o.CreatedTime = ""
+	}
+}
+
+func maskConfigObject(obj interface{}) {
+	switch o := obj.(type) {
+	case *Cert:
+		if o.PrivateKey != "" {
+			o.PrivateKey = "***"
+		}
+	case *Provider:
+		if o.ClientSecret != "" {
+			o.ClientSecret = "***"
+		}
+		if o.ClientSecret2 != "" {
+			o.ClientSecret2 = "***"
+		}
+	case *Application:
+		if o.ClientSecret != "" {
+			o.ClientSecret = "***"
+		}
+		maskAttestationConfig(o.Attestation)
+	}
+}
+
+// prepareConfigObject handles the "***" placeholders of masked secrets. The
+// update functions of organizations, providers and applications already skip
+// them, certs get the existing private key back, and new objects get empty
+// secrets so that fresh ones are generated where supported.
+func prepareConfigObject(obj interface{}, existing interface{}) {
+	sanitizeConfigObject(obj)
+
+	switch o := obj.(type) {
+	case *Organization:
+		if existing == nil {
+			if o.MasterPassword == "***" {
+				o.MasterPassword = ""
+			}
+			if o.AccountItems == nil {
+				o.AccountItems = getBuiltInAccountItems()
+			}
+		}
+	case *Cert:
+		if o.PrivateKey == "***" {
+			if existing != nil {
+				o.PrivateKey = existing.(*Cert).PrivateKey
+			} else {
+				o.PrivateKey = ""
+				o.Certificate = ""
+			}
+		}
+	case *Provider:
+		if existing == nil {
+			if o.ClientSecret == "***" {
+				o.ClientSecret = ""
+			}
+			if o.ClientSecret2 == "***" {
+				o.ClientSecret2 = ""
+			}
+		}
+	case *Application:
+		if existing == nil && o.ClientSecret == "***" {
+			o.ClientSecret = ""
+		}
+		if existing == nil && o.Attestation != nil && o.Attestation.PlayDecryptionKey == "***" {
+			o.Attestation.PlayDecryptionKey = ""
+		}
+	}
+}
+
+func getChangedFields(oldObj interface{}, newObj interface{}) []string {
+	oldMap := toJsonMap(oldObj)
+	newMap := toJsonMap(newObj)
+
+	fields := []string{}
+	for key, value := range newMap {
+		// a masked secret keeps the existing value
+		if value == "***" {
+			continue
+		}
+		if !reflect.DeepEqual(oldMap[key], value) {
+			fields = append(fields, key)
+		}
+	}
+	for key := range oldMap {
+		if _, ok := newMap[key]; !ok {
+			fields = append(fields, key)
+		}
+	}
+
+	sort.Strings(fields)
+	return fields
+}
+
+func toJsonMap(obj interface{}) map[string]interface{} {
+	data, err := json.Marshal(obj)
+	if err != nil {
+		panic(err)
+	}
+
+	res := map[string]interface{}{}
+	err = json.Unmarshal(data, &res)
+	if err != nil {
+		panic(err)
+	}
+
+	// an empty list and a missing list are the same for a declarative bundle
+	for key, value := range res {
+		if list, ok := value.([]interface{}); ok && len(list) == 0 {
+			res[key] = nil
+		}
+	}
+	return res
+}
@@ -0,0 +1,316 @@
+// Copyright 2023 The Casdoor Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package object
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"time"
+
+	"github.com/beego/beego/logs"
+	"github.com/casdoor/casdoor/i18n"
+	"github.com/casdoor/casdoor/util"
+	"github.com/thanhpk/randstr"
+	"github.com/xorm-io/core"
+)
+
+const (
+	ContactChangeStatePending  = "Pending"
+	ContactChangeStateApplied  = "Applied"
+	ContactChangeStateUndone   = "Undone"
+	ContactChangeStateCanceled = "Canceled"
+	ContactChangeStateFailed   = "Failed"
+)
+
+const (
+	defaultContactChangeHoldHours = 24
+	contactChangeUndoDays         = 7
+	contactChangeJobInterval      = time.Minute
+)
+
+// ContactChange is a change of the email or the phone of a user. The new
+// value is verified with a code before the change is requested, the old
+// value stays active until the change is confirmed from the old address or
+// the hold period of the organization passes, and the old address can undo
+// the change until a few days after it is applied.
+type ContactChange struct {
+	Owner       string `xorm:"varchar(100) notnull pk" json:"owner"`
+	Name        string `xorm:"varchar(100) notnull pk" json:"name"`
+	CreatedTime string `xorm:"varchar(100)" json:"createdTime"`
+
+	User             string `xorm:"varchar(100) index" json:"user"`
+	Type             string `xorm:"varchar(100)" json:"type"`
+	OldValue         string `xorm:"varchar(100)" json:"oldValue"`
+	NewValue         string `xorm:"varchar(100)" json:"newValue"`
+	State            string `xorm:"varchar(100) index" json:"state"`
+	ConfirmTokenHash string `xorm:"varchar(100) index" json:"-"`
+	UndoTokenHash    string `xorm:"varchar(100) index" json:"-"`
+	ApplyTime        string `xorm:"varchar(100)" json:"applyTime"`
+	AppliedTime      string `xorm:"varchar(100)" json:"appliedTime"`
+	UndoExpireTime   string `xorm:"varchar(100)" json:"undoExpireTime"`
+	ClientIp         string `xorm:"varchar(100)" json:"clientIp"`
+}
+
+func (organization *Organization) getContactChangeHoldHours() int {
+	if organization == nil || organization.ContactChangeHoldHours <= 0 {
+		return defaultContactChangeHoldHours
+	}
+	return organization.ContactChangeHoldHours
+}
+
+func getContactChangeTokenHash(token string) string {
+	hash := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(hash[:])
+}
+
+func getUserContact(user *User, typ string) string {
+	if typ == "phone" {
+		return user.Phone
+	}
+	return user.Email
+}
+
+func getContactChanges(owner string, user string, state string) []*ContactChange {
+	contactChanges := []*ContactChange{}
+	err := adapter.Engine.Desc("created_time").Find(&contactChanges, &ContactChange{Owner: owner, User: user, State: state})
+	if err != nil {
+		panic(err)
+	}
+
+	return contactChanges
+}
+
+func GetContactChanges(owner string, user string) []*ContactChange {
+	return getContactChanges(owner, user, "")
+}
+
+func getContactChangeByTokenHash(confirmTokenHash string, undoTokenHash string) *ContactChange {
+	contactChange := ContactChange{ConfirmTokenHash: confirmTokenHash, UndoTokenHash: undoTokenHash}
+	existed, err := adapter.Engine.Get(&contactChange)
+	if err != nil {
+		panic(err)
+	}
+
+	if existed {
+		return &contactChange
+	}
+	return nil
+}
+
+// updateContactChangeState moves the change on only if it is still in the
+// given state, so that a token can't be used twice.
+func updateContactChangeState(contactChange *ContactChange, fromState string, cols ...string) bool {
+	affected, err := adapter.Engine.ID(core.PK{contactChange.Owner, contactChange.Name}).Where("state = ?", fromState).Cols(append(cols, "state")...).Update(contactChange)
+	if err != nil {
+		panic(err)
+	}
+	return affected != 0
+}
+
+func addContactChangeRecord(contactChange *ContactChange, clientIp string, action string) {
+	AddRecord(&Record{
+		Name:         util.GenerateId(),
+		CreatedTime:  util.GetCurrentTime(),
+		Organization: contactChange.Owner,
+		ClientIp:     clientIp,
+		User:         contactChange.User,
+		Method:       "POST",
+		RequestUri:   fmt.Sprintf("/api/get-contact-changes?id=%s", util.GetId(contactChange.Owner, contactChange.User)),
+		Action:       action,
+	})
+}
+
+// RequestContactChange requests a change to a new value that has already been
+// verified. A user without an old value gets the new one immediately,
+// otherwise the old address is notified with the links to confirm and undo
+// the change and the change is pending. Pending changes of the same type are
+// replaced.
+func RequestContactChange(user *User, typ string, newValue string, clientIp string, host string) *ContactChange {
+	now := time.Now()
+	contactChange := &ContactChange{
+		Owner:       user.Owner,
+		Name:        util.GenerateId(),
+		CreatedTime: now.Format(time.RFC3339),
+		User:        user.Name,
+		Type:        typ,
+		OldValue:    getUserContact(user, typ),
+		NewValue:    newValue,
+		State:       ContactChangeStatePending,
+		ClientIp:    clientIp,
+	}
+
+	for _, pending := range getContactChanges(user.Owner, user.Name, ContactChangeStatePending) {
+		if pending.Type == typ {
+			pending.State = ContactChangeStateCanceled
+			updateContactChangeState(pending, ContactChangeStatePending)
+		}
+	}
+
+	confirmToken := randstr.Hex(32)
+	undoToken := randstr.Hex(32)
+	contactChange.ConfirmTokenHash = getContactChangeTokenHash(confirmToken)
+	contactChange.UndoTokenHash = getContactChangeTokenHash(undoToken)
+	holdHours := GetOrganizationByUser(user).getContactChangeHoldHours()
+	contactChange.ApplyTime = now.Add(time.Duration(holdHours) * time.Hour).Format(time.RFC3339)
+
+	_, err := adapter.Engine.Insert(contactChange)
+	if err != nil {
+		panic(err)
+	}
+
+	if contactChange.OldValue == "" {
+		applyContactChange(contactChange, clientIp)
+		return contactChange
+	}
+
+	util.SafeGoroutine(func() { notifyContactChange(user, contactChange, confirmToken, undoToken, host) })
+	return contactChange
+}
+
+// notifyContactChange sends the links to confirm and undo the change to the
+// old address with the providers of the default application.
+func notifyContactChange(user *User, contactChange *ContactChange, confirmToken string, undoToken string, host string) {
+	application, err := GetDefaultApplication(util.GetId("admin", user.Owner))
+	if err != nil {
+		return
+	}
+
+	originFrontend, _ := getOriginFromHost(host)
+	link := fmt.Sprintf("%s/contact-change?token=", originFrontend)
+	lang := user.GetLanguage()
+	content := fmt.Sprintf(i18n.Translate(lang, "security:A change of the %s of your account to %s was requested, it takes effect at %s. Confirm it: %s, or undo it if it wasn't you: %s"),
+		contactChange.Type, contactChange.NewValue, contactChange.ApplyTime, link+confirmToken+"&action=confirm", link+undoToken+"&action=undo")
+
+	if contactChange.Type == "email" {
+		if provider := application.GetEmailProvider(); provider != nil {
+			err = SendEmail(provider, i18n.Translate(lang, "security:Change of your account"), content, contactChange.OldValue, provider.DisplayName)
+		}
+	} else {
+		// the message goes into the template of the SMS provider
+		phone, ok := util.GetE164Number(contactChange.OldValue, user.GetCountryCode(""))
+		if provider := application.GetSmsProvider(); provider != nil && ok {
+			err = SendSms(provider, content, phone)
+		}
+	}
+	if err != nil {
+		logs.Warning(fmt.Sprintf("failed to notify the contact change: %s/%s, %s", contactChange.Owner, contactChange.Name, err.Error()))
+	}
+}
+
+// applyContactChange sets the new value, unless the value of the user was
+// changed otherwise in the meantime or the new value got taken.
+func applyContactChange(contactChange *ContactChange, clientIp string) {
+	now := time.Now()
+	user := getUser(contactChange.Owner, contactChange.User)
+	if user == nil || getUserContact(user, contactChange.Type) != contactChange.OldValue || HasUserByField(user.Owner, contactChange.Type, contactChange.NewValue) {
+		contactChange.State = ContactChangeStateFailed
+		updateContactChangeState(contactChange, ContactChangeStatePending)
+		return
+	}
+
+	contactChange.State = ContactChangeStateApplied
+	contactChange.AppliedTime = now.Format(time.RFC3339)
+	contactChange.UndoExpireTime = now.AddDate(0, 0, contactChangeUndoDays).Format(time.RFC3339)
+	if !updateContactChangeState(contactChange, ContactChangeStatePending, "applied_time", "undo_expire_time") {
+		return
+	}
+
+	SetUserField(user, contactChange.Type, contactChange.NewValue)
+	addContactChangeRecord(contactChange, clientIp, "apply-contact-change")
+}
+
+func getContactChangeByToken(token string, confirm bool) *ContactChange {
+	if token == "" {
+		return nil
+	}
+
+	if confirm {
+		return getContactChangeByTokenHash(getContactChangeTokenHash(token), "")
+	}
+	return getContactChangeByTokenHash("", getContactChangeTokenHash(token))
+}
+
+// ConfirmContactChange applies a pending change with the confirm link sent
+// to the old address.
+func ConfirmContactChange(token string, clientIp string, lang string) (*ContactChange, error) {
+	contactChange := getContactChangeByToken(token, true)
+	if contactChange == nil || contactChange.State != ContactChangeStatePending {
+		return nil, fmt.Errorf(i18n.Translate(lang, "security:The link is invalid or has expired"))
+	}
+
+	applyContactChange(contactChange, clientIp)
+	if contactChange.State != ContactChangeStateApplied {
+		return nil, fmt.Errorf(i18n.Translate(lang, "security:The change can't be applied anymore, please request it again"))
+	}
+	return contactChange, nil
+}
+
+// UndoContactChange cancels a pending change or restores the old value of an
+// applied one with the undo link sent to the old address. Whoever requested
+// the change may have taken over the account, so the user is signed out
+// everywhere.
+func UndoContactChange(token string, clientIp string, lang string) (*ContactChange, error) {
+	contactChange := getContactChangeByToken(token, false)
+	if contactChange == nil {
+		return nil, fmt.Errorf(i18n.Translate(lang, "security:The link is invalid or has expired"))
+	}
+
+	fromState := contactChange.State
+	if fromState != ContactChangeStatePending && (fromState != ContactChangeStateApplied || contactChange.UndoExpireTime < time.Now().Format(time.RFC3339)) {
+		return nil, fmt.Errorf(i18n.Translate(lang, "security:The link is invalid or has expired"))
+	}
+
+	user := getUser(contactChange.Owner, contactChange.User)
+	if user == nil {
+		return nil, fmt.Errorf(i18n.Translate(lang, "security:The link is invalid or has expired"))
+	}
+
+	contactChange.State = ContactChangeStateUndone
+	if !updateContactChangeState(contactChange, fromState) {
+		return nil, fmt.Errorf(i18n.Translate(lang, "security:The link is invalid or has expired"))
+	}
+
+	if fromState == ContactChangeStateApplied && getUserContact(user, contactChange.Type) == contactChange.NewValue {
+		SetUserField(user, contactChange.Type, contactChange.OldValue)
+	}
+	RevokeUserSessions(user)
+	addContactChangeRecord(contactChange, clientIp, "undo-contact-change")
+	return contactChange, nil
+}
+
+func applyPendingContactChanges() error {
+	contactChanges := []*ContactChange{}
+	err := adapter.Engine.Where("state = ? and apply_time <= ?", ContactChangeStatePending, time.Now().Format(time.RFC3339)).Find(&contactChanges)
+	if err != nil {
+		return err
+	}
+
+	for _, contactChange := range contactChanges {
+		applyContactChange(contactChange, contactChange.ClientIp)
+	}
+	return nil
+}
+
+// RunContactChangeJob applies the pending changes whose hold period has
+// passed every minute, once per interval cluster-wide.
+func RunContactChangeJob() {
+	ticker := time.NewTicker(contactChangeJobInterval)
+	defer ticker.Stop()
+	for {
+		RunClusterJob("contact-change", contactChangeJobInterval, applyPendingContactChanges)
+		<-ticker.C
+	}
+}
@@ -0,0 +1,655 @@
+// Copyright 2023 The Casdoor Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package object
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/subtle"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/hex"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/casdoor/casdoor/util"
+	"github.com/thanhpk/randstr"
+	"github.com/xorm-io/core"
+)
+
+const (
+	DeviceGrantType = "device_credentials"
+
+	CertTypeDeviceCa = "Device CA"
+
+	DeviceStateRegistered = "Registered"
+	DeviceStateActive     = "Active"
+	DeviceStateRevoked    = "Revoked"
+
+	DeviceCredentialSecret      = "Secret"
+	DeviceCredentialCertificate = "Certificate"
+
+	maxDeviceRegistrationCount = 1000
+)
+
+// Device is a machine like an IoT sensor or a kiosk that gets tokens of its
+// own, with a secret or a client certificate issued by the organization.
+// Its tokens are bound to it and expire when it's revoked.
+type Device struct {
+	Owner       string `xorm:"varchar(100) notnull pk" json:"owner"`
+	Name        string `xorm:"varchar(100) notnull pk" json:"name"`
+	CreatedTime string `xorm:"varchar(100)" json:"createdTime"`
+
+	DisplayName  string   `xorm:"varchar(100)" json:"displayName"`
+	Id           string   `xorm:"varchar(100) index" json:"id"`
+	Type         string   `xorm:"varchar(100)" json:"type"`
+	Application  string   `xorm:"varchar(100)" json:"application"`
+	SerialNumber string   `xorm:"varchar(100)" json:"serialNumber"`
+	Tags         []string `xorm:"varchar(1000)" json:"tags"`
+	State        string   `xorm:"varchar(100)" json:"state"`
+
+	// only the hash of the secret is kept, the secret is shown once when issued
+	CredentialType        string `xorm:"varchar(100)" json:"credentialType"`
+	SecretHash            string `xorm:"varchar(100)" json:"-"`
+	CertificateSerial     string `xorm:"varchar(100)" json:"certificateSerial"`
+	CertificateExpireTime string `xorm:"varchar(100)" json:"certificateExpireTime"`
+	IssuedTime            string `xorm:"varchar(100)" json:"issuedTime"`
+	LastSeenTime          string `xorm:"varchar(100)" json:"lastSeenTime"`
+	RevokedTime           string `xorm:"varchar(100)" json:"revokedTime"`
+}
+
+// DeviceRegistrationForm registers a batch of devices, named by Prefix and
+// SerialNumbers, or by Prefix and a random suffix when there are none.
+type DeviceRegistrationForm struct {
+	Prefix         string   `json:"prefix"`
+	Count          int      `json:"count"`
+	SerialNumbers  []string `json:"serialNumbers"`
+	Type           string   `json:"type"`
+	Application    string   `json:"application"`
+	Tags           []string `json:"tags"`
+	CredentialType string   `json:"credentialType"`
+}
+
+// DeviceCredential is what is installed on a device, it's only returned when
+// it's issued.
+type DeviceCredential struct {
+	Name        string `json:"name"`
+	Id          string `json:"id"`
+	Secret      string `json:"secret,omitempty"`
+	Certificate string `json:"certificate,omitempty"`
+	PrivateKey  string `json:"privateKey,omitempty"`
+}
+
+// DeviceCredentials are what a device presents to get a token: a secret, or
+// a client certificate in the TLS connection or the client certificate header
+// of the application.
+type DeviceCredentials struct {
+	Secret  string
+	Request *http.Request
+}
+
+func GetDeviceCount(owner, field, value string) int {
+	session := GetSession(owner, -1, -1, field, value, "", "")
+	count, err := session.Count(&Device{})
+	if err != nil {
+		panic(err)
+	}
+
+	return int(count)
+}
+
+func GetDevices(owner string) []*Device {
+	devices := []*Device{}
+	err := adapter.Engine.Desc("created_time").Find(&devices, &Device{Owner: owner})
+	if err != nil {
+		panic(err)
+	}
+
+	return devices
+}
+
+func GetPaginationDevices(owner string, offset, limit int, field, value, sortField, sortOrder string) []*Device {
+	devices := []*Device{}
+	session := GetSession(owner, offset, limit, field, value, sortField, sortOrder)
+	err := session.Find(&devices)
+	if err != nil {
+		panic(err)
+	}
+
+	return devices
+}
+
+func getDevice(owner string, name string) *Device {
+	if owner == "" || name == "" {
+		return nil
+	}
+
+	device := Device{Owner: owner, Name: name}
+	existed, err := adapter.Engine.Get(&device)
+	if err != nil {
+		panic(err)
+	}
+
+	if existed {
+		return &device
+	} else {
+		return nil
+	}
+}
+
+func GetDevice(id string) *Device {
+	owner, name := util.GetOwnerAndNameFromId(id)
+	return getDevice(owner, name)
+}
+
+// UpdateDevice only updates the description of the device, the credentials
+// and the state change with IssueDeviceCredential and RevokeDevice.
+func UpdateDevice(id string, device *Device) bool {
+	owner, name := util.GetOwnerAndNameFromId(id)
+	if getDevice(owner, name) == nil {
+		return false
+	}
+
+	affected, err := adapter.Engine.ID(core.PK{owner, name}).Cols("display_name", "type", "application", "serial_number", "tags").Update(device)
+	if err != nil {
+		panic(err)
+	}
+
+	return affected != 0
+}
+
+func DeleteDevice(device *Device) bool {
+	affected, err := adapter.Engine.ID(core.PK{device.Owner, device.Name}).Delete(&Device{})
+	if err != nil {
+		panic(err)
+	}
+
+	expireDeviceTokens(device)
+	return affected != 0
+}
+
+func (device *Device) GetId() string {
+	return fmt.Sprintf("%s/%s", device.Owner, device.Name)
+}
+
+func getDeviceSecretHash(secret string) string {
+	hash := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(hash[:])
+}
+
+func expireDeviceTokens(device *Device) {
+	_, err := adapter.Engine.Where("expires_in > ?", 0).Cols("expires_in").Update(&Token{ExpiresIn: 0}, &Token{Organization: device.Owner, DeviceId: device.Name})
+	if err != nil {
+		panic(err)
+	}
+}
+
+// generateDeviceCaKeys generates the self-signed certificate of a device CA,
+// unlike the x509 certs for JWT it may sign other certificates.
+func generateDeviceCaKeys(bitSize int, expireInYears int, commonName string, organization string) (string, string, error) {
+	if bitSize <= 0 {
+		bitSize = 2048
+	}
+	if expireInYears <= 0 {
+		expireInYears = 20
+	}
+
+	key, err := rsa.GenerateKey(rand.Reader, bitSize)
+	if err != nil {
+		return "", "", err
+	}
+
+	now := time.Now()
+	template := &x509.Certificate{
+		SerialNumber: getDeviceCertificateSerial(),
+		Subject: pkix.Name{
+			CommonName:   commonName,
+			Organization: []string{organization},
+		},
+		NotBefore:             now,
+		NotAfter:              now.AddDate(expireInYears, 0, 0),
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageCRLSign,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	certificate, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		return "", "", err
+	}
+
+	certificatePem := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: certificate})
+	keyPem := pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)})
+	return string(certificatePem), string(keyPem), nil
+}
+
+func getDeviceCertificateSerial() *big.Int {
+	serial, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	if err != nil {
+		panic(err)
+	}
+	return serial
+}
+
+type deviceCa struct {
+	certificate *x509.Certificate
+	key         *rsa.PrivateKey
+}
+
+func getDeviceCa(organization *Organization) (*deviceCa, error) {
+	if organization.DeviceCa == "" {
+		return nil, fmt.Errorf("the organization: %s has no device CA", organization.Name)
+	}
+	cert := getCert("admin", organization.DeviceCa)
+	if cert == nil || cert.Type != CertTypeDeviceCa {
+		return nil, fmt.Errorf("the cert: %s is not a device CA", organization.DeviceCa)
+	}
+	if cert.Organization != organization.Name {
+		return nil, fmt.Errorf("the device CA: %s doesn't belong to the organization: %s", cert.Name, organization.Name)
+	}
+
+	certificate, err := parseCertificatePem(cert.Certificate)
+	if err != nil {
+		return nil, err
+	}
+	key, err := parseRsaPrivateKeyPem(cert.PrivateKey)
+	if err != nil {
+		return nil, fmt.Errorf("the private key of the device CA: %s is invalid, %s", cert.Name, err.Error())
+	}
+	return &deviceCa{certificate: certificate, key: key}, nil
+}
+
+// issueDeviceCertificate generates the key of the device and signs its
+// certificate, valid for client authentication until the CA expires. The
+// device is identified by the serial of the certificate.
+func issueDeviceCertificate(ca *deviceCa, device *Device, credential *DeviceCredential) error {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return err
+	}
+
+	template := &x509.Certificate{
+		SerialNumber: getDeviceCertificateSerial(),
+		Subject: pkix.Name{
+			CommonName:   device.Name,
+			Organization: []string{device.Owner},
+			SerialNumber: device.SerialNumber,
+		},
+		NotBefore:   time.Now().Add(-time.Minute),
+		NotAfter:    ca.certificate.NotAfter,
+		KeyUsage:    x509.KeyUsageDigitalSignature,
+		ExtKeyUsage: []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+	certificate, err := x509.CreateCertificate(rand.Reader, template, ca.certificate, &key.PublicKey, ca.key)
+	if err != nil {
+		return err
+	}
+	keyDer, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		return err
+	}
+
+	device.CertificateSerial = template.SerialNumber.String()
+	device.CertificateExpireTime = template.NotAfter.Format(time.RFC3339)
+	credential.Certificate = string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: certificate}))
+	credential.PrivateKey = string(pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDer}))
+	return nil
+}
+
+// issueDeviceCredential replaces the credential of the device and activates
+// it. ca is only needed for certificates.
+func issueDeviceCredential(device *Device, credentialType string, ca *deviceCa) (*DeviceCredential, error) {
+	credential := &DeviceCredential{Name: device.Name, Id: device.Id}
+	device.SecretHash = ""
+	device.CertificateSerial = ""
+	device.CertificateExpireTime = ""
+
+	switch credentialType {
+	case DeviceCredentialSecret:
+		credential.Secret = util.GenerateClientSecret()
+		device.SecretHash = getDeviceSecretHash(credential.Secret)
+	case DeviceCredentialCertificate:
+		err := issueDeviceCertificate(ca, device, credential)
+		if err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("the credential type: %s is not supported", credentialType)
+	}
+
+	device.CredentialType = credentialType
+	device.State = DeviceStateActive
+	device.IssuedTime = util.GetCurrentTime()
+	return credential, nil
+}
+
+func getDeviceRegistrationNames(form *DeviceRegistrationForm) ([]string, error) {
+	names := []string{}
+	if len(form.SerialNumbers) != 0 {
+		for _, serialNumber := range form.SerialNumbers {
+			serialNumber = strings.TrimSpace(serialNumber)
+			if serialNumber != "" {
+				names = append(names, form.Prefix+serialNumber)
+			}
+		}
+	} else {
+		for i := 0; i < form.Count; i++ {
+			names = append(names, form.Prefix+randstr.Hex(6))
+		}
+	}
+
+	if len(names) == 0 || len(names) > maxDeviceRegistrationCount {
+		return nil, fmt.Errorf("between 1 and %d devices can be registered at once", maxDeviceRegistrationCount)
+	}
+
+	seen := map[string]bool{}
+	for _, name := range names {
+		if seen[name] {
+			return nil, fmt.Errorf("the device: %s is duplicated", name)
+		}
+		seen[name] = true
+	}
+	return names, nil
+}
+
+// RegisterDevices pre-registers a batch of devices of the organization, and
+// issues their credentials when CredentialType is set. The credentials are
+// returned in the order of the devices and can't be read again afterwards.
+func RegisterDevices(owner string, form *DeviceRegistrationForm) ([]*DeviceCredential, error) {
+	organization := getOrganization("admin", owner)
+	if organization == nil {
+		return nil, fmt.Errorf("the organization: %s doesn't exist", owner)
+	}
+	if form.Application != "" {
+		application := getApplication("admin", form.Application)
+		if application == nil || application.Organization != owner {
+			return nil, fmt.Errorf("the application: %s doesn't exist in the organization: %s", form.Application, owner)
+		}
+	}
+
+	names, err := getDeviceRegistrationNames(form)
+	if err != nil {
+		return nil, err
+	}
+	existing := []*Device{}
+	err = adapter.Engine.Cols("name").Where("owner = ?", owner).In("name", names).Find(&existing)
+	if err != nil {
+		panic(err)
+	}
+	if len(existing) != 0 {
+		return nil, fmt.Errorf("the device: %s already exists", existing[0].Name)
+	}
+
+	var ca *deviceCa
+	if form.CredentialType == DeviceCredentialCertificate {
+		ca, err = getDeviceCa(organization)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	devices := []*Device{}
+	credentials := []*DeviceCredential{}
+	for _, name := range names {
+		device := &Device{
+			Owner:       owner,
+			Name:        name,
+			CreatedTime: util.GetCurrentTime(),
+			DisplayName: name,
+			Id:          util.GenerateId(),
+			Type:        form.Type,
+			Application: form.Application,
+			Tags:        form.Tags,
+			State:       DeviceStateRegistered,
+		}
+		if len(form.SerialNumbers) != 0 {
+			device.SerialNumber = strings.TrimPrefix(name, form.Prefix)
+		}
+		if device.Tags == nil {
+			device.Tags = []string{}
+		}
+
+		if form.CredentialType != "" {
+			credential, err := issueDeviceCredential(device, form.CredentialType, ca)
+			if err != nil {
+				return nil, fmt.Errorf("failed to issue the credential of the device: %s, %s", name, err.Error())
+			}
+			credentials = append(credentials, credential)
+		} else {
+			credentials = append(credentials, &DeviceCredential{Name: device.Name, Id: device.Id})
+		}
+		devices = append(devices, device)
+	}
+
+	session := adapter.Engine.NewSession()
+	defer session.Close()
+	err = session.Begin()
+	if err != nil {
+		panic(err)
+	}
+	for i := 0; i < len(devices); i += 100 {
+		end := i + 100
+		if end > len(devices) {
+			end = len(devices)
+		}
+
+		_, err = session.Insert(devices[i:end])
+		if err != nil {
+			panic(err)
+		}
+	}
+	err = session.Commit()
+	if err != nil {
+		panic(err)
+	}
+
+	return credentials, nil
+}
+
+// IssueDeviceCredential issues a new credential to the device, the previous
+// credential stops working and the tokens got with it expire.
+func IssueDeviceCredential(id string, credentialType string) (*DeviceCredential, error) {
+	device := GetDevice(id)
+	if device == nil {
+		return nil, fmt.Errorf("the device: %s doesn't exist", id)
+	}
+	if device.State == DeviceStateRevoked {
+		return nil, fmt.Errorf("the device: %s is revoked", id)
+	}
+
+	var ca *deviceCa
+	if credentialType == DeviceCredentialCertificate {
+		organization := getOrganization("admin", device.Owner)
+		if organization == nil {
+			return nil, fmt.Errorf("the organization: %s doesn't exist", device.Owner)
+		}
+
+		var err error
+		ca, err = getDeviceCa(organization)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	credential, err := issueDeviceCredential(device, credentialType, ca)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = adapter.Engine.ID(core.PK{device.Owner, device.Name}).Cols("credential_type", "secret_hash", "certificate_serial", "certificate_expire_time", "state", "issued_time").Update(device)
+	if err != nil {
+		panic(err)
+	}
+
+	expireDeviceTokens(device)
+	return credential, nil
+}
+
+// RevokeDevice revokes the credential of the device for good and expires all
+// its tokens. Resource servers that verify the JWT offline still accept the
+// tokens until they expire, introspection rejects them right away.
+func RevokeDevice(id string) bool {
+	device := GetDevice(id)
+	if device == nil {
+		return false
+	}
+
+	device.State = DeviceStateRevoked
+	device.SecretHash = ""
+	device.CertificateSerial = ""
+	device.RevokedTime = util.GetCurrentTime()
+	affected, err := adapter.Engine.ID(core.PK{device.Owner, device.Name}).Cols("state", "secret_hash", "certificate_serial", "revoked_time").Update(device)
+	if err != nil {
+		panic(err)
+	}
+
+	expireDeviceTokens(device)
+	return affected != 0
+}
+
+func checkDeviceCertificate(application *Application, device *Device, request *http.Request) error {
+	config := application.ClientCertLogin
+	if config == nil {
+		config = &ClientCertLoginConfig{}
+	}
+	certificates, err := getClientCertificates(config, request)
+	if err != nil {
+		return err
+	}
+
+	organization := getOrganization("admin", device.Owner)
+	if organization == nil {
+		return fmt.Errorf("the organization: %s doesn't exist", device.Owner)
+	}
+	ca, err := getDeviceCa(organization)
+	if err != nil {
+		return err
+	}
+
+	roots := x509.NewCertPool()
+	roots.AddCert(ca.certificate)
+	_, err = certificates[0].Verify(x509.VerifyOptions{
+		Roots:     roots,
+		KeyUsages: []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	})
+	if err != nil {
+		return err
+	}
+
+	// a certificate issued before the last one, or to another device
+	if certificates[0].SerialNumber.String() != device.CertificateSerial {
+		return fmt.Errorf("the certificate is not the one of the device")
+	}
+	return nil
+}
+
+func checkDeviceCredentials(application *Application, device *Device, credentials *DeviceCredentials) error {
+	switch device.CredentialType {
+	case DeviceCredentialSecret:
+		if credentials.Secret == "" || subtle.ConstantTimeCompare([]byte(getDeviceSecretHash(credentials.Secret)), []byte(device.SecretHash)) != 1 {
+			return fmt.Errorf("device_secret is invalid")
+		}
+		return nil
+	case DeviceCredentialCertificate:
+		if credentials.Request == nil {
+			return fmt.Errorf("no client certificate is provided")
+		}
+		return checkDeviceCertificate(application, device, credentials.Request)
+	default:
+		return fmt.Errorf("the device has no credential")
+	}
+}
+
+// GetDeviceToken returns a token bound to the device, device_id is the name
+// of the device. The token has no refresh token, the device gets a new one
+// with its credential.
+func GetDeviceToken(application *Application, deviceId string, credentials *DeviceCredentials, scope string, host string) (*Token, *TokenError) {
+	// GetOAuthToken skips the grant types check for tagged requests
+	if !IsGrantTypeValid(DeviceGrantType, application.GrantTypes) {
+		return nil, &TokenError{
+			Error:            UnsupportedGrantType,
+			ErrorDescription: fmt.Sprintf("grant_type: %s is not supported in this application", DeviceGrantType),
+		}
+	}
+
+	device := getDevice(application.Organization, deviceId)
+	if device == nil || (device.Application != "" && device.Application != application.Name) {
+		return nil, &TokenError{
+			Error:            InvalidClient,
+			ErrorDescription: "device_id is invalid",
+		}
+	}
+	if device.State != DeviceStateActive {
+		return nil, &TokenError{
+			Error:            InvalidGrant,
+			ErrorDescription: fmt.Sprintf("the device is %s", strings.ToLower(device.State)),
+		}
+	}
+
+	if credentials == nil {
+		credentials = &DeviceCredentials{}
+	}
+	err := checkDeviceCredentials(application, device, credentials)
+	if err != nil {
+		return nil, &TokenError{
+			Error:            InvalidClient,
+			ErrorDescription: err.Error(),
+		}
+	}
+
+	deviceUser := &User{
+		Owner:       device.Owner,
+		Id:          device.Id,
+		Name:        device.Name,
+		DisplayName: device.DisplayName,
+		Type:        "device",
+		Tag:         device.Type,
+	}
+	accessToken, _, tokenName, err := generateJwtToken(application, deviceUser, "", scope, host)
+	if err != nil {
+		return nil, &TokenError{
+			Error:            EndpointError,
+			ErrorDescription: fmt.Sprintf("generate jwt token error: %s", err.Error()),
+		}
+	}
+	token := &Token{
+		Owner:        application.Owner,
+		Name:         tokenName,
+		CreatedTime:  util.GetCurrentTime(),
+		Application:  application.Name,
+		Organization: application.Organization,
+		Code:         util.GenerateClientId(),
+		AccessToken:  accessToken,
+		ExpiresIn:    application.ExpireInHours * hourSeconds,
+		Scope:        scope,
+		TokenType:    "Bearer",
+		CodeIsUsed:   true,
+		DeviceId:     device.Name,
+	}
+	AddToken(token)
+
+	device.LastSeenTime = token.CreatedTime
+	_, err = adapter.Engine.ID(core.PK{device.Owner, device.Name}).Cols("last_seen_time").Update(device)
+	if err != nil {
+		panic(err)
+	}
+	return token, nil
+}
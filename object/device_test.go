@@ -0,0 +1,98 @@
+// Copyright 2023 The Casdoor Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package object
+
+import (
+	"crypto/x509"
+	"testing"
+)
+
+func TestDeviceCredential(t *testing.T) {
+	device := &Device{Owner: "built-in", Name: "sensor-1", Id: "id-1", SerialNumber: "SN1"}
+
+	credential, err := issueDeviceCredential(device, DeviceCredentialSecret, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if device.State != DeviceStateActive || device.SecretHash == credential.Secret {
+		t.Fatalf("unexpected device: %+v", device)
+	}
+	if err = checkDeviceCredentials(nil, device, &DeviceCredentials{Secret: credential.Secret}); err != nil {
+		t.Fatal(err)
+	}
+	if err = checkDeviceCredentials(nil, device, &DeviceCredentials{Secret: "wrong"}); err == nil {
+		t.Fatal("a wrong secret is accepted")
+	}
+
+	caCertificate, caKey, err := generateDeviceCaKeys(2048, 1, "device-ca", "built-in")
+	if err != nil {
+		t.Fatal(err)
+	}
+	certificate, err := parseCertificatePem(caCertificate)
+	if err != nil {
+		t.Fatal(err)
+	}
+	key, err := parseRsaPrivateKeyPem(caKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	credential, err = issueDeviceCredential(device, DeviceCredentialCertificate, &deviceCa{certificate: certificate, key: key})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if device.SecretHash != "" || device.CredentialType != DeviceCredentialCertificate {
+		t.Fatalf("the secret is kept: %+v", device)
+	}
+
+	deviceCertificate, err := parseCertificatePem(credential.Certificate)
+	if err != nil {
+		t.Fatal(err)
+	}
+	roots := x509.NewCertPool()
+	roots.AddCert(certificate)
+	_, err = deviceCertificate.Verify(x509.VerifyOptions{Roots: roots, KeyUsages: []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if deviceCertificate.SerialNumber.String() != device.CertificateSerial || deviceCertificate.Subject.CommonName != "sensor-1" {
+		t.Fatalf("unexpected certificate: %s, %s", deviceCertificate.SerialNumber, deviceCertificate.Subject)
+	}
+}
+
+func TestGetDeviceRegistrationNames(t *testing.T) {
+	names, err := getDeviceRegistrationNames(&DeviceRegistrationForm{Prefix: "kiosk-", SerialNumbers: []string{"A1", " B2 ", ""}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(names) != 2 || names[0] != "kiosk-A1" || names[1] != "kiosk-B2" {
+		t.Fatalf("unexpected names: %v", names)
+	}
+
+	names, err = getDeviceRegistrationNames(&DeviceRegistrationForm{Prefix: "sensor-", Count: 3})
+	if err != nil || len(names) != 3 {
+		t.Fatalf("unexpected names: %v, %v", names, err)
+	}
+
+	for _, form := range []*DeviceRegistrationForm{
+		{Count: 0},
+		{Count: maxDeviceRegistrationCount + 1},
+		{SerialNumbers: []string{"A1", "A1"}},
+	} {
+		if _, err = getDeviceRegistrationNames(form); err == nil {
+			t.Fatalf("the form is accepted: %+v", form)
+		}
+	}
+}
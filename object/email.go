@@ -18,7 +18,9 @@ package object
 
 import (
 	"crypto/tls"
+	"fmt"
 
+	"github.com/casdoor/casdoor/email"
 	"github.com/casdoor/gomail/v2"
 )
 
@@ -36,11 +38,33 @@ func getDialer(provider *Provider) *gomail.Dialer {
 	return dialer
 }
 
+func (provider *Provider) getEmailProvider() email.EmailProvider {
+	return email.GetEmailProvider(provider.Type, provider.ClientId, provider.ClientSecret, provider.ClientSecret2, provider.Host, provider.Domain, provider.RegionId)
+}
+
+func (provider *Provider) getFromAddress() string {
+	if provider.FromAddress != "" {
+		return provider.FromAddress
+	}
+	return provider.ClientId
+}
+
+// SendEmail sends through the API of the provider or with SMTP, the
+// addresses on the suppression list of the owner of the provider are
+// refused.
 func SendEmail(provider *Provider, title string, content string, dest string, sender string) error {
+	if IsEmailSuppressed(provider.Owner, dest) {
+		return fmt.Errorf("the email address: %s is on the suppression list because of a bounce or a complaint", dest)
+	}
+
+	if emailProvider := provider.getEmailProvider(); emailProvider != nil {
+		return emailProvider.Send(provider.getFromAddress(), sender, dest, title, content)
+	}
+
 	dialer := getDialer(provider)
 
 	message := gomail.NewMessage()
-	message.SetAddressHeader("From", provider.ClientId, sender)
+	message.SetAddressHeader("From", provider.getFromAddress(), sender)
 	message.SetHeader("To", dest)
 	message.SetHeader("Subject", title)
 	message.SetBody("text/html", content)
@@ -54,6 +78,10 @@ func SendEmail(provider *Provider, title string, content string, dest string, se
 
 // DailSmtpServer Dail Smtp server
 func DailSmtpServer(provider *Provider) error {
+	if provider.getEmailProvider() != nil {
+		return fmt.Errorf("the provider: %s sends with the API of %s, send a testing email instead", provider.Name, provider.Type)
+	}
+
 	dialer := getDialer(provider)
 
 	sender, err := dialer.Dial()
@@ -0,0 +1,174 @@
+// Copyright 2023 The Casdoor Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package object
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/casdoor/casdoor/i18n"
+	"github.com/casdoor/casdoor/util"
+	"github.com/xorm-io/core"
+)
+
+// EmailDomain is an email domain claimed by an organization, it's verified
+// with the same TXT record as the custom domain of the organization.
+type EmailDomain struct {
+	Domain         string `json:"domain"`
+	Token          string `json:"token"`
+	IsVerified     bool   `json:"isVerified"`
+	EnableAutoJoin bool   `json:"enableAutoJoin"`
+}
+
+func getEmailDomain(email string) string {
+	index := strings.LastIndex(email, "@")
+	if index == -1 {
+		return ""
+	}
+	return normalizeDomain(email[index+1:])
+}
+
+// prepareEmailDomains keeps the tokens and the verification states from
+// oldOrganization, newly claimed domains get a token and need verification.
+func (organization *Organization) prepareEmailDomains(oldOrganization *Organization) {
+	oldEmailDomains := map[string]*EmailDomain{}
+	if oldOrganization != nil {
+		for _, emailDomain := range oldOrganization.EmailDomains {
+			oldEmailDomains[emailDomain.Domain] = emailDomain
+		}
+	}
+
+	emailDomains := []*EmailDomain{}
+	for _, emailDomain := range organization.EmailDomains {
+		emailDomain.Domain = normalizeDomain(emailDomain.Domain)
+		if emailDomain.Domain == "" {
+			continue
+		}
+
+		if oldEmailDomain, ok := oldEmailDomains[emailDomain.Domain]; ok {
+			emailDomain.Token = oldEmailDomain.Token
+			emailDomain.IsVerified = oldEmailDomain.IsVerified
+		} else {
+			emailDomain.Token = util.GenerateId()
+			emailDomain.IsVerified = false
+		}
+		emailDomains = append(emailDomains, emailDomain)
+	}
+	organization.EmailDomains = emailDomains
+}
+
+func (organization *Organization) getEmailDomain(domain string) *EmailDomain {
+	for _, emailDomain := range organization.EmailDomains {
+		if emailDomain.Domain == domain {
+			return emailDomain
+		}
+	}
+	return nil
+}
+
+// IsEmailOfVerifiedDomain returns true if the domain of the email is claimed
+// and verified by the organization.
+func (organization *Organization) IsEmailOfVerifiedDomain(email string) bool {
+	emailDomain := organization.getEmailDomain(getEmailDomain(email))
+	return emailDomain != nil && emailDomain.IsVerified
+}
+
+// GetOrganizationByEmail returns the organization that has verified the
+// domain of the email, it's used for the home realm discovery and the auto
+// join of new users.
+func GetOrganizationByEmail(email string) (*Organization, *EmailDomain) {
+	domain := getEmailDomain(email)
+	if domain == "" {
+		return nil, nil
+	}
+
+	for _, organization := range GetOrganizations("admin") {
+		if emailDomain := organization.getEmailDomain(domain); emailDomain != nil && emailDomain.IsVerified {
+			return organization, emailDomain
+		}
+	}
+	return nil, nil
+}
+
+// VerifyEmailDomain marks the email domain of the organization as verified
+// when the TXT record of the challenge contains its token. A domain can only
+// be verified by one organization.
+func VerifyEmailDomain(organization *Organization, domain string) error {
+	emailDomain := organization.getEmailDomain(normalizeDomain(domain))
+	if emailDomain == nil {
+		return fmt.Errorf("the organization: %s hasn't claimed the email domain: %s", organization.Name, domain)
+	}
+
+	other, _ := GetOrganizationByEmail("@" + emailDomain.Domain)
+	if other != nil && other.Name != organization.Name {
+		return fmt.Errorf("the email domain: %s is already verified by the organization: %s", emailDomain.Domain, other.Name)
+	}
+
+	err := checkDomainChallenge(emailDomain.Domain, emailDomain.Token)
+	if err != nil {
+		return err
+	}
+
+	emailDomain.IsVerified = true
+	_, err = adapter.Engine.ID(core.PK{organization.Owner, organization.Name}).Cols("email_domains").Incr("version").Update(organization)
+	if err != nil {
+		panic(err)
+	}
+
+	deleteCachedObjects(getSharedCacheKey("organization", util.GetId(organization.Owner, organization.Name)))
+	return nil
+}
+
+// GetAutoJoinOrganization returns the organization that a new user with the
+// email joins instead of signupOrganization. Only the built-in organization,
+// whose applications aren't tied to a tenant, hands over its signups.
+func GetAutoJoinOrganization(signupOrganization string, email string) *Organization {
+	if signupOrganization != "built-in" {
+		return nil
+	}
+
+	organization, emailDomain := GetOrganizationByEmail(email)
+	if organization == nil || !emailDomain.EnableAutoJoin || organization.Name == signupOrganization {
+		return nil
+	}
+	return organization
+}
+
+// CheckAdminEmailDomain returns an error message when the organization of
+// the user only allows admins with an email of its verified domains.
+func CheckAdminEmailDomain(user *User, lang string) string {
+	if !user.IsAdmin {
+		return ""
+	}
+
+	organization := getOrganization("admin", user.Owner)
+	if organization == nil || !organization.RequireDomainForAdmin {
+		return ""
+	}
+
+	if !organization.IsEmailOfVerifiedDomain(user.Email) {
+		return i18n.Translate(lang, "check:Only users with an email of a verified domain of the organization can be admins")
+	}
+	return ""
+}
+
+// GetHomeRealmLoginUrl returns the login page of the organization, on its
+// custom domain when it has verified one.
+func GetHomeRealmLoginUrl(organization *Organization) string {
+	if organization.Domain != "" && organization.IsDomainVerified {
+		return fmt.Sprintf("https://%s/login/%s", organization.Domain, organization.Name)
+	}
+	return fmt.Sprintf("/login/%s", organization.Name)
+}
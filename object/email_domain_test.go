@@ -0,0 +1,45 @@
+// Copyright 2023 The Casdoor Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package object
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPrepareEmailDomains(t *testing.T) {
+	oldOrganization := &Organization{
+		EmailDomains: []*EmailDomain{{Domain: "acme.com", Token: "token", IsVerified: true}},
+	}
+	organization := &Organization{
+		EmailDomains: []*EmailDomain{
+			{Domain: "ACME.com.", IsVerified: false, EnableAutoJoin: true},
+			{Domain: "acme.org", Token: "forged", IsVerified: true},
+			{Domain: " "},
+		},
+	}
+
+	organization.prepareEmailDomains(oldOrganization)
+	assert.Len(t, organization.EmailDomains, 2)
+	assert.Equal(t, &EmailDomain{Domain: "acme.com", Token: "token", IsVerified: true, EnableAutoJoin: true}, organization.EmailDomains[0])
+	assert.Equal(t, "acme.org", organization.EmailDomains[1].Domain)
+	assert.False(t, organization.EmailDomains[1].IsVerified)
+	assert.NotEqual(t, "forged", organization.EmailDomains[1].Token)
+
+	assert.True(t, organization.IsEmailOfVerifiedDomain("alice@Acme.com"))
+	assert.False(t, organization.IsEmailOfVerifiedDomain("bob@acme.org"))
+	assert.False(t, organization.IsEmailOfVerifiedDomain("acme.com"))
+}
@@ -0,0 +1,157 @@
+// Copyright 2023 The Casdoor Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package object
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/casdoor/casdoor/util"
+	"github.com/xorm-io/core"
+)
+
+const EmailSuppressionTypeManual = "Manual"
+
+// EmailSuppression is an address no email is sent to with the providers of
+// the owner, added by a bounce or complaint webhook or by an admin. Name is
+// the address in lower case.
+type EmailSuppression struct {
+	Owner       string `xorm:"varchar(100) notnull pk" json:"owner"`
+	Name        string `xorm:"varchar(100) notnull pk" json:"name"`
+	CreatedTime string `xorm:"varchar(100)" json:"createdTime"`
+
+	Provider string `xorm:"varchar(100)" json:"provider"`
+	Type     string `xorm:"varchar(100)" json:"type"`
+	Reason   string `xorm:"varchar(1000)" json:"reason"`
+}
+
+func GetEmailSuppressionCount(owner, field, value string) int {
+	session := GetSession(owner, -1, -1, field, value, "", "")
+	count, err := session.Count(&EmailSuppression{})
+	if err != nil {
+		panic(err)
+	}
+
+	return int(count)
+}
+
+func GetEmailSuppressions(owner string) []*EmailSuppression {
+	emailSuppressions := []*EmailSuppression{}
+	err := adapter.Engine.Desc("created_time").Find(&emailSuppressions, &EmailSuppression{Owner: owner})
+	if err != nil {
+		panic(err)
+	}
+
+	return emailSuppressions
+}
+
+func GetPaginationEmailSuppressions(owner string, offset, limit int, field, value, sortField, sortOrder string) []*EmailSuppression {
+	emailSuppressions := []*EmailSuppression{}
+	session := GetSession(owner, offset, limit, field, value, sortField, sortOrder)
+	err := session.Find(&emailSuppressions)
+	if err != nil {
+		panic(err)
+	}
+
+	return emailSuppressions
+}
+
+func getEmailSuppression(owner string, name string) *EmailSuppression {
+	if owner == "" || name == "" {
+		return nil
+	}
+
+	emailSuppression := EmailSuppression{Owner: owner, Name: strings.ToLower(name)}
+	existed, err := adapter.Engine.Get(&emailSuppression)
+	if err != nil {
+		panic(err)
+	}
+
+	if existed {
+		return &emailSuppression
+	}
+	return nil
+}
+
+func GetEmailSuppression(id string) *EmailSuppression {
+	owner, name := util.GetOwnerAndNameFromId(id)
+	return getEmailSuppression(owner, name)
+}
+
+// IsEmailSuppressed tells whether the address is on the suppression list of
+// the owner of the provider.
+func IsEmailSuppressed(owner string, address string) bool {
+	return getEmailSuppression(owner, address) != nil
+}
+
+func AddEmailSuppression(emailSuppression *EmailSuppression) bool {
+	emailSuppression.Name = strings.ToLower(strings.TrimSpace(emailSuppression.Name))
+	if emailSuppression.CreatedTime == "" {
+		emailSuppression.CreatedTime = util.GetCurrentTime()
+	}
+	if emailSuppression.Type == "" {
+		emailSuppression.Type = EmailSuppressionTypeManual
+	}
+
+	affected, err := adapter.Engine.Insert(emailSuppression)
+	if err != nil {
+		panic(err)
+	}
+
+	return affected != 0
+}
+
+func DeleteEmailSuppression(emailSuppression *EmailSuppression) bool {
+	affected, err := adapter.Engine.ID(core.PK{emailSuppression.Owner, strings.ToLower(emailSuppression.Name)}).Delete(&EmailSuppression{})
+	if err != nil {
+		panic(err)
+	}
+
+	return affected != 0
+}
+
+// HandleEmailWebhook adds the bounced and complaining addresses reported by
+// the email provider to the suppression list of its owner.
+func HandleEmailWebhook(request *http.Request, body []byte, owner string, providerName string) error {
+	provider := getProvider(owner, providerName)
+	if provider == nil || provider.Category != "Email" {
+		return fmt.Errorf("the email provider: %s does not exist", providerName)
+	}
+	emailProvider := provider.getEmailProvider()
+	if emailProvider == nil {
+		return fmt.Errorf("the email provider: %s of type: %s has no webhook", providerName, provider.Type)
+	}
+
+	bounces, err := emailProvider.ParseWebhook(request, body)
+	if err != nil {
+		return err
+	}
+
+	for _, bounce := range bounces {
+		if bounce.Email == "" || IsEmailSuppressed(provider.Owner, bounce.Email) {
+			continue
+		}
+
+		AddEmailSuppression(&EmailSuppression{
+			Owner:    provider.Owner,
+			Name:     bounce.Email,
+			Provider: provider.Name,
+			Type:     bounce.Type,
+			Reason:   bounce.Reason,
+		})
+	}
+	return nil
+}
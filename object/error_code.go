@@ -0,0 +1,177 @@
+// Copyright 2023 The Casdoor Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package object
+
+import (
+	"github.com/casdoor/casdoor/i18n"
+	"github.com/casdoor/casdoor/util"
+)
+
+// The codes of the error catalog, they are returned in the "code" of error
+// responses so that clients don't need to parse the messages.
+const (
+	ErrorCodeInvalidRequest          = "invalid_request"
+	ErrorCodeInvalidClient           = "invalid_client"
+	ErrorCodeInvalidRedirectUri      = "invalid_redirect_uri"
+	ErrorCodeUnsupportedResponseType = "unsupported_response_type"
+	ErrorCodeApplicationNotFound     = "application_not_found"
+	ErrorCodeLoginMethodDisabled     = "login_method_disabled"
+	ErrorCodeUserNotFound            = "user_not_found"
+	ErrorCodeInvalidCredentials      = "invalid_credentials"
+	ErrorCodeUserForbidden           = "user_forbidden"
+	ErrorCodeAccessDenied            = "access_denied"
+	ErrorCodeCaptchaFailed           = "captcha_failed"
+	ErrorCodeSignupNotAllowed        = "signup_not_allowed"
+	ErrorCodeServerError             = "server_error"
+)
+
+var errorCodes = []string{
+	ErrorCodeInvalidRequest,
+	ErrorCodeInvalidClient,
+	ErrorCodeInvalidRedirectUri,
+	ErrorCodeUnsupportedResponseType,
+	ErrorCodeApplicationNotFound,
+	ErrorCodeLoginMethodDisabled,
+	ErrorCodeUserNotFound,
+	ErrorCodeInvalidCredentials,
+	ErrorCodeUserForbidden,
+	ErrorCodeAccessDenied,
+	ErrorCodeCaptchaFailed,
+	ErrorCodeSignupNotAllowed,
+	ErrorCodeServerError,
+}
+
+// ErrorMessage replaces the message of an error code for the users of an
+// organization, with an optional link to a help page. An empty language
+// applies to the languages without a message of their own.
+type ErrorMessage struct {
+	Code     string `json:"code"`
+	Language string `json:"language"`
+	Message  string `json:"message"`
+	HelpUrl  string `json:"helpUrl"`
+}
+
+type ErrorCodeInfo struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+	HelpUrl string `json:"helpUrl"`
+}
+
+// CodedError is an error with a code of the catalog, its message is shown to
+// the user as it is.
+type CodedError struct {
+	Code    string
+	Message string
+}
+
+func NewCodedError(code string, message string) *CodedError {
+	return &CodedError{Code: code, Message: message}
+}
+
+func (e *CodedError) Error() string {
+	return e.Message
+}
+
+func getDefaultErrorMessage(lang string, code string) string {
+	switch code {
+	case ErrorCodeInvalidRequest:
+		return i18n.Translate(lang, "error:The request is invalid")
+	case ErrorCodeInvalidClient:
+		return i18n.Translate(lang, "error:The client is invalid")
+	case ErrorCodeInvalidRedirectUri:
+		return i18n.Translate(lang, "error:The redirect URI is not allowed for the application")
+	case ErrorCodeUnsupportedResponseType:
+		return i18n.Translate(lang, "error:The response type is not supported")
+	case ErrorCodeApplicationNotFound:
+		return i18n.Translate(lang, "error:The application doesn't exist")
+	case ErrorCodeLoginMethodDisabled:
+		return i18n.Translate(lang, "error:The login method is not enabled for the application")
+	case ErrorCodeUserNotFound:
+		return i18n.Translate(lang, "error:The user doesn't exist")
+	case ErrorCodeInvalidCredentials:
+		return i18n.Translate(lang, "error:The credentials are invalid")
+	case ErrorCodeUserForbidden:
+		return i18n.Translate(lang, "error:The user is forbidden to sign in, please contact the administrator")
+	case ErrorCodeAccessDenied:
+		return i18n.Translate(lang, "error:Access is denied")
+	case ErrorCodeCaptchaFailed:
+		return i18n.Translate(lang, "error:The captcha verification failed")
+	case ErrorCodeSignupNotAllowed:
+		return i18n.Translate(lang, "error:Signing up is not allowed for the application")
+	default:
+		return i18n.Translate(lang, "error:Something went wrong, please try again later")
+	}
+}
+
+func (organization *Organization) getErrorMessage(code string, lang string) *ErrorMessage {
+	if organization == nil {
+		return nil
+	}
+
+	var res *ErrorMessage
+	for _, errorMessage := range organization.ErrorMessages {
+		if errorMessage.Code != code {
+			continue
+		}
+
+		if errorMessage.Language == lang {
+			return errorMessage
+		} else if errorMessage.Language == "" && res == nil {
+			res = errorMessage
+		}
+	}
+	return res
+}
+
+// GetErrorCodeInfos returns the catalog with the messages the organization
+// set, an empty organization gives the default messages.
+func GetErrorCodeInfos(organization string, lang string) []*ErrorCodeInfo {
+	organizationObj := getErrorOrganization(organization)
+
+	res := []*ErrorCodeInfo{}
+	for _, code := range errorCodes {
+		message, helpUrl := getErrorMessage(organizationObj, code, "", lang)
+		res = append(res, &ErrorCodeInfo{Code: code, Message: message, HelpUrl: helpUrl})
+	}
+	return res
+}
+
+func getErrorOrganization(organization string) *Organization {
+	if organization == "" {
+		return nil
+	}
+	return GetOrganization(util.GetId("admin", organization))
+}
+
+// getErrorMessage returns the message the organization set for the code,
+// otherwise message, otherwise the default message of the code. The message
+// of a server error is never shown as it may reveal internals.
+func getErrorMessage(organization *Organization, code string, message string, lang string) (string, string) {
+	helpUrl := ""
+	if errorMessage := organization.getErrorMessage(code, lang); errorMessage != nil {
+		if errorMessage.Message != "" {
+			return errorMessage.Message, errorMessage.HelpUrl
+		}
+		helpUrl = errorMessage.HelpUrl
+	}
+	if message == "" || code == ErrorCodeServerError {
+		message = getDefaultErrorMessage(lang, code)
+	}
+	return message, helpUrl
+}
+
+func GetErrorMessage(organization string, code string, message string, lang string) (string, string) {
+	return getErrorMessage(getErrorOrganization(organization), code, message, lang)
+}
@@ -0,0 +1,58 @@
+// Copyright 2023 The Casdoor Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package object
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetErrorMessage(t *testing.T) {
+	organization := &Organization{
+		ErrorMessages: []*ErrorMessage{
+			{Code: ErrorCodeUserForbidden, Message: "Your account is locked, call the help desk", HelpUrl: "https://help.example.com/locked"},
+			{Code: ErrorCodeUserForbidden, Language: "de", Message: "Ihr Konto ist gesperrt"},
+			{Code: ErrorCodeCaptchaFailed, Message: "", HelpUrl: "https://help.example.com/captcha"},
+		},
+	}
+
+	scenarios := []struct {
+		description     string
+		organization    *Organization
+		code            string
+		lang            string
+		message         string
+		expectedMessage string
+		expectedHelpUrl string
+	}{
+		{"custom message", organization, ErrorCodeUserForbidden, "en", "forbidden", "Your account is locked, call the help desk", "https://help.example.com/locked"},
+		{"custom message of the language", organization, ErrorCodeUserForbidden, "de", "forbidden", "Ihr Konto ist gesperrt", ""},
+		{"custom message without a language", organization, ErrorCodeUserForbidden, "fr", "forbidden", "Your account is locked, call the help desk", "https://help.example.com/locked"},
+		{"custom help URL only", organization, ErrorCodeCaptchaFailed, "en", "Turing test failed.", "Turing test failed.", "https://help.example.com/captcha"},
+		{"not customized", organization, ErrorCodeInvalidRequest, "en", "bad form", "bad form", ""},
+		{"default message", nil, ErrorCodeUserNotFound, "en", "", "The user doesn't exist", ""},
+		{"server error hides the message", nil, ErrorCodeServerError, "en", "dial tcp 10.0.0.1:3306: connection refused", "Something went wrong, please try again later", ""},
+		{"unknown code", nil, "unknown", "en", "", "Something went wrong, please try again later", ""},
+	}
+
+	for _, scenery := range scenarios {
+		t.Run(scenery.description, func(t *testing.T) {
+			message, helpUrl := getErrorMessage(scenery.organization, scenery.code, scenery.message, scenery.lang)
+			assert.Equal(t, scenery.expectedMessage, message)
+			assert.Equal(t, scenery.expectedHelpUrl, helpUrl)
+		})
+	}
+}
@@ -0,0 +1,223 @@
+// Copyright 2023 The Casdoor Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package object
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"strconv"
+
+	"github.com/xorm-io/xorm"
+)
+
+const (
+	ExportFormatNdjson = "ndjson"
+	ExportFormatCsv    = "csv"
+
+	// the rows written between two flushes of the response
+	exportFlushRows = 1000
+)
+
+// ExportFilter filters the exported rows on the server, Field and Value are
+// the "like" filter of the list pages and the times bound the created time.
+type ExportFilter struct {
+	Field     string
+	Value     string
+	StartTime string
+	EndTime   string
+}
+
+var userExportColumns = []string{
+	"owner", "name", "createdTime", "updatedTime", "id", "type", "displayName", "firstName", "lastName", "email", "emailVerified",
+	"phone", "countryCode", "region", "location", "affiliation", "tag", "signupApplication", "isAdmin", "isGlobalAdmin", "isForbidden",
+	"isDeleted", "lastSigninTime", "lastSigninIp",
+}
+
+var recordExportColumns = []string{
+	"id", "owner", "name", "createdTime", "organization", "clientIp", "userAgent", "location", "user", "method", "requestUri", "action", "isTriggered",
+}
+
+func getUserExportRow(user *User) []string {
+	return []string{
+		user.Owner, user.Name, user.CreatedTime, user.UpdatedTime, user.Id, user.Type, user.DisplayName, user.FirstName, user.LastName, user.Email, strconv.FormatBool(user.EmailVerified),
+		user.Phone, user.CountryCode, user.Region, user.Location, user.Affiliation, user.Tag, user.SignupApplication, strconv.FormatBool(user.IsAdmin), strconv.FormatBool(user.IsGlobalAdmin), strconv.FormatBool(user.IsForbidden),
+		strconv.FormatBool(user.IsDeleted), user.LastSigninTime, user.LastSigninIp,
+	}
+}
+
+func getRecordExportRow(record *Record) []string {
+	return []string{
+		strconv.Itoa(record.Id), record.Owner, record.Name, record.CreatedTime, record.Organization, record.ClientIp, record.UserAgent, record.Location, record.User, record.Method, record.RequestUri, record.Action, strconv.FormatBool(record.IsTriggered),
+	}
+}
+
+// exportWriter writes the rows one by one, flush is called every
+// exportFlushRows rows so that the client gets the export as it's read.
+type exportWriter struct {
+	writer *bufio.Writer
+	csv    *csv.Writer
+	json   *json.Encoder
+	flush  func()
+	rows   int
+}
+
+func IsExportFormatValid(format string) bool {
+	return format == ExportFormatNdjson || format == ExportFormatCsv
+}
+
+func newExportWriter(w io.Writer, flush func(), format string, columns []string) (*exportWriter, error) {
+	writer := &exportWriter{writer: bufio.NewWriter(w), flush: flush}
+	if format == ExportFormatCsv {
+		writer.csv = csv.NewWriter(writer.writer)
+		return writer, writer.csv.Write(columns)
+	}
+
+	writer.json = json.NewEncoder(writer.writer)
+	return writer, nil
+}
+
+func (writer *exportWriter) write(obj interface{}, row func() []string) error {
+	var err error
+	if writer.csv != nil {
+		err = writer.csv.Write(row())
+	} else {
+		err = writer.json.Encode(obj)
+	}
+	if err != nil {
+		return err
+	}
+
+	writer.rows++
+	if writer.rows%exportFlushRows == 0 {
+		return writer.Flush()
+	}
+	return nil
+}
+
+func (writer *exportWriter) Flush() error {
+	if writer.csv != nil {
+		writer.csv.Flush()
+		if err := writer.csv.Error(); err != nil {
+			return err
+		}
+	}
+
+	err := writer.writer.Flush()
+	if err != nil {
+		return err
+	}
+	if writer.flush != nil {
+		writer.flush()
+	}
+	return nil
+}
+
+func getExportSession(engine *xorm.Engine, owner string, filter *ExportFilter) *xorm.Session {
+	session := getEngineSession(engine, owner, -1, -1, filter.Field, filter.Value, "created_time", "ascend")
+	if filter.StartTime != "" {
+		session = session.And("created_time >= ?", filter.StartTime)
+	}
+	if filter.EndTime != "" {
+		session = session.And("created_time <= ?", filter.EndTime)
+	}
+	return session
+}
+
+// ExportUsers writes the users of the owner (all organizations when empty)
+// to w while they are read from the database, passwords are masked.
+func ExportUsers(w io.Writer, flush func(), format string, owner string, filter *ExportFilter) (int, error) {
+	writer, err := newExportWriter(w, flush, format, userExportColumns)
+	if err != nil {
+		return 0, err
+	}
+
+	engines := getUserEngines()
+	if owner != "" {
+		engines = []*xorm.Engine{getUserEngine(owner)}
+	}
+	for _, engine := range engines {
+		err = exportUserRows(writer, getExportSession(engine, owner, filter))
+		if err != nil {
+			return writer.rows, err
+		}
+	}
+
+	return writer.rows, writer.Flush()
+}
+
+func exportUserRows(writer *exportWriter, session *xorm.Session) error {
+	rows, err := session.Rows(&User{})
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		user := &User{}
+		err = rows.Scan(user)
+		if err != nil {
+			return err
+		}
+
+		user = GetMaskedUser(user)
+		err = writer.write(user, func() []string { return getUserExportRow(user) })
+		if err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
+// ExportRecords writes the records matching filterRecord (the organization,
+// user and action when they are set) to w while they are read.
+func ExportRecords(w io.Writer, flush func(), format string, filterRecord *Record, filter *ExportFilter) (int, error) {
+	writer, err := newExportWriter(w, flush, format, recordExportColumns)
+	if err != nil {
+		return 0, err
+	}
+
+	rows, err := getExportSession(adapter.Engine, "", filter).Rows(filterRecord)
+	if err != nil {
+		return 0, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		record := &Record{}
+		err = rows.Scan(record)
+		if err != nil {
+			return writer.rows, err
+		}
+
+		err = writer.write(record, func() []string { return getRecordExportRow(record) })
+		if err != nil {
+			return writer.rows, err
+		}
+	}
+	if err = rows.Err(); err != nil {
+		return writer.rows, err
+	}
+
+	return writer.rows, writer.Flush()
+}
+
+func GetExportContentType(format string) string {
+	if format == ExportFormatCsv {
+		return "text/csv; charset=utf-8"
+	}
+	return "application/x-ndjson"
+}
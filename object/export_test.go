@@ -0,0 +1,66 @@
+// Copyright 2023 The Casdoor Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package object
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExportWriter(t *testing.T) {
+	records := []*Record{}
+	for i := 1; i <= exportFlushRows+1; i++ {
+		records = append(records, &Record{Id: i, Organization: "org", User: "alice", Action: "login"})
+	}
+
+	scenarios := []struct {
+		description string
+		format      string
+		firstLine   string
+		lines       int
+	}{
+		{"NDJSON", ExportFormatNdjson, `{"id":1,"owner":"","name":"","createdTime":"","organization":"org","clientIp":"","userAgent":"","location":"","user":"alice","method":"","requestUri":"","action":"login","extendedUser":null,"isTriggered":false}`, exportFlushRows + 1},
+		{"CSV", ExportFormatCsv, strings.Join(recordExportColumns, ","), exportFlushRows + 2},
+	}
+
+	for _, scenery := range scenarios {
+		t.Run(scenery.description, func(t *testing.T) {
+			buf := &bytes.Buffer{}
+			flushes := 0
+			writer, err := newExportWriter(buf, func() { flushes++ }, scenery.format, recordExportColumns)
+			assert.Nil(t, err)
+
+			for _, record := range records {
+				record := record
+				assert.Nil(t, writer.write(record, func() []string { return getRecordExportRow(record) }))
+			}
+			assert.Equal(t, 1, flushes, "the rows are flushed every exportFlushRows rows")
+			assert.Nil(t, writer.Flush())
+
+			lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+			assert.Equal(t, scenery.lines, len(lines))
+			assert.Equal(t, scenery.firstLine, lines[0])
+		})
+	}
+}
+
+func TestExportRows(t *testing.T) {
+	assert.Equal(t, len(userExportColumns), len(getUserExportRow(&User{})))
+	assert.Equal(t, len(recordExportColumns), len(getRecordExportRow(&Record{})))
+	assert.Equal(t, "1,,,,org,,,,alice,,,login,false", strings.Join(getRecordExportRow(&Record{Id: 1, Organization: "org", User: "alice", Action: "login"}), ","))
+}
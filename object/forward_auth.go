@@ -0,0 +1,225 @@
+// Copyright 2023 The Casdoor Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package object
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/casdoor/casdoor/util"
+)
+
+const (
+	ForwardAuthCookie       = "casdoor_forward_auth"
+	ForwardAuthNonceCookie  = "casdoor_forward_auth_nonce"
+	ForwardAuthCallbackPath = "/api/forward-auth/callback"
+	// ForwardAuthNonceMaxAge is how long the login may take, in seconds
+	ForwardAuthNonceMaxAge = 600
+)
+
+// ForwardAuthState is passed through the login of the application and tells
+// the callback where to return to. The nonce is also kept in a cookie of the
+// browser that started the login, so that a callback URL of another login
+// can't sign the browser in.
+type ForwardAuthState struct {
+	Application string `json:"application"`
+	Url         string `json:"url"`
+	Nonce       string `json:"nonce"`
+}
+
+// EncodeForwardAuthState signs the state with the client secret of the
+// application, as "<state>.<signature>".
+func EncodeForwardAuthState(state *ForwardAuthState, application *Application) string {
+	data, err := json.Marshal(state)
+	if err != nil {
+		panic(err)
+	}
+
+	payload := base64.RawURLEncoding.EncodeToString(data)
+	return payload + "." + getForwardAuthStateSignature(payload, application)
+}
+
+func getForwardAuthStateSignature(payload string, application *Application) string {
+	mac := hmac.New(sha256.New, []byte(application.ClientSecret))
+	mac.Write([]byte(payload))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// DecodeForwardAuthState returns the state without checking it, see
+// CheckForwardAuthState.
+func DecodeForwardAuthState(s string) (*ForwardAuthState, error) {
+	parts := strings.Split(s, ".")
+	if len(parts) != 2 {
+		return nil, fmt.Errorf("the state is invalid")
+	}
+	data, err := base64.RawURLEncoding.DecodeString(parts[0])
+	if err != nil {
+		return nil, fmt.Errorf("the state is invalid")
+	}
+
+	state := &ForwardAuthState{}
+	err = json.Unmarshal(data, state)
+	if err != nil || state.Application == "" || state.Url == "" || state.Nonce == "" {
+		return nil, fmt.Errorf("the state is invalid")
+	}
+	return state, nil
+}
+
+// CheckForwardAuthState checks the signature of the state by its application
+// and that the nonce cookie of the browser is the one of the state.
+func CheckForwardAuthState(s string, state *ForwardAuthState, application *Application, nonce string) error {
+	parts := strings.Split(s, ".")
+	if len(parts) != 2 || !hmac.Equal([]byte(parts[1]), []byte(getForwardAuthStateSignature(parts[0], application))) {
+		return fmt.Errorf("the state is invalid")
+	}
+	if nonce == "" || !hmac.Equal([]byte(nonce), []byte(state.Nonce)) {
+		return fmt.Errorf("the login wasn't started by this browser, please try again")
+	}
+	return nil
+}
+
+// GetForwardAuthCallbackUrl returns the callback under the host of the
+// forwarded URL, it is the redirect URL of the login.
+func GetForwardAuthCallbackUrl(forwardedUrl string) (string, error) {
+	u, err := url.Parse(forwardedUrl)
+	if err != nil || u.Scheme == "" || u.Host == "" {
+		return "", fmt.Errorf("the forwarded URL: %s is invalid", forwardedUrl)
+	}
+	return fmt.Sprintf("%s://%s%s", u.Scheme, u.Host, ForwardAuthCallbackPath), nil
+}
+
+// GetForwardedUrl returns the URL that the proxy asks about, from the
+// X-Forwarded-Proto, X-Forwarded-Host and X-Forwarded-Uri headers of Traefik
+// and Caddy, or the X-Original-URL header usually set for nginx auth_request.
+func GetForwardedUrl(header http.Header) string {
+	if originalUrl := header.Get("X-Original-URL"); originalUrl != "" {
+		return originalUrl
+	}
+
+	host := header.Get("X-Forwarded-Host")
+	if host == "" {
+		return ""
+	}
+	proto := header.Get("X-Forwarded-Proto")
+	if proto == "" {
+		proto = "https"
+	}
+	uri := header.Get("X-Forwarded-Uri")
+	if !strings.HasPrefix(uri, "/") {
+		uri = "/" + uri
+	}
+	return fmt.Sprintf("%s://%s%s", proto, host, uri)
+}
+
+// GetForwardAuthAccessToken returns the access token of the proxied request,
+// a Bearer token of an API client first and then the cookie set by the
+// callback for browsers.
+func GetForwardAuthAccessToken(request *http.Request) string {
+	authorization := request.Header.Get("Authorization")
+	if strings.HasPrefix(authorization, "Bearer ") {
+		return strings.TrimSpace(strings.TrimPrefix(authorization, "Bearer "))
+	}
+
+	cookie, err := request.Cookie(ForwardAuthCookie)
+	if err != nil {
+		return ""
+	}
+	return cookie.Value
+}
+
+// GetForwardAuthLoginUrl returns the login page of the application and the
+// nonce of its state, the callback is served by the proxy under the host of
+// the forwarded URL.
+func GetForwardAuthLoginUrl(application *Application, forwardedUrl string, host string) (string, string, error) {
+	callbackUrl, err := GetForwardAuthCallbackUrl(forwardedUrl)
+	if err != nil {
+		return "", "", err
+	}
+
+	nonce := util.GenerateId()
+	state := EncodeForwardAuthState(&ForwardAuthState{Application: application.GetId(), Url: forwardedUrl, Nonce: nonce}, application)
+	originFrontend, _ := getOriginFromHost(host)
+	loginUrl := fmt.Sprintf("%s/login/oauth/authorize?client_id=%s&response_type=code&redirect_uri=%s&scope=%s&state=%s",
+		originFrontend, application.ClientId, url.QueryEscape(callbackUrl), url.QueryEscape("openid profile email"), state)
+	return loginUrl, nonce, nil
+}
+
+// IsForwardAuthRedirectAllowed only lets the callback return to the host it
+// was called on, so that the state can't be used as an open redirect.
+func IsForwardAuthRedirectAllowed(redirectUrl string, host string) bool {
+	u, err := url.Parse(redirectUrl)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+		return false
+	}
+	return u.Host == host
+}
+
+// GetForwardAuthUserIdByToken checks that the access token was issued by the
+// application and is still valid.
+func GetForwardAuthUserIdByToken(application *Application, accessToken string) (string, error) {
+	token := GetTokenByAccessToken(accessToken)
+	if token == nil {
+		return "", fmt.Errorf("the access token doesn't exist")
+	}
+	if token.Owner != application.Owner || token.Application != application.Name {
+		return "", fmt.Errorf("the access token is for another application")
+	}
+	if util.IsTokenExpired(token.CreatedTime, token.ExpiresIn) {
+		return "", fmt.Errorf("the access token has expired")
+	}
+	return util.GetId(token.Organization, token.User), nil
+}
+
+// CheckForwardAuthUser returns the user with its roles when the user may
+// access the application.
+func CheckForwardAuthUser(application *Application, userId string) (*User, error) {
+	user := GetUser(userId)
+	if user == nil || user.IsDeleted {
+		return nil, fmt.Errorf("the user: %s doesn't exist", userId)
+	}
+	if user.IsForbidden {
+		return nil, fmt.Errorf("the user: %s is forbidden", userId)
+	}
+	if user.Owner != application.Organization {
+		return nil, fmt.Errorf("the user: %s doesn't belong to the organization of the application", userId)
+	}
+
+	allowed, err := CheckAccessPermission(userId, application)
+	if err != nil {
+		return nil, err
+	}
+	if !allowed {
+		return nil, fmt.Errorf("the user: %s has no permission to access the application", userId)
+	}
+
+	ExtendUserWithRolesAndPermissions(user)
+	return user, nil
+}
+
+// GetForwardAuthHeaders returns the identity headers that the proxy copies
+// to the request for the upstream application.
+func GetForwardAuthHeaders(user *User) map[string]string {
+	return map[string]string{
+		"X-User":  user.Name,
+		"X-Email": user.Email,
+		"X-Roles": user.getRolesString(),
+	}
+}
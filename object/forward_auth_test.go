@@ -0,0 +1,112 @@
+// Copyright 2023 The Casdoor Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package object
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetForwardedUrl(t *testing.T) {
+	scenarios := []struct {
+		description string
+		header      map[string]string
+		expected    string
+	}{
+		{"Traefik", map[string]string{"X-Forwarded-Proto": "https", "X-Forwarded-Host": "app.example.com", "X-Forwarded-Uri": "/page?a=1"}, "https://app.example.com/page?a=1"},
+		{"Without protocol", map[string]string{"X-Forwarded-Host": "app.example.com", "X-Forwarded-Uri": "/"}, "https://app.example.com/"},
+		{"Without URI", map[string]string{"X-Forwarded-Proto": "http", "X-Forwarded-Host": "app.example.com"}, "http://app.example.com/"},
+		{"nginx", map[string]string{"X-Original-URL": "https://app.example.com/page", "X-Forwarded-Host": "other.example.com"}, "https://app.example.com/page"},
+		{"Without headers", map[string]string{}, ""},
+	}
+
+	for _, scenery := range scenarios {
+		t.Run(scenery.description, func(t *testing.T) {
+			header := http.Header{}
+			for key, value := range scenery.header {
+				header.Set(key, value)
+			}
+			assert.Equal(t, scenery.expected, GetForwardedUrl(header))
+		})
+	}
+}
+
+func TestForwardAuthState(t *testing.T) {
+	application := &Application{Owner: "admin", Name: "app", ClientSecret: "secret"}
+	state := &ForwardAuthState{Application: "admin/app", Url: "https://app.example.com/page?a=1", Nonce: "nonce"}
+	s := EncodeForwardAuthState(state, application)
+	decoded, err := DecodeForwardAuthState(s)
+	assert.Nil(t, err)
+	assert.Equal(t, state, decoded)
+	assert.Nil(t, CheckForwardAuthState(s, decoded, application, "nonce"))
+
+	assert.EqualError(t, CheckForwardAuthState(s, decoded, application, ""), "the login wasn't started by this browser, please try again")
+	assert.EqualError(t, CheckForwardAuthState(s, decoded, application, "other"), "the login wasn't started by this browser, please try again")
+	assert.EqualError(t, CheckForwardAuthState(s, decoded, &Application{ClientSecret: "other"}, "nonce"), "the state is invalid")
+
+	// a state changed to return to another page isn't signed
+	forged := EncodeForwardAuthState(&ForwardAuthState{Application: "admin/app", Url: "https://app.example.com/admin", Nonce: "nonce"}, &Application{ClientSecret: "guess"})
+	decoded, err = DecodeForwardAuthState(forged)
+	assert.Nil(t, err)
+	assert.EqualError(t, CheckForwardAuthState(forged, decoded, application, "nonce"), "the state is invalid")
+
+	_, err = DecodeForwardAuthState("not a state")
+	assert.EqualError(t, err, "the state is invalid")
+
+	_, err = DecodeForwardAuthState(EncodeForwardAuthState(&ForwardAuthState{Application: "admin/app"}, application))
+	assert.EqualError(t, err, "the state is invalid")
+}
+
+func TestGetForwardAuthCallbackUrl(t *testing.T) {
+	callbackUrl, err := GetForwardAuthCallbackUrl("https://app.example.com/page?a=1")
+	assert.Nil(t, err)
+	assert.Equal(t, "https://app.example.com/api/forward-auth/callback", callbackUrl)
+
+	_, err = GetForwardAuthCallbackUrl("/page")
+	assert.NotNil(t, err)
+}
+
+func TestIsForwardAuthRedirectAllowed(t *testing.T) {
+	scenarios := []struct {
+		description string
+		url         string
+		expected    bool
+	}{
+		{"Same host", "https://app.example.com/page", true},
+		{"Other host", "https://evil.example.com/page", false},
+		{"Subdomain", "https://a.app.example.com/page", false},
+		{"Other scheme", "javascript://app.example.com/page", false},
+		{"Relative", "/page", false},
+	}
+
+	for _, scenery := range scenarios {
+		t.Run(scenery.description, func(t *testing.T) {
+			assert.Equal(t, scenery.expected, IsForwardAuthRedirectAllowed(scenery.url, "app.example.com"))
+		})
+	}
+}
+
+func TestGetForwardAuthAccessToken(t *testing.T) {
+	request, _ := http.NewRequest("GET", "/api/forward-auth", nil)
+	assert.Equal(t, "", GetForwardAuthAccessToken(request))
+
+	request.AddCookie(&http.Cookie{Name: ForwardAuthCookie, Value: "cookie-token"})
+	assert.Equal(t, "cookie-token", GetForwardAuthAccessToken(request))
+
+	request.Header.Set("Authorization", "Bearer bearer-token")
+	assert.Equal(t, "bearer-token", GetForwardAuthAccessToken(request))
+}
@@ -0,0 +1,223 @@
+// Copyright 2023 The Casdoor Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package object
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/casdoor/casdoor/util"
+	"github.com/thanhpk/randstr"
+	"github.com/xorm-io/core"
+)
+
+const (
+	GuestGrantType = "guest"
+
+	guestDeviceIdMinLength = 16
+	guestUserLimitPerIp    = 20
+	guestUserLimitWindow   = time.Hour
+)
+
+// guestUserTimes keeps the recent creation times of guest users by client
+// IP to throttle the creation of guest users.
+var guestUserTimes = struct {
+	sync.Mutex
+	times map[string][]time.Time
+}{times: map[string][]time.Time{}}
+
+// allowGuestUser records a new guest user of the IP unless the IP has
+// created guestUserLimitPerIp guest users in the last guestUserLimitWindow.
+func allowGuestUser(clientIp string, now time.Time) bool {
+	guestUserTimes.Lock()
+	defer guestUserTimes.Unlock()
+
+	for ip, times := range guestUserTimes.times {
+		recent := []time.Time{}
+		for _, t := range times {
+			if now.Sub(t) < guestUserLimitWindow {
+				recent = append(recent, t)
+			}
+		}
+		if len(recent) == 0 {
+			delete(guestUserTimes.times, ip)
+		} else {
+			guestUserTimes.times[ip] = recent
+		}
+	}
+
+	if len(guestUserTimes.times[clientIp]) >= guestUserLimitPerIp {
+		return false
+	}
+	guestUserTimes.times[clientIp] = append(guestUserTimes.times[clientIp], now)
+	return true
+}
+
+// getGuestDevice returns what is stored for the device of a guest, a guest
+// is bound to one device in one application.
+func getGuestDevice(application *Application, deviceId string) string {
+	hash := sha256.Sum256([]byte(application.GetId() + "/" + deviceId))
+	return hex.EncodeToString(hash[:])
+}
+
+func getGuestUser(application *Application, deviceId string) *User {
+	user := User{Owner: application.Organization, GuestDevice: getGuestDevice(application, deviceId)}
+	existed, err := getUserEngine(user.Owner).Get(&user)
+	if err != nil {
+		panic(err)
+	}
+
+	if existed {
+		return &user
+	}
+	return nil
+}
+
+// GetGuestToken returns a token for the anonymous user bound to the device,
+// the user is created on the first call. The device ID is a random value
+// made and kept by the client, e.g. in the keychain of a mobile app.
+func GetGuestToken(application *Application, deviceId string, scope string, host string, clientIp string) (*Token, *TokenError) {
+	// GetOAuthToken skips the grant types check for tagged requests
+	if !IsGrantTypeValid(GuestGrantType, application.GrantTypes) {
+		return nil, &TokenError{
+			Error:            UnsupportedGrantType,
+			ErrorDescription: fmt.Sprintf("grant_type: %s is not supported in this application", GuestGrantType),
+		}
+	}
+	if len(deviceId) < guestDeviceIdMinLength {
+		return nil, &TokenError{
+			Error:            InvalidRequest,
+			ErrorDescription: fmt.Sprintf("device_id must have at least %d characters", guestDeviceIdMinLength),
+		}
+	}
+
+	user := getGuestUser(application, deviceId)
+	if user == nil {
+		organization := getOrganization("admin", application.Organization)
+		if organization == nil {
+			return nil, &TokenError{
+				Error:            InvalidClient,
+				ErrorDescription: fmt.Sprintf("the organization: %s doesn't exist", application.Organization),
+			}
+		}
+		if clientIp != "" && !allowGuestUser(clientIp, time.Now()) {
+			return nil, &TokenError{
+				Error:            InvalidRequest,
+				ErrorDescription: "too many guest users are created from this IP, please try again later",
+			}
+		}
+
+		user = &User{
+			Owner:             application.Organization,
+			Name:              fmt.Sprintf("guest_%s", randstr.Hex(8)),
+			CreatedTime:       util.GetCurrentTime(),
+			Id:                util.GenerateId(),
+			Type:              "normal-user",
+			DisplayName:       "Guest",
+			Avatar:            getInheritedOrganization(organization).DefaultAvatar,
+			Address:           []string{},
+			Score:             organization.InitScore,
+			IsGuest:           true,
+			GuestDevice:       getGuestDevice(application, deviceId),
+			SignupApplication: application.Name,
+			Properties:        map[string]string{},
+		}
+		if !AddUser(user) {
+			return nil, &TokenError{
+				Error:            EndpointError,
+				ErrorDescription: "failed to add the guest user",
+			}
+		}
+	}
+
+	if user.IsForbidden {
+		return nil, &TokenError{
+			Error:            InvalidGrant,
+			ErrorDescription: "the user is forbidden to sign in, please contact the administrator",
+		}
+	}
+
+	token, err := GetTokenByUser(application, user, scope, host)
+	if err != nil {
+		return nil, &TokenError{
+			Error:            EndpointError,
+			ErrorDescription: fmt.Sprintf("generate jwt token error: %s", err.Error()),
+		}
+	}
+	return token, nil
+}
+
+// GetGuestUserByAccessToken returns the guest user of a valid access token
+// of the organization, nil for other tokens.
+func GetGuestUserByAccessToken(accessToken string, organization string) *User {
+	token := GetTokenByAccessToken(accessToken)
+	if token == nil || token.Organization != organization {
+		return nil
+	}
+
+	application := getApplication(token.Owner, token.Application)
+	if application == nil {
+		return nil
+	}
+	if _, err := ParseJwtTokenByApplication(accessToken, application); err != nil {
+		return nil
+	}
+
+	user := getUser(token.Organization, token.User)
+	if user == nil || !user.IsGuest {
+		return nil
+	}
+	return user
+}
+
+// PromoteGuestUser turns the guest into the signed-up user. The guest keeps
+// its ID (the subject of its tokens) and its roles, permissions and tokens
+// move to the new username.
+func PromoteGuestUser(guest *User, user *User) bool {
+	user.Owner = guest.Owner
+	user.Id = guest.Id
+	user.CreatedTime = guest.CreatedTime
+	user.IsGuest = false
+	user.GuestDevice = ""
+
+	organization := GetOrganizationByUser(user)
+	if organization == nil {
+		return false
+	}
+	user.UpdateUserPassword(organization)
+	user.UpdateUserHash()
+
+	if guest.Name != user.Name {
+		err := userChangeTrigger(guest.Name, user.Name)
+		if err != nil {
+			return false
+		}
+
+		_, err = adapter.Engine.Where("organization=? and user=?", guest.Owner, guest.Name).Cols("user").Update(&Token{User: user.Name})
+		if err != nil {
+			panic(err)
+		}
+	}
+
+	affected, err := getUserEngine(guest.Owner).ID(core.PK{guest.Owner, guest.Name}).AllCols().Update(user)
+	if err != nil {
+		panic(err)
+	}
+
+	return affected != 0
+}
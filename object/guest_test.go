@@ -0,0 +1,51 @@
+// Copyright 2023 The Casdoor Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package object
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetGuestDevice(t *testing.T) {
+	game := &Application{Owner: "admin", Name: "game"}
+	shop := &Application{Owner: "admin", Name: "shop"}
+	deviceId := "6f1c2a0e-3b7d-4f5e-9a8b-123456789abc"
+
+	assert.Equal(t, getGuestDevice(game, deviceId), getGuestDevice(game, deviceId), "the same device must get the same guest")
+	assert.NotEqual(t, getGuestDevice(game, deviceId), getGuestDevice(shop, deviceId), "a device must get a guest per application")
+	assert.NotEqual(t, getGuestDevice(game, deviceId), getGuestDevice(game, deviceId+"1"))
+	assert.NotContains(t, getGuestDevice(game, deviceId), deviceId, "the device ID must not be stored")
+}
+
+func TestAllowGuestUser(t *testing.T) {
+	now := time.Date(2023, 6, 1, 0, 0, 0, 0, time.UTC)
+	for i := 0; i < guestUserLimitPerIp; i++ {
+		assert.True(t, allowGuestUser("198.51.100.1", now.Add(time.Duration(i)*time.Second)))
+	}
+	assert.False(t, allowGuestUser("198.51.100.1", now.Add(time.Minute)), "the IP has reached the limit")
+	assert.True(t, allowGuestUser("198.51.100.2", now.Add(time.Minute)), "the limit is per IP")
+	assert.True(t, allowGuestUser("198.51.100.1", now.Add(guestUserLimitWindow+time.Minute)), "the old guest users don't count")
+}
+
+func TestGetGuestTokenGrantType(t *testing.T) {
+	application := &Application{Owner: "admin", Name: "game", GrantTypes: []string{"authorization_code"}}
+	_, tokenError := GetGuestToken(application, "6f1c2a0e-3b7d-4f5e-9a8b-123456789abc", "", "", "198.51.100.1")
+	assert.Equal(t, UnsupportedGrantType, tokenError.Error)
+	_, tokenError = GetDeviceToken(application, "sensor-1", nil, "", "")
+	assert.Equal(t, UnsupportedGrantType, tokenError.Error)
+}
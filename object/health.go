@@ -0,0 +1,174 @@
+// Copyright 2023 The Casdoor Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package object
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	HealthStatusUp      = "up"
+	HealthStatusWarning = "warning"
+	HealthStatusDown    = "down"
+)
+
+// certExpiryWarningDays is how long before expiry a cert in use is reported
+// with a warning.
+const certExpiryWarningDays = 14
+
+// certHealthCacheTtl is how long the result of checkCertHealth is reused, it
+// reads all the applications and parses their certs.
+const certHealthCacheTtl = time.Minute
+
+var certHealthCache = struct {
+	sync.Mutex
+	status    string
+	message   string
+	checkTime time.Time
+}{}
+
+type HealthComponent struct {
+	Name    string `json:"name"`
+	Status  string `json:"status"`
+	Message string `json:"message"`
+	Latency int64  `json:"latency"`
+}
+
+type Health struct {
+	Status     string             `json:"status"`
+	Node       string             `json:"node"`
+	Components []*HealthComponent `json:"components"`
+}
+
+// GetReadiness checks the dependencies needed to serve requests, the status
+// is down if any of them is down.
+func GetReadiness() *Health {
+	health := &Health{
+		Status: HealthStatusUp,
+		Node:   GetNodeName(),
+		Components: []*HealthComponent{
+			checkHealth("database", checkDatabaseHealth),
+			checkHealth("session", checkSessionStoreHealth),
+			checkHealth("cert", checkCertHealth),
+			checkHealth("maintenance", checkMaintenanceHealth),
+		},
+	}
+
+	for _, component := range health.Components {
+		if component.Status == HealthStatusDown {
+			health.Status = HealthStatusDown
+		} else if component.Status == HealthStatusWarning && health.Status == HealthStatusUp {
+			health.Status = HealthStatusWarning
+		}
+	}
+	return health
+}
+
+// checkHealth runs check, which returns a status and a message, and turns a
+// panic into a down status.
+func checkHealth(name string, check func() (string, string)) (component *HealthComponent) {
+	component = &HealthComponent{Name: name}
+	start := time.Now()
+	defer func() {
+		component.Latency = time.Since(start).Milliseconds()
+		if r := recover(); r != nil {
+			component.Status = HealthStatusDown
+			component.Message = fmt.Sprintf("%v", r)
+		}
+	}()
+
+	component.Status, component.Message = check()
+	return component
+}
+
+func checkDatabaseHealth() (string, string) {
+	err := adapter.Engine.Ping()
+	if err != nil {
+		return HealthStatusDown, err.Error()
+	}
+	return HealthStatusUp, ""
+}
+
+// checkSessionStoreHealth checks Redis when it's the session store, the
+// master for Sentinel and a node for Cluster.
+func checkSessionStoreHealth() (string, string) {
+	client := getRedisClient()
+	if client == nil {
+		_, err := os.Stat("./tmp")
+		if err != nil && !os.IsNotExist(err) {
+			return HealthStatusDown, err.Error()
+		}
+		return HealthStatusUp, "file"
+	}
+
+	_, err := client.Do("PING")
+	if err != nil {
+		return HealthStatusDown, err.Error()
+	}
+	return HealthStatusUp, fmt.Sprintf("redis (%s)", client.config.Mode)
+}
+
+func checkCertHealth() (string, string) {
+	certHealthCache.Lock()
+	defer certHealthCache.Unlock()
+
+	if certHealthCache.checkTime.IsZero() || time.Since(certHealthCache.checkTime) > certHealthCacheTtl {
+		certHealthCache.status, certHealthCache.message = getCertHealth()
+		certHealthCache.checkTime = time.Now()
+	}
+	return certHealthCache.status, certHealthCache.message
+}
+
+// getCertHealth checks the certs used by applications. An expired or invalid
+// cert is only a warning: the node can't fix it and taking all the nodes out
+// of service would stop the applications that don't use it too.
+func getCertHealth() (string, string) {
+	names := map[string]bool{}
+	for _, application := range GetApplications("") {
+		if application.Cert != "" {
+			names[application.Cert] = true
+		}
+	}
+
+	status := HealthStatusUp
+	messages := []string{}
+	for name := range names {
+		cert := getCert("admin", name)
+		if cert == nil || cert.Certificate == "" {
+			continue
+		}
+
+		certificate, err := parseCertificatePem(cert.Certificate)
+		if err != nil {
+			status = HealthStatusWarning
+			messages = append(messages, fmt.Sprintf("%s: %s", name, err.Error()))
+			continue
+		}
+
+		if time.Now().After(certificate.NotAfter) {
+			status = HealthStatusWarning
+			messages = append(messages, fmt.Sprintf("%s: expired at %s", name, certificate.NotAfter.Format(time.RFC3339)))
+		} else if time.Until(certificate.NotAfter) < certExpiryWarningDays*24*time.Hour {
+			status = HealthStatusWarning
+			messages = append(messages, fmt.Sprintf("%s: expires at %s", name, certificate.NotAfter.Format(time.RFC3339)))
+		}
+	}
+
+	return status, strings.Join(messages, "; ")
+}
@@ -0,0 +1,322 @@
+// Copyright 2023 The Casdoor Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package object
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/beego/beego/logs"
+	"github.com/casdoor/casdoor/idv"
+	"github.com/casdoor/casdoor/util"
+	"github.com/xorm-io/core"
+)
+
+const IdentityVerificationCategory = "Identity Verification"
+
+// IdentityVerification is a verification session of a user at an identity
+// verification provider. The level of an approved verification is copied
+// to the user, where it can be used in the ABAC matchers as
+// userAttr(r.sub, "verificationLevel") and is part of the token claims.
+type IdentityVerification struct {
+	Owner       string `xorm:"varchar(100) notnull pk" json:"owner"`
+	Name        string `xorm:"varchar(100) notnull pk" json:"name"`
+	CreatedTime string `xorm:"varchar(100)" json:"createdTime"`
+	UpdatedTime string `xorm:"varchar(100)" json:"updatedTime"`
+
+	User        string `xorm:"varchar(100) index" json:"user"`
+	Application string `xorm:"varchar(100)" json:"application"`
+	Provider    string `xorm:"varchar(100)" json:"provider"`
+	ExternalId  string `xorm:"varchar(100) index" json:"externalId"`
+	Url         string `xorm:"varchar(1000)" json:"url"`
+	State       string `xorm:"varchar(100)" json:"state"`
+	Level       int    `json:"level"`
+	Reason      string `xorm:"varchar(1000)" json:"reason"`
+}
+
+func GetIdentityVerificationCount(owner, field, value string) int {
+	session := GetSession(owner, -1, -1, field, value, "", "")
+	count, err := session.Count(&IdentityVerification{})
+	if err != nil {
+		panic(err)
+	}
+
+	return int(count)
+}
+
+func GetIdentityVerifications(owner string) []*IdentityVerification {
+	verifications := []*IdentityVerification{}
+	err := adapter.Engine.Desc("created_time").Find(&verifications, &IdentityVerification{Owner: owner})
+	if err != nil {
+		panic(err)
+	}
+
+	return verifications
+}
+
+func GetPaginationIdentityVerifications(owner string, offset, limit int, field, value, sortField, sortOrder string) []*IdentityVerification {
+	verifications := []*IdentityVerification{}
+	session := GetSession(owner, offset, limit, field, value, sortField, sortOrder)
+	err := session.Find(&verifications)
+	if err != nil {
+		panic(err)
+	}
+
+	return verifications
+}
+
+func GetUserIdentityVerifications(user *User) []*IdentityVerification {
+	verifications := []*IdentityVerification{}
+	err := adapter.Engine.Desc("created_time").Find(&verifications, &IdentityVerification{Owner: user.Owner, User: user.Name})
+	if err != nil {
+		panic(err)
+	}
+
+	return verifications
+}
+
+func getIdentityVerification(owner string, name string) *IdentityVerification {
+	if owner == "" || name == "" {
+		return nil
+	}
+
+	verification := IdentityVerification{Owner: owner, Name: name}
+	existed, err := adapter.Engine.Get(&verification)
+	if err != nil {
+		panic(err)
+	}
+
+	if existed {
+		return &verification
+	} else {
+		return nil
+	}
+}
+
+func GetIdentityVerification(id string) *IdentityVerification {
+	owner, name := util.GetOwnerAndNameFromId(id)
+	return getIdentityVerification(owner, name)
+}
+
+func getIdentityVerificationByExternalId(provider string, externalId string) *IdentityVerification {
+	if externalId == "" {
+		return nil
+	}
+
+	verification := IdentityVerification{Provider: provider, ExternalId: externalId}
+	existed, err := adapter.Engine.Get(&verification)
+	if err != nil {
+		panic(err)
+	}
+
+	if existed {
+		return &verification
+	} else {
+		return nil
+	}
+}
+
+func (verification *IdentityVerification) GetId() string {
+	return fmt.Sprintf("%s/%s", verification.Owner, verification.Name)
+}
+
+// GetIdentityVerificationProvider returns the provider named by providerId,
+// or else the first identity verification provider of the application. A
+// named provider must be one of the application or of the organization of
+// the user.
+func GetIdentityVerificationProvider(application *Application, user *User, providerId string) *Provider {
+	if providerId != "" {
+		provider := GetProvider(providerId)
+		if provider == nil || provider.Category != IdentityVerificationCategory {
+			return nil
+		}
+		if provider.Owner != user.Owner && !application.hasProvider(provider) {
+			return nil
+		}
+		return provider
+	}
+
+	if application == nil {
+		return nil
+	}
+	providerItem := application.getIdentityVerificationProviderItem()
+	if providerItem == nil {
+		return nil
+	}
+	return providerItem.Provider
+}
+
+func (application *Application) hasProvider(provider *Provider) bool {
+	if application == nil {
+		return false
+	}
+
+	for _, providerItem := range application.Providers {
+		if providerItem.Provider != nil && providerItem.Provider.GetId() == provider.GetId() {
+			return true
+		}
+	}
+	return false
+}
+
+func (application *Application) getIdentityVerificationProviderItem() *ProviderItem {
+	for _, providerItem := range application.Providers {
+		if providerItem.Provider != nil && providerItem.Provider.Category == IdentityVerificationCategory {
+			return providerItem
+		}
+	}
+	return nil
+}
+
+// IsIdentityVerifiedOnSignup tells whether a new user of the application is
+// sent to the identity verification provider right after signing up.
+func (application *Application) IsIdentityVerifiedOnSignup() bool {
+	providerItem := application.getIdentityVerificationProviderItem()
+	return providerItem != nil && providerItem.CanSignUp
+}
+
+// StartIdentityVerification starts a session at the provider for the user,
+// the user is sent to the URL of the returned verification and comes back
+// to returnUrl.
+func StartIdentityVerification(user *User, application *Application, provider *Provider, returnUrl string, host string) (*IdentityVerification, error) {
+	idvProvider, err := provider.getIdvProvider()
+	if err != nil {
+		return nil, err
+	}
+
+	verification := &IdentityVerification{
+		Owner:       user.Owner,
+		Name:        util.GenerateTimeId(),
+		CreatedTime: util.GetCurrentTime(),
+		UpdatedTime: util.GetCurrentTime(),
+		User:        user.Name,
+		Provider:    provider.GetId(),
+		State:       idv.VerificationStatusPending,
+	}
+	if application != nil {
+		verification.Application = application.Name
+	}
+
+	_, originBackend := getOriginFromHost(host)
+	notifyUrl := fmt.Sprintf("%s/api/identity-verification-webhook/%s/%s", originBackend, provider.Owner, provider.Name)
+	session, err := idvProvider.CreateSession(verification.GetId(), returnUrl, notifyUrl)
+	if err != nil {
+		return nil, err
+	}
+
+	verification.ExternalId = session.Id
+	verification.Url = session.Url
+	_, err = adapter.Engine.Insert(verification)
+	if err != nil {
+		return nil, err
+	}
+
+	return verification, nil
+}
+
+// StartSignupIdentityVerification starts the verification of a new user when
+// the provider of the application is enabled for signup, and returns the URL
+// to send the user to. A failing provider doesn't fail the signup, the user
+// can start the verification later on.
+func StartSignupIdentityVerification(application *Application, user *User, host string) string {
+	if !application.IsIdentityVerifiedOnSignup() {
+		return ""
+	}
+
+	// the same result page as after a signup without verification
+	originFrontend, _ := getOriginFromHost(host)
+	returnUrl := fmt.Sprintf("%s/result/%s", originFrontend, application.Name)
+	if application.HasPromptPage() {
+		returnUrl = fmt.Sprintf("%s/prompt/%s", originFrontend, application.Name)
+	}
+
+	verification, err := StartIdentityVerification(user, application, GetIdentityVerificationProvider(application, user, ""), returnUrl, host)
+	if err != nil {
+		logs.Warning(fmt.Sprintf("failed to start the identity verification of user: %s, %s", user.GetId(), err.Error()))
+		return ""
+	}
+	return verification.Url
+}
+
+// HandleIdentityVerificationWebhook applies a result of the provider to its
+// verification, the user keeps the highest level that was approved.
+// Retried results change nothing.
+func HandleIdentityVerificationWebhook(request *http.Request, body []byte, owner string, providerName string) error {
+	provider := getProvider(owner, providerName)
+	if provider == nil || provider.Category != IdentityVerificationCategory {
+		return fmt.Errorf("the identity verification provider: %s does not exist", providerName)
+	}
+	idvProvider, err := provider.getIdvProvider()
+	if err != nil {
+		return err
+	}
+
+	result, err := idvProvider.ParseWebhook(request, body)
+	if err != nil {
+		return err
+	}
+	if result.Status == "" {
+		return nil
+	}
+
+	verification := getIdentityVerificationByExternalId(provider.GetId(), result.SessionId)
+	if verification == nil && result.Reference != "" {
+		verification = GetIdentityVerification(result.Reference)
+	}
+	if verification == nil || verification.Provider != provider.GetId() {
+		return fmt.Errorf("the verification of the session: %s does not exist", result.SessionId)
+	}
+	if verification.State == result.Status && verification.Level == result.Level {
+		return nil
+	}
+
+	verification.UpdatedTime = util.GetCurrentTime()
+	verification.State = result.Status
+	verification.Level = result.Level
+	verification.Reason = result.Reason
+	_, err = adapter.Engine.ID(core.PK{verification.Owner, verification.Name}).Cols("updated_time", "state", "level", "reason").Update(verification)
+	if err != nil {
+		return err
+	}
+
+	user := getUser(verification.Owner, verification.User)
+	if user == nil {
+		return nil
+	}
+	if result.Status == idv.VerificationStatusApproved && result.Level > user.VerificationLevel {
+		user.VerificationLevel = result.Level
+		user.VerifiedTime = util.GetCurrentTime()
+		_, err = getUserEngine(user.Owner).ID(core.PK{user.Owner, user.Name}).Cols("verification_level", "verified_time").Update(user)
+		if err != nil {
+			return err
+		}
+	}
+
+	addIdentityVerificationRecord(verification)
+	return nil
+}
+
+func addIdentityVerificationRecord(verification *IdentityVerification) {
+	record := &Record{
+		Name:         util.GenerateId(),
+		CreatedTime:  util.GetCurrentTime(),
+		Organization: verification.Owner,
+		User:         verification.User,
+		Method:       "POST",
+		RequestUri:   fmt.Sprintf("/api/get-identity-verification?id=%s", verification.GetId()),
+		Action:       "identity-verification",
+	}
+	AddRecord(record)
+}
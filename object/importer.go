@@ -0,0 +1,294 @@
+// Copyright 2023 The Casdoor Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package object
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/casdoor/casdoor/util"
+)
+
+// ImportResult holds the objects parsed from the export of another identity
+// provider, before they are written to the database.
+type ImportResult struct {
+	Organization *Organization  `json:"organization"`
+	Applications []*Application `json:"applications"`
+	Users        []*User        `json:"users"`
+	Roles        []*Role        `json:"roles"`
+	Warnings     []string       `json:"warnings"`
+	idMap        map[string]*User
+}
+
+type ImportSummary struct {
+	Organization        string   `json:"organization"`
+	OrganizationCreated bool     `json:"organizationCreated"`
+	Applications        int      `json:"applications"`
+	Users               int      `json:"users"`
+	Roles               int      `json:"roles"`
+	SkippedApplications []string `json:"skippedApplications"`
+	SkippedUsers        []string `json:"skippedUsers"`
+	Warnings            []string `json:"warnings"`
+}
+
+type Importer interface {
+	// Import parses data and returns the objects for the organization, an
+	// empty organization means the one named in the export (if any).
+	Import(data []byte, organization string) (*ImportResult, error)
+}
+
+func GetImporter(typ string) Importer {
+	switch typ {
+	case "Keycloak":
+		return &KeycloakImporter{}
+	case "Auth0":
+		return &Auth0Importer{}
+	case "Okta":
+		return &OktaImporter{}
+	default:
+		return nil
+	}
+}
+
+func newImportResult(organization string, passwordType string) *ImportResult {
+	return &ImportResult{
+		Organization: &Organization{
+			Owner:        "admin",
+			Name:         organization,
+			CreatedTime:  util.GetCurrentTime(),
+			DisplayName:  organization,
+			PasswordType: passwordType,
+			CountryCodes: []string{"US"},
+			Tags:         []string{},
+			Languages:    []string{"en", "zh", "es", "fr", "de", "id", "ja", "ko", "ru", "vi"},
+			InitScore:    2000,
+			AccountItems: getBuiltInAccountItems(),
+		},
+		Applications: []*Application{},
+		Users:        []*User{},
+		Roles:        []*Role{},
+		Warnings:     []string{},
+		idMap:        map[string]*User{},
+	}
+}
+
+var importNameRegex = regexp.MustCompile(`[^a-zA-Z0-9_\-.@]+`)
+
+// getImportName turns an external identifier into a valid Casdoor name.
+func getImportName(name string) string {
+	return strings.Trim(importNameRegex.ReplaceAllString(name, "_"), "_")
+}
+
+func (result *ImportResult) addWarning(format string, a ...interface{}) {
+	result.Warnings = append(result.Warnings, fmt.Sprintf(format, a...))
+}
+
+func (result *ImportResult) addApplication(clientId string, displayName string, clientSecret string, redirectUris []string) {
+	name := getImportName(clientId)
+	if name == "" {
+		result.addWarning("skipped an application without client ID")
+		return
+	}
+	if displayName == "" {
+		displayName = clientId
+	}
+	if redirectUris == nil {
+		redirectUris = []string{}
+	}
+
+	result.Applications = append(result.Applications, &Application{
+		Owner:          "admin",
+		Name:           name,
+		CreatedTime:    util.GetCurrentTime(),
+		DisplayName:    displayName,
+		Organization:   result.Organization.Name,
+		Cert:           "cert-built-in",
+		EnablePassword: true,
+		EnableSignUp:   true,
+		ClientId:       clientId,
+		ClientSecret:   clientSecret,
+		Providers:      []*ProviderItem{},
+		SignupItems: []*SignupItem{
+			{Name: "ID", Visible: false, Required: true, Prompted: false, Rule: "Random"},
+			{Name: "Username", Visible: true, Required: true, Prompted: false, Rule: "None"},
+			{Name: "Display name", Visible: true, Required: true, Prompted: false, Rule: "None"},
+			{Name: "Password", Visible: true, Required: true, Prompted: false, Rule: "None"},
+			{Name: "Confirm password", Visible: true, Required: true, Prompted: false, Rule: "None"},
+			{Name: "Email", Visible: true, Required: true, Prompted: false, Rule: "Normal"},
+		},
+		RedirectUris:  redirectUris,
+		ExpireInHours: 168,
+		FormOffset:    2,
+	})
+}
+
+// newUser returns a user with the defaults of the organization, externalId
+// is the ID in the source system and is kept as the user ID.
+func (result *ImportResult) newUser(name string, externalId string) *User {
+	user := &User{
+		Owner:       result.Organization.Name,
+		Name:        getImportName(name),
+		CreatedTime: util.GetCurrentTime(),
+		Id:          externalId,
+		Type:        "normal-user",
+		Address:     []string{},
+		Score:       result.Organization.InitScore,
+		Properties:  map[string]string{},
+	}
+	if user.Id == "" {
+		user.Id = util.GenerateId()
+	}
+	return user
+}
+
+func (result *ImportResult) addUser(user *User, externalId string) bool {
+	if user.Name == "" {
+		result.addWarning("skipped a user without username: %s", externalId)
+		return false
+	}
+
+	result.Users = append(result.Users, user)
+	if externalId != "" {
+		result.idMap[externalId] = user
+	}
+	return true
+}
+
+// addRoleUser adds the user to the role, creating the role on first use.
+func (result *ImportResult) addRoleUser(roleName string, user *User) {
+	name := getImportName(roleName)
+	if name == "" {
+		return
+	}
+
+	var role *Role
+	for _, r := range result.Roles {
+		if r.Name == name {
+			role = r
+			break
+		}
+	}
+	if role == nil {
+		role = &Role{
+			Owner:       result.Organization.Name,
+			Name:        name,
+			CreatedTime: util.GetCurrentTime(),
+			DisplayName: roleName,
+			Users:       []string{},
+			Roles:       []string{},
+			Domains:     []string{},
+			IsEnabled:   true,
+		}
+		result.Roles = append(result.Roles, role)
+	}
+
+	if user != nil && !util.ContainsString(role.Users, user.GetId()) {
+		role.Users = append(role.Users, user.GetId())
+	}
+}
+
+func getImportTimeFromMillis(millis int64) string {
+	if millis == 0 {
+		return util.GetCurrentTime()
+	}
+	return time.Unix(millis/1000, 0).Format(time.RFC3339)
+}
+
+// ApplyImportResult writes what doesn't exist yet: the organization, the
+// applications (by client ID) and the users (by name) are never overwritten,
+// roles that exist get the imported users added. Password hashes are stored
+// as they are, with the hash type recorded on each user.
+func ApplyImportResult(result *ImportResult, dryRun bool, lang string) (*ImportSummary, error) {
+	summary := &ImportSummary{
+		Organization:        result.Organization.Name,
+		SkippedApplications: []string{},
+		SkippedUsers:        []string{},
+		Warnings:            result.Warnings,
+	}
+
+	applications := []*Application{}
+	for _, application := range result.Applications {
+		if getApplication(application.Owner, application.Name) != nil || GetApplicationByClientId(application.ClientId) != nil {
+			summary.SkippedApplications = append(summary.SkippedApplications, application.ClientId)
+			continue
+		}
+
+		applications = append(applications, application)
+	}
+	summary.Applications = len(applications)
+
+	users := []*User{}
+	for _, user := range result.Users {
+		if getUser(user.Owner, user.Name) != nil || (user.Id != "" && getUserById(user.Owner, user.Id) != nil) {
+			summary.SkippedUsers = append(summary.SkippedUsers, user.Name)
+			continue
+		}
+
+		users = append(users, user)
+	}
+	summary.Users = len(users)
+
+	// nothing is written when the organization has no seats left, so that a
+	// dry run tells the same
+	if len(applications) > 0 {
+		err := CheckSeatLimit(result.Organization.Name, SeatResourceApplication, len(applications), lang)
+		if err != nil {
+			return nil, err
+		}
+	}
+	err := CheckUsersSeatLimit(users, lang)
+	if err != nil {
+		return nil, err
+	}
+
+	organizationId := util.GetId(result.Organization.Owner, result.Organization.Name)
+	if GetOrganization(organizationId) == nil {
+		summary.OrganizationCreated = true
+		if !dryRun {
+			AddOrganization(result.Organization)
+		}
+	}
+
+	if !dryRun {
+		for _, application := range applications {
+			AddApplication(application)
+		}
+		AddUsersInBatch(users)
+	}
+
+	for _, role := range result.Roles {
+		summary.Roles++
+		if dryRun {
+			continue
+		}
+
+		oldRole := getRole(role.Owner, role.Name)
+		if oldRole == nil {
+			AddRole(role)
+			continue
+		}
+
+		for _, userId := range role.Users {
+			if !util.ContainsString(oldRole.Users, userId) {
+				oldRole.Users = append(oldRole.Users, userId)
+			}
+		}
+		UpdateRole(role.GetId(), oldRole)
+	}
+
+	return summary, nil
+}
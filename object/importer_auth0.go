@@ -0,0 +1,177 @@
+// Copyright 2023 The Casdoor Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package object
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/casdoor/casdoor/cred"
+)
+
+type Auth0Importer struct{}
+
+type auth0Export struct {
+	Users   []*auth0User   `json:"users"`
+	Clients []*auth0Client `json:"clients"`
+}
+
+type auth0User struct {
+	UserId             string                 `json:"user_id"`
+	Email              string                 `json:"email"`
+	EmailVerified      bool                   `json:"email_verified"`
+	Username           string                 `json:"username"`
+	PhoneNumber        string                 `json:"phone_number"`
+	Name               string                 `json:"name"`
+	Nickname           string                 `json:"nickname"`
+	GivenName          string                 `json:"given_name"`
+	FamilyName         string                 `json:"family_name"`
+	Picture            string                 `json:"picture"`
+	CreatedAt          string                 `json:"created_at"`
+	Blocked            bool                   `json:"blocked"`
+	PasswordHash       string                 `json:"passwordHash"`
+	CustomPasswordHash *auth0CustomHash       `json:"custom_password_hash"`
+	UserMetadata       map[string]interface{} `json:"user_metadata"`
+	AppMetadata        struct {
+		Roles []string `json:"roles"`
+	} `json:"app_metadata"`
+}
+
+type auth0CustomHash struct {
+	Hash struct {
+		Value string `json:"value"`
+	} `json:"hash"`
+}
+
+type auth0Client struct {
+	ClientId     string   `json:"client_id"`
+	Name         string   `json:"name"`
+	ClientSecret string   `json:"client_secret"`
+	Callbacks    []string `json:"callbacks"`
+}
+
+// parseAuth0Export accepts the NDJSON of a user export job, a JSON array of
+// users or an object with "users" and "clients".
+func parseAuth0Export(data []byte) (*auth0Export, error) {
+	export := &auth0Export{}
+	data = bytes.TrimSpace(data)
+	if len(data) == 0 {
+		return export, nil
+	}
+
+	switch data[0] {
+	case '[':
+		err := json.Unmarshal(data, &export.Users)
+		return export, err
+	case '{':
+		err := json.Unmarshal(data, export)
+		if err == nil && (export.Users != nil || export.Clients != nil) {
+			return export, nil
+		}
+	}
+
+	export = &auth0Export{}
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(make([]byte, 1024*1024), 16*1024*1024)
+	for scanner.Scan() {
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+
+		user := &auth0User{}
+		err := json.Unmarshal(line, user)
+		if err != nil {
+			return nil, err
+		}
+		export.Users = append(export.Users, user)
+	}
+	return export, scanner.Err()
+}
+
+// Import parses a user export of Auth0, the password hashes (bcrypt) are only
+// part of the exports made by the Auth0 support.
+func (importer *Auth0Importer) Import(data []byte, organization string) (*ImportResult, error) {
+	if organization == "" {
+		return nil, fmt.Errorf("the organization is required for Auth0")
+	}
+
+	export, err := parseAuth0Export(data)
+	if err != nil {
+		return nil, err
+	}
+
+	result := newImportResult(organization, "bcrypt")
+	for _, client := range export.Clients {
+		result.addApplication(client.ClientId, client.Name, client.ClientSecret, client.Callbacks)
+	}
+
+	for _, auth0User := range export.Users {
+		name := auth0User.Username
+		if name == "" {
+			name = auth0User.Email
+		}
+		if name == "" {
+			name = auth0User.UserId
+		}
+
+		user := result.newUser(name, "")
+		if createdTime, err := time.Parse(time.RFC3339, auth0User.CreatedAt); err == nil {
+			user.CreatedTime = createdTime.Format(time.RFC3339)
+		}
+		user.DisplayName = auth0User.Name
+		if user.DisplayName == "" {
+			user.DisplayName = auth0User.Nickname
+		}
+		user.FirstName = auth0User.GivenName
+		user.LastName = auth0User.FamilyName
+		user.Avatar = auth0User.Picture
+		user.Email = auth0User.Email
+		user.EmailVerified = auth0User.EmailVerified
+		user.Phone = auth0User.PhoneNumber
+		user.IsForbidden = auth0User.Blocked
+		user.Properties["auth0_user_id"] = auth0User.UserId
+		for key, value := range auth0User.UserMetadata {
+			if s, ok := value.(string); ok {
+				user.Properties[key] = s
+			}
+		}
+
+		// custom password hashes are only supported in the PHC string format
+		// (argon2, pbkdf2) or as bcrypt
+		passwordHash := auth0User.PasswordHash
+		if passwordHash == "" && auth0User.CustomPasswordHash != nil {
+			passwordHash = auth0User.CustomPasswordHash.Hash.Value
+		}
+		if passwordType := cred.GetPasswordTypeByHash(passwordHash); passwordType != "" {
+			user.Password = passwordHash
+			user.PasswordType = passwordType
+		} else if passwordHash != "" {
+			result.addWarning("the password hash of user: %s isn't supported, the user needs to reset the password", name)
+		}
+
+		if !result.addUser(user, auth0User.UserId) {
+			continue
+		}
+		for _, roleName := range auth0User.AppMetadata.Roles {
+			result.addRoleUser(roleName, user)
+		}
+	}
+
+	return result, nil
+}
@@ -0,0 +1,199 @@
+// Copyright 2023 The Casdoor Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package object
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/casdoor/casdoor/util"
+)
+
+type KeycloakImporter struct{}
+
+type keycloakRealm struct {
+	Realm       string            `json:"realm"`
+	DisplayName string            `json:"displayName"`
+	Clients     []*keycloakClient `json:"clients"`
+	Roles       struct {
+		Realm []*keycloakRole `json:"realm"`
+	} `json:"roles"`
+	Users []*keycloakUser `json:"users"`
+}
+
+type keycloakClient struct {
+	ClientId     string   `json:"clientId"`
+	Name         string   `json:"name"`
+	Secret       string   `json:"secret"`
+	RedirectUris []string `json:"redirectUris"`
+}
+
+type keycloakRole struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+}
+
+type keycloakUser struct {
+	Id               string              `json:"id"`
+	CreatedTimestamp int64               `json:"createdTimestamp"`
+	Username         string              `json:"username"`
+	Enabled          bool                `json:"enabled"`
+	EmailVerified    bool                `json:"emailVerified"`
+	FirstName        string              `json:"firstName"`
+	LastName         string              `json:"lastName"`
+	Email            string              `json:"email"`
+	Attributes       map[string][]string `json:"attributes"`
+	Credentials      []*struct {
+		Type           string `json:"type"`
+		SecretData     string `json:"secretData"`
+		CredentialData string `json:"credentialData"`
+	} `json:"credentials"`
+	RealmRoles []string `json:"realmRoles"`
+}
+
+type keycloakCredentialData struct {
+	HashIterations       int                 `json:"hashIterations"`
+	Algorithm            string              `json:"algorithm"`
+	AdditionalParameters map[string][]string `json:"additionalParameters"`
+}
+
+func (data *keycloakCredentialData) getParameter(name string) string {
+	if values := data.AdditionalParameters[name]; len(values) > 0 {
+		return values[0]
+	}
+	return ""
+}
+
+// getKeycloakPasswordHash returns the hash and the password type for a
+// password credential, the default PBKDF2 is kept as stored for the
+// "pbkdf2-salt" type, the other hashes are turned into PHC strings.
+func getKeycloakPasswordHash(secret *Credential, data *keycloakCredentialData) (string, string) {
+	salt := strings.TrimRight(secret.Salt, "=")
+	value := strings.TrimRight(secret.Value, "=")
+
+	switch data.Algorithm {
+	case "pbkdf2-sha256", "pbkdf2-sha512", "pbkdf2":
+		if data.Algorithm == "pbkdf2-sha256" && data.HashIterations == 27500 {
+			return secret.Value, "pbkdf2-salt"
+		}
+
+		digest := strings.TrimPrefix(data.Algorithm, "pbkdf2-")
+		if data.Algorithm == "pbkdf2" {
+			digest = "sha1"
+		}
+		return fmt.Sprintf("$pbkdf2-%s$i=%d$%s$%s", digest, data.HashIterations, salt, value), "pbkdf2"
+	case "argon2":
+		typ := data.getParameter("type")
+		if typ != "id" && typ != "i" {
+			return "", ""
+		}
+		if version := data.getParameter("version"); version != "" && version != "1.3" {
+			return "", ""
+		}
+		return fmt.Sprintf("$argon2%s$v=19$m=%s,t=%d,p=%s$%s$%s", typ, data.getParameter("memory"), data.HashIterations, data.getParameter("parallelism"), salt, value), "argon2id"
+	default:
+		return "", ""
+	}
+}
+
+var keycloakBuiltInClients = []string{"account", "account-console", "admin-cli", "broker", "realm-management", "security-admin-console"}
+
+func isKeycloakBuiltInRole(name string) bool {
+	return name == "offline_access" || name == "uma_authorization" || strings.HasPrefix(name, "default-roles-")
+}
+
+// Import parses a realm export ("Partial export" in the admin console or
+// "kc.sh export"), the users may also come from a separate users file.
+func (importer *KeycloakImporter) Import(data []byte, organization string) (*ImportResult, error) {
+	realm := &keycloakRealm{}
+	err := json.Unmarshal(data, realm)
+	if err != nil {
+		return nil, err
+	}
+
+	if organization == "" {
+		organization = realm.Realm
+	}
+	if organization == "" {
+		return nil, fmt.Errorf("the organization is required when the export has no realm")
+	}
+
+	result := newImportResult(organization, "pbkdf2-salt")
+	if realm.DisplayName != "" {
+		result.Organization.DisplayName = realm.DisplayName
+	}
+
+	for _, client := range realm.Clients {
+		if util.ContainsString(keycloakBuiltInClients, client.ClientId) {
+			continue
+		}
+		result.addApplication(client.ClientId, client.Name, client.Secret, client.RedirectUris)
+	}
+
+	for _, role := range realm.Roles.Realm {
+		if !isKeycloakBuiltInRole(role.Name) {
+			result.addRoleUser(role.Name, nil)
+		}
+	}
+
+	for _, keycloakUser := range realm.Users {
+		user := result.newUser(keycloakUser.Username, keycloakUser.Id)
+		user.CreatedTime = getImportTimeFromMillis(keycloakUser.CreatedTimestamp)
+		user.FirstName = keycloakUser.FirstName
+		user.LastName = keycloakUser.LastName
+		user.DisplayName = strings.TrimSpace(fmt.Sprintf("%s %s", keycloakUser.FirstName, keycloakUser.LastName))
+		user.Email = keycloakUser.Email
+		user.EmailVerified = keycloakUser.EmailVerified
+		user.IsForbidden = !keycloakUser.Enabled
+		for key, values := range keycloakUser.Attributes {
+			user.Properties[key] = strings.Join(values, ",")
+		}
+
+		for _, credential := range keycloakUser.Credentials {
+			if credential.Type != "password" {
+				continue
+			}
+
+			secret := Credential{}
+			_ = json.Unmarshal([]byte(credential.SecretData), &secret)
+			credentialData := keycloakCredentialData{}
+			_ = json.Unmarshal([]byte(credential.CredentialData), &credentialData)
+
+			password, passwordType := getKeycloakPasswordHash(&secret, &credentialData)
+			if passwordType == "" {
+				result.addWarning("the password of user: %s uses %s which isn't supported, the user needs to reset the password", keycloakUser.Username, credentialData.Algorithm)
+				continue
+			}
+
+			user.Password = password
+			user.PasswordType = passwordType
+			if passwordType == "pbkdf2-salt" {
+				user.PasswordSalt = secret.Salt
+			}
+		}
+
+		if !result.addUser(user, keycloakUser.Id) {
+			continue
+		}
+		for _, roleName := range keycloakUser.RealmRoles {
+			if !isKeycloakBuiltInRole(roleName) {
+				result.addRoleUser(roleName, user)
+			}
+		}
+	}
+
+	return result, nil
+}
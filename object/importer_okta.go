@@ -0,0 +1,148 @@
+// Copyright 2023 The Casdoor Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package object
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+)
+
+type OktaImporter struct{}
+
+type oktaExport struct {
+	Users  []*oktaUser  `json:"users"`
+	Groups []*oktaGroup `json:"groups"`
+	Apps   []*oktaApp   `json:"apps"`
+}
+
+type oktaUser struct {
+	Id      string `json:"id"`
+	Status  string `json:"status"`
+	Created string `json:"created"`
+	Profile struct {
+		Login       string `json:"login"`
+		Email       string `json:"email"`
+		FirstName   string `json:"firstName"`
+		LastName    string `json:"lastName"`
+		DisplayName string `json:"displayName"`
+		MobilePhone string `json:"mobilePhone"`
+	} `json:"profile"`
+	Credentials struct {
+		Password struct {
+			Hash *oktaPasswordHash `json:"hash"`
+		} `json:"password"`
+	} `json:"credentials"`
+}
+
+type oktaPasswordHash struct {
+	Algorithm  string `json:"algorithm"`
+	WorkFactor int    `json:"workFactor"`
+	Salt       string `json:"salt"`
+	Value      string `json:"value"`
+}
+
+// oktaGroup is a group of the Groups API, with the IDs of its members (from
+// "/api/v1/groups/{id}/users") in users.
+type oktaGroup struct {
+	Id      string `json:"id"`
+	Profile struct {
+		Name string `json:"name"`
+	} `json:"profile"`
+	Users []string `json:"users"`
+}
+
+type oktaApp struct {
+	Label       string `json:"label"`
+	Credentials struct {
+		OauthClient struct {
+			ClientId     string `json:"client_id"`
+			ClientSecret string `json:"client_secret"`
+		} `json:"oauthClient"`
+	} `json:"credentials"`
+	Settings struct {
+		OauthClient struct {
+			RedirectUris []string `json:"redirect_uris"`
+		} `json:"oauthClient"`
+	} `json:"settings"`
+}
+
+// Import parses the users of the Users API, either as a JSON array or in an
+// object together with the groups and the OIDC apps.
+func (importer *OktaImporter) Import(data []byte, organization string) (*ImportResult, error) {
+	if organization == "" {
+		return nil, fmt.Errorf("the organization is required for Okta")
+	}
+
+	export := &oktaExport{}
+	var err error
+	if data = bytes.TrimSpace(data); len(data) > 0 && data[0] == '[' {
+		err = json.Unmarshal(data, &export.Users)
+	} else {
+		err = json.Unmarshal(data, export)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	result := newImportResult(organization, "bcrypt")
+	for _, app := range export.Apps {
+		clientId := app.Credentials.OauthClient.ClientId
+		if clientId == "" {
+			continue
+		}
+		result.addApplication(clientId, app.Label, app.Credentials.OauthClient.ClientSecret, app.Settings.OauthClient.RedirectUris)
+	}
+
+	for _, oktaUser := range export.Users {
+		user := result.newUser(oktaUser.Profile.Login, oktaUser.Id)
+		if createdTime, err := time.Parse(time.RFC3339, oktaUser.Created); err == nil {
+			user.CreatedTime = createdTime.Format(time.RFC3339)
+		}
+		user.FirstName = oktaUser.Profile.FirstName
+		user.LastName = oktaUser.Profile.LastName
+		user.DisplayName = oktaUser.Profile.DisplayName
+		if user.DisplayName == "" {
+			user.DisplayName = strings.TrimSpace(fmt.Sprintf("%s %s", oktaUser.Profile.FirstName, oktaUser.Profile.LastName))
+		}
+		user.Email = oktaUser.Profile.Email
+		user.Phone = oktaUser.Profile.MobilePhone
+		user.IsForbidden = oktaUser.Status != "" && oktaUser.Status != "ACTIVE"
+
+		// Okta only exports hashes that were imported into it before
+		hash := oktaUser.Credentials.Password.Hash
+		if hash != nil && hash.Algorithm == "BCRYPT" {
+			user.Password = fmt.Sprintf("$2a$%02d$%s%s", hash.WorkFactor, hash.Salt, hash.Value)
+			user.PasswordType = "bcrypt"
+		} else {
+			result.addWarning("the password of user: %s can't be exported from Okta, the user needs to reset the password", oktaUser.Profile.Login)
+		}
+
+		result.addUser(user, oktaUser.Id)
+	}
+
+	for _, group := range export.Groups {
+		result.addRoleUser(group.Profile.Name, nil)
+		for _, userId := range group.Users {
+			if user, ok := result.idMap[userId]; ok {
+				result.addRoleUser(group.Profile.Name, user)
+			}
+		}
+	}
+
+	return result, nil
+}
@@ -0,0 +1,89 @@
+// Copyright 2023 The Casdoor Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package object
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestImport(t *testing.T) {
+	scenarios := []struct {
+		description  string
+		typ          string
+		data         string
+		organization string
+		user         *User
+		roleUsers    []string
+		applications int
+	}{
+		{
+			"Should import a Keycloak realm", "Keycloak", `{
+				"realm": "acme",
+				"clients": [{"clientId": "admin-cli"}, {"clientId": "web", "secret": "s", "redirectUris": ["https://acme.com/*"]}],
+				"roles": {"realm": [{"name": "offline_access"}, {"name": "staff"}]},
+				"users": [{
+					"id": "1b2e", "username": "alice", "enabled": true, "email": "alice@acme.com", "createdTimestamp": 1672531200000,
+					"credentials": [{"type": "password", "secretData": "{\"value\":\"hash\",\"salt\":\"salt\"}", "credentialData": "{\"hashIterations\":27500,\"algorithm\":\"pbkdf2-sha256\"}"}],
+					"realmRoles": ["default-roles-acme", "staff"]
+				}]
+			}`, "",
+			&User{Owner: "acme", Name: "alice", Id: "1b2e", Email: "alice@acme.com", Password: "hash", PasswordSalt: "salt", PasswordType: "pbkdf2-salt"},
+			[]string{"acme/alice"}, 1,
+		},
+		{
+			"Should import an Auth0 NDJSON export", "Auth0",
+			`{"user_id": "auth0|1", "email": "bob@acme.com", "passwordHash": "$2b$10$abc", "app_metadata": {"roles": ["staff"]}}` + "\n",
+			"acme",
+			&User{Owner: "acme", Name: "bob@acme.com", Email: "bob@acme.com", Password: "$2b$10$abc", PasswordType: "bcrypt"},
+			[]string{"acme/bob@acme.com"}, 0,
+		},
+		{
+			"Should import Okta users and groups", "Okta", `{
+				"users": [{"id": "00u1", "status": "SUSPENDED", "profile": {"login": "carol@acme.com", "email": "carol@acme.com"},
+					"credentials": {"password": {"hash": {"algorithm": "BCRYPT", "workFactor": 10, "salt": "salt", "value": "value"}}}}],
+				"groups": [{"profile": {"name": "staff"}, "users": ["00u1"]}]
+			}`, "acme",
+			&User{Owner: "acme", Name: "carol@acme.com", Id: "00u1", Email: "carol@acme.com", Password: "$2a$10$saltvalue", PasswordType: "bcrypt", IsForbidden: true},
+			[]string{"acme/carol@acme.com"}, 0,
+		},
+	}
+
+	for _, scenery := range scenarios {
+		t.Run(scenery.description, func(t *testing.T) {
+			result, err := GetImporter(scenery.typ).Import([]byte(scenery.data), scenery.organization)
+			assert.Nil(t, err)
+			assert.Equal(t, scenery.user.Owner, result.Organization.Name)
+			assert.Equal(t, scenery.applications, len(result.Applications))
+
+			assert.Equal(t, 1, len(result.Users))
+			user := result.Users[0]
+			assert.Equal(t, scenery.user.Name, user.Name)
+			assert.Equal(t, scenery.user.Email, user.Email)
+			assert.Equal(t, scenery.user.Password, user.Password)
+			assert.Equal(t, scenery.user.PasswordSalt, user.PasswordSalt)
+			assert.Equal(t, scenery.user.PasswordType, user.PasswordType)
+			assert.Equal(t, scenery.user.IsForbidden, user.IsForbidden)
+			if scenery.user.Id != "" {
+				assert.Equal(t, scenery.user.Id, user.Id)
+			}
+
+			assert.Equal(t, 1, len(result.Roles))
+			assert.Equal(t, "staff", result.Roles[0].Name)
+			assert.Equal(t, scenery.roleUsers, result.Roles[0].Users)
+		})
+	}
+}
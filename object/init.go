@@ -69,6 +69,9 @@ func getBuiltInAccountItems() []*AccountItem {
 		{Name: "Is deleted", Visible: true, ViewRule: "Admin", ModifyRule: "Admin"},
 		{Name: "WebAuthn credentials", Visible: true, ViewRule: "Self", ModifyRule: "Self"},
 		{Name: "Managed accounts", Visible: true, ViewRule: "Self", ModifyRule: "Self"},
+		{Name: "Identity verification", Visible: true, ViewRule: "Self", ModifyRule: "Immutable"},
+		{Name: "Security questions", Visible: true, ViewRule: "Self", ModifyRule: "Self"},
+		{Name: "Account recovery", Visible: true, ViewRule: "Admin", ModifyRule: "Admin"},
 	}
 }
 
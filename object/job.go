@@ -0,0 +1,216 @@
+// Copyright 2023 The Casdoor Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package object
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/beego/beego/logs"
+	"github.com/casdoor/casdoor/util"
+	"github.com/xorm-io/core"
+)
+
+const (
+	JobStatusRunning   = "Running"
+	JobStatusSucceeded = "Succeeded"
+	JobStatusFailed    = "Failed"
+)
+
+// The lease of a running job is renewed every jobHeartbeatInterval, so that
+// another node takes the job over jobLeaseTimeout after a node died.
+const (
+	jobHeartbeatInterval = 10 * time.Second
+	jobLeaseTimeout      = 30 * time.Second
+)
+
+// Job is the cluster-wide state of a background job. The row is also the
+// lock of the job: a node runs the job only after it moved the lease of the
+// row to itself, which only succeeds once the previous lease expired.
+type Job struct {
+	Owner       string `xorm:"varchar(100) notnull pk" json:"owner"`
+	Name        string `xorm:"varchar(100) notnull pk" json:"name"`
+	CreatedTime string `xorm:"varchar(100)" json:"createdTime"`
+
+	Node            string `xorm:"varchar(100)" json:"node"`
+	LeaseExpireTime int64  `json:"leaseExpireTime"`
+	IsRunning       bool   `json:"isRunning"`
+	LastStartTime   string `xorm:"varchar(100)" json:"lastStartTime"`
+	LastEndTime     string `xorm:"varchar(100)" json:"lastEndTime"`
+	LastStatus      string `xorm:"varchar(100)" json:"lastStatus"`
+	LastError       string `xorm:"mediumtext" json:"lastError"`
+	RunCount        int    `json:"runCount"`
+	FailCount       int    `json:"failCount"`
+}
+
+var nodeName = getNodeName()
+
+func getNodeName() string {
+	hostname, err := os.Hostname()
+	if err != nil {
+		hostname = "unknown"
+	}
+	return fmt.Sprintf("%s-%d-%s", hostname, os.Getpid(), util.GenerateId()[:8])
+}
+
+func GetNodeName() string {
+	return nodeName
+}
+
+func GetJobs(owner string) []*Job {
+	jobs := []*Job{}
+	err := adapter.Engine.Asc("name").Find(&jobs, &Job{Owner: owner})
+	if err != nil {
+		panic(err)
+	}
+
+	return jobs
+}
+
+func getJob(owner string, name string) *Job {
+	if owner == "" || name == "" {
+		return nil
+	}
+
+	job := Job{Owner: owner, Name: name}
+	existed, err := adapter.Engine.Get(&job)
+	if err != nil {
+		panic(err)
+	}
+
+	if existed {
+		return &job
+	} else {
+		return nil
+	}
+}
+
+func GetJob(id string) *Job {
+	owner, name := util.GetOwnerAndNameFromId(id)
+	return getJob(owner, name)
+}
+
+func ensureJob(name string) {
+	if getJob("admin", name) != nil {
+		return
+	}
+
+	job := &Job{Owner: "admin", Name: name, CreatedTime: util.GetCurrentTime()}
+	_, err := adapter.Engine.Insert(job)
+	if err != nil && getJob("admin", name) == nil {
+		panic(err)
+	}
+}
+
+// acquireJob moves the lease of the job to this node until leaseExpireTime,
+// it fails when another node (or an earlier run of this node) holds it.
+func acquireJob(name string, leaseExpireTime time.Time) bool {
+	ensureJob(name)
+
+	job := &Job{
+		Node:            nodeName,
+		LeaseExpireTime: leaseExpireTime.Unix(),
+		IsRunning:       true,
+		LastStartTime:   util.GetCurrentTime(),
+	}
+	affected, err := adapter.Engine.Where("owner = ? and name = ? and lease_expire_time < ?", "admin", name, time.Now().Unix()).
+		Cols("node", "lease_expire_time", "is_running", "last_start_time").Update(job)
+	if err != nil {
+		panic(err)
+	}
+
+	return affected != 0
+}
+
+func renewJob(name string, leaseExpireTime time.Time) {
+	_, err := adapter.Engine.Where("owner = ? and name = ? and node = ? and lease_expire_time < ?", "admin", name, nodeName, leaseExpireTime.Unix()).
+		Cols("lease_expire_time").Update(&Job{LeaseExpireTime: leaseExpireTime.Unix()})
+	if err != nil {
+		logs.Warning(fmt.Sprintf("failed to renew the lease of job: %s, %s", name, err.Error()))
+	}
+}
+
+func finishJob(name string, jobErr error) {
+	job := getJob("admin", name)
+	if job == nil {
+		return
+	}
+
+	job.IsRunning = false
+	job.LastEndTime = util.GetCurrentTime()
+	job.RunCount++
+	job.LastStatus = JobStatusSucceeded
+	job.LastError = ""
+	if jobErr != nil {
+		job.FailCount++
+		job.LastStatus = JobStatusFailed
+		job.LastError = jobErr.Error()
+	}
+
+	_, err := adapter.Engine.ID(core.PK{job.Owner, job.Name}).Cols("is_running", "last_end_time", "run_count", "last_status", "last_error", "fail_count").Update(job)
+	if err != nil {
+		panic(err)
+	}
+}
+
+// RunClusterJob runs fn unless the job already ran on any node within the
+// last interval, so that a job scheduled on every node runs once per
+// interval cluster-wide. It returns whether fn was run on this node.
+func RunClusterJob(name string, interval time.Duration, fn func() error) bool {
+	// the nodes that aren't in maintenance mode take the job over
+	if IsMaintenanceMode() {
+		return false
+	}
+
+	// a little less than the interval, so that the next tick of the same
+	// schedule isn't refused
+	start := time.Now()
+	leaseExpireTime := start.Add(interval - interval/10)
+	if !acquireJob(name, leaseExpireTime) {
+		return false
+	}
+
+	done := make(chan struct{})
+	util.SafeGoroutine(func() {
+		ticker := time.NewTicker(jobHeartbeatInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				renewJob(name, time.Now().Add(jobLeaseTimeout))
+			}
+		}
+	})
+
+	var err error
+	func() {
+		defer func() {
+			if r := recover(); r != nil {
+				err = fmt.Errorf("%v", r)
+			}
+		}()
+		err = fn()
+	}()
+	close(done)
+
+	if err != nil {
+		logs.Warning(fmt.Sprintf("job: %s failed, %s", name, err.Error()))
+	}
+	finishJob(name, err)
+	return true
+}
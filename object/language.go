@@ -0,0 +1,44 @@
+// Copyright 2023 The Casdoor Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package object
+
+import "github.com/casdoor/casdoor/conf"
+
+func (organization *Organization) getDefaultLanguage() string {
+	if organization == nil {
+		return ""
+	}
+	return conf.GetSupportedLanguage(organization.DefaultLanguage)
+}
+
+// GetOrganizationLanguage returns the default language of the organization,
+// or "en" when it has none that is supported.
+func GetOrganizationLanguage(organization string) string {
+	if organization != "" {
+		if language := getOrganization("admin", organization).getDefaultLanguage(); language != "" {
+			return language
+		}
+	}
+	return "en"
+}
+
+// GetLanguage returns the language of the messages sent to the user: the
+// language of the user, otherwise the default language of its organization.
+func (user *User) GetLanguage() string {
+	if language := conf.GetSupportedLanguage(user.Language); language != "" {
+		return language
+	}
+	return GetOrganizationLanguage(user.Owner)
+}
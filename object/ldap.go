@@ -406,18 +406,23 @@ func SyncLdapUsers(owner string, users []LdapRespUser, ldapId string) (*[]LdapRe
 	}
 	tag := strings.Join(ou, ".")
 
+	newUsers := []LdapRespUser{}
 	for _, user := range users {
-		found := false
-		if len(existUuids) > 0 {
-			for _, existUuid := range existUuids {
-				if user.Uuid == existUuid {
-					existUsers = append(existUsers, user)
-					found = true
-				}
-			}
+		if util.ContainsString(existUuids, user.Uuid) {
+			existUsers = append(existUsers, user)
+		} else {
+			newUsers = append(newUsers, user)
 		}
+	}
+
+	// the new users fail together when the organization has no seats left
+	if len(newUsers) > 0 && CheckSeatLimit(owner, SeatResourceUser, len(newUsers), "en") != nil {
+		failedUsers = append(failedUsers, newUsers...)
+		return &existUsers, &failedUsers
+	}
 
-		if !found && !AddUser(&User{
+	for _, user := range newUsers {
+		if !AddUser(&User{
 			Owner:       owner,
 			Name:        buildLdapUserName(user.Uid, user.UidNumber),
 			CreatedTime: util.GetCurrentTime(),
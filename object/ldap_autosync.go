@@ -74,26 +74,35 @@ func (l *LdapAutoSynchronizer) syncRoutine(ldap *Ldap, stopChan chan struct{}) {
 		case <-ticker.C:
 		}
 
-		UpdateLdapSyncTime(ldap.Id)
-		// fetch all users
-		conn, err := ldap.GetLdapConn()
-		if err != nil {
-			logs.Warning(fmt.Sprintf("autoSync failed for %s, error %s", ldap.Id, err))
-			continue
-		}
+		// every node runs the ticker, the job lock lets one of them sync
+		RunClusterJob(fmt.Sprintf("ldap-%s", ldap.Id), time.Duration(ldap.AutoSync)*time.Minute, func() error {
+			return syncLdapUsersOnce(ldap)
+		})
+	}
+}
 
-		users, err := conn.GetLdapUsers(ldap.BaseDn)
-		if err != nil {
-			logs.Warning(fmt.Sprintf("autoSync failed for %s, error %s", ldap.Id, err))
-			continue
-		}
-		existed, failed := SyncLdapUsers(ldap.Owner, LdapUsersToLdapRespUsers(users), ldap.Id)
-		if len(*failed) != 0 {
-			logs.Warning(fmt.Sprintf("ldap autosync,%d new users,but %d user failed during :", len(users)-len(*existed)-len(*failed), len(*failed)), *failed)
-		} else {
-			logs.Info(fmt.Sprintf("ldap autosync success, %d new users, %d existing users", len(users)-len(*existed), len(*existed)))
-		}
+func syncLdapUsersOnce(ldap *Ldap) error {
+	UpdateLdapSyncTime(ldap.Id)
+	// fetch all users
+	conn, err := ldap.GetLdapConn()
+	if err != nil {
+		logs.Warning(fmt.Sprintf("autoSync failed for %s, error %s", ldap.Id, err))
+		return err
 	}
+
+	users, err := conn.GetLdapUsers(ldap.BaseDn)
+	if err != nil {
+		logs.Warning(fmt.Sprintf("autoSync failed for %s, error %s", ldap.Id, err))
+		return err
+	}
+	existed, failed := SyncLdapUsers(ldap.Owner, LdapUsersToLdapRespUsers(users), ldap.Id)
+	if len(*failed) != 0 {
+		logs.Warning(fmt.Sprintf("ldap autosync,%d new users,but %d user failed during :", len(users)-len(*existed)-len(*failed), len(*failed)), *failed)
+		return fmt.Errorf("%d users failed to sync", len(*failed))
+	} else {
+		logs.Info(fmt.Sprintf("ldap autosync success, %d new users, %d existing users", len(users)-len(*existed), len(*existed)))
+	}
+	return nil
 }
 
 // LdapAutoSynchronizerStartUpAll
@@ -0,0 +1,349 @@
+// Copyright 2023 The Casdoor Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package object
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/beego/beego/logs"
+	"github.com/casdoor/casdoor/i18n"
+	"github.com/casdoor/casdoor/util"
+	"github.com/xorm-io/core"
+)
+
+const (
+	LoginAlertTypeNewDevice        = "new-device"
+	LoginAlertTypeImpossibleTravel = "impossible-travel"
+
+	LoginAlertStatePending   = "Pending"
+	LoginAlertStateConfirmed = "Confirmed"
+
+	defaultMaxTravelSpeed    = 1000
+	defaultMinTravelDistance = 500
+	earthRadiusKm            = 6371
+)
+
+// LoginAlertConfig is the sensitivity of the login alerts of an
+// organization. MaxTravelSpeed (km/h) is the fastest plausible travel
+// between two logins, MinTravelDistance (km) ignores the jumps that come
+// from the inaccuracy of IP locations. Channels are "Email" and "SMS".
+type LoginAlertConfig struct {
+	EnableNewDevice        bool     `json:"enableNewDevice"`
+	EnableImpossibleTravel bool     `json:"enableImpossibleTravel"`
+	MaxTravelSpeed         int      `json:"maxTravelSpeed"`
+	MinTravelDistance      int      `json:"minTravelDistance"`
+	Channels               []string `json:"channels"`
+}
+
+// LoginDevice is a device a user signed in from, DeviceId is the device
+// cookie of browsers.
+type LoginDevice struct {
+	Owner       string `xorm:"varchar(100) notnull pk" json:"owner"`
+	Name        string `xorm:"varchar(100) notnull pk" json:"name"`
+	CreatedTime string `xorm:"varchar(100)" json:"createdTime"`
+
+	User          string  `xorm:"varchar(100) index" json:"user"`
+	DeviceId      string  `xorm:"varchar(100) index" json:"deviceId"`
+	Device        string  `xorm:"varchar(100)" json:"device"`
+	ClientIp      string  `xorm:"varchar(100)" json:"clientIp"`
+	Location      string  `xorm:"varchar(200)" json:"location"`
+	Latitude      float64 `json:"latitude"`
+	Longitude     float64 `json:"longitude"`
+	LastLoginTime string  `xorm:"varchar(100)" json:"lastLoginTime"`
+	IsConfirmed   bool    `json:"isConfirmed"`
+}
+
+type LoginAlert struct {
+	Owner       string `xorm:"varchar(100) notnull pk" json:"owner"`
+	Name        string `xorm:"varchar(100) notnull pk" json:"name"`
+	CreatedTime string `xorm:"varchar(100)" json:"createdTime"`
+
+	User        string `xorm:"varchar(100) index" json:"user"`
+	Type        string `xorm:"varchar(100)" json:"type"`
+	LoginDevice string `xorm:"varchar(100)" json:"loginDevice"`
+	Device      string `xorm:"varchar(100)" json:"device"`
+	ClientIp    string `xorm:"varchar(100)" json:"clientIp"`
+	Location    string `xorm:"varchar(200)" json:"location"`
+	Message     string `xorm:"varchar(500)" json:"message"`
+	State       string `xorm:"varchar(100)" json:"state"`
+}
+
+// LoginContext is what is known of the device of a login.
+type LoginContext struct {
+	DeviceId  string
+	UserAgent string
+	ClientIp  string
+	Location  *util.Location
+}
+
+func (loginAlert *LoginAlert) GetId() string {
+	return fmt.Sprintf("%s/%s", loginAlert.Owner, loginAlert.Name)
+}
+
+func getLoginDevices(owner string, user string) []*LoginDevice {
+	loginDevices := []*LoginDevice{}
+	err := adapter.Engine.Desc("last_login_time").Find(&loginDevices, &LoginDevice{Owner: owner, User: user})
+	if err != nil {
+		panic(err)
+	}
+
+	return loginDevices
+}
+
+// findLoginDevice matches the device cookie, or for clients that don't keep
+// cookies the same device name from the same IP.
+func findLoginDevice(loginDevices []*LoginDevice, deviceId string, device string, clientIp string) *LoginDevice {
+	for _, loginDevice := range loginDevices {
+		if deviceId != "" && loginDevice.DeviceId == deviceId {
+			return loginDevice
+		}
+	}
+	for _, loginDevice := range loginDevices {
+		if loginDevice.Device == device && loginDevice.ClientIp == clientIp {
+			return loginDevice
+		}
+	}
+	return nil
+}
+
+// getTravelDistance returns the great-circle distance in km.
+func getTravelDistance(latitude1 float64, longitude1 float64, latitude2 float64, longitude2 float64) float64 {
+	toRadians := func(degree float64) float64 {
+		return degree * math.Pi / 180
+	}
+
+	dLatitude := toRadians(latitude2 - latitude1)
+	dLongitude := toRadians(longitude2 - longitude1)
+	a := math.Sin(dLatitude/2)*math.Sin(dLatitude/2) + math.Cos(toRadians(latitude1))*math.Cos(toRadians(latitude2))*math.Sin(dLongitude/2)*math.Sin(dLongitude/2)
+	return 2 * earthRadiusKm * math.Asin(math.Sqrt(a))
+}
+
+// isImpossibleTravel tells whether going from the last login to this one
+// needs to be faster than the max speed.
+func isImpossibleTravel(config *LoginAlertConfig, last *LoginDevice, location *util.Location, now time.Time) bool {
+	if last == nil || location == nil || (last.Latitude == 0 && last.Longitude == 0) || (location.Latitude == 0 && location.Longitude == 0) {
+		return false
+	}
+
+	lastTime, err := time.Parse(time.RFC3339, last.LastLoginTime)
+	if err != nil {
+		return false
+	}
+
+	maxSpeed := float64(config.MaxTravelSpeed)
+	if maxSpeed <= 0 {
+		maxSpeed = defaultMaxTravelSpeed
+	}
+	minDistance := float64(config.MinTravelDistance)
+	if minDistance <= 0 {
+		minDistance = defaultMinTravelDistance
+	}
+
+	distance := getTravelDistance(last.Latitude, last.Longitude, location.Latitude, location.Longitude)
+	if distance < minDistance {
+		return false
+	}
+
+	hours := now.Sub(lastTime).Hours()
+	return hours <= 0 || distance/hours > maxSpeed
+}
+
+// getLoginAlertType returns the alert of a login, if any. The first device
+// of a user is never new, and the devices the user confirmed don't raise
+// impossible travel alerts.
+func getLoginAlertType(config *LoginAlertConfig, loginDevices []*LoginDevice, loginDevice *LoginDevice, location *util.Location, now time.Time) string {
+	if config == nil || len(loginDevices) == 0 {
+		return ""
+	}
+
+	if loginDevice == nil {
+		if config.EnableNewDevice {
+			return LoginAlertTypeNewDevice
+		}
+	} else if loginDevice.IsConfirmed {
+		return ""
+	}
+
+	if config.EnableImpossibleTravel && isImpossibleTravel(config, loginDevices[0], location, now) {
+		return LoginAlertTypeImpossibleTravel
+	}
+	return ""
+}
+
+func getLoginAlertMessage(typ string, loginDevice *LoginDevice, last *LoginDevice, lang string) string {
+	if typ == LoginAlertTypeNewDevice {
+		return fmt.Sprintf(i18n.Translate(lang, "security:A new sign-in to your account from %s (IP: %s, location: %s)."), loginDevice.Device, loginDevice.ClientIp, loginDevice.Location)
+	}
+	return fmt.Sprintf(i18n.Translate(lang, "security:A sign-in to your account from %s (IP: %s) shortly after a sign-in from %s (IP: %s), too far to travel in between."), loginDevice.Location, loginDevice.ClientIp, last.Location, last.ClientIp)
+}
+
+// notifyLoginAlert sends the alert over the channels of the organization
+// with the providers of its default application.
+func notifyLoginAlert(user *User, config *LoginAlertConfig, loginAlert *LoginAlert) {
+	application, err := GetDefaultApplication(util.GetId("admin", user.Owner))
+	if err != nil {
+		return
+	}
+
+	lang := user.GetLanguage()
+	content := fmt.Sprintf(i18n.Translate(lang, "security:%s If this was you, confirm it on the security activity of your account, otherwise report it there and change your password."), loginAlert.Message)
+	for _, channel := range config.Channels {
+		if channel == "Email" && user.Email != "" {
+			if provider := application.GetEmailProvider(); provider != nil {
+				err = SendEmail(provider, i18n.Translate(lang, "security:New sign-in to your account"), content, user.Email, provider.DisplayName)
+			}
+		} else if channel == "SMS" && user.Phone != "" {
+			// the message goes into the template of the SMS provider
+			phone, ok := util.GetE164Number(user.Phone, user.GetCountryCode(""))
+			if provider := application.GetSmsProvider(); provider != nil && ok {
+				err = SendSms(provider, loginAlert.Message, phone)
+			}
+		}
+		if err != nil {
+			logs.Warning(fmt.Sprintf("failed to send the login alert: %s over %s, %s", loginAlert.GetId(), channel, err.Error()))
+		}
+	}
+}
+
+func addLoginAlertRecord(loginAlert *LoginAlert) {
+	record := &Record{
+		Name:         util.GenerateId(),
+		CreatedTime:  util.GetCurrentTime(),
+		Organization: loginAlert.Owner,
+		ClientIp:     loginAlert.ClientIp,
+		Location:     loginAlert.Location,
+		User:         loginAlert.User,
+		Method:       "POST",
+		RequestUri:   fmt.Sprintf("/api/get-login-alerts?id=%s", loginAlert.GetId()),
+		Action:       "login-alert",
+	}
+	AddRecord(record)
+}
+
+// CheckLoginAlerts records the device of a login and alerts the user of
+// logins from new devices or after an impossible travel, as configured for
+// the organization.
+func CheckLoginAlerts(userId string, loginContext *LoginContext) {
+	user := GetUser(userId)
+	if user == nil {
+		return
+	}
+
+	var config *LoginAlertConfig
+	if organization := GetOrganizationByUser(user); organization != nil {
+		config = organization.LoginAlert
+	}
+
+	now := time.Now()
+	device := util.GetDeviceFromUserAgent(loginContext.UserAgent)
+	loginDevices := getLoginDevices(user.Owner, user.Name)
+	loginDevice := findLoginDevice(loginDevices, loginContext.DeviceId, device, loginContext.ClientIp)
+	typ := getLoginAlertType(config, loginDevices, loginDevice, loginContext.Location, now)
+
+	isNew := loginDevice == nil
+	if isNew {
+		loginDevice = &LoginDevice{
+			Owner:       user.Owner,
+			Name:        util.GenerateId(),
+			CreatedTime: now.Format(time.RFC3339),
+			User:        user.Name,
+		}
+	}
+	if loginContext.DeviceId != "" {
+		loginDevice.DeviceId = loginContext.DeviceId
+	}
+	loginDevice.Device = device
+	loginDevice.ClientIp = loginContext.ClientIp
+	loginDevice.Location = loginContext.Location.String()
+	loginDevice.Latitude, loginDevice.Longitude = 0, 0
+	if loginContext.Location != nil {
+		loginDevice.Latitude, loginDevice.Longitude = loginContext.Location.Latitude, loginContext.Location.Longitude
+	}
+	loginDevice.LastLoginTime = now.Format(time.RFC3339)
+
+	var err error
+	if isNew {
+		_, err = adapter.Engine.Insert(loginDevice)
+	} else {
+		_, err = adapter.Engine.ID(core.PK{loginDevice.Owner, loginDevice.Name}).AllCols().Update(loginDevice)
+	}
+	if err != nil {
+		panic(err)
+	}
+
+	if typ == "" {
+		return
+	}
+
+	loginAlert := &LoginAlert{
+		Owner:       user.Owner,
+		Name:        util.GenerateId(),
+		CreatedTime: now.Format(time.RFC3339),
+		User:        user.Name,
+		Type:        typ,
+		LoginDevice: loginDevice.Name,
+		Device:      loginDevice.Device,
+		ClientIp:    loginDevice.ClientIp,
+		Location:    loginDevice.Location,
+		Message:     getLoginAlertMessage(typ, loginDevice, loginDevices[0], user.GetLanguage()),
+		State:       LoginAlertStatePending,
+	}
+	_, err = adapter.Engine.Insert(loginAlert)
+	if err != nil {
+		panic(err)
+	}
+
+	addLoginAlertRecord(loginAlert)
+	notifyLoginAlert(user, config, loginAlert)
+}
+
+func GetUserLoginAlerts(user *User) []*LoginAlert {
+	loginAlerts := []*LoginAlert{}
+	err := adapter.Engine.Desc("created_time").Limit(100).Find(&loginAlerts, &LoginAlert{Owner: user.Owner, User: user.Name})
+	if err != nil {
+		panic(err)
+	}
+
+	return loginAlerts
+}
+
+// ConfirmLoginAlert marks the alert and its device as the user's own, the
+// device raises no more alerts.
+func ConfirmLoginAlert(user *User, id string, lang string) (*LoginAlert, error) {
+	owner, name := util.GetOwnerAndNameFromId(id)
+	loginAlert := &LoginAlert{Owner: owner, Name: name}
+	existed, err := adapter.Engine.Get(loginAlert)
+	if err != nil {
+		panic(err)
+	}
+	if !existed || loginAlert.Owner != user.Owner || loginAlert.User != user.Name {
+		return nil, fmt.Errorf(i18n.Translate(lang, "security:The login alert: %s does not exist"), id)
+	}
+
+	loginAlert.State = LoginAlertStateConfirmed
+	_, err = adapter.Engine.ID(core.PK{loginAlert.Owner, loginAlert.Name}).Cols("state").Update(loginAlert)
+	if err != nil {
+		panic(err)
+	}
+
+	_, err = adapter.Engine.ID(core.PK{loginAlert.Owner, loginAlert.LoginDevice}).Cols("is_confirmed").Update(&LoginDevice{IsConfirmed: true})
+	if err != nil {
+		panic(err)
+	}
+
+	return loginAlert, nil
+}
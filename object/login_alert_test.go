@@ -0,0 +1,79 @@
+// Copyright 2023 The Casdoor Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package object
+
+import (
+	"testing"
+	"time"
+
+	"github.com/casdoor/casdoor/util"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetTravelDistance(t *testing.T) {
+	// Berlin to Paris is about 878 km
+	distance := getTravelDistance(52.52, 13.405, 48.8566, 2.3522)
+	assert.InDelta(t, 878, distance, 5)
+	assert.Equal(t, 0.0, getTravelDistance(52.52, 13.405, 52.52, 13.405))
+}
+
+func TestGetLoginAlertType(t *testing.T) {
+	now := time.Date(2023, 6, 1, 12, 0, 0, 0, time.UTC)
+	config := &LoginAlertConfig{EnableNewDevice: true, EnableImpossibleTravel: true}
+	berlin := &LoginDevice{Name: "1", DeviceId: "a", Device: "Chrome on Windows", ClientIp: "1.1.1.1", Latitude: 52.52, Longitude: 13.405, LastLoginTime: now.Add(-time.Hour).Format(time.RFC3339)}
+	confirmed := &LoginDevice{Name: "2", DeviceId: "b", Device: "Safari on iOS", ClientIp: "2.2.2.2", IsConfirmed: true}
+	loginDevices := []*LoginDevice{berlin, confirmed}
+
+	tokyo := &util.Location{Country: "JP", Latitude: 35.6762, Longitude: 139.6503}
+	potsdam := &util.Location{Country: "DE", Latitude: 52.39, Longitude: 13.06}
+
+	scenarios := []struct {
+		description  string
+		config       *LoginAlertConfig
+		loginDevices []*LoginDevice
+		loginDevice  *LoginDevice
+		location     *util.Location
+		now          time.Time
+		expected     string
+	}{
+		{"no config", nil, loginDevices, nil, tokyo, now, ""},
+		{"first device of the user", config, []*LoginDevice{}, nil, nil, now, ""},
+		{"new device", config, loginDevices, nil, potsdam, now, LoginAlertTypeNewDevice},
+		{"new device alerts disabled", &LoginAlertConfig{EnableImpossibleTravel: true}, loginDevices, nil, potsdam, now, ""},
+		{"known device nearby", config, loginDevices, berlin, potsdam, now, ""},
+		{"known device too far in an hour", config, loginDevices, berlin, tokyo, now, LoginAlertTypeImpossibleTravel},
+		{"known device far after a day", config, loginDevices, berlin, tokyo, now.Add(24 * time.Hour), ""},
+		{"confirmed device", config, loginDevices, confirmed, tokyo, now, ""},
+		{"unknown location", config, loginDevices, berlin, nil, now, ""},
+		{"lower speed limit", &LoginAlertConfig{EnableImpossibleTravel: true, MaxTravelSpeed: 100}, loginDevices, berlin, tokyo, now.Add(24 * time.Hour), LoginAlertTypeImpossibleTravel},
+	}
+
+	for _, scenery := range scenarios {
+		t.Run(scenery.description, func(t *testing.T) {
+			assert.Equal(t, scenery.expected, getLoginAlertType(scenery.config, scenery.loginDevices, scenery.loginDevice, scenery.location, scenery.now))
+		})
+	}
+}
+
+func TestFindLoginDevice(t *testing.T) {
+	browser := &LoginDevice{Name: "1", DeviceId: "a", Device: "Chrome on Windows", ClientIp: "1.1.1.1"}
+	client := &LoginDevice{Name: "2", Device: "curl/8.0.1", ClientIp: "2.2.2.2"}
+	loginDevices := []*LoginDevice{browser, client}
+
+	assert.Equal(t, browser, findLoginDevice(loginDevices, "a", "Chrome on macOS", "3.3.3.3"), "the device cookie wins")
+	assert.Equal(t, client, findLoginDevice(loginDevices, "", "curl/8.0.1", "2.2.2.2"))
+	assert.Nil(t, findLoginDevice(loginDevices, "", "curl/8.0.1", "3.3.3.3"))
+	assert.Nil(t, findLoginDevice(loginDevices, "c", "Firefox on Linux", "1.1.1.1"))
+}
@@ -0,0 +1,410 @@
+// Copyright 2023 The Casdoor Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package object
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/Knetic/govaluate"
+	"github.com/beego/beego/logs"
+	"github.com/casdoor/casdoor/util"
+	"github.com/xorm-io/core"
+)
+
+const (
+	LoginHookEventPreSignup          = "pre-signup"
+	LoginHookEventPostAuthentication = "post-authentication"
+	LoginHookEventPreTokenIssuance   = "pre-token-issuance"
+
+	LoginHookTypeWebhook    = "Webhook"
+	LoginHookTypeExpression = "Expression"
+
+	LoginHookActionAllow   = "allow"
+	LoginHookActionDeny    = "deny"
+	LoginHookActionRequire = "require"
+
+	loginHookDefaultTimeout = 5
+)
+
+// LoginHook is a synchronous extension point of the login flow. A webhook
+// hook posts the LoginHookContext and reads a LoginHookResult back, an
+// expression hook returns its result when the expression is true.
+type LoginHook struct {
+	Owner       string `xorm:"varchar(100) notnull pk" json:"owner"`
+	Name        string `xorm:"varchar(100) notnull pk" json:"name"`
+	CreatedTime string `xorm:"varchar(100)" json:"createdTime"`
+
+	Organization string `xorm:"varchar(100) index" json:"organization"`
+	Application  string `xorm:"varchar(100)" json:"application"`
+	Event        string `xorm:"varchar(100)" json:"event"`
+	Type         string `xorm:"varchar(100)" json:"type"`
+
+	Url     string    `xorm:"varchar(200)" json:"url"`
+	Headers []*Header `xorm:"mediumtext" json:"headers"`
+	Timeout int       `json:"timeout"`
+
+	Expression string                 `xorm:"mediumtext" json:"expression"`
+	Action     string                 `xorm:"varchar(100)" json:"action"`
+	Message    string                 `xorm:"varchar(500)" json:"message"`
+	Steps      []string               `xorm:"varchar(1000)" json:"steps"`
+	Claims     map[string]interface{} `xorm:"mediumtext" json:"claims"`
+
+	FailOpen  bool `json:"failOpen"`
+	IsEnabled bool `json:"isEnabled"`
+}
+
+type LoginHookContext struct {
+	Event        string                 `json:"event"`
+	Organization string                 `json:"organization"`
+	Application  string                 `json:"application"`
+	User         *User                  `json:"user"`
+	Ip           string                 `json:"ip"`
+	UserAgent    string                 `json:"userAgent"`
+	Claims       map[string]interface{} `json:"claims,omitempty"`
+}
+
+// LoginHookResult is what a hook answers. Claims are added to the token
+// before it is issued, and to the properties of the user for the other
+// events. Steps ask the user to do more before the login completes, they are
+// only supported after the authentication and deny the other events.
+type LoginHookResult struct {
+	Action      string                 `json:"action"`
+	Message     string                 `json:"message"`
+	Steps       []string               `json:"steps"`
+	RedirectUrl string                 `json:"redirectUrl"`
+	Claims      map[string]interface{} `json:"claims"`
+}
+
+func GetLoginHookCount(owner, field, value string) int {
+	session := GetSession(owner, -1, -1, field, value, "", "")
+	count, err := session.Count(&LoginHook{})
+	if err != nil {
+		panic(err)
+	}
+
+	return int(count)
+}
+
+func GetLoginHooks(owner string) []*LoginHook {
+	hooks := []*LoginHook{}
+	err := adapter.Engine.Desc("created_time").Find(&hooks, &LoginHook{Owner: owner})
+	if err != nil {
+		panic(err)
+	}
+
+	return hooks
+}
+
+func GetPaginationLoginHooks(owner string, offset, limit int, field, value, sortField, sortOrder string) []*LoginHook {
+	hooks := []*LoginHook{}
+	session := GetSession(owner, offset, limit, field, value, sortField, sortOrder)
+	err := session.Find(&hooks)
+	if err != nil {
+		panic(err)
+	}
+
+	return hooks
+}
+
+func getLoginHook(owner string, name string) *LoginHook {
+	if owner == "" || name == "" {
+		return nil
+	}
+
+	hook := LoginHook{Owner: owner, Name: name}
+	existed, err := adapter.Engine.Get(&hook)
+	if err != nil {
+		panic(err)
+	}
+
+	if existed {
+		return &hook
+	} else {
+		return nil
+	}
+}
+
+func GetLoginHook(id string) *LoginHook {
+	owner, name := util.GetOwnerAndNameFromId(id)
+	return getLoginHook(owner, name)
+}
+
+// checkLoginHook makes sure an expression hook compiles before it is saved,
+// so that a typo doesn't block every login of the organization.
+func checkLoginHook(hook *LoginHook) error {
+	switch hook.Event {
+	case LoginHookEventPreSignup, LoginHookEventPostAuthentication, LoginHookEventPreTokenIssuance:
+	default:
+		return fmt.Errorf("the event: %s isn't supported", hook.Event)
+	}
+
+	switch hook.Type {
+	case LoginHookTypeWebhook:
+		if !strings.HasPrefix(hook.Url, "http://") && !strings.HasPrefix(hook.Url, "https://") {
+			return fmt.Errorf("the URL: %s must be an HTTP(S) URL", hook.Url)
+		}
+	case LoginHookTypeExpression:
+		if _, err := govaluate.NewEvaluableExpression(hook.Expression); err != nil {
+			return fmt.Errorf("the expression is invalid: %s", err.Error())
+		}
+		if hook.Action != LoginHookActionAllow && hook.Action != LoginHookActionDeny && hook.Action != LoginHookActionRequire {
+			return fmt.Errorf("the action: %s isn't supported", hook.Action)
+		}
+	default:
+		return fmt.Errorf("the type: %s isn't supported", hook.Type)
+	}
+	return nil
+}
+
+func UpdateLoginHook(id string, hook *LoginHook) (bool, error) {
+	owner, name := util.GetOwnerAndNameFromId(id)
+	if getLoginHook(owner, name) == nil {
+		return false, nil
+	}
+
+	if err := checkLoginHook(hook); err != nil {
+		return false, err
+	}
+
+	affected, err := adapter.Engine.ID(core.PK{owner, name}).AllCols().Update(hook)
+	if err != nil {
+		panic(err)
+	}
+
+	return affected != 0, nil
+}
+
+func AddLoginHook(hook *LoginHook) (bool, error) {
+	if err := checkLoginHook(hook); err != nil {
+		return false, err
+	}
+
+	affected, err := adapter.Engine.Insert(hook)
+	if err != nil {
+		panic(err)
+	}
+
+	return affected != 0, nil
+}
+
+func DeleteLoginHook(hook *LoginHook) bool {
+	affected, err := adapter.Engine.ID(core.PK{hook.Owner, hook.Name}).Delete(&LoginHook{})
+	if err != nil {
+		panic(err)
+	}
+
+	return affected != 0
+}
+
+func (hook *LoginHook) GetId() string {
+	return fmt.Sprintf("%s/%s", hook.Owner, hook.Name)
+}
+
+// getMatchedLoginHooks returns the enabled hooks of the event for the
+// organization, an empty application of a hook matches all applications.
+func getMatchedLoginHooks(organization string, application string, event string) []*LoginHook {
+	hooks := []*LoginHook{}
+	err := adapter.Engine.Asc("name").Find(&hooks, &LoginHook{Organization: organization, Event: event, IsEnabled: true})
+	if err != nil {
+		panic(err)
+	}
+
+	res := []*LoginHook{}
+	for _, hook := range hooks {
+		if hook.Application == "" || hook.Application == application {
+			res = append(res, hook)
+		}
+	}
+	return res
+}
+
+func getLoginHookParameters(ctx *LoginHookContext) map[string]interface{} {
+	user := ctx.User
+	if user == nil {
+		user = &User{}
+	}
+
+	return map[string]interface{}{
+		"event":        ctx.Event,
+		"organization": ctx.Organization,
+		"application":  ctx.Application,
+		"user":         user,
+		"ip":           ctx.Ip,
+		"userAgent":    ctx.UserAgent,
+	}
+}
+
+func runExpressionLoginHook(hook *LoginHook, ctx *LoginHookContext) (*LoginHookResult, error) {
+	expression, err := govaluate.NewEvaluableExpression(hook.Expression)
+	if err != nil {
+		return nil, err
+	}
+
+	value, err := expression.Evaluate(getLoginHookParameters(ctx))
+	if err != nil {
+		return nil, err
+	}
+	matched, ok := value.(bool)
+	if !ok {
+		return nil, fmt.Errorf("the expression returned: %v instead of a boolean", value)
+	}
+	if !matched {
+		return &LoginHookResult{Action: LoginHookActionAllow}, nil
+	}
+
+	return &LoginHookResult{Action: hook.Action, Message: hook.Message, Steps: hook.Steps, Claims: hook.Claims}, nil
+}
+
+// getLoginHookPayload returns the context as JSON without the password of
+// the user, the user of the context stays as it is.
+func getLoginHookPayload(ctx *LoginHookContext) string {
+	payload := *ctx
+	if ctx.User != nil {
+		user := &User{}
+		err := json.Unmarshal([]byte(util.StructToJson(ctx.User)), user)
+		if err != nil {
+			panic(err)
+		}
+		user.PasswordSalt = ""
+		payload.User = GetMaskedUser(user)
+	}
+	return util.StructToJson(payload)
+}
+
+func runWebhookLoginHook(hook *LoginHook, ctx *LoginHookContext) (*LoginHookResult, error) {
+	timeout := hook.Timeout
+	if timeout <= 0 {
+		timeout = loginHookDefaultTimeout
+	}
+	client := &http.Client{Timeout: time.Duration(timeout) * time.Second}
+
+	req, err := http.NewRequest("POST", hook.Url, strings.NewReader(getLoginHookPayload(ctx)))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for _, header := range hook.Headers {
+		req.Header.Set(header.Name, header.Value)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return nil, fmt.Errorf("the login hook: %s returned the status: %s", hook.GetId(), resp.Status)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, 1024*1024))
+	if err != nil {
+		return nil, err
+	}
+
+	result := &LoginHookResult{}
+	err = json.Unmarshal(data, result)
+	if err != nil {
+		return nil, fmt.Errorf("the login hook: %s returned an invalid result: %s", hook.GetId(), err.Error())
+	}
+	if result.Action == "" {
+		result.Action = LoginHookActionAllow
+	}
+	return result, nil
+}
+
+func runLoginHook(hook *LoginHook, ctx *LoginHookContext) (*LoginHookResult, error) {
+	switch hook.Type {
+	case LoginHookTypeWebhook:
+		return runWebhookLoginHook(hook, ctx)
+	case LoginHookTypeExpression:
+		return runExpressionLoginHook(hook, ctx)
+	default:
+		return nil, fmt.Errorf("the type: %s isn't supported", hook.Type)
+	}
+}
+
+// mergeLoginHookResult folds the result of a hook into the result so far, a
+// denial or required steps stop the remaining hooks.
+func mergeLoginHookResult(res *LoginHookResult, result *LoginHookResult, event string) bool {
+	for key, value := range result.Claims {
+		res.Claims[key] = value
+	}
+
+	switch result.Action {
+	case LoginHookActionAllow:
+		return true
+	case LoginHookActionRequire:
+		if event == LoginHookEventPostAuthentication {
+			res.Action = LoginHookActionRequire
+			res.Message = result.Message
+			res.Steps = result.Steps
+			res.RedirectUrl = result.RedirectUrl
+			return false
+		}
+	}
+
+	res.Action = LoginHookActionDeny
+	res.Message = result.Message
+	if res.Message == "" {
+		res.Message = fmt.Sprintf("the action: %s is rejected by a login hook", event)
+	}
+	return false
+}
+
+// RunLoginHooks runs the hooks of the event in the order of their names. A
+// hook that fails denies the login unless it is marked as fail-open.
+func RunLoginHooks(ctx *LoginHookContext) *LoginHookResult {
+	res := &LoginHookResult{Action: LoginHookActionAllow, Claims: map[string]interface{}{}}
+	for _, hook := range getMatchedLoginHooks(ctx.Organization, ctx.Application, ctx.Event) {
+		result, err := runLoginHook(hook, ctx)
+		if err != nil {
+			logs.Warning(fmt.Sprintf("login hook: %s failed, %s", hook.GetId(), err.Error()))
+			if hook.FailOpen {
+				continue
+			}
+			result = &LoginHookResult{Action: LoginHookActionDeny, Message: fmt.Sprintf("the login hook: %s failed", hook.GetId())}
+		}
+
+		if !mergeLoginHookResult(res, result, ctx.Event) {
+			break
+		}
+	}
+	return res
+}
+
+// ApplyClaimsToUser stores the claims of a hook as properties of the user,
+// values that aren't strings are stored as JSON.
+func (result *LoginHookResult) ApplyClaimsToUser(user *User) {
+	if len(result.Claims) == 0 {
+		return
+	}
+	if user.Properties == nil {
+		user.Properties = map[string]string{}
+	}
+
+	for key, value := range result.Claims {
+		if s, ok := value.(string); ok {
+			user.Properties[key] = s
+		} else {
+			user.Properties[key] = util.StructToJson(value)
+		}
+	}
+}
@@ -0,0 +1,118 @@
+// Copyright 2023 The Casdoor Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package object
+
+import (
+	"testing"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRunExpressionLoginHook(t *testing.T) {
+	ctx := &LoginHookContext{
+		Event: LoginHookEventPostAuthentication,
+		User:  &User{Name: "alice", Email: "alice@example.com", IsAdmin: true},
+		Ip:    "10.0.0.1",
+	}
+
+	scenarios := []struct {
+		description string
+		expression  string
+		expected    string
+		isError     bool
+	}{
+		{"matched", `user.Email =~ "@example\\.com$"`, LoginHookActionDeny, false},
+		{"not matched", `user.Name == "bob"`, LoginHookActionAllow, false},
+		{"boolean field", `user.IsAdmin && ip == "10.0.0.1"`, LoginHookActionDeny, false},
+		{"not a boolean", `user.Name`, "", true},
+	}
+
+	for _, scenery := range scenarios {
+		t.Run(scenery.description, func(t *testing.T) {
+			hook := &LoginHook{Type: LoginHookTypeExpression, Expression: scenery.expression, Action: LoginHookActionDeny, Message: "denied"}
+			result, err := runExpressionLoginHook(hook, ctx)
+			if scenery.isError {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, scenery.expected, result.Action)
+		})
+	}
+}
+
+func TestMergeLoginHookResult(t *testing.T) {
+	scenarios := []struct {
+		description    string
+		event          string
+		result         *LoginHookResult
+		expectedAction string
+		expectedNext   bool
+	}{
+		{"allow", LoginHookEventPreSignup, &LoginHookResult{Action: LoginHookActionAllow}, LoginHookActionAllow, true},
+		{"deny", LoginHookEventPreSignup, &LoginHookResult{Action: LoginHookActionDeny}, LoginHookActionDeny, false},
+		{"require after authentication", LoginHookEventPostAuthentication, &LoginHookResult{Action: LoginHookActionRequire, Steps: []string{"mfa"}}, LoginHookActionRequire, false},
+		{"require before signup", LoginHookEventPreSignup, &LoginHookResult{Action: LoginHookActionRequire}, LoginHookActionDeny, false},
+		{"unknown action", LoginHookEventPreTokenIssuance, &LoginHookResult{Action: "skip"}, LoginHookActionDeny, false},
+	}
+
+	for _, scenery := range scenarios {
+		t.Run(scenery.description, func(t *testing.T) {
+			res := &LoginHookResult{Action: LoginHookActionAllow, Claims: map[string]interface{}{}}
+			assert.Equal(t, scenery.expectedNext, mergeLoginHookResult(res, scenery.result, scenery.event))
+			assert.Equal(t, scenery.expectedAction, res.Action)
+			if res.Action == LoginHookActionDeny {
+				assert.NotEmpty(t, res.Message)
+			}
+		})
+	}
+}
+
+func TestGetClaimsWithHookClaims(t *testing.T) {
+	claims := ClaimsShort{
+		UserShort:        &UserShort{Owner: "org", Name: "alice"},
+		TokenType:        "access-token",
+		RegisteredClaims: jwt.RegisteredClaims{Subject: "id"},
+	}
+
+	assert.Equal(t, claims, getClaimsWithHookClaims(claims, nil))
+
+	res := getClaimsWithHookClaims(claims, map[string]interface{}{"department": "sales", "sub": "other", "tokenType": "id-token", "owner": "built-in", "name": "admin", "isAdmin": true, "isGlobalAdmin": true}).(jwt.MapClaims)
+	assert.Equal(t, "sales", res["department"])
+	assert.Equal(t, "id", res["sub"])
+	assert.Equal(t, "access-token", res["tokenType"])
+	assert.Equal(t, "org", res["owner"])
+	assert.Equal(t, "alice", res["name"])
+	assert.NotContains(t, res, "isAdmin")
+	assert.NotContains(t, res, "isGlobalAdmin")
+}
+
+func TestGetLoginHookPayload(t *testing.T) {
+	user := &User{Name: "alice", Password: "secret", PasswordSalt: "salt"}
+	payload := getLoginHookPayload(&LoginHookContext{User: user})
+
+	assert.NotContains(t, payload, "secret")
+	assert.NotContains(t, payload, "salt\"")
+	assert.Equal(t, "secret", user.Password)
+}
+
+func TestCheckLoginHook(t *testing.T) {
+	assert.NoError(t, checkLoginHook(&LoginHook{Event: LoginHookEventPreSignup, Type: LoginHookTypeWebhook, Url: "https://hooks.example.com"}))
+	assert.NoError(t, checkLoginHook(&LoginHook{Event: LoginHookEventPreSignup, Type: LoginHookTypeExpression, Expression: `ip == "1.2.3.4"`, Action: LoginHookActionDeny}))
+	assert.Error(t, checkLoginHook(&LoginHook{Event: "post-signup", Type: LoginHookTypeWebhook, Url: "https://hooks.example.com"}))
+	assert.Error(t, checkLoginHook(&LoginHook{Event: LoginHookEventPreSignup, Type: LoginHookTypeWebhook, Url: "ftp://hooks.example.com"}))
+	assert.Error(t, checkLoginHook(&LoginHook{Event: LoginHookEventPreSignup, Type: LoginHookTypeExpression, Expression: `ip ==`, Action: LoginHookActionDeny}))
+}
@@ -0,0 +1,266 @@
+// Copyright 2023 The Casdoor Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package object
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/casdoor/casdoor/util"
+	"github.com/xorm-io/core"
+)
+
+const (
+	LoginStatEventLogin   = "login"
+	LoginStatEventSignup  = "signup"
+	LoginStatEventFailure = "failure"
+)
+
+// loginStatDimensions are what the login stats can be grouped by.
+var loginStatDimensions = []string{"date", "application", "provider", "country"}
+
+// LoginStat counts the logins, signups and failed attempts of an
+// organization for one day, application, provider and country. Name is
+// "<date>/<application>/<provider>/<country>".
+type LoginStat struct {
+	Owner       string `xorm:"varchar(100) notnull pk" json:"owner"`
+	Name        string `xorm:"varchar(255) notnull pk" json:"name"`
+	UpdatedTime string `xorm:"varchar(100)" json:"updatedTime"`
+
+	Date        string `xorm:"varchar(100) index" json:"date"`
+	Application string `xorm:"varchar(100)" json:"application"`
+	Provider    string `xorm:"varchar(100)" json:"provider"`
+	Country     string `xorm:"varchar(100)" json:"country"`
+	Logins      int    `json:"logins"`
+	Signups     int    `json:"signups"`
+	Failures    int    `json:"failures"`
+}
+
+func getLoginStatColumn(event string) string {
+	switch event {
+	case LoginStatEventSignup:
+		return "signups"
+	case LoginStatEventFailure:
+		return "failures"
+	default:
+		return "logins"
+	}
+}
+
+func incrLoginStat(loginStat *LoginStat, column string) int64 {
+	affected, err := adapter.Engine.ID(core.PK{loginStat.Owner, loginStat.Name}).Incr(column).Cols("updated_time").Update(&LoginStat{UpdatedTime: loginStat.UpdatedTime})
+	if err != nil {
+		panic(err)
+	}
+	return affected
+}
+
+// AddLoginStat counts an event of a login to the application, identified by
+// its name or client ID, in the stats of its organization. Without an
+// application the event counts for the organization.
+func AddLoginStat(organization string, applicationName string, clientId string, provider string, country string, event string) {
+	var application *Application
+	if applicationName != "" {
+		application = getApplication("admin", applicationName)
+	} else if clientId != "" {
+		application = GetApplicationByClientId(clientId)
+	}
+	if application != nil {
+		organization = application.Organization
+		applicationName = application.Name
+	}
+	if organization == "" {
+		return
+	}
+
+	now := time.Now()
+	loginStat := &LoginStat{
+		Owner:       organization,
+		UpdatedTime: now.Format(time.RFC3339),
+		Date:        now.Format("2006-01-02"),
+		Application: applicationName,
+		Provider:    provider,
+		Country:     country,
+	}
+	loginStat.Name = strings.Join([]string{loginStat.Date, loginStat.Application, loginStat.Provider, loginStat.Country}, "/")
+
+	column := getLoginStatColumn(event)
+	if incrLoginStat(loginStat, column) != 0 {
+		return
+	}
+
+	switch column {
+	case "signups":
+		loginStat.Signups = 1
+	case "failures":
+		loginStat.Failures = 1
+	default:
+		loginStat.Logins = 1
+	}
+	_, err := adapter.Engine.Insert(loginStat)
+	if err != nil {
+		// inserted by a concurrent login in the meantime
+		incrLoginStat(loginStat, column)
+	}
+}
+
+// LoginStatReport is a row of the login stats grouped by some of the
+// dimensions, the other dimensions are empty.
+type LoginStatReport struct {
+	Date        string  `json:"date"`
+	Application string  `json:"application"`
+	Provider    string  `json:"provider"`
+	Country     string  `json:"country"`
+	Logins      int     `json:"logins"`
+	Signups     int     `json:"signups"`
+	Failures    int     `json:"failures"`
+	FailureRate float64 `json:"failureRate"`
+}
+
+func (report *LoginStatReport) getDimension(dimension string) string {
+	switch dimension {
+	case "date":
+		return report.Date
+	case "application":
+		return report.Application
+	case "provider":
+		return report.Provider
+	default:
+		return report.Country
+	}
+}
+
+// ParseLoginStatGroupBy parses the comma-separated dimensions to group by,
+// the default is by date.
+func ParseLoginStatGroupBy(groupBy string) ([]string, error) {
+	if groupBy == "" {
+		return []string{"date"}, nil
+	}
+
+	dimensions := []string{}
+	for _, dimension := range strings.Split(groupBy, ",") {
+		dimension = strings.TrimSpace(dimension)
+		if !util.ContainsString(loginStatDimensions, dimension) {
+			return nil, fmt.Errorf("the login stats can't be grouped by: %s, the dimensions are: %s", dimension, strings.Join(loginStatDimensions, ", "))
+		}
+		dimensions = append(dimensions, dimension)
+	}
+	return dimensions, nil
+}
+
+// aggregateLoginStats sums the stats by the dimensions, the rows are sorted
+// by the dimensions in their order.
+func aggregateLoginStats(loginStats []*LoginStat, groupBy []string) []*LoginStatReport {
+	has := map[string]bool{}
+	for _, dimension := range groupBy {
+		has[dimension] = true
+	}
+
+	reportMap := map[string]*LoginStatReport{}
+	for _, loginStat := range loginStats {
+		report := &LoginStatReport{}
+		if has["date"] {
+			report.Date = loginStat.Date
+		}
+		if has["application"] {
+			report.Application = loginStat.Application
+		}
+		if has["provider"] {
+			report.Provider = loginStat.Provider
+		}
+		if has["country"] {
+			report.Country = loginStat.Country
+		}
+
+		key := strings.Join([]string{report.Date, report.Application, report.Provider, report.Country}, "\x00")
+		if existing, ok := reportMap[key]; ok {
+			report = existing
+		} else {
+			reportMap[key] = report
+		}
+		report.Logins += loginStat.Logins
+		report.Signups += loginStat.Signups
+		report.Failures += loginStat.Failures
+	}
+
+	reports := []*LoginStatReport{}
+	for _, report := range reportMap {
+		if total := report.Logins + report.Signups + report.Failures; total > 0 {
+			report.FailureRate = float64(report.Failures) / float64(total)
+		}
+		reports = append(reports, report)
+	}
+
+	sort.Slice(reports, func(i, j int) bool {
+		for _, dimension := range groupBy {
+			a, b := reports[i].getDimension(dimension), reports[j].getDimension(dimension)
+			if a != b {
+				return a < b
+			}
+		}
+		return false
+	})
+	return reports
+}
+
+// GetLoginStats returns the login stats of the organization between the
+// dates (inclusive, "2006-01-02") grouped by the dimensions, empty dates
+// leave the range open.
+func GetLoginStats(organization string, startDate string, endDate string, groupBy []string) []*LoginStatReport {
+	loginStats := []*LoginStat{}
+	session := adapter.Engine.Where("owner = ?", organization)
+	if startDate != "" {
+		session = session.And("date >= ?", startDate)
+	}
+	if endDate != "" {
+		session = session.And("date <= ?", endDate)
+	}
+	err := session.Find(&loginStats)
+	if err != nil {
+		panic(err)
+	}
+
+	return aggregateLoginStats(loginStats, groupBy)
+}
+
+// ExportLoginStats writes the report as CSV with a column per dimension.
+func ExportLoginStats(reports []*LoginStatReport, groupBy []string) ([]byte, error) {
+	buf := &bytes.Buffer{}
+	writer := csv.NewWriter(buf)
+	err := writer.Write(append(append([]string{}, groupBy...), "logins", "signups", "failures", "failureRate"))
+	if err != nil {
+		return nil, err
+	}
+
+	for _, report := range reports {
+		row := []string{}
+		for _, dimension := range groupBy {
+			row = append(row, report.getDimension(dimension))
+		}
+		row = append(row, fmt.Sprintf("%d", report.Logins), fmt.Sprintf("%d", report.Signups), fmt.Sprintf("%d", report.Failures), fmt.Sprintf("%.4f", report.FailureRate))
+
+		err = writer.Write(row)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	writer.Flush()
+	return buf.Bytes(), writer.Error()
+}
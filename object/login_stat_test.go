@@ -0,0 +1,77 @@
+// Copyright 2023 The Casdoor Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package object
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAggregateLoginStats(t *testing.T) {
+	loginStats := []*LoginStat{
+		{Date: "2023-06-02", Application: "app", Provider: "GitHub", Country: "US", Logins: 3, Failures: 1},
+		{Date: "2023-06-01", Application: "app", Provider: "Password", Country: "US", Logins: 5, Signups: 2, Failures: 3},
+		{Date: "2023-06-01", Application: "app", Provider: "GitHub", Country: "DE", Logins: 1, Signups: 1},
+		{Date: "2023-06-02", Application: "app-2", Provider: "GitHub", Country: "US", Failures: 4},
+	}
+
+	scenarios := []struct {
+		description string
+		groupBy     []string
+		expected    []*LoginStatReport
+	}{
+		{"By date", []string{"date"}, []*LoginStatReport{
+			{Date: "2023-06-01", Logins: 6, Signups: 3, Failures: 3, FailureRate: 0.25},
+			{Date: "2023-06-02", Logins: 3, Failures: 5, FailureRate: 0.625},
+		}},
+		{"By provider and country", []string{"provider", "country"}, []*LoginStatReport{
+			{Provider: "GitHub", Country: "DE", Logins: 1, Signups: 1},
+			{Provider: "GitHub", Country: "US", Logins: 3, Failures: 5, FailureRate: 0.625},
+			{Provider: "Password", Country: "US", Logins: 5, Signups: 2, Failures: 3, FailureRate: 0.3},
+		}},
+		{"By application", []string{"application"}, []*LoginStatReport{
+			{Application: "app", Logins: 9, Signups: 3, Failures: 4, FailureRate: 0.25},
+			{Application: "app-2", Failures: 4, FailureRate: 1},
+		}},
+	}
+
+	for _, scenery := range scenarios {
+		t.Run(scenery.description, func(t *testing.T) {
+			assert.Equal(t, scenery.expected, aggregateLoginStats(loginStats, scenery.groupBy))
+		})
+	}
+}
+
+func TestParseLoginStatGroupBy(t *testing.T) {
+	groupBy, err := ParseLoginStatGroupBy("")
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"date"}, groupBy)
+
+	groupBy, err = ParseLoginStatGroupBy("provider, date")
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"provider", "date"}, groupBy)
+
+	_, err = ParseLoginStatGroupBy("date,user")
+	assert.NotNil(t, err)
+}
+
+func TestExportLoginStats(t *testing.T) {
+	reports := []*LoginStatReport{{Date: "2023-06-01", Provider: "GitHub", Logins: 3, Failures: 1, FailureRate: 0.25}}
+
+	data, err := ExportLoginStats(reports, []string{"date", "provider"})
+	assert.Nil(t, err)
+	assert.Equal(t, "date,provider,logins,signups,failures,failureRate\n2023-06-01,GitHub,3,0,1,0.2500\n", string(data))
+}
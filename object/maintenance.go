@@ -0,0 +1,150 @@
+// Copyright 2023 The Casdoor Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package object
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/casdoor/casdoor/conf"
+	"github.com/casdoor/casdoor/util"
+)
+
+const defaultDrainTimeout = 30 * time.Second
+
+// MaintenanceStatus is the maintenance mode of the cluster, which stops new
+// logins and the background jobs, and the drain state of this node.
+type MaintenanceStatus struct {
+	Enabled          bool   `json:"enabled"`
+	Notice           string `json:"notice"`
+	Node             string `json:"node"`
+	IsDraining       bool   `json:"isDraining"`
+	DrainStartedTime string `json:"drainStartedTime"`
+	InFlightRequests int64  `json:"inFlightRequests"`
+	IsDrained        bool   `json:"isDrained"`
+}
+
+var (
+	inFlightRequests int64
+	drainState       = struct {
+		sync.RWMutex
+		startedTime string
+	}{}
+)
+
+// SetMaintenanceMode turns the maintenance mode of all nodes on or off, it
+// is a runtime setting so the other nodes pick it up within a few seconds.
+// An empty notice falls back to app.conf.
+func SetMaintenanceMode(enabled bool, notice string, user string) error {
+	enabledValue := "false"
+	if enabled {
+		enabledValue = "true"
+	}
+
+	values := map[string]*string{"maintenanceMode": &enabledValue, "maintenanceNotice": nil}
+	if notice != "" {
+		values["maintenanceNotice"] = &notice
+	}
+	return UpdateSettings(values, user)
+}
+
+// IsMaintenanceMode returns whether new logins and background jobs are
+// stopped on this node, which also holds while it drains.
+func IsMaintenanceMode() bool {
+	return conf.IsMaintenanceMode() || IsDraining()
+}
+
+func GetMaintenanceNotice() string {
+	return conf.GetConfigString("maintenanceNotice")
+}
+
+func IsDraining() bool {
+	drainState.RLock()
+	defer drainState.RUnlock()
+
+	return drainState.startedTime != ""
+}
+
+// StartRequest and FinishRequest count the requests this node is serving.
+func StartRequest() {
+	atomic.AddInt64(&inFlightRequests, 1)
+}
+
+func FinishRequest() {
+	atomic.AddInt64(&inFlightRequests, -1)
+}
+
+func getInFlightRequests() int64 {
+	return atomic.LoadInt64(&inFlightRequests)
+}
+
+// StartDrain puts this node into maintenance mode and lets the readiness
+// probe fail, so that the load balancer stops sending requests to it.
+func StartDrain() {
+	drainState.Lock()
+	defer drainState.Unlock()
+
+	if drainState.startedTime == "" {
+		drainState.startedTime = util.GetCurrentTime()
+	}
+}
+
+func GetDrainTimeout() time.Duration {
+	timeout, err := conf.GetConfigInt64("drainTimeout")
+	if err != nil || timeout <= 0 {
+		return defaultDrainTimeout
+	}
+	return time.Duration(timeout) * time.Second
+}
+
+// WaitForDrain waits until no more than ignored requests are in flight, the
+// callers that are requests themselves ignore one. It returns false when
+// the timeout is reached first.
+func WaitForDrain(timeout time.Duration, ignored int64) bool {
+	deadline := time.Now().Add(timeout)
+	for getInFlightRequests() > ignored {
+		if time.Now().After(deadline) {
+			return false
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+	return true
+}
+
+func GetMaintenanceStatus() *MaintenanceStatus {
+	drainState.RLock()
+	drainStartedTime := drainState.startedTime
+	drainState.RUnlock()
+
+	return &MaintenanceStatus{
+		Enabled:          IsMaintenanceMode(),
+		Notice:           GetMaintenanceNotice(),
+		Node:             GetNodeName(),
+		IsDraining:       drainStartedTime != "",
+		DrainStartedTime: drainStartedTime,
+		InFlightRequests: getInFlightRequests(),
+	}
+}
+
+func checkMaintenanceHealth() (string, string) {
+	if IsDraining() {
+		return HealthStatusDown, "draining"
+	}
+	if IsMaintenanceMode() {
+		return HealthStatusWarning, "maintenance mode"
+	}
+	return HealthStatusUp, ""
+}
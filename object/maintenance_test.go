@@ -0,0 +1,48 @@
+// Copyright 2023 The Casdoor Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package object
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWaitForDrain(t *testing.T) {
+	defer func() { drainState.startedTime = "" }()
+
+	status, _ := checkMaintenanceHealth()
+	assert.Equal(t, HealthStatusUp, status)
+
+	StartRequest()
+	StartRequest()
+	StartDrain()
+	assert.True(t, IsMaintenanceMode())
+	status, _ = checkMaintenanceHealth()
+	assert.Equal(t, HealthStatusDown, status)
+
+	assert.False(t, WaitForDrain(200*time.Millisecond, 1), "two requests are in flight")
+
+	go func() {
+		time.Sleep(100 * time.Millisecond)
+		FinishRequest()
+	}()
+	assert.True(t, WaitForDrain(time.Second, 1), "the request of the caller is ignored")
+
+	FinishRequest()
+	assert.True(t, WaitForDrain(0, 0))
+	assert.Equal(t, int64(0), GetMaintenanceStatus().InFlightRequests)
+}
@@ -0,0 +1,261 @@
+// Copyright 2023 The Casdoor Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package object
+
+import (
+	"fmt"
+
+	"github.com/casdoor/casdoor/i18n"
+	"github.com/casdoor/casdoor/util"
+	"github.com/xorm-io/core"
+)
+
+// Membership lets a user of one organization (the home organization, the
+// owner of the user) also belong to the organization that owns the
+// membership. The profile fields overlay the ones of the user while the
+// membership's organization is the active one.
+type Membership struct {
+	Owner       string `xorm:"varchar(100) notnull pk" json:"owner"`
+	Name        string `xorm:"varchar(100) notnull pk" json:"name"`
+	CreatedTime string `xorm:"varchar(100)" json:"createdTime"`
+
+	User        string            `xorm:"varchar(100) index" json:"user"`
+	DisplayName string            `xorm:"varchar(100)" json:"displayName"`
+	Avatar      string            `xorm:"varchar(500)" json:"avatar"`
+	Title       string            `xorm:"varchar(100)" json:"title"`
+	Tag         string            `xorm:"varchar(100)" json:"tag"`
+	Properties  map[string]string `json:"properties"`
+	IsEnabled   bool              `json:"isEnabled"`
+}
+
+func GetMembershipCount(owner, field, value string) int {
+	session := GetSession(owner, -1, -1, field, value, "", "")
+	count, err := session.Count(&Membership{})
+	if err != nil {
+		panic(err)
+	}
+
+	return int(count)
+}
+
+func GetMemberships(owner string) []*Membership {
+	memberships := []*Membership{}
+	err := adapter.Engine.Desc("created_time").Find(&memberships, &Membership{Owner: owner})
+	if err != nil {
+		panic(err)
+	}
+
+	return memberships
+}
+
+func GetPaginationMemberships(owner string, offset, limit int, field, value, sortField, sortOrder string) []*Membership {
+	memberships := []*Membership{}
+	session := GetSession(owner, offset, limit, field, value, sortField, sortOrder)
+	err := session.Find(&memberships)
+	if err != nil {
+		panic(err)
+	}
+
+	return memberships
+}
+
+// GetUserMemberships returns the memberships of the user in other
+// organizations than its home organization.
+func GetUserMemberships(userId string) []*Membership {
+	memberships := []*Membership{}
+	err := adapter.Engine.Asc("owner").Find(&memberships, &Membership{User: userId})
+	if err != nil {
+		panic(err)
+	}
+
+	return memberships
+}
+
+func getMembership(owner string, name string) *Membership {
+	if owner == "" || name == "" {
+		return nil
+	}
+
+	membership := Membership{Owner: owner, Name: name}
+	existed, err := adapter.Engine.Get(&membership)
+	if err != nil {
+		panic(err)
+	}
+
+	if existed {
+		return &membership
+	} else {
+		return nil
+	}
+}
+
+func GetMembership(id string) *Membership {
+	owner, name := util.GetOwnerAndNameFromId(id)
+	return getMembership(owner, name)
+}
+
+// getUserMembership returns the enabled membership of the user in the
+// organization.
+func getUserMembership(userId string, organization string) *Membership {
+	membership := Membership{Owner: organization, User: userId}
+	existed, err := adapter.Engine.Get(&membership)
+	if err != nil {
+		panic(err)
+	}
+
+	if existed && membership.IsEnabled {
+		return &membership
+	} else {
+		return nil
+	}
+}
+
+func CheckMembership(membership *Membership, lang string) string {
+	if getOrganization("admin", membership.Owner) == nil {
+		return i18n.Translate(lang, "check:Organization does not exist")
+	}
+
+	userOwner, _ := util.GetOwnerAndNameFromId(membership.User)
+	if GetUser(membership.User) == nil {
+		return fmt.Sprintf(i18n.Translate(lang, "general:The user: %s doesn't exist"), membership.User)
+	}
+	if userOwner == membership.Owner {
+		return i18n.Translate(lang, "membership:The user already belongs to this organization")
+	}
+
+	existing := Membership{Owner: membership.Owner, User: membership.User}
+	existed, err := adapter.Engine.Get(&existing)
+	if err != nil {
+		panic(err)
+	}
+	if existed && existing.Name != membership.Name {
+		return i18n.Translate(lang, "membership:The user already belongs to this organization")
+	}
+
+	return ""
+}
+
+func UpdateMembership(id string, membership *Membership) bool {
+	owner, name := util.GetOwnerAndNameFromId(id)
+	if getMembership(owner, name) == nil {
+		return false
+	}
+
+	affected, err := adapter.Engine.ID(core.PK{owner, name}).AllCols().Update(membership)
+	if err != nil {
+		panic(err)
+	}
+
+	return affected != 0
+}
+
+func AddMembership(membership *Membership) bool {
+	affected, err := adapter.Engine.Insert(membership)
+	if err != nil {
+		panic(err)
+	}
+
+	return affected != 0
+}
+
+func DeleteMembership(membership *Membership) bool {
+	affected, err := adapter.Engine.ID(core.PK{membership.Owner, membership.Name}).Delete(&Membership{})
+	if err != nil {
+		panic(err)
+	}
+
+	return affected != 0
+}
+
+func (membership *Membership) GetId() string {
+	return fmt.Sprintf("%s/%s", membership.Owner, membership.Name)
+}
+
+// GetUserOrganizations returns the organizations the user can sign in to:
+// the home organization first, then the ones of the enabled memberships.
+func GetUserOrganizations(user *User) []*Organization {
+	organizations := []*Organization{}
+	if organization := getOrganization("admin", user.Owner); organization != nil {
+		organizations = append(organizations, organization)
+	}
+
+	for _, membership := range GetUserMemberships(user.GetId()) {
+		if !membership.IsEnabled {
+			continue
+		}
+		if organization := getOrganization("admin", membership.Owner); organization != nil {
+			organizations = append(organizations, organization)
+		}
+	}
+
+	return organizations
+}
+
+// IsUserInOrganization returns whether the user belongs to the organization,
+// either as its home organization or by an enabled membership.
+func IsUserInOrganization(user *User, organization string) bool {
+	return user.Owner == organization || getUserMembership(user.GetId(), organization) != nil
+}
+
+// ApplyMembership returns a copy of the user with the organization as the
+// active one, the profile of the membership overlays the user's profile. The
+// user is returned as it is without a membership in the organization.
+func ApplyMembership(user *User, organization string) *User {
+	if user == nil || organization == "" {
+		return user
+	}
+
+	if organization == user.Owner {
+		res := *user
+		res.ActiveOrganization = organization
+		return &res
+	}
+
+	membership := getUserMembership(user.GetId(), organization)
+	if membership == nil {
+		return user
+	}
+
+	return applyMembership(user, membership)
+}
+
+func applyMembership(user *User, membership *Membership) *User {
+	res := *user
+	res.ActiveOrganization = membership.Owner
+	if membership.DisplayName != "" {
+		res.DisplayName = membership.DisplayName
+	}
+	if membership.Avatar != "" {
+		res.Avatar = membership.Avatar
+	}
+	if membership.Title != "" {
+		res.Title = membership.Title
+	}
+	if membership.Tag != "" {
+		res.Tag = membership.Tag
+	}
+
+	if len(membership.Properties) != 0 {
+		res.Properties = map[string]string{}
+		for key, value := range user.Properties {
+			res.Properties[key] = value
+		}
+		for key, value := range membership.Properties {
+			res.Properties[key] = value
+		}
+	}
+
+	return &res
+}
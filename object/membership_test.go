@@ -0,0 +1,63 @@
+// Copyright 2023 The Casdoor Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package object
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestApplyMembership(t *testing.T) {
+	user := &User{
+		Owner:       "home",
+		Name:        "alice",
+		DisplayName: "Alice",
+		Avatar:      "https://example.com/alice.png",
+		Title:       "Engineer",
+		Properties:  map[string]string{"team": "core", "site": "berlin"},
+	}
+
+	scenarios := []struct {
+		description string
+		membership  *Membership
+		expected    *User
+	}{
+		{
+			"empty overlay keeps the profile",
+			&Membership{Owner: "partner"},
+			&User{Owner: "home", Name: "alice", DisplayName: "Alice", Avatar: "https://example.com/alice.png", Title: "Engineer", Properties: map[string]string{"team": "core", "site": "berlin"}, ActiveOrganization: "partner"},
+		},
+		{
+			"overlay replaces set fields",
+			&Membership{Owner: "partner", DisplayName: "Alice (Partner)", Title: "Consultant"},
+			&User{Owner: "home", Name: "alice", DisplayName: "Alice (Partner)", Avatar: "https://example.com/alice.png", Title: "Consultant", Properties: map[string]string{"team": "core", "site": "berlin"}, ActiveOrganization: "partner"},
+		},
+		{
+			"properties are merged",
+			&Membership{Owner: "partner", Properties: map[string]string{"team": "sales"}},
+			&User{Owner: "home", Name: "alice", DisplayName: "Alice", Avatar: "https://example.com/alice.png", Title: "Engineer", Properties: map[string]string{"team": "sales", "site": "berlin"}, ActiveOrganization: "partner"},
+		},
+	}
+
+	for _, scenery := range scenarios {
+		t.Run(scenery.description, func(t *testing.T) {
+			assert.Equal(t, scenery.expected, applyMembership(user, scenery.membership))
+		})
+	}
+
+	assert.Equal(t, "core", user.Properties["team"], "the user must not be changed")
+	assert.Equal(t, "", user.ActiveOrganization, "the user must not be changed")
+}
@@ -115,6 +115,10 @@ func UpdateModel(id string, modelObj *Model) bool {
 		panic(err)
 	}
 
+	if affected != 0 {
+		invalidateEnforcers()
+	}
+
 	return affected != 0
 }
 
@@ -0,0 +1,153 @@
+// Copyright 2023 The Casdoor Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package object
+
+// ModelTemplate is an example model with policies and requests that can be
+// loaded into the model validation.
+type ModelTemplate struct {
+	Name        string           `json:"name"`
+	Description string           `json:"description"`
+	Validation  *ModelValidation `json:"validation"`
+}
+
+func newBool(b bool) *bool {
+	return &b
+}
+
+var modelTemplates = []*ModelTemplate{
+	{
+		Name:        "rbac",
+		Description: "Roles of users, the default model of Casdoor",
+		Validation: &ModelValidation{
+			ModelText: `[request_definition]
+r = sub, obj, act
+
+[policy_definition]
+p = sub, obj, act
+
+[role_definition]
+g = _, _
+
+[policy_effect]
+e = some(where (p.eft == allow))
+
+[matchers]
+m = g(r.sub, p.sub) && r.obj == p.obj && r.act == p.act`,
+			Policies:         [][]string{{"built-in/role-editor", "data1", "write"}},
+			GroupingPolicies: [][]string{{"built-in/alice", "built-in/role-editor"}},
+			Requests: []*ModelValidationRequest{
+				{Request: []string{"built-in/alice", "data1", "write"}, Expected: newBool(true)},
+				{Request: []string{"built-in/bob", "data1", "write"}, Expected: newBool(false)},
+			},
+		},
+	},
+	{
+		Name:        "rbac-with-domains",
+		Description: "Roles of users that only apply within a domain (tenant)",
+		Validation: &ModelValidation{
+			ModelText: `[request_definition]
+r = sub, dom, obj, act
+
+[policy_definition]
+p = sub, dom, obj, act
+
+[role_definition]
+g = _, _, _
+
+[policy_effect]
+e = some(where (p.eft == allow))
+
+[matchers]
+m = g(r.sub, p.sub, r.dom) && r.dom == p.dom && r.obj == p.obj && r.act == p.act`,
+			Policies:         [][]string{{"built-in/role-admin", "tenant1", "data1", "read"}},
+			GroupingPolicies: [][]string{{"built-in/alice", "built-in/role-admin", "tenant1"}},
+			Requests: []*ModelValidationRequest{
+				{Request: []string{"built-in/alice", "tenant1", "data1", "read"}, Expected: newBool(true)},
+				{Request: []string{"built-in/alice", "tenant2", "data1", "read"}, Expected: newBool(false)},
+			},
+		},
+	},
+	{
+		Name:        "abac-resource-owner",
+		Description: "Users can act on the resources they own, the resource is a JSON object in the request",
+		Validation: &ModelValidation{
+			ModelText: `[request_definition]
+r = sub, obj, act
+
+[policy_definition]
+p = act
+
+[policy_effect]
+e = some(where (p.eft == allow))
+
+[matchers]
+m = attr(r.obj, "owner") == r.sub && r.act == p.act`,
+			Policies: [][]string{{"read"}, {"write"}},
+			Requests: []*ModelValidationRequest{
+				{Request: []string{"built-in/alice", `{"name": "doc1", "owner": "built-in/alice"}`, "write"}, Expected: newBool(true)},
+				{Request: []string{"built-in/bob", `{"name": "doc1", "owner": "built-in/alice"}`, "write"}, Expected: newBool(false)},
+			},
+		},
+	},
+	{
+		Name:        "abac-user-attributes",
+		Description: "Access by a field or a property of the user, like the tag or the department",
+		Validation: &ModelValidation{
+			ModelText: `[request_definition]
+r = sub, obj, act
+
+[policy_definition]
+p = tag, obj, act
+
+[policy_effect]
+e = some(where (p.eft == allow))
+
+[matchers]
+m = userAttr(r.sub, "tag") == p.tag && r.obj == p.obj && r.act == p.act`,
+			Policies: [][]string{{"staff", "data1", "read"}},
+		},
+	},
+	{
+		Name:        "abac-request-context",
+		Description: "Access only from the office network during office hours, the context is a JSON object with the IP and the time",
+		Validation: &ModelValidation{
+			ModelText: `[request_definition]
+r = sub, obj, act, ctx
+
+[policy_definition]
+p = sub, obj, act, network
+
+[role_definition]
+g = _, _
+
+[policy_effect]
+e = some(where (p.eft == allow))
+
+[matchers]
+m = g(r.sub, p.sub) && r.obj == p.obj && r.act == p.act && ipMatch(attr(r.ctx, "ip"), p.network) && timeInRange(r.ctx, "09:00", "18:00")`,
+			Policies:         [][]string{{"built-in/role-staff", "data1", "read", "10.0.0.0/8"}},
+			GroupingPolicies: [][]string{{"built-in/alice", "built-in/role-staff"}},
+			Requests: []*ModelValidationRequest{
+				{Request: []string{"built-in/alice", "data1", "read", `{"ip": "10.1.2.3", "time": "2023-03-01T10:00:00Z"}`}, Expected: newBool(true)},
+				{Request: []string{"built-in/alice", "data1", "read", `{"ip": "192.168.1.1", "time": "2023-03-01T10:00:00Z"}`}, Expected: newBool(false)},
+				{Request: []string{"built-in/alice", "data1", "read", `{"ip": "10.1.2.3", "time": "2023-03-01T20:00:00Z"}`}, Expected: newBool(false)},
+			},
+		},
+	},
+}
+
+func GetModelTemplates() []*ModelTemplate {
+	return modelTemplates
+}
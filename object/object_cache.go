@@ -0,0 +1,316 @@
+// Copyright 2023 The Casdoor Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package object
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/beego/beego/logs"
+	"github.com/casdoor/casdoor/conf"
+	"github.com/casdoor/casdoor/util"
+	"github.com/gomodule/redigo/redis"
+)
+
+const (
+	objectCacheWatcherChannel = "casdoor:object-cache"
+	objectCacheMaxItems       = 10000
+)
+
+type ObjectCacheMetrics struct {
+	Items          int     `json:"items"`
+	Hits           int64   `json:"hits"`
+	SharedHits     int64   `json:"sharedHits"`
+	Misses         int64   `json:"misses"`
+	HitRate        float64 `json:"hitRate"`
+	Invalidations  int64   `json:"invalidations"`
+	Flushes        int64   `json:"flushes"`
+	Ttl            int64   `json:"ttl"`
+	SharedEnabled  bool    `json:"sharedEnabled"`
+	WatcherEnabled bool    `json:"watcherEnabled"`
+}
+
+type cachedObject struct {
+	data       []byte
+	expireTime time.Time
+}
+
+// objectCache keeps the objects as JSON, every read gets a copy of its own
+// as the callers change the objects they get (masking, extending).
+var objectCache = struct {
+	sync.Mutex
+	objects        map[string]*cachedObject
+	metrics        ObjectCacheMetrics
+	watcherEnabled bool
+}{objects: map[string]*cachedObject{}}
+
+type objectCacheInvalidation struct {
+	Node string `json:"node"`
+	// no keys is a flush, a key ending with "*" is a prefix
+	Keys []string `json:"keys"`
+}
+
+// getObjectCacheTtl returns how long an object is kept in this node, 0
+// disables the in-process cache while the shared cache stays as it is.
+func getObjectCacheTtl() time.Duration {
+	ttl, err := conf.GetConfigInt64("objectCacheTtl")
+	if err != nil {
+		ttl = 60
+	}
+	return time.Duration(ttl) * time.Second
+}
+
+func getLocalCache(key string, obj interface{}) bool {
+	objectCache.Lock()
+	cached, ok := objectCache.objects[key]
+	if ok && time.Now().After(cached.expireTime) {
+		delete(objectCache.objects, key)
+		ok = false
+	}
+	objectCache.Unlock()
+
+	return ok && json.Unmarshal(cached.data, obj) == nil
+}
+
+func setLocalCache(key string, obj interface{}) {
+	ttl := getObjectCacheTtl()
+	if ttl <= 0 {
+		return
+	}
+
+	data, err := json.Marshal(obj)
+	if err != nil {
+		return
+	}
+
+	objectCache.Lock()
+	defer objectCache.Unlock()
+
+	if len(objectCache.objects) >= objectCacheMaxItems {
+		now := time.Now()
+		for k, cached := range objectCache.objects {
+			if now.After(cached.expireTime) {
+				delete(objectCache.objects, k)
+			}
+		}
+		if len(objectCache.objects) >= objectCacheMaxItems {
+			return
+		}
+	}
+	objectCache.objects[key] = &cachedObject{data: data, expireTime: time.Now().Add(ttl)}
+}
+
+// deleteLocalCache deletes the keys, a key ending with "*" deletes all the
+// keys with the prefix and no keys deletes everything.
+func deleteLocalCache(keys []string) {
+	objectCache.Lock()
+	defer objectCache.Unlock()
+
+	if len(keys) == 0 {
+		objectCache.objects = map[string]*cachedObject{}
+		objectCache.metrics.Flushes++
+		return
+	}
+
+	for _, key := range keys {
+		if strings.HasSuffix(key, "*") {
+			prefix := strings.TrimSuffix(key, "*")
+			for k := range objectCache.objects {
+				if strings.HasPrefix(k, prefix) {
+					delete(objectCache.objects, k)
+				}
+			}
+		} else {
+			delete(objectCache.objects, key)
+		}
+	}
+	objectCache.metrics.Invalidations++
+}
+
+// getCachedObject reads the object from this node, then from the shared
+// cache of Redis, false means it has to be read from the database.
+func getCachedObject(key string, obj interface{}) bool {
+	if getLocalCache(key, obj) {
+		objectCache.Lock()
+		objectCache.metrics.Hits++
+		objectCache.Unlock()
+		return true
+	}
+
+	if getSharedCache(key, obj) {
+		setLocalCache(key, obj)
+		objectCache.Lock()
+		objectCache.metrics.SharedHits++
+		objectCache.Unlock()
+		return true
+	}
+
+	objectCache.Lock()
+	objectCache.metrics.Misses++
+	objectCache.Unlock()
+	return false
+}
+
+func setCachedObject(key string, obj interface{}) {
+	setLocalCache(key, obj)
+	setSharedCache(key, obj)
+}
+
+// deleteCachedObjects deletes the keys in this node, in the shared cache and
+// in the other nodes.
+func deleteCachedObjects(keys ...string) {
+	// no keys would be a flush
+	if len(keys) == 0 {
+		return
+	}
+
+	deleteLocalCache(keys)
+
+	sharedKeys := []string{}
+	for _, key := range keys {
+		if !strings.HasSuffix(key, "*") {
+			sharedKeys = append(sharedKeys, key)
+		}
+	}
+	deleteSharedCache(sharedKeys...)
+	publishObjectCacheInvalidation(keys)
+}
+
+// FlushObjectCache empties the caches of all the nodes and the shared cache.
+func FlushObjectCache() error {
+	deleteLocalCache(nil)
+	publishObjectCacheInvalidation(nil)
+	return flushSharedCache()
+}
+
+func publishObjectCacheInvalidation(keys []string) {
+	objectCache.Lock()
+	watcherEnabled := objectCache.watcherEnabled
+	objectCache.Unlock()
+	if !watcherEnabled {
+		return
+	}
+
+	data, err := json.Marshal(&objectCacheInvalidation{Node: GetNodeName(), Keys: keys})
+	if err != nil {
+		panic(err)
+	}
+
+	_, err = getRedisClient().Do("PUBLISH", objectCacheWatcherChannel, data)
+	if err != nil {
+		logs.Warning(fmt.Sprintf("failed to publish the object cache invalidation, %s", err.Error()))
+	}
+}
+
+// StartObjectCacheWatcher subscribes to the invalidations of the other
+// nodes through Redis, without redisEndpoint the objects of other nodes are
+// stale for up to objectCacheTtl.
+func StartObjectCacheWatcher() {
+	if getObjectCacheTtl() <= 0 || getRedisClient() == nil {
+		return
+	}
+
+	objectCache.Lock()
+	objectCache.watcherEnabled = true
+	objectCache.Unlock()
+
+	for {
+		err := watchObjectCacheInvalidations()
+		logs.Warning(fmt.Sprintf("the object cache watcher stopped, %s", err.Error()))
+
+		// the invalidations while disconnected are lost
+		deleteLocalCache(nil)
+		time.Sleep(5 * time.Second)
+	}
+}
+
+func watchObjectCacheInvalidations() error {
+	conn, err := dialRedis(0)
+	if err != nil {
+		return err
+	}
+
+	psc := redis.PubSubConn{Conn: conn}
+	defer psc.Close()
+
+	err = psc.Subscribe(objectCacheWatcherChannel)
+	if err != nil {
+		return err
+	}
+
+	for {
+		switch v := psc.Receive().(type) {
+		case redis.Message:
+			invalidation := &objectCacheInvalidation{}
+			err = json.Unmarshal(v.Data, invalidation)
+			if err != nil || invalidation.Node == GetNodeName() {
+				continue
+			}
+			deleteLocalCache(invalidation.Keys)
+		case error:
+			return v
+		}
+	}
+}
+
+func GetObjectCacheMetrics() *ObjectCacheMetrics {
+	objectCache.Lock()
+	defer objectCache.Unlock()
+
+	metrics := objectCache.metrics
+	metrics.Items = len(objectCache.objects)
+	if total := metrics.Hits + metrics.SharedHits + metrics.Misses; total > 0 {
+		metrics.HitRate = float64(metrics.Hits+metrics.SharedHits) / float64(total)
+	}
+	metrics.Ttl = int64(getObjectCacheTtl() / time.Second)
+	metrics.SharedEnabled = getSharedCacheClient() != nil
+	metrics.WatcherEnabled = objectCache.watcherEnabled
+	return &metrics
+}
+
+// deleteCachedObjectsByQuery deletes the cached objects of the rows matching
+// the condition, for the triggers that update a table in bulk.
+func deleteCachedObjectsByQuery(typ string, query string, args ...interface{}) {
+	ids := []string{}
+	switch typ {
+	case "application":
+		applications := []*Application{}
+		err := adapter.Engine.Cols("owner", "name").Where(query, args...).Find(&applications)
+		if err != nil {
+			panic(err)
+		}
+		for _, application := range applications {
+			ids = append(ids, util.GetId(application.Owner, application.Name))
+		}
+	case "organization":
+		organizations := []*Organization{}
+		err := adapter.Engine.Cols("owner", "name").Where(query, args...).Find(&organizations)
+		if err != nil {
+			panic(err)
+		}
+		for _, organization := range organizations {
+			ids = append(ids, util.GetId(organization.Owner, organization.Name))
+		}
+	}
+
+	keys := []string{}
+	for _, id := range ids {
+		keys = append(keys, getSharedCacheKey(typ, id))
+	}
+	deleteCachedObjects(keys...)
+}
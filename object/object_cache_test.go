@@ -0,0 +1,60 @@
+// Copyright 2023 The Casdoor Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package object
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestObjectCache(t *testing.T) {
+	deleteLocalCache(nil)
+
+	setCachedObject(getSharedCacheKey("cert", "admin/cert-1"), &Cert{Owner: "admin", Name: "cert-1", BitSize: 4096})
+	cert := &Cert{}
+	assert.True(t, getCachedObject(getSharedCacheKey("cert", "admin/cert-1"), cert))
+	assert.Equal(t, 4096, cert.BitSize)
+
+	// every read gets a copy of its own
+	cert.BitSize = 2048
+	cert = &Cert{}
+	assert.True(t, getCachedObject(getSharedCacheKey("cert", "admin/cert-1"), cert))
+	assert.Equal(t, 4096, cert.BitSize)
+
+	assert.False(t, getCachedObject(getSharedCacheKey("cert", "admin/cert-2"), &Cert{}))
+
+	deleteCachedObjects(getSharedCacheKey("cert", "admin/cert-1"))
+	assert.False(t, getCachedObject(getSharedCacheKey("cert", "admin/cert-1"), &Cert{}))
+}
+
+func TestDeleteLocalCache(t *testing.T) {
+	deleteLocalCache(nil)
+	setLocalCache(getSharedCacheKey("providers", "org-1"), []*Provider{})
+	setLocalCache(getSharedCacheKey("providers", "org-2"), []*Provider{})
+	setLocalCache(getSharedCacheKey("provider", "provider-1"), &Provider{})
+	assert.Equal(t, 3, GetObjectCacheMetrics().Items)
+
+	deleteCachedObjects()
+	assert.Equal(t, 3, GetObjectCacheMetrics().Items, "no keys isn't a flush")
+
+	deleteLocalCache([]string{getSharedCacheKey("providers", "*")})
+	assert.Equal(t, 1, GetObjectCacheMetrics().Items)
+
+	flushes := GetObjectCacheMetrics().Flushes
+	assert.Nil(t, FlushObjectCache())
+	assert.Equal(t, 0, GetObjectCacheMetrics().Items)
+	assert.Equal(t, flushes+1, GetObjectCacheMetrics().Flushes)
+}
@@ -92,12 +92,21 @@ func GetOidcDiscovery(host string) OidcDiscovery {
 }
 
 func GetJsonWebKeySet() (jose.JSONWebKeySet, error) {
-	certs := GetCerts("admin")
 	jwks := jose.JSONWebKeySet{}
+	if getCachedObject(sharedCacheJwksKey, &jwks) {
+		return jwks, nil
+	}
+
+	certs := GetCerts("admin")
 	// follows the protocol rfc 7517(draft)
 	// link here: https://self-issued.info/docs/draft-ietf-jose-json-web-key.html
 	// or https://datatracker.ietf.org/doc/html/draft-ietf-jose-json-web-key
 	for _, cert := range certs {
+		// an SSH CA has no X.509 certificate to publish and a device CA signs no JWT
+		if cert.Type == CertTypeSshCa || cert.Type == CertTypeDeviceCa {
+			continue
+		}
+
 		certPemBlock := []byte(cert.Certificate)
 		certDerBlock, _ := pem.Decode(certPemBlock)
 		x509Cert, _ := x509.ParseCertificate(certDerBlock.Bytes)
@@ -111,5 +120,6 @@ func GetJsonWebKeySet() (jose.JSONWebKeySet, error) {
 		jwks.Keys = append(jwks.Keys, jwk)
 	}
 
+	setCachedObject(sharedCacheJwksKey, jwks)
 	return jwks, nil
 }
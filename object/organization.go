@@ -15,6 +15,7 @@
 package object
 
 import (
+	"errors"
 	"fmt"
 	"strings"
 
@@ -39,26 +40,53 @@ type ThemeData struct {
 	IsEnabled    bool   `xorm:"bool" json:"isEnabled"`
 }
 
+// Argon2Params tunes the "argon2id" password type, zero values keep the
+// defaults. The memory is in KiB.
+type Argon2Params struct {
+	Memory      int `json:"memory"`
+	Iterations  int `json:"iterations"`
+	Parallelism int `json:"parallelism"`
+}
+
 type Organization struct {
 	Owner       string `xorm:"varchar(100) notnull pk" json:"owner"`
 	Name        string `xorm:"varchar(100) notnull pk" json:"name"`
 	CreatedTime string `xorm:"varchar(100)" json:"createdTime"`
-
-	DisplayName        string     `xorm:"varchar(100)" json:"displayName"`
-	WebsiteUrl         string     `xorm:"varchar(100)" json:"websiteUrl"`
-	Favicon            string     `xorm:"varchar(100)" json:"favicon"`
-	PasswordType       string     `xorm:"varchar(100)" json:"passwordType"`
-	PasswordSalt       string     `xorm:"varchar(100)" json:"passwordSalt"`
-	CountryCodes       []string   `xorm:"varchar(200)"  json:"countryCodes"`
-	DefaultAvatar      string     `xorm:"varchar(100)" json:"defaultAvatar"`
-	DefaultApplication string     `xorm:"varchar(100)" json:"defaultApplication"`
-	Tags               []string   `xorm:"mediumtext" json:"tags"`
-	Languages          []string   `xorm:"varchar(255)" json:"languages"`
-	ThemeData          *ThemeData `xorm:"json" json:"themeData"`
-	MasterPassword     string     `xorm:"varchar(100)" json:"masterPassword"`
-	InitScore          int        `json:"initScore"`
-	EnableSoftDeletion bool       `json:"enableSoftDeletion"`
-	IsProfilePublic    bool       `json:"isProfilePublic"`
+	Version     int    `xorm:"int notnull default 0" json:"version"`
+
+	DisplayName            string                 `xorm:"varchar(100)" json:"displayName"`
+	ParentOrganization     string                 `xorm:"varchar(100) index" json:"parentOrganization"`
+	WebsiteUrl             string                 `xorm:"varchar(100)" json:"websiteUrl"`
+	Favicon                string                 `xorm:"varchar(100)" json:"favicon"`
+	PasswordType           string                 `xorm:"varchar(100)" json:"passwordType"`
+	PasswordSalt           string                 `xorm:"varchar(100)" json:"passwordSalt"`
+	Argon2Params           *Argon2Params          `xorm:"json" json:"argon2Params"`
+	CountryCodes           []string               `xorm:"varchar(200)"  json:"countryCodes"`
+	DefaultAvatar          string                 `xorm:"varchar(100)" json:"defaultAvatar"`
+	DefaultApplication     string                 `xorm:"varchar(100)" json:"defaultApplication"`
+	Domain                 string                 `xorm:"varchar(100) index" json:"domain"`
+	DomainToken            string                 `xorm:"varchar(100)" json:"domainToken"`
+	IsDomainVerified       bool                   `json:"isDomainVerified"`
+	EmailDomains           []*EmailDomain         `xorm:"mediumtext" json:"emailDomains"`
+	RequireDomainForAdmin  bool                   `json:"requireDomainForAdmin"`
+	Tags                   []string               `xorm:"mediumtext" json:"tags"`
+	Languages              []string               `xorm:"varchar(255)" json:"languages"`
+	DefaultLanguage        string                 `xorm:"varchar(100)" json:"defaultLanguage"`
+	ThemeData              *ThemeData             `xorm:"json" json:"themeData"`
+	Branding               *Branding              `xorm:"json" json:"branding"`
+	MasterPassword         string                 `xorm:"varchar(100)" json:"masterPassword"`
+	InitScore              int                    `json:"initScore"`
+	EnableSoftDeletion     bool                   `json:"enableSoftDeletion"`
+	IsProfilePublic        bool                   `json:"isProfilePublic"`
+	LoginAlert             *LoginAlertConfig      `xorm:"json" json:"loginAlert"`
+	AccountRecovery        *AccountRecoveryConfig `xorm:"json" json:"accountRecovery"`
+	ContactChangeHoldHours int                    `json:"contactChangeHoldHours"`
+	ErrorMessages          []*ErrorMessage        `xorm:"mediumtext" json:"errorMessages"`
+	RoutingRules           []*RoutingRule         `xorm:"mediumtext" json:"routingRules"`
+	SshCa                  string                 `xorm:"varchar(100)" json:"sshCa"`
+	DeviceCa               string                 `xorm:"varchar(100)" json:"deviceCa"`
+	AvatarSettings         *AvatarConfig          `xorm:"json" json:"avatarSettings"`
+	Shard                  string                 `xorm:"varchar(100)" json:"shard"`
 
 	AccountItems []*AccountItem `xorm:"varchar(3000)" json:"accountItems"`
 }
@@ -100,12 +128,18 @@ func getOrganization(owner string, name string) *Organization {
 	}
 
 	organization := Organization{Owner: owner, Name: name}
+	key := getSharedCacheKey("organization", util.GetId(owner, name))
+	if getCachedObject(key, &organization) {
+		return &organization
+	}
+
 	existed, err := adapter.Engine.Get(&organization)
 	if err != nil {
 		panic(err)
 	}
 
 	if existed {
+		setCachedObject(key, &organization)
 		return &organization
 	}
 
@@ -136,24 +170,47 @@ func GetMaskedOrganizations(organizations []*Organization) []*Organization {
 }
 
 func UpdateOrganization(id string, organization *Organization) bool {
+	affected, _ := UpdateOrganizationWithPrecondition(id, organization, nil)
+	return affected
+}
+
+// UpdateOrganizationWithPrecondition updates the organization only if it
+// satisfies precondition, otherwise it returns ErrPreconditionFailed.
+func UpdateOrganizationWithPrecondition(id string, organization *Organization, precondition *Precondition) (bool, error) {
 	owner, name := util.GetOwnerAndNameFromId(id)
-	if getOrganization(owner, name) == nil {
-		return false
+	oldOrganization := getOrganization(owner, name)
+	if !precondition.isSatisfiedBy(oldOrganization) {
+		return false, ErrPreconditionFailed
+	}
+	if oldOrganization == nil {
+		return false, nil
 	}
+	if msg := CheckOrganizationCa(organization, oldOrganization, "en"); msg != "" {
+		return false, errors.New(msg)
+	}
+	organization.prepareDomain(oldOrganization)
+	organization.prepareEmailDomains(oldOrganization)
 
 	if name == "built-in" {
 		organization.Name = name
 	}
 
+	// the users have to be moved by MoveOrganizationShard
+	organization.Shard = oldOrganization.Shard
+
 	if name != organization.Name {
-		err := organizationChangeTrigger(name, organization.Name)
+		err := precondition.checkRename(name, organization.Name)
 		if err != nil {
-			return false
+			return false, err
+		}
+		err = organizationChangeTrigger(name, organization.Name)
+		if err != nil {
+			return false, nil
 		}
 	}
 
 	if organization.MasterPassword != "" && organization.MasterPassword != "***" {
-		credManager := cred.GetCredManager(organization.PasswordType)
+		credManager := organization.getCredManager()
 		if credManager != nil {
 			hashedPassword := credManager.GetHashedPassword(organization.MasterPassword, "", organization.PasswordSalt)
 			organization.MasterPassword = hashedPassword
@@ -164,15 +221,18 @@ func UpdateOrganization(id string, organization *Organization) bool {
 	if organization.MasterPassword == "***" {
 		session.Omit("master_password")
 	}
-	affected, err := session.Update(organization)
+	affected, err := updateWithVersion(session, organization, oldOrganization.Version, precondition)
 	if err != nil {
-		panic(err)
+		return false, err
 	}
 
-	return affected != 0
+	deleteCachedObjects(getSharedCacheKey("organization", id), getSharedCacheKey("organization", util.GetId(organization.Owner, organization.Name)))
+	return affected, nil
 }
 
 func AddOrganization(organization *Organization) bool {
+	organization.prepareDomain(nil)
+	organization.prepareEmailDomains(nil)
 	affected, err := adapter.Engine.Insert(organization)
 	if err != nil {
 		panic(err)
@@ -191,11 +251,15 @@ func DeleteOrganization(organization *Organization) bool {
 		panic(err)
 	}
 
+	deleteCachedObjects(getSharedCacheKey("organization", util.GetId(organization.Owner, organization.Name)))
 	return affected != 0
 }
 
+// GetOrganizationByUser returns the organization of the user with the
+// settings inherited from its parent organizations, it isn't meant to be
+// written back.
 func GetOrganizationByUser(user *User) *Organization {
-	return getOrganization("admin", user.Owner)
+	return getInheritedOrganization(getOrganization("admin", user.Owner))
 }
 
 func GetAccountItemByName(name string, organization *Organization) *AccountItem {
@@ -280,14 +344,26 @@ func organizationChangeTrigger(oldName string, newName string) error {
 
 	application := new(Application)
 	application.Organization = newName
-	_, err = session.Where("organization=?", oldName).Update(application)
+	_, err = session.Where("organization=?", oldName).Incr("version").Update(application)
 	if err != nil {
 		return err
 	}
 
 	user := new(User)
 	user.Owner = newName
-	_, err = session.Where("owner=?", oldName).Update(user)
+	if getOrganizationShard(oldName) == "" {
+		_, err = session.Where("owner=?", oldName).Update(user)
+	} else {
+		// the users are in a shard, out of the transaction
+		_, err = getUserEngine(oldName).Where("owner=?", oldName).Update(user)
+	}
+	if err != nil {
+		return err
+	}
+
+	child := new(Organization)
+	child.ParentOrganization = newName
+	_, err = session.Where("owner=? and parent_organization=?", "admin", oldName).Incr("version").Update(child)
 	if err != nil {
 		return err
 	}
@@ -411,5 +487,22 @@ func organizationChangeTrigger(oldName string, newName string) error {
 		return err
 	}
 
-	return session.Commit()
+	err = session.Commit()
+	if err != nil {
+		return err
+	}
+
+	deleteCachedObjectsByQuery("application", "organization=?", newName)
+	deleteCachedObjectsByQuery("organization", "owner=? and parent_organization=?", "admin", newName)
+	return nil
+}
+
+// getCredManager returns the manager for the password type of the
+// organization, with its hashing parameters.
+func (organization *Organization) getCredManager() cred.CredManager {
+	if organization.PasswordType == "argon2id" && organization.Argon2Params != nil {
+		params := organization.Argon2Params
+		return cred.NewArgon2idCredManagerWithParams(params.Memory, params.Iterations, params.Parallelism)
+	}
+	return cred.GetCredManager(organization.PasswordType)
 }
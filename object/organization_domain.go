@@ -0,0 +1,184 @@
+// Copyright 2023 The Casdoor Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package object
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/casdoor/casdoor/conf"
+	"github.com/casdoor/casdoor/util"
+	"github.com/xorm-io/core"
+	"golang.org/x/crypto/acme"
+	"golang.org/x/crypto/acme/autocert"
+)
+
+const domainChallengePrefix = "_casdoor-challenge."
+
+var (
+	acmeManager     *autocert.Manager
+	acmeManagerOnce sync.Once
+)
+
+func normalizeDomain(domain string) string {
+	return strings.TrimSuffix(strings.ToLower(strings.TrimSpace(domain)), ".")
+}
+
+// prepareDomain keeps the verification state from oldOrganization, a new
+// domain needs to be verified again with a new token.
+func (organization *Organization) prepareDomain(oldOrganization *Organization) {
+	organization.Domain = normalizeDomain(organization.Domain)
+	if oldOrganization != nil && oldOrganization.Domain == organization.Domain {
+		organization.DomainToken = oldOrganization.DomainToken
+		organization.IsDomainVerified = oldOrganization.IsDomainVerified
+		return
+	}
+
+	organization.DomainToken = ""
+	organization.IsDomainVerified = false
+	if organization.Domain != "" {
+		organization.DomainToken = util.GenerateId()
+	}
+}
+
+// GetDomainChallenge returns the name and the value of the TXT record that
+// proves the ownership of the domain of the organization.
+func (organization *Organization) GetDomainChallenge() (string, string) {
+	return domainChallengePrefix + organization.Domain, organization.DomainToken
+}
+
+// checkDomainChallenge looks up the TXT record "_casdoor-challenge.<domain>"
+// and checks that it contains token.
+func checkDomainChallenge(domain string, token string) error {
+	name := domainChallengePrefix + domain
+	records, err := net.LookupTXT(name)
+	if err != nil {
+		return fmt.Errorf("failed to look up the TXT record: %s, %s", name, err.Error())
+	}
+	if !util.ContainsString(records, token) {
+		return fmt.Errorf("the TXT record: %s doesn't contain the token: %s", name, token)
+	}
+	return nil
+}
+
+// GetOrganizationByDomain returns the organization that has verified the
+// domain, the port of a Host header is ignored.
+func GetOrganizationByDomain(host string) *Organization {
+	domain := host
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		domain = h
+	}
+	domain = normalizeDomain(domain)
+	if domain == "" {
+		return nil
+	}
+
+	organization := Organization{Domain: domain, IsDomainVerified: true}
+	existed, err := adapter.Engine.Get(&organization)
+	if err != nil {
+		panic(err)
+	}
+
+	if existed {
+		return &organization
+	}
+	return nil
+}
+
+// VerifyOrganizationDomain marks the domain as verified when the TXT record of
+// the challenge contains the token.
+func VerifyOrganizationDomain(organization *Organization) error {
+	if organization.Domain == "" {
+		return fmt.Errorf("the organization: %s has no domain", organization.Name)
+	}
+
+	other := GetOrganizationByDomain(organization.Domain)
+	if other != nil && other.Name != organization.Name {
+		return fmt.Errorf("the domain: %s is already used by the organization: %s", organization.Domain, other.Name)
+	}
+
+	err := checkDomainChallenge(organization.Domain, organization.DomainToken)
+	if err != nil {
+		return err
+	}
+
+	organization.IsDomainVerified = true
+	_, err = adapter.Engine.ID(core.PK{organization.Owner, organization.Name}).Cols("is_domain_verified").Incr("version").Update(organization)
+	if err != nil {
+		panic(err)
+	}
+
+	deleteCachedObjects(getSharedCacheKey("organization", util.GetId(organization.Owner, organization.Name)))
+	return nil
+}
+
+// GetAcmeManager returns the ACME manager for the verified domains of the
+// organizations, or nil when "acmeEnabled" isn't set. Certificates are kept in
+// "acmeCacheDir" and renewed by the manager before they expire.
+func GetAcmeManager() *autocert.Manager {
+	acmeManagerOnce.Do(func() {
+		if enabled, err := conf.GetConfigBool("acmeEnabled"); err != nil || !enabled {
+			return
+		}
+
+		cacheDir := conf.GetConfigString("acmeCacheDir")
+		if cacheDir == "" {
+			cacheDir = "acme"
+		}
+
+		acmeManager = &autocert.Manager{
+			Prompt: autocert.AcceptTOS,
+			Cache:  autocert.DirCache(cacheDir),
+			Email:  conf.GetConfigString("acmeEmail"),
+			HostPolicy: func(_ context.Context, host string) error {
+				if GetOrganizationByDomain(host) == nil {
+					return fmt.Errorf("the domain: %s isn't verified by any organization", host)
+				}
+				return nil
+			},
+		}
+		if directoryUrl := conf.GetConfigString("acmeDirectoryUrl"); directoryUrl != "" {
+			acmeManager.Client = &acme.Client{DirectoryURL: directoryUrl}
+		}
+	})
+	return acmeManager
+}
+
+// StartAcmeServer serves handler over TLS on "acmeHttpsPort" (443 by default)
+// with the certificates of the ACME manager.
+func StartAcmeServer(handler http.Handler) {
+	manager := GetAcmeManager()
+	if manager == nil {
+		return
+	}
+
+	port := conf.GetConfigString("acmeHttpsPort")
+	if port == "" {
+		port = "443"
+	}
+
+	tlsConfig := manager.TLSConfig()
+	tlsConfig.MinVersion = tls.VersionTLS12
+	server := &http.Server{Addr: ":" + port, Handler: handler, TLSConfig: tlsConfig}
+	err := server.ListenAndServeTLS("", "")
+	if err != nil {
+		panic(err)
+	}
+}
@@ -0,0 +1,197 @@
+// Copyright 2023 The Casdoor Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package object
+
+import (
+	"fmt"
+
+	"github.com/casdoor/casdoor/i18n"
+)
+
+// maxOrganizationDepth bounds the walks up the hierarchy, so that a cycle
+// made before the checks existed can't hang a request.
+const maxOrganizationDepth = 16
+
+// getOrganizationAncestors returns the ancestors of the organization, the
+// parent first.
+func getOrganizationAncestors(organization *Organization) []*Organization {
+	ancestors := []*Organization{}
+	visited := map[string]bool{organization.Name: true}
+	parentName := organization.ParentOrganization
+	for parentName != "" && !visited[parentName] && len(ancestors) < maxOrganizationDepth {
+		parent := getOrganization("admin", parentName)
+		if parent == nil {
+			break
+		}
+
+		visited[parentName] = true
+		ancestors = append(ancestors, parent)
+		parentName = parent.ParentOrganization
+	}
+	return ancestors
+}
+
+// IsOrganizationAncestor returns whether ancestor is above the organization
+// in the hierarchy, an organization isn't its own ancestor.
+func IsOrganizationAncestor(ancestor string, organization string) bool {
+	if ancestor == "" || ancestor == organization {
+		return false
+	}
+
+	child := getOrganization("admin", organization)
+	if child == nil {
+		return false
+	}
+
+	for _, parent := range getOrganizationAncestors(child) {
+		if parent.Name == ancestor {
+			return true
+		}
+	}
+	return false
+}
+
+// GetDescendantOrganizations returns the names of all organizations below
+// the organization.
+func GetDescendantOrganizations(name string) []string {
+	children := map[string][]string{}
+	for _, organization := range GetOrganizations("admin") {
+		if organization.ParentOrganization != "" {
+			children[organization.ParentOrganization] = append(children[organization.ParentOrganization], organization.Name)
+		}
+	}
+
+	descendants := []string{}
+	visited := map[string]bool{name: true}
+	queue := []string{name}
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+		for _, child := range children[current] {
+			if !visited[child] {
+				visited[child] = true
+				descendants = append(descendants, child)
+				queue = append(queue, child)
+			}
+		}
+	}
+	return descendants
+}
+
+// CheckOrganizationParent checks that the parent exists and that the
+// organization doesn't become an ancestor of itself.
+func CheckOrganizationParent(organization *Organization, lang string) string {
+	if organization.ParentOrganization == "" {
+		return ""
+	}
+
+	if organization.ParentOrganization == organization.Name {
+		return i18n.Translate(lang, "organization:An organization can't be its own parent")
+	}
+
+	parent := getOrganization("admin", organization.ParentOrganization)
+	if parent == nil {
+		return fmt.Sprintf(i18n.Translate(lang, "organization:The parent organization: %s does not exist"), organization.ParentOrganization)
+	}
+
+	ancestors := getOrganizationAncestors(parent)
+	if len(ancestors) >= maxOrganizationDepth-1 {
+		return i18n.Translate(lang, "organization:The organization hierarchy is too deep")
+	}
+	for _, ancestor := range ancestors {
+		if ancestor.Name == organization.Name {
+			return i18n.Translate(lang, "organization:The parent organization can't be a descendant of the organization")
+		}
+	}
+	return ""
+}
+
+// getInheritedOrganization returns a copy of the organization with the
+// settings it leaves empty taken from the nearest ancestor that sets them:
+// the password policy, the favicon, the default avatar, the login alerts and
+// the theme. The branding is merged down the hierarchy, the closest
+// organization wins.
+func getInheritedOrganization(organization *Organization) *Organization {
+	if organization == nil || organization.ParentOrganization == "" {
+		return organization
+	}
+
+	return inheritOrganization(organization, getOrganizationAncestors(organization))
+}
+
+// inheritOrganization fills the organization from its ancestors, the parent
+// first.
+func inheritOrganization(organization *Organization, ancestors []*Organization) *Organization {
+	if len(ancestors) == 0 {
+		return organization
+	}
+
+	res := *organization
+	for _, ancestor := range ancestors {
+		if res.PasswordType == "" {
+			res.PasswordType = ancestor.PasswordType
+			res.PasswordSalt = ancestor.PasswordSalt
+			res.Argon2Params = ancestor.Argon2Params
+		}
+		if res.Favicon == "" {
+			res.Favicon = ancestor.Favicon
+		}
+		if res.DefaultAvatar == "" {
+			res.DefaultAvatar = ancestor.DefaultAvatar
+		}
+		if res.LoginAlert == nil {
+			res.LoginAlert = ancestor.LoginAlert
+		}
+		if res.ThemeData == nil || !res.ThemeData.IsEnabled {
+			if ancestor.ThemeData != nil && ancestor.ThemeData.IsEnabled {
+				res.ThemeData = ancestor.ThemeData
+			}
+		}
+	}
+
+	var branding *Branding
+	for i := len(ancestors) - 1; i >= 0; i-- {
+		if ancestors[i].Branding != nil {
+			if branding == nil {
+				branding = &Branding{}
+			}
+			branding = mergeBranding(branding, ancestors[i].Branding)
+		}
+	}
+	if branding != nil {
+		res.Branding = mergeBranding(branding, organization.Branding)
+	}
+
+	return &res
+}
+
+// getInheritedProviderMap returns the providers of the organization by name,
+// together with the ones of its ancestors that it doesn't override.
+func getInheritedProviderMap(owner string) map[string]*Provider {
+	m := getProviderMap(owner)
+	organization := getOrganization("admin", owner)
+	if organization == nil {
+		return m
+	}
+
+	for _, ancestor := range getOrganizationAncestors(organization) {
+		for name, provider := range getProviderMap(ancestor.Name) {
+			if _, ok := m[name]; !ok {
+				m[name] = provider
+			}
+		}
+	}
+	return m
+}
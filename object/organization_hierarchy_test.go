@@ -0,0 +1,83 @@
+// Copyright 2023 The Casdoor Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package object
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInheritOrganization(t *testing.T) {
+	root := &Organization{
+		Name:         "reseller",
+		PasswordType: "bcrypt",
+		Favicon:      "https://example.com/reseller.ico",
+		ThemeData:    &ThemeData{ColorPrimary: "#ff0000", IsEnabled: true},
+		Branding:     &Branding{Logo: "https://example.com/reseller.png", CustomCss: "body {}"},
+	}
+	division := &Organization{
+		Name:               "division",
+		ParentOrganization: "reseller",
+		Branding:           &Branding{Logo: "https://example.com/division.png"},
+	}
+
+	scenarios := []struct {
+		description  string
+		organization *Organization
+		ancestors    []*Organization
+		check        func(t *testing.T, res *Organization)
+	}{
+		{
+			"no ancestors",
+			&Organization{Name: "top", PasswordType: ""},
+			nil,
+			func(t *testing.T, res *Organization) {
+				assert.Equal(t, "", res.PasswordType)
+			},
+		},
+		{
+			"empty settings are inherited",
+			&Organization{Name: "customer", ParentOrganization: "division"},
+			[]*Organization{division, root},
+			func(t *testing.T, res *Organization) {
+				assert.Equal(t, "bcrypt", res.PasswordType)
+				assert.Equal(t, "https://example.com/reseller.ico", res.Favicon)
+				assert.Equal(t, "#ff0000", res.ThemeData.ColorPrimary)
+				assert.Equal(t, "https://example.com/division.png", res.Branding.Logo)
+				assert.Equal(t, "body {}", res.Branding.CustomCss)
+			},
+		},
+		{
+			"overrides win",
+			&Organization{Name: "customer", ParentOrganization: "division", PasswordType: "argon2id", Favicon: "https://example.com/customer.ico", Branding: &Branding{CustomCss: "div {}"}},
+			[]*Organization{division, root},
+			func(t *testing.T, res *Organization) {
+				assert.Equal(t, "argon2id", res.PasswordType)
+				assert.Equal(t, "https://example.com/customer.ico", res.Favicon)
+				assert.Equal(t, "https://example.com/division.png", res.Branding.Logo)
+				assert.Equal(t, "div {}", res.Branding.CustomCss)
+			},
+		},
+	}
+
+	for _, scenery := range scenarios {
+		t.Run(scenery.description, func(t *testing.T) {
+			scenery.check(t, inheritOrganization(scenery.organization, scenery.ancestors))
+		})
+	}
+
+	assert.Equal(t, "https://example.com/division.png", division.Branding.Logo, "the ancestors must not be changed")
+}
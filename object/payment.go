@@ -54,6 +54,9 @@ type Payment struct {
 	InvoiceTaxId  string `xorm:"varchar(100)" json:"invoiceTaxId"`
 	InvoiceRemark string `xorm:"varchar(100)" json:"invoiceRemark"`
 	InvoiceUrl    string `xorm:"varchar(255)" json:"invoiceUrl"`
+
+	Subscription string `xorm:"varchar(100)" json:"subscription"`
+	ExternalId   string `xorm:"varchar(100) index" json:"externalId"`
 }
 
 func GetPaymentCount(owner, field, value string) int {
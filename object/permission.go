@@ -43,6 +43,8 @@ type Permission struct {
 	Approver    string `xorm:"varchar(100)" json:"approver"`
 	ApproveTime string `xorm:"varchar(100)" json:"approveTime"`
 	State       string `xorm:"varchar(100)" json:"state"`
+
+	Approvers []string `xorm:"mediumtext" json:"approvers"`
 }
 
 type PermissionRule struct {
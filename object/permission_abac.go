@@ -0,0 +1,313 @@
+// Copyright 2023 The Casdoor Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package object
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/casbin/casbin/v2"
+	"github.com/casbin/casbin/v2/log"
+)
+
+// The ABAC functions of the permission matchers:
+//
+//	userAttr(r.sub, "tag")           a field (by JSON name) or a property of the user
+//	attr(r.ctx, "resource.owner")    a field of a JSON object passed in the request
+//	timeInRange(r.ctx, "09:00", "18:00")  the "time" of the JSON object (or now) is in the range
+//
+// ipMatch(attr(r.ctx, "ip"), "10.0.0.0/8") of Casbin can be used for the IP.
+func addAbacFunctions(enforcer *casbin.Enforcer) {
+	enforcer.AddFunction("userAttr", userAttrFunc)
+	enforcer.AddFunction("attr", attrFunc)
+	enforcer.AddFunction("timeInRange", timeInRangeFunc)
+}
+
+// getAttributeByPath returns the value at the dot separated path, or "" so
+// that comparisons in matchers on missing attributes are false.
+func getAttributeByPath(attributes map[string]interface{}, path string) interface{} {
+	var value interface{} = attributes
+	for _, key := range strings.Split(path, ".") {
+		m, ok := value.(map[string]interface{})
+		if !ok {
+			return ""
+		}
+		if value, ok = m[key]; !ok || value == nil {
+			return ""
+		}
+	}
+	return value
+}
+
+// parseAttributes accepts a map or a JSON object in a string, the usual form
+// of a request value.
+func parseAttributes(value interface{}) (map[string]interface{}, error) {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		return v, nil
+	case string:
+		attributes := map[string]interface{}{}
+		if strings.TrimSpace(v) == "" {
+			return attributes, nil
+		}
+		err := json.Unmarshal([]byte(v), &attributes)
+		if err != nil {
+			return nil, fmt.Errorf("the request value: %s isn't a JSON object", v)
+		}
+		return attributes, nil
+	default:
+		return nil, fmt.Errorf("the request value: %v isn't a JSON object", value)
+	}
+}
+
+func getAttributeArgs(name string, args []interface{}) (interface{}, string, error) {
+	if len(args) != 2 {
+		return nil, "", fmt.Errorf("%s() expects 2 arguments, got %d", name, len(args))
+	}
+	path, ok := args[1].(string)
+	if !ok {
+		return nil, "", fmt.Errorf("the second argument of %s() must be a string", name)
+	}
+	return args[0], path, nil
+}
+
+func userAttrFunc(args ...interface{}) (interface{}, error) {
+	value, path, err := getAttributeArgs("userAttr", args)
+	if err != nil {
+		return nil, err
+	}
+
+	userId, ok := value.(string)
+	if !ok || !strings.Contains(userId, "/") {
+		return "", nil
+	}
+
+	user := GetUser(userId)
+	if user == nil {
+		return "", nil
+	}
+
+	attributes := toJsonMap(user)
+	delete(attributes, "password")
+	delete(attributes, "passwordSalt")
+	res := getAttributeByPath(attributes, path)
+	if res == "" {
+		if property, ok := user.Properties[path]; ok {
+			return property, nil
+		}
+	}
+	return res, nil
+}
+
+func attrFunc(args ...interface{}) (interface{}, error) {
+	value, path, err := getAttributeArgs("attr", args)
+	if err != nil {
+		return nil, err
+	}
+
+	attributes, err := parseAttributes(value)
+	if err != nil {
+		return nil, err
+	}
+	return getAttributeByPath(attributes, path), nil
+}
+
+func parseClock(clock string) (int, error) {
+	t, err := time.Parse("15:04", clock)
+	if err != nil {
+		return 0, fmt.Errorf("the time: %s must be in the HH:MM format", clock)
+	}
+	return t.Hour()*60 + t.Minute(), nil
+}
+
+func timeInRangeFunc(args ...interface{}) (interface{}, error) {
+	if len(args) != 3 {
+		return nil, fmt.Errorf("timeInRange() expects 3 arguments, got %d", len(args))
+	}
+	start, ok1 := args[1].(string)
+	end, ok2 := args[2].(string)
+	if !ok1 || !ok2 {
+		return nil, fmt.Errorf("the range of timeInRange() must be strings")
+	}
+
+	attributes, err := parseAttributes(args[0])
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	if s, ok := attributes["time"].(string); ok && s != "" {
+		now, err = time.Parse(time.RFC3339, s)
+		if err != nil {
+			return nil, fmt.Errorf("the request time: %s must be in the RFC 3339 format", s)
+		}
+	}
+
+	startMinute, err := parseClock(start)
+	if err != nil {
+		return nil, err
+	}
+	endMinute, err := parseClock(end)
+	if err != nil {
+		return nil, err
+	}
+
+	minute := now.Hour()*60 + now.Minute()
+	if startMinute <= endMinute {
+		return minute >= startMinute && minute < endMinute, nil
+	}
+	// the range spans midnight, like 22:00 - 06:00
+	return minute >= startMinute || minute < endMinute, nil
+}
+
+type ModelValidationRequest struct {
+	Request  []string `json:"request"`
+	Expected *bool    `json:"expected"`
+}
+
+type ModelValidationResult struct {
+	Request []string `json:"request"`
+	Allowed bool     `json:"allowed"`
+	Passed  bool     `json:"passed"`
+	Explain []string `json:"explain"`
+	Error   string   `json:"error"`
+}
+
+// ModelValidation is a model with policies and requests to try, the owner is
+// the organization it's validated for.
+type ModelValidation struct {
+	Owner            string                    `json:"owner"`
+	ModelText        string                    `json:"modelText"`
+	Policies         [][]string                `json:"policies"`
+	GroupingPolicies [][]string                `json:"groupingPolicies"`
+	Requests         []*ModelValidationRequest `json:"requests"`
+}
+
+type ModelValidationReport struct {
+	IsValid bool                     `json:"isValid"`
+	Error   string                   `json:"error"`
+	Results []*ModelValidationResult `json:"results"`
+}
+
+// padPolicy fills the policy up to the built-in policy definition, with the
+// permission ID as the last field like the stored rules.
+func padPolicy(policy []string, permissionId string) []string {
+	res := append([]string{}, policy...)
+	for len(res) < builtInAvailableField {
+		res = append(res, "")
+	}
+	return append(res, permissionId)
+}
+
+func trimPolicy(policy []string) []string {
+	if len(policy) > 0 && policy[len(policy)-1] == "validation" {
+		policy = policy[:len(policy)-1]
+	}
+	for len(policy) > 0 && policy[len(policy)-1] == "" {
+		policy = policy[:len(policy)-1]
+	}
+	return policy
+}
+
+// ValidateModel checks that the model parses, that the policies fit it and
+// that the matcher evaluates, then runs the requests with their expected
+// results. Nothing is written to the database.
+func ValidateModel(validation *ModelValidation) (report *ModelValidationReport) {
+	report = &ModelValidationReport{IsValid: true, Results: []*ModelValidationResult{}}
+	defer func() {
+		if r := recover(); r != nil {
+			report.IsValid = false
+			report.Error = fmt.Sprintf("%v", r)
+		}
+	}()
+
+	m, err := GetBuiltInModel(validation.ModelText)
+	if err != nil {
+		report.IsValid = false
+		report.Error = err.Error()
+		return report
+	}
+
+	enforcer, err := casbin.NewEnforcer(&log.DefaultLogger{}, false)
+	if err != nil {
+		panic(err)
+	}
+	err = enforcer.InitWithModelAndAdapter(m, nil)
+	if err != nil {
+		report.IsValid = false
+		report.Error = err.Error()
+		return report
+	}
+	addAbacFunctions(enforcer)
+
+	for _, policy := range validation.Policies {
+		if len(policy) > builtInAvailableField {
+			report.IsValid = false
+			report.Error = fmt.Sprintf("the policy: %v has more than %d fields", policy, builtInAvailableField)
+			return report
+		}
+		_, err = enforcer.AddPolicy(padPolicy(policy, "validation"))
+		if err != nil {
+			panic(err)
+		}
+	}
+	if len(validation.GroupingPolicies) > 0 {
+		if !HasRoleDefinition(m) {
+			report.IsValid = false
+			report.Error = "the grouping policies need a [role_definition] in the model"
+			return report
+		}
+		for _, policy := range validation.GroupingPolicies {
+			_, err = enforcer.AddGroupingPolicy(padPolicy(policy, "validation"))
+			if err != nil {
+				panic(err)
+			}
+		}
+	}
+
+	requests := validation.Requests
+	if len(requests) == 0 {
+		// an empty request still evaluates the matcher and finds its errors
+		tokens := m["r"]["r"].Tokens
+		requests = []*ModelValidationRequest{{Request: make([]string, len(tokens))}}
+	}
+
+	for _, request := range requests {
+		rvals := []interface{}{}
+		for _, value := range request.Request {
+			rvals = append(rvals, value)
+		}
+
+		result := &ModelValidationResult{Request: request.Request, Passed: true}
+		allowed, explain, err := enforcer.EnforceEx(rvals...)
+		if err != nil {
+			result.Error = err.Error()
+			result.Passed = false
+			report.IsValid = false
+		} else {
+			result.Allowed = allowed
+			result.Explain = trimPolicy(explain)
+			if request.Expected != nil && *request.Expected != allowed {
+				result.Passed = false
+				report.IsValid = false
+			}
+		}
+		report.Results = append(report.Results, result)
+	}
+
+	return report
+}
@@ -0,0 +1,122 @@
+// Copyright 2023 The Casdoor Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package object
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateModelTemplates(t *testing.T) {
+	for _, template := range GetModelTemplates() {
+		t.Run(template.Name, func(t *testing.T) {
+			report := ValidateModel(template.Validation)
+			assert.True(t, report.IsValid, report.Error)
+			for _, result := range report.Results {
+				assert.True(t, result.Passed, "%v: %s", result.Request, result.Error)
+			}
+		})
+	}
+}
+
+func TestValidateModel(t *testing.T) {
+	scenarios := []struct {
+		description string
+		validation  *ModelValidation
+		isValid     bool
+	}{
+		{
+			"Should report an unknown function in the matcher",
+			&ModelValidation{ModelText: `[request_definition]
+r = sub, obj, act
+
+[policy_definition]
+p = sub, obj, act
+
+[policy_effect]
+e = some(where (p.eft == allow))
+
+[matchers]
+m = unknown(r.sub) && r.obj == p.obj`},
+			false,
+		},
+		{
+			"Should report grouping policies without role definition",
+			&ModelValidation{
+				ModelText: `[request_definition]
+r = sub, obj, act
+
+[policy_definition]
+p = sub, obj, act
+
+[policy_effect]
+e = some(where (p.eft == allow))
+
+[matchers]
+m = r.sub == p.sub && r.obj == p.obj`,
+				GroupingPolicies: [][]string{{"alice", "admin"}},
+			},
+			false,
+		},
+		{
+			"Should report a request with the wrong size",
+			&ModelValidation{
+				ModelText: `[request_definition]
+r = sub, obj, act
+
+[policy_definition]
+p = sub, obj, act
+
+[policy_effect]
+e = some(where (p.eft == allow))
+
+[matchers]
+m = r.sub == p.sub && r.obj == p.obj && r.act == p.act`,
+				Policies: [][]string{{"alice", "data1", "read"}},
+				Requests: []*ModelValidationRequest{{Request: []string{"alice", "data1"}}},
+			},
+			false,
+		},
+	}
+
+	for _, scenery := range scenarios {
+		t.Run(scenery.description, func(t *testing.T) {
+			report := ValidateModel(scenery.validation)
+			assert.Equal(t, scenery.isValid, report.IsValid)
+		})
+	}
+}
+
+func TestAttrFunctions(t *testing.T) {
+	value, err := attrFunc(`{"resource": {"owner": "built-in/alice"}, "level": 3}`, "resource.owner")
+	assert.Nil(t, err)
+	assert.Equal(t, "built-in/alice", value)
+
+	value, err = attrFunc(map[string]interface{}{"level": float64(3)}, "level")
+	assert.Nil(t, err)
+	assert.Equal(t, float64(3), value)
+
+	value, err = attrFunc(`{}`, "missing.key")
+	assert.Nil(t, err)
+	assert.Equal(t, "", value)
+
+	_, err = attrFunc("not json", "key")
+	assert.NotNil(t, err)
+
+	inRange, err := timeInRangeFunc(`{"time": "2023-03-01T23:30:00Z"}`, "22:00", "06:00")
+	assert.Nil(t, err)
+	assert.Equal(t, true, inRange)
+}
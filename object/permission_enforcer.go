@@ -61,6 +61,7 @@ func getEnforcer(permission *Permission) *casbin.Enforcer {
 
 	enforcer.InitWithModelAndAdapter(m, nil)
 	enforcer.SetAdapter(adapter)
+	addAbacFunctions(enforcer)
 
 	policyFilter := xormadapter.Filter{
 		V5: []string{permission.GetId()},
@@ -118,34 +119,52 @@ func getPolicies(permission *Permission) [][]string {
 func getGroupingPolicies(permission *Permission) [][]string {
 	var groupingPolicies [][]string
 
+	visited := map[string]bool{}
+	for _, role := range permission.Roles {
+		groupingPolicies = append(groupingPolicies, getRoleGroupingPolicies(permission, role, visited)...)
+	}
+
+	return groupingPolicies
+}
+
+// getRoleGroupingPolicies returns the grouping policies of the members of
+// the role, following the sub roles so that a chain of inherited roles is
+// resolved by the role manager.
+func getRoleGroupingPolicies(permission *Permission, role string, visited map[string]bool) [][]string {
+	var groupingPolicies [][]string
+	if visited[role] {
+		return groupingPolicies
+	}
+	visited[role] = true
+
+	roleObj := GetRole(role)
+	if roleObj == nil {
+		return groupingPolicies
+	}
+
 	domainExist := len(permission.Domains) > 0
 	permissionId := permission.GetId()
 
-	for _, role := range permission.Roles {
-		roleObj := GetRole(role)
-		if roleObj == nil {
-			continue
-		}
-
-		for _, subUser := range roleObj.Users {
-			if domainExist {
-				for _, domain := range permission.Domains {
-					groupingPolicies = append(groupingPolicies, []string{subUser, domain, role, "", "", permissionId})
-				}
-			} else {
-				groupingPolicies = append(groupingPolicies, []string{subUser, role, "", "", "", permissionId})
+	for _, subUser := range roleObj.Users {
+		if domainExist {
+			for _, domain := range permission.Domains {
+				groupingPolicies = append(groupingPolicies, []string{subUser, domain, role, "", "", permissionId})
 			}
+		} else {
+			groupingPolicies = append(groupingPolicies, []string{subUser, role, "", "", "", permissionId})
 		}
+	}
 
-		for _, subRole := range roleObj.Roles {
-			if domainExist {
-				for _, domain := range permission.Domains {
-					groupingPolicies = append(groupingPolicies, []string{subRole, domain, role, "", "", permissionId})
-				}
-			} else {
-				groupingPolicies = append(groupingPolicies, []string{subRole, role, "", "", "", permissionId})
+	for _, subRole := range roleObj.Roles {
+		if domainExist {
+			for _, domain := range permission.Domains {
+				groupingPolicies = append(groupingPolicies, []string{subRole, domain, role, "", "", permissionId})
 			}
+		} else {
+			groupingPolicies = append(groupingPolicies, []string{subRole, role, "", "", "", permissionId})
 		}
+
+		groupingPolicies = append(groupingPolicies, getRoleGroupingPolicies(permission, subRole, visited)...)
 	}
 
 	return groupingPolicies
@@ -159,6 +178,8 @@ func addPolicies(permission *Permission) {
 	if err != nil {
 		panic(err)
 	}
+
+	invalidateEnforcers(permission.GetId())
 }
 
 func addGroupingPolicies(permission *Permission) {
@@ -171,6 +192,8 @@ func addGroupingPolicies(permission *Permission) {
 			panic(err)
 		}
 	}
+
+	invalidateEnforcers(permission.GetId())
 }
 
 func removeGroupingPolicies(permission *Permission) {
@@ -183,6 +206,8 @@ func removeGroupingPolicies(permission *Permission) {
 			panic(err)
 		}
 	}
+
+	invalidateEnforcers(permission.GetId())
 }
 
 func removePolicies(permission *Permission) {
@@ -193,15 +218,17 @@ func removePolicies(permission *Permission) {
 	if err != nil {
 		panic(err)
 	}
+
+	invalidateEnforcers(permission.GetId())
 }
 
 func Enforce(permissionRule *PermissionRule) bool {
 	permission := GetPermission(permissionRule.Id)
-	enforcer := getEnforcer(permission)
+	enforcer := getPooledEnforcer(permission)
 
 	request, _ := permissionRule.GetRequest(builtInAdapter, permissionRule.Id)
 
-	allow, err := enforcer.Enforce(request...)
+	allow, _, err := enforcer.enforce(request)
 	if err != nil {
 		panic(err)
 	}
@@ -209,29 +236,84 @@ func Enforce(permissionRule *PermissionRule) bool {
 }
 
 func BatchEnforce(permissionRules []PermissionRule) []bool {
-	var requests [][]interface{}
+	permission := GetPermission(permissionRules[0].Id)
+	enforcer := getPooledEnforcer(permission)
+
+	var allow []bool
 	for _, permissionRule := range permissionRules {
 		request, _ := permissionRule.GetRequest(builtInAdapter, permissionRule.Id)
-		requests = append(requests, request)
-	}
-	permission := GetPermission(permissionRules[0].Id)
-	enforcer := getEnforcer(permission)
-	allow, err := enforcer.BatchEnforce(requests)
-	if err != nil {
-		panic(err)
+		allowed, _, err := enforcer.enforce(request)
+		if err != nil {
+			panic(err)
+		}
+		allow = append(allow, allowed)
 	}
 	return allow
 }
 
+type EnforceResult struct {
+	Allowed    bool     `json:"allowed"`
+	Permission string   `json:"permission"`
+	Explain    []string `json:"explain"`
+	Error      string   `json:"error"`
+}
+
+// BatchEnforceEx enforces each rule with the permission of its ID and returns
+// the matched policy (without the permission ID) as the explanation. An
+// error of one rule doesn't fail the others.
+func BatchEnforceEx(permissionRules []PermissionRule) []*EnforceResult {
+	enforcers := map[string]*pooledEnforcer{}
+	results := []*EnforceResult{}
+	for _, permissionRule := range permissionRules {
+		result := &EnforceResult{Permission: permissionRule.Id, Explain: []string{}}
+		results = append(results, result)
+
+		enforcer, ok := enforcers[permissionRule.Id]
+		if !ok {
+			permission := GetPermission(permissionRule.Id)
+			if permission == nil {
+				result.Error = fmt.Sprintf("the permission: %s doesn't exist", permissionRule.Id)
+				continue
+			}
+
+			enforcer = getPooledEnforcer(permission)
+			enforcers[permissionRule.Id] = enforcer
+		}
+
+		request, err := permissionRule.GetRequest(builtInAdapter, permissionRule.Id)
+		if err != nil {
+			result.Error = err.Error()
+			continue
+		}
+
+		allowed, explain, err := enforcer.enforce(request)
+		if err != nil {
+			result.Error = err.Error()
+			continue
+		}
+
+		result.Allowed = allowed
+		if len(explain) > 0 {
+			// the last field is the permission ID
+			explain = explain[:len(explain)-1]
+			for len(explain) > 0 && explain[len(explain)-1] == "" {
+				explain = explain[:len(explain)-1]
+			}
+			result.Explain = explain
+		}
+	}
+	return results
+}
+
 func getAllValues(userId string, fn func(enforcer *casbin.Enforcer) []string) []string {
 	permissions := GetPermissionsByUser(userId)
-	for _, role := range GetAllRoles(userId) {
-		permissions = append(permissions, GetPermissionsByRole(role)...)
+	for _, role := range GetAllRolesByUser(userId) {
+		permissions = append(permissions, GetPermissionsByRole(role.GetId())...)
 	}
 
 	var values []string
 	for _, permission := range permissions {
-		enforcer := getEnforcer(permission)
+		enforcer := getPooledEnforcer(permission).enforcer
 		values = append(values, fn(enforcer)...)
 	}
 	return values
@@ -250,7 +332,7 @@ func GetAllActions(userId string) []string {
 }
 
 func GetAllRoles(userId string) []string {
-	roles := GetRolesByUser(userId)
+	roles := GetAllRolesByUser(userId)
 	var res []string
 	for _, role := range roles {
 		res = append(res, role.Name)
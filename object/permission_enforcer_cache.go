@@ -0,0 +1,291 @@
+// Copyright 2023 The Casdoor Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package object
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/beego/beego/logs"
+	"github.com/casbin/casbin/v2"
+	"github.com/casdoor/casdoor/conf"
+	"github.com/gomodule/redigo/redis"
+)
+
+const (
+	enforcerWatcherChannel = "casdoor_enforcer"
+
+	// enforcerMaxAge bounds how stale a pooled enforcer can get when the
+	// policies are changed by another node without the watcher
+	enforcerMaxAge = 10 * time.Minute
+)
+
+type pooledEnforcer struct {
+	enforcer    *casbin.Enforcer
+	createdTime time.Time
+	// decisions of models depending on the current time aren't cached
+	cacheDecisions bool
+	decisions      sync.Map
+}
+
+type cachedDecision struct {
+	allowed    bool
+	explain    []string
+	expireTime time.Time
+}
+
+type EnforcerMetrics struct {
+	Enforcers      int     `json:"enforcers"`
+	EnforcerHits   int64   `json:"enforcerHits"`
+	EnforcerMisses int64   `json:"enforcerMisses"`
+	DecisionHits   int64   `json:"decisionHits"`
+	DecisionMisses int64   `json:"decisionMisses"`
+	HitRate        float64 `json:"hitRate"`
+	Invalidations  int64   `json:"invalidations"`
+	AverageLatency float64 `json:"averageLatency"`
+	MaxLatency     float64 `json:"maxLatency"`
+	WatcherEnabled bool    `json:"watcherEnabled"`
+	DecisionTtl    int64   `json:"decisionTtl"`
+	totalLatency   time.Duration
+	maxLatency     time.Duration
+	decisionsTimed int64
+}
+
+var enforcerPool = struct {
+	sync.Mutex
+	enforcers map[string]*pooledEnforcer
+	// generation changes on every invalidation, so that an enforcer loaded
+	// while its policies changed isn't put back into the pool
+	generation   int64
+	metrics      EnforcerMetrics
+	totalLatency time.Duration
+	maxLatency   time.Duration
+}{enforcers: map[string]*pooledEnforcer{}}
+
+var enforcerWatcherPool *redis.Pool
+
+// getEnforcerDecisionTtl returns how long a decision is cached, 0 disables
+// the decision cache while the enforcers are still pooled.
+func getEnforcerDecisionTtl() time.Duration {
+	ttl, err := conf.GetConfigInt64("enforcerDecisionCacheTtl")
+	if err != nil {
+		ttl = 60
+	}
+	return time.Duration(ttl) * time.Second
+}
+
+// getPooledEnforcer returns the enforcer of the permission from the pool,
+// loading its policies on first use.
+func getPooledEnforcer(permission *Permission) *pooledEnforcer {
+	permissionId := permission.GetId()
+
+	enforcerPool.Lock()
+	pooled, ok := enforcerPool.enforcers[permissionId]
+	if ok && time.Since(pooled.createdTime) < enforcerMaxAge {
+		enforcerPool.metrics.EnforcerHits++
+		enforcerPool.Unlock()
+		return pooled
+	}
+	enforcerPool.metrics.EnforcerMisses++
+	generation := enforcerPool.generation
+	enforcerPool.Unlock()
+
+	enforcer := getEnforcer(permission)
+	pooled = &pooledEnforcer{enforcer: enforcer, createdTime: time.Now(), cacheDecisions: true}
+	if matcher, ok := enforcer.GetModel()["m"]["m"]; ok && strings.Contains(matcher.Value, "timeInRange(") {
+		pooled.cacheDecisions = false
+	}
+
+	enforcerPool.Lock()
+	if enforcerPool.generation == generation {
+		enforcerPool.enforcers[permissionId] = pooled
+	}
+	enforcerPool.Unlock()
+	return pooled
+}
+
+func getDecisionKey(request []interface{}) string {
+	values := []string{}
+	for _, value := range request {
+		values = append(values, fmt.Sprintf("%v", value))
+	}
+	return strings.Join(values, "\x00")
+}
+
+// enforce makes the decision with the cached one if it hasn't expired.
+func (pooled *pooledEnforcer) enforce(request []interface{}) (bool, []string, error) {
+	ttl := getEnforcerDecisionTtl()
+	cacheDecisions := pooled.cacheDecisions && ttl > 0
+
+	key := ""
+	if cacheDecisions {
+		key = getDecisionKey(request)
+		if value, ok := pooled.decisions.Load(key); ok {
+			decision := value.(*cachedDecision)
+			if time.Now().Before(decision.expireTime) {
+				enforcerPool.Lock()
+				enforcerPool.metrics.DecisionHits++
+				enforcerPool.Unlock()
+				return decision.allowed, decision.explain, nil
+			}
+			pooled.decisions.Delete(key)
+		}
+	}
+
+	startTime := time.Now()
+	allowed, explain, err := pooled.enforcer.EnforceEx(request...)
+	latency := time.Since(startTime)
+
+	enforcerPool.Lock()
+	enforcerPool.metrics.DecisionMisses++
+	enforcerPool.totalLatency += latency
+	if latency > enforcerPool.maxLatency {
+		enforcerPool.maxLatency = latency
+	}
+	enforcerPool.Unlock()
+
+	if err != nil {
+		return false, nil, err
+	}
+
+	if cacheDecisions {
+		pooled.decisions.Store(key, &cachedDecision{allowed: allowed, explain: explain, expireTime: time.Now().Add(ttl)})
+	}
+	return allowed, explain, nil
+}
+
+func invalidateLocalEnforcers(permissionIds []string) {
+	enforcerPool.Lock()
+	defer enforcerPool.Unlock()
+
+	enforcerPool.generation++
+	enforcerPool.metrics.Invalidations++
+	if len(permissionIds) == 0 {
+		enforcerPool.enforcers = map[string]*pooledEnforcer{}
+		return
+	}
+	for _, permissionId := range permissionIds {
+		delete(enforcerPool.enforcers, permissionId)
+	}
+}
+
+// invalidateEnforcers drops the pooled enforcers of the permissions after
+// their policies changed, no IDs means all of them. The other nodes are
+// told through the watcher when it's enabled.
+func invalidateEnforcers(permissionIds ...string) {
+	invalidateLocalEnforcers(permissionIds)
+	publishEnforcerInvalidation(permissionIds)
+}
+
+type enforcerInvalidation struct {
+	Node          string   `json:"node"`
+	PermissionIds []string `json:"permissionIds"`
+}
+
+func publishEnforcerInvalidation(permissionIds []string) {
+	if enforcerWatcherPool == nil {
+		return
+	}
+
+	data, err := json.Marshal(&enforcerInvalidation{Node: GetNodeName(), PermissionIds: permissionIds})
+	if err != nil {
+		panic(err)
+	}
+
+	conn := enforcerWatcherPool.Get()
+	defer conn.Close()
+	_, err = conn.Do("PUBLISH", enforcerWatcherChannel, data)
+	if err != nil {
+		logs.Warning(fmt.Sprintf("failed to publish the enforcer invalidation, %s", err.Error()))
+	}
+}
+
+// StartEnforcerWatcher subscribes to the invalidations of the other nodes
+// through Redis when enableEnforcerWatcher is set, so that a policy change
+// isn't enforced with stale enforcers elsewhere.
+func StartEnforcerWatcher() {
+	enabled, _ := conf.GetConfigBool("enableEnforcerWatcher")
+	if !enabled || getRedisClient() == nil {
+		return
+	}
+
+	enforcerWatcherPool = &redis.Pool{
+		MaxIdle:     3,
+		IdleTimeout: 240 * time.Second,
+		Dial: func() (redis.Conn, error) {
+			return dialRedis(3 * time.Second)
+		},
+	}
+
+	for {
+		err := watchEnforcerInvalidations()
+		logs.Warning(fmt.Sprintf("the enforcer watcher stopped, %s", err.Error()))
+
+		// the invalidations while disconnected are lost
+		invalidateLocalEnforcers(nil)
+		time.Sleep(5 * time.Second)
+	}
+}
+
+func watchEnforcerInvalidations() error {
+	conn, err := dialRedis(0)
+	if err != nil {
+		return err
+	}
+
+	psc := redis.PubSubConn{Conn: conn}
+	defer psc.Close()
+
+	err = psc.Subscribe(enforcerWatcherChannel)
+	if err != nil {
+		return err
+	}
+
+	for {
+		switch v := psc.Receive().(type) {
+		case redis.Message:
+			invalidation := &enforcerInvalidation{}
+			err = json.Unmarshal(v.Data, invalidation)
+			if err != nil || invalidation.Node == GetNodeName() {
+				continue
+			}
+			invalidateLocalEnforcers(invalidation.PermissionIds)
+		case error:
+			return v
+		}
+	}
+}
+
+func GetEnforcerMetrics() *EnforcerMetrics {
+	enforcerPool.Lock()
+	defer enforcerPool.Unlock()
+
+	metrics := enforcerPool.metrics
+	metrics.Enforcers = len(enforcerPool.enforcers)
+	if total := metrics.DecisionHits + metrics.DecisionMisses; total > 0 {
+		metrics.HitRate = float64(metrics.DecisionHits) / float64(total)
+	}
+	// latencies are in milliseconds, cached decisions aren't timed
+	if metrics.DecisionMisses > 0 {
+		metrics.AverageLatency = float64(enforcerPool.totalLatency.Microseconds()) / float64(metrics.DecisionMisses) / 1000
+	}
+	metrics.MaxLatency = float64(enforcerPool.maxLatency.Microseconds()) / 1000
+	metrics.WatcherEnabled = enforcerWatcherPool != nil
+	metrics.DecisionTtl = int64(getEnforcerDecisionTtl() / time.Second)
+	return &metrics
+}
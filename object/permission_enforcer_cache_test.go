@@ -0,0 +1,94 @@
+// Copyright 2023 The Casdoor Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package object
+
+import (
+	"testing"
+	"time"
+
+	"github.com/casbin/casbin/v2"
+	"github.com/casbin/casbin/v2/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestPooledEnforcer(t *testing.T) *pooledEnforcer {
+	m, err := model.NewModelFromString(`
+[request_definition]
+r = sub, obj, act
+
+[policy_definition]
+p = sub, obj, act
+
+[policy_effect]
+e = some(where (p.eft == allow))
+
+[matchers]
+m = r.sub == p.sub && r.obj == p.obj && r.act == p.act`)
+	assert.Nil(t, err)
+
+	enforcer, err := casbin.NewEnforcer(m)
+	assert.Nil(t, err)
+	_, err = enforcer.AddPolicy("alice", "data1", "read")
+	assert.Nil(t, err)
+
+	return &pooledEnforcer{enforcer: enforcer, createdTime: time.Now(), cacheDecisions: true}
+}
+
+func TestPooledEnforcerDecisionCache(t *testing.T) {
+	pooled := newTestPooledEnforcer(t)
+	before := GetEnforcerMetrics()
+
+	allowed, explain, err := pooled.enforce([]interface{}{"alice", "data1", "read"})
+	assert.Nil(t, err)
+	assert.True(t, allowed)
+	assert.Equal(t, []string{"alice", "data1", "read"}, explain)
+
+	allowed, _, err = pooled.enforce([]interface{}{"alice", "data1", "read"})
+	assert.Nil(t, err)
+	assert.True(t, allowed)
+
+	allowed, _, err = pooled.enforce([]interface{}{"alice", "data1", "write"})
+	assert.Nil(t, err)
+	assert.False(t, allowed)
+
+	after := GetEnforcerMetrics()
+	assert.Equal(t, before.DecisionHits+1, after.DecisionHits)
+	assert.Equal(t, before.DecisionMisses+2, after.DecisionMisses)
+
+	pooled.cacheDecisions = false
+	_, _, err = pooled.enforce([]interface{}{"alice", "data1", "read"})
+	assert.Nil(t, err)
+	assert.Equal(t, after.DecisionHits, GetEnforcerMetrics().DecisionHits)
+}
+
+func TestInvalidateLocalEnforcers(t *testing.T) {
+	enforcerPool.Lock()
+	enforcerPool.enforcers["org/p1"] = newTestPooledEnforcer(t)
+	enforcerPool.enforcers["org/p2"] = newTestPooledEnforcer(t)
+	generation := enforcerPool.generation
+	enforcerPool.Unlock()
+
+	invalidateLocalEnforcers([]string{"org/p1"})
+	enforcerPool.Lock()
+	_, ok1 := enforcerPool.enforcers["org/p1"]
+	_, ok2 := enforcerPool.enforcers["org/p2"]
+	assert.False(t, ok1)
+	assert.True(t, ok2)
+	assert.Equal(t, generation+1, enforcerPool.generation)
+	enforcerPool.Unlock()
+
+	invalidateLocalEnforcers(nil)
+	assert.Equal(t, 0, GetEnforcerMetrics().Enforcers)
+}
@@ -0,0 +1,149 @@
+// Copyright 2023 The Casdoor Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package object
+
+import (
+	"fmt"
+
+	"github.com/casdoor/casdoor/util"
+	"github.com/xorm-io/core"
+)
+
+const (
+	PlanPeriodMonthly = "Monthly"
+	PlanPeriodYearly  = "Yearly"
+)
+
+// Plan is a recurring product billed by a payment provider that supports
+// subscriptions (Stripe or PayPal), the subscribers get the role of the plan.
+type Plan struct {
+	Owner       string `xorm:"varchar(100) notnull pk" json:"owner"`
+	Name        string `xorm:"varchar(100) notnull pk" json:"name"`
+	CreatedTime string `xorm:"varchar(100)" json:"createdTime"`
+	DisplayName string `xorm:"varchar(100)" json:"displayName"`
+
+	Description    string  `xorm:"varchar(100)" json:"description"`
+	Currency       string  `xorm:"varchar(100)" json:"currency"`
+	Price          float64 `json:"price"`
+	Period         string  `xorm:"varchar(100)" json:"period"`
+	Role           string  `xorm:"varchar(100)" json:"role"`
+	Provider       string  `xorm:"varchar(100)" json:"provider"`
+	ProviderPlanId string  `xorm:"varchar(100)" json:"providerPlanId"`
+	IsEnabled      bool    `json:"isEnabled"`
+
+	// MaxUsers and MaxApplications make the plan seat-based, they limit the
+	// organization of the subscription, zero is unlimited
+	MaxUsers        int `json:"maxUsers"`
+	MaxApplications int `json:"maxApplications"`
+}
+
+func GetPlanCount(owner, field, value string) int {
+	session := GetSession(owner, -1, -1, field, value, "", "")
+	count, err := session.Count(&Plan{})
+	if err != nil {
+		panic(err)
+	}
+
+	return int(count)
+}
+
+func GetPlans(owner string) []*Plan {
+	plans := []*Plan{}
+	err := adapter.Engine.Desc("created_time").Find(&plans, &Plan{Owner: owner})
+	if err != nil {
+		panic(err)
+	}
+
+	return plans
+}
+
+func GetPaginationPlans(owner string, offset, limit int, field, value, sortField, sortOrder string) []*Plan {
+	plans := []*Plan{}
+	session := GetSession(owner, offset, limit, field, value, sortField, sortOrder)
+	err := session.Find(&plans)
+	if err != nil {
+		panic(err)
+	}
+
+	return plans
+}
+
+func getPlan(owner string, name string) *Plan {
+	if owner == "" || name == "" {
+		return nil
+	}
+
+	plan := Plan{Owner: owner, Name: name}
+	existed, err := adapter.Engine.Get(&plan)
+	if err != nil {
+		panic(err)
+	}
+
+	if existed {
+		return &plan
+	} else {
+		return nil
+	}
+}
+
+func GetPlan(id string) *Plan {
+	owner, name := util.GetOwnerAndNameFromId(id)
+	return getPlan(owner, name)
+}
+
+func UpdatePlan(id string, plan *Plan) bool {
+	owner, name := util.GetOwnerAndNameFromId(id)
+	if getPlan(owner, name) == nil {
+		return false
+	}
+
+	affected, err := adapter.Engine.ID(core.PK{owner, name}).AllCols().Update(plan)
+	if err != nil {
+		panic(err)
+	}
+
+	return affected != 0
+}
+
+func AddPlan(plan *Plan) bool {
+	affected, err := adapter.Engine.Insert(plan)
+	if err != nil {
+		panic(err)
+	}
+
+	return affected != 0
+}
+
+func DeletePlan(plan *Plan) bool {
+	affected, err := adapter.Engine.ID(core.PK{plan.Owner, plan.Name}).Delete(&Plan{})
+	if err != nil {
+		panic(err)
+	}
+
+	return affected != 0
+}
+
+func (plan *Plan) GetId() string {
+	return fmt.Sprintf("%s/%s", plan.Owner, plan.Name)
+}
+
+func (plan *Plan) getProvider() (*Provider, error) {
+	provider := getProvider(plan.Owner, plan.Provider)
+	if provider == nil {
+		return nil, fmt.Errorf("the payment provider: %s does not exist", plan.Provider)
+	}
+
+	return provider, nil
+}
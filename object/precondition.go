@@ -0,0 +1,235 @@
+// Copyright 2023 The Casdoor Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package object
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/casdoor/casdoor/util"
+	"github.com/xorm-io/xorm"
+)
+
+var ErrPreconditionFailed = errors.New("precondition failed")
+
+// errConditionalRename is returned for a conditional write that renames, as
+// the references in the other tables are renamed before the write, which may
+// still fail the precondition.
+var errConditionalRename = errors.New("the name can't be changed by a write with If-Match or If-None-Match")
+
+// Precondition holds the HTTP conditional request headers of a write.
+type Precondition struct {
+	IfMatch     string
+	IfNoneMatch string
+}
+
+// GetETag returns a strong ETag for the stored state of obj. Fields filled in
+// from other tables when reading (like the organization of an application)
+// don't take part, so the ETag only changes when the object itself changes.
+func GetETag(obj interface{}) string {
+	if isNil(obj) {
+		return ""
+	}
+
+	if application, ok := obj.(*Application); ok {
+		applicationCopy := *application
+		applicationCopy.OrganizationObj = nil
+		applicationCopy.Providers = []*ProviderItem{}
+		for _, providerItem := range application.Providers {
+			providerItemCopy := *providerItem
+			providerItemCopy.Provider = nil
+			applicationCopy.Providers = append(applicationCopy.Providers, &providerItemCopy)
+		}
+		obj = &applicationCopy
+	}
+
+	data, err := json.Marshal(obj)
+	if err != nil {
+		panic(err)
+	}
+
+	hash := sha256.Sum256(data)
+	return fmt.Sprintf("\"%x\"", hash[:16])
+}
+
+func isNil(obj interface{}) bool {
+	if obj == nil {
+		return true
+	}
+
+	value := reflect.ValueOf(obj)
+	return value.Kind() == reflect.Ptr && value.IsNil()
+}
+
+func matchETag(header string, eTag string) bool {
+	for _, token := range strings.Split(header, ",") {
+		token = strings.TrimSpace(token)
+		if token == "*" || strings.TrimPrefix(token, "W/") == eTag {
+			return true
+		}
+	}
+	return false
+}
+
+// isSatisfiedBy follows RFC 7232: "If-Match" requires an existing object
+// with a matching ETag, "If-None-Match: *" requires that there is none.
+func (precondition *Precondition) isSatisfiedBy(current interface{}) bool {
+	if precondition == nil {
+		return true
+	}
+
+	if precondition.IfMatch != "" {
+		if isNil(current) || !matchETag(precondition.IfMatch, GetETag(current)) {
+			return false
+		}
+	}
+
+	if precondition.IfNoneMatch != "" {
+		if !isNil(current) && matchETag(precondition.IfNoneMatch, GetETag(current)) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// isConditional returns whether the write has If-Match or If-None-Match.
+func (precondition *Precondition) isConditional() bool {
+	return precondition != nil && (precondition.IfMatch != "" || precondition.IfNoneMatch != "")
+}
+
+// checkRename returns an error when a conditional write changes the name.
+func (precondition *Precondition) checkRename(oldName string, newName string) error {
+	if precondition.isConditional() && oldName != newName {
+		return errConditionalRename
+	}
+	return nil
+}
+
+// updateWithVersion runs the update of session with bean and increments the
+// version in the same UPDATE. A conditional write also requires the version
+// of the object that its precondition was checked against, so that a write
+// made in between by any node fails the precondition instead of being lost.
+func updateWithVersion(session *xorm.Session, bean interface{}, version int, precondition *Precondition) (bool, error) {
+	session = session.Omit("version").Incr("version")
+	if precondition.isConditional() {
+		session = session.And("version = ?", version)
+	}
+
+	affected, err := session.Update(bean)
+	if err != nil {
+		panic(err)
+	}
+
+	if affected == 0 && precondition.isConditional() {
+		return false, ErrPreconditionFailed
+	}
+	return affected != 0, nil
+}
+
+// UpsertOrganization creates or fully replaces the organization with the id,
+// returning whether it was created. The owner and name in the body must
+// match the id, so an upsert never renames. Of two creations at the same
+// time, the database refuses the second one.
+func UpsertOrganization(id string, organization *Organization, precondition *Precondition) (bool, error) {
+	oldOrganization := GetOrganization(id)
+	if oldOrganization == nil {
+		if !precondition.isSatisfiedBy(nil) {
+			return false, ErrPreconditionFailed
+		}
+
+		if organization.CreatedTime == "" {
+			organization.CreatedTime = util.GetCurrentTime()
+		}
+		AddOrganization(organization)
+		return true, nil
+	}
+
+	if organization.CreatedTime == "" {
+		organization.CreatedTime = oldOrganization.CreatedTime
+	}
+	_, err := UpdateOrganizationWithPrecondition(id, organization, precondition)
+	return false, err
+}
+
+// UpsertApplication creates or fully replaces the application with the id.
+func UpsertApplication(id string, application *Application, precondition *Precondition) (bool, error) {
+	oldApplication := GetApplication(id)
+	if oldApplication == nil {
+		if !precondition.isSatisfiedBy(nil) {
+			return false, ErrPreconditionFailed
+		}
+
+		if application.CreatedTime == "" {
+			application.CreatedTime = util.GetCurrentTime()
+		}
+		AddApplication(application)
+		return true, nil
+	}
+
+	if application.CreatedTime == "" {
+		application.CreatedTime = oldApplication.CreatedTime
+	}
+	_, err := UpdateApplicationWithPrecondition(id, application, precondition)
+	return false, err
+}
+
+// UpsertProvider creates or fully replaces the provider with the id.
+func UpsertProvider(id string, provider *Provider, precondition *Precondition) (bool, error) {
+	oldProvider := GetProvider(id)
+	if oldProvider == nil {
+		if !precondition.isSatisfiedBy(nil) {
+			return false, ErrPreconditionFailed
+		}
+
+		if provider.CreatedTime == "" {
+			provider.CreatedTime = util.GetCurrentTime()
+		}
+		AddProvider(provider)
+		return true, nil
+	}
+
+	if provider.CreatedTime == "" {
+		provider.CreatedTime = oldProvider.CreatedTime
+	}
+	_, err := UpdateProviderWithPrecondition(id, provider, precondition)
+	return false, err
+}
+
+// UpsertCert creates or fully replaces the cert with the id.
+func UpsertCert(id string, cert *Cert, precondition *Precondition) (bool, error) {
+	oldCert := GetCert(id)
+	if oldCert == nil {
+		if !precondition.isSatisfiedBy(nil) {
+			return false, ErrPreconditionFailed
+		}
+
+		if cert.CreatedTime == "" {
+			cert.CreatedTime = util.GetCurrentTime()
+		}
+		AddCert(cert)
+		return true, nil
+	}
+
+	if cert.CreatedTime == "" {
+		cert.CreatedTime = oldCert.CreatedTime
+	}
+	_, err := UpdateCertWithPrecondition(id, cert, precondition)
+	return false, err
+}
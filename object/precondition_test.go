@@ -0,0 +1,78 @@
+// Copyright 2023 The Casdoor Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package object
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/xorm-io/core"
+	"github.com/xorm-io/xorm"
+)
+
+func TestUpdateWithVersion(t *testing.T) {
+	engine, err := xorm.NewEngine("sqlite", "file:"+filepath.Join(t.TempDir(), "precondition.db"))
+	assert.Nil(t, err)
+	defer engine.Close()
+	assert.Nil(t, engine.Sync2(new(Cert)))
+
+	_, err = engine.Insert(&Cert{Owner: "admin", Name: "cert", DisplayName: "a"})
+	assert.Nil(t, err)
+	getCert := func() *Cert {
+		cert := &Cert{Owner: "admin", Name: "cert"}
+		_, err := engine.Get(cert)
+		assert.Nil(t, err)
+		return cert
+	}
+	update := func(displayName string, version int, precondition *Precondition) (bool, error) {
+		cert := getCert()
+		cert.DisplayName = displayName
+		// the version of the body is ignored
+		cert.Version = 100
+		return updateWithVersion(engine.ID(core.PK{"admin", "cert"}).AllCols(), cert, version, precondition)
+	}
+
+	affected, err := update("b", 5, nil)
+	assert.True(t, affected)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, getCert().Version)
+
+	affected, err = update("c", 1, &Precondition{})
+	assert.True(t, affected)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, getCert().Version)
+
+	// written by another node since the precondition was checked
+	precondition := &Precondition{IfMatch: "*"}
+	affected, err = update("d", 1, precondition)
+	assert.False(t, affected)
+	assert.Equal(t, ErrPreconditionFailed, err)
+	assert.Equal(t, "c", getCert().DisplayName)
+
+	affected, err = update("d", 2, precondition)
+	assert.True(t, affected)
+	assert.Nil(t, err)
+	assert.Equal(t, "d", getCert().DisplayName)
+	assert.Equal(t, 3, getCert().Version)
+}
+
+func TestCheckRename(t *testing.T) {
+	var precondition *Precondition
+	assert.Nil(t, precondition.checkRename("a", "b"))
+	assert.Nil(t, (&Precondition{}).checkRename("a", "b"))
+	assert.Nil(t, (&Precondition{IfMatch: "*"}).checkRename("a", "a"))
+	assert.Equal(t, errConditionalRename, (&Precondition{IfMatch: "*"}).checkRename("a", "b"))
+}
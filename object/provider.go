@@ -18,6 +18,7 @@ import (
 	"fmt"
 
 	"github.com/casdoor/casdoor/i18n"
+	"github.com/casdoor/casdoor/idv"
 	"github.com/casdoor/casdoor/pp"
 	"github.com/casdoor/casdoor/util"
 	"github.com/xorm-io/core"
@@ -27,6 +28,7 @@ type Provider struct {
 	Owner       string `xorm:"varchar(100) notnull pk" json:"owner"`
 	Name        string `xorm:"varchar(100) notnull pk unique" json:"name"`
 	CreatedTime string `xorm:"varchar(100)" json:"createdTime"`
+	Version     int    `xorm:"int notnull default 0" json:"version"`
 
 	DisplayName       string `xorm:"varchar(100)" json:"displayName"`
 	Category          string `xorm:"varchar(100)" json:"category"`
@@ -36,7 +38,7 @@ type Provider struct {
 	ClientId          string `xorm:"varchar(100)" json:"clientId"`
 	ClientSecret      string `xorm:"varchar(2000)" json:"clientSecret"`
 	ClientId2         string `xorm:"varchar(100)" json:"clientId2"`
-	ClientSecret2     string `xorm:"varchar(100)" json:"clientSecret2"`
+	ClientSecret2     string `xorm:"varchar(1000)" json:"clientSecret2"`
 	Cert              string `xorm:"varchar(100)" json:"cert"`
 	CustomAuthUrl     string `xorm:"varchar(200)" json:"customAuthUrl"`
 	CustomScope       string `xorm:"varchar(200)" json:"customScope"`
@@ -44,12 +46,14 @@ type Provider struct {
 	CustomUserInfoUrl string `xorm:"varchar(200)" json:"customUserInfoUrl"`
 	CustomLogo        string `xorm:"varchar(200)" json:"customLogo"`
 
-	Host       string `xorm:"varchar(100)" json:"host"`
-	Port       int    `json:"port"`
-	DisableSsl bool   `json:"disableSsl"` // If the provider type is WeChat, DisableSsl means EnableQRCode
-	Title      string `xorm:"varchar(100)" json:"title"`
-	Content    string `xorm:"varchar(1000)" json:"content"` // If provider type is WeChat, Content means QRCode string by Base64 encoding
-	Receiver   string `xorm:"varchar(100)" json:"receiver"`
+	Host         string `xorm:"varchar(100)" json:"host"`
+	Port         int    `json:"port"`
+	DisableSsl   bool   `json:"disableSsl"` // If the provider type is WeChat, DisableSsl means EnableQRCode
+	Title        string `xorm:"varchar(100)" json:"title"`
+	Content      string `xorm:"varchar(1000)" json:"content"` // If provider type is WeChat, Content means QRCode string by Base64 encoding
+	Receiver     string `xorm:"varchar(100)" json:"receiver"`
+	FromAddress  string `xorm:"varchar(100)" json:"fromAddress"` // The sender of the emails, the username of SMTP is used when empty
+	DkimSelector string `xorm:"varchar(100)" json:"dkimSelector"`
 
 	RegionId     string `xorm:"varchar(100)" json:"regionId"`
 	SignName     string `xorm:"varchar(100)" json:"signName"`
@@ -112,13 +116,22 @@ func GetGlobalProviderCount(field, value string) int {
 	return int(count)
 }
 
+// GetProviders returns the providers of the owner and the global ones, the
+// lists are only cached in this node as any change of a global provider
+// changes all of them.
 func GetProviders(owner string) []*Provider {
 	providers := []*Provider{}
+	key := getSharedCacheKey("providers", owner)
+	if getLocalCache(key, &providers) {
+		return providers
+	}
+
 	err := adapter.Engine.Where("owner = ? or owner = ? ", "admin", owner).Desc("created_time").Find(&providers, &Provider{})
 	if err != nil {
 		panic(err)
 	}
 
+	setLocalCache(key, providers)
 	return providers
 }
 
@@ -160,18 +173,34 @@ func getProvider(owner string, name string) *Provider {
 	}
 
 	provider := Provider{Name: name}
+	key := getSharedCacheKey("provider", name)
+	if getCachedObject(key, &provider) {
+		return &provider
+	}
+
 	existed, err := adapter.Engine.Get(&provider)
 	if err != nil {
 		panic(err)
 	}
 
 	if existed {
+		setCachedObject(key, &provider)
 		return &provider
 	} else {
 		return nil
 	}
 }
 
+// deleteProviderCaches deletes the cached provider with the names and all
+// the cached lists of providers.
+func deleteProviderCaches(names ...string) {
+	keys := []string{getSharedCacheKey("providers", "*")}
+	for _, name := range names {
+		keys = append(keys, getSharedCacheKey("provider", name))
+	}
+	deleteCachedObjects(keys...)
+}
+
 func GetProvider(id string) *Provider {
 	owner, name := util.GetOwnerAndNameFromId(id)
 	return getProvider(owner, name)
@@ -202,15 +231,30 @@ func GetWechatMiniProgramProvider(application *Application) *Provider {
 }
 
 func UpdateProvider(id string, provider *Provider) bool {
+	affected, _ := UpdateProviderWithPrecondition(id, provider, nil)
+	return affected
+}
+
+// UpdateProviderWithPrecondition updates the provider only if it satisfies
+// precondition, otherwise it returns ErrPreconditionFailed.
+func UpdateProviderWithPrecondition(id string, provider *Provider, precondition *Precondition) (bool, error) {
 	owner, name := util.GetOwnerAndNameFromId(id)
-	if getProvider(owner, name) == nil {
-		return false
+	oldProvider := getProvider(owner, name)
+	if !precondition.isSatisfiedBy(oldProvider) {
+		return false, ErrPreconditionFailed
+	}
+	if oldProvider == nil {
+		return false, nil
 	}
 
 	if name != provider.Name {
-		err := providerChangeTrigger(name, provider.Name)
+		err := precondition.checkRename(name, provider.Name)
+		if err != nil {
+			return false, err
+		}
+		err = providerChangeTrigger(name, provider.Name)
 		if err != nil {
-			return false
+			return false, nil
 		}
 	}
 
@@ -221,12 +265,13 @@ func UpdateProvider(id string, provider *Provider) bool {
 	if provider.ClientSecret2 == "***" {
 		session = session.Omit("client_secret2")
 	}
-	affected, err := session.Update(provider)
+	affected, err := updateWithVersion(session, provider, oldProvider.Version, precondition)
 	if err != nil {
-		panic(err)
+		return false, err
 	}
 
-	return affected != 0
+	deleteProviderCaches(name, provider.Name)
+	return affected, nil
 }
 
 func AddProvider(provider *Provider) bool {
@@ -235,6 +280,7 @@ func AddProvider(provider *Provider) bool {
 		panic(err)
 	}
 
+	deleteProviderCaches()
 	return affected != 0
 }
 
@@ -244,6 +290,7 @@ func DeleteProvider(provider *Provider) bool {
 		panic(err)
 	}
 
+	deleteProviderCaches(provider.Name)
 	return affected != 0
 }
 
@@ -264,6 +311,24 @@ func (p *Provider) getPaymentProvider() (pp.PaymentProvider, *Cert, error) {
 	return pProvider, cert, nil
 }
 
+func (p *Provider) getSubscriptionProvider() (pp.SubscriptionProvider, error) {
+	pProvider := pp.GetSubscriptionProvider(p.Type, p.ClientId, p.ClientSecret, p.ClientSecret2, p.Host)
+	if pProvider == nil {
+		return nil, fmt.Errorf("the payment provider type: %s doesn't support subscriptions", p.Type)
+	}
+
+	return pProvider, nil
+}
+
+func (p *Provider) getIdvProvider() (idv.IdvProvider, error) {
+	idvProvider := idv.GetIdvProvider(p.Type, p.ClientId, p.ClientSecret, p.Endpoint)
+	if idvProvider == nil {
+		return nil, fmt.Errorf("the identity verification provider type: %s is not supported", p.Type)
+	}
+
+	return idvProvider, nil
+}
+
 func (p *Provider) GetId() string {
 	return fmt.Sprintf("%s/%s", p.Owner, p.Name)
 }
@@ -324,10 +389,11 @@ func providerChangeTrigger(oldName string, newName string) error {
 			}
 		}
 		applications[i].Providers = providers
-		_, err = session.Where("name=?", applications[i].Name).Update(applications[i])
+		_, err = session.Where("name=?", applications[i].Name).Omit("version").Incr("version").Update(applications[i])
 		if err != nil {
 			return err
 		}
+		deleteCachedObjects(getSharedCacheKey("application", applications[i].GetId()))
 	}
 
 	resource := new(Resource)
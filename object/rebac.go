@@ -0,0 +1,292 @@
+// Copyright 2023 The Casdoor Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package object
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/casdoor/casdoor/util"
+	"github.com/xorm-io/core"
+)
+
+// rebacMaxDepth bounds the nested usersets followed by a check.
+const rebacMaxDepth = 25
+
+// RebacNamespace defines the relations of the objects of a type, e.g. "doc".
+// A relation also holds for the subjects of the relations it inherits: an
+// entry "editor" means every editor of the object, "parent->viewer" means
+// every viewer of the objects that are the parent of the object.
+type RebacNamespace struct {
+	Name      string           `json:"name"`
+	Relations []*RebacRelation `json:"relations"`
+}
+
+type RebacRelation struct {
+	Name     string   `json:"name"`
+	Inherits []string `json:"inherits"`
+}
+
+// RelationTuple states that the subject has the relation to the object, in
+// Zanzibar notation "doc:123#editor@user:alice". The subject is either an
+// object like "user:alice" or a userset like "group:eng#member".
+type RelationTuple struct {
+	Owner       string `xorm:"varchar(100) notnull pk" json:"owner"`
+	Name        string `xorm:"varchar(100) notnull pk" json:"name"`
+	CreatedTime string `xorm:"varchar(100)" json:"createdTime"`
+
+	Application string `xorm:"varchar(100) index" json:"application"`
+	Object      string `xorm:"varchar(200) index" json:"object"`
+	Relation    string `xorm:"varchar(100)" json:"relation"`
+	Subject     string `xorm:"varchar(300) index" json:"subject"`
+}
+
+type RelationCheck struct {
+	Owner       string `json:"owner"`
+	Application string `json:"application"`
+	Object      string `json:"object"`
+	Relation    string `json:"relation"`
+	Subject     string `json:"subject"`
+}
+
+func (tuple *RelationTuple) String() string {
+	return fmt.Sprintf("%s#%s@%s", tuple.Object, tuple.Relation, tuple.Subject)
+}
+
+// parseRebacObject splits "doc:123" into the namespace and the ID.
+func parseRebacObject(object string) (string, string, bool) {
+	tokens := strings.SplitN(object, ":", 2)
+	if len(tokens) != 2 || tokens[0] == "" || tokens[1] == "" || strings.Contains(object, "#") {
+		return "", "", false
+	}
+	return tokens[0], tokens[1], true
+}
+
+// parseRebacSubject splits "group:eng#member" into the object and the
+// relation, the relation of a plain subject like "user:alice" is empty.
+func parseRebacSubject(subject string) (string, string, bool) {
+	object, relation := subject, ""
+	if i := strings.Index(subject, "#"); i != -1 {
+		object, relation = subject[:i], subject[i+1:]
+		if relation == "" {
+			return "", "", false
+		}
+	}
+
+	_, _, ok := parseRebacObject(object)
+	return object, relation, ok
+}
+
+func getRebacRelation(namespaces []*RebacNamespace, namespace string, relation string) *RebacRelation {
+	for _, ns := range namespaces {
+		if ns.Name != namespace {
+			continue
+		}
+		for _, r := range ns.Relations {
+			if r.Name == relation {
+				return r
+			}
+		}
+	}
+	return nil
+}
+
+// checkRelationTuple verifies that the relations of the tuple are defined in
+// the namespaces of the application.
+func checkRelationTuple(namespaces []*RebacNamespace, tuple *RelationTuple) error {
+	namespace, _, ok := parseRebacObject(tuple.Object)
+	if !ok {
+		return fmt.Errorf("the object: %s must be like \"namespace:id\"", tuple.Object)
+	}
+	if getRebacRelation(namespaces, namespace, tuple.Relation) == nil {
+		return fmt.Errorf("the relation: %s isn't defined for namespace: %s", tuple.Relation, namespace)
+	}
+
+	subject, subjectRelation, ok := parseRebacSubject(tuple.Subject)
+	if !ok {
+		return fmt.Errorf("the subject: %s must be like \"namespace:id\" or \"namespace:id#relation\"", tuple.Subject)
+	}
+	if subjectRelation != "" {
+		subjectNamespace, _, _ := parseRebacObject(subject)
+		if getRebacRelation(namespaces, subjectNamespace, subjectRelation) == nil {
+			return fmt.Errorf("the relation: %s isn't defined for namespace: %s", subjectRelation, subjectNamespace)
+		}
+	}
+	return nil
+}
+
+func getRebacApplication(owner string, application string) (*Application, error) {
+	app := getApplication("admin", application)
+	if app == nil || app.Organization != owner {
+		return nil, fmt.Errorf("the application: %s doesn't exist in organization: %s", application, owner)
+	}
+	return app, nil
+}
+
+func GetRelationTuples(owner string, application string, object string, relation string, subject string) []*RelationTuple {
+	tuples := []*RelationTuple{}
+	err := adapter.Engine.Desc("created_time").Find(&tuples, &RelationTuple{Owner: owner, Application: application, Object: object, Relation: relation, Subject: subject})
+	if err != nil {
+		panic(err)
+	}
+
+	return tuples
+}
+
+func getRelationTuple(owner string, application string, object string, relation string, subject string) *RelationTuple {
+	tuple := RelationTuple{Owner: owner, Application: application, Object: object, Relation: relation, Subject: subject}
+	existed, err := adapter.Engine.Get(&tuple)
+	if err != nil {
+		panic(err)
+	}
+
+	if existed {
+		return &tuple
+	} else {
+		return nil
+	}
+}
+
+// AddRelationTuple writes the tuple unless it already exists, which isn't
+// an error so that writes can be retried.
+func AddRelationTuple(tuple *RelationTuple) (bool, error) {
+	app, err := getRebacApplication(tuple.Owner, tuple.Application)
+	if err != nil {
+		return false, err
+	}
+
+	err = checkRelationTuple(app.RebacNamespaces, tuple)
+	if err != nil {
+		return false, err
+	}
+
+	if getRelationTuple(tuple.Owner, tuple.Application, tuple.Object, tuple.Relation, tuple.Subject) != nil {
+		return false, nil
+	}
+
+	tuple.Name = util.GenerateId()
+	tuple.CreatedTime = util.GetCurrentTime()
+	affected, err := adapter.Engine.Insert(tuple)
+	if err != nil {
+		panic(err)
+	}
+
+	return affected != 0, nil
+}
+
+func DeleteRelationTuple(tuple *RelationTuple) bool {
+	existing := getRelationTuple(tuple.Owner, tuple.Application, tuple.Object, tuple.Relation, tuple.Subject)
+	if existing == nil {
+		return false
+	}
+
+	affected, err := adapter.Engine.ID(core.PK{existing.Owner, existing.Name}).Delete(&RelationTuple{})
+	if err != nil {
+		panic(err)
+	}
+
+	return affected != 0
+}
+
+type rebacChecker struct {
+	namespaces []*RebacNamespace
+	getTuples  func(object string, relation string) []*RelationTuple
+	visited    map[string]bool
+}
+
+// check resolves the relation like Zanzibar: the direct tuples of the
+// object, the members of their usersets and the inherited relations.
+func (checker *rebacChecker) check(object string, relation string, subject string, depth int) (bool, error) {
+	if depth > rebacMaxDepth {
+		return false, fmt.Errorf("the check exceeded the maximum depth of %d", rebacMaxDepth)
+	}
+
+	key := fmt.Sprintf("%s#%s", object, relation)
+	if checker.visited[key] {
+		return false, nil
+	}
+	checker.visited[key] = true
+
+	namespace, _, ok := parseRebacObject(object)
+	if !ok {
+		return false, fmt.Errorf("the object: %s must be like \"namespace:id\"", object)
+	}
+	r := getRebacRelation(checker.namespaces, namespace, relation)
+	if r == nil {
+		return false, fmt.Errorf("the relation: %s isn't defined for namespace: %s", relation, namespace)
+	}
+
+	tuples := checker.getTuples(object, relation)
+	for _, tuple := range tuples {
+		if tuple.Subject == subject {
+			return true, nil
+		}
+	}
+
+	for _, tuple := range tuples {
+		subjectObject, subjectRelation, ok := parseRebacSubject(tuple.Subject)
+		if !ok || subjectRelation == "" {
+			continue
+		}
+
+		allowed, err := checker.check(subjectObject, subjectRelation, subject, depth+1)
+		if err != nil || allowed {
+			return allowed, err
+		}
+	}
+
+	for _, inherit := range r.Inherits {
+		tokens := strings.SplitN(inherit, "->", 2)
+		if len(tokens) == 1 {
+			allowed, err := checker.check(object, inherit, subject, depth+1)
+			if err != nil || allowed {
+				return allowed, err
+			}
+			continue
+		}
+
+		for _, tuple := range checker.getTuples(object, tokens[0]) {
+			parent, parentRelation, ok := parseRebacSubject(tuple.Subject)
+			if !ok || parentRelation != "" {
+				continue
+			}
+
+			allowed, err := checker.check(parent, tokens[1], subject, depth+1)
+			if err != nil || allowed {
+				return allowed, err
+			}
+		}
+	}
+
+	return false, nil
+}
+
+// CheckRelation returns whether the subject has the relation to the object
+// in the tuples of the application.
+func CheckRelation(relationCheck *RelationCheck) (bool, error) {
+	app, err := getRebacApplication(relationCheck.Owner, relationCheck.Application)
+	if err != nil {
+		return false, err
+	}
+
+	checker := &rebacChecker{
+		namespaces: app.RebacNamespaces,
+		getTuples: func(object string, relation string) []*RelationTuple {
+			return GetRelationTuples(relationCheck.Owner, relationCheck.Application, object, relation, "")
+		},
+		visited: map[string]bool{},
+	}
+	return checker.check(relationCheck.Object, relationCheck.Relation, relationCheck.Subject, 0)
+}
@@ -0,0 +1,98 @@
+// Copyright 2023 The Casdoor Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package object
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestRebacChecker(tuples []*RelationTuple) *rebacChecker {
+	namespaces := []*RebacNamespace{
+		{Name: "group", Relations: []*RebacRelation{{Name: "member"}}},
+		{Name: "folder", Relations: []*RebacRelation{{Name: "viewer"}}},
+		{Name: "doc", Relations: []*RebacRelation{
+			{Name: "parent"},
+			{Name: "owner"},
+			{Name: "editor", Inherits: []string{"owner"}},
+			{Name: "viewer", Inherits: []string{"editor", "parent->viewer"}},
+		}},
+	}
+
+	return &rebacChecker{
+		namespaces: namespaces,
+		getTuples: func(object string, relation string) []*RelationTuple {
+			res := []*RelationTuple{}
+			for _, tuple := range tuples {
+				if tuple.Object == object && tuple.Relation == relation {
+					res = append(res, tuple)
+				}
+			}
+			return res
+		},
+		visited: map[string]bool{},
+	}
+}
+
+func TestCheckRelation(t *testing.T) {
+	tuples := []*RelationTuple{
+		{Object: "doc:1", Relation: "owner", Subject: "user:alice"},
+		{Object: "doc:1", Relation: "editor", Subject: "group:eng#member"},
+		{Object: "group:eng", Relation: "member", Subject: "user:bob"},
+		{Object: "doc:1", Relation: "parent", Subject: "folder:x"},
+		{Object: "folder:x", Relation: "viewer", Subject: "user:carol"},
+		{Object: "group:a", Relation: "member", Subject: "group:b#member"},
+		{Object: "group:b", Relation: "member", Subject: "group:a#member"},
+	}
+
+	scenarios := []struct {
+		description string
+		object      string
+		relation    string
+		subject     string
+		allowed     bool
+		hasError    bool
+	}{
+		{"direct tuple", "doc:1", "owner", "user:alice", true, false},
+		{"inherited relation", "doc:1", "viewer", "user:alice", true, false},
+		{"userset", "doc:1", "editor", "user:bob", true, false},
+		{"userset through inherited relation", "doc:1", "viewer", "user:bob", true, false},
+		{"tuple to userset", "doc:1", "viewer", "user:carol", true, false},
+		{"not inherited downwards", "doc:1", "editor", "user:carol", false, false},
+		{"unknown subject", "doc:1", "viewer", "user:dave", false, false},
+		{"cycle of usersets", "group:a", "member", "user:dave", false, false},
+		{"undefined relation", "doc:1", "admin", "user:alice", false, true},
+		{"invalid object", "doc", "viewer", "user:alice", false, true},
+	}
+
+	for _, scenery := range scenarios {
+		t.Run(scenery.description, func(t *testing.T) {
+			checker := newTestRebacChecker(tuples)
+			allowed, err := checker.check(scenery.object, scenery.relation, scenery.subject, 0)
+			assert.Equal(t, scenery.allowed, allowed)
+			assert.Equal(t, scenery.hasError, err != nil)
+		})
+	}
+}
+
+func TestCheckRelationTuple(t *testing.T) {
+	namespaces := newTestRebacChecker(nil).namespaces
+
+	assert.Nil(t, checkRelationTuple(namespaces, &RelationTuple{Object: "doc:1", Relation: "editor", Subject: "group:eng#member"}))
+	assert.NotNil(t, checkRelationTuple(namespaces, &RelationTuple{Object: "doc:1", Relation: "admin", Subject: "user:alice"}))
+	assert.NotNil(t, checkRelationTuple(namespaces, &RelationTuple{Object: "doc:1", Relation: "editor", Subject: "group:eng#admin"}))
+	assert.NotNil(t, checkRelationTuple(namespaces, &RelationTuple{Object: "doc:1", Relation: "editor", Subject: "alice"}))
+}
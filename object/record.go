@@ -15,7 +15,6 @@
 package object
 
 import (
-	"fmt"
 	"strings"
 
 	"github.com/beego/beego/context"
@@ -23,16 +22,6 @@ import (
 	"github.com/casdoor/casdoor/util"
 )
 
-var logPostOnly bool
-
-func init() {
-	var err error
-	logPostOnly, err = conf.GetConfigBool("logPostOnly")
-	if err != nil {
-		// panic(err)
-	}
-}
-
 type Record struct {
 	Id int `xorm:"int notnull pk autoincr" json:"id"`
 
@@ -42,6 +31,8 @@ type Record struct {
 
 	Organization string `xorm:"varchar(100)" json:"organization"`
 	ClientIp     string `xorm:"varchar(100)" json:"clientIp"`
+	UserAgent    string `xorm:"varchar(500)" json:"userAgent"`
+	Location     string `xorm:"varchar(200)" json:"location"`
 	User         string `xorm:"varchar(100)" json:"user"`
 	Method       string `xorm:"varchar(100)" json:"method"`
 	RequestUri   string `xorm:"varchar(1000)" json:"requestUri"`
@@ -59,11 +50,17 @@ func NewRecord(ctx *context.Context) *Record {
 	if len(requestUri) > 1000 {
 		requestUri = requestUri[0:1000]
 	}
+	userAgent := ctx.Request.UserAgent()
+	if len(userAgent) > 500 {
+		userAgent = userAgent[0:500]
+	}
 
 	record := Record{
 		Name:        util.GenerateId(),
 		CreatedTime: util.GetCurrentTime(),
 		ClientIp:    ip,
+		UserAgent:   userAgent,
+		Location:    util.GetLocationFromRequest(ctx.Request, conf.GetConfigString("geoHeaders")).String(),
 		User:        "",
 		Method:      ctx.Request.Method,
 		RequestUri:  requestUri,
@@ -74,7 +71,8 @@ func NewRecord(ctx *context.Context) *Record {
 }
 
 func AddRecord(record *Record) bool {
-	if logPostOnly {
+	// read on each call, as logPostOnly can be changed at runtime
+	if logPostOnly, _ := conf.GetConfigBool("logPostOnly"); logPostOnly {
 		if record.Method == "GET" {
 			return false
 		}
@@ -86,18 +84,16 @@ func AddRecord(record *Record) bool {
 
 	record.Owner = record.Organization
 
-	errWebhook := SendWebhooks(record)
-	if errWebhook == nil {
-		record.IsTriggered = true
-	} else {
-		fmt.Println(errWebhook)
-	}
+	webhooks := getMatchedWebhooks(getWebhooksByOrganization(record.Organization), record.Action)
+	record.IsTriggered = len(webhooks) == 0
 
 	affected, err := adapter.Engine.Insert(record)
 	if err != nil {
 		panic(err)
 	}
 
+	EnqueueWebhooks(webhooks, record)
+
 	return affected != 0
 }
 
@@ -142,33 +138,28 @@ func GetRecordsByField(record *Record) []*Record {
 	return records
 }
 
-func SendWebhooks(record *Record) error {
-	webhooks := getWebhooksByOrganization(record.Organization)
+// getMatchedWebhooks returns the enabled webhooks subscribed to the action.
+func getMatchedWebhooks(webhooks []*Webhook, action string) []*Webhook {
+	res := []*Webhook{}
 	for _, webhook := range webhooks {
-		if !webhook.IsEnabled {
-			continue
+		if webhook.IsEnabled && util.ContainsString(webhook.Events, action) {
+			res = append(res, webhook)
 		}
+	}
+	return res
+}
 
-		matched := false
-		for _, event := range webhook.Events {
-			if record.Action == event {
-				matched = true
-				break
-			}
+// EnqueueWebhooks queues a call of each webhook for the record, the record
+// is marked as triggered once a call succeeded.
+func EnqueueWebhooks(webhooks []*Webhook, record *Record) {
+	for _, webhook := range webhooks {
+		payload := &webhookTaskPayload{Webhook: webhook.GetId(), Record: record}
+		if webhook.IsUserExtended {
+			copied := *record
+			copied.ExtendedUser = getUser(record.Organization, record.User)
+			payload.Record = &copied
 		}
 
-		if matched {
-			if webhook.IsUserExtended {
-				user := getUser(record.Organization, record.User)
-				record.ExtendedUser = user
-			}
-
-			err := sendWebhook(webhook, record)
-			if err != nil {
-				return err
-			}
-		}
+		EnqueueTask(record.Organization, TaskTypeWebhook, payload)
 	}
-
-	return nil
 }
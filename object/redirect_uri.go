@@ -0,0 +1,266 @@
+// Copyright 2023 The Casdoor Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package object
+
+import (
+	"net/url"
+	"regexp"
+	"strings"
+
+	"github.com/casdoor/casdoor/i18n"
+)
+
+const (
+	RedirectUriPolicyLegacy     = ""
+	RedirectUriPolicyExact      = "Exact"
+	RedirectUriPolicySubdomain  = "Subdomain wildcard"
+	RedirectUriPolicyPathPrefix = "Path prefix"
+)
+
+// The rejection reasons, from the least to the most specific so that the
+// reason of the closest allowed URI is reported.
+const (
+	redirectUriReasonNoAllowedUris = "no_allowed_uris"
+	redirectUriReasonInvalid       = "not_absolute"
+	redirectUriReasonFragment      = "fragment"
+	redirectUriReasonUserinfo      = "userinfo"
+	redirectUriReasonDotSegment    = "dot_segment"
+	redirectUriReasonHost          = "host_mismatch"
+	redirectUriReasonScheme        = "scheme_mismatch"
+	redirectUriReasonPort          = "port_mismatch"
+	redirectUriReasonPath          = "path_mismatch"
+	redirectUriReasonQuery         = "query_mismatch"
+)
+
+var redirectUriReasonOrder = []string{redirectUriReasonHost, redirectUriReasonScheme, redirectUriReasonPort, redirectUriReasonPath, redirectUriReasonQuery}
+
+func getRedirectUriReasonRank(reason string) int {
+	for i, r := range redirectUriReasonOrder {
+		if r == reason {
+			return i
+		}
+	}
+	return -1
+}
+
+func parseRedirectUri(uri string) (*url.URL, string) {
+	u, err := url.Parse(uri)
+	if err != nil || u.Scheme == "" || u.Host == "" {
+		return nil, redirectUriReasonInvalid
+	}
+	if u.Fragment != "" || strings.Contains(uri, "#") {
+		return nil, redirectUriReasonFragment
+	}
+	if u.User != nil {
+		return nil, redirectUriReasonUserinfo
+	}
+	for _, segment := range strings.Split(u.EscapedPath(), "/") {
+		if segment == "." || segment == ".." || strings.EqualFold(segment, "%2e") || strings.EqualFold(segment, "%2e%2e") {
+			return nil, redirectUriReasonDotSegment
+		}
+	}
+	return u, ""
+}
+
+// getRedirectUriPort returns the port with the default of the scheme, so that
+// "https://example.com" and "https://example.com:443" are the same.
+func getRedirectUriPort(u *url.URL) string {
+	if port := u.Port(); port != "" {
+		return port
+	}
+	switch strings.ToLower(u.Scheme) {
+	case "http":
+		return "80"
+	case "https":
+		return "443"
+	}
+	return ""
+}
+
+// matchRedirectUriHost supports "*." as the first label of the allowed host
+// in the subdomain wildcard mode, it matches exactly one label.
+func matchRedirectUriHost(policy string, allowedHost string, host string) bool {
+	allowedHost = strings.ToLower(allowedHost)
+	host = strings.ToLower(host)
+	if policy == RedirectUriPolicySubdomain && strings.HasPrefix(allowedHost, "*.") {
+		suffix := allowedHost[1:]
+		label := strings.TrimSuffix(host, suffix)
+		return strings.HasSuffix(host, suffix) && label != "" && !strings.Contains(label, ".")
+	}
+	return allowedHost == host
+}
+
+func getRedirectUriPath(u *url.URL) string {
+	if u.EscapedPath() == "" {
+		return "/"
+	}
+	return u.EscapedPath()
+}
+
+func matchRedirectUriPath(policy string, allowedPath string, path string) bool {
+	if policy != RedirectUriPolicyPathPrefix || allowedPath == path {
+		return allowedPath == path
+	}
+
+	// the prefix ends on a segment boundary, "/app" allows "/app/x" but not "/apps"
+	return strings.HasPrefix(path, strings.TrimSuffix(allowedPath, "/")+"/")
+}
+
+// matchRedirectUri returns the reason why uri doesn't match allowedUri, or
+// "" when it matches. An allowed URI with a query requires the same query,
+// otherwise any query is accepted.
+func matchRedirectUri(policy string, allowedUri string, u *url.URL) string {
+	allowed, err := url.Parse(strings.Replace(allowedUri, "://*.", "://wildcard.", 1))
+	if err != nil || allowed.Host == "" {
+		return redirectUriReasonHost
+	}
+	allowedHost := allowed.Hostname()
+	if strings.Contains(allowedUri, "://*.") {
+		allowedHost = "*" + strings.TrimPrefix(allowedHost, "wildcard")
+	}
+
+	if !matchRedirectUriHost(policy, allowedHost, u.Hostname()) {
+		return redirectUriReasonHost
+	}
+	if !strings.EqualFold(allowed.Scheme, u.Scheme) {
+		return redirectUriReasonScheme
+	}
+	if getRedirectUriPort(allowed) != getRedirectUriPort(u) {
+		return redirectUriReasonPort
+	}
+	if !matchRedirectUriPath(policy, getRedirectUriPath(allowed), getRedirectUriPath(u)) {
+		return redirectUriReasonPath
+	}
+	if allowed.RawQuery != "" && allowed.RawQuery != u.RawQuery {
+		return redirectUriReasonQuery
+	}
+	return ""
+}
+
+func matchRedirectUriLegacy(allowedUris []string, uri string) bool {
+	for _, allowedUri := range allowedUris {
+		allowedUriRegex, err := regexp.Compile(allowedUri)
+		if (err == nil && allowedUriRegex.MatchString(uri)) || strings.Contains(uri, allowedUri) {
+			return true
+		}
+	}
+	return false
+}
+
+// checkRedirectUri returns "" when uri is allowed by one of allowedUris under
+// policy, otherwise the reason of the allowed URI that came the closest. The
+// legacy policy keeps the regex and substring matching of older applications.
+func checkRedirectUri(policy string, allowedUris []string, uri string) string {
+	if len(allowedUris) == 0 {
+		return redirectUriReasonNoAllowedUris
+	}
+	if policy == RedirectUriPolicyLegacy {
+		if matchRedirectUriLegacy(allowedUris, uri) {
+			return ""
+		}
+		return redirectUriReasonHost
+	}
+
+	u, reason := parseRedirectUri(uri)
+	if u == nil {
+		return reason
+	}
+
+	reason = redirectUriReasonHost
+	for _, allowedUri := range allowedUris {
+		r := matchRedirectUri(policy, allowedUri, u)
+		if r == "" {
+			return ""
+		}
+		if getRedirectUriReasonRank(r) > getRedirectUriReasonRank(reason) {
+			reason = r
+		}
+	}
+	return reason
+}
+
+func TranslateRedirectUriReason(lang string, reason string) string {
+	switch reason {
+	case redirectUriReasonNoAllowedUris:
+		return i18n.Translate(lang, "redirect:No redirect URI is allowed for the application")
+	case redirectUriReasonInvalid:
+		return i18n.Translate(lang, "redirect:The URI is not an absolute URL")
+	case redirectUriReasonFragment:
+		return i18n.Translate(lang, "redirect:The URI must not contain a fragment")
+	case redirectUriReasonUserinfo:
+		return i18n.Translate(lang, "redirect:The URI must not contain user credentials")
+	case redirectUriReasonDotSegment:
+		return i18n.Translate(lang, "redirect:The URI must not contain dot segments in the path")
+	case redirectUriReasonHost:
+		return i18n.Translate(lang, "redirect:The host is not allowed")
+	case redirectUriReasonScheme:
+		return i18n.Translate(lang, "redirect:The scheme doesn't match the allowed URI")
+	case redirectUriReasonPort:
+		return i18n.Translate(lang, "redirect:The port doesn't match the allowed URI")
+	case redirectUriReasonPath:
+		return i18n.Translate(lang, "redirect:The path doesn't match the allowed URI")
+	case redirectUriReasonQuery:
+		return i18n.Translate(lang, "redirect:The query doesn't match the allowed URI")
+	default:
+		return reason
+	}
+}
+
+// CheckRedirectUri returns the reason why redirectUri is rejected, or ""
+// when it is allowed.
+func (application *Application) CheckRedirectUri(redirectUri string) string {
+	return checkRedirectUri(application.RedirectUriPolicy, application.RedirectUris, redirectUri)
+}
+
+// CheckServiceUri checks the SAML assertion consumer service URL or the CAS
+// service against the redirect URIs. They were never checked under the legacy
+// policy, so the legacy applications without redirect URIs keep working.
+func (application *Application) CheckServiceUri(serviceUri string) string {
+	if application.RedirectUriPolicy == RedirectUriPolicyLegacy {
+		return ""
+	}
+	return application.CheckRedirectUri(serviceUri)
+}
+
+func (application *Application) IsRedirectUriValid(redirectUri string) bool {
+	return application.CheckRedirectUri(redirectUri) == ""
+}
+
+// isOriginValid checks an origin (without path) against the scheme, host and
+// port of the redirect URIs.
+func (application *Application) isOriginValid(origin string) bool {
+	if application.RedirectUriPolicy == RedirectUriPolicyLegacy {
+		return application.IsRedirectUriValid(origin)
+	}
+
+	u, reason := parseRedirectUri(origin)
+	if u == nil {
+		return false
+	}
+	for _, allowedUri := range application.RedirectUris {
+		reason = matchRedirectUri(application.RedirectUriPolicy, allowedUri, u)
+		if reason == "" || reason == redirectUriReasonPath || reason == redirectUriReasonQuery {
+			return true
+		}
+	}
+	return false
+}
+
+// CheckCasService checks the service of a ticket validation against the
+// service the ticket was issued to, a longer path is allowed as CAS clients
+// commonly append to the service URL.
+func CheckCasService(issuedService string, service string) string {
+	return checkRedirectUri(RedirectUriPolicyPathPrefix, []string{issuedService}, service)
+}
@@ -0,0 +1,104 @@
+// Copyright 2023 The Casdoor Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package object
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckRedirectUri(t *testing.T) {
+	scenarios := []struct {
+		description string
+		policy      string
+		allowedUris []string
+		uri         string
+		expected    string
+	}{
+		{"exact match", RedirectUriPolicyExact, []string{"https://app.example.com/callback"}, "https://app.example.com/callback", ""},
+		{"exact with query", RedirectUriPolicyExact, []string{"https://app.example.com/callback"}, "https://app.example.com/callback?x=1", ""},
+		{"exact with the default port", RedirectUriPolicyExact, []string{"https://app.example.com/callback"}, "https://app.example.com:443/callback", ""},
+		{"exact host case", RedirectUriPolicyExact, []string{"https://app.example.com/callback"}, "https://APP.example.com/callback", ""},
+		{"exact other path", RedirectUriPolicyExact, []string{"https://app.example.com/callback"}, "https://app.example.com/callback/x", redirectUriReasonPath},
+		{"exact other host", RedirectUriPolicyExact, []string{"https://app.example.com/callback"}, "https://evil.com/callback", redirectUriReasonHost},
+		{"exact host suffix", RedirectUriPolicyExact, []string{"https://app.example.com/callback"}, "https://app.example.com.evil.com/callback", redirectUriReasonHost},
+		{"exact scheme", RedirectUriPolicyExact, []string{"https://app.example.com/callback"}, "http://app.example.com/callback", redirectUriReasonScheme},
+		{"exact port", RedirectUriPolicyExact, []string{"https://app.example.com/callback"}, "https://app.example.com:8443/callback", redirectUriReasonPort},
+		{"exact query required", RedirectUriPolicyExact, []string{"https://app.example.com/callback?a=1"}, "https://app.example.com/callback?a=2", redirectUriReasonQuery},
+		{"exact wildcard is literal", RedirectUriPolicyExact, []string{"https://*.example.com/callback"}, "https://app.example.com/callback", redirectUriReasonHost},
+		{"closest reason", RedirectUriPolicyExact, []string{"https://other.com/", "https://app.example.com/callback"}, "https://app.example.com/cb", redirectUriReasonPath},
+		{"fragment", RedirectUriPolicyExact, []string{"https://app.example.com/callback"}, "https://app.example.com/callback#x", redirectUriReasonFragment},
+		{"userinfo", RedirectUriPolicyExact, []string{"https://app.example.com/callback"}, "https://user@app.example.com/callback", redirectUriReasonUserinfo},
+		{"relative", RedirectUriPolicyExact, []string{"https://app.example.com/callback"}, "/callback", redirectUriReasonInvalid},
+		{"no allowed uri", RedirectUriPolicyExact, []string{}, "https://app.example.com/callback", redirectUriReasonNoAllowedUris},
+		{"subdomain", RedirectUriPolicySubdomain, []string{"https://*.example.com/callback"}, "https://tenant.example.com/callback", ""},
+		{"subdomain apex", RedirectUriPolicySubdomain, []string{"https://*.example.com/callback"}, "https://example.com/callback", redirectUriReasonHost},
+		{"subdomain two labels", RedirectUriPolicySubdomain, []string{"https://*.example.com/callback"}, "https://a.b.example.com/callback", redirectUriReasonHost},
+		{"subdomain suffix", RedirectUriPolicySubdomain, []string{"https://*.example.com/callback"}, "https://evilexample.com/callback", redirectUriReasonHost},
+		{"subdomain path", RedirectUriPolicySubdomain, []string{"https://*.example.com/callback"}, "https://tenant.example.com/other", redirectUriReasonPath},
+		{"prefix", RedirectUriPolicyPathPrefix, []string{"https://app.example.com/app"}, "https://app.example.com/app/callback", ""},
+		{"prefix itself", RedirectUriPolicyPathPrefix, []string{"https://app.example.com/app/"}, "https://app.example.com/app/", ""},
+		{"prefix segment", RedirectUriPolicyPathPrefix, []string{"https://app.example.com/app"}, "https://app.example.com/apps", redirectUriReasonPath},
+		{"prefix dot segments", RedirectUriPolicyPathPrefix, []string{"https://app.example.com/app"}, "https://app.example.com/app/../admin", redirectUriReasonDotSegment},
+		{"prefix encoded dot segments", RedirectUriPolicyPathPrefix, []string{"https://app.example.com/app"}, "https://app.example.com/app/%2e%2e/admin", redirectUriReasonDotSegment},
+		{"legacy regex", RedirectUriPolicyLegacy, []string{"^https://.*\\.example\\.com/"}, "https://app.example.com/callback", ""},
+		{"legacy substring", RedirectUriPolicyLegacy, []string{"app.example.com"}, "https://app.example.com/callback", ""},
+		{"legacy rejected", RedirectUriPolicyLegacy, []string{"app.example.com"}, "https://evil.com/callback", redirectUriReasonHost},
+	}
+
+	for _, scenery := range scenarios {
+		t.Run(scenery.description, func(t *testing.T) {
+			assert.Equal(t, scenery.expected, checkRedirectUri(scenery.policy, scenery.allowedUris, scenery.uri))
+		})
+	}
+}
+
+func TestIsOriginValid(t *testing.T) {
+	application := &Application{RedirectUriPolicy: RedirectUriPolicySubdomain, RedirectUris: []string{"https://*.example.com/callback"}}
+
+	scenarios := []struct {
+		description string
+		origin      string
+		expected    bool
+	}{
+		{"subdomain", "https://tenant.example.com", true},
+		{"other host", "https://evil.com", false},
+		{"other scheme", "http://tenant.example.com", false},
+	}
+
+	for _, scenery := range scenarios {
+		t.Run(scenery.description, func(t *testing.T) {
+			assert.Equal(t, scenery.expected, application.isOriginValid(scenery.origin))
+		})
+	}
+}
+
+func TestCheckCasService(t *testing.T) {
+	assert.Equal(t, "", CheckCasService("https://app.example.com/cas", "https://app.example.com/cas/login"))
+	assert.Equal(t, redirectUriReasonHost, CheckCasService("https://app.example.com", "https://app.example.com.evil.com"))
+}
+
+func TestCheckServiceUri(t *testing.T) {
+	application := &Application{RedirectUriPolicy: RedirectUriPolicyLegacy}
+	assert.Equal(t, "", application.CheckServiceUri("https://sp.example.com/saml/acs"))
+
+	application.RedirectUriPolicy = RedirectUriPolicyExact
+	assert.Equal(t, redirectUriReasonNoAllowedUris, application.CheckServiceUri("https://sp.example.com/saml/acs"))
+
+	application.RedirectUris = []string{"https://sp.example.com/saml/acs"}
+	assert.Equal(t, "", application.CheckServiceUri("https://sp.example.com/saml/acs"))
+	assert.Equal(t, redirectUriReasonHost, application.CheckServiceUri("https://evil.example.com/saml/acs"))
+}
@@ -0,0 +1,349 @@
+// Copyright 2023 The Casdoor Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package object
+
+import (
+	"crypto/tls"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/casdoor/casdoor/conf"
+	"github.com/gomodule/redigo/redis"
+)
+
+const (
+	RedisModeStandalone = "standalone"
+	RedisModeSentinel   = "sentinel"
+	RedisModeCluster    = "cluster"
+)
+
+const redisMaxRedirects = 3
+
+type redisConfig struct {
+	Mode          string
+	Addrs         []string
+	PoolSize      int
+	Password      string
+	Db            int
+	MasterName    string
+	Username      string
+	UseTls        bool
+	TlsSkipVerify bool
+}
+
+// redisClient sends commands to a standalone Redis, to the master known by
+// the sentinels or to the node of a cluster that owns the key.
+type redisClient struct {
+	config *redisConfig
+
+	lock       sync.Mutex
+	pools      map[string]*redis.Pool
+	masterAddr string
+	slots      map[int]string
+}
+
+var redisClientOnce sync.Once
+var redisClientInstance *redisClient
+
+// parseRedisEndpoint parses redisEndpoint in the "address,pool size,
+// password,db,master name" form of beego, the addresses of the sentinels or
+// of the cluster nodes are separated by ";".
+func parseRedisEndpoint(mode string, redisEndpoint string) *redisConfig {
+	config := &redisConfig{Mode: mode, PoolSize: 100, MasterName: "mymaster"}
+	if config.Mode == "" {
+		config.Mode = RedisModeStandalone
+	}
+
+	parts := strings.Split(redisEndpoint, ",")
+	for _, addr := range strings.Split(parts[0], ";") {
+		if addr = strings.TrimSpace(addr); addr != "" {
+			config.Addrs = append(config.Addrs, addr)
+		}
+	}
+	if len(parts) > 1 {
+		if poolSize, err := strconv.Atoi(parts[1]); err == nil && poolSize > 0 {
+			config.PoolSize = poolSize
+		}
+	}
+	if len(parts) > 2 {
+		config.Password = parts[2]
+	}
+	if len(parts) > 3 {
+		if db, err := strconv.Atoi(parts[3]); err == nil && db > 0 {
+			config.Db = db
+		}
+	}
+	if len(parts) > 4 && parts[4] != "" {
+		config.MasterName = parts[4]
+	}
+	return config
+}
+
+func getRedisEndpoint() string {
+	return conf.GetConfigString("redisEndpoint")
+}
+
+// getRedisClient returns the client of redisEndpoint, nil when Redis isn't
+// configured. redisMode selects standalone, sentinel or cluster, redisUsername
+// is the ACL user of the password and redisTls enables TLS.
+func getRedisClient() *redisClient {
+	redisClientOnce.Do(func() {
+		redisEndpoint := getRedisEndpoint()
+		if redisEndpoint == "" {
+			return
+		}
+
+		config := parseRedisEndpoint(conf.GetConfigString("redisMode"), redisEndpoint)
+		config.Username = conf.GetConfigString("redisUsername")
+		config.UseTls, _ = conf.GetConfigBool("redisTls")
+		config.TlsSkipVerify, _ = conf.GetConfigBool("redisTlsSkipVerify")
+		if len(config.Addrs) == 0 {
+			return
+		}
+
+		redisClientInstance = &redisClient{config: config, pools: map[string]*redis.Pool{}, slots: map[int]string{}}
+	})
+	return redisClientInstance
+}
+
+func (client *redisClient) dial(addr string, readTimeout time.Duration, auth bool) (redis.Conn, error) {
+	options := []redis.DialOption{redis.DialConnectTimeout(3 * time.Second), redis.DialReadTimeout(readTimeout), redis.DialWriteTimeout(3 * time.Second)}
+	if client.config.UseTls {
+		host := addr
+		if i := strings.LastIndex(addr, ":"); i != -1 {
+			host = addr[:i]
+		}
+		options = append(options, redis.DialUseTLS(true), redis.DialTLSConfig(&tls.Config{ServerName: host, MinVersion: tls.VersionTLS12}), redis.DialTLSSkipVerify(client.config.TlsSkipVerify))
+	}
+	if auth && client.config.Password != "" && client.config.Username == "" {
+		options = append(options, redis.DialPassword(client.config.Password))
+	}
+	// a cluster only has the db 0
+	if auth && client.config.Db > 0 && client.config.Mode != RedisModeCluster {
+		options = append(options, redis.DialDatabase(client.config.Db))
+	}
+
+	conn, err := redis.Dial("tcp", addr, options...)
+	if err != nil {
+		return nil, err
+	}
+
+	if auth && client.config.Password != "" && client.config.Username != "" {
+		_, err = conn.Do("AUTH", client.config.Username, client.config.Password)
+		if err != nil {
+			conn.Close()
+			return nil, err
+		}
+	}
+	return conn, nil
+}
+
+// getMasterAddr asks the sentinels in turn for the address of the master,
+// the sentinels are expected to accept the connection without the password.
+func (client *redisClient) getMasterAddr() (string, error) {
+	client.lock.Lock()
+	masterAddr := client.masterAddr
+	client.lock.Unlock()
+	if masterAddr != "" {
+		return masterAddr, nil
+	}
+
+	var lastErr error
+	for _, sentinelAddr := range client.config.Addrs {
+		conn, err := client.dial(sentinelAddr, 3*time.Second, false)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+
+		res, err := redis.Strings(conn.Do("SENTINEL", "get-master-addr-by-name", client.config.MasterName))
+		conn.Close()
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		if len(res) != 2 {
+			lastErr = fmt.Errorf("the sentinel: %s doesn't know the master: %s", sentinelAddr, client.config.MasterName)
+			continue
+		}
+
+		masterAddr = fmt.Sprintf("%s:%s", res[0], res[1])
+		client.lock.Lock()
+		client.masterAddr = masterAddr
+		client.lock.Unlock()
+		return masterAddr, nil
+	}
+
+	return "", fmt.Errorf("no sentinel knows the master: %s, %v", client.config.MasterName, lastErr)
+}
+
+// getAddr returns the address to send a command for the key to, any node of
+// a cluster serves subscriptions and commands without a key.
+func (client *redisClient) getAddr(key string) (string, error) {
+	switch client.config.Mode {
+	case RedisModeSentinel:
+		return client.getMasterAddr()
+	case RedisModeCluster:
+		if key != "" {
+			client.lock.Lock()
+			addr, ok := client.slots[getRedisKeySlot(key)]
+			client.lock.Unlock()
+			if ok {
+				return addr, nil
+			}
+		}
+		return client.config.Addrs[0], nil
+	default:
+		return client.config.Addrs[0], nil
+	}
+}
+
+func (client *redisClient) getPool(addr string) *redis.Pool {
+	client.lock.Lock()
+	defer client.lock.Unlock()
+
+	pool, ok := client.pools[addr]
+	if !ok {
+		pool = &redis.Pool{
+			MaxIdle:     3,
+			MaxActive:   client.config.PoolSize,
+			IdleTimeout: 240 * time.Second,
+			Dial: func() (redis.Conn, error) {
+				return client.dial(addr, 3*time.Second, true)
+			},
+		}
+		client.pools[addr] = pool
+	}
+	return pool
+}
+
+// Dial opens a connection of its own, for subscriptions that block with a
+// zero readTimeout.
+func (client *redisClient) Dial(readTimeout time.Duration) (redis.Conn, error) {
+	addr, err := client.getAddr("")
+	if err != nil {
+		return nil, err
+	}
+
+	conn, err := client.dial(addr, readTimeout, true)
+	if err != nil {
+		client.resetMaster()
+	}
+	return conn, err
+}
+
+func (client *redisClient) resetMaster() {
+	client.lock.Lock()
+	client.masterAddr = ""
+	client.lock.Unlock()
+}
+
+// Do sends the command, the first argument is the key. The MOVED and ASK
+// redirections of a cluster are followed, and a failed or demoted master of
+// the sentinels is looked up again on the next command.
+func (client *redisClient) Do(commandName string, args ...interface{}) (interface{}, error) {
+	key := ""
+	if len(args) > 0 {
+		key, _ = args[0].(string)
+	}
+
+	addr, err := client.getAddr(key)
+	if err != nil {
+		return nil, err
+	}
+
+	asking := false
+	for i := 0; ; i++ {
+		conn := client.getPool(addr).Get()
+		if asking {
+			_, _ = conn.Do("ASKING")
+		}
+		res, err := conn.Do(commandName, args...)
+		conn.Close()
+
+		redisErr, ok := err.(redis.Error)
+		if (err != nil && !ok) || (ok && strings.HasPrefix(redisErr.Error(), "READONLY")) {
+			client.resetMaster()
+			return res, err
+		}
+		if !ok || client.config.Mode != RedisModeCluster || i >= redisMaxRedirects {
+			return res, err
+		}
+
+		redirection, slot, redirectAddr := parseRedisRedirection(redisErr.Error())
+		if redirection == "" {
+			return res, err
+		}
+
+		addr = redirectAddr
+		asking = redirection == "ASK"
+		if redirection == "MOVED" {
+			client.lock.Lock()
+			client.slots[slot] = addr
+			client.lock.Unlock()
+		}
+	}
+}
+
+// parseRedisRedirection parses an error like "MOVED 3999 127.0.0.1:6381".
+func parseRedisRedirection(message string) (string, int, string) {
+	parts := strings.Split(message, " ")
+	if len(parts) != 3 || parts[0] != "MOVED" && parts[0] != "ASK" {
+		return "", 0, ""
+	}
+
+	slot, err := strconv.Atoi(parts[1])
+	if err != nil {
+		return "", 0, ""
+	}
+	return parts[0], slot, parts[2]
+}
+
+// getRedisKeySlot returns the hash slot of a cluster for the key, only the
+// part inside the first "{...}" is hashed when it isn't empty.
+func getRedisKeySlot(key string) int {
+	if start := strings.Index(key, "{"); start != -1 {
+		if end := strings.Index(key[start+1:], "}"); end > 0 {
+			key = key[start+1 : start+1+end]
+		}
+	}
+
+	// CRC16-CCITT (XModem)
+	crc := uint16(0)
+	for i := 0; i < len(key); i++ {
+		crc ^= uint16(key[i]) << 8
+		for j := 0; j < 8; j++ {
+			if crc&0x8000 != 0 {
+				crc = crc<<1 ^ 0x1021
+			} else {
+				crc <<= 1
+			}
+		}
+	}
+	return int(crc % 16384)
+}
+
+// dialRedis connects to redisEndpoint, a zero readTimeout blocks for
+// subscriptions.
+func dialRedis(readTimeout time.Duration) (redis.Conn, error) {
+	client := getRedisClient()
+	if client == nil {
+		return nil, fmt.Errorf("redisEndpoint isn't configured")
+	}
+	return client.Dial(readTimeout)
+}
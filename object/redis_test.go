@@ -0,0 +1,62 @@
+// Copyright 2023 The Casdoor Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package object
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseRedisEndpoint(t *testing.T) {
+	scenarios := []struct {
+		description   string
+		mode          string
+		redisEndpoint string
+		expected      *redisConfig
+	}{
+		{"standalone", "", "127.0.0.1:6379", &redisConfig{Mode: RedisModeStandalone, Addrs: []string{"127.0.0.1:6379"}, PoolSize: 100, MasterName: "mymaster"}},
+		{"standalone with password and db", "", "127.0.0.1:6379,10,secret,2", &redisConfig{Mode: RedisModeStandalone, Addrs: []string{"127.0.0.1:6379"}, PoolSize: 10, Password: "secret", Db: 2, MasterName: "mymaster"}},
+		{"sentinel", RedisModeSentinel, "10.0.0.1:26379;10.0.0.2:26379,,secret,0,casdoor", &redisConfig{Mode: RedisModeSentinel, Addrs: []string{"10.0.0.1:26379", "10.0.0.2:26379"}, PoolSize: 100, Password: "secret", MasterName: "casdoor"}},
+		{"cluster", RedisModeCluster, "10.0.0.1:6379; 10.0.0.2:6379;", &redisConfig{Mode: RedisModeCluster, Addrs: []string{"10.0.0.1:6379", "10.0.0.2:6379"}, PoolSize: 100, MasterName: "mymaster"}},
+	}
+
+	for _, scenery := range scenarios {
+		t.Run(scenery.description, func(t *testing.T) {
+			assert.Equal(t, scenery.expected, parseRedisEndpoint(scenery.mode, scenery.redisEndpoint))
+		})
+	}
+}
+
+func TestGetRedisKeySlot(t *testing.T) {
+	assert.Equal(t, 12182, getRedisKeySlot("foo"))
+	assert.Equal(t, 12739, getRedisKeySlot("123456789"))
+	assert.Equal(t, getRedisKeySlot("user1000"), getRedisKeySlot("{user1000}.following"))
+}
+
+func TestParseRedisRedirection(t *testing.T) {
+	redirection, slot, addr := parseRedisRedirection("MOVED 3999 127.0.0.1:6381")
+	assert.Equal(t, "MOVED", redirection)
+	assert.Equal(t, 3999, slot)
+	assert.Equal(t, "127.0.0.1:6381", addr)
+
+	redirection, slot, addr = parseRedisRedirection("ASK 3999 127.0.0.1:6381")
+	assert.Equal(t, "ASK", redirection)
+	assert.Equal(t, 3999, slot)
+	assert.Equal(t, "127.0.0.1:6381", addr)
+
+	redirection, _, _ = parseRedisRedirection("ERR unknown command")
+	assert.Equal(t, "", redirection)
+}
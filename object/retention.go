@@ -0,0 +1,277 @@
+// Copyright 2023 The Casdoor Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package object
+
+import (
+	"encoding/json"
+	"sync"
+	"time"
+
+	"github.com/casdoor/casdoor/conf"
+	"github.com/xorm-io/core"
+)
+
+const (
+	retentionJobInterval = time.Hour
+	retentionBatchSize   = 1000
+)
+
+// RetentionPolicy is the "retention" setting: how many days the objects are
+// kept, 0 keeps them forever. Tokens are kept for the days after their
+// refresh token expired, the webhook retention applies to the records that
+// were sent to webhooks and the task retention to the tasks that succeeded or
+// failed for good.
+type RetentionPolicy struct {
+	Token              int `json:"token"`
+	VerificationRecord int `json:"verificationRecord"`
+	Record             int `json:"record"`
+	Webhook            int `json:"webhook"`
+	Session            int `json:"session"`
+	Task               int `json:"task"`
+}
+
+// RetentionMetrics holds the row counts of the purged tables and what the
+// purge job deleted on this node, the job runs on one node at a time.
+type RetentionMetrics struct {
+	Policy       *RetentionPolicy `json:"policy"`
+	Rows         map[string]int64 `json:"rows"`
+	LastRunTime  string           `json:"lastRunTime"`
+	LastDuration float64          `json:"lastDuration"`
+	LastDeleted  map[string]int64 `json:"lastDeleted"`
+	TotalDeleted map[string]int64 `json:"totalDeleted"`
+}
+
+var retentionMetrics = struct {
+	sync.Mutex
+	lastRunTime  string
+	lastDuration time.Duration
+	lastDeleted  map[string]int64
+	totalDeleted map[string]int64
+}{lastDeleted: map[string]int64{}, totalDeleted: map[string]int64{}}
+
+func GetRetentionPolicy() *RetentionPolicy {
+	policy := &RetentionPolicy{}
+	if value := conf.GetConfigString("retention"); value != "" {
+		_ = json.Unmarshal([]byte(value), policy)
+	}
+	return policy
+}
+
+func getRetentionCutoff(days int) time.Time {
+	return time.Now().Add(-time.Duration(days) * 24 * time.Hour)
+}
+
+// purgeRecords deletes the records created before the cutoff in batches, so
+// that a large backlog doesn't lock the table for long.
+func purgeRecords(cutoff time.Time, isTriggered bool) int64 {
+	var deleted int64
+	for {
+		records := []*Record{}
+		session := adapter.Engine.Cols("id").Where("created_time < ?", cutoff.Format(time.RFC3339))
+		if isTriggered {
+			session = session.And("is_triggered = ?", true)
+		}
+		err := session.Limit(retentionBatchSize).Find(&records)
+		if err != nil {
+			panic(err)
+		}
+		if len(records) == 0 {
+			return deleted
+		}
+
+		ids := []int{}
+		for _, record := range records {
+			ids = append(ids, record.Id)
+		}
+		affected, err := adapter.Engine.In("id", ids).Delete(&Record{})
+		if err != nil {
+			panic(err)
+		}
+		deleted += affected
+
+		if len(records) < retentionBatchSize {
+			return deleted
+		}
+	}
+}
+
+func purgeVerificationRecords(cutoff time.Time) int64 {
+	affected, err := adapter.Engine.Where("time < ?", cutoff.Unix()).Delete(&VerificationRecord{})
+	if err != nil {
+		panic(err)
+	}
+
+	return affected
+}
+
+func purgeSessions(cutoff time.Time) int64 {
+	affected, err := adapter.Engine.Where("created_time < ?", cutoff.Format(time.RFC3339)).Delete(&Session{})
+	if err != nil {
+		panic(err)
+	}
+
+	return affected
+}
+
+func purgeTasks(cutoff time.Time) int64 {
+	affected, err := adapter.Engine.Where("updated_time < ?", cutoff.Format(time.RFC3339)).
+		In("state", TaskStateSucceeded, TaskStateFailed).Delete(&Task{})
+	if err != nil {
+		panic(err)
+	}
+
+	return affected
+}
+
+// purgeTokens deletes the tokens whose access token and refresh token both
+// expired before the cutoff.
+func purgeTokens(cutoff time.Time) int64 {
+	refreshExpireInHours := map[string]int{}
+	getRefreshExpireInHours := func(name string) int {
+		if hours, ok := refreshExpireInHours[name]; ok {
+			return hours
+		}
+
+		hours := 0
+		if application := getApplication("admin", name); application != nil {
+			hours = application.RefreshExpireInHours
+		}
+		refreshExpireInHours[name] = hours
+		return hours
+	}
+
+	var deleted int64
+	offset := 0
+	for {
+		tokens := []*Token{}
+		err := adapter.Engine.Cols("owner", "name", "created_time", "application", "expires_in").
+			Where("created_time < ?", cutoff.Format(time.RFC3339)).Asc("created_time").Limit(retentionBatchSize, offset).Find(&tokens)
+		if err != nil {
+			panic(err)
+		}
+
+		kept := 0
+		for _, token := range tokens {
+			createdTime, err := time.Parse(time.RFC3339, token.CreatedTime)
+			if err != nil {
+				kept++
+				continue
+			}
+
+			expireTime := createdTime.Add(time.Duration(token.ExpiresIn) * time.Second)
+			refreshExpireTime := createdTime.Add(time.Duration(getRefreshExpireInHours(token.Application)) * time.Hour)
+			if refreshExpireTime.After(expireTime) {
+				expireTime = refreshExpireTime
+			}
+			if expireTime.After(cutoff) {
+				kept++
+				continue
+			}
+
+			affected, err := adapter.Engine.ID(core.PK{token.Owner, token.Name}).Delete(&Token{})
+			if err != nil {
+				panic(err)
+			}
+			deleted += affected
+		}
+
+		if len(tokens) < retentionBatchSize {
+			return deleted
+		}
+		offset += kept
+	}
+}
+
+// purgeExpiredObjects deletes what is older than the retention policy.
+func purgeExpiredObjects() error {
+	startTime := time.Now()
+	policy := GetRetentionPolicy()
+	deleted := map[string]int64{}
+	if policy.Token > 0 {
+		deleted["token"] = purgeTokens(getRetentionCutoff(policy.Token))
+	}
+	if policy.VerificationRecord > 0 {
+		deleted["verificationRecord"] = purgeVerificationRecords(getRetentionCutoff(policy.VerificationRecord))
+	}
+	if policy.Webhook > 0 {
+		deleted["webhook"] = purgeRecords(getRetentionCutoff(policy.Webhook), true)
+	}
+	if policy.Record > 0 {
+		deleted["record"] = purgeRecords(getRetentionCutoff(policy.Record), false)
+	}
+	if policy.Session > 0 {
+		deleted["session"] = purgeSessions(getRetentionCutoff(policy.Session))
+	}
+	if policy.Task > 0 {
+		deleted["task"] = purgeTasks(getRetentionCutoff(policy.Task))
+	}
+
+	retentionMetrics.Lock()
+	defer retentionMetrics.Unlock()
+	retentionMetrics.lastRunTime = startTime.Format(time.RFC3339)
+	retentionMetrics.lastDuration = time.Since(startTime)
+	retentionMetrics.lastDeleted = deleted
+	for name, count := range deleted {
+		retentionMetrics.totalDeleted[name] += count
+	}
+	return nil
+}
+
+// RunRetentionJob purges the expired objects every hour, once per interval
+// cluster-wide.
+func RunRetentionJob() {
+	ticker := time.NewTicker(retentionJobInterval)
+	defer ticker.Stop()
+	for {
+		RunClusterJob("retention", retentionJobInterval, purgeExpiredObjects)
+		<-ticker.C
+	}
+}
+
+func countRows(bean interface{}) int64 {
+	count, err := adapter.Engine.Count(bean)
+	if err != nil {
+		panic(err)
+	}
+
+	return count
+}
+
+func GetRetentionMetrics() *RetentionMetrics {
+	metrics := &RetentionMetrics{
+		Policy: GetRetentionPolicy(),
+		Rows: map[string]int64{
+			"token":              countRows(&Token{}),
+			"verificationRecord": countRows(&VerificationRecord{}),
+			"record":             countRows(&Record{}),
+			"session":            countRows(&Session{}),
+			"task":               countRows(&Task{}),
+		},
+		LastDeleted:  map[string]int64{},
+		TotalDeleted: map[string]int64{},
+	}
+
+	retentionMetrics.Lock()
+	defer retentionMetrics.Unlock()
+	metrics.LastRunTime = retentionMetrics.lastRunTime
+	metrics.LastDuration = float64(retentionMetrics.lastDuration) / float64(time.Millisecond)
+	for name, count := range retentionMetrics.lastDeleted {
+		metrics.LastDeleted[name] = count
+	}
+	for name, count := range retentionMetrics.totalDeleted {
+		metrics.TotalDeleted[name] = count
+	}
+	return metrics
+}
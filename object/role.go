@@ -17,7 +17,9 @@ package object
 import (
 	"fmt"
 	"strings"
+	"time"
 
+	"github.com/casdoor/casdoor/i18n"
 	"github.com/casdoor/casdoor/util"
 	"github.com/xorm-io/core"
 )
@@ -32,6 +34,11 @@ type Role struct {
 	Roles     []string `xorm:"mediumtext" json:"roles"`
 	Domains   []string `xorm:"mediumtext" json:"domains"`
 	IsEnabled bool     `json:"isEnabled"`
+
+	Assignments []*RoleAssignment `xorm:"mediumtext" json:"assignments"`
+	Approvers   []string          `xorm:"mediumtext" json:"approvers"`
+
+	Capabilities []string `xorm:"mediumtext" json:"capabilities"`
 }
 
 func GetRoleCount(owner, field, value string) int {
@@ -95,7 +102,9 @@ func UpdateRole(id string, role *Role) bool {
 		return false
 	}
 
-	permissions := GetPermissionsByRole(id)
+	_, expiredUsers := role.applyAssignments(time.Now())
+
+	permissions := getPermissionsByRoleAndAncestors(oldRole)
 	for _, permission := range permissions {
 		removeGroupingPolicies(permission)
 		removePolicies(permission)
@@ -113,17 +122,22 @@ func UpdateRole(id string, role *Role) bool {
 		panic(err)
 	}
 
-	newRoleID := role.GetId()
-	permissions = GetPermissionsByRole(newRoleID)
+	permissions = getPermissionsByRoleAndAncestors(role)
 	for _, permission := range permissions {
 		addGroupingPolicies(permission)
 		addPolicies(permission)
 	}
 
+	for _, userId := range expiredUsers {
+		addRoleAssignmentExpiredRecord(role, userId)
+	}
+
 	return affected != 0
 }
 
 func AddRole(role *Role) bool {
+	role.applyAssignments(time.Now())
+
 	affected, err := adapter.Engine.Insert(role)
 	if err != nil {
 		panic(err)
@@ -140,6 +154,18 @@ func DeleteRole(role *Role) bool {
 		UpdatePermission(permission.GetId(), permission)
 	}
 
+	parents := []*Role{}
+	err := adapter.Engine.Where("roles like ?", "%"+roleId+"%").Find(&parents)
+	if err != nil {
+		panic(err)
+	}
+	for _, parent := range parents {
+		if util.ContainsString(parent.Roles, roleId) {
+			parent.Roles = util.DeleteVal(parent.Roles, roleId)
+			UpdateRole(parent.GetId(), parent)
+		}
+	}
+
 	affected, err := adapter.Engine.ID(core.PK{role.Owner, role.Name}).Delete(&Role{})
 	if err != nil {
 		panic(err)
@@ -166,6 +192,87 @@ func GetRolesByUser(userId string) []*Role {
 	return roles
 }
 
+// GetAllRolesByUser returns the roles of the user together with the roles
+// they inherit, i.e. the roles that have one of them as a sub role.
+func GetAllRolesByUser(userId string) []*Role {
+	roles := GetRolesByUser(userId)
+	roles = append(roles, getAncestorRoles(roles)...)
+	return GetMaskedRoles(roles)
+}
+
+// getAncestorRoles follows the sub role chains upwards, every role is
+// returned once even if the chains have a cycle.
+func getAncestorRoles(roles []*Role) []*Role {
+	visited := map[string]bool{}
+	for _, role := range roles {
+		visited[role.GetId()] = true
+	}
+
+	res := []*Role{}
+	queue := roles
+	for len(queue) > 0 {
+		roleId := queue[0].GetId()
+		queue = queue[1:]
+
+		parents := []*Role{}
+		err := adapter.Engine.Where("roles like ?", "%"+roleId+"%").Find(&parents)
+		if err != nil {
+			panic(err)
+		}
+
+		for _, parent := range parents {
+			parentId := parent.GetId()
+			if visited[parentId] || !util.ContainsString(parent.Roles, roleId) {
+				continue
+			}
+
+			visited[parentId] = true
+			res = append(res, parent)
+			queue = append(queue, parent)
+		}
+	}
+	return res
+}
+
+// getPermissionsByRoleAndAncestors returns the permissions whose grouping
+// policies include the members of the role.
+func getPermissionsByRoleAndAncestors(role *Role) []*Permission {
+	permissionMap := map[string]bool{}
+	res := []*Permission{}
+	for _, r := range append([]*Role{role}, getAncestorRoles([]*Role{role})...) {
+		for _, permission := range GetPermissionsByRole(r.GetId()) {
+			if !permissionMap[permission.GetId()] {
+				permissionMap[permission.GetId()] = true
+				res = append(res, permission)
+			}
+		}
+	}
+	return res
+}
+
+// CheckRoleInheritance rejects sub roles that would make the role inherit
+// from itself through a chain of sub roles.
+func CheckRoleInheritance(id string, role *Role, lang string) string {
+	visited := map[string]bool{}
+	stack := append([]string{}, role.Roles...)
+	for len(stack) > 0 {
+		subRoleId := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		if subRoleId == id || subRoleId == role.GetId() {
+			return fmt.Sprintf(i18n.Translate(lang, "role:The sub roles of role: %s can't include the role itself"), role.GetId())
+		}
+		if visited[subRoleId] {
+			continue
+		}
+		visited[subRoleId] = true
+
+		if subRole := GetRole(subRoleId); subRole != nil {
+			stack = append(stack, subRole.Roles...)
+		}
+	}
+	return ""
+}
+
 func roleChangeTrigger(oldName string, newName string) error {
 	session := adapter.Engine.NewSession()
 	defer session.Close()
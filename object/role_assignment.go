@@ -0,0 +1,147 @@
+// Copyright 2023 The Casdoor Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package object
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/beego/beego/logs"
+	"github.com/casdoor/casdoor/i18n"
+	"github.com/casdoor/casdoor/util"
+)
+
+const roleAssignmentJobInterval = time.Minute
+
+// RoleAssignment bounds the membership of a user in a role, an empty start
+// time means from now and an empty end time means forever. The user is in
+// the users of the role only while the assignment is active.
+type RoleAssignment struct {
+	User      string `json:"user"`
+	StartTime string `json:"startTime"`
+	EndTime   string `json:"endTime"`
+}
+
+func parseAssignmentTime(s string) (time.Time, bool) {
+	if s == "" {
+		return time.Time{}, false
+	}
+
+	t, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		return time.Time{}, false
+	}
+	return t, true
+}
+
+// applyAssignments adds the users of the assignments that started to the
+// role, removes the ones outside of their assignments and drops the expired
+// assignments, it returns the users whose assignments expired.
+func (role *Role) applyAssignments(now time.Time) (bool, []string) {
+	changed := false
+	expiredUsers := []string{}
+	assignments := []*RoleAssignment{}
+	for _, assignment := range role.Assignments {
+		if assignment.User == "" {
+			continue
+		}
+
+		startTime, hasStartTime := parseAssignmentTime(assignment.StartTime)
+		endTime, hasEndTime := parseAssignmentTime(assignment.EndTime)
+		isMember := util.ContainsString(role.Users, assignment.User)
+
+		if hasEndTime && !now.Before(endTime) {
+			if isMember {
+				role.Users = util.DeleteVal(role.Users, assignment.User)
+			}
+			expiredUsers = append(expiredUsers, assignment.User)
+			changed = true
+			continue
+		}
+
+		assignments = append(assignments, assignment)
+		isActive := !hasStartTime || !now.Before(startTime)
+		if isActive && !isMember {
+			role.Users = append(role.Users, assignment.User)
+			changed = true
+		} else if !isActive && isMember {
+			role.Users = util.DeleteVal(role.Users, assignment.User)
+			changed = true
+		}
+	}
+
+	role.Assignments = assignments
+	return changed, expiredUsers
+}
+
+func CheckRoleAssignments(role *Role, lang string) string {
+	for _, assignment := range role.Assignments {
+		startTime, hasStartTime := parseAssignmentTime(assignment.StartTime)
+		endTime, hasEndTime := parseAssignmentTime(assignment.EndTime)
+		if (assignment.StartTime != "" && !hasStartTime) || (assignment.EndTime != "" && !hasEndTime) {
+			return fmt.Sprintf(i18n.Translate(lang, "role:The assignment of user: %s has an invalid time"), assignment.User)
+		}
+		if hasStartTime && hasEndTime && !startTime.Before(endTime) {
+			return fmt.Sprintf(i18n.Translate(lang, "role:The assignment of user: %s must start before it ends"), assignment.User)
+		}
+	}
+	return ""
+}
+
+// addRoleAssignmentExpiredRecord emits the "expire-role-assignment" event,
+// so that the webhooks of the organization are sent like for the API calls.
+func addRoleAssignmentExpiredRecord(role *Role, userId string) {
+	logs.Info(fmt.Sprintf("the assignment of user: %s to role: %s expired", userId, role.GetId()))
+
+	owner, name := util.GetOwnerAndNameFromId(userId)
+	record := &Record{
+		Name:         util.GenerateId(),
+		CreatedTime:  util.GetCurrentTime(),
+		Organization: owner,
+		User:         name,
+		Method:       "POST",
+		RequestUri:   fmt.Sprintf("/api/update-role?id=%s", role.GetId()),
+		Action:       "expire-role-assignment",
+	}
+	AddRecord(record)
+}
+
+func applyRoleAssignments() error {
+	now := time.Now()
+	for _, role := range GetRoles("") {
+		if len(role.Assignments) == 0 {
+			continue
+		}
+
+		// UpdateRole applies the assignments again and emits the events
+		copiedRole := *role
+		copiedRole.Users = append([]string{}, role.Users...)
+		if changed, _ := copiedRole.applyAssignments(now); changed {
+			UpdateRole(role.GetId(), role)
+		}
+	}
+	return nil
+}
+
+// RunRoleAssignmentJob starts and expires the role assignments every
+// minute, once per interval cluster-wide.
+func RunRoleAssignmentJob() {
+	ticker := time.NewTicker(roleAssignmentJobInterval)
+	defer ticker.Stop()
+	for {
+		RunClusterJob("role-assignment", roleAssignmentJobInterval, applyRoleAssignments)
+		<-ticker.C
+	}
+}
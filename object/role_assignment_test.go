@@ -0,0 +1,55 @@
+// Copyright 2023 The Casdoor Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package object
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestApplyRoleAssignments(t *testing.T) {
+	now := time.Date(2023, 6, 1, 12, 0, 0, 0, time.UTC)
+	past := now.Add(-time.Hour).Format(time.RFC3339)
+	future := now.Add(time.Hour).Format(time.RFC3339)
+
+	scenarios := []struct {
+		description  string
+		users        []string
+		assignments  []*RoleAssignment
+		changed      bool
+		expectUsers  []string
+		expiredUsers []string
+		assignCount  int
+	}{
+		{"active assignment adds the user", []string{}, []*RoleAssignment{{User: "org/alice", StartTime: past, EndTime: future}}, true, []string{"org/alice"}, []string{}, 1},
+		{"assignment without times stays", []string{"org/alice"}, []*RoleAssignment{{User: "org/alice"}}, false, []string{"org/alice"}, []string{}, 1},
+		{"pending assignment removes the user", []string{"org/alice", "org/bob"}, []*RoleAssignment{{User: "org/alice", StartTime: future}}, true, []string{"org/bob"}, []string{}, 1},
+		{"expired assignment is dropped", []string{"org/alice", "org/bob"}, []*RoleAssignment{{User: "org/alice", EndTime: past}}, true, []string{"org/bob"}, []string{"org/alice"}, 0},
+		{"other users are kept", []string{"org/bob"}, []*RoleAssignment{}, false, []string{"org/bob"}, []string{}, 0},
+	}
+
+	for _, scenery := range scenarios {
+		t.Run(scenery.description, func(t *testing.T) {
+			role := &Role{Owner: "org", Name: "contractor", Users: scenery.users, Assignments: scenery.assignments}
+			changed, expiredUsers := role.applyAssignments(now)
+			assert.Equal(t, scenery.changed, changed)
+			assert.Equal(t, scenery.expectUsers, role.Users)
+			assert.Equal(t, scenery.expiredUsers, expiredUsers)
+			assert.Equal(t, scenery.assignCount, len(role.Assignments))
+		})
+	}
+}
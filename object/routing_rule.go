@@ -0,0 +1,97 @@
+// Copyright 2023 The Casdoor Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package object
+
+import (
+	"github.com/casdoor/casdoor/util"
+)
+
+// RoutingRule decides where a user lands after signing in without a
+// redirect URI. All the conditions that are set must match: the tag of the
+// user (how an organization groups its users), a role of the user, the type
+// of the device and whether the user is an admin. A rule without conditions
+// matches everyone.
+type RoutingRule struct {
+	Name        string   `json:"name"`
+	Tags        []string `json:"tags"`
+	Roles       []string `json:"roles"`
+	DeviceTypes []string `json:"deviceTypes"`
+	IsAdmin     bool     `json:"isAdmin"`
+	Url         string   `json:"url"`
+}
+
+func (rule *RoutingRule) isMatched(user *User, roles []string, deviceType string) bool {
+	if rule.IsAdmin && !user.IsAdmin && !user.IsGlobalAdmin {
+		return false
+	}
+	if len(rule.Tags) > 0 && !util.ContainsString(rule.Tags, user.Tag) {
+		return false
+	}
+	if len(rule.DeviceTypes) > 0 && !util.ContainsString(rule.DeviceTypes, deviceType) {
+		return false
+	}
+	if len(rule.Roles) > 0 {
+		for _, role := range roles {
+			if util.ContainsString(rule.Roles, role) {
+				return true
+			}
+		}
+		return false
+	}
+	return true
+}
+
+// getRoutingUrl returns the url of the first matching rule, roles are only
+// loaded when a rule needs them.
+func getRoutingUrl(rules []*RoutingRule, user *User, getRoles func() []string, deviceType string) string {
+	var roles []string
+	for _, rule := range rules {
+		if rule.Url == "" {
+			continue
+		}
+		if len(rule.Roles) > 0 && roles == nil {
+			roles = getRoles()
+		}
+		if rule.isMatched(user, roles, deviceType) {
+			return rule.Url
+		}
+	}
+	return ""
+}
+
+// GetPostLoginUrl returns where the user lands after signing in to the
+// application, the rules of the application come before the ones of the
+// organization. It is empty when no rule matches.
+func GetPostLoginUrl(application *Application, user *User, userAgent string) string {
+	rules := []*RoutingRule{}
+	if application != nil {
+		rules = append(rules, application.RoutingRules...)
+	}
+	if organization := GetOrganizationByUser(user); organization != nil {
+		rules = append(rules, organization.RoutingRules...)
+	}
+	if len(rules) == 0 {
+		return ""
+	}
+
+	getRoles := func() []string {
+		roles := []string{}
+		for _, role := range GetAllRolesByUser(user.GetId()) {
+			roles = append(roles, role.Name)
+		}
+		return roles
+	}
+	return getRoutingUrl(rules, user, getRoles, util.GetDeviceTypeFromUserAgent(userAgent))
+}
@@ -0,0 +1,55 @@
+// Copyright 2023 The Casdoor Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package object
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetRoutingUrl(t *testing.T) {
+	rules := []*RoutingRule{
+		{Name: "admins", IsAdmin: true, Url: "/console"},
+		{Name: "mobile staff", Tags: []string{"staff"}, DeviceTypes: []string{"Mobile"}, Url: "https://m.example.com"},
+		{Name: "support", Roles: []string{"support"}, Url: "https://support.example.com"},
+		{Name: "empty", Url: ""},
+		{Name: "others", Url: "https://portal.example.com"},
+	}
+
+	scenarios := []struct {
+		description string
+		user        *User
+		roles       []string
+		deviceType  string
+		expected    string
+	}{
+		{"Admin", &User{IsAdmin: true, Tag: "staff"}, nil, "Mobile", "/console"},
+		{"Global admin", &User{IsGlobalAdmin: true}, nil, "Desktop", "/console"},
+		{"Staff on mobile", &User{Tag: "staff"}, nil, "Mobile", "https://m.example.com"},
+		{"Staff on desktop", &User{Tag: "staff"}, nil, "Desktop", "https://portal.example.com"},
+		{"Support role", &User{}, []string{"member", "support"}, "Desktop", "https://support.example.com"},
+		{"Others", &User{Tag: "customer"}, []string{"member"}, "Tablet", "https://portal.example.com"},
+	}
+
+	for _, scenery := range scenarios {
+		t.Run(scenery.description, func(t *testing.T) {
+			actual := getRoutingUrl(rules, scenery.user, func() []string { return scenery.roles }, scenery.deviceType)
+			assert.Equal(t, scenery.expected, actual)
+		})
+	}
+
+	assert.Equal(t, "", getRoutingUrl([]*RoutingRule{{Tags: []string{"staff"}, Url: "/staff"}}, &User{}, func() []string { return []string{} }, ""))
+}
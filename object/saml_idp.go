@@ -271,6 +271,8 @@ func GetSamlResponse(application *Application, user *User, samlRequest string, h
 		authnRequest.AssertionConsumerServiceURL = application.SamlReplyUrl
 	} else if authnRequest.AssertionConsumerServiceURL == "" {
 		return "", "", "", fmt.Errorf("err: SAML request don't has attribute 'AssertionConsumerServiceURL' in <samlp:AuthnRequest>")
+	} else if reason := application.CheckServiceUri(authnRequest.AssertionConsumerServiceURL); reason != "" {
+		return "", "", method, fmt.Errorf("err: Assertion Consumer Service URL: %s is rejected: %s", authnRequest.AssertionConsumerServiceURL, TranslateRedirectUriReason("en", reason))
 	}
 
 	_, originBackend := getOriginFromHost(host)
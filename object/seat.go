@@ -0,0 +1,303 @@
+// Copyright 2023 The Casdoor Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package object
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/beego/beego/logs"
+	"github.com/casdoor/casdoor/i18n"
+	"github.com/casdoor/casdoor/util"
+	"github.com/xorm-io/core"
+)
+
+const (
+	SeatResourceUser        = "user"
+	SeatResourceApplication = "application"
+
+	// seatWarningRatio is the share of a limit at which the
+	// "seat-limit-warning" event is emitted
+	seatWarningRatio = 0.9
+	usageJobInterval = time.Hour
+)
+
+// Usage is the metered usage of an organization for one day, Name is the
+// date. Users and Applications are the last measured counts, their peaks are
+// what the billing is reconciled against.
+type Usage struct {
+	Owner       string `xorm:"varchar(100) notnull pk" json:"owner"`
+	Name        string `xorm:"varchar(100) notnull pk" json:"name"`
+	CreatedTime string `xorm:"varchar(100)" json:"createdTime"`
+	UpdatedTime string `xorm:"varchar(100)" json:"updatedTime"`
+
+	Subscription     string `xorm:"varchar(100)" json:"subscription"`
+	Plan             string `xorm:"varchar(100)" json:"plan"`
+	Users            int    `json:"users"`
+	PeakUsers        int    `json:"peakUsers"`
+	MaxUsers         int    `json:"maxUsers"`
+	Applications     int    `json:"applications"`
+	PeakApplications int    `json:"peakApplications"`
+	MaxApplications  int    `json:"maxApplications"`
+}
+
+func (plan *Plan) isSeatBased() bool {
+	return plan.MaxUsers > 0 || plan.MaxApplications > 0
+}
+
+// getSeatSubscription returns the latest active subscription of the
+// organization to a seat-based plan, organizations without one are not
+// limited.
+func getSeatSubscription(organization string) (*Subscription, *Plan) {
+	subscriptions := []*Subscription{}
+	err := adapter.Engine.Desc("created_time").Find(&subscriptions, &Subscription{Organization: organization})
+	if err != nil {
+		panic(err)
+	}
+
+	for _, subscription := range subscriptions {
+		if !subscription.hasAccess() {
+			continue
+		}
+
+		plan := getPlan(subscription.Owner, subscription.Plan)
+		if plan != nil && plan.isSeatBased() {
+			return subscription, plan
+		}
+	}
+	return nil, nil
+}
+
+// getActiveUserCount counts the users that take a seat, forbidden, deleted
+// and guest users don't.
+func getActiveUserCount(organization string) int {
+	count, err := getUserEngine(organization).Where("owner = ? and is_forbidden = ? and is_deleted = ? and is_guest = ?", organization, false, false, false).Count(&User{})
+	if err != nil {
+		panic(err)
+	}
+
+	return int(count)
+}
+
+// GetOrganizationUsage returns the current usage of the organization, the
+// limits are zero (unlimited) when it has no seat-based plan.
+func GetOrganizationUsage(organization string) *Usage {
+	now := time.Now()
+	usage := &Usage{
+		Owner:        organization,
+		Name:         now.Format("2006-01-02"),
+		CreatedTime:  now.Format(time.RFC3339),
+		UpdatedTime:  now.Format(time.RFC3339),
+		Users:        getActiveUserCount(organization),
+		Applications: GetOrganizationApplicationCount("admin", organization, "", ""),
+	}
+	usage.PeakUsers = usage.Users
+	usage.PeakApplications = usage.Applications
+
+	subscription, plan := getSeatSubscription(organization)
+	if subscription != nil {
+		usage.Subscription = subscription.GetId()
+		usage.Plan = plan.GetId()
+		usage.MaxUsers = plan.MaxUsers
+		usage.MaxApplications = plan.MaxApplications
+	}
+	return usage
+}
+
+// getSeatLimitState tells whether adding to count exceeds max, and whether
+// it crosses the warning threshold, so that the warning is emitted once.
+func getSeatLimitState(count int, adding int, max int) (bool, bool) {
+	if max <= 0 {
+		return false, false
+	}
+
+	total := count + adding
+	if total > max {
+		return true, false
+	}
+
+	threshold := seatWarningRatio * float64(max)
+	return false, float64(total) >= threshold && float64(count) < threshold
+}
+
+func addSeatLimitRecord(usage *Usage, action string, resource string) {
+	logs.Warning(fmt.Sprintf("%s for the %ss of organization: %s, users: %d/%d, applications: %d/%d", action, resource, usage.Owner, usage.Users, usage.MaxUsers, usage.Applications, usage.MaxApplications))
+
+	record := &Record{
+		Name:         util.GenerateId(),
+		CreatedTime:  util.GetCurrentTime(),
+		Organization: usage.Owner,
+		Method:       "POST",
+		RequestUri:   fmt.Sprintf("/api/get-organization-usage?id=admin/%s", usage.Owner),
+		Action:       action,
+	}
+	AddRecord(record)
+}
+
+// CheckSeatLimit is called before adding users or applications to an
+// organization. It refuses what would exceed the seat-based plan of the
+// organization and emits the "seat-limit-warning" event when the usage gets
+// close to a limit.
+func CheckSeatLimit(organization string, resource string, adding int, lang string) error {
+	usage := GetOrganizationUsage(organization)
+	if usage.Plan == "" {
+		return nil
+	}
+
+	count, max := usage.Users, usage.MaxUsers
+	if resource == SeatResourceApplication {
+		count, max = usage.Applications, usage.MaxApplications
+	}
+
+	exceeded, warning := getSeatLimitState(count, adding, max)
+	if exceeded {
+		addSeatLimitRecord(usage, "seat-limit-exceeded", resource)
+		if resource == SeatResourceApplication {
+			return fmt.Errorf(i18n.Translate(lang, "subscription:The plan: %s allows at most %d applications"), usage.Plan, max)
+		}
+		return fmt.Errorf(i18n.Translate(lang, "subscription:The plan: %s allows at most %d users"), usage.Plan, max)
+	}
+	if warning {
+		addSeatLimitRecord(usage, "seat-limit-warning", resource)
+	}
+	return nil
+}
+
+// CheckUsersSeatLimit calls CheckSeatLimit for each organization of the users
+// that are added together.
+func CheckUsersSeatLimit(users []*User, lang string) error {
+	owners := []string{}
+	counts := map[string]int{}
+	for _, user := range users {
+		if counts[user.Owner] == 0 {
+			owners = append(owners, user.Owner)
+		}
+		counts[user.Owner]++
+	}
+
+	for _, owner := range owners {
+		err := CheckSeatLimit(owner, SeatResourceUser, counts[owner], lang)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// checkPlanSeats refuses to change to a plan whose limits are below the
+// current usage of the organization.
+func checkPlanSeats(subscription *Subscription, newPlan *Plan) error {
+	if !newPlan.isSeatBased() {
+		return nil
+	}
+
+	usage := GetOrganizationUsage(subscription.Organization)
+	if newPlan.MaxUsers > 0 && usage.Users > newPlan.MaxUsers {
+		return fmt.Errorf("the organization has %d users, more than the %d of the plan: %s", usage.Users, newPlan.MaxUsers, newPlan.Name)
+	}
+	if newPlan.MaxApplications > 0 && usage.Applications > newPlan.MaxApplications {
+		return fmt.Errorf("the organization has %d applications, more than the %d of the plan: %s", usage.Applications, newPlan.MaxApplications, newPlan.Name)
+	}
+	return nil
+}
+
+func getUsage(owner string, name string) *Usage {
+	usage := Usage{Owner: owner, Name: name}
+	existed, err := adapter.Engine.Get(&usage)
+	if err != nil {
+		panic(err)
+	}
+
+	if existed {
+		return &usage
+	}
+	return nil
+}
+
+// GetUsages returns the daily usage of the organization between the dates
+// (inclusive, "2006-01-02"), empty dates leave the range open.
+func GetUsages(organization string, startDate string, endDate string) []*Usage {
+	usages := []*Usage{}
+	session := adapter.Engine.Where("owner = ?", organization)
+	if startDate != "" {
+		session = session.And("name >= ?", startDate)
+	}
+	if endDate != "" {
+		session = session.And("name <= ?", endDate)
+	}
+	err := session.Asc("name").Find(&usages)
+	if err != nil {
+		panic(err)
+	}
+
+	return usages
+}
+
+// mergeUsage folds a new measurement into the usage of the same day.
+func mergeUsage(oldUsage *Usage, usage *Usage) {
+	usage.CreatedTime = oldUsage.CreatedTime
+	if oldUsage.PeakUsers > usage.PeakUsers {
+		usage.PeakUsers = oldUsage.PeakUsers
+	}
+	if oldUsage.PeakApplications > usage.PeakApplications {
+		usage.PeakApplications = oldUsage.PeakApplications
+	}
+}
+
+func recordUsage(organization string) {
+	usage := GetOrganizationUsage(organization)
+	oldUsage := getUsage(usage.Owner, usage.Name)
+	if oldUsage == nil {
+		_, err := adapter.Engine.Insert(usage)
+		if err != nil {
+			panic(err)
+		}
+		return
+	}
+
+	mergeUsage(oldUsage, usage)
+	_, err := adapter.Engine.ID(core.PK{usage.Owner, usage.Name}).AllCols().Update(usage)
+	if err != nil {
+		panic(err)
+	}
+}
+
+// recordUsages meters the organizations with an active seat-based plan.
+func recordUsages() error {
+	organizations := map[string]bool{}
+	for _, subscription := range GetSubscriptions("admin") {
+		if !subscription.hasAccess() || organizations[subscription.Organization] {
+			continue
+		}
+
+		plan := getPlan(subscription.Owner, subscription.Plan)
+		if plan != nil && plan.isSeatBased() {
+			organizations[subscription.Organization] = true
+			recordUsage(subscription.Organization)
+		}
+	}
+	return nil
+}
+
+// RunUsageJob records the usage of the organizations every hour, once per
+// interval cluster-wide.
+func RunUsageJob() {
+	ticker := time.NewTicker(usageJobInterval)
+	defer ticker.Stop()
+	for {
+		RunClusterJob("usage", usageJobInterval, recordUsages)
+		<-ticker.C
+	}
+}
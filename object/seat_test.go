@@ -0,0 +1,60 @@
+// Copyright 2023 The Casdoor Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package object
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetSeatLimitState(t *testing.T) {
+	scenarios := []struct {
+		description string
+		count       int
+		adding      int
+		max         int
+		exceeded    bool
+		warning     bool
+	}{
+		{"unlimited", 1000, 1, 0, false, false},
+		{"below the threshold", 5, 1, 10, false, false},
+		{"crossing the threshold", 8, 1, 10, false, true},
+		{"already above the threshold", 9, 1, 10, false, false},
+		{"reaching the limit in a batch", 3, 7, 10, false, true},
+		{"exceeding the limit", 10, 1, 10, true, false},
+		{"exceeding the limit in a batch", 5, 6, 10, true, false},
+	}
+
+	for _, scenery := range scenarios {
+		t.Run(scenery.description, func(t *testing.T) {
+			exceeded, warning := getSeatLimitState(scenery.count, scenery.adding, scenery.max)
+			assert.Equal(t, scenery.exceeded, exceeded)
+			assert.Equal(t, scenery.warning, warning)
+		})
+	}
+}
+
+func TestMergeUsage(t *testing.T) {
+	oldUsage := &Usage{CreatedTime: "2023-06-01T00:00:00Z", Users: 12, PeakUsers: 12, Applications: 2, PeakApplications: 3}
+	usage := &Usage{CreatedTime: "2023-06-01T05:00:00Z", Users: 10, PeakUsers: 10, Applications: 4, PeakApplications: 4}
+
+	mergeUsage(oldUsage, usage)
+	assert.Equal(t, "2023-06-01T00:00:00Z", usage.CreatedTime)
+	assert.Equal(t, 10, usage.Users)
+	assert.Equal(t, 12, usage.PeakUsers)
+	assert.Equal(t, 4, usage.Applications)
+	assert.Equal(t, 4, usage.PeakApplications)
+}
@@ -0,0 +1,267 @@
+// Copyright 2023 The Casdoor Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package object
+
+import (
+	"fmt"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/beego/beego/logs"
+	"github.com/casdoor/casdoor/i18n"
+	"github.com/casdoor/casdoor/util"
+)
+
+const (
+	SecurityEventLogin            = "login"
+	SecurityEventSignup           = "signup"
+	SecurityEventPasswordChange   = "password-change"
+	SecurityEventMfaChange        = "mfa-change"
+	SecurityEventContactChange    = "contact-change"
+	SecurityEventAppAuthorization = "app-authorization"
+
+	securityActivityDays = 90
+)
+
+// securityEventActions maps the actions of records to the security events
+// shown to the users.
+var securityEventActions = map[string]string{
+	"login":                      SecurityEventLogin,
+	"signup":                     SecurityEventSignup,
+	"set-password":               SecurityEventPasswordChange,
+	"reset-password-by-recovery": SecurityEventPasswordChange,
+	"webauthn/signup/finish":     SecurityEventMfaChange,
+	"reset-email-or-phone":       SecurityEventContactChange,
+	"apply-contact-change":       SecurityEventContactChange,
+	"undo-contact-change":        SecurityEventContactChange,
+}
+
+// SecurityEvent is an event of the own account that a user can review, Id is
+// "record:<id>" for the events from the records and "token:<name>" for the
+// authorizations of applications.
+type SecurityEvent struct {
+	Id          string `json:"id"`
+	Type        string `json:"type"`
+	CreatedTime string `json:"createdTime"`
+	ClientIp    string `json:"clientIp"`
+	Device      string `json:"device"`
+	Location    string `json:"location"`
+	Application string `json:"application"`
+	IsReported  bool   `json:"isReported"`
+}
+
+func getSecurityRecords(user *User, since string) []*Record {
+	actions := []string{}
+	for action := range securityEventActions {
+		actions = append(actions, action)
+	}
+
+	records := []*Record{}
+	err := adapter.Engine.Where("created_time >= ?", since).In("action", actions).Desc("id").Find(&records, &Record{Organization: user.Owner, User: user.Name})
+	if err != nil {
+		panic(err)
+	}
+
+	return records
+}
+
+// getFirstAuthorizationTokens returns the earliest token of each application
+// the user authorized since the time.
+func getFirstAuthorizationTokens(user *User, since string) []*Token {
+	tokens := []*Token{}
+	err := adapter.Engine.Where("created_time >= ?", since).Asc("created_time").Find(&tokens, &Token{Organization: user.Owner, User: user.Name})
+	if err != nil {
+		panic(err)
+	}
+
+	res := []*Token{}
+	applications := map[string]bool{}
+	for _, token := range tokens {
+		if !applications[token.Application] {
+			applications[token.Application] = true
+			res = append(res, token)
+		}
+	}
+	return res
+}
+
+// getReportedSecurityEventIds returns the events the user already reported,
+// from the records of "report-security-event".
+func getReportedSecurityEventIds(user *User) map[string]bool {
+	records := []*Record{}
+	err := adapter.Engine.Find(&records, &Record{Organization: user.Owner, User: user.Name, Action: "report-security-event"})
+	if err != nil {
+		panic(err)
+	}
+
+	res := map[string]bool{}
+	for _, record := range records {
+		if u, err := url.Parse(record.RequestUri); err == nil {
+			res[u.Query().Get("id")] = true
+		}
+	}
+	return res
+}
+
+func getRecordSecurityEvent(record *Record) *SecurityEvent {
+	return &SecurityEvent{
+		Id:          fmt.Sprintf("record:%d", record.Id),
+		Type:        securityEventActions[record.Action],
+		CreatedTime: record.CreatedTime,
+		ClientIp:    record.ClientIp,
+		Device:      util.GetDeviceFromUserAgent(record.UserAgent),
+		Location:    record.Location,
+	}
+}
+
+func getTokenSecurityEvent(token *Token) *SecurityEvent {
+	return &SecurityEvent{
+		Id:          fmt.Sprintf("token:%s", token.Name),
+		Type:        SecurityEventAppAuthorization,
+		CreatedTime: token.CreatedTime,
+		Application: token.Application,
+	}
+}
+
+// GetSecurityActivity returns the security events of the user in the last
+// 90 days, the newest first.
+func GetSecurityActivity(user *User) []*SecurityEvent {
+	since := time.Now().AddDate(0, 0, -securityActivityDays).Format(time.RFC3339)
+
+	events := []*SecurityEvent{}
+	for _, record := range getSecurityRecords(user, since) {
+		events = append(events, getRecordSecurityEvent(record))
+	}
+	for _, token := range getFirstAuthorizationTokens(user, since) {
+		events = append(events, getTokenSecurityEvent(token))
+	}
+
+	reportedIds := getReportedSecurityEventIds(user)
+	for _, event := range events {
+		event.IsReported = reportedIds[event.Id]
+	}
+
+	// the times are RFC 3339 in the same zone, so they sort as strings
+	sort.SliceStable(events, func(i, j int) bool {
+		return events[i].CreatedTime > events[j].CreatedTime
+	})
+	return events
+}
+
+// getSecurityEvent returns the event if it belongs to the user.
+func getSecurityEvent(user *User, id string) *SecurityEvent {
+	tokens := strings.SplitN(id, ":", 2)
+	if len(tokens) != 2 {
+		return nil
+	}
+
+	switch tokens[0] {
+	case "record":
+		recordId, err := strconv.Atoi(tokens[1])
+		if err != nil {
+			return nil
+		}
+
+		record := &Record{Id: recordId}
+		existed, err := adapter.Engine.Get(record)
+		if err != nil {
+			panic(err)
+		}
+		if !existed || record.Organization != user.Owner || record.User != user.Name || securityEventActions[record.Action] == "" {
+			return nil
+		}
+		return getRecordSecurityEvent(record)
+	case "token":
+		token := &Token{Name: tokens[1]}
+		existed, err := adapter.Engine.Get(token)
+		if err != nil {
+			panic(err)
+		}
+		if !existed || token.Organization != user.Owner || token.User != user.Name {
+			return nil
+		}
+		return getTokenSecurityEvent(token)
+	default:
+		return nil
+	}
+}
+
+// RevokeUserSessions signs the user out of all applications and expires all
+// the access tokens of the user.
+func RevokeUserSessions(user *User) {
+	sessions := []*Session{}
+	err := adapter.Engine.Find(&sessions, &Session{Owner: user.Owner, Name: user.Name})
+	if err != nil {
+		panic(err)
+	}
+
+	for _, session := range sessions {
+		DeleteBeegoSession(session.SessionId)
+		DeleteSession(session.GetId())
+	}
+
+	_, err = adapter.Engine.Where("expires_in > ?", 0).Cols("expires_in").Update(&Token{ExpiresIn: 0}, &Token{Organization: user.Owner, User: user.Name})
+	if err != nil {
+		panic(err)
+	}
+}
+
+// notifyOrganizationAdmins emails the admins of the organization with the
+// email provider of its default application.
+func notifyOrganizationAdmins(organization string, title string, content string) {
+	application, err := GetDefaultApplication(util.GetId("admin", organization))
+	if err != nil {
+		return
+	}
+	provider := application.GetEmailProvider()
+	if provider == nil {
+		return
+	}
+
+	for _, user := range GetUsers(organization) {
+		if !user.IsAdmin || user.IsForbidden || user.IsDeleted || user.Email == "" {
+			continue
+		}
+
+		err = SendEmail(provider, title, content, user.Email, provider.DisplayName)
+		if err != nil {
+			logs.Warning(fmt.Sprintf("failed to notify %s, %s", user.GetId(), err.Error()))
+		}
+	}
+}
+
+// ReportSecurityEvent handles "this wasn't me" for an event of the user: all
+// the sessions and tokens of the user are revoked and the admins of the
+// organization are notified. The report itself is the record of the API call.
+func ReportSecurityEvent(user *User, id string, lang string) (*SecurityEvent, error) {
+	event := getSecurityEvent(user, id)
+	if event == nil {
+		return nil, fmt.Errorf(i18n.Translate(lang, "security:The security event: %s does not exist"), id)
+	}
+
+	RevokeUserSessions(user)
+	logs.Warning(fmt.Sprintf("the user: %s reported the %s event: %s, the sessions are revoked", user.GetId(), event.Type, event.Id))
+
+	title := fmt.Sprintf("Security event reported by %s", user.GetId())
+	content := fmt.Sprintf("The user %s reported that they didn't do the %s event at %s (IP: %s, device: %s, location: %s, application: %s). All the sessions and tokens of the user have been revoked.",
+		user.GetId(), event.Type, event.CreatedTime, event.ClientIp, event.Device, event.Location, event.Application)
+	util.SafeGoroutine(func() { notifyOrganizationAdmins(user.Owner, title, content) })
+
+	event.IsReported = true
+	return event, nil
+}
@@ -0,0 +1,154 @@
+// Copyright 2023 The Casdoor Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package object
+
+import (
+	"fmt"
+	"net/http"
+	"sync"
+
+	"github.com/beego/beego/session"
+	"github.com/gomodule/redigo/redis"
+)
+
+// RedisSessionProvider stores the sessions in Redis through redisClient, so
+// that Sentinel, Cluster, TLS and ACL users work the same for sessions. The
+// keys are the session IDs like the "redis" provider of beego, the sessions
+// stored by it are kept.
+const RedisSessionProvider = "casdoor-redis"
+
+type redisSessionStore struct {
+	client      *redisClient
+	sid         string
+	lock        sync.RWMutex
+	values      map[interface{}]interface{}
+	maxlifetime int64
+}
+
+func (store *redisSessionStore) Set(key, value interface{}) error {
+	store.lock.Lock()
+	defer store.lock.Unlock()
+	store.values[key] = value
+	return nil
+}
+
+func (store *redisSessionStore) Get(key interface{}) interface{} {
+	store.lock.RLock()
+	defer store.lock.RUnlock()
+	return store.values[key]
+}
+
+func (store *redisSessionStore) Delete(key interface{}) error {
+	store.lock.Lock()
+	defer store.lock.Unlock()
+	delete(store.values, key)
+	return nil
+}
+
+func (store *redisSessionStore) Flush() error {
+	store.lock.Lock()
+	defer store.lock.Unlock()
+	store.values = map[interface{}]interface{}{}
+	return nil
+}
+
+func (store *redisSessionStore) SessionID() string {
+	return store.sid
+}
+
+func (store *redisSessionStore) SessionRelease(w http.ResponseWriter) {
+	store.lock.RLock()
+	data, err := session.EncodeGob(store.values)
+	store.lock.RUnlock()
+	if err != nil {
+		return
+	}
+
+	_, _ = store.client.Do("SETEX", store.sid, store.maxlifetime, string(data))
+}
+
+type redisSessionProvider struct {
+	maxlifetime int64
+	client      *redisClient
+}
+
+// SessionInit ignores the config, the connection comes from redisEndpoint
+// and the other Redis settings.
+func (provider *redisSessionProvider) SessionInit(maxlifetime int64, config string) error {
+	provider.maxlifetime = maxlifetime
+	provider.client = getRedisClient()
+	if provider.client == nil {
+		return fmt.Errorf("redisEndpoint isn't configured")
+	}
+
+	_, err := provider.client.Do("PING")
+	return err
+}
+
+func (provider *redisSessionProvider) SessionRead(sid string) (session.Store, error) {
+	values := map[interface{}]interface{}{}
+	data, err := redis.Bytes(provider.client.Do("GET", sid))
+	if err != nil && err != redis.ErrNil {
+		return nil, err
+	}
+	if len(data) != 0 {
+		values, err = session.DecodeGob(data)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return &redisSessionStore{client: provider.client, sid: sid, values: values, maxlifetime: provider.maxlifetime}, nil
+}
+
+func (provider *redisSessionProvider) SessionExist(sid string) bool {
+	existed, err := redis.Int(provider.client.Do("EXISTS", sid))
+	return err == nil && existed != 0
+}
+
+// SessionRegenerate copies the session instead of RENAME, which fails in a
+// cluster when the two IDs are in different slots.
+func (provider *redisSessionProvider) SessionRegenerate(oldsid, sid string) (session.Store, error) {
+	data, err := redis.Bytes(provider.client.Do("GET", oldsid))
+	if err != nil && err != redis.ErrNil {
+		return nil, err
+	}
+
+	_, err = provider.client.Do("SETEX", sid, provider.maxlifetime, data)
+	if err != nil {
+		return nil, err
+	}
+	if len(data) != 0 {
+		_, _ = provider.client.Do("DEL", oldsid)
+	}
+	return provider.SessionRead(sid)
+}
+
+func (provider *redisSessionProvider) SessionDestroy(sid string) error {
+	_, err := provider.client.Do("DEL", sid)
+	return err
+}
+
+// SessionAll isn't counted, Redis expires the sessions itself.
+func (provider *redisSessionProvider) SessionAll() int {
+	return 0
+}
+
+func (provider *redisSessionProvider) SessionGC() {
+}
+
+func init() {
+	session.Register(RedisSessionProvider, &redisSessionProvider{})
+}
@@ -0,0 +1,242 @@
+// Copyright 2023 The Casdoor Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package object
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/casdoor/casdoor/conf"
+	"github.com/casdoor/casdoor/proxy"
+	"github.com/casdoor/casdoor/util"
+	"github.com/xorm-io/core"
+)
+
+// settingReloadInterval is how long the other nodes of a cluster take to
+// pick up a changed setting.
+const settingReloadInterval = 10 * time.Second
+
+// Setting is a runtime value of an app.conf key, it takes effect without a
+// restart and wins over app.conf and the environment.
+type Setting struct {
+	Owner       string `xorm:"varchar(100) notnull pk" json:"owner"`
+	Name        string `xorm:"varchar(100) notnull pk" json:"name"`
+	CreatedTime string `xorm:"varchar(100)" json:"createdTime"`
+	UpdatedTime string `xorm:"varchar(100)" json:"updatedTime"`
+
+	Value     string `xorm:"mediumtext" json:"value"`
+	UpdatedBy string `xorm:"varchar(100)" json:"updatedBy"`
+}
+
+// SettingChange is the audit log entry of a setting that was set or reset.
+type SettingChange struct {
+	Owner       string `xorm:"varchar(100) notnull pk" json:"owner"`
+	Name        string `xorm:"varchar(100) notnull pk" json:"name"`
+	CreatedTime string `xorm:"varchar(100) index" json:"createdTime"`
+
+	Setting  string `xorm:"varchar(100) index" json:"setting"`
+	OldValue string `xorm:"mediumtext" json:"oldValue"`
+	NewValue string `xorm:"mediumtext" json:"newValue"`
+	IsReset  bool   `json:"isReset"`
+	User     string `xorm:"varchar(100)" json:"user"`
+}
+
+// SettingItem is a runtime setting as shown by the API, with the value it
+// falls back to when it isn't set.
+type SettingItem struct {
+	Name        string `json:"name"`
+	Type        string `json:"type"`
+	Value       string `json:"value"`
+	StaticValue string `json:"staticValue"`
+	IsSet       bool   `json:"isSet"`
+	UpdatedTime string `json:"updatedTime"`
+	UpdatedBy   string `json:"updatedBy"`
+}
+
+func getSettings() []*Setting {
+	settings := []*Setting{}
+	err := adapter.Engine.Find(&settings, &Setting{Owner: "admin"})
+	if err != nil {
+		panic(err)
+	}
+
+	return settings
+}
+
+func GetSettingItems() []*SettingItem {
+	settingMap := map[string]*Setting{}
+	for _, setting := range getSettings() {
+		settingMap[setting.Name] = setting
+	}
+
+	items := []*SettingItem{}
+	for name, typ := range conf.RuntimeSettingTypes {
+		item := &SettingItem{
+			Name:        name,
+			Type:        typ,
+			StaticValue: conf.GetStaticConfigString(name),
+		}
+		item.Value = item.StaticValue
+		if setting, ok := settingMap[name]; ok {
+			item.Value = setting.Value
+			item.IsSet = true
+			item.UpdatedTime = setting.UpdatedTime
+			item.UpdatedBy = setting.UpdatedBy
+		}
+		items = append(items, item)
+	}
+
+	sort.Slice(items, func(i, j int) bool {
+		return items[i].Name < items[j].Name
+	})
+	return items
+}
+
+func GetSettingChanges(limit int) []*SettingChange {
+	changes := []*SettingChange{}
+	session := adapter.Engine.Desc("created_time")
+	if limit > 0 {
+		session = session.Limit(limit)
+	}
+	err := session.Find(&changes, &SettingChange{Owner: "admin"})
+	if err != nil {
+		panic(err)
+	}
+
+	return changes
+}
+
+func checkSettingValue(name string, value string) error {
+	typ, ok := conf.RuntimeSettingTypes[name]
+	if !ok {
+		return fmt.Errorf("the setting: %s can't be changed at runtime", name)
+	}
+
+	var err error
+	switch typ {
+	case "bool":
+		if value != "true" && value != "false" {
+			err = fmt.Errorf("should be true or false")
+		}
+	case "int":
+		_, err = strconv.Atoi(value)
+	case "json":
+		if !json.Valid([]byte(value)) {
+			err = fmt.Errorf("should be valid JSON")
+		}
+	}
+
+	if err != nil {
+		return fmt.Errorf("invalid value for the setting: %s, %s", name, err.Error())
+	}
+	return nil
+}
+
+// UpdateSettings sets the settings to the values, a nil value resets the
+// setting to app.conf. Every change is recorded with the user who made it.
+func UpdateSettings(values map[string]*string, user string) error {
+	for name, value := range values {
+		if value == nil {
+			if _, ok := conf.RuntimeSettingTypes[name]; !ok {
+				return fmt.Errorf("the setting: %s can't be changed at runtime", name)
+			}
+		} else if err := checkSettingValue(name, *value); err != nil {
+			return err
+		}
+	}
+
+	currentTime := util.GetCurrentTime()
+	for name, value := range values {
+		oldSetting := &Setting{Owner: "admin", Name: name}
+		existed, err := adapter.Engine.Get(oldSetting)
+		if err != nil {
+			panic(err)
+		}
+
+		change := &SettingChange{
+			Owner:       "admin",
+			Name:        util.GenerateId(),
+			CreatedTime: currentTime,
+			Setting:     name,
+			OldValue:    conf.GetConfigString(name),
+			User:        user,
+		}
+
+		if value == nil {
+			if !existed {
+				continue
+			}
+
+			_, err = adapter.Engine.ID(core.PK{"admin", name}).Delete(&Setting{})
+			change.IsReset = true
+			change.NewValue = conf.GetStaticConfigString(name)
+		} else {
+			if existed && oldSetting.Value == *value {
+				continue
+			}
+
+			setting := &Setting{Owner: "admin", Name: name, CreatedTime: currentTime, UpdatedTime: currentTime, Value: *value, UpdatedBy: user}
+			if existed {
+				setting.CreatedTime = oldSetting.CreatedTime
+				_, err = adapter.Engine.ID(core.PK{"admin", name}).AllCols().Update(setting)
+			} else {
+				_, err = adapter.Engine.Insert(setting)
+			}
+			change.NewValue = *value
+		}
+		if err != nil {
+			panic(err)
+		}
+
+		_, err = adapter.Engine.Insert(change)
+		if err != nil {
+			panic(err)
+		}
+	}
+
+	ReloadSettings()
+	return nil
+}
+
+// ReloadSettings applies the settings stored in the database to this node.
+func ReloadSettings() {
+	settings := map[string]string{}
+	for _, setting := range getSettings() {
+		if _, ok := conf.RuntimeSettingTypes[setting.Name]; ok {
+			settings[setting.Name] = setting.Value
+		}
+	}
+
+	oldSocks5Proxy := conf.GetConfigString("socks5Proxy")
+	conf.SetRuntimeSettings(settings)
+	if conf.GetConfigString("socks5Proxy") != oldSocks5Proxy {
+		proxy.InitHttpClient()
+	}
+}
+
+// InitRuntimeSettings loads the settings and keeps reloading them, so that
+// a change made on another node takes effect here too.
+func InitRuntimeSettings() {
+	ReloadSettings()
+
+	go func() {
+		for range time.Tick(settingReloadInterval) {
+			util.SafeGoroutine(ReloadSettings)
+		}
+	}()
+}
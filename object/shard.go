@@ -0,0 +1,340 @@
+// Copyright 2023 The Casdoor Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package object
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+
+	"github.com/casdoor/casdoor/conf"
+	"github.com/casdoor/casdoor/util"
+	"github.com/xorm-io/core"
+	"github.com/xorm-io/xorm"
+)
+
+// Shard is a database that the users of some organizations are stored in
+// instead of the main one, e.g. to keep them in a jurisdiction. The shards
+// are configured by the "shards" key of app.conf as a JSON list.
+type Shard struct {
+	Name           string `json:"name"`
+	Region         string `json:"region"`
+	DriverName     string `json:"driverName"`
+	DataSourceName string `json:"dataSourceName"`
+	DbName         string `json:"dbName"`
+}
+
+type ShardInfo struct {
+	Name          string   `json:"name"`
+	Region        string   `json:"region"`
+	DriverName    string   `json:"driverName"`
+	DbName        string   `json:"dbName"`
+	Organizations []string `json:"organizations"`
+	UserCount     int      `json:"userCount"`
+}
+
+type ShardMoveResult struct {
+	Organization string `json:"organization"`
+	From         string `json:"from"`
+	To           string `json:"to"`
+	Users        int    `json:"users"`
+}
+
+var (
+	shards        []*Shard
+	shardAdapters = map[string]*Adapter{}
+)
+
+func initShards() {
+	shards = []*Shard{}
+	shardAdapters = map[string]*Adapter{}
+
+	value := conf.GetStaticConfigString("shards")
+	if value == "" {
+		return
+	}
+	err := json.Unmarshal([]byte(value), &shards)
+	if err != nil {
+		panic(fmt.Errorf("the shards of app.conf are invalid, %s", err.Error()))
+	}
+
+	for _, shard := range shards {
+		if shard.Name == "" || shard.DriverName == "" || shard.DataSourceName == "" {
+			panic(fmt.Errorf("the shard: %s must have a name, driverName and dataSourceName", shard.Name))
+		}
+		if _, ok := shardAdapters[shard.Name]; ok {
+			panic(fmt.Errorf("the shard: %s is configured twice", shard.Name))
+		}
+		shardAdapters[shard.Name] = NewAdapter(shard.DriverName, shard.DataSourceName, shard.DbName)
+	}
+}
+
+func createShardTables(createDatabase bool) {
+	for _, shard := range shards {
+		a := shardAdapters[shard.Name]
+		if createDatabase {
+			a.CreateDatabase()
+		}
+
+		a.setEngineOptions()
+		err := a.Engine.Sync2(new(User))
+		if err != nil {
+			panic(err)
+		}
+	}
+}
+
+func getShard(name string) *Shard {
+	for _, shard := range shards {
+		if shard.Name == name {
+			return shard
+		}
+	}
+	return nil
+}
+
+// getOrganizationShard returns the shard of the organization, empty for the
+// main database.
+func getOrganizationShard(organization string) string {
+	if len(shards) == 0 || organization == "" {
+		return ""
+	}
+
+	org := getOrganization("admin", organization)
+	if org == nil {
+		return ""
+	}
+	return org.Shard
+}
+
+// getUserEngine returns the engine of the database that the users of the
+// organization are stored in.
+func getUserEngine(organization string) *xorm.Engine {
+	return getShardEngine(getOrganizationShard(organization))
+}
+
+func getShardEngine(shard string) *xorm.Engine {
+	if shard == "" {
+		return adapter.Engine
+	}
+
+	a, ok := shardAdapters[shard]
+	if !ok {
+		panic(fmt.Errorf("the shard: %s is not configured in app.conf", shard))
+	}
+	return a.Engine
+}
+
+// getUserEngines returns the main database first and then every shard, for
+// the queries on the users of all organizations.
+func getUserEngines() []*xorm.Engine {
+	engines := []*xorm.Engine{adapter.Engine}
+	for _, shard := range shards {
+		engines = append(engines, shardAdapters[shard.Name].Engine)
+	}
+	return engines
+}
+
+// getUsersEngine returns the engine that all the users are stored in, the
+// users stored elsewhere get an error as a batch runs in one transaction.
+func getUsersEngine(users []*User, results []*BatchResult) *xorm.Engine {
+	if len(users) == 0 {
+		return adapter.Engine
+	}
+
+	shard := getOrganizationShard(users[0].Owner)
+	for i, user := range users {
+		if getOrganizationShard(user.Owner) != shard {
+			setBatchError(results[i], fmt.Sprintf("the user: %s is stored in another database than the user: %s", user.GetId(), users[0].GetId()))
+		}
+	}
+	return getShardEngine(shard)
+}
+
+// sortUsers sorts the users of several databases like GetSession does.
+func sortUsers(users []*User, sortField string, sortOrder string) {
+	if sortField == "" || sortOrder == "" {
+		sortField = "created_time"
+	}
+	column := util.SnakeString(sortField)
+
+	index := -1
+	userType := reflect.TypeOf(User{})
+	for i := 0; i < userType.NumField(); i++ {
+		if util.SnakeString(userType.Field(i).Name) == column {
+			index = i
+			break
+		}
+	}
+	if index == -1 {
+		return
+	}
+
+	less := func(a reflect.Value, b reflect.Value) bool {
+		switch a.Kind() {
+		case reflect.Int, reflect.Int64:
+			return a.Int() < b.Int()
+		case reflect.Float64:
+			return a.Float() < b.Float()
+		case reflect.Bool:
+			return !a.Bool() && b.Bool()
+		default:
+			return fmt.Sprint(a.Interface()) < fmt.Sprint(b.Interface())
+		}
+	}
+	sort.SliceStable(users, func(i, j int) bool {
+		a := reflect.ValueOf(users[i]).Elem().Field(index)
+		b := reflect.ValueOf(users[j]).Elem().Field(index)
+		if sortOrder == "ascend" {
+			return less(a, b)
+		}
+		return less(b, a)
+	})
+}
+
+func GetShards() []*ShardInfo {
+	res := []*ShardInfo{{Name: "", Region: "", DriverName: adapter.driverName, DbName: adapter.dbName, Organizations: []string{}}}
+	for _, shard := range shards {
+		res = append(res, &ShardInfo{Name: shard.Name, Region: shard.Region, DriverName: shard.DriverName, DbName: shard.DbName, Organizations: []string{}})
+	}
+
+	for _, organization := range GetOrganizations("admin") {
+		for _, info := range res {
+			if info.Name == organization.Shard {
+				info.Organizations = append(info.Organizations, organization.Name)
+			}
+		}
+	}
+
+	for _, info := range res {
+		count, err := getShardEngine(info.Name).Count(&User{})
+		if err != nil {
+			panic(err)
+		}
+		info.UserCount = int(count)
+	}
+	return res
+}
+
+// MoveOrganizationShard copies the users of the organization to the target
+// shard (empty for the main database), switches the organization to it and
+// then deletes the users from the source. It needs the maintenance mode so
+// that no logins, signups or jobs change the users while they are copied,
+// and the source is only deleted when both sides still have the same user
+// count as the copy.
+func MoveOrganizationShard(organization string, target string) (*ShardMoveResult, error) {
+	org := getOrganization("admin", organization)
+	if org == nil {
+		return nil, fmt.Errorf("the organization: %s doesn't exist", organization)
+	}
+	if !IsShardConfigured(target) {
+		return nil, fmt.Errorf("the shard: %s is not configured in app.conf", target)
+	}
+	if !IsMaintenanceMode() {
+		return nil, fmt.Errorf("the maintenance mode has to be enabled to move the organization: %s", organization)
+	}
+
+	result := &ShardMoveResult{Organization: organization, From: org.Shard, To: target}
+	if org.Shard == target {
+		return result, nil
+	}
+
+	source := getShardEngine(org.Shard)
+	destination := getShardEngine(target)
+
+	count, err := destination.Count(&User{Owner: organization})
+	if err != nil {
+		return nil, err
+	}
+	if count != 0 {
+		return nil, fmt.Errorf("the shard: %s already has %d users of the organization: %s", target, count, organization)
+	}
+
+	users := []*User{}
+	err = source.Find(&users, &User{Owner: organization})
+	if err != nil {
+		return nil, err
+	}
+
+	session := destination.NewSession()
+	defer session.Close()
+	err = session.Begin()
+	if err != nil {
+		return nil, err
+	}
+	batchSize := conf.GetConfigBatchSize()
+	for i := 0; i < len(users); i += batchSize {
+		end := i + batchSize
+		if end > len(users) {
+			end = len(users)
+		}
+		_, err = session.Insert(users[i:end])
+		if err != nil {
+			_ = session.Rollback()
+			return nil, err
+		}
+	}
+	err = session.Commit()
+	if err != nil {
+		return nil, err
+	}
+
+	err = checkShardMoveCount(source, destination, organization, len(users))
+	if err != nil {
+		_, _ = destination.Where("owner = ?", organization).Delete(&User{})
+		return nil, err
+	}
+
+	_, err = adapter.Engine.ID(core.PK{org.Owner, org.Name}).Cols("shard").Incr("version").Update(&Organization{Shard: target})
+	if err != nil {
+		_, _ = destination.Where("owner = ?", organization).Delete(&User{})
+		return nil, err
+	}
+	deleteCachedObjects(getSharedCacheKey("organization", util.GetId(org.Owner, org.Name)))
+
+	_, err = source.Where("owner = ?", organization).Delete(&User{})
+	if err != nil {
+		return nil, fmt.Errorf("the users are moved but not deleted from the source, %s", err.Error())
+	}
+
+	result.Users = len(users)
+	return result, nil
+}
+
+// checkShardMoveCount returns an error when the source or the destination
+// doesn't have the copied number of users, as the source has been changed
+// during the copy.
+func checkShardMoveCount(source *xorm.Engine, destination *xorm.Engine, organization string, count int) error {
+	sourceCount, err := source.Count(&User{Owner: organization})
+	if err != nil {
+		return err
+	}
+	destinationCount, err := destination.Count(&User{Owner: organization})
+	if err != nil {
+		return err
+	}
+
+	if int(sourceCount) != count || int(destinationCount) != count {
+		return fmt.Errorf("the users of the organization: %s are changed during the move, %d users are copied but the source has %d and the shard has %d", organization, count, sourceCount, destinationCount)
+	}
+	return nil
+}
+
+// IsShardConfigured returns whether the shard is in app.conf, empty is the
+// main database.
+func IsShardConfigured(name string) bool {
+	return name == "" || getShard(name) != nil
+}
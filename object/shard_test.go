@@ -0,0 +1,54 @@
+// Copyright 2023 The Casdoor Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package object
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSortUsers(t *testing.T) {
+	scenarios := []struct {
+		description string
+		sortField   string
+		sortOrder   string
+		expected    []string
+	}{
+		{"Default", "", "", []string{"carol", "alice", "bob"}},
+		{"Created time ascend", "createdTime", "ascend", []string{"bob", "alice", "carol"}},
+		{"Name descend", "name", "descend", []string{"carol", "bob", "alice"}},
+		{"Score ascend", "score", "ascend", []string{"carol", "bob", "alice"}},
+		{"Boolean descend", "isAdmin", "descend", []string{"bob", "alice", "carol"}},
+		{"Unknown field", "unknown", "ascend", []string{"alice", "bob", "carol"}},
+	}
+
+	for _, scenery := range scenarios {
+		t.Run(scenery.description, func(t *testing.T) {
+			users := []*User{
+				{Name: "alice", CreatedTime: "2023-02-01T00:00:00Z", Score: 30},
+				{Name: "bob", CreatedTime: "2023-01-01T00:00:00Z", Score: 20, IsAdmin: true},
+				{Name: "carol", CreatedTime: "2023-03-01T00:00:00Z", Score: 10},
+			}
+			sortUsers(users, scenery.sortField, scenery.sortOrder)
+
+			names := []string{}
+			for _, user := range users {
+				names = append(names, user.Name)
+			}
+			assert.Equal(t, scenery.expected, names)
+		})
+	}
+}
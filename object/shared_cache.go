@@ -0,0 +1,143 @@
+// Copyright 2023 The Casdoor Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package object
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/casdoor/casdoor/conf"
+	"github.com/gomodule/redigo/redis"
+)
+
+// The shared cache keeps hot objects in Redis for all the nodes: the JWKS,
+// applications (the rows, before the providers and the organization are
+// added), certs, organizations and providers. Writes through the object
+// functions drop the keys, what is missed is bounded by sharedCacheTtl. The
+// in-process cache of object_cache.go sits in front of it.
+
+const sharedCacheJwksKey = "casdoor:cache:jwks"
+
+// getSharedCacheTtl returns the seconds an object is cached, 0 disables the
+// shared cache, which is also off without redisEndpoint.
+func getSharedCacheTtl() int64 {
+	ttl, err := conf.GetConfigInt64("sharedCacheTtl")
+	if err != nil {
+		ttl = 300
+	}
+	return ttl
+}
+
+func getSharedCacheClient() *redisClient {
+	if getSharedCacheTtl() <= 0 {
+		return nil
+	}
+	return getRedisClient()
+}
+
+func getSharedCacheKey(typ string, id string) string {
+	return fmt.Sprintf("casdoor:cache:%s:%s", typ, id)
+}
+
+// getSharedCache reads the cached object into obj, an unavailable Redis is
+// the same as a miss so that reads fall back to the database.
+func getSharedCache(key string, obj interface{}) bool {
+	client := getSharedCacheClient()
+	if client == nil {
+		return false
+	}
+
+	data, err := redis.Bytes(client.Do("GET", key))
+	if err != nil {
+		return false
+	}
+	return json.Unmarshal(data, obj) == nil
+}
+
+func setSharedCache(key string, obj interface{}) {
+	client := getSharedCacheClient()
+	if client == nil {
+		return
+	}
+
+	data, err := json.Marshal(obj)
+	if err != nil {
+		return
+	}
+	_, _ = client.Do("SETEX", key, getSharedCacheTtl(), data)
+}
+
+// deleteSharedCache deletes the keys one by one, as they may be in different
+// slots of a cluster.
+func deleteSharedCache(keys ...string) {
+	client := getSharedCacheClient()
+	if client == nil {
+		return
+	}
+
+	for _, key := range keys {
+		_, _ = client.Do("DEL", key)
+	}
+}
+
+// flushSharedCache deletes all the keys of the shared cache. Only the nodes
+// in redisEndpoint are scanned for a cluster, so all its masters should be
+// listed there.
+func flushSharedCache() error {
+	client := getSharedCacheClient()
+	if client == nil {
+		return nil
+	}
+
+	addrs := client.config.Addrs
+	if client.config.Mode != RedisModeCluster {
+		addr, err := client.getAddr("")
+		if err != nil {
+			return err
+		}
+		addrs = []string{addr}
+	}
+
+	for _, addr := range addrs {
+		err := flushSharedCacheOfNode(client, addr)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func flushSharedCacheOfNode(client *redisClient, addr string) error {
+	conn := client.getPool(addr).Get()
+	defer conn.Close()
+
+	cursor := 0
+	for {
+		res, err := redis.Values(conn.Do("SCAN", cursor, "MATCH", "casdoor:cache:*", "COUNT", 100))
+		if err != nil {
+			return err
+		}
+		if len(res) != 2 {
+			return fmt.Errorf("unexpected reply of SCAN from: %s", addr)
+		}
+
+		cursor, _ = redis.Int(res[0], nil)
+		keys, _ := redis.Strings(res[1], nil)
+		deleteSharedCache(keys...)
+		if cursor == 0 {
+			return nil
+		}
+	}
+}
@@ -0,0 +1,290 @@
+// Copyright 2023 The Casdoor Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package object
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/binary"
+	"encoding/pem"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/casdoor/casdoor/i18n"
+	"github.com/casdoor/casdoor/util"
+	"golang.org/x/crypto/ssh"
+)
+
+const (
+	CertTypeSshCa = "SSH CA"
+
+	defaultSshExpireInMinutes = 8 * 60
+
+	// the certificates are valid a little before they are issued, for
+	// servers whose clock is behind
+	sshCertificateClockSkew = time.Minute
+)
+
+// SshCertificate records a user certificate signed by the SSH CA of an
+// organization, Name is the serial of the certificate.
+type SshCertificate struct {
+	Owner       string `xorm:"varchar(100) notnull pk" json:"owner"`
+	Name        string `xorm:"varchar(100) notnull pk" json:"name"`
+	CreatedTime string `xorm:"varchar(100)" json:"createdTime"`
+
+	User        string   `xorm:"varchar(100) index" json:"user"`
+	Ca          string   `xorm:"varchar(100)" json:"ca"`
+	KeyId       string   `xorm:"varchar(200)" json:"keyId"`
+	Principals  []string `xorm:"varchar(1000)" json:"principals"`
+	Fingerprint string   `xorm:"varchar(100)" json:"fingerprint"`
+	ValidAfter  string   `xorm:"varchar(100)" json:"validAfter"`
+	ValidBefore string   `xorm:"varchar(100)" json:"validBefore"`
+	ClientIp    string   `xorm:"varchar(100)" json:"clientIp"`
+}
+
+func GetSshCertificateCount(owner, field, value string) int {
+	session := GetSession(owner, -1, -1, field, value, "", "")
+	count, err := session.Count(&SshCertificate{})
+	if err != nil {
+		panic(err)
+	}
+
+	return int(count)
+}
+
+func GetSshCertificates(owner string) []*SshCertificate {
+	sshCertificates := []*SshCertificate{}
+	err := adapter.Engine.Desc("created_time").Find(&sshCertificates, &SshCertificate{Owner: owner})
+	if err != nil {
+		panic(err)
+	}
+
+	return sshCertificates
+}
+
+func GetPaginationSshCertificates(owner string, offset, limit int, field, value, sortField, sortOrder string) []*SshCertificate {
+	sshCertificates := []*SshCertificate{}
+	session := GetSession(owner, offset, limit, field, value, sortField, sortOrder)
+	err := session.Find(&sshCertificates)
+	if err != nil {
+		panic(err)
+	}
+
+	return sshCertificates
+}
+
+func AddSshCertificate(sshCertificate *SshCertificate) bool {
+	affected, err := adapter.Engine.Insert(sshCertificate)
+	if err != nil {
+		panic(err)
+	}
+
+	return affected != 0
+}
+
+// generateSshCaKeys generates the key of an SSH CA. The certificate is the
+// public key in the authorized_keys format, as sshd's TrustedUserCAKeys
+// expects it.
+func generateSshCaKeys(cryptoAlgorithm string, bitSize int) (string, string, error) {
+	var key interface{}
+	var err error
+	if cryptoAlgorithm == "Ed25519" {
+		_, key, err = ed25519.GenerateKey(rand.Reader)
+	} else {
+		if bitSize <= 0 {
+			bitSize = 4096
+		}
+		key, err = rsa.GenerateKey(rand.Reader, bitSize)
+	}
+	if err != nil {
+		return "", "", err
+	}
+
+	signer, err := ssh.NewSignerFromKey(key)
+	if err != nil {
+		return "", "", err
+	}
+	privateKeyBytes, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		return "", "", err
+	}
+
+	publicKey := strings.TrimSpace(string(ssh.MarshalAuthorizedKey(signer.PublicKey())))
+	privateKey := string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: privateKeyBytes}))
+	return publicKey, privateKey, nil
+}
+
+// getSshPrincipals returns the names of the roles of the user, after the user
+// name if the CA allows it, the accounts the certificate may log in as.
+func getSshPrincipals(cert *Cert, user *User) []string {
+	principals := []string{}
+	if cert.SshUserPrincipal {
+		principals = append(principals, user.Name)
+	}
+	for _, role := range user.Roles {
+		if !util.ContainsString(principals, role.Name) {
+			principals = append(principals, role.Name)
+		}
+	}
+	return principals
+}
+
+// getSshCertificateTtl returns the requested lifetime capped to the maximum
+// of the CA, or the default of the CA when none is requested.
+func getSshCertificateTtl(cert *Cert, expireInMinutes int) time.Duration {
+	defaultMinutes := cert.SshExpireInMinutes
+	if defaultMinutes <= 0 {
+		defaultMinutes = defaultSshExpireInMinutes
+	}
+	maxMinutes := cert.SshMaxExpireInMinutes
+	if maxMinutes <= 0 {
+		maxMinutes = defaultMinutes
+	}
+
+	minutes := expireInMinutes
+	if minutes <= 0 {
+		minutes = defaultMinutes
+	}
+	if minutes > maxMinutes {
+		minutes = maxMinutes
+	}
+	return time.Duration(minutes) * time.Minute
+}
+
+func signSshCertificate(signer ssh.Signer, publicKey ssh.PublicKey, serial uint64, keyId string, principals []string, validAfter time.Time, validBefore time.Time) (*ssh.Certificate, error) {
+	certificate := &ssh.Certificate{
+		Key:             publicKey,
+		Serial:          serial,
+		CertType:        ssh.UserCert,
+		KeyId:           keyId,
+		ValidPrincipals: principals,
+		ValidAfter:      uint64(validAfter.Unix()),
+		ValidBefore:     uint64(validBefore.Unix()),
+		Permissions: ssh.Permissions{
+			Extensions: map[string]string{
+				"permit-X11-forwarding":   "",
+				"permit-agent-forwarding": "",
+				"permit-port-forwarding":  "",
+				"permit-pty":              "",
+				"permit-user-rc":          "",
+			},
+		},
+	}
+
+	err := certificate.SignCert(rand.Reader, signer)
+	if err != nil {
+		return nil, err
+	}
+	return certificate, nil
+}
+
+func getSshCertificateSerial() uint64 {
+	buffer := make([]byte, 8)
+	_, err := rand.Read(buffer)
+	if err != nil {
+		panic(err)
+	}
+	return binary.BigEndian.Uint64(buffer)
+}
+
+// CheckOrganizationCa returns an error message when the SSH CA or the device
+// CA of the organization is changed from oldOrganization (nil when added) to
+// a cert that isn't a CA of that type bound to the organization.
+func CheckOrganizationCa(organization *Organization, oldOrganization *Organization, lang string) string {
+	oldSshCa, oldDeviceCa := "", ""
+	if oldOrganization != nil {
+		oldSshCa, oldDeviceCa = oldOrganization.SshCa, oldOrganization.DeviceCa
+	}
+
+	if organization.SshCa != "" && organization.SshCa != oldSshCa && !isOrganizationCa(organization, organization.SshCa, CertTypeSshCa) {
+		return fmt.Sprintf(i18n.Translate(lang, "organization:The cert: %s is not an SSH CA of the organization"), organization.SshCa)
+	}
+	if organization.DeviceCa != "" && organization.DeviceCa != oldDeviceCa && !isOrganizationCa(organization, organization.DeviceCa, CertTypeDeviceCa) {
+		return fmt.Sprintf(i18n.Translate(lang, "organization:The cert: %s is not a device CA of the organization"), organization.DeviceCa)
+	}
+	return ""
+}
+
+func isOrganizationCa(organization *Organization, name string, typ string) bool {
+	cert := getCert("admin", name)
+	return cert != nil && cert.Type == typ && cert.Organization == organization.Name
+}
+
+// IssueSshCertificate signs the public key of the user with the SSH CA of
+// the organization of the user and records the issued certificate.
+func IssueSshCertificate(user *User, publicKey string, expireInMinutes int, clientIp string) (string, *SshCertificate, error) {
+	if user.IsForbidden {
+		return "", nil, fmt.Errorf("the user: %s is forbidden", user.GetId())
+	}
+
+	organization := getOrganization("admin", user.Owner)
+	if organization == nil || organization.SshCa == "" {
+		return "", nil, fmt.Errorf("the organization: %s has no SSH CA", user.Owner)
+	}
+	cert := getCert("admin", organization.SshCa)
+	if cert == nil || cert.Type != CertTypeSshCa {
+		return "", nil, fmt.Errorf("the cert: %s is not an SSH CA", organization.SshCa)
+	}
+	if cert.Organization != organization.Name {
+		return "", nil, fmt.Errorf("the SSH CA: %s doesn't belong to the organization: %s", cert.Name, organization.Name)
+	}
+	signer, err := ssh.ParsePrivateKey([]byte(cert.PrivateKey))
+	if err != nil {
+		return "", nil, fmt.Errorf("the private key of the SSH CA: %s is invalid, %s", cert.Name, err.Error())
+	}
+
+	key, _, _, _, err := ssh.ParseAuthorizedKey([]byte(publicKey))
+	if err != nil {
+		return "", nil, fmt.Errorf("the public key is invalid, %s", err.Error())
+	}
+	if _, ok := key.(*ssh.Certificate); ok {
+		return "", nil, fmt.Errorf("the public key must not be a certificate")
+	}
+
+	ExtendUserWithRolesAndPermissions(user)
+	principals := getSshPrincipals(cert, user)
+	// a certificate without principals is valid for any account
+	if len(principals) == 0 {
+		return "", nil, fmt.Errorf("the user: %s has no role to log in as", user.GetId())
+	}
+	now := time.Now()
+	validBefore := now.Add(getSshCertificateTtl(cert, expireInMinutes))
+	serial := getSshCertificateSerial()
+	certificate, err := signSshCertificate(signer, key, serial, user.GetId(), principals, now.Add(-sshCertificateClockSkew), validBefore)
+	if err != nil {
+		return "", nil, err
+	}
+
+	sshCertificate := &SshCertificate{
+		Owner:       user.Owner,
+		Name:        strconv.FormatUint(serial, 10),
+		CreatedTime: util.GetCurrentTime(),
+		User:        user.Name,
+		Ca:          cert.Name,
+		KeyId:       certificate.KeyId,
+		Principals:  principals,
+		Fingerprint: ssh.FingerprintSHA256(key),
+		ValidAfter:  time.Unix(int64(certificate.ValidAfter), 0).Format(time.RFC3339),
+		ValidBefore: validBefore.Format(time.RFC3339),
+		ClientIp:    clientIp,
+	}
+	AddSshCertificate(sshCertificate)
+
+	return strings.TrimSpace(string(ssh.MarshalAuthorizedKey(certificate))), sshCertificate, nil
+}
@@ -0,0 +1,95 @@
+// Copyright 2023 The Casdoor Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package object
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/crypto/ssh"
+)
+
+func TestGetSshCertificateTtl(t *testing.T) {
+	scenarios := []struct {
+		description     string
+		cert            *Cert
+		expireInMinutes int
+		expected        time.Duration
+	}{
+		{"Built-in default", &Cert{}, 0, 8 * time.Hour},
+		{"Default of the CA", &Cert{SshExpireInMinutes: 60, SshMaxExpireInMinutes: 600}, 0, time.Hour},
+		{"Requested", &Cert{SshExpireInMinutes: 60, SshMaxExpireInMinutes: 600}, 30, 30 * time.Minute},
+		{"Capped to the maximum", &Cert{SshExpireInMinutes: 60, SshMaxExpireInMinutes: 600}, 6000, 10 * time.Hour},
+		{"Maximum defaults to the default", &Cert{SshExpireInMinutes: 60}, 120, time.Hour},
+	}
+
+	for _, scenery := range scenarios {
+		t.Run(scenery.description, func(t *testing.T) {
+			assert.Equal(t, scenery.expected, getSshCertificateTtl(scenery.cert, scenery.expireInMinutes))
+		})
+	}
+}
+
+func TestGetSshPrincipals(t *testing.T) {
+	user := &User{Name: "alice", Roles: []*Role{{Name: "ops"}, {Name: "alice"}, {Name: "db-admin"}}}
+	assert.Equal(t, []string{"ops", "alice", "db-admin"}, getSshPrincipals(&Cert{}, user))
+	assert.Equal(t, []string{"alice", "ops", "db-admin"}, getSshPrincipals(&Cert{SshUserPrincipal: true}, user))
+	assert.Equal(t, []string{}, getSshPrincipals(&Cert{}, &User{Name: "bob"}))
+}
+
+func TestSignSshCertificate(t *testing.T) {
+	for _, cryptoAlgorithm := range []string{"Ed25519", "RS256"} {
+		t.Run(cryptoAlgorithm, func(t *testing.T) {
+			caPublicKey, caPrivateKey, err := generateSshCaKeys(cryptoAlgorithm, 2048)
+			assert.Nil(t, err)
+			signer, err := ssh.ParsePrivateKey([]byte(caPrivateKey))
+			assert.Nil(t, err)
+			authority, _, _, _, err := ssh.ParseAuthorizedKey([]byte(caPublicKey))
+			assert.Nil(t, err)
+
+			userPublicKey, _, err := ed25519.GenerateKey(rand.Reader)
+			assert.Nil(t, err)
+			key, err := ssh.NewPublicKey(userPublicKey)
+			assert.Nil(t, err)
+
+			now := time.Now()
+			certificate, err := signSshCertificate(signer, key, 42, "org/alice", []string{"alice", "ops"}, now.Add(-time.Minute), now.Add(time.Hour))
+			assert.Nil(t, err)
+
+			checker := &ssh.CertChecker{
+				IsUserAuthority: func(auth ssh.PublicKey) bool {
+					return string(auth.Marshal()) == string(authority.Marshal())
+				},
+			}
+			_, err = checker.Authenticate(principalConn("ops"), certificate)
+			assert.Nil(t, err)
+			_, err = checker.Authenticate(principalConn("root"), certificate)
+			assert.NotNil(t, err)
+		})
+	}
+}
+
+type principalConn string
+
+func (c principalConn) User() string          { return string(c) }
+func (c principalConn) SessionID() []byte     { return nil }
+func (c principalConn) ClientVersion() []byte { return nil }
+func (c principalConn) ServerVersion() []byte { return nil }
+func (c principalConn) RemoteAddr() net.Addr  { return nil }
+func (c principalConn) LocalAddr() net.Addr   { return nil }
@@ -0,0 +1,333 @@
+// Copyright 2023 The Casdoor Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package object
+
+import (
+	"bytes"
+	"compress/flate"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/beevik/etree"
+	"github.com/casdoor/casdoor/util"
+	dsig "github.com/russellhaering/goxmldsig"
+)
+
+const (
+	SsoCheckTypeSaml = "saml"
+	SsoCheckTypeOidc = "oidc"
+
+	SsoCheckStatusOk     = "Ok"
+	SsoCheckStatusFailed = "Failed"
+
+	ssoCheckScope = "openid profile email"
+)
+
+var samlCheckAttributes = []string{"Email", "Name", "DisplayName", "Roles"}
+
+type SsoCheckStep struct {
+	Name    string `json:"name"`
+	Status  string `json:"status"`
+	Message string `json:"message"`
+}
+
+// SsoCheckResult is the outcome of a round trip against an application, the
+// steps stop at the first one that failed.
+type SsoCheckResult struct {
+	Type    string          `json:"type"`
+	Success bool            `json:"success"`
+	Steps   []*SsoCheckStep `json:"steps"`
+}
+
+func (result *SsoCheckResult) addStep(name string, message string, err error) bool {
+	step := &SsoCheckStep{Name: name, Status: SsoCheckStatusOk, Message: message}
+	if err != nil {
+		step.Status = SsoCheckStatusFailed
+		step.Message = err.Error()
+		result.Success = false
+	}
+	result.Steps = append(result.Steps, step)
+	return err == nil
+}
+
+func getSsoCheckRedirectUri(application *Application, redirectUri string) (string, error) {
+	if redirectUri != "" {
+		return redirectUri, nil
+	}
+	if len(application.RedirectUris) == 0 {
+		return "", fmt.Errorf("the application has no redirect URI")
+	}
+	return application.RedirectUris[0], nil
+}
+
+// CheckApplicationSso acts as a dummy SP (SAML) or RP (OIDC) of the
+// application and runs a full sign-in of the user through it.
+func CheckApplicationSso(application *Application, user *User, typ string, redirectUri string, host string, lang string) (*SsoCheckResult, error) {
+	redirectUri, err := getSsoCheckRedirectUri(application, redirectUri)
+	if err != nil {
+		return nil, err
+	}
+
+	switch typ {
+	case SsoCheckTypeSaml:
+		return checkApplicationSaml(application, user, redirectUri, host), nil
+	case SsoCheckTypeOidc:
+		return checkApplicationOidc(application, user, redirectUri, host, lang), nil
+	default:
+		return nil, fmt.Errorf("unsupported check type: %s", typ)
+	}
+}
+
+func newSamlCheckRequest(id string, issuer string, acsUrl string) (string, error) {
+	doc := etree.NewDocument()
+	request := doc.CreateElement("samlp:AuthnRequest")
+	request.CreateAttr("xmlns:samlp", "urn:oasis:names:tc:SAML:2.0:protocol")
+	request.CreateAttr("xmlns:saml", "urn:oasis:names:tc:SAML:2.0:assertion")
+	request.CreateAttr("ID", id)
+	request.CreateAttr("Version", "2.0")
+	request.CreateAttr("IssueInstant", time.Now().UTC().Format(time.RFC3339))
+	request.CreateAttr("ProtocolBinding", "urn:oasis:names:tc:SAML:2.0:bindings:HTTP-POST")
+	request.CreateAttr("AssertionConsumerServiceURL", acsUrl)
+	request.CreateElement("saml:Issuer").SetText(issuer)
+	xmlBytes, err := doc.WriteToBytes()
+	if err != nil {
+		return "", err
+	}
+
+	buffer := bytes.NewBuffer(nil)
+	writer, err := flate.NewWriter(buffer, flate.DefaultCompression)
+	if err != nil {
+		return "", err
+	}
+	_, err = writer.Write(xmlBytes)
+	if err != nil {
+		return "", err
+	}
+	err = writer.Close()
+	if err != nil {
+		return "", err
+	}
+
+	return base64.StdEncoding.EncodeToString(buffer.Bytes()), nil
+}
+
+func decodeSamlCheckResponse(samlResponse string, compressed bool) (*etree.Element, error) {
+	data, err := base64.StdEncoding.DecodeString(samlResponse)
+	if err != nil {
+		return nil, err
+	}
+
+	if compressed {
+		var buffer bytes.Buffer
+		_, err = io.Copy(&buffer, flate.NewReader(bytes.NewReader(data)))
+		if err != nil {
+			return nil, err
+		}
+		data = buffer.Bytes()
+	}
+
+	doc := etree.NewDocument()
+	err = doc.ReadFromBytes(data)
+	if err != nil {
+		return nil, err
+	}
+	if doc.Root() == nil {
+		return nil, fmt.Errorf("the response is empty")
+	}
+	return doc.Root(), nil
+}
+
+func verifySamlCheckSignature(response *etree.Element, application *Application) error {
+	cert := getCertByApplication(application)
+	if cert == nil {
+		return fmt.Errorf("the application has no cert")
+	}
+	block, _ := pem.Decode([]byte(cert.Certificate))
+	if block == nil {
+		return fmt.Errorf("the cert: %s is not a PEM certificate", cert.Name)
+	}
+	certificate, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		return err
+	}
+
+	ctx := dsig.NewDefaultValidationContext(&dsig.MemoryX509CertificateStore{Roots: []*x509.Certificate{certificate}})
+	_, err = ctx.Validate(response)
+	return err
+}
+
+// checkSamlCheckResponse checks what a dummy SP checks after the signature:
+// the status, who the response is for and when it is valid.
+func checkSamlCheckResponse(response *etree.Element, requestId string, destination string, issuer string, now time.Time) error {
+	statusCode := response.FindElement("./Status/StatusCode")
+	if statusCode == nil || statusCode.SelectAttrValue("Value", "") != "urn:oasis:names:tc:SAML:2.0:status:Success" {
+		return fmt.Errorf("the response status is not Success")
+	}
+	if value := response.SelectAttrValue("InResponseTo", ""); value != requestId {
+		return fmt.Errorf("InResponseTo: %s doesn't match the request ID: %s", value, requestId)
+	}
+	if value := response.SelectAttrValue("Destination", ""); value != destination {
+		return fmt.Errorf("Destination: %s doesn't match the Assertion Consumer Service URL: %s", value, destination)
+	}
+
+	conditions := response.FindElement("./Assertion/Conditions")
+	if conditions == nil {
+		return fmt.Errorf("the assertion has no Conditions")
+	}
+	notBefore, err := time.Parse(time.RFC3339, conditions.SelectAttrValue("NotBefore", ""))
+	if err != nil {
+		return fmt.Errorf("NotBefore is invalid, %s", err.Error())
+	}
+	notOnOrAfter, err := time.Parse(time.RFC3339, conditions.SelectAttrValue("NotOnOrAfter", ""))
+	if err != nil {
+		return fmt.Errorf("NotOnOrAfter is invalid, %s", err.Error())
+	}
+	// the timestamps have a precision of one second
+	if now.Add(time.Second).Before(notBefore) || !now.Before(notOnOrAfter) {
+		return fmt.Errorf("the assertion is only valid from %s to %s", notBefore.Format(time.RFC3339), notOnOrAfter.Format(time.RFC3339))
+	}
+
+	for _, audience := range conditions.FindElements("./AudienceRestriction/Audience") {
+		if audience.Text() == issuer {
+			return nil
+		}
+	}
+	return fmt.Errorf("the issuer: %s is not in the audiences of the assertion", issuer)
+}
+
+// checkSamlCheckAttributes returns the attributes of the assertion, with an
+// error when the name ID or one of the attributes sent by Casdoor is missing.
+func checkSamlCheckAttributes(response *etree.Element, user *User) (map[string]string, error) {
+	nameId := response.FindElement("./Assertion/Subject/NameID")
+	if nameId == nil || nameId.Text() != user.Name {
+		return nil, fmt.Errorf("the NameID doesn't match the user: %s", user.Name)
+	}
+
+	attributes := map[string]string{}
+	for _, attribute := range response.FindElements("./Assertion/AttributeStatement/Attribute") {
+		value := ""
+		if attributeValue := attribute.FindElement("./AttributeValue"); attributeValue != nil {
+			value = attributeValue.Text()
+		}
+		attributes[attribute.SelectAttrValue("Name", "")] = value
+	}
+
+	for _, name := range samlCheckAttributes {
+		if _, ok := attributes[name]; !ok {
+			return attributes, fmt.Errorf("the attribute: %s is missing", name)
+		}
+	}
+	return attributes, nil
+}
+
+func checkApplicationSaml(application *Application, user *User, redirectUri string, host string) *SsoCheckResult {
+	result := &SsoCheckResult{Type: SsoCheckTypeSaml, Success: true, Steps: []*SsoCheckStep{}}
+
+	requestId := fmt.Sprintf("_%s", util.GenerateId())
+	samlRequest, err := newSamlCheckRequest(requestId, redirectUri, redirectUri)
+	if !result.addStep("Build AuthnRequest", fmt.Sprintf("issuer: %s, ID: %s", redirectUri, requestId), err) {
+		return result
+	}
+
+	samlResponse, acsUrl, method, err := GetSamlResponse(application, user, samlRequest, host)
+	if !result.addStep("Generate response", fmt.Sprintf("%s to %s", method, acsUrl), err) {
+		return result
+	}
+
+	response, err := decodeSamlCheckResponse(samlResponse, application.EnableSamlCompress)
+	if !result.addStep("Decode response", fmt.Sprintf("compressed: %t", application.EnableSamlCompress), err) {
+		return result
+	}
+
+	err = verifySamlCheckSignature(response, application)
+	if !result.addStep("Verify signature", fmt.Sprintf("cert: %s", application.Cert), err) {
+		return result
+	}
+
+	err = checkSamlCheckResponse(response, requestId, acsUrl, redirectUri, time.Now().UTC())
+	if !result.addStep("Validate response", "status, destination, validity and audience", err) {
+		return result
+	}
+
+	attributes, err := checkSamlCheckAttributes(response, user)
+	result.addStep("Check attributes", fmt.Sprintf("%v", attributes), err)
+	return result
+}
+
+func checkApplicationOidc(application *Application, user *User, redirectUri string, host string, lang string) *SsoCheckResult {
+	result := &SsoCheckResult{Type: SsoCheckTypeOidc, Success: true, Steps: []*SsoCheckStep{}}
+
+	nonce := util.GenerateClientId()
+	code := GetOAuthCode(user.GetId(), application.ClientId, "code", redirectUri, ssoCheckScope, "casdoor-sso-check", nonce, "", host, "", lang)
+	var err error
+	if code.Message != "" {
+		err = fmt.Errorf("%s", code.Message)
+	}
+	if !result.addStep("Authorization code", fmt.Sprintf("redirect URI: %s, scope: %s", redirectUri, ssoCheckScope), err) {
+		return result
+	}
+	// the check must not leave a usable token behind
+	defer func() {
+		if token := getTokenByCode(code.Code); token != nil {
+			DeleteToken(token)
+		}
+	}()
+
+	var tokenWrapper *TokenWrapper
+	switch res := GetOAuthToken("authorization_code", application.ClientId, application.ClientSecret, code.Code, "", "", "", "", "", host, "", "", "", "", "", nil, nil, lang).(type) {
+	case *TokenWrapper:
+		tokenWrapper = res
+	case *TokenError:
+		err = fmt.Errorf("%s: %s", res.Error, res.ErrorDescription)
+	default:
+		err = fmt.Errorf("unexpected token response: %T", res)
+	}
+	if !result.addStep("Token exchange", "grant type: authorization_code", err) {
+		return result
+	}
+
+	claims, err := ParseJwtTokenByApplication(tokenWrapper.IdToken, application)
+	if err == nil {
+		err = checkOidcCheckClaims(claims, application.ClientId, nonce)
+	}
+	if !result.addStep("Validate ID token", fmt.Sprintf("cert: %s", application.Cert), err) {
+		return result
+	}
+
+	userinfo := GetUserInfo(claims.User, claims.Scope, application.ClientId, host)
+	err = nil
+	if userinfo.Sub != user.Id {
+		err = fmt.Errorf("the sub: %s of the userinfo doesn't match the user ID: %s", userinfo.Sub, user.Id)
+	}
+	result.addStep("Userinfo", fmt.Sprintf("sub: %s, name: %s, email: %s", userinfo.Sub, userinfo.Name, userinfo.Email), err)
+	return result
+}
+
+func checkOidcCheckClaims(claims *Claims, clientId string, nonce string) error {
+	if claims.Nonce != nonce {
+		return fmt.Errorf("the nonce: %s doesn't match the requested nonce: %s", claims.Nonce, nonce)
+	}
+	if !util.ContainsString(claims.Audience, clientId) {
+		return fmt.Errorf("the audience: %v doesn't contain the client ID: %s", claims.Audience, clientId)
+	}
+	if claims.User == nil {
+		return fmt.Errorf("the ID token has no user")
+	}
+	return nil
+}
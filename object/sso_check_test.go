@@ -0,0 +1,86 @@
+// Copyright 2023 The Casdoor Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package object
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/beevik/etree"
+	"github.com/stretchr/testify/assert"
+)
+
+func getSsoCheckResponse(t *testing.T, inResponseTo string, audience string, attributes string) *etree.Element {
+	doc := etree.NewDocument()
+	err := doc.ReadFromString(fmt.Sprintf(`<samlp:Response xmlns:samlp="urn:oasis:names:tc:SAML:2.0:protocol" xmlns:saml="urn:oasis:names:tc:SAML:2.0:assertion" Destination="https://sp.example.com/acs" InResponseTo="%s">
+	<samlp:Status><samlp:StatusCode Value="urn:oasis:names:tc:SAML:2.0:status:Success"/></samlp:Status>
+	<saml:Assertion>
+		<saml:Subject><saml:NameID>alice</saml:NameID></saml:Subject>
+		<saml:Conditions NotBefore="2023-06-08T10:00:00Z" NotOnOrAfter="2023-06-09T10:00:00Z">
+			<saml:AudienceRestriction><saml:Audience>%s</saml:Audience></saml:AudienceRestriction>
+		</saml:Conditions>
+		<saml:AttributeStatement>%s</saml:AttributeStatement>
+	</saml:Assertion>
+</samlp:Response>`, inResponseTo, audience, attributes))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return doc.Root()
+}
+
+func TestCheckSamlCheckResponse(t *testing.T) {
+	now := time.Date(2023, 6, 8, 12, 0, 0, 0, time.UTC)
+
+	scenarios := []struct {
+		description  string
+		inResponseTo string
+		audience     string
+		now          time.Time
+		expected     string
+	}{
+		{"Valid response", "_id", "https://sp.example.com", now, ""},
+		{"Wrong InResponseTo", "_other", "https://sp.example.com", now, "InResponseTo: _other doesn't match the request ID: _id"},
+		{"Missing audience", "_id", "https://other.example.com", now, "the issuer: https://sp.example.com is not in the audiences of the assertion"},
+		{"Expired", "_id", "https://sp.example.com", now.Add(time.Hour * 24), "the assertion is only valid from 2023-06-08T10:00:00Z to 2023-06-09T10:00:00Z"},
+	}
+
+	for _, scenery := range scenarios {
+		t.Run(scenery.description, func(t *testing.T) {
+			response := getSsoCheckResponse(t, scenery.inResponseTo, scenery.audience, "")
+			err := checkSamlCheckResponse(response, "_id", "https://sp.example.com/acs", "https://sp.example.com", scenery.now)
+			if scenery.expected == "" {
+				assert.Nil(t, err)
+			} else {
+				assert.EqualError(t, err, scenery.expected)
+			}
+		})
+	}
+}
+
+func TestCheckSamlCheckAttributes(t *testing.T) {
+	attribute := `<saml:Attribute Name="%s"><saml:AttributeValue>%s</saml:AttributeValue></saml:Attribute>`
+	allAttributes := fmt.Sprintf(attribute, "Email", "alice@example.com") + fmt.Sprintf(attribute, "Name", "alice") + fmt.Sprintf(attribute, "DisplayName", "Alice") + fmt.Sprintf(attribute, "Roles", "")
+
+	attributes, err := checkSamlCheckAttributes(getSsoCheckResponse(t, "_id", "", allAttributes), &User{Name: "alice"})
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]string{"Email": "alice@example.com", "Name": "alice", "DisplayName": "Alice", "Roles": ""}, attributes)
+
+	_, err = checkSamlCheckAttributes(getSsoCheckResponse(t, "_id", "", fmt.Sprintf(attribute, "Email", "alice@example.com")), &User{Name: "alice"})
+	assert.EqualError(t, err, "the attribute: Name is missing")
+
+	_, err = checkSamlCheckAttributes(getSsoCheckResponse(t, "_id", "", allAttributes), &User{Name: "bob"})
+	assert.EqualError(t, err, "the NameID doesn't match the user: bob")
+}
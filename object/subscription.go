@@ -0,0 +1,596 @@
+// Copyright 2023 The Casdoor Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package object
+
+import (
+	"fmt"
+	"math"
+	"net/http"
+	"time"
+
+	"github.com/casdoor/casdoor/pp"
+	"github.com/casdoor/casdoor/util"
+	"github.com/xorm-io/core"
+)
+
+const (
+	SubscriptionStatePending  = "Pending"
+	SubscriptionStateActive   = "Active"
+	SubscriptionStatePastDue  = "PastDue"
+	SubscriptionStateCanceled = "Canceled"
+	SubscriptionStateRefunded = "Refunded"
+)
+
+// Subscription is a plan that a user pays for every period, its state is
+// kept up to date by the webhooks of the payment provider.
+type Subscription struct {
+	Owner       string `xorm:"varchar(100) notnull pk" json:"owner"`
+	Name        string `xorm:"varchar(100) notnull pk" json:"name"`
+	CreatedTime string `xorm:"varchar(100)" json:"createdTime"`
+	DisplayName string `xorm:"varchar(100)" json:"displayName"`
+
+	Organization           string `xorm:"varchar(100)" json:"organization"`
+	User                   string `xorm:"varchar(100)" json:"user"`
+	Plan                   string `xorm:"varchar(100)" json:"plan"`
+	Provider               string `xorm:"varchar(100)" json:"provider"`
+	ProviderSubscriptionId string `xorm:"varchar(100) index" json:"providerSubscriptionId"`
+	PayUrl                 string `xorm:"varchar(2000)" json:"payUrl"`
+
+	State     string `xorm:"varchar(100)" json:"state"`
+	StartTime string `xorm:"varchar(100)" json:"startTime"`
+	EndTime   string `xorm:"varchar(100)" json:"endTime"`
+	Message   string `xorm:"varchar(2000)" json:"message"`
+}
+
+type SubscriptionProration struct {
+	Credit   float64 `json:"credit"`
+	Charge   float64 `json:"charge"`
+	Amount   float64 `json:"amount"`
+	Currency string  `json:"currency"`
+}
+
+func GetSubscriptionCount(owner, field, value string) int {
+	session := GetSession(owner, -1, -1, field, value, "", "")
+	count, err := session.Count(&Subscription{})
+	if err != nil {
+		panic(err)
+	}
+
+	return int(count)
+}
+
+func GetSubscriptions(owner string) []*Subscription {
+	subscriptions := []*Subscription{}
+	err := adapter.Engine.Desc("created_time").Find(&subscriptions, &Subscription{Owner: owner})
+	if err != nil {
+		panic(err)
+	}
+
+	return subscriptions
+}
+
+func GetUserSubscriptions(owner string, organization string, user string) []*Subscription {
+	subscriptions := []*Subscription{}
+	err := adapter.Engine.Desc("created_time").Find(&subscriptions, &Subscription{Owner: owner, Organization: organization, User: user})
+	if err != nil {
+		panic(err)
+	}
+
+	return subscriptions
+}
+
+func GetPaginationSubscriptions(owner string, offset, limit int, field, value, sortField, sortOrder string) []*Subscription {
+	subscriptions := []*Subscription{}
+	session := GetSession(owner, offset, limit, field, value, sortField, sortOrder)
+	err := session.Find(&subscriptions)
+	if err != nil {
+		panic(err)
+	}
+
+	return subscriptions
+}
+
+func getSubscription(owner string, name string) *Subscription {
+	if owner == "" || name == "" {
+		return nil
+	}
+
+	subscription := Subscription{Owner: owner, Name: name}
+	existed, err := adapter.Engine.Get(&subscription)
+	if err != nil {
+		panic(err)
+	}
+
+	if existed {
+		return &subscription
+	} else {
+		return nil
+	}
+}
+
+func GetSubscription(id string) *Subscription {
+	owner, name := util.GetOwnerAndNameFromId(id)
+	return getSubscription(owner, name)
+}
+
+func UpdateSubscription(id string, subscription *Subscription) bool {
+	owner, name := util.GetOwnerAndNameFromId(id)
+	if getSubscription(owner, name) == nil {
+		return false
+	}
+
+	affected, err := adapter.Engine.ID(core.PK{owner, name}).AllCols().Update(subscription)
+	if err != nil {
+		panic(err)
+	}
+
+	return affected != 0
+}
+
+func AddSubscription(subscription *Subscription) bool {
+	affected, err := adapter.Engine.Insert(subscription)
+	if err != nil {
+		panic(err)
+	}
+
+	return affected != 0
+}
+
+func DeleteSubscription(subscription *Subscription) bool {
+	affected, err := adapter.Engine.ID(core.PK{subscription.Owner, subscription.Name}).Delete(&Subscription{})
+	if err != nil {
+		panic(err)
+	}
+
+	return affected != 0
+}
+
+func (subscription *Subscription) GetId() string {
+	return fmt.Sprintf("%s/%s", subscription.Owner, subscription.Name)
+}
+
+// hasAccess is true while the user gets the role of the plan, a failed
+// renewal keeps it until the provider gives up and cancels.
+func (subscription *Subscription) hasAccess() bool {
+	return subscription.State == SubscriptionStateActive || subscription.State == SubscriptionStatePastDue
+}
+
+func getPlanPeriodEnd(period string, start time.Time) time.Time {
+	if period == PlanPeriodYearly {
+		return start.AddDate(1, 0, 0)
+	}
+	return start.AddDate(0, 1, 0)
+}
+
+func getPlanPeriodStart(period string, end time.Time) time.Time {
+	if period == PlanPeriodYearly {
+		return end.AddDate(-1, 0, 0)
+	}
+	return end.AddDate(0, -1, 0)
+}
+
+func getSubscriptionEndTime(subscription *Subscription) time.Time {
+	endTime, err := time.Parse(time.RFC3339, subscription.EndTime)
+	if err != nil {
+		return time.Time{}
+	}
+	return endTime
+}
+
+// extendSubscription moves the end of the subscription to the end of the
+// paid period, one period after the current end if the provider doesn't
+// tell it.
+func extendSubscription(subscription *Subscription, plan *Plan, periodEnd time.Time, now time.Time) {
+	endTime := getSubscriptionEndTime(subscription)
+	if periodEnd.IsZero() {
+		start := endTime
+		if start.Before(now) {
+			start = now
+		}
+		periodEnd = getPlanPeriodEnd(plan.Period, start)
+	}
+
+	if periodEnd.After(endTime) {
+		subscription.EndTime = periodEnd.Format(time.RFC3339)
+	}
+}
+
+// applySubscriptionEvent updates the state of the subscription, the events
+// may come more than once and out of order.
+func applySubscriptionEvent(subscription *Subscription, plan *Plan, event *pp.SubscriptionEvent, now time.Time) {
+	if event.SubscriptionId != "" && subscription.ProviderSubscriptionId == "" {
+		subscription.ProviderSubscriptionId = event.SubscriptionId
+	}
+
+	switch event.Type {
+	case pp.SubscriptionEventActivated, pp.SubscriptionEventRenewed:
+		if subscription.State == SubscriptionStateCanceled || subscription.State == SubscriptionStateRefunded {
+			return
+		}
+		if subscription.StartTime == "" {
+			subscription.StartTime = now.Format(time.RFC3339)
+		}
+		subscription.State = SubscriptionStateActive
+		subscription.Message = ""
+		extendSubscription(subscription, plan, event.PeriodEnd, now)
+	case pp.SubscriptionEventFailed:
+		if subscription.State == SubscriptionStateActive {
+			subscription.State = SubscriptionStatePastDue
+			subscription.Message = "the payment of the renewal failed"
+		}
+	case pp.SubscriptionEventCanceled, pp.SubscriptionEventRefunded:
+		if subscription.State == SubscriptionStateRefunded {
+			return
+		}
+		subscription.State = SubscriptionStateCanceled
+		if event.Type == pp.SubscriptionEventRefunded {
+			subscription.State = SubscriptionStateRefunded
+		}
+		if endTime := getSubscriptionEndTime(subscription); endTime.IsZero() || endTime.After(now) {
+			subscription.EndTime = now.Format(time.RFC3339)
+		}
+	}
+}
+
+// setSubscriptionRole adds the user of the subscription to the role of the
+// plan or removes it.
+func setSubscriptionRole(subscription *Subscription, roleId string, granted bool) {
+	if roleId == "" {
+		return
+	}
+
+	role := GetRole(roleId)
+	if role == nil {
+		return
+	}
+
+	userId := util.GetId(subscription.Organization, subscription.User)
+	hasUser := util.ContainsString(role.Users, userId)
+	if granted && !hasUser {
+		role.Users = append(role.Users, userId)
+	} else if !granted && hasUser {
+		role.Users = util.DeleteVal(role.Users, userId)
+	} else {
+		return
+	}
+	UpdateRole(role.GetId(), role)
+}
+
+func getActiveSubscription(owner string, organization string, user string) *Subscription {
+	for _, subscription := range GetUserSubscriptions(owner, organization, user) {
+		if subscription.hasAccess() {
+			return subscription
+		}
+	}
+	return nil
+}
+
+// Subscribe creates a pending subscription and returns the URL where the
+// user approves it at the payment provider.
+func Subscribe(plan *Plan, user *User, host string) (string, error) {
+	if !plan.IsEnabled {
+		return "", fmt.Errorf("the plan: %s is not enabled", plan.Name)
+	}
+	if subscription := getActiveSubscription(plan.Owner, user.Owner, user.Name); subscription != nil {
+		return "", fmt.Errorf("the user already has the subscription: %s, please change its plan instead", subscription.Name)
+	}
+
+	provider, err := plan.getProvider()
+	if err != nil {
+		return "", err
+	}
+	pProvider, err := provider.getSubscriptionProvider()
+	if err != nil {
+		return "", err
+	}
+
+	subscriptionName := util.GenerateTimeId()
+	originFrontend, _ := getOriginFromHost(host)
+	returnUrl := fmt.Sprintf("%s/subscriptions", originFrontend)
+	payUrl, providerSubscriptionId, err := pProvider.Subscribe(plan.ProviderPlanId, subscriptionName, returnUrl)
+	if err != nil {
+		return "", err
+	}
+
+	subscription := &Subscription{
+		Owner:                  plan.Owner,
+		Name:                   subscriptionName,
+		CreatedTime:            util.GetCurrentTime(),
+		DisplayName:            plan.DisplayName,
+		Organization:           user.Owner,
+		User:                   user.Name,
+		Plan:                   plan.Name,
+		Provider:               provider.Name,
+		ProviderSubscriptionId: providerSubscriptionId,
+		PayUrl:                 payUrl,
+		State:                  SubscriptionStatePending,
+	}
+	if !AddSubscription(subscription) {
+		return "", fmt.Errorf("failed to add subscription: %s", util.StructToJson(subscription))
+	}
+
+	return payUrl, nil
+}
+
+func roundPrice(price float64) float64 {
+	return math.Round(price*100) / 100
+}
+
+// getProration returns the credit for the unused time of the old price and
+// the charge for the same time at the new price.
+func getProration(oldPrice float64, newPrice float64, periodStart time.Time, periodEnd time.Time, now time.Time) (float64, float64) {
+	total := periodEnd.Sub(periodStart)
+	remaining := periodEnd.Sub(now)
+	if total <= 0 || remaining <= 0 {
+		return 0, 0
+	}
+	if remaining > total {
+		remaining = total
+	}
+
+	ratio := float64(remaining) / float64(total)
+	return roundPrice(oldPrice * ratio), roundPrice(newPrice * ratio)
+}
+
+func checkPlanChange(subscription *Subscription, oldPlan *Plan, newPlan *Plan) error {
+	if !subscription.hasAccess() {
+		return fmt.Errorf("the subscription: %s is not active", subscription.Name)
+	}
+	if oldPlan == nil {
+		return fmt.Errorf("the plan: %s does not exist", subscription.Plan)
+	}
+	if !newPlan.IsEnabled {
+		return fmt.Errorf("the plan: %s is not enabled", newPlan.Name)
+	}
+	if oldPlan.Name == newPlan.Name {
+		return fmt.Errorf("the subscription is already on the plan: %s", newPlan.Name)
+	}
+	if oldPlan.Owner != newPlan.Owner || oldPlan.Provider != newPlan.Provider || oldPlan.Currency != newPlan.Currency || oldPlan.Period != newPlan.Period {
+		return fmt.Errorf("the plan can only be changed to a plan with the same provider, currency and period")
+	}
+	return nil
+}
+
+// GetSubscriptionProration previews the prorated amount of a plan change
+// for the rest of the current period, the provider charges its own figure.
+func GetSubscriptionProration(subscription *Subscription, newPlan *Plan) (*SubscriptionProration, error) {
+	oldPlan := getPlan(subscription.Owner, subscription.Plan)
+	err := checkPlanChange(subscription, oldPlan, newPlan)
+	if err != nil {
+		return nil, err
+	}
+
+	endTime := getSubscriptionEndTime(subscription)
+	credit, charge := getProration(oldPlan.Price, newPlan.Price, getPlanPeriodStart(oldPlan.Period, endTime), endTime, time.Now())
+	return &SubscriptionProration{
+		Credit:   credit,
+		Charge:   charge,
+		Amount:   roundPrice(charge - credit),
+		Currency: newPlan.Currency,
+	}, nil
+}
+
+func changeSubscriptionPlan(subscription *Subscription, oldPlan *Plan, newPlan *Plan) {
+	subscription.Plan = newPlan.Name
+	subscription.DisplayName = newPlan.DisplayName
+	UpdateSubscription(subscription.GetId(), subscription)
+
+	if oldPlan != nil && oldPlan.Role != newPlan.Role {
+		setSubscriptionRole(subscription, oldPlan.Role, false)
+	}
+	setSubscriptionRole(subscription, newPlan.Role, subscription.hasAccess())
+}
+
+// ChangeSubscriptionPlan moves the subscription to another plan. It returns
+// a URL when the user has to approve the change at the provider (PayPal),
+// the plan then changes with the webhook of the approval.
+func ChangeSubscriptionPlan(subscription *Subscription, newPlan *Plan) (string, error) {
+	oldPlan := getPlan(subscription.Owner, subscription.Plan)
+	err := checkPlanChange(subscription, oldPlan, newPlan)
+	if err != nil {
+		return "", err
+	}
+	err = checkPlanSeats(subscription, newPlan)
+	if err != nil {
+		return "", err
+	}
+
+	provider, err := newPlan.getProvider()
+	if err != nil {
+		return "", err
+	}
+	pProvider, err := provider.getSubscriptionProvider()
+	if err != nil {
+		return "", err
+	}
+
+	approveUrl, err := pProvider.ChangePlan(subscription.ProviderSubscriptionId, newPlan.ProviderPlanId)
+	if err != nil {
+		return "", err
+	}
+
+	if approveUrl == "" {
+		changeSubscriptionPlan(subscription, oldPlan, newPlan)
+	}
+	return approveUrl, nil
+}
+
+func CancelSubscription(subscription *Subscription) error {
+	if subscription.State == SubscriptionStateCanceled || subscription.State == SubscriptionStateRefunded {
+		return fmt.Errorf("the subscription: %s has been canceled", subscription.Name)
+	}
+
+	if subscription.ProviderSubscriptionId != "" {
+		provider := getProvider(subscription.Owner, subscription.Provider)
+		if provider == nil {
+			return fmt.Errorf("the payment provider: %s does not exist", subscription.Provider)
+		}
+		pProvider, err := provider.getSubscriptionProvider()
+		if err != nil {
+			return err
+		}
+
+		err = pProvider.Cancel(subscription.ProviderSubscriptionId)
+		if err != nil {
+			return err
+		}
+	}
+
+	plan := getPlan(subscription.Owner, subscription.Plan)
+	applySubscriptionEvent(subscription, plan, &pp.SubscriptionEvent{Type: pp.SubscriptionEventCanceled}, time.Now())
+	UpdateSubscription(subscription.GetId(), subscription)
+	if plan != nil {
+		setSubscriptionRole(subscription, plan.Role, false)
+	}
+	return nil
+}
+
+func getSubscriptionOfEvent(owner string, providerName string, event *pp.SubscriptionEvent) *Subscription {
+	if event.Reference != "" {
+		if subscription := getSubscription(owner, event.Reference); subscription != nil && subscription.Provider == providerName {
+			return subscription
+		}
+	}
+	if event.SubscriptionId == "" {
+		return nil
+	}
+
+	subscription := Subscription{Owner: owner, Provider: providerName, ProviderSubscriptionId: event.SubscriptionId}
+	existed, err := adapter.Engine.Get(&subscription)
+	if err != nil {
+		panic(err)
+	}
+
+	if existed {
+		return &subscription
+	}
+	return nil
+}
+
+func getPaymentByExternalId(owner string, providerName string, externalId string) *Payment {
+	payment := Payment{Owner: owner, Provider: providerName, ExternalId: externalId}
+	existed, err := adapter.Engine.Get(&payment)
+	if err != nil {
+		panic(err)
+	}
+
+	if existed {
+		return &payment
+	}
+	return nil
+}
+
+func getPlanByProviderPlanId(owner string, providerName string, providerPlanId string) *Plan {
+	plan := Plan{Owner: owner, Provider: providerName, ProviderPlanId: providerPlanId}
+	existed, err := adapter.Engine.Get(&plan)
+	if err != nil {
+		panic(err)
+	}
+
+	if existed {
+		return &plan
+	}
+	return nil
+}
+
+func addSubscriptionPayment(subscription *Subscription, plan *Plan, provider *Provider, event *pp.SubscriptionEvent) {
+	paymentName := util.GenerateTimeId()
+	payment := &Payment{
+		Owner:              subscription.Owner,
+		Name:               paymentName,
+		CreatedTime:        util.GetCurrentTime(),
+		DisplayName:        paymentName,
+		Provider:           provider.Name,
+		Type:               provider.Type,
+		Organization:       subscription.Organization,
+		User:               subscription.User,
+		ProductName:        plan.Name,
+		ProductDisplayName: plan.DisplayName,
+		Detail:             plan.Description,
+		Currency:           event.Currency,
+		Price:              event.Amount,
+		State:              "Paid",
+		Subscription:       subscription.Name,
+		ExternalId:         event.PaymentId,
+	}
+	AddPayment(payment)
+}
+
+// HandleSubscriptionWebhook applies a webhook event of the provider to its
+// subscription: renewals are recorded as payments, refunds mark them and end
+// the subscription. Retried events change nothing.
+func HandleSubscriptionWebhook(request *http.Request, body []byte, owner string, providerName string) error {
+	provider := getProvider(owner, providerName)
+	if provider == nil {
+		return fmt.Errorf("the payment provider: %s does not exist", providerName)
+	}
+	pProvider, err := provider.getSubscriptionProvider()
+	if err != nil {
+		return err
+	}
+
+	event, err := pProvider.ParseWebhook(request, body)
+	if err != nil {
+		return err
+	}
+	if event.Type == "" {
+		return nil
+	}
+
+	var subscription *Subscription
+	if event.Type == pp.SubscriptionEventRefunded {
+		// refunds only name the payment, which may not be of a subscription
+		payment := getPaymentByExternalId(owner, providerName, event.PaymentId)
+		if payment == nil || payment.Subscription == "" || payment.State == "Refunded" {
+			return nil
+		}
+
+		payment.State = "Refunded"
+		payment.Message = fmt.Sprintf("refunded %.2f %s", event.Amount, event.Currency)
+		UpdatePayment(payment.GetId(), payment)
+
+		subscription = getSubscription(owner, payment.Subscription)
+	} else {
+		subscription = getSubscriptionOfEvent(owner, providerName, event)
+	}
+	if subscription == nil {
+		return fmt.Errorf("the subscription of the event: %s does not exist", event.Id)
+	}
+
+	plan := getPlan(subscription.Owner, subscription.Plan)
+	if plan == nil {
+		return fmt.Errorf("the plan: %s does not exist", subscription.Plan)
+	}
+
+	if event.Type == pp.SubscriptionEventPlanChanged {
+		newPlan := getPlanByProviderPlanId(owner, providerName, event.PlanId)
+		if newPlan != nil && newPlan.Name != plan.Name {
+			changeSubscriptionPlan(subscription, plan, newPlan)
+		}
+		return nil
+	}
+
+	if event.Type == pp.SubscriptionEventRenewed {
+		if getPaymentByExternalId(owner, providerName, event.PaymentId) != nil {
+			return nil
+		}
+		addSubscriptionPayment(subscription, plan, provider, event)
+	}
+
+	applySubscriptionEvent(subscription, plan, event, time.Now())
+	UpdateSubscription(subscription.GetId(), subscription)
+	setSubscriptionRole(subscription, plan.Role, subscription.hasAccess())
+	return nil
+}
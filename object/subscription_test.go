@@ -0,0 +1,85 @@
+// Copyright 2023 The Casdoor Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package object
+
+import (
+	"testing"
+	"time"
+
+	"github.com/casdoor/casdoor/pp"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetProration(t *testing.T) {
+	start := time.Date(2023, 6, 1, 0, 0, 0, 0, time.UTC)
+	end := time.Date(2023, 7, 1, 0, 0, 0, 0, time.UTC)
+
+	scenarios := []struct {
+		description string
+		now         time.Time
+		credit      float64
+		charge      float64
+	}{
+		{"half of the period", time.Date(2023, 6, 16, 0, 0, 0, 0, time.UTC), 5, 15},
+		{"start of the period", start, 10, 30},
+		{"end of the period", end, 0, 0},
+		{"before the period", start.Add(-time.Hour), 10, 30},
+	}
+
+	for _, scenery := range scenarios {
+		t.Run(scenery.description, func(t *testing.T) {
+			credit, charge := getProration(10, 30, start, end, scenery.now)
+			assert.Equal(t, scenery.credit, credit)
+			assert.Equal(t, scenery.charge, charge)
+		})
+	}
+}
+
+func TestApplySubscriptionEvent(t *testing.T) {
+	plan := &Plan{Name: "pro", Period: PlanPeriodMonthly}
+	now := time.Date(2023, 6, 1, 0, 0, 0, 0, time.UTC)
+	periodEnd := time.Date(2023, 7, 1, 0, 0, 0, 0, time.UTC)
+
+	subscription := &Subscription{State: SubscriptionStatePending}
+	applySubscriptionEvent(subscription, plan, &pp.SubscriptionEvent{Type: pp.SubscriptionEventActivated, SubscriptionId: "sub_1"}, now)
+	assert.Equal(t, SubscriptionStateActive, subscription.State)
+	assert.Equal(t, "sub_1", subscription.ProviderSubscriptionId)
+	assert.Equal(t, periodEnd.Format(time.RFC3339), subscription.EndTime, "the period of the plan is used when the provider doesn't tell it")
+
+	// the first payment comes after the activation and doesn't add a period
+	applySubscriptionEvent(subscription, plan, &pp.SubscriptionEvent{Type: pp.SubscriptionEventRenewed, PeriodEnd: periodEnd}, now)
+	assert.Equal(t, periodEnd.Format(time.RFC3339), subscription.EndTime)
+
+	applySubscriptionEvent(subscription, plan, &pp.SubscriptionEvent{Type: pp.SubscriptionEventFailed}, periodEnd)
+	assert.Equal(t, SubscriptionStatePastDue, subscription.State)
+	assert.True(t, subscription.hasAccess(), "a failed renewal keeps the access until the provider cancels")
+
+	nextPeriodEnd := periodEnd.AddDate(0, 1, 0)
+	applySubscriptionEvent(subscription, plan, &pp.SubscriptionEvent{Type: pp.SubscriptionEventRenewed, PeriodEnd: nextPeriodEnd}, periodEnd)
+	assert.Equal(t, SubscriptionStateActive, subscription.State)
+	assert.Equal(t, nextPeriodEnd.Format(time.RFC3339), subscription.EndTime)
+
+	refundTime := periodEnd.AddDate(0, 0, 3)
+	applySubscriptionEvent(subscription, plan, &pp.SubscriptionEvent{Type: pp.SubscriptionEventRefunded}, refundTime)
+	assert.Equal(t, SubscriptionStateRefunded, subscription.State)
+	assert.Equal(t, refundTime.Format(time.RFC3339), subscription.EndTime)
+	assert.False(t, subscription.hasAccess())
+
+	// a late cancellation or renewal doesn't revive a refunded subscription
+	applySubscriptionEvent(subscription, plan, &pp.SubscriptionEvent{Type: pp.SubscriptionEventCanceled}, refundTime.Add(time.Hour))
+	applySubscriptionEvent(subscription, plan, &pp.SubscriptionEvent{Type: pp.SubscriptionEventRenewed}, refundTime.Add(time.Hour))
+	assert.Equal(t, SubscriptionStateRefunded, subscription.State)
+	assert.Equal(t, refundTime.Format(time.RFC3339), subscription.EndTime)
+}
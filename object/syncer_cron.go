@@ -16,6 +16,7 @@ package object
 
 import (
 	"fmt"
+	"time"
 
 	"github.com/robfig/cron/v3"
 )
@@ -52,11 +53,21 @@ func addSyncerJob(syncer *Syncer) {
 
 	syncer.initAdapter()
 
-	syncer.syncUsers()
+	// every node schedules the syncer, the job lock lets one of them sync
+	jobName := fmt.Sprintf("syncer-%s", syncer.Name)
+	interval := time.Duration(syncer.SyncInterval) * time.Second
+	syncUsers := func() {
+		RunClusterJob(jobName, interval, func() error {
+			syncer.syncUsers()
+			return nil
+		})
+	}
+
+	syncUsers()
 
 	schedule := fmt.Sprintf("@every %ds", syncer.SyncInterval)
 	cron := getCronMap(syncer.Name)
-	_, err := cron.AddFunc(schedule, syncer.syncUsers)
+	_, err := cron.AddFunc(schedule, syncUsers)
 	if err != nil {
 		panic(err)
 	}
@@ -86,7 +86,16 @@ func (syncer *Syncer) syncUsers() {
 			}
 		}
 	}
-	AddUsersInBatch(newUsers)
+	err = CheckUsersSeatLimit(newUsers, "en")
+	if err != nil {
+		fmt.Printf(err.Error())
+
+		timestamp := time.Now().Format("2006-01-02 15:04:05")
+		line := fmt.Sprintf("[%s] %s\n", timestamp, err.Error())
+		updateSyncerErrorText(syncer, line)
+	} else {
+		AddUsersInBatch(newUsers)
+	}
 
 	for _, user := range users {
 		id := user.Id
@@ -0,0 +1,399 @@
+// Copyright 2023 The Casdoor Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package object
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/beego/beego/logs"
+	"github.com/casdoor/casdoor/conf"
+	"github.com/casdoor/casdoor/i18n"
+	"github.com/casdoor/casdoor/util"
+	"github.com/xorm-io/core"
+)
+
+const (
+	TaskTypeWebhook      = "webhook"
+	TaskTypeWelcomeEmail = "welcome-email"
+	TaskTypeProvision    = "provision"
+)
+
+const (
+	TaskStatePending   = "Pending"
+	TaskStateRunning   = "Running"
+	TaskStateSucceeded = "Succeeded"
+	TaskStateFailed    = "Failed"
+)
+
+const (
+	taskPollInterval   = 5 * time.Second
+	taskLeaseTimeout   = 5 * time.Minute
+	taskClaimBatchSize = 10
+	taskMaxAttempts    = 8
+	taskBaseBackoff    = 30 * time.Second
+	taskMaxBackoff     = time.Hour
+)
+
+// Task is a side effect of a request (a webhook call, an email, a push to
+// the original database of a syncer) that is run by the workers after the
+// request returned. A task is retried with an exponential backoff until it
+// succeeds or runs out of attempts, the row is leased by the node running
+// it so that a task of a node that died is picked up by another one.
+type Task struct {
+	Owner       string `xorm:"varchar(100) notnull pk" json:"owner"`
+	Name        string `xorm:"varchar(100) notnull pk" json:"name"`
+	CreatedTime string `xorm:"varchar(100)" json:"createdTime"`
+	UpdatedTime string `xorm:"varchar(100)" json:"updatedTime"`
+
+	Type            string `xorm:"varchar(100) index" json:"type"`
+	Payload         string `xorm:"mediumtext" json:"payload"`
+	State           string `xorm:"varchar(100) index" json:"state"`
+	Attempts        int    `json:"attempts"`
+	MaxAttempts     int    `json:"maxAttempts"`
+	NextRunTime     int64  `xorm:"index" json:"nextRunTime"`
+	Node            string `xorm:"varchar(100)" json:"node"`
+	LeaseExpireTime int64  `json:"leaseExpireTime"`
+	LastError       string `xorm:"mediumtext" json:"lastError"`
+}
+
+type webhookTaskPayload struct {
+	Webhook string  `json:"webhook"`
+	Record  *Record `json:"record"`
+}
+
+type userTaskPayload struct {
+	User        string `json:"user"`
+	Application string `json:"application"`
+}
+
+var taskHandlers = map[string]func(task *Task) error{
+	TaskTypeWebhook:      runWebhookTask,
+	TaskTypeWelcomeEmail: runWelcomeEmailTask,
+	TaskTypeProvision:    runProvisionTask,
+}
+
+var taskWakeup = make(chan struct{}, 1)
+
+func GetTaskCount(owner, field, value string) int {
+	session := GetSession(owner, -1, -1, field, value, "", "")
+	count, err := session.Count(&Task{})
+	if err != nil {
+		panic(err)
+	}
+
+	return int(count)
+}
+
+func GetTasks(owner string) []*Task {
+	tasks := []*Task{}
+	err := adapter.Engine.Desc("created_time").Find(&tasks, &Task{Owner: owner})
+	if err != nil {
+		panic(err)
+	}
+
+	return tasks
+}
+
+func GetPaginationTasks(owner string, offset, limit int, field, value, sortField, sortOrder string) []*Task {
+	tasks := []*Task{}
+	session := GetSession(owner, offset, limit, field, value, sortField, sortOrder)
+	err := session.Find(&tasks)
+	if err != nil {
+		panic(err)
+	}
+
+	return tasks
+}
+
+func getTask(owner string, name string) *Task {
+	if owner == "" || name == "" {
+		return nil
+	}
+
+	task := Task{Owner: owner, Name: name}
+	existed, err := adapter.Engine.Get(&task)
+	if err != nil {
+		panic(err)
+	}
+
+	if existed {
+		return &task
+	} else {
+		return nil
+	}
+}
+
+func GetTask(id string) *Task {
+	owner, name := util.GetOwnerAndNameFromId(id)
+	return getTask(owner, name)
+}
+
+// EnqueueTask stores a task of the organization to be run as soon as a
+// worker is free.
+func EnqueueTask(owner string, typ string, payload interface{}) {
+	task := &Task{
+		Owner:       owner,
+		Name:        util.GenerateId(),
+		CreatedTime: util.GetCurrentTime(),
+		UpdatedTime: util.GetCurrentTime(),
+		Type:        typ,
+		Payload:     util.StructToJson(payload),
+		State:       TaskStatePending,
+		MaxAttempts: taskMaxAttempts,
+		NextRunTime: time.Now().Unix(),
+	}
+	_, err := adapter.Engine.Insert(task)
+	if err != nil {
+		panic(err)
+	}
+
+	wakeUpTaskWorkers()
+}
+
+// RetryTask schedules a failed (or pending) task to be run again right away,
+// with all its attempts.
+func RetryTask(id string) bool {
+	owner, name := util.GetOwnerAndNameFromId(id)
+	task := &Task{
+		UpdatedTime: util.GetCurrentTime(),
+		State:       TaskStatePending,
+		NextRunTime: time.Now().Unix(),
+	}
+	affected, err := adapter.Engine.Where("owner = ? and name = ? and state <> ?", owner, name, TaskStateRunning).
+		Cols("updated_time", "state", "attempts", "next_run_time").Update(task)
+	if err != nil {
+		panic(err)
+	}
+
+	if affected != 0 {
+		wakeUpTaskWorkers()
+	}
+	return affected != 0
+}
+
+func wakeUpTaskWorkers() {
+	select {
+	case taskWakeup <- struct{}{}:
+	default:
+	}
+}
+
+// getTaskBackoff returns how long to wait before the next attempt after the
+// given number of failed attempts.
+func getTaskBackoff(attempts int) time.Duration {
+	backoff := taskBaseBackoff
+	for i := 1; i < attempts; i++ {
+		backoff *= 2
+		if backoff >= taskMaxBackoff {
+			return taskMaxBackoff
+		}
+	}
+	return backoff
+}
+
+// claimTask leases a due task to this node, including a running task whose
+// lease expired because its node died. The update only succeeds for the
+// node that saw the task last, so that a task is never run twice at once.
+func claimTask() *Task {
+	now := time.Now().Unix()
+	tasks := []*Task{}
+	err := adapter.Engine.Where("(state = ? and next_run_time <= ?) or (state = ? and lease_expire_time < ?)", TaskStatePending, now, TaskStateRunning, now).
+		Asc("next_run_time").Limit(taskClaimBatchSize).Find(&tasks)
+	if err != nil {
+		panic(err)
+	}
+
+	for _, task := range tasks {
+		claimed := &Task{
+			UpdatedTime:     util.GetCurrentTime(),
+			State:           TaskStateRunning,
+			Attempts:        task.Attempts + 1,
+			Node:            nodeName,
+			LeaseExpireTime: time.Now().Add(taskLeaseTimeout).Unix(),
+		}
+		affected, err := adapter.Engine.Where("owner = ? and name = ? and state = ? and attempts = ?", task.Owner, task.Name, task.State, task.Attempts).
+			Cols("updated_time", "state", "attempts", "node", "lease_expire_time").Update(claimed)
+		if err != nil {
+			panic(err)
+		}
+		if affected == 0 {
+			continue
+		}
+
+		task.UpdatedTime = claimed.UpdatedTime
+		task.State = claimed.State
+		task.Attempts = claimed.Attempts
+		task.Node = claimed.Node
+		task.LeaseExpireTime = claimed.LeaseExpireTime
+		return task
+	}
+
+	return nil
+}
+
+func runTask(task *Task) {
+	var err error
+	func() {
+		defer func() {
+			if r := recover(); r != nil {
+				err = fmt.Errorf("%v", r)
+			}
+		}()
+
+		handler, ok := taskHandlers[task.Type]
+		if !ok {
+			task.Attempts = task.MaxAttempts
+			err = fmt.Errorf("unknown task type: %s", task.Type)
+			return
+		}
+		err = handler(task)
+	}()
+
+	task.UpdatedTime = util.GetCurrentTime()
+	task.LeaseExpireTime = 0
+	if err == nil {
+		task.State = TaskStateSucceeded
+		task.LastError = ""
+	} else {
+		task.LastError = err.Error()
+		if task.Attempts >= task.MaxAttempts {
+			task.State = TaskStateFailed
+			logs.Warning(fmt.Sprintf("task: %s of type: %s failed after %d attempts, %s", task.Name, task.Type, task.Attempts, err.Error()))
+		} else {
+			task.State = TaskStatePending
+			task.NextRunTime = time.Now().Add(getTaskBackoff(task.Attempts)).Unix()
+		}
+	}
+
+	_, err = adapter.Engine.ID(core.PK{task.Owner, task.Name}).Where("node = ?", nodeName).
+		Cols("updated_time", "state", "attempts", "next_run_time", "lease_expire_time", "last_error").Update(task)
+	if err != nil {
+		panic(err)
+	}
+}
+
+func runTaskWorker() {
+	for {
+		var task *Task
+		if !IsMaintenanceMode() {
+			task = claimTask()
+		}
+		if task != nil {
+			runTask(task)
+			continue
+		}
+
+		select {
+		case <-taskWakeup:
+		case <-time.After(taskPollInterval):
+		}
+	}
+}
+
+// StartTaskWorkers runs the "taskWorkers" workers (4 by default) of this
+// node, every node of the cluster takes tasks from the same table.
+func StartTaskWorkers() {
+	workers, err := conf.GetConfigInt64("taskWorkers")
+	if err != nil || workers <= 0 {
+		workers = 4
+	}
+
+	for i := int64(0); i < workers; i++ {
+		util.SafeGoroutine(runTaskWorker)
+	}
+}
+
+// EnqueueSignupTasks queues the provisioning of a new user into the original
+// database of the syncer and the welcome email of the application.
+func EnqueueSignupTasks(application *Application, user *User) {
+	if getEnabledSyncerForOrganization(user.Owner) != nil {
+		EnqueueTask(user.Owner, TaskTypeProvision, &userTaskPayload{User: user.GetId()})
+	}
+	if application.EnableWelcomeEmail && user.Email != "" {
+		EnqueueTask(user.Owner, TaskTypeWelcomeEmail, &userTaskPayload{User: user.GetId(), Application: application.GetId()})
+	}
+}
+
+func runWebhookTask(task *Task) error {
+	payload := webhookTaskPayload{}
+	err := json.Unmarshal([]byte(task.Payload), &payload)
+	if err != nil {
+		return err
+	}
+
+	// the webhook may have been disabled or deleted since
+	webhook := GetWebhook(payload.Webhook)
+	if webhook == nil || !webhook.IsEnabled {
+		return nil
+	}
+
+	err = sendWebhook(webhook, payload.Record)
+	if err != nil {
+		return err
+	}
+
+	_, err = adapter.Engine.ID(payload.Record.Id).Cols("is_triggered").Update(&Record{IsTriggered: true})
+	return err
+}
+
+func runWelcomeEmailTask(task *Task) error {
+	payload := userTaskPayload{}
+	err := json.Unmarshal([]byte(task.Payload), &payload)
+	if err != nil {
+		return err
+	}
+
+	user := GetUser(payload.User)
+	application := GetApplication(payload.Application)
+	if user == nil || application == nil || user.Email == "" {
+		return nil
+	}
+
+	provider := application.GetEmailProvider()
+	if provider == nil {
+		return fmt.Errorf("the application: %s has no email provider", application.GetId())
+	}
+
+	displayName := application.DisplayName
+	if displayName == "" {
+		displayName = application.Name
+	}
+	userName := user.DisplayName
+	if userName == "" {
+		userName = user.Name
+	}
+	lang := user.GetLanguage()
+	title := fmt.Sprintf(i18n.Translate(lang, "account:Welcome to %s"), displayName)
+	content := fmt.Sprintf(i18n.Translate(lang, "account:Hi %s, your account %s at %s has been created."), userName, user.Name, displayName)
+	return SendEmail(provider, title, content, user.Email, provider.DisplayName)
+}
+
+func runProvisionTask(task *Task) error {
+	payload := userTaskPayload{}
+	err := json.Unmarshal([]byte(task.Payload), &payload)
+	if err != nil {
+		return err
+	}
+
+	user := GetUser(payload.User)
+	if user == nil {
+		return nil
+	}
+
+	AddUserToOriginalDatabase(user)
+	return nil
+}
@@ -0,0 +1,70 @@
+// Copyright 2023 The Casdoor Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package object
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetTaskBackoff(t *testing.T) {
+	scenarios := []struct {
+		description string
+		attempts    int
+		expected    time.Duration
+	}{
+		{"first attempt", 1, 30 * time.Second},
+		{"second attempt", 2, time.Minute},
+		{"fifth attempt", 5, 8 * time.Minute},
+		{"capped", 8, time.Hour},
+		{"far past the cap", 100, time.Hour},
+	}
+
+	for _, scenery := range scenarios {
+		t.Run(scenery.description, func(t *testing.T) {
+			assert.Equal(t, scenery.expected, getTaskBackoff(scenery.attempts))
+		})
+	}
+}
+
+func TestGetMatchedWebhooks(t *testing.T) {
+	webhooks := []*Webhook{
+		{Name: "login", IsEnabled: true, Events: []string{"login", "logout"}},
+		{Name: "disabled", IsEnabled: false, Events: []string{"login"}},
+		{Name: "signup", IsEnabled: true, Events: []string{"signup"}},
+	}
+
+	scenarios := []struct {
+		description string
+		action      string
+		expected    []string
+	}{
+		{"one enabled webhook", "login", []string{"login"}},
+		{"another event", "signup", []string{"signup"}},
+		{"no webhook", "update-user", []string{}},
+	}
+
+	for _, scenery := range scenarios {
+		t.Run(scenery.description, func(t *testing.T) {
+			names := []string{}
+			for _, webhook := range getMatchedWebhooks(webhooks, scenery.action) {
+				names = append(names, webhook.Name)
+			}
+			assert.Equal(t, scenery.expected, names)
+		})
+	}
+}
@@ -20,6 +20,7 @@ import (
 	"fmt"
 	"time"
 
+	"github.com/beego/beego/logs"
 	"github.com/casdoor/casdoor/i18n"
 	"github.com/casdoor/casdoor/idp"
 	"github.com/casdoor/casdoor/util"
@@ -38,8 +39,20 @@ const (
 )
 
 type Code struct {
-	Message string `xorm:"varchar(100)" json:"message"`
-	Code    string `xorm:"varchar(100)" json:"code"`
+	Message   string `xorm:"varchar(100)" json:"message"`
+	Code      string `xorm:"varchar(100)" json:"code"`
+	ErrorCode string `xorm:"-" json:"errorCode,omitempty"`
+}
+
+// getCodeByError keeps the message of a CodedError, the messages of other
+// errors aren't given to the client.
+func getCodeByError(err error, lang string) *Code {
+	if codedError, ok := err.(*CodedError); ok {
+		return &Code{Message: codedError.Message, ErrorCode: codedError.Code}
+	}
+
+	logs.Warning(fmt.Sprintf("failed to generate the code, %s", err.Error()))
+	return &Code{Message: getDefaultErrorMessage(lang, ErrorCodeServerError), ErrorCode: ErrorCodeServerError}
 }
 
 type Token struct {
@@ -47,9 +60,10 @@ type Token struct {
 	Name        string `xorm:"varchar(100) notnull pk" json:"name"`
 	CreatedTime string `xorm:"varchar(100)" json:"createdTime"`
 
-	Application  string `xorm:"varchar(100)" json:"application"`
-	Organization string `xorm:"varchar(100)" json:"organization"`
-	User         string `xorm:"varchar(100)" json:"user"`
+	Application        string `xorm:"varchar(100)" json:"application"`
+	Organization       string `xorm:"varchar(100)" json:"organization"`
+	User               string `xorm:"varchar(100)" json:"user"`
+	ActiveOrganization string `xorm:"varchar(100)" json:"activeOrganization"`
 
 	Code          string `xorm:"varchar(100) index" json:"code"`
 	AccessToken   string `xorm:"mediumtext" json:"accessToken"`
@@ -60,6 +74,9 @@ type Token struct {
 	CodeChallenge string `xorm:"varchar(100)" json:"codeChallenge"`
 	CodeIsUsed    bool   `json:"codeIsUsed"`
 	CodeExpireIn  int64  `json:"codeExpireIn"`
+	Attestation   string `xorm:"varchar(300)" json:"attestation"`
+	DeviceId      string `xorm:"varchar(100) index" json:"deviceId"`
+	RedirectUri   string `xorm:"varchar(500)" json:"redirectUri"`
 }
 
 type TokenWrapper struct {
@@ -89,6 +106,7 @@ type IntrospectionResponse struct {
 	Aud       []string `json:"aud,omitempty"`
 	Iss       string   `json:"iss,omitempty"`
 	Jti       string   `json:"jti,omitempty"`
+	DeviceId  string   `json:"device_id,omitempty"`
 }
 
 func GetTokenCount(owner, field, value string) int {
@@ -155,7 +173,7 @@ func getTokenByCode(code string) *Token {
 }
 
 func updateUsedByCode(token *Token) bool {
-	affected, err := adapter.Engine.Where("code=?", token.Code).Cols("code_is_used").Update(token)
+	affected, err := adapter.Engine.Where("code=?", token.Code).Cols("code_is_used", "attestation").Update(token)
 	if err != nil {
 		panic(err)
 	}
@@ -244,52 +262,56 @@ func GetTokenByTokenAndApplication(token string, application string) *Token {
 	return &tokenResult
 }
 
-func CheckOAuthLogin(clientId string, responseType string, redirectUri string, scope string, state string, lang string) (string, *Application) {
+func CheckOAuthLogin(clientId string, responseType string, redirectUri string, scope string, state string, lang string) (*CodedError, *Application) {
 	if responseType != "code" && responseType != "token" && responseType != "id_token" {
-		return fmt.Sprintf(i18n.Translate(lang, "token:Grant_type: %s is not supported in this application"), responseType), nil
+		return NewCodedError(ErrorCodeUnsupportedResponseType, fmt.Sprintf(i18n.Translate(lang, "token:Grant_type: %s is not supported in this application"), responseType)), nil
 	}
 
 	application := GetApplicationByClientId(clientId)
 	if application == nil {
-		return i18n.Translate(lang, "token:Invalid client_id"), nil
+		return NewCodedError(ErrorCodeInvalidClient, i18n.Translate(lang, "token:Invalid client_id")), nil
 	}
 
-	if !application.IsRedirectUriValid(redirectUri) {
-		return fmt.Sprintf(i18n.Translate(lang, "token:Redirect URI: %s doesn't exist in the allowed Redirect URI list"), redirectUri), application
+	if reason := application.CheckRedirectUri(redirectUri); reason != "" {
+		return NewCodedError(ErrorCodeInvalidRedirectUri, fmt.Sprintf(i18n.Translate(lang, "token:Redirect URI: %s is rejected: %s"), redirectUri, TranslateRedirectUriReason(lang, reason))), application
 	}
 
 	// Mask application for /api/get-app-login
 	application.ClientSecret = ""
-	return "", application
+	return nil, application
 }
 
-func GetOAuthCode(userId string, clientId string, responseType string, redirectUri string, scope string, state string, nonce string, challenge string, host string, lang string) *Code {
+func GetOAuthCode(userId string, clientId string, responseType string, redirectUri string, scope string, state string, nonce string, challenge string, host string, activeOrganization string, lang string) *Code {
 	user := GetUser(userId)
 	if user == nil {
 		return &Code{
-			Message: fmt.Sprintf("general:The user: %s doesn't exist", userId),
-			Code:    "",
+			Message:   fmt.Sprintf(i18n.Translate(lang, "general:The user: %s doesn't exist"), userId),
+			Code:      "",
+			ErrorCode: ErrorCodeUserNotFound,
 		}
 	}
 	if user.IsForbidden {
 		return &Code{
-			Message: "error: the user is forbidden to sign in, please contact the administrator",
-			Code:    "",
+			Message:   i18n.Translate(lang, "check:The user is forbidden to sign in, please contact the administrator"),
+			Code:      "",
+			ErrorCode: ErrorCodeUserForbidden,
 		}
 	}
 
-	msg, application := CheckOAuthLogin(clientId, responseType, redirectUri, scope, state, lang)
-	if msg != "" {
+	codedError, application := CheckOAuthLogin(clientId, responseType, redirectUri, scope, state, lang)
+	if codedError != nil {
 		return &Code{
-			Message: msg,
-			Code:    "",
+			Message:   codedError.Message,
+			Code:      "",
+			ErrorCode: codedError.Code,
 		}
 	}
 
+	user = ApplyMembership(user, activeOrganization)
 	ExtendUserWithRolesAndPermissions(user)
 	accessToken, refreshToken, tokenName, err := generateJwtToken(application, user, nonce, scope, host)
 	if err != nil {
-		panic(err)
+		return getCodeByError(err, lang)
 	}
 
 	if challenge == "null" {
@@ -297,21 +319,23 @@ func GetOAuthCode(userId string, clientId string, responseType string, redirectU
 	}
 
 	token := &Token{
-		Owner:         application.Owner,
-		Name:          tokenName,
-		CreatedTime:   util.GetCurrentTime(),
-		Application:   application.Name,
-		Organization:  user.Owner,
-		User:          user.Name,
-		Code:          util.GenerateClientId(),
-		AccessToken:   accessToken,
-		RefreshToken:  refreshToken,
-		ExpiresIn:     application.ExpireInHours * hourSeconds,
-		Scope:         scope,
-		TokenType:     "Bearer",
-		CodeChallenge: challenge,
-		CodeIsUsed:    false,
-		CodeExpireIn:  time.Now().Add(time.Minute * 5).Unix(),
+		Owner:              application.Owner,
+		Name:               tokenName,
+		CreatedTime:        util.GetCurrentTime(),
+		Application:        application.Name,
+		Organization:       user.Owner,
+		User:               user.Name,
+		ActiveOrganization: user.ActiveOrganization,
+		Code:               util.GenerateClientId(),
+		AccessToken:        accessToken,
+		RefreshToken:       refreshToken,
+		ExpiresIn:          application.ExpireInHours * hourSeconds,
+		Scope:              scope,
+		TokenType:          "Bearer",
+		CodeChallenge:      challenge,
+		CodeIsUsed:         false,
+		CodeExpireIn:       time.Now().Add(time.Minute * 5).Unix(),
+		RedirectUri:        redirectUri,
 	}
 	AddToken(token)
 
@@ -321,7 +345,7 @@ func GetOAuthCode(userId string, clientId string, responseType string, redirectU
 	}
 }
 
-func GetOAuthToken(grantType string, clientId string, clientSecret string, code string, verifier string, scope string, username string, password string, host string, refreshToken string, tag string, avatar string, lang string) interface{} {
+func GetOAuthToken(grantType string, clientId string, clientSecret string, code string, verifier string, redirectUri string, scope string, username string, password string, host string, clientIp string, refreshToken string, tag string, avatar string, deviceId string, deviceCredentials *DeviceCredentials, attestationRequest *AttestationRequest, lang string) interface{} {
 	application := GetApplicationByClientId(clientId)
 	if application == nil {
 		return &TokenError{
@@ -339,17 +363,28 @@ func GetOAuthToken(grantType string, clientId string, clientSecret string, code
 		}
 	}
 
+	if grantType == "refresh_token" {
+		return RefreshToken(grantType, refreshToken, scope, clientId, clientSecret, host, attestationRequest)
+	}
+
+	// the token is bound to the mobile app that made the request
+	attestation, tokenError := checkTokenAttestation(application, grantType, attestationRequest)
+	if tokenError != nil {
+		return tokenError
+	}
+
 	var token *Token
-	var tokenError *TokenError
 	switch grantType {
 	case "authorization_code": // Authorization Code Grant
-		token, tokenError = GetAuthorizationCodeToken(application, clientSecret, code, verifier)
+		token, tokenError = GetAuthorizationCodeToken(application, clientSecret, code, verifier, redirectUri)
 	case "password": //	Resource Owner Password Credentials Grant
 		token, tokenError = GetPasswordToken(application, username, password, scope, host)
 	case "client_credentials": // Client Credentials Grant
 		token, tokenError = GetClientCredentialsToken(application, clientSecret, scope, host)
-	case "refresh_token":
-		return RefreshToken(grantType, refreshToken, scope, clientId, clientSecret, host)
+	case GuestGrantType: // anonymous user bound to a device
+		token, tokenError = GetGuestToken(application, deviceId, scope, host, clientIp)
+	case DeviceGrantType: // device with a credential issued by the organization
+		token, tokenError = GetDeviceToken(application, deviceId, deviceCredentials, scope, host)
 	}
 
 	if tag == "wechat_miniprogram" {
@@ -362,6 +397,7 @@ func GetOAuthToken(grantType string, clientId string, clientSecret string, code
 	}
 
 	token.CodeIsUsed = true
+	token.Attestation = attestation
 	go updateUsedByCode(token)
 
 	tokenWrapper := &TokenWrapper{
@@ -376,7 +412,7 @@ func GetOAuthToken(grantType string, clientId string, clientSecret string, code
 	return tokenWrapper
 }
 
-func RefreshToken(grantType string, refreshToken string, scope string, clientId string, clientSecret string, host string) interface{} {
+func RefreshToken(grantType string, refreshToken string, scope string, clientId string, clientSecret string, host string, attestationRequest *AttestationRequest) interface{} {
 	// check parameters
 	if grantType != "refresh_token" {
 		return &TokenError{
@@ -415,8 +451,26 @@ func RefreshToken(grantType string, refreshToken string, scope string, clientId
 			ErrorDescription: fmt.Sprintf("parse refresh token error: %s", err.Error()),
 		}
 	}
-	// generate a new token
-	user := getUser(application.Organization, token.User)
+
+	// a token bound to an app is only refreshed by the same app
+	attestation, tokenError := checkTokenAttestation(application, grantType, attestationRequest)
+	if tokenError != nil {
+		return tokenError
+	}
+	if token.Attestation != "" && attestation != token.Attestation {
+		return &TokenError{
+			Error:            InvalidGrant,
+			ErrorDescription: "the refresh token is bound to another app",
+		}
+	}
+	// generate a new token, the user may be a member from another organization
+	user := getUser(token.Organization, token.User)
+	if user == nil {
+		return &TokenError{
+			Error:            InvalidGrant,
+			ErrorDescription: fmt.Sprintf("the user: %s doesn't exist", util.GetId(token.Organization, token.User)),
+		}
+	}
 	if user.IsForbidden {
 		return &TokenError{
 			Error:            InvalidGrant,
@@ -424,6 +478,7 @@ func RefreshToken(grantType string, refreshToken string, scope string, clientId
 		}
 	}
 
+	user = ApplyMembership(user, token.ActiveOrganization)
 	ExtendUserWithRolesAndPermissions(user)
 	newAccessToken, newRefreshToken, tokenName, err := generateJwtToken(application, user, "", scope, host)
 	if err != nil {
@@ -434,18 +489,20 @@ func RefreshToken(grantType string, refreshToken string, scope string, clientId
 	}
 
 	newToken := &Token{
-		Owner:        application.Owner,
-		Name:         tokenName,
-		CreatedTime:  util.GetCurrentTime(),
-		Application:  application.Name,
-		Organization: user.Owner,
-		User:         user.Name,
-		Code:         util.GenerateClientId(),
-		AccessToken:  newAccessToken,
-		RefreshToken: newRefreshToken,
-		ExpiresIn:    application.ExpireInHours * hourSeconds,
-		Scope:        scope,
-		TokenType:    "Bearer",
+		Owner:              application.Owner,
+		Name:               tokenName,
+		CreatedTime:        util.GetCurrentTime(),
+		Application:        application.Name,
+		Organization:       user.Owner,
+		User:               user.Name,
+		ActiveOrganization: user.ActiveOrganization,
+		Code:               util.GenerateClientId(),
+		AccessToken:        newAccessToken,
+		RefreshToken:       newRefreshToken,
+		ExpiresIn:          application.ExpireInHours * hourSeconds,
+		Scope:              scope,
+		TokenType:          "Bearer",
+		Attestation:        attestation,
 	}
 	AddToken(newToken)
 	DeleteToken(&token)
@@ -486,7 +543,7 @@ func IsGrantTypeValid(method string, grantTypes []string) bool {
 
 // GetAuthorizationCodeToken
 // Authorization code flow
-func GetAuthorizationCodeToken(application *Application, clientSecret string, code string, verifier string) (*Token, *TokenError) {
+func GetAuthorizationCodeToken(application *Application, clientSecret string, code string, verifier string, redirectUri string) (*Token, *TokenError) {
 	if code == "" {
 		return nil, &TokenError{
 			Error:            InvalidRequest,
@@ -509,6 +566,14 @@ func GetAuthorizationCodeToken(application *Application, clientSecret string, co
 		}
 	}
 
+	// the redirect URI is optional for the clients that don't send it
+	if redirectUri != "" && token.RedirectUri != "" && redirectUri != token.RedirectUri {
+		return nil, &TokenError{
+			Error:            InvalidGrant,
+			ErrorDescription: "redirect_uri doesn't match the one of the authorization request",
+		}
+	}
+
 	if token.CodeChallenge != "" && pkceChallenge(verifier) != token.CodeChallenge {
 		return nil, &TokenError{
 			Error:            InvalidGrant,
@@ -644,7 +709,7 @@ func GetClientCredentialsToken(application *Application, clientSecret string, sc
 }
 
 // GetTokenByUser
-// Implicit flow
+// Implicit flow, the active organization of the user is kept in the token
 func GetTokenByUser(application *Application, user *User, scope string, host string) (*Token, error) {
 	ExtendUserWithRolesAndPermissions(user)
 	accessToken, refreshToken, tokenName, err := generateJwtToken(application, user, "", scope, host)
@@ -652,19 +717,20 @@ func GetTokenByUser(application *Application, user *User, scope string, host str
 		return nil, err
 	}
 	token := &Token{
-		Owner:        application.Owner,
-		Name:         tokenName,
-		CreatedTime:  util.GetCurrentTime(),
-		Application:  application.Name,
-		Organization: user.Owner,
-		User:         user.Name,
-		Code:         util.GenerateClientId(),
-		AccessToken:  accessToken,
-		RefreshToken: refreshToken,
-		ExpiresIn:    application.ExpireInHours * hourSeconds,
-		Scope:        scope,
-		TokenType:    "Bearer",
-		CodeIsUsed:   true,
+		Owner:              application.Owner,
+		Name:               tokenName,
+		CreatedTime:        util.GetCurrentTime(),
+		Application:        application.Name,
+		Organization:       user.Owner,
+		User:               user.Name,
+		ActiveOrganization: user.ActiveOrganization,
+		Code:               util.GenerateClientId(),
+		AccessToken:        accessToken,
+		RefreshToken:       refreshToken,
+		ExpiresIn:          application.ExpireInHours * hourSeconds,
+		Scope:              scope,
+		TokenType:          "Bearer",
+		CodeIsUsed:         true,
 	}
 	AddToken(token)
 	return token, nil
@@ -15,6 +15,7 @@
 package object
 
 import (
+	"encoding/json"
 	"fmt"
 	"time"
 
@@ -34,6 +35,8 @@ type Claims struct {
 type UserShort struct {
 	Owner string `xorm:"varchar(100) notnull pk" json:"owner"`
 	Name  string `xorm:"varchar(100) notnull pk" json:"name"`
+
+	ActiveOrganization string `xorm:"-" json:"activeOrganization,omitempty"`
 }
 
 type UserWithoutThirdIdp struct {
@@ -70,12 +73,15 @@ type UserWithoutThirdIdp struct {
 	Score               int               `json:"score"`
 	Karma               int               `json:"karma"`
 	Ranking             int               `json:"ranking"`
+	VerificationLevel   int               `json:"verificationLevel"`
+	VerifiedTime        string            `xorm:"varchar(100)" json:"verifiedTime"`
 	IsDefaultAvatar     bool              `json:"isDefaultAvatar"`
 	IsOnline            bool              `json:"isOnline"`
 	IsAdmin             bool              `json:"isAdmin"`
 	IsGlobalAdmin       bool              `json:"isGlobalAdmin"`
 	IsForbidden         bool              `json:"isForbidden"`
 	IsDeleted           bool              `json:"isDeleted"`
+	IsGuest             bool              `json:"isGuest"`
 	SignupApplication   string            `xorm:"varchar(100)" json:"signupApplication"`
 	Hash                string            `xorm:"varchar(100)" json:"hash"`
 	PreHash             string            `xorm:"varchar(100)" json:"preHash"`
@@ -88,6 +94,8 @@ type UserWithoutThirdIdp struct {
 	Permissions         []*Permission     `xorm:"-" json:"permissions"`
 	LastSigninWrongTime string            `xorm:"varchar(100)" json:"lastSigninWrongTime"`
 	SigninWrongTimes    int               `json:"signinWrongTimes"`
+
+	ActiveOrganization string `xorm:"-" json:"activeOrganization,omitempty"`
 }
 
 type ClaimsShort struct {
@@ -111,6 +119,8 @@ func getShortUser(user *User) *UserShort {
 	res := &UserShort{
 		Owner: user.Owner,
 		Name:  user.Name,
+
+		ActiveOrganization: user.ActiveOrganization,
 	}
 	return res
 }
@@ -151,12 +161,15 @@ func getUserWithoutThirdIdp(user *User) *UserWithoutThirdIdp {
 		Score:             user.Score,
 		Karma:             user.Karma,
 		Ranking:           user.Ranking,
+		VerificationLevel: user.VerificationLevel,
+		VerifiedTime:      user.VerifiedTime,
 		IsDefaultAvatar:   user.IsDefaultAvatar,
 		IsOnline:          user.IsOnline,
 		IsAdmin:           user.IsAdmin,
 		IsGlobalAdmin:     user.IsGlobalAdmin,
 		IsForbidden:       user.IsForbidden,
 		IsDeleted:         user.IsDeleted,
+		IsGuest:           user.IsGuest,
 		SignupApplication: user.SignupApplication,
 		Hash:              user.Hash,
 		PreHash:           user.PreHash,
@@ -173,6 +186,8 @@ func getUserWithoutThirdIdp(user *User) *UserWithoutThirdIdp {
 
 		LastSigninWrongTime: user.LastSigninWrongTime,
 		SigninWrongTimes:    user.SigninWrongTimes,
+
+		ActiveOrganization: user.ActiveOrganization,
 	}
 
 	return res
@@ -220,6 +235,38 @@ func refineUser(user *User) *User {
 	return user
 }
 
+// reservedJwtClaims can't be added by the claims of login hooks even when
+// the token format leaves them out, as the applications trust them for the
+// identity and the privileges of the user.
+var reservedJwtClaims = []string{
+	"iss", "sub", "aud", "exp", "nbf", "iat", "jti", "azp", "tokenType", "nonce", "tag", "scope",
+	"owner", "name", "id", "type", "preferred_username", "email", "emailVerified", "phone", "signupApplication",
+	"isAdmin", "isGlobalAdmin", "isForbidden", "isDeleted", "roles", "permissions", "groups", "activeOrganization",
+}
+
+// getClaimsWithHookClaims returns the claims as they are without claims of
+// hooks, otherwise as a map with the claims of hooks added, which never
+// replace the claims of the token.
+func getClaimsWithHookClaims(claims jwt.Claims, hookClaims map[string]interface{}) jwt.Claims {
+	if len(hookClaims) == 0 {
+		return claims
+	}
+
+	res := jwt.MapClaims{}
+	err := json.Unmarshal([]byte(util.StructToJson(claims)), &res)
+	if err != nil {
+		panic(err)
+	}
+
+	for key, value := range hookClaims {
+		if _, ok := res[key]; ok || util.ContainsString(reservedJwtClaims, key) {
+			continue
+		}
+		res[key] = value
+	}
+	return res
+}
+
 func generateJwtToken(application *Application, user *User, nonce string, scope string, host string) (string, string, string, error) {
 	nowTime := time.Now()
 	expireTime := nowTime.Add(time.Duration(application.ExpireInHours) * time.Hour)
@@ -250,6 +297,16 @@ func generateJwtToken(application *Application, user *User, nonce string, scope
 		},
 	}
 
+	hookResult := RunLoginHooks(&LoginHookContext{
+		Event:        LoginHookEventPreTokenIssuance,
+		Organization: application.Organization,
+		Application:  application.Name,
+		User:         user,
+	})
+	if hookResult.Action != LoginHookActionAllow {
+		return "", "", "", NewCodedError(ErrorCodeAccessDenied, hookResult.Message)
+	}
+
 	var token *jwt.Token
 	var refreshToken *jwt.Token
 
@@ -257,17 +314,17 @@ func generateJwtToken(application *Application, user *User, nonce string, scope
 	if application.TokenFormat == "JWT-Empty" {
 		claimsShort := getShortClaims(claims)
 
-		token = jwt.NewWithClaims(jwt.SigningMethodRS256, claimsShort)
+		token = jwt.NewWithClaims(jwt.SigningMethodRS256, getClaimsWithHookClaims(claimsShort, hookResult.Claims))
 		claimsShort.ExpiresAt = jwt.NewNumericDate(refreshExpireTime)
 		claimsShort.TokenType = "refresh-token"
-		refreshToken = jwt.NewWithClaims(jwt.SigningMethodRS256, claimsShort)
+		refreshToken = jwt.NewWithClaims(jwt.SigningMethodRS256, getClaimsWithHookClaims(claimsShort, hookResult.Claims))
 	} else {
 		claimsWithoutThirdIdp := getClaimsWithoutThirdIdp(claims)
 
-		token = jwt.NewWithClaims(jwt.SigningMethodRS256, claimsWithoutThirdIdp)
+		token = jwt.NewWithClaims(jwt.SigningMethodRS256, getClaimsWithHookClaims(claimsWithoutThirdIdp, hookResult.Claims))
 		claimsWithoutThirdIdp.ExpiresAt = jwt.NewNumericDate(refreshExpireTime)
 		claimsWithoutThirdIdp.TokenType = "refresh-token"
-		refreshToken = jwt.NewWithClaims(jwt.SigningMethodRS256, claimsWithoutThirdIdp)
+		refreshToken = jwt.NewWithClaims(jwt.SigningMethodRS256, getClaimsWithHookClaims(claimsWithoutThirdIdp, hookResult.Claims))
 	}
 
 	cert := getCertByApplication(application)
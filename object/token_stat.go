@@ -0,0 +1,437 @@
+// Copyright 2023 The Casdoor Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package object
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/beego/beego/logs"
+	"github.com/casdoor/casdoor/util"
+	"github.com/xorm-io/core"
+)
+
+const (
+	TokenStatEventIssue    = "issue"
+	TokenStatEventRefresh  = "refresh"
+	TokenStatEventUserinfo = "userinfo"
+
+	TokenAnomalyTypeSpike   = "Spike"
+	TokenAnomalyTypeManyIps = "Many IPs"
+
+	// a day is a spike when it counts at least tokenSpikeMinCount events and
+	// tokenSpikeRatio times the daily average of the tokenSpikeBaselineDays
+	// before it
+	tokenSpikeRatio        = 5
+	tokenSpikeMinCount     = 100
+	tokenSpikeBaselineDays = 7
+	// a token is flagged once it's used from more addresses than this
+	tokenMaxClientIps       = 5
+	tokenAnomalyJobInterval = time.Hour
+)
+
+// TokenStat counts the tokens issued and refreshed by an application and
+// the userinfo calls made with its tokens for one day. Name is
+// "<date>/<application>".
+type TokenStat struct {
+	Owner       string `xorm:"varchar(100) notnull pk" json:"owner"`
+	Name        string `xorm:"varchar(255) notnull pk" json:"name"`
+	UpdatedTime string `xorm:"varchar(100)" json:"updatedTime"`
+
+	Date        string `xorm:"varchar(100) index" json:"date"`
+	Application string `xorm:"varchar(100)" json:"application"`
+	Issued      int    `json:"issued"`
+	Refreshed   int    `json:"refreshed"`
+	Userinfo    int    `json:"userinfo"`
+}
+
+// TokenUsage keeps the addresses an access token was used from for the
+// userinfo endpoints, Name is the name of the token.
+type TokenUsage struct {
+	Owner       string `xorm:"varchar(100) notnull pk" json:"owner"`
+	Name        string `xorm:"varchar(100) notnull pk" json:"name"`
+	CreatedTime string `xorm:"varchar(100)" json:"createdTime"`
+	UpdatedTime string `xorm:"varchar(100)" json:"updatedTime"`
+
+	Application string   `xorm:"varchar(100)" json:"application"`
+	User        string   `xorm:"varchar(100)" json:"user"`
+	ClientIps   []string `xorm:"mediumtext" json:"clientIps"`
+	Count       int      `json:"count"`
+}
+
+// TokenAnomaly is a suspicious use of the tokens of an application, kept for
+// a security review. Name is "<date>/<application>/<metric>" for spikes and
+// the name of the token for tokens used from many addresses, so that each
+// anomaly is flagged once.
+type TokenAnomaly struct {
+	Owner       string `xorm:"varchar(100) notnull pk" json:"owner"`
+	Name        string `xorm:"varchar(255) notnull pk" json:"name"`
+	CreatedTime string `xorm:"varchar(100)" json:"createdTime"`
+
+	Type        string   `xorm:"varchar(100)" json:"type"`
+	Date        string   `xorm:"varchar(100)" json:"date"`
+	Application string   `xorm:"varchar(100)" json:"application"`
+	Metric      string   `xorm:"varchar(100)" json:"metric"`
+	Count       int      `json:"count"`
+	Baseline    float64  `json:"baseline"`
+	Token       string   `xorm:"varchar(100)" json:"token"`
+	User        string   `xorm:"varchar(100)" json:"user"`
+	ClientIps   []string `xorm:"mediumtext" json:"clientIps"`
+
+	IsReviewed   bool   `json:"isReviewed"`
+	ReviewedBy   string `xorm:"varchar(100)" json:"reviewedBy"`
+	ReviewedTime string `xorm:"varchar(100)" json:"reviewedTime"`
+}
+
+func getTokenStatColumn(event string) string {
+	switch event {
+	case TokenStatEventRefresh:
+		return "refreshed"
+	case TokenStatEventUserinfo:
+		return "userinfo"
+	default:
+		return "issued"
+	}
+}
+
+func (tokenStat *TokenStat) getCount(column string) int {
+	switch column {
+	case "refreshed":
+		return tokenStat.Refreshed
+	case "userinfo":
+		return tokenStat.Userinfo
+	default:
+		return tokenStat.Issued
+	}
+}
+
+func incrTokenStat(tokenStat *TokenStat, column string) int64 {
+	affected, err := adapter.Engine.ID(core.PK{tokenStat.Owner, tokenStat.Name}).Incr(column).Cols("updated_time").Update(&TokenStat{UpdatedTime: tokenStat.UpdatedTime})
+	if err != nil {
+		panic(err)
+	}
+	return affected
+}
+
+func addTokenStat(organization string, application string, event string) {
+	now := time.Now()
+	tokenStat := &TokenStat{
+		Owner:       organization,
+		UpdatedTime: now.Format(time.RFC3339),
+		Date:        now.Format("2006-01-02"),
+		Application: application,
+	}
+	tokenStat.Name = fmt.Sprintf("%s/%s", tokenStat.Date, tokenStat.Application)
+
+	column := getTokenStatColumn(event)
+	if incrTokenStat(tokenStat, column) != 0 {
+		return
+	}
+
+	switch column {
+	case "refreshed":
+		tokenStat.Refreshed = 1
+	case "userinfo":
+		tokenStat.Userinfo = 1
+	default:
+		tokenStat.Issued = 1
+	}
+	_, err := adapter.Engine.Insert(tokenStat)
+	if err != nil {
+		// inserted by a concurrent request in the meantime
+		incrTokenStat(tokenStat, column)
+	}
+}
+
+// AddTokenStat counts a token issued or refreshed by the application with
+// the client ID.
+func AddTokenStat(clientId string, event string) {
+	application := GetApplicationByClientId(clientId)
+	if application == nil {
+		return
+	}
+
+	addTokenStat(application.Organization, application.Name, event)
+}
+
+// AddTokenUserinfo counts a userinfo call made with the token from the
+// address, and flags the token once it's used from too many addresses.
+func AddTokenUserinfo(token *Token, clientIp string) {
+	addTokenStat(token.Organization, token.Application, TokenStatEventUserinfo)
+
+	now := util.GetCurrentTime()
+	tokenUsage := getTokenUsage(token.Organization, token.Name)
+	if tokenUsage == nil {
+		tokenUsage = &TokenUsage{
+			Owner:       token.Organization,
+			Name:        token.Name,
+			CreatedTime: now,
+			UpdatedTime: now,
+			Application: token.Application,
+			User:        token.User,
+			ClientIps:   []string{clientIp},
+			Count:       1,
+		}
+		_, err := adapter.Engine.Insert(tokenUsage)
+		if err != nil {
+			panic(err)
+		}
+		return
+	}
+
+	tokenUsage.UpdatedTime = now
+	tokenUsage.Count += 1
+	isNewIp := !util.ContainsString(tokenUsage.ClientIps, clientIp)
+	// the addresses beyond the limit aren't needed to flag the token
+	if isNewIp && len(tokenUsage.ClientIps) <= tokenMaxClientIps {
+		tokenUsage.ClientIps = append(tokenUsage.ClientIps, clientIp)
+	}
+	_, err := adapter.Engine.ID(core.PK{tokenUsage.Owner, tokenUsage.Name}).Cols("updated_time", "count", "client_ips").Update(tokenUsage)
+	if err != nil {
+		panic(err)
+	}
+
+	if isNewIp && len(tokenUsage.ClientIps) > tokenMaxClientIps {
+		addTokenAnomaly(&TokenAnomaly{
+			Owner:       tokenUsage.Owner,
+			Name:        tokenUsage.Name,
+			CreatedTime: now,
+			Type:        TokenAnomalyTypeManyIps,
+			Date:        time.Now().Format("2006-01-02"),
+			Application: tokenUsage.Application,
+			Metric:      "clientIps",
+			Count:       len(tokenUsage.ClientIps),
+			Baseline:    tokenMaxClientIps,
+			Token:       tokenUsage.Name,
+			User:        tokenUsage.User,
+			ClientIps:   tokenUsage.ClientIps,
+		})
+	}
+}
+
+func getTokenUsage(owner string, name string) *TokenUsage {
+	tokenUsage := TokenUsage{Owner: owner, Name: name}
+	existed, err := adapter.Engine.Get(&tokenUsage)
+	if err != nil {
+		panic(err)
+	}
+
+	if existed {
+		return &tokenUsage
+	}
+	return nil
+}
+
+// GetTokenStats returns the daily token stats of the organization between
+// the dates (inclusive, "2006-01-02"), empty dates leave the range open and
+// an empty application returns all of them.
+func GetTokenStats(organization string, startDate string, endDate string, application string) []*TokenStat {
+	tokenStats := []*TokenStat{}
+	session := adapter.Engine.Where("owner = ?", organization)
+	if startDate != "" {
+		session = session.And("date >= ?", startDate)
+	}
+	if endDate != "" {
+		session = session.And("date <= ?", endDate)
+	}
+	if application != "" {
+		session = session.And("application = ?", application)
+	}
+	err := session.Asc("date").Asc("application").Find(&tokenStats)
+	if err != nil {
+		panic(err)
+	}
+
+	return tokenStats
+}
+
+// isTokenSpike tells whether the count of a day is a spike compared to the
+// counts of the days before it, and returns their daily average. Days
+// without a stat count as zero, but an application without any history
+// isn't flagged.
+func isTokenSpike(count int, previousCounts []int) (bool, float64) {
+	if len(previousCounts) == 0 {
+		return false, 0
+	}
+
+	sum := 0
+	for _, previousCount := range previousCounts {
+		sum += previousCount
+	}
+	baseline := float64(sum) / tokenSpikeBaselineDays
+
+	return count >= tokenSpikeMinCount && float64(count) >= tokenSpikeRatio*baseline, baseline
+}
+
+// getTokenSpikes returns the spikes of the stats of the date, the stats
+// hold the date and the tokenSpikeBaselineDays before it.
+func getTokenSpikes(tokenStats []*TokenStat, date string) []*TokenAnomaly {
+	previousStats := map[string][]*TokenStat{}
+	for _, tokenStat := range tokenStats {
+		if tokenStat.Date < date {
+			key := util.GetId(tokenStat.Owner, tokenStat.Application)
+			previousStats[key] = append(previousStats[key], tokenStat)
+		}
+	}
+
+	anomalies := []*TokenAnomaly{}
+	for _, tokenStat := range tokenStats {
+		if tokenStat.Date != date {
+			continue
+		}
+
+		previous := previousStats[util.GetId(tokenStat.Owner, tokenStat.Application)]
+		for _, column := range []string{"issued", "refreshed", "userinfo"} {
+			previousCounts := []int{}
+			for _, previousStat := range previous {
+				previousCounts = append(previousCounts, previousStat.getCount(column))
+			}
+
+			count := tokenStat.getCount(column)
+			if spike, baseline := isTokenSpike(count, previousCounts); spike {
+				anomalies = append(anomalies, &TokenAnomaly{
+					Owner:       tokenStat.Owner,
+					Name:        fmt.Sprintf("%s/%s", tokenStat.Name, column),
+					Type:        TokenAnomalyTypeSpike,
+					Date:        tokenStat.Date,
+					Application: tokenStat.Application,
+					Metric:      column,
+					Count:       count,
+					Baseline:    baseline,
+				})
+			}
+		}
+	}
+
+	sort.Slice(anomalies, func(i, j int) bool {
+		if anomalies[i].Owner != anomalies[j].Owner {
+			return anomalies[i].Owner < anomalies[j].Owner
+		}
+		return anomalies[i].Name < anomalies[j].Name
+	})
+	return anomalies
+}
+
+func getTokenAnomaly(owner string, name string) *TokenAnomaly {
+	tokenAnomaly := TokenAnomaly{Owner: owner, Name: name}
+	existed, err := adapter.Engine.Get(&tokenAnomaly)
+	if err != nil {
+		panic(err)
+	}
+
+	if existed {
+		return &tokenAnomaly
+	}
+	return nil
+}
+
+func GetTokenAnomaly(id string) *TokenAnomaly {
+	owner, name := util.GetOwnerAndNameFromIdNoCheck(id)
+	return getTokenAnomaly(owner, name)
+}
+
+// addTokenAnomaly flags the anomaly unless it already is, and emits the
+// "token-anomaly" event.
+func addTokenAnomaly(tokenAnomaly *TokenAnomaly) {
+	if getTokenAnomaly(tokenAnomaly.Owner, tokenAnomaly.Name) != nil {
+		return
+	}
+
+	if tokenAnomaly.CreatedTime == "" {
+		tokenAnomaly.CreatedTime = util.GetCurrentTime()
+	}
+	_, err := adapter.Engine.Insert(tokenAnomaly)
+	if err != nil {
+		// flagged by another node in the meantime
+		return
+	}
+
+	detail := fmt.Sprintf("%s: %d, baseline: %.1f", tokenAnomaly.Metric, tokenAnomaly.Count, tokenAnomaly.Baseline)
+	if tokenAnomaly.Token != "" {
+		detail = fmt.Sprintf("token: %s, IPs: %s", tokenAnomaly.Token, strings.Join(tokenAnomaly.ClientIps, ", "))
+	}
+	logs.Warning(fmt.Sprintf("token anomaly: %s for the application: %s of organization: %s, %s", tokenAnomaly.Type, tokenAnomaly.Application, tokenAnomaly.Owner, detail))
+
+	record := &Record{
+		Name:         util.GenerateId(),
+		CreatedTime:  util.GetCurrentTime(),
+		Organization: tokenAnomaly.Owner,
+		User:         tokenAnomaly.User,
+		Method:       "POST",
+		RequestUri:   fmt.Sprintf("/api/get-token-anomalies?id=admin/%s", tokenAnomaly.Owner),
+		Action:       "token-anomaly",
+	}
+	AddRecord(record)
+}
+
+// GetTokenAnomalies returns the anomalies of the organization, the latest
+// first, only the ones not reviewed yet unless all is set.
+func GetTokenAnomalies(organization string, all bool) []*TokenAnomaly {
+	tokenAnomalies := []*TokenAnomaly{}
+	session := adapter.Engine.Where("owner = ?", organization)
+	if !all {
+		session = session.And("is_reviewed = ?", false)
+	}
+	err := session.Desc("created_time").Find(&tokenAnomalies)
+	if err != nil {
+		panic(err)
+	}
+
+	return tokenAnomalies
+}
+
+// ReviewTokenAnomaly marks the anomaly as reviewed by the user.
+func ReviewTokenAnomaly(tokenAnomaly *TokenAnomaly, reviewer string) bool {
+	tokenAnomaly.IsReviewed = true
+	tokenAnomaly.ReviewedBy = reviewer
+	tokenAnomaly.ReviewedTime = util.GetCurrentTime()
+	affected, err := adapter.Engine.ID(core.PK{tokenAnomaly.Owner, tokenAnomaly.Name}).Cols("is_reviewed", "reviewed_by", "reviewed_time").Update(tokenAnomaly)
+	if err != nil {
+		panic(err)
+	}
+
+	return affected != 0
+}
+
+// detectTokenSpikes flags the spikes of the day so far.
+func detectTokenSpikes() error {
+	now := time.Now()
+	date := now.Format("2006-01-02")
+	startDate := now.AddDate(0, 0, -tokenSpikeBaselineDays).Format("2006-01-02")
+
+	tokenStats := []*TokenStat{}
+	err := adapter.Engine.Where("date >= ? and date <= ?", startDate, date).Find(&tokenStats)
+	if err != nil {
+		return err
+	}
+
+	for _, tokenAnomaly := range getTokenSpikes(tokenStats, date) {
+		addTokenAnomaly(tokenAnomaly)
+	}
+	return nil
+}
+
+// RunTokenAnomalyJob looks for spikes of the token stats every hour, once
+// per interval cluster-wide.
+func RunTokenAnomalyJob() {
+	ticker := time.NewTicker(tokenAnomalyJobInterval)
+	defer ticker.Stop()
+	for {
+		RunClusterJob("token-anomaly", tokenAnomalyJobInterval, detectTokenSpikes)
+		<-ticker.C
+	}
+}
@@ -0,0 +1,60 @@
+// Copyright 2023 The Casdoor Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package object
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIsTokenSpike(t *testing.T) {
+	scenarios := []struct {
+		description    string
+		count          int
+		previousCounts []int
+		expected       bool
+		baseline       float64
+	}{
+		{"Without history", 1000, []int{}, false, 0},
+		{"Five times the average", 500, []int{70, 70, 70, 70, 70, 70, 70}, true, 70},
+		{"Below five times the average", 340, []int{70, 70, 70, 70, 70, 70, 70}, false, 70},
+		{"Missing days count as zero", 120, []int{14}, true, 2},
+		{"Below the minimum count", 99, []int{7}, false, 1},
+	}
+
+	for _, scenery := range scenarios {
+		t.Run(scenery.description, func(t *testing.T) {
+			spike, baseline := isTokenSpike(scenery.count, scenery.previousCounts)
+			assert.Equal(t, scenery.expected, spike)
+			assert.Equal(t, scenery.baseline, baseline)
+		})
+	}
+}
+
+func TestGetTokenSpikes(t *testing.T) {
+	tokenStats := []*TokenStat{
+		{Owner: "org", Name: "2023-06-08/app", Date: "2023-06-08", Application: "app", Issued: 800, Refreshed: 20, Userinfo: 199},
+		{Owner: "org", Name: "2023-06-07/app", Date: "2023-06-07", Application: "app", Issued: 70, Refreshed: 10, Userinfo: 280},
+		{Owner: "org", Name: "2023-06-08/app-2", Date: "2023-06-08", Application: "app-2", Issued: 900},
+		{Owner: "org-2", Name: "2023-06-08/app", Date: "2023-06-08", Application: "app", Userinfo: 150},
+		{Owner: "org-2", Name: "2023-06-06/app", Date: "2023-06-06", Application: "app", Userinfo: 7},
+	}
+
+	assert.Equal(t, []*TokenAnomaly{
+		{Owner: "org", Name: "2023-06-08/app/issued", Type: TokenAnomalyTypeSpike, Date: "2023-06-08", Application: "app", Metric: "issued", Count: 800, Baseline: 10},
+		{Owner: "org-2", Name: "2023-06-08/app/userinfo", Type: TokenAnomalyTypeSpike, Date: "2023-06-08", Application: "app", Metric: "userinfo", Count: 150, Baseline: 1},
+	}, getTokenSpikes(tokenStats, "2023-06-08"))
+}
@@ -39,6 +39,7 @@ type User struct {
 	Type              string   `xorm:"varchar(100)" json:"type"`
 	Password          string   `xorm:"varchar(100)" json:"password"`
 	PasswordSalt      string   `xorm:"varchar(100)" json:"passwordSalt"`
+	PasswordType      string   `xorm:"varchar(100)" json:"passwordType"`
 	DisplayName       string   `xorm:"varchar(100)" json:"displayName"`
 	FirstName         string   `xorm:"varchar(100)" json:"firstName"`
 	LastName          string   `xorm:"varchar(100)" json:"lastName"`
@@ -65,12 +66,16 @@ type User struct {
 	Score             int      `json:"score"`
 	Karma             int      `json:"karma"`
 	Ranking           int      `json:"ranking"`
+	VerificationLevel int      `json:"verificationLevel"`
+	VerifiedTime      string   `xorm:"varchar(100)" json:"verifiedTime"`
 	IsDefaultAvatar   bool     `json:"isDefaultAvatar"`
 	IsOnline          bool     `json:"isOnline"`
 	IsAdmin           bool     `json:"isAdmin"`
 	IsGlobalAdmin     bool     `json:"isGlobalAdmin"`
 	IsForbidden       bool     `json:"isForbidden"`
 	IsDeleted         bool     `json:"isDeleted"`
+	IsGuest           bool     `json:"isGuest"`
+	GuestDevice       string   `xorm:"varchar(100) index" json:"-"`
 	SignupApplication string   `xorm:"varchar(100)" json:"signupApplication"`
 	Hash              string   `xorm:"varchar(100)" json:"hash"`
 	PreHash           string   `xorm:"varchar(100)" json:"preHash"`
@@ -155,6 +160,7 @@ type User struct {
 	Custom          string `xorm:"custom varchar(100)" json:"custom"`
 
 	WebauthnCredentials []webauthn.Credential `xorm:"webauthnCredentials blob" json:"webauthnCredentials"`
+	SecurityAnswers     []*SecurityAnswer     `xorm:"mediumtext" json:"securityAnswers"`
 
 	Ldap       string            `xorm:"ldap varchar(100)" json:"ldap"`
 	Properties map[string]string `json:"properties"`
@@ -166,6 +172,8 @@ type User struct {
 	SigninWrongTimes    int    `json:"signinWrongTimes"`
 
 	ManagedAccounts []ManagedAccount `xorm:"managedAccounts blob" json:"managedAccounts"`
+
+	ActiveOrganization string `xorm:"-" json:"activeOrganization,omitempty"`
 }
 
 type Userinfo struct {
@@ -188,38 +196,66 @@ type ManagedAccount struct {
 }
 
 func GetGlobalUserCount(field, value string) int {
-	session := GetSession("", -1, -1, field, value, "", "")
-	count, err := session.Count(&User{})
-	if err != nil {
-		panic(err)
+	res := 0
+	for _, engine := range getUserEngines() {
+		session := getEngineSession(engine, "", -1, -1, field, value, "", "")
+		count, err := session.Count(&User{})
+		if err != nil {
+			panic(err)
+		}
+		res += int(count)
 	}
 
-	return int(count)
+	return res
 }
 
 func GetGlobalUsers() []*User {
 	users := []*User{}
-	err := adapter.Engine.Desc("created_time").Find(&users)
-	if err != nil {
-		panic(err)
+	for _, engine := range getUserEngines() {
+		err := engine.Desc("created_time").Find(&users)
+		if err != nil {
+			panic(err)
+		}
 	}
 
+	sortUsers(users, "", "")
 	return users
 }
 
 func GetPaginationGlobalUsers(offset, limit int, field, value, sortField, sortOrder string) []*User {
 	users := []*User{}
-	session := GetSession("", offset, limit, field, value, sortField, sortOrder)
-	err := session.Find(&users)
-	if err != nil {
-		panic(err)
+	engines := getUserEngines()
+	if len(engines) == 1 {
+		session := GetSession("", offset, limit, field, value, sortField, sortOrder)
+		err := session.Find(&users)
+		if err != nil {
+			panic(err)
+		}
+
+		return users
 	}
 
-	return users
+	// the page is cut from the first offset+limit users of every database
+	for _, engine := range engines {
+		session := getEngineSession(engine, "", 0, offset+limit, field, value, sortField, sortOrder)
+		err := session.Find(&users)
+		if err != nil {
+			panic(err)
+		}
+	}
+	sortUsers(users, sortField, sortOrder)
+
+	if offset > len(users) {
+		offset = len(users)
+	}
+	if offset+limit < len(users) {
+		users = users[:offset+limit]
+	}
+	return users[offset:]
 }
 
 func GetUserCount(owner, field, value string) int {
-	session := GetSession(owner, -1, -1, field, value, "", "")
+	session := getEngineSession(getUserEngine(owner), owner, -1, -1, field, value, "", "")
 	count, err := session.Count(&User{})
 	if err != nil {
 		panic(err)
@@ -229,7 +265,7 @@ func GetUserCount(owner, field, value string) int {
 }
 
 func GetOnlineUserCount(owner string, isOnline int) int {
-	count, err := adapter.Engine.Where("is_online = ?", isOnline).Count(&User{Owner: owner})
+	count, err := getUserEngine(owner).Where("is_online = ?", isOnline).Count(&User{Owner: owner})
 	if err != nil {
 		panic(err)
 	}
@@ -239,7 +275,7 @@ func GetOnlineUserCount(owner string, isOnline int) int {
 
 func GetUsers(owner string) []*User {
 	users := []*User{}
-	err := adapter.Engine.Desc("created_time").Find(&users, &User{Owner: owner})
+	err := getUserEngine(owner).Desc("created_time").Find(&users, &User{Owner: owner})
 	if err != nil {
 		panic(err)
 	}
@@ -249,7 +285,7 @@ func GetUsers(owner string) []*User {
 
 func GetSortedUsers(owner string, sorter string, limit int) []*User {
 	users := []*User{}
-	err := adapter.Engine.Desc(sorter).Limit(limit, 0).Find(&users, &User{Owner: owner})
+	err := getUserEngine(owner).Desc(sorter).Limit(limit, 0).Find(&users, &User{Owner: owner})
 	if err != nil {
 		panic(err)
 	}
@@ -259,7 +295,7 @@ func GetSortedUsers(owner string, sorter string, limit int) []*User {
 
 func GetPaginationUsers(owner string, offset, limit int, field, value, sortField, sortOrder string) []*User {
 	users := []*User{}
-	session := GetSession(owner, offset, limit, field, value, sortField, sortOrder)
+	session := getEngineSession(getUserEngine(owner), owner, offset, limit, field, value, sortField, sortOrder)
 	err := session.Find(&users)
 	if err != nil {
 		panic(err)
@@ -274,7 +310,7 @@ func getUser(owner string, name string) *User {
 	}
 
 	user := User{Owner: owner, Name: name}
-	existed, err := adapter.Engine.Get(&user)
+	existed, err := getUserEngine(owner).Get(&user)
 	if err != nil {
 		panic(err)
 	}
@@ -292,7 +328,7 @@ func getUserById(owner string, id string) *User {
 	}
 
 	user := User{Owner: owner, Id: id}
-	existed, err := adapter.Engine.Get(&user)
+	existed, err := getUserEngine(owner).Get(&user)
 	if err != nil {
 		panic(err)
 	}
@@ -308,17 +344,19 @@ func getUserByWechatId(wechatOpenId string, wechatUnionId string) *User {
 	if wechatUnionId == "" {
 		wechatUnionId = wechatOpenId
 	}
-	user := &User{}
-	existed, err := adapter.Engine.Where("wechat = ? OR wechat = ?", wechatOpenId, wechatUnionId).Get(user)
-	if err != nil {
-		panic(err)
-	}
+	for _, engine := range getUserEngines() {
+		user := &User{}
+		existed, err := engine.Where("wechat = ? OR wechat = ?", wechatOpenId, wechatUnionId).Get(user)
+		if err != nil {
+			panic(err)
+		}
 
-	if existed {
-		return user
-	} else {
-		return nil
+		if existed {
+			return user
+		}
 	}
+
+	return nil
 }
 
 func GetUserByEmail(owner string, email string) *User {
@@ -327,7 +365,7 @@ func GetUserByEmail(owner string, email string) *User {
 	}
 
 	user := User{Owner: owner, Email: email}
-	existed, err := adapter.Engine.Get(&user)
+	existed, err := getUserEngine(owner).Get(&user)
 	if err != nil {
 		panic(err)
 	}
@@ -345,7 +383,7 @@ func GetUserByPhone(owner string, phone string) *User {
 	}
 
 	user := User{Owner: owner, Phone: phone}
-	existed, err := adapter.Engine.Get(&user)
+	existed, err := getUserEngine(owner).Get(&user)
 	if err != nil {
 		panic(err)
 	}
@@ -363,7 +401,7 @@ func GetUserByUserId(owner string, userId string) *User {
 	}
 
 	user := User{Owner: owner, Id: userId}
-	existed, err := adapter.Engine.Get(&user)
+	existed, err := getUserEngine(owner).Get(&user)
 	if err != nil {
 		panic(err)
 	}
@@ -399,6 +437,11 @@ func GetMaskedUser(user *User) *User {
 			manageAccount.Password = "***"
 		}
 	}
+
+	for _, securityAnswer := range user.SecurityAnswers {
+		securityAnswer.Salt = ""
+		securityAnswer.Hash = ""
+	}
 	return user
 }
 
@@ -411,7 +454,7 @@ func GetMaskedUsers(users []*User) []*User {
 
 func GetLastUser(owner string) *User {
 	user := User{Owner: owner}
-	existed, err := adapter.Engine.Desc("created_time", "id").Get(&user)
+	existed, err := getUserEngine(owner).Desc("created_time", "id").Get(&user)
 	if err != nil {
 		panic(err)
 	}
@@ -458,14 +501,41 @@ func UpdateUser(id string, user *User, columns []string, isGlobalAdmin bool) boo
 		columns = append(columns, "name", "email", "phone", "country_code")
 	}
 
-	affected, err := adapter.Engine.ID(core.PK{owner, name}).Cols(columns...).Update(user)
+	affected, err := getUserEngine(owner).ID(core.PK{owner, name}).Cols(columns...).Update(user)
 	if err != nil {
 		panic(err)
 	}
 
+	if affected != 0 && user.Avatar != oldUser.Avatar && util.ContainsString(columns, "avatar") {
+		processUserAvatarAsync(user)
+	}
 	return affected != 0
 }
 
+// adminUserColumns aren't account items, they are set for the users by the
+// verification, guest, password and sign-in flows, so only the admins of the
+// organization can update them directly.
+var adminUserColumns = []string{
+	"password_salt", "password_type", "email_verified", "score", "karma", "ranking",
+	"verification_level", "verified_time", "is_guest", "security_answers",
+	"signin_wrong_times", "last_signin_wrong_time", "created_ip", "ldap",
+}
+
+// GetAdminUserColumn returns the first of columns that only admins can
+// update. The columns are compared without case and "_", as both
+// "verification_level" and "verificationLevel" update the same column.
+func GetAdminUserColumn(columns []string) string {
+	for _, column := range columns {
+		normalized := strings.ToLower(strings.ReplaceAll(strings.TrimSpace(column), "_", ""))
+		for _, adminColumn := range adminUserColumns {
+			if normalized == strings.ReplaceAll(adminColumn, "_", "") {
+				return column
+			}
+		}
+	}
+	return ""
+}
+
 func UpdateUserForAllFields(id string, user *User) bool {
 	owner, name := util.GetOwnerAndNameFromId(id)
 	oldUser := getUser(owner, name)
@@ -486,7 +556,7 @@ func UpdateUserForAllFields(id string, user *User) bool {
 		user.PermanentAvatar = getPermanentAvatarUrl(user.Owner, user.Name, user.Avatar, false)
 	}
 
-	affected, err := adapter.Engine.ID(core.PK{owner, name}).AllCols().Update(user)
+	affected, err := getUserEngine(owner).ID(core.PK{owner, name}).AllCols().Update(user)
 	if err != nil {
 		panic(err)
 	}
@@ -517,11 +587,14 @@ func AddUser(user *User) bool {
 
 	user.Ranking = GetUserCount(user.Owner, "", "") + 1
 
-	affected, err := adapter.Engine.Insert(user)
+	affected, err := getUserEngine(user.Owner).Insert(user)
 	if err != nil {
 		panic(err)
 	}
 
+	if affected != 0 {
+		processUserAvatarAsync(user)
+	}
 	return affected != 0
 }
 
@@ -541,13 +614,16 @@ func AddUsers(users []*User) bool {
 		user.PermanentAvatar = getPermanentAvatarUrl(user.Owner, user.Name, user.Avatar, true)
 	}
 
-	affected, err := adapter.Engine.Insert(users)
+	affected, err := getUserEngine(users[0].Owner).Insert(users)
 	if err != nil {
 		if !strings.Contains(err.Error(), "Duplicate entry") {
 			panic(err)
 		}
 	}
 
+	if affected != 0 {
+		processUsersAvatarsAsync(users)
+	}
 	return affected != 0
 }
 
@@ -581,7 +657,7 @@ func DeleteUser(user *User) bool {
 	// Forced offline the user first
 	DeleteSession(util.GetSessionId(user.Owner, user.Name, CasdoorApplication))
 
-	affected, err := adapter.Engine.ID(core.PK{user.Owner, user.Name}).Delete(&User{})
+	affected, err := getUserEngine(user.Owner).ID(core.PK{user.Owner, user.Name}).Delete(&User{})
 	if err != nil {
 		panic(err)
 	}
@@ -622,6 +698,14 @@ func (user *User) GetId() string {
 	return fmt.Sprintf("%s/%s", user.Owner, user.Name)
 }
 
+func (user *User) getRolesString() string {
+	roles := []string{}
+	for _, role := range user.Roles {
+		roles = append(roles, role.Name)
+	}
+	return strings.Join(roles, ",")
+}
+
 func isUserIdGlobalAdmin(userId string) bool {
 	return strings.HasPrefix(userId, "built-in/")
 }
@@ -631,8 +715,28 @@ func ExtendUserWithRolesAndPermissions(user *User) {
 		return
 	}
 
-	user.Roles = GetRolesByUser(user.GetId())
+	user.Roles = GetAllRolesByUser(user.GetId())
 	user.Permissions = GetPermissionsByUser(user.GetId())
+
+	// with an active organization, only the roles and permissions of that
+	// organization apply
+	if user.ActiveOrganization != "" {
+		roles := []*Role{}
+		for _, role := range user.Roles {
+			if role.Owner == user.ActiveOrganization {
+				roles = append(roles, role)
+			}
+		}
+		user.Roles = roles
+
+		permissions := []*Permission{}
+		for _, permission := range user.Permissions {
+			if permission.Owner == user.ActiveOrganization {
+				permissions = append(permissions, permission)
+			}
+		}
+		user.Permissions = permissions
+	}
 }
 
 func userChangeTrigger(oldName string, newName string) error {
@@ -31,9 +31,41 @@ func (user *User) UpdateUserHash() {
 }
 
 func (user *User) UpdateUserPassword(organization *Organization) {
-	credManager := cred.GetCredManager(organization.PasswordType)
+	credManager := organization.getCredManager()
 	if credManager != nil {
 		hashedPassword := credManager.GetHashedPassword(user.Password, user.PasswordSalt, organization.PasswordSalt)
 		user.Password = hashedPassword
+		user.PasswordType = organization.PasswordType
 	}
 }
+
+// getPasswordType returns the type of the stored password hash. Users
+// imported from other systems keep the hash type of that system until they
+// set a new password, the others use the type of their organization.
+func (user *User) getPasswordType(organization *Organization) string {
+	if user.PasswordType != "" {
+		return user.PasswordType
+	}
+	return organization.PasswordType
+}
+
+// needsRehash tells whether the password hash of the user was made with
+// weaker parameters than the ones of the organization.
+func (user *User) needsRehash(credManager cred.CredManager) bool {
+	rehashCredManager, ok := credManager.(cred.RehashCredManager)
+	return ok && rehashCredManager.NeedsRehash(user.Password)
+}
+
+// rehashUserPassword replaces a password hash imported from another system,
+// or made with weaker parameters, by a hash of the organization's type, once
+// the plain password is known at login. Organizations with plain passwords
+// keep the imported hash.
+func rehashUserPassword(user *User, organization *Organization, password string) {
+	if organization.PasswordType == "plain" || organization.getCredManager() == nil {
+		return
+	}
+
+	user.Password = password
+	user.UpdateUserPassword(organization)
+	UpdateUser(user.GetId(), user, []string{"password", "password_type", "hash"}, user.IsGlobalAdmin)
+}
@@ -21,6 +21,7 @@ import (
 	"testing"
 
 	"github.com/casdoor/casdoor/util"
+	"github.com/stretchr/testify/assert"
 	"github.com/xorm-io/core"
 )
 
@@ -130,3 +131,11 @@ func TestGetEmailsForUsers(t *testing.T) {
 	text := strings.Join(emails, "\n")
 	println(text)
 }
+
+func TestGetAdminUserColumn(t *testing.T) {
+	assert.Equal(t, "", GetAdminUserColumn(nil))
+	assert.Equal(t, "", GetAdminUserColumn([]string{"display_name", "bio"}))
+	assert.Equal(t, "verification_level", GetAdminUserColumn([]string{"bio", "verification_level", "verified_time"}))
+	assert.Equal(t, "isGuest", GetAdminUserColumn([]string{"isGuest"}))
+	assert.Equal(t, " Security_Answers", GetAdminUserColumn([]string{" Security_Answers"}))
+}
@@ -15,6 +15,7 @@
 package object
 
 import (
+	"github.com/casdoor/casdoor/cred"
 	"github.com/casdoor/casdoor/util"
 	"github.com/casdoor/casdoor/xlsx"
 )
@@ -47,7 +48,7 @@ func parseLineItemBool(line *[]string, i int) bool {
 	return parseLineItemInt(line, i) != 0
 }
 
-func UploadUsers(owner string, fileId string) bool {
+func UploadUsers(owner string, fileId string, lang string) (bool, error) {
 	table := xlsx.ReadXlsxFile(fileId)
 
 	oldUserMap := getUserMap(owner)
@@ -105,6 +106,8 @@ func UploadUsers(owner string, fileId string) bool {
 			Ldap:              "",
 			Properties:        map[string]string{},
 		}
+		// hashes imported from other systems are verified with their own type
+		user.PasswordType = cred.GetPasswordTypeByHash(user.Password)
 
 		if _, ok := oldUserMap[user.GetId()]; !ok {
 			newUsers = append(newUsers, user)
@@ -112,7 +115,12 @@ func UploadUsers(owner string, fileId string) bool {
 	}
 
 	if len(newUsers) == 0 {
-		return false
+		return false, nil
 	}
-	return AddUsersInBatch(newUsers)
+
+	err := CheckUsersSeatLimit(newUsers, lang)
+	if err != nil {
+		return false, err
+	}
+	return AddUsersInBatch(newUsers), nil
 }
@@ -29,7 +29,7 @@ func GetUserByField(organizationName string, field string, value string) *User {
 	}
 
 	user := User{Owner: organizationName}
-	existed, err := adapter.Engine.Where(fmt.Sprintf("%s=?", strings.ToLower(field)), value).Get(&user)
+	existed, err := getUserEngine(organizationName).Where(fmt.Sprintf("%s=?", strings.ToLower(field)), value).Get(&user)
 	if err != nil {
 		panic(err)
 	}
@@ -76,20 +76,22 @@ func GetUserByFields(organization string, field string) *User {
 }
 
 func SetUserField(user *User, field string, value string) bool {
+	bean := map[string]interface{}{strings.ToLower(field): value}
 	if field == "password" {
 		organization := GetOrganizationByUser(user)
 		user.UpdateUserPassword(organization)
-		value = user.Password
+		bean["password"] = user.Password
+		bean["password_type"] = user.PasswordType
 	}
 
-	affected, err := adapter.Engine.Table(user).ID(core.PK{user.Owner, user.Name}).Update(map[string]interface{}{strings.ToLower(field): value})
+	affected, err := getUserEngine(user.Owner).Table(user).ID(core.PK{user.Owner, user.Name}).Update(bean)
 	if err != nil {
 		panic(err)
 	}
 
 	user = getUser(user.Owner, user.Name)
 	user.UpdateUserHash()
-	_, err = adapter.Engine.ID(core.PK{user.Owner, user.Name}).Cols("hash").Update(user)
+	_, err = getUserEngine(user.Owner).ID(core.PK{user.Owner, user.Name}).Cols("hash").Update(user)
 	if err != nil {
 		panic(err)
 	}
@@ -165,7 +167,7 @@ func ClearUserOAuthProperties(user *User, providerType string) bool {
 		}
 	}
 
-	affected, err := adapter.Engine.ID(core.PK{user.Owner, user.Name}).Cols("properties").Update(user)
+	affected, err := getUserEngine(user.Owner).ID(core.PK{user.Owner, user.Name}).Cols("properties").Update(user)
 	if err != nil {
 		panic(err)
 	}
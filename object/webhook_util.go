@@ -15,14 +15,16 @@
 package object
 
 import (
+	"fmt"
 	"net/http"
 	"strings"
+	"time"
 
 	"github.com/casdoor/casdoor/util"
 )
 
 func sendWebhook(webhook *Webhook, record *Record) error {
-	client := &http.Client{}
+	client := &http.Client{Timeout: 30 * time.Second}
 
 	body := strings.NewReader(util.StructToJson(record))
 
@@ -37,6 +39,14 @@ func sendWebhook(webhook *Webhook, record *Record) error {
 		req.Header.Set(header.Name, header.Value)
 	}
 
-	_, err = client.Do(req)
-	return err
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("the webhook: %s returned the status: %s", webhook.GetId(), resp.Status)
+	}
+	return nil
 }
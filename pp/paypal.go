@@ -0,0 +1,314 @@
+// Copyright 2023 The Casdoor Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pp
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type PaypalSubscriptionProvider struct {
+	ClientId     string
+	ClientSecret string
+	WebhookId    string
+	ApiBase      string
+}
+
+type paypalEvent struct {
+	Id        string          `json:"id"`
+	EventType string          `json:"event_type"`
+	Resource  json.RawMessage `json:"resource"`
+}
+
+type paypalAmount struct {
+	Total    string `json:"total"`
+	Currency string `json:"currency"`
+}
+
+type paypalSubscription struct {
+	Id          string `json:"id"`
+	CustomId    string `json:"custom_id"`
+	PlanId      string `json:"plan_id"`
+	BillingInfo struct {
+		NextBillingTime time.Time `json:"next_billing_time"`
+	} `json:"billing_info"`
+	Links []paypalLink `json:"links"`
+}
+
+type paypalSale struct {
+	Id                 string       `json:"id"`
+	SaleId             string       `json:"sale_id"`
+	BillingAgreementId string       `json:"billing_agreement_id"`
+	Custom             string       `json:"custom"`
+	Amount             paypalAmount `json:"amount"`
+}
+
+type paypalLink struct {
+	Href string `json:"href"`
+	Rel  string `json:"rel"`
+}
+
+// NewPaypalSubscriptionProvider uses the live API unless host is given, e.g.
+// "api-m.sandbox.paypal.com".
+func NewPaypalSubscriptionProvider(clientId string, clientSecret string, webhookId string, host string) *PaypalSubscriptionProvider {
+	if host == "" {
+		host = "api-m.paypal.com"
+	}
+
+	return &PaypalSubscriptionProvider{
+		ClientId:     clientId,
+		ClientSecret: clientSecret,
+		WebhookId:    webhookId,
+		ApiBase:      "https://" + strings.TrimPrefix(host, "https://"),
+	}
+}
+
+func (pp *PaypalSubscriptionProvider) getAccessToken() (string, error) {
+	req, err := http.NewRequest("POST", pp.ApiBase+"/v1/oauth2/token", strings.NewReader("grant_type=client_credentials"))
+	if err != nil {
+		return "", err
+	}
+	req.SetBasicAuth(pp.ClientId, pp.ClientSecret)
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	var token struct {
+		AccessToken      string `json:"access_token"`
+		ErrorDescription string `json:"error_description"`
+	}
+	err = json.NewDecoder(resp.Body).Decode(&token)
+	if err != nil {
+		return "", err
+	}
+	if token.AccessToken == "" {
+		return "", fmt.Errorf("paypal error: %s", token.ErrorDescription)
+	}
+	return token.AccessToken, nil
+}
+
+func (pp *PaypalSubscriptionProvider) doRequest(method string, path string, data interface{}, v interface{}) error {
+	accessToken, err := pp.getAccessToken()
+	if err != nil {
+		return err
+	}
+
+	var body io.Reader
+	if data != nil {
+		dataBytes, err := json.Marshal(data)
+		if err != nil {
+			return err
+		}
+		body = bytes.NewReader(dataBytes)
+	}
+
+	req, err := http.NewRequest(method, pp.ApiBase+path, body)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+accessToken)
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	respBytes, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("paypal error: %s", string(respBytes))
+	}
+
+	if v == nil || len(respBytes) == 0 {
+		return nil
+	}
+	return json.Unmarshal(respBytes, v)
+}
+
+func getPaypalApproveUrl(links []paypalLink) string {
+	for _, link := range links {
+		if link.Rel == "approve" {
+			return link.Href
+		}
+	}
+	return ""
+}
+
+func (pp *PaypalSubscriptionProvider) Subscribe(planId string, reference string, returnUrl string) (string, string, error) {
+	data := map[string]interface{}{
+		"plan_id":   planId,
+		"custom_id": reference,
+		"application_context": map[string]string{
+			"return_url": returnUrl,
+			"cancel_url": returnUrl,
+		},
+	}
+
+	subscription := paypalSubscription{}
+	err := pp.doRequest("POST", "/v1/billing/subscriptions", data, &subscription)
+	if err != nil {
+		return "", "", err
+	}
+
+	return getPaypalApproveUrl(subscription.Links), subscription.Id, nil
+}
+
+// ChangePlan revises the plan, which PayPal doesn't prorate: the new price
+// applies from the next billing cycle once the payer approves it.
+func (pp *PaypalSubscriptionProvider) ChangePlan(subscriptionId string, planId string) (string, error) {
+	subscription := paypalSubscription{}
+	err := pp.doRequest("POST", fmt.Sprintf("/v1/billing/subscriptions/%s/revise", url.PathEscape(subscriptionId)), map[string]string{"plan_id": planId}, &subscription)
+	if err != nil {
+		return "", err
+	}
+
+	return getPaypalApproveUrl(subscription.Links), nil
+}
+
+func (pp *PaypalSubscriptionProvider) Cancel(subscriptionId string) error {
+	return pp.doRequest("POST", fmt.Sprintf("/v1/billing/subscriptions/%s/cancel", url.PathEscape(subscriptionId)), map[string]string{"reason": "Canceled by the user"}, nil)
+}
+
+// verifyWebhook asks PayPal to check the signature headers of the webhook,
+// which is simpler than verifying the certificate chain of PayPal locally.
+func (pp *PaypalSubscriptionProvider) verifyWebhook(request *http.Request, body []byte) error {
+	// PayPal can't tell the events of this webhook from the ones of others
+	if pp.WebhookId == "" {
+		return fmt.Errorf("the webhook ID of the PayPal provider is empty")
+	}
+
+	data := map[string]interface{}{
+		"auth_algo":         request.Header.Get("PAYPAL-AUTH-ALGO"),
+		"cert_url":          request.Header.Get("PAYPAL-CERT-URL"),
+		"transmission_id":   request.Header.Get("PAYPAL-TRANSMISSION-ID"),
+		"transmission_sig":  request.Header.Get("PAYPAL-TRANSMISSION-SIG"),
+		"transmission_time": request.Header.Get("PAYPAL-TRANSMISSION-TIME"),
+		"webhook_id":        pp.WebhookId,
+		"webhook_event":     json.RawMessage(body),
+	}
+
+	var res struct {
+		VerificationStatus string `json:"verification_status"`
+	}
+	err := pp.doRequest("POST", "/v1/notifications/verify-webhook-signature", data, &res)
+	if err != nil {
+		return err
+	}
+	if res.VerificationStatus != "SUCCESS" {
+		return fmt.Errorf("the PayPal webhook signature is invalid")
+	}
+	return nil
+}
+
+func getPaypalAmount(amount paypalAmount) float64 {
+	price, _ := strconv.ParseFloat(amount.Total, 64)
+	return price
+}
+
+// parsePaypalEvent normalizes the event, the period end of a renewal isn't
+// part of the sale and is filled in by ParseWebhook.
+func parsePaypalEvent(body []byte) (*SubscriptionEvent, error) {
+	event := paypalEvent{}
+	err := json.Unmarshal(body, &event)
+	if err != nil {
+		return nil, err
+	}
+
+	res := &SubscriptionEvent{Id: event.Id}
+	switch event.EventType {
+	case "BILLING.SUBSCRIPTION.ACTIVATED", "BILLING.SUBSCRIPTION.UPDATED", "BILLING.SUBSCRIPTION.PAYMENT.FAILED", "BILLING.SUBSCRIPTION.CANCELLED", "BILLING.SUBSCRIPTION.EXPIRED":
+		subscription := paypalSubscription{}
+		err = json.Unmarshal(event.Resource, &subscription)
+		if err != nil {
+			return res, err
+		}
+		res.SubscriptionId = subscription.Id
+		res.Reference = subscription.CustomId
+		switch event.EventType {
+		case "BILLING.SUBSCRIPTION.ACTIVATED":
+			res.Type = SubscriptionEventActivated
+			res.PeriodEnd = subscription.BillingInfo.NextBillingTime
+		case "BILLING.SUBSCRIPTION.UPDATED":
+			res.Type = SubscriptionEventPlanChanged
+			res.PlanId = subscription.PlanId
+		case "BILLING.SUBSCRIPTION.PAYMENT.FAILED":
+			res.Type = SubscriptionEventFailed
+		default:
+			res.Type = SubscriptionEventCanceled
+		}
+	case "PAYMENT.SALE.COMPLETED":
+		sale := paypalSale{}
+		err = json.Unmarshal(event.Resource, &sale)
+		if err != nil || sale.BillingAgreementId == "" {
+			return res, err
+		}
+		res.Type = SubscriptionEventRenewed
+		res.SubscriptionId = sale.BillingAgreementId
+		res.Reference = sale.Custom
+		res.PaymentId = sale.Id
+		res.Amount = getPaypalAmount(sale.Amount)
+		res.Currency = sale.Amount.Currency
+	case "PAYMENT.SALE.REFUNDED":
+		sale := paypalSale{}
+		err = json.Unmarshal(event.Resource, &sale)
+		if err != nil || sale.SaleId == "" {
+			return res, err
+		}
+		res.Type = SubscriptionEventRefunded
+		res.PaymentId = sale.SaleId
+		res.Amount = getPaypalAmount(sale.Amount)
+		res.Currency = sale.Amount.Currency
+	}
+
+	return res, nil
+}
+
+func (pp *PaypalSubscriptionProvider) ParseWebhook(request *http.Request, body []byte) (*SubscriptionEvent, error) {
+	err := pp.verifyWebhook(request, body)
+	if err != nil {
+		return nil, err
+	}
+
+	event, err := parsePaypalEvent(body)
+	if err != nil {
+		return nil, err
+	}
+
+	if event.Type == SubscriptionEventRenewed {
+		subscription := paypalSubscription{}
+		err = pp.doRequest("GET", "/v1/billing/subscriptions/"+url.PathEscape(event.SubscriptionId), nil, &subscription)
+		if err != nil {
+			return nil, err
+		}
+		event.PeriodEnd = subscription.BillingInfo.NextBillingTime
+	}
+	return event, nil
+}
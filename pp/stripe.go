@@ -0,0 +1,315 @@
+// Copyright 2023 The Casdoor Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pp
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	stripeApiBase            = "https://api.stripe.com"
+	stripeSignatureTolerance = 5 * time.Minute
+)
+
+// stripeZeroDecimalCurrencies are charged in the main unit instead of cents
+var stripeZeroDecimalCurrencies = []string{"bif", "clp", "djf", "gnf", "jpy", "kmf", "krw", "mga", "pyg", "rwf", "ugx", "vnd", "vuv", "xaf", "xof", "xpf"}
+
+type StripeSubscriptionProvider struct {
+	SecretKey     string
+	WebhookSecret string
+}
+
+type stripeEvent struct {
+	Id   string `json:"id"`
+	Type string `json:"type"`
+	Data struct {
+		Object json.RawMessage `json:"object"`
+	} `json:"data"`
+}
+
+type stripeCheckoutSession struct {
+	Id                string `json:"id"`
+	Url               string `json:"url"`
+	Subscription      string `json:"subscription"`
+	ClientReferenceId string `json:"client_reference_id"`
+}
+
+type stripeInvoice struct {
+	Id                  string `json:"id"`
+	Subscription        string `json:"subscription"`
+	AmountPaid          int64  `json:"amount_paid"`
+	Currency            string `json:"currency"`
+	SubscriptionDetails struct {
+		Metadata map[string]string `json:"metadata"`
+	} `json:"subscription_details"`
+	Lines struct {
+		Data []struct {
+			Period struct {
+				End int64 `json:"end"`
+			} `json:"period"`
+		} `json:"data"`
+	} `json:"lines"`
+}
+
+type stripeCharge struct {
+	Invoice        string `json:"invoice"`
+	AmountRefunded int64  `json:"amount_refunded"`
+	Currency       string `json:"currency"`
+}
+
+type stripeSubscription struct {
+	Id       string            `json:"id"`
+	Metadata map[string]string `json:"metadata"`
+	Items    struct {
+		Data []struct {
+			Id    string `json:"id"`
+			Price struct {
+				Id string `json:"id"`
+			} `json:"price"`
+		} `json:"data"`
+	} `json:"items"`
+}
+
+type stripeError struct {
+	Error struct {
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+func NewStripeSubscriptionProvider(secretKey string, webhookSecret string) *StripeSubscriptionProvider {
+	return &StripeSubscriptionProvider{
+		SecretKey:     secretKey,
+		WebhookSecret: webhookSecret,
+	}
+}
+
+func getStripeAmount(amount int64, currency string) float64 {
+	for _, zeroDecimalCurrency := range stripeZeroDecimalCurrencies {
+		if strings.EqualFold(currency, zeroDecimalCurrency) {
+			return float64(amount)
+		}
+	}
+	return float64(amount) / 100
+}
+
+func (pp *StripeSubscriptionProvider) doRequest(method string, path string, form url.Values, v interface{}) error {
+	var body io.Reader
+	if form != nil {
+		body = strings.NewReader(form.Encode())
+	}
+
+	req, err := http.NewRequest(method, stripeApiBase+path, body)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+pp.SecretKey)
+	if form != nil {
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	respBytes, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		respError := stripeError{}
+		_ = json.Unmarshal(respBytes, &respError)
+		return fmt.Errorf("stripe error: %s", respError.Error.Message)
+	}
+
+	if v == nil {
+		return nil
+	}
+	return json.Unmarshal(respBytes, v)
+}
+
+func (pp *StripeSubscriptionProvider) Subscribe(planId string, reference string, returnUrl string) (string, string, error) {
+	form := url.Values{}
+	form.Set("mode", "subscription")
+	form.Set("line_items[0][price]", planId)
+	form.Set("line_items[0][quantity]", "1")
+	form.Set("client_reference_id", reference)
+	form.Set("subscription_data[metadata][casdoor_subscription]", reference)
+	form.Set("success_url", returnUrl)
+	form.Set("cancel_url", returnUrl)
+
+	session := stripeCheckoutSession{}
+	err := pp.doRequest("POST", "/v1/checkout/sessions", form, &session)
+	if err != nil {
+		return "", "", err
+	}
+
+	// the subscription is only created when the checkout completes
+	return session.Url, "", nil
+}
+
+func (pp *StripeSubscriptionProvider) ChangePlan(subscriptionId string, planId string) (string, error) {
+	subscription := stripeSubscription{}
+	err := pp.doRequest("GET", "/v1/subscriptions/"+url.PathEscape(subscriptionId), nil, &subscription)
+	if err != nil {
+		return "", err
+	}
+	if len(subscription.Items.Data) != 1 {
+		return "", fmt.Errorf("the Stripe subscription: %s should have exactly one item", subscriptionId)
+	}
+
+	// the prorated difference is invoiced and charged at once
+	form := url.Values{}
+	form.Set("items[0][id]", subscription.Items.Data[0].Id)
+	form.Set("items[0][price]", planId)
+	form.Set("proration_behavior", "always_invoice")
+	return "", pp.doRequest("POST", "/v1/subscriptions/"+url.PathEscape(subscriptionId), form, nil)
+}
+
+func (pp *StripeSubscriptionProvider) Cancel(subscriptionId string) error {
+	return pp.doRequest("DELETE", "/v1/subscriptions/"+url.PathEscape(subscriptionId), nil, nil)
+}
+
+// verifyStripeSignature checks the "Stripe-Signature" header, which has the
+// timestamp as "t" and HMAC-SHA256 signatures of "<t>.<body>" as "v1".
+func verifyStripeSignature(header string, body []byte, secret string, now time.Time) error {
+	timestamp := ""
+	signatures := []string{}
+	for _, item := range strings.Split(header, ",") {
+		pair := strings.SplitN(strings.TrimSpace(item), "=", 2)
+		if len(pair) != 2 {
+			continue
+		}
+
+		switch pair[0] {
+		case "t":
+			timestamp = pair[1]
+		case "v1":
+			signatures = append(signatures, pair[1])
+		}
+	}
+
+	seconds, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return fmt.Errorf("the Stripe signature has no timestamp")
+	}
+	if diff := now.Sub(time.Unix(seconds, 0)); diff > stripeSignatureTolerance || diff < -stripeSignatureTolerance {
+		return fmt.Errorf("the Stripe signature has expired")
+	}
+
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp + "."))
+	mac.Write(body)
+	expected := hex.EncodeToString(mac.Sum(nil))
+	for _, signature := range signatures {
+		if hmac.Equal([]byte(signature), []byte(expected)) {
+			return nil
+		}
+	}
+	return fmt.Errorf("the Stripe signature is invalid")
+}
+
+func parseStripeEvent(body []byte) (*SubscriptionEvent, error) {
+	event := stripeEvent{}
+	err := json.Unmarshal(body, &event)
+	if err != nil {
+		return nil, err
+	}
+
+	res := &SubscriptionEvent{Id: event.Id}
+	switch event.Type {
+	case "checkout.session.completed":
+		session := stripeCheckoutSession{}
+		err = json.Unmarshal(event.Data.Object, &session)
+		if err != nil || session.Subscription == "" {
+			return res, err
+		}
+		res.Type = SubscriptionEventActivated
+		res.SubscriptionId = session.Subscription
+		res.Reference = session.ClientReferenceId
+	case "invoice.paid", "invoice.payment_failed":
+		invoice := stripeInvoice{}
+		err = json.Unmarshal(event.Data.Object, &invoice)
+		if err != nil || invoice.Subscription == "" {
+			return res, err
+		}
+		res.Type = SubscriptionEventRenewed
+		if event.Type == "invoice.payment_failed" {
+			res.Type = SubscriptionEventFailed
+		}
+		res.SubscriptionId = invoice.Subscription
+		res.Reference = invoice.SubscriptionDetails.Metadata["casdoor_subscription"]
+		res.PaymentId = invoice.Id
+		res.Amount = getStripeAmount(invoice.AmountPaid, invoice.Currency)
+		res.Currency = strings.ToUpper(invoice.Currency)
+		for _, line := range invoice.Lines.Data {
+			if periodEnd := time.Unix(line.Period.End, 0); periodEnd.After(res.PeriodEnd) {
+				res.PeriodEnd = periodEnd
+			}
+		}
+	case "charge.refunded":
+		charge := stripeCharge{}
+		err = json.Unmarshal(event.Data.Object, &charge)
+		if err != nil || charge.Invoice == "" {
+			return res, err
+		}
+		res.Type = SubscriptionEventRefunded
+		res.PaymentId = charge.Invoice
+		res.Amount = getStripeAmount(charge.AmountRefunded, charge.Currency)
+		res.Currency = strings.ToUpper(charge.Currency)
+	case "customer.subscription.updated", "customer.subscription.deleted":
+		subscription := stripeSubscription{}
+		err = json.Unmarshal(event.Data.Object, &subscription)
+		if err != nil {
+			return res, err
+		}
+		res.SubscriptionId = subscription.Id
+		res.Reference = subscription.Metadata["casdoor_subscription"]
+		if event.Type == "customer.subscription.deleted" {
+			res.Type = SubscriptionEventCanceled
+		} else if len(subscription.Items.Data) == 1 {
+			res.Type = SubscriptionEventPlanChanged
+			res.PlanId = subscription.Items.Data[0].Price.Id
+		}
+	}
+
+	return res, nil
+}
+
+func (pp *StripeSubscriptionProvider) ParseWebhook(request *http.Request, body []byte) (*SubscriptionEvent, error) {
+	// anyone could sign the events with an empty secret
+	if pp.WebhookSecret == "" {
+		return nil, fmt.Errorf("the webhook secret of the Stripe provider is empty")
+	}
+
+	err := verifyStripeSignature(request.Header.Get("Stripe-Signature"), body, pp.WebhookSecret, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	return parseStripeEvent(body)
+}
@@ -0,0 +1,74 @@
+// Copyright 2023 The Casdoor Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pp
+
+import (
+	"net/http"
+	"time"
+)
+
+const (
+	SubscriptionEventActivated = "Activated"
+	SubscriptionEventRenewed   = "Renewed"
+	SubscriptionEventFailed    = "Failed"
+	SubscriptionEventRefunded  = "Refunded"
+	SubscriptionEventCanceled  = "Canceled"
+
+	SubscriptionEventPlanChanged = "PlanChanged"
+)
+
+// SubscriptionEvent is a webhook event of a payment provider, normalized to
+// what changes the state of a subscription. Type is empty for the events
+// that don't.
+type SubscriptionEvent struct {
+	Id   string
+	Type string
+
+	// SubscriptionId is the ID at the provider, Reference is the name of the
+	// subscription in Casdoor when the event carries it
+	SubscriptionId string
+	Reference      string
+	// PlanId is the plan at the provider, for a changed plan
+	PlanId string
+
+	// PaymentId identifies the charge of a renewal and of its refund
+	PaymentId string
+	Amount    float64
+	Currency  string
+	PeriodEnd time.Time
+}
+
+// SubscriptionProvider is a payment provider that bills subscriptions by
+// itself and reports their lifecycle with webhooks.
+type SubscriptionProvider interface {
+	// Subscribe returns the URL where the payer approves the subscription and,
+	// if the provider creates it at once, its ID
+	Subscribe(planId string, reference string, returnUrl string) (string, string, error)
+	// ChangePlan moves the subscription to another plan, prorated by the
+	// provider when it supports it, and returns a URL if the payer has to
+	// approve the change
+	ChangePlan(subscriptionId string, planId string) (string, error)
+	Cancel(subscriptionId string) error
+	ParseWebhook(request *http.Request, body []byte) (*SubscriptionEvent, error)
+}
+
+func GetSubscriptionProvider(typ string, clientId string, clientSecret string, webhookSecret string, host string) SubscriptionProvider {
+	if typ == "Stripe" {
+		return NewStripeSubscriptionProvider(clientSecret, webhookSecret)
+	} else if typ == "PayPal" {
+		return NewPaypalSubscriptionProvider(clientId, clientSecret, webhookSecret, host)
+	}
+	return nil
+}
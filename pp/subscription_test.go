@@ -0,0 +1,53 @@
+// Copyright 2023 The Casdoor Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pp
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseWebhook(t *testing.T) {
+	body := []byte(`{"type":"customer.subscription.deleted","data":{"object":{"id":"sub_1"}}}`)
+	timestamp := fmt.Sprintf("%d", time.Now().Unix())
+	mac := hmac.New(sha256.New, []byte("whsec_1"))
+	mac.Write([]byte(timestamp + "."))
+	mac.Write(body)
+
+	request := &http.Request{Header: http.Header{}}
+	request.Header.Set("Stripe-Signature", fmt.Sprintf("t=%s,v1=%s", timestamp, hex.EncodeToString(mac.Sum(nil))))
+	_, err := NewStripeSubscriptionProvider("sk_1", "whsec_1").ParseWebhook(request, body)
+	assert.Nil(t, err)
+	_, err = NewStripeSubscriptionProvider("sk_1", "whsec_2").ParseWebhook(request, body)
+	assert.NotNil(t, err, "wrong secret")
+
+	// an empty secret would make the signature of anyone valid
+	mac = hmac.New(sha256.New, []byte(""))
+	mac.Write([]byte(timestamp + "."))
+	mac.Write(body)
+	request.Header.Set("Stripe-Signature", fmt.Sprintf("t=%s,v1=%s", timestamp, hex.EncodeToString(mac.Sum(nil))))
+	_, err = NewStripeSubscriptionProvider("sk_1", "").ParseWebhook(request, body)
+	assert.NotNil(t, err, "empty Stripe secret")
+
+	_, err = NewPaypalSubscriptionProvider("client", "secret", "", "").ParseWebhook(&http.Request{Header: http.Header{}}, body)
+	assert.NotNil(t, err, "empty PayPal webhook ID")
+}
@@ -22,12 +22,15 @@ import (
 
 	"github.com/beego/beego/context"
 	"github.com/casdoor/casdoor/authz"
+	"github.com/casdoor/casdoor/object"
 	"github.com/casdoor/casdoor/util"
 )
 
 type Object struct {
 	Owner string `json:"owner"`
 	Name  string `json:"name"`
+
+	ParentOrganization string `json:"parentOrganization"`
 }
 
 func getUsername(ctx *context.Context) (username string) {
@@ -87,6 +90,11 @@ func getObject(ctx *context.Context) (string, string) {
 			return "", ""
 		}
 
+		// a sub-organization is added by the admins of its parent
+		if path == "/api/add-organization" && obj.ParentOrganization != "" {
+			return obj.Owner, obj.ParentOrganization
+		}
+
 		if path == "/api/delete-resource" {
 			tokens := strings.Split(obj.Name, "/")
 			if len(tokens) >= 5 {
@@ -130,6 +138,23 @@ func AuthzFilter(ctx *context.Context) {
 	if strings.HasPrefix(urlPath, "/api/notify-payment") {
 		urlPath = "/api/notify-payment"
 	}
+	if strings.HasPrefix(urlPath, "/api/subscription-webhook") {
+		urlPath = "/api/subscription-webhook"
+	}
+	if strings.HasPrefix(urlPath, "/api/identity-verification-webhook") {
+		urlPath = "/api/identity-verification-webhook"
+	}
+	if strings.HasPrefix(urlPath, "/api/email-webhook") {
+		urlPath = "/api/email-webhook"
+	}
+
+	if apiKeyId := getSessionApiKey(ctx); apiKeyId != "" {
+		msg := object.CheckApiKeyRequest(apiKeyId, method, urlPath, util.GetIPFromRequest(ctx.Request), getAcceptLanguage(ctx))
+		if msg != "" {
+			responseError(ctx, msg)
+			return
+		}
+	}
 
 	isAllowed := authz.IsAllowed(subOwner, subName, method, urlPath, objOwner, objName)
 
@@ -16,6 +16,7 @@ package routers
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/beego/beego/context"
 	"github.com/casdoor/casdoor/object"
@@ -27,6 +28,25 @@ func AutoSigninFilter(ctx *context.Context) {
 	//	return
 	//}
 
+	// the forward-auth endpoints check the tokens of the proxied requests
+	// themselves and must never answer them with a 200 error
+	if strings.HasPrefix(ctx.Request.URL.Path, "/api/forward-auth") {
+		return
+	}
+
+	// HTTP header like "X-Api-Key: cak_123" or "Authorization: Bearer cak_123"
+	if key := getApiKey(ctx); key != "" {
+		apiKey := object.GetApiKeyByKey(key)
+		if apiKey == nil {
+			responseError(ctx, T(ctx, "auth:The API key is invalid"))
+			return
+		}
+
+		setSessionUser(ctx, apiKey.GetSubject())
+		setSessionApiKey(ctx, apiKey.GetId())
+		return
+	}
+
 	// GET parameter like "/page?access_token=123" or
 	// HTTP Bearer token like "Authorization: Bearer 123"
 	accessToken := util.GetMaxLenStr(ctx.Input.Query("accessToken"), ctx.Input.Query("access_token"), parseBearerToken(ctx))
@@ -43,10 +63,20 @@ func AutoSigninFilter(ctx *context.Context) {
 			return
 		}
 
+		// a device token has no user to sign in as
+		if token.DeviceId != "" {
+			return
+		}
+
 		userId := fmt.Sprintf("%s/%s", token.Organization, token.User)
 		application, _ := object.GetApplicationByUserId(fmt.Sprintf("app/%s", token.Application))
 		setSessionUser(ctx, userId)
 		setSessionOidc(ctx, token.Scope, application.ClientId)
+
+		if ctx.Request.URL.Path == "/api/userinfo" || ctx.Request.URL.Path == "/api/user" {
+			clientIp := util.GetIPFromRequest(ctx.Request)
+			util.SafeGoroutine(func() { object.AddTokenUserinfo(token, clientIp) })
+		}
 		return
 	}
 
@@ -62,6 +62,13 @@ func denyRequest(ctx *context.Context) {
 }
 
 func getUsernameByClientIdSecret(ctx *context.Context) string {
+	// the application secrets give full access, scoped API keys can be
+	// required instead
+	disabled, _ := conf.GetConfigBool("disableClientSecretAccess")
+	if disabled {
+		return ""
+	}
+
 	clientId, clientSecret, ok := ctx.Request.BasicAuth()
 	if !ok {
 		clientId = ctx.Input.Query("clientId")
@@ -94,11 +101,47 @@ func setSessionUser(ctx *context.Context, user string) {
 	if err != nil {
 		panic(err)
 	}
+	err = ctx.Input.CruSession.Delete("apiKey")
+	if err != nil {
+		panic(err)
+	}
 
 	// https://github.com/beego/beego/issues/3445#issuecomment-455411915
 	ctx.Input.CruSession.SessionRelease(ctx.ResponseWriter)
 }
 
+// getApiKey returns the key of "X-Api-Key: cak_123" or of a bearer token
+// like "Authorization: Bearer cak_123".
+func getApiKey(ctx *context.Context) string {
+	apiKey := ctx.Request.Header.Get("X-Api-Key")
+	if apiKey == "" && object.IsApiKey(parseBearerToken(ctx)) {
+		apiKey = parseBearerToken(ctx)
+	}
+	return apiKey
+}
+
+// setSessionApiKey marks the session as made with the API key, so that the
+// scopes of the key are checked for every request of the session.
+func setSessionApiKey(ctx *context.Context, id string) {
+	err := ctx.Input.CruSession.Set("apiKey", id)
+	if err != nil {
+		panic(err)
+	}
+	ctx.Input.CruSession.SessionRelease(ctx.ResponseWriter)
+}
+
+func getSessionApiKey(ctx *context.Context) string {
+	if ctx.Input.CruSession == nil {
+		return ""
+	}
+
+	id := ctx.Input.CruSession.Get("apiKey")
+	if id == nil {
+		return ""
+	}
+	return id.(string)
+}
+
 func setSessionExpire(ctx *context.Context, ExpireTime int64) {
 	SessionData := struct{ ExpireTime int64 }{ExpireTime: ExpireTime}
 	err := ctx.Input.CruSession.Set("SessionData", util.StructToJson(SessionData))
@@ -0,0 +1,87 @@
+// Copyright 2023 The Casdoor Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package routers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/beego/beego/context"
+	"github.com/casdoor/casdoor/i18n"
+	"github.com/casdoor/casdoor/object"
+)
+
+// maintenanceLoginPaths start new sessions, they are refused in maintenance
+// mode while the existing sessions and tokens keep working.
+var maintenanceLoginPaths = map[string]bool{
+	"/api/login":                  true,
+	"/api/signup":                 true,
+	"/api/certificate-login":      true,
+	"/api/webauthn/signin/begin":  true,
+	"/api/webauthn/signin/finish": true,
+}
+
+// maintenanceGrantTypes of the token endpoint sign in users or create new
+// guest users.
+var maintenanceGrantTypes = map[string]bool{
+	"password":            true,
+	object.GuestGrantType: true,
+}
+
+func isMaintenanceLoginRequest(ctx *context.Context) bool {
+	path := ctx.Request.URL.Path
+	if path == "/api/login/oauth/access_token" {
+		return maintenanceGrantTypes[getTokenRequestGrantType(ctx)]
+	}
+	return maintenanceLoginPaths[path]
+}
+
+// getTokenRequestGrantType returns the grant type of a token request from
+// the form or, like GetOAuthToken, from the JSON body.
+func getTokenRequestGrantType(ctx *context.Context) string {
+	if grantType := ctx.Input.Query("grant_type"); grantType != "" {
+		return grantType
+	}
+
+	var tokenRequest struct {
+		GrantType string `json:"grant_type"`
+	}
+	_ = json.Unmarshal(ctx.Input.RequestBody, &tokenRequest)
+	return tokenRequest.GrantType
+}
+
+func MaintenanceFilter(ctx *context.Context) {
+	if !object.IsMaintenanceMode() || !isMaintenanceLoginRequest(ctx) {
+		return
+	}
+
+	notice := object.GetMaintenanceNotice()
+	if notice == "" {
+		notice = i18n.Translate(getAcceptLanguage(ctx), "general:The service is under maintenance, please try again later")
+	}
+	ctx.Output.SetStatus(http.StatusServiceUnavailable)
+	responseError(ctx, notice, "Maintenance")
+}
+
+// DrainMiddleware counts the requests in flight, so that a draining node
+// knows when it can be shut down.
+func DrainMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		object.StartRequest()
+		defer object.FinishRequest()
+
+		next.ServeHTTP(w, r)
+	})
+}
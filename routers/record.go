@@ -54,7 +54,9 @@ func getUserByClientIdSecret(ctx *context.Context) string {
 }
 
 func RecordMessage(ctx *context.Context) {
-	if ctx.Request.URL.Path == "/api/login" || ctx.Request.URL.Path == "/api/signup" {
+	if ctx.Request.URL.Path == "/api/login" || ctx.Request.URL.Path == "/api/certificate-login" || ctx.Request.URL.Path == "/api/signup" || ctx.Request.URL.Path == "/api/verify-account-recovery" || ctx.Request.URL.Path == "/api/reset-password-by-recovery" ||
+		ctx.Request.URL.Path == "/api/confirm-contact-change" || ctx.Request.URL.Path == "/api/undo-contact-change" ||
+		ctx.Request.URL.Path == "/api/forward-auth" {
 		return
 	}
 
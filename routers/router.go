@@ -46,9 +46,15 @@ func initAPI() {
 
 	beego.Router("/api/signup", &controllers.ApiController{}, "POST:Signup")
 	beego.Router("/api/login", &controllers.ApiController{}, "POST:Login")
+	beego.Router("/api/certificate-login", &controllers.ApiController{}, "POST:CertificateLogin")
 	beego.Router("/api/get-app-login", &controllers.ApiController{}, "GET:GetApplicationLogin")
+	beego.Router("/api/get-error-codes", &controllers.ApiController{}, "GET:GetErrorCodes")
 	beego.Router("/api/logout", &controllers.ApiController{}, "GET,POST:Logout")
 	beego.Router("/api/get-account", &controllers.ApiController{}, "GET:GetAccount")
+	beego.Router("/api/get-security-activity", &controllers.ApiController{}, "GET:GetSecurityActivity")
+	beego.Router("/api/report-security-event", &controllers.ApiController{}, "POST:ReportSecurityEvent")
+	beego.Router("/api/get-login-alerts", &controllers.ApiController{}, "GET:GetLoginAlerts")
+	beego.Router("/api/confirm-login-alert", &controllers.ApiController{}, "POST:ConfirmLoginAlert")
 	beego.Router("/api/userinfo", &controllers.ApiController{}, "GET:GetUserinfo")
 	beego.Router("/api/user", &controllers.ApiController{}, "GET:GetUserinfo2")
 	beego.Router("/api/unlink", &controllers.ApiController{}, "POST:Unlink")
@@ -61,25 +67,40 @@ func initAPI() {
 	beego.Router("/api/get-organizations", &controllers.ApiController{}, "GET:GetOrganizations")
 	beego.Router("/api/get-organization", &controllers.ApiController{}, "GET:GetOrganization")
 	beego.Router("/api/update-organization", &controllers.ApiController{}, "POST:UpdateOrganization")
+	beego.Router("/api/upsert-organization", &controllers.ApiController{}, "POST:UpsertOrganization")
 	beego.Router("/api/add-organization", &controllers.ApiController{}, "POST:AddOrganization")
 	beego.Router("/api/delete-organization", &controllers.ApiController{}, "POST:DeleteOrganization")
+	beego.Router("/api/verify-organization-domain", &controllers.ApiController{}, "POST:VerifyOrganizationDomain")
+	beego.Router("/api/verify-email-domain", &controllers.ApiController{}, "POST:VerifyEmailDomain")
+	beego.Router("/api/get-email-domain-application", &controllers.ApiController{}, "GET:GetEmailDomainApplication")
 	beego.Router("/api/get-default-application", &controllers.ApiController{}, "GET:GetDefaultApplication")
+	beego.Router("/api/get-branding", &controllers.ApiController{}, "GET:GetBranding")
 
 	beego.Router("/api/get-global-users", &controllers.ApiController{}, "GET:GetGlobalUsers")
 	beego.Router("/api/get-users", &controllers.ApiController{}, "GET:GetUsers")
 	beego.Router("/api/get-sorted-users", &controllers.ApiController{}, "GET:GetSortedUsers")
 	beego.Router("/api/get-user-count", &controllers.ApiController{}, "GET:GetUserCount")
+	beego.Router("/api/export-users", &controllers.ApiController{}, "GET:ExportUsers")
 	beego.Router("/api/get-user", &controllers.ApiController{}, "GET:GetUser")
+	beego.Router("/api/get-user-avatar", &controllers.ApiController{}, "GET:GetUserAvatar")
+	beego.Router("/api/refresh-user-avatar", &controllers.ApiController{}, "POST:RefreshUserAvatar")
 	beego.Router("/api/update-user", &controllers.ApiController{}, "POST:UpdateUser")
 	beego.Router("/api/add-user", &controllers.ApiController{}, "POST:AddUser")
 	beego.Router("/api/delete-user", &controllers.ApiController{}, "POST:DeleteUser")
 	beego.Router("/api/upload-users", &controllers.ApiController{}, "POST:UploadUsers")
+	beego.Router("/api/batch-add-users", &controllers.ApiController{}, "POST:BatchAddUsers")
+	beego.Router("/api/batch-update-users", &controllers.ApiController{}, "POST:BatchUpdateUsers")
+	beego.Router("/api/batch-delete-users", &controllers.ApiController{}, "POST:BatchDeleteUsers")
 
 	beego.Router("/api/get-roles", &controllers.ApiController{}, "GET:GetRoles")
 	beego.Router("/api/get-role", &controllers.ApiController{}, "GET:GetRole")
 	beego.Router("/api/update-role", &controllers.ApiController{}, "POST:UpdateRole")
 	beego.Router("/api/add-role", &controllers.ApiController{}, "POST:AddRole")
 	beego.Router("/api/delete-role", &controllers.ApiController{}, "POST:DeleteRole")
+	beego.Router("/api/batch-add-roles", &controllers.ApiController{}, "POST:BatchAddRoles")
+	beego.Router("/api/batch-update-roles", &controllers.ApiController{}, "POST:BatchUpdateRoles")
+	beego.Router("/api/batch-delete-roles", &controllers.ApiController{}, "POST:BatchDeleteRoles")
+	beego.Router("/api/get-capabilities", &controllers.ApiController{}, "GET:GetCapabilities")
 
 	beego.Router("/api/get-permissions", &controllers.ApiController{}, "GET:GetPermissions")
 	beego.Router("/api/get-permissions-by-submitter", &controllers.ApiController{}, "GET:GetPermissionsBySubmitter")
@@ -88,9 +109,67 @@ func initAPI() {
 	beego.Router("/api/update-permission", &controllers.ApiController{}, "POST:UpdatePermission")
 	beego.Router("/api/add-permission", &controllers.ApiController{}, "POST:AddPermission")
 	beego.Router("/api/delete-permission", &controllers.ApiController{}, "POST:DeletePermission")
+	beego.Router("/api/batch-add-permissions", &controllers.ApiController{}, "POST:BatchAddPermissions")
+	beego.Router("/api/batch-update-permissions", &controllers.ApiController{}, "POST:BatchUpdatePermissions")
+	beego.Router("/api/batch-delete-permissions", &controllers.ApiController{}, "POST:BatchDeletePermissions")
 
 	beego.Router("/api/enforce", &controllers.ApiController{}, "POST:Enforce")
 	beego.Router("/api/batch-enforce", &controllers.ApiController{}, "POST:BatchEnforce")
+	beego.Router("/api/get-enforcer-metrics", &controllers.ApiController{}, "GET:GetEnforcerMetrics")
+	beego.Router("/api/get-object-cache-metrics", &controllers.ApiController{}, "GET:GetObjectCacheMetrics")
+	beego.Router("/api/flush-object-cache", &controllers.ApiController{}, "POST:FlushObjectCache")
+
+	beego.Router("/api/get-relation-tuples", &controllers.ApiController{}, "GET:GetRelationTuples")
+	beego.Router("/api/add-relation-tuple", &controllers.ApiController{}, "POST:AddRelationTuple")
+	beego.Router("/api/delete-relation-tuple", &controllers.ApiController{}, "POST:DeleteRelationTuple")
+	beego.Router("/api/check-relation", &controllers.ApiController{}, "POST:CheckRelation")
+
+	beego.Router("/api/get-access-requests", &controllers.ApiController{}, "GET:GetAccessRequests")
+	beego.Router("/api/get-access-request", &controllers.ApiController{}, "GET:GetAccessRequest")
+	beego.Router("/api/get-user-access-requests", &controllers.ApiController{}, "GET:GetUserAccessRequests")
+	beego.Router("/api/add-access-request", &controllers.ApiController{}, "POST:AddAccessRequest")
+	beego.Router("/api/approve-access-request", &controllers.ApiController{}, "POST:ApproveAccessRequest")
+	beego.Router("/api/deny-access-request", &controllers.ApiController{}, "POST:DenyAccessRequest")
+
+	beego.Router("/api/get-access-reviews", &controllers.ApiController{}, "GET:GetAccessReviews")
+	beego.Router("/api/get-access-review", &controllers.ApiController{}, "GET:GetAccessReview")
+	beego.Router("/api/update-access-review", &controllers.ApiController{}, "POST:UpdateAccessReview")
+	beego.Router("/api/add-access-review", &controllers.ApiController{}, "POST:AddAccessReview")
+	beego.Router("/api/delete-access-review", &controllers.ApiController{}, "POST:DeleteAccessReview")
+	beego.Router("/api/complete-access-review", &controllers.ApiController{}, "POST:CompleteAccessReview")
+	beego.Router("/api/get-access-review-items", &controllers.ApiController{}, "GET:GetAccessReviewItems")
+	beego.Router("/api/get-user-access-review-items", &controllers.ApiController{}, "GET:GetUserAccessReviewItems")
+	beego.Router("/api/decide-access-review-item", &controllers.ApiController{}, "POST:DecideAccessReviewItem")
+	beego.Router("/api/export-access-review", &controllers.ApiController{}, "GET:ExportAccessReview")
+
+	beego.Router("/api/get-api-keys", &controllers.ApiController{}, "GET:GetApiKeys")
+	beego.Router("/api/get-api-key", &controllers.ApiController{}, "GET:GetApiKey")
+	beego.Router("/api/get-user-api-keys", &controllers.ApiController{}, "GET:GetUserApiKeys")
+	beego.Router("/api/add-api-key", &controllers.ApiController{}, "POST:AddApiKey")
+	beego.Router("/api/revoke-api-key", &controllers.ApiController{}, "POST:RevokeApiKey")
+	beego.Router("/api/delete-api-key", &controllers.ApiController{}, "POST:DeleteApiKey")
+
+	beego.Router("/api/get-memberships", &controllers.ApiController{}, "GET:GetMemberships")
+	beego.Router("/api/get-membership", &controllers.ApiController{}, "GET:GetMembership")
+	beego.Router("/api/update-membership", &controllers.ApiController{}, "POST:UpdateMembership")
+	beego.Router("/api/add-membership", &controllers.ApiController{}, "POST:AddMembership")
+	beego.Router("/api/delete-membership", &controllers.ApiController{}, "POST:DeleteMembership")
+	beego.Router("/api/get-user-organizations", &controllers.ApiController{}, "GET:GetUserOrganizations")
+	beego.Router("/api/set-active-organization", &controllers.ApiController{}, "POST:SetActiveOrganization")
+
+	beego.Router("/api/graphql", &controllers.ApiController{}, "GET,POST:GraphQL")
+	beego.Router("/api/export-config", &controllers.ApiController{}, "GET:ExportConfig")
+	beego.Router("/api/import-config", &controllers.ApiController{}, "POST:ImportConfig")
+	beego.Router("/api/create-backup", &controllers.ApiController{}, "GET:CreateBackup")
+	beego.Router("/api/restore-backup", &controllers.ApiController{}, "POST:RestoreBackup")
+	beego.Router("/api/get-shards", &controllers.ApiController{}, "GET:GetShards")
+	beego.Router("/api/get-maintenance-status", &controllers.ApiController{}, "GET:GetMaintenanceStatus")
+	beego.Router("/api/get-maintenance-notice", &controllers.ApiController{}, "GET:GetMaintenanceNotice")
+	beego.Router("/api/set-maintenance-mode", &controllers.ApiController{}, "POST:SetMaintenanceMode")
+	beego.Router("/api/drain-instance", &controllers.ApiController{}, "POST:DrainInstance")
+	beego.Router("/api/move-organization-shard", &controllers.ApiController{}, "POST:MoveOrganizationShard")
+	beego.Router("/api/import-from-idp", &controllers.ApiController{}, "POST:ImportFromIdp")
+
 	beego.Router("/api/get-all-objects", &controllers.ApiController{}, "GET:GetAllObjects")
 	beego.Router("/api/get-all-actions", &controllers.ApiController{}, "GET:GetAllActions")
 	beego.Router("/api/get-all-roles", &controllers.ApiController{}, "GET:GetAllRoles")
@@ -100,6 +179,8 @@ func initAPI() {
 	beego.Router("/api/update-model", &controllers.ApiController{}, "POST:UpdateModel")
 	beego.Router("/api/add-model", &controllers.ApiController{}, "POST:AddModel")
 	beego.Router("/api/delete-model", &controllers.ApiController{}, "POST:DeleteModel")
+	beego.Router("/api/validate-model", &controllers.ApiController{}, "POST:ValidateModel")
+	beego.Router("/api/get-model-templates", &controllers.ApiController{}, "GET:GetModelTemplates")
 
 	beego.Router("/api/get-adapters", &controllers.ApiController{}, "GET:GetCasbinAdapters")
 	beego.Router("/api/get-adapter", &controllers.ApiController{}, "GET:GetCasbinAdapter")
@@ -117,6 +198,14 @@ func initAPI() {
 	beego.Router("/api/send-verification-code", &controllers.ApiController{}, "POST:SendVerificationCode")
 	beego.Router("/api/verify-captcha", &controllers.ApiController{}, "POST:VerifyCaptcha")
 	beego.Router("/api/reset-email-or-phone", &controllers.ApiController{}, "POST:ResetEmailOrPhone")
+	beego.Router("/api/verify-account-recovery", &controllers.ApiController{}, "POST:VerifyAccountRecovery")
+	beego.Router("/api/reset-password-by-recovery", &controllers.ApiController{}, "POST:ResetPasswordByRecovery")
+	beego.Router("/api/set-security-answers", &controllers.ApiController{}, "POST:SetSecurityAnswers")
+	beego.Router("/api/create-account-recovery-link", &controllers.ApiController{}, "POST:CreateAccountRecoveryLink")
+	beego.Router("/api/get-account-recoveries", &controllers.ApiController{}, "GET:GetAccountRecoveries")
+	beego.Router("/api/confirm-contact-change", &controllers.ApiController{}, "POST:ConfirmContactChange")
+	beego.Router("/api/undo-contact-change", &controllers.ApiController{}, "POST:UndoContactChange")
+	beego.Router("/api/get-contact-changes", &controllers.ApiController{}, "GET:GetContactChanges")
 	beego.Router("/api/get-captcha", &controllers.ApiController{}, "GET:GetCaptcha")
 
 	beego.Router("/api/get-ldap-users", &controllers.ApiController{}, "GET:GetLdapUsers")
@@ -131,6 +220,7 @@ func initAPI() {
 	beego.Router("/api/get-provider", &controllers.ApiController{}, "GET:GetProvider")
 	beego.Router("/api/get-global-providers", &controllers.ApiController{}, "GET:GetGlobalProviders")
 	beego.Router("/api/update-provider", &controllers.ApiController{}, "POST:UpdateProvider")
+	beego.Router("/api/upsert-provider", &controllers.ApiController{}, "POST:UpsertProvider")
 	beego.Router("/api/add-provider", &controllers.ApiController{}, "POST:AddProvider")
 	beego.Router("/api/delete-provider", &controllers.ApiController{}, "POST:DeleteProvider")
 
@@ -139,8 +229,12 @@ func initAPI() {
 	beego.Router("/api/get-user-application", &controllers.ApiController{}, "GET:GetUserApplication")
 	beego.Router("/api/get-organization-applications", &controllers.ApiController{}, "GET:GetOrganizationApplications")
 	beego.Router("/api/update-application", &controllers.ApiController{}, "POST:UpdateApplication")
+	beego.Router("/api/upsert-application", &controllers.ApiController{}, "POST:UpsertApplication")
 	beego.Router("/api/add-application", &controllers.ApiController{}, "POST:AddApplication")
 	beego.Router("/api/delete-application", &controllers.ApiController{}, "POST:DeleteApplication")
+	beego.Router("/api/check-application-sso", &controllers.ApiController{}, "GET:CheckApplicationSso")
+	beego.Router("/api/forward-auth", &controllers.ApiController{}, "*:ForwardAuth")
+	beego.Router("/api/forward-auth/callback", &controllers.ApiController{}, "GET:ForwardAuthCallback")
 
 	beego.Router("/api/get-resources", &controllers.ApiController{}, "GET:GetResources")
 	beego.Router("/api/get-resource", &controllers.ApiController{}, "GET:GetResource")
@@ -158,9 +252,12 @@ func initAPI() {
 	beego.Router("/api/login/oauth/access_token", &controllers.ApiController{}, "POST:GetOAuthToken")
 	beego.Router("/api/login/oauth/refresh_token", &controllers.ApiController{}, "POST:RefreshToken")
 	beego.Router("/api/login/oauth/introspect", &controllers.ApiController{}, "POST:IntrospectToken")
+	beego.Router("/api/login/oauth/attestation-challenge", &controllers.ApiController{}, "GET:GetAttestationChallenge")
+	beego.Router("/api/login/oauth/register-attest-key", &controllers.ApiController{}, "POST:RegisterAppAttestKey")
 	beego.Router("/api/get-records", &controllers.ApiController{}, "GET:GetRecords")
 	beego.Router("/api/get-records-filter", &controllers.ApiController{}, "POST:GetRecordsByFilter")
 	beego.Router("/api/add-record", &controllers.ApiController{}, "POST:AddRecord")
+	beego.Router("/api/export-records", &controllers.ApiController{}, "GET:ExportRecords")
 
 	beego.Router("/api/get-sessions", &controllers.ApiController{}, "GET:GetSessions")
 	beego.Router("/api/get-session", &controllers.ApiController{}, "GET:GetSingleSession")
@@ -175,6 +272,12 @@ func initAPI() {
 	beego.Router("/api/add-webhook", &controllers.ApiController{}, "POST:AddWebhook")
 	beego.Router("/api/delete-webhook", &controllers.ApiController{}, "POST:DeleteWebhook")
 
+	beego.Router("/api/get-login-hooks", &controllers.ApiController{}, "GET:GetLoginHooks")
+	beego.Router("/api/get-login-hook", &controllers.ApiController{}, "GET:GetLoginHook")
+	beego.Router("/api/update-login-hook", &controllers.ApiController{}, "POST:UpdateLoginHook")
+	beego.Router("/api/add-login-hook", &controllers.ApiController{}, "POST:AddLoginHook")
+	beego.Router("/api/delete-login-hook", &controllers.ApiController{}, "POST:DeleteLoginHook")
+
 	beego.Router("/api/get-syncers", &controllers.ApiController{}, "GET:GetSyncers")
 	beego.Router("/api/get-syncer", &controllers.ApiController{}, "GET:GetSyncer")
 	beego.Router("/api/update-syncer", &controllers.ApiController{}, "POST:UpdateSyncer")
@@ -182,11 +285,39 @@ func initAPI() {
 	beego.Router("/api/delete-syncer", &controllers.ApiController{}, "POST:DeleteSyncer")
 	beego.Router("/api/run-syncer", &controllers.ApiController{}, "GET:RunSyncer")
 
+	beego.Router("/api/get-jobs", &controllers.ApiController{}, "GET:GetJobs")
+	beego.Router("/api/get-job", &controllers.ApiController{}, "GET:GetJob")
+
+	beego.Router("/api/get-tasks", &controllers.ApiController{}, "GET:GetTasks")
+	beego.Router("/api/get-task", &controllers.ApiController{}, "GET:GetTask")
+	beego.Router("/api/retry-task", &controllers.ApiController{}, "POST:RetryTask")
+
+	beego.Router("/api/get-settings", &controllers.ApiController{}, "GET:GetSettings")
+	beego.Router("/api/update-settings", &controllers.ApiController{}, "POST:UpdateSettings")
+	beego.Router("/api/get-setting-changes", &controllers.ApiController{}, "GET:GetSettingChanges")
+	beego.Router("/api/get-retention-metrics", &controllers.ApiController{}, "GET:GetRetentionMetrics")
+
+	beego.Router("/api/health/live", &controllers.ApiController{}, "GET:GetLiveness")
+	beego.Router("/api/health/ready", &controllers.ApiController{}, "GET:GetReadiness")
+
 	beego.Router("/api/get-certs", &controllers.ApiController{}, "GET:GetCerts")
 	beego.Router("/api/get-cert", &controllers.ApiController{}, "GET:GetCert")
 	beego.Router("/api/update-cert", &controllers.ApiController{}, "POST:UpdateCert")
+	beego.Router("/api/upsert-cert", &controllers.ApiController{}, "POST:UpsertCert")
 	beego.Router("/api/add-cert", &controllers.ApiController{}, "POST:AddCert")
 	beego.Router("/api/delete-cert", &controllers.ApiController{}, "POST:DeleteCert")
+	beego.Router("/api/generate-cert-csr", &controllers.ApiController{}, "POST:GenerateCertCsr")
+	beego.Router("/api/upload-cert-certificate", &controllers.ApiController{}, "POST:UploadCertCertificate")
+	beego.Router("/api/issue-ssh-certificate", &controllers.ApiController{}, "POST:IssueSshCertificate")
+	beego.Router("/api/get-ssh-certificates", &controllers.ApiController{}, "GET:GetSshCertificates")
+
+	beego.Router("/api/get-devices", &controllers.ApiController{}, "GET:GetDevices")
+	beego.Router("/api/get-device", &controllers.ApiController{}, "GET:GetDevice")
+	beego.Router("/api/update-device", &controllers.ApiController{}, "POST:UpdateDevice")
+	beego.Router("/api/delete-device", &controllers.ApiController{}, "POST:DeleteDevice")
+	beego.Router("/api/register-devices", &controllers.ApiController{}, "POST:RegisterDevices")
+	beego.Router("/api/issue-device-credential", &controllers.ApiController{}, "POST:IssueDeviceCredential")
+	beego.Router("/api/revoke-device", &controllers.ApiController{}, "POST:RevokeDevice")
 
 	beego.Router("/api/get-products", &controllers.ApiController{}, "GET:GetProducts")
 	beego.Router("/api/get-product", &controllers.ApiController{}, "GET:GetProduct")
@@ -204,7 +335,42 @@ func initAPI() {
 	beego.Router("/api/notify-payment/?:owner/?:provider/?:product/?:payment", &controllers.ApiController{}, "POST:NotifyPayment")
 	beego.Router("/api/invoice-payment", &controllers.ApiController{}, "POST:InvoicePayment")
 
+	beego.Router("/api/get-plans", &controllers.ApiController{}, "GET:GetPlans")
+	beego.Router("/api/get-plan", &controllers.ApiController{}, "GET:GetPlan")
+	beego.Router("/api/update-plan", &controllers.ApiController{}, "POST:UpdatePlan")
+	beego.Router("/api/add-plan", &controllers.ApiController{}, "POST:AddPlan")
+	beego.Router("/api/delete-plan", &controllers.ApiController{}, "POST:DeletePlan")
+
+	beego.Router("/api/get-subscriptions", &controllers.ApiController{}, "GET:GetSubscriptions")
+	beego.Router("/api/get-user-subscriptions", &controllers.ApiController{}, "GET:GetUserSubscriptions")
+	beego.Router("/api/get-subscription", &controllers.ApiController{}, "GET:GetSubscription")
+	beego.Router("/api/update-subscription", &controllers.ApiController{}, "POST:UpdateSubscription")
+	beego.Router("/api/add-subscription", &controllers.ApiController{}, "POST:AddSubscription")
+	beego.Router("/api/delete-subscription", &controllers.ApiController{}, "POST:DeleteSubscription")
+	beego.Router("/api/subscribe", &controllers.ApiController{}, "POST:Subscribe")
+	beego.Router("/api/get-subscription-proration", &controllers.ApiController{}, "GET:GetSubscriptionProration")
+	beego.Router("/api/change-subscription-plan", &controllers.ApiController{}, "POST:ChangeSubscriptionPlan")
+	beego.Router("/api/cancel-subscription", &controllers.ApiController{}, "POST:CancelSubscription")
+	beego.Router("/api/get-organization-usage", &controllers.ApiController{}, "GET:GetOrganizationUsage")
+	beego.Router("/api/get-usages", &controllers.ApiController{}, "GET:GetUsages")
+	beego.Router("/api/get-login-stats", &controllers.ApiController{}, "GET:GetLoginStats")
+	beego.Router("/api/export-login-stats", &controllers.ApiController{}, "GET:ExportLoginStats")
+	beego.Router("/api/get-token-stats", &controllers.ApiController{}, "GET:GetTokenStats")
+	beego.Router("/api/get-token-anomalies", &controllers.ApiController{}, "GET:GetTokenAnomalies")
+	beego.Router("/api/review-token-anomaly", &controllers.ApiController{}, "POST:ReviewTokenAnomaly")
+	beego.Router("/api/subscription-webhook/?:owner/?:provider", &controllers.ApiController{}, "POST:SubscriptionWebhook")
+
+	beego.Router("/api/get-identity-verifications", &controllers.ApiController{}, "GET:GetIdentityVerifications")
+	beego.Router("/api/get-identity-verification", &controllers.ApiController{}, "GET:GetIdentityVerification")
+	beego.Router("/api/get-user-identity-verifications", &controllers.ApiController{}, "GET:GetUserIdentityVerifications")
+	beego.Router("/api/start-identity-verification", &controllers.ApiController{}, "POST:StartIdentityVerification")
+	beego.Router("/api/identity-verification-webhook/?:owner/?:provider", &controllers.ApiController{}, "POST:IdentityVerificationWebhook")
+
 	beego.Router("/api/send-email", &controllers.ApiController{}, "POST:SendEmail")
+	beego.Router("/api/get-email-suppressions", &controllers.ApiController{}, "GET:GetEmailSuppressions")
+	beego.Router("/api/add-email-suppression", &controllers.ApiController{}, "POST:AddEmailSuppression")
+	beego.Router("/api/delete-email-suppression", &controllers.ApiController{}, "POST:DeleteEmailSuppression")
+	beego.Router("/api/email-webhook/?:owner/?:provider", &controllers.ApiController{}, "POST:EmailWebhook")
 	beego.Router("/api/send-sms", &controllers.ApiController{}, "POST:SendSms")
 
 	beego.Router("/.well-known/openid-configuration", &controllers.RootController{}, "GET:GetOidcDiscovery")
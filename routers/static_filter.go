@@ -22,13 +22,11 @@ import (
 
 	"github.com/beego/beego/context"
 	"github.com/casdoor/casdoor/conf"
+	"github.com/casdoor/casdoor/object"
 	"github.com/casdoor/casdoor/util"
 )
 
-var (
-	oldStaticBaseUrl = "https://cdn.casbin.org"
-	newStaticBaseUrl = conf.GetConfigString("staticBaseUrl")
-)
+var oldStaticBaseUrl = "https://cdn.casbin.org"
 
 func StaticFilter(ctx *context.Context) {
 	urlPath := ctx.Request.URL.Path
@@ -37,9 +35,21 @@ func StaticFilter(ctx *context.Context) {
 		http.ServeContent(ctx.ResponseWriter, ctx.Request, "acme-challenge", time.Now(), strings.NewReader("content"))
 	}
 
+	if strings.HasPrefix(urlPath, "/.well-known/acme-challenge/") {
+		if manager := object.GetAcmeManager(); manager != nil {
+			manager.HTTPHandler(nil).ServeHTTP(ctx.ResponseWriter, ctx.Request)
+			return
+		}
+	}
+
 	if strings.HasPrefix(urlPath, "/api/") || strings.HasPrefix(urlPath, "/.well-known/") {
 		return
 	}
+
+	if redirectUrl := getDomainRedirectUrl(ctx.Request.Host, urlPath); redirectUrl != "" {
+		ctx.Redirect(http.StatusFound, redirectUrl)
+		return
+	}
 	if strings.HasPrefix(urlPath, "/cas") && (strings.HasSuffix(urlPath, "/serviceValidate") || strings.HasSuffix(urlPath, "/proxy") || strings.HasSuffix(urlPath, "/proxyValidate") || strings.HasSuffix(urlPath, "/validate") || strings.HasSuffix(urlPath, "/p3/serviceValidate") || strings.HasSuffix(urlPath, "/p3/proxyValidate") || strings.HasSuffix(urlPath, "/samlValidate")) {
 		return
 	}
@@ -55,6 +65,7 @@ func StaticFilter(ctx *context.Context) {
 		path = "web/build/index.html"
 	}
 
+	newStaticBaseUrl := conf.GetConfigString("staticBaseUrl")
 	if oldStaticBaseUrl == newStaticBaseUrl {
 		http.ServeFile(ctx.ResponseWriter, ctx.Request, path)
 	} else {
@@ -62,6 +73,28 @@ func StaticFilter(ctx *context.Context) {
 	}
 }
 
+// getDomainRedirectUrl sends the entry pages on the custom domain of an
+// organization to the login and signup pages of the organization.
+func getDomainRedirectUrl(host string, urlPath string) string {
+	if urlPath != "/" && urlPath != "/login" && urlPath != "/signup" {
+		return ""
+	}
+
+	organization := object.GetOrganizationByDomain(host)
+	if organization == nil {
+		return ""
+	}
+
+	if urlPath == "/signup" {
+		application, err := object.GetDefaultApplication(util.GetId(organization.Owner, organization.Name))
+		if err != nil {
+			return ""
+		}
+		return "/signup/" + application.Name
+	}
+	return "/login/" + organization.Name
+}
+
 func serveFileWithReplace(w http.ResponseWriter, r *http.Request, name string, old string, new string) {
 	f, err := os.Open(name)
 	if err != nil {
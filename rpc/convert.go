@@ -0,0 +1,163 @@
+// Copyright 2023 The Casdoor Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rpc
+
+import (
+	"github.com/casdoor/casdoor/object"
+	pb "github.com/casdoor/casdoor/proto"
+	"github.com/casdoor/casdoor/util"
+)
+
+func toPbUser(user *object.User) *pb.User {
+	if user == nil {
+		return nil
+	}
+
+	res := &pb.User{
+		Owner:             user.Owner,
+		Name:              user.Name,
+		CreatedTime:       user.CreatedTime,
+		UpdatedTime:       user.UpdatedTime,
+		Id:                user.Id,
+		Type:              user.Type,
+		Password:          user.Password,
+		DisplayName:       user.DisplayName,
+		Avatar:            user.Avatar,
+		Email:             user.Email,
+		EmailVerified:     user.EmailVerified,
+		Phone:             user.Phone,
+		CountryCode:       user.CountryCode,
+		Region:            user.Region,
+		Affiliation:       user.Affiliation,
+		Tag:               user.Tag,
+		SignupApplication: user.SignupApplication,
+		IsAdmin:           user.IsAdmin,
+		IsGlobalAdmin:     user.IsGlobalAdmin,
+		IsForbidden:       user.IsForbidden,
+		IsDeleted:         user.IsDeleted,
+		Properties:        user.Properties,
+	}
+
+	for _, role := range user.Roles {
+		res.Roles = append(res.Roles, role.GetId())
+	}
+	for _, permission := range user.Permissions {
+		res.Permissions = append(res.Permissions, util.GetId(permission.Owner, permission.Name))
+	}
+
+	return res
+}
+
+func toPbUsers(users []*object.User) []*pb.User {
+	res := []*pb.User{}
+	for _, user := range users {
+		res = append(res, toPbUser(user))
+	}
+	return res
+}
+
+func fromPbUser(user *pb.User) *object.User {
+	if user == nil {
+		return &object.User{}
+	}
+
+	return &object.User{
+		Owner:             user.Owner,
+		Name:              user.Name,
+		CreatedTime:       user.CreatedTime,
+		UpdatedTime:       user.UpdatedTime,
+		Id:                user.Id,
+		Type:              user.Type,
+		Password:          user.Password,
+		DisplayName:       user.DisplayName,
+		Avatar:            user.Avatar,
+		Email:             user.Email,
+		EmailVerified:     user.EmailVerified,
+		Phone:             user.Phone,
+		CountryCode:       user.CountryCode,
+		Region:            user.Region,
+		Affiliation:       user.Affiliation,
+		Tag:               user.Tag,
+		SignupApplication: user.SignupApplication,
+		IsAdmin:           user.IsAdmin,
+		IsGlobalAdmin:     user.IsGlobalAdmin,
+		IsForbidden:       user.IsForbidden,
+		IsDeleted:         user.IsDeleted,
+		Properties:        user.Properties,
+	}
+}
+
+func toPbApplication(application *object.Application) *pb.Application {
+	if application == nil {
+		return nil
+	}
+
+	return &pb.Application{
+		Owner:                application.Owner,
+		Name:                 application.Name,
+		CreatedTime:          application.CreatedTime,
+		DisplayName:          application.DisplayName,
+		Logo:                 application.Logo,
+		HomepageUrl:          application.HomepageUrl,
+		Description:          application.Description,
+		Organization:         application.Organization,
+		Cert:                 application.Cert,
+		EnablePassword:       application.EnablePassword,
+		EnableSignUp:         application.EnableSignUp,
+		ClientId:             application.ClientId,
+		RedirectUris:         application.RedirectUris,
+		TokenFormat:          application.TokenFormat,
+		ExpireInHours:        int32(application.ExpireInHours),
+		RefreshExpireInHours: int32(application.RefreshExpireInHours),
+	}
+}
+
+func toPbTokenResponse(res interface{}) *pb.TokenResponse {
+	switch token := res.(type) {
+	case *object.TokenWrapper:
+		return &pb.TokenResponse{
+			AccessToken:  token.AccessToken,
+			IdToken:      token.IdToken,
+			RefreshToken: token.RefreshToken,
+			TokenType:    token.TokenType,
+			ExpiresIn:    int32(token.ExpiresIn),
+			Scope:        token.Scope,
+		}
+	case *object.TokenError:
+		return &pb.TokenResponse{
+			Error:            token.Error,
+			ErrorDescription: token.ErrorDescription,
+		}
+	default:
+		return &pb.TokenResponse{Error: object.InvalidRequest}
+	}
+}
+
+func fromPbPermissionRule(rule *pb.PermissionRule) object.PermissionRule {
+	if rule == nil {
+		return object.PermissionRule{}
+	}
+
+	return object.PermissionRule{
+		Ptype: rule.Ptype,
+		V0:    rule.V0,
+		V1:    rule.V1,
+		V2:    rule.V2,
+		V3:    rule.V3,
+		V4:    rule.V4,
+		V5:    rule.V5,
+		Id:    rule.Id,
+	}
+}
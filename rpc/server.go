@@ -0,0 +1,98 @@
+// Copyright 2023 The Casdoor Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rpc
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net"
+	"os"
+
+	"github.com/casdoor/casdoor/conf"
+	pb "github.com/casdoor/casdoor/proto"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+)
+
+// StartGrpcServer serves the gRPC API on "grpcPort". It does nothing when the
+// port is not configured. When "grpcTlsCert" and "grpcTlsKey" are set the
+// server uses TLS, and when "grpcClientCa" is also set every client must
+// present a certificate signed by that CA (mTLS).
+func StartGrpcServer() {
+	port := conf.GetConfigString("grpcPort")
+	if port == "" {
+		return
+	}
+
+	opts, err := getServerOptions()
+	if err != nil {
+		panic(err)
+	}
+
+	listener, err := net.Listen("tcp", "0.0.0.0:"+port)
+	if err != nil {
+		panic(err)
+	}
+
+	server := grpc.NewServer(opts...)
+	pb.RegisterCasdoorServiceServer(server, &Server{})
+
+	fmt.Printf("gRPC server is listening on port: %s\n", port)
+	err = server.Serve(listener)
+	if err != nil {
+		panic(err)
+	}
+}
+
+func getServerOptions() ([]grpc.ServerOption, error) {
+	opts := []grpc.ServerOption{
+		grpc.ChainUnaryInterceptor(recoverInterceptor, authInterceptor),
+	}
+
+	certFile := conf.GetConfigString("grpcTlsCert")
+	keyFile := conf.GetConfigString("grpcTlsKey")
+	if certFile == "" || keyFile == "" {
+		return opts, nil
+	}
+
+	cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+	if err != nil {
+		return nil, err
+	}
+
+	tlsConfig := &tls.Config{
+		Certificates: []tls.Certificate{cert},
+		MinVersion:   tls.VersionTLS12,
+	}
+
+	caFile := conf.GetConfigString("grpcClientCa")
+	if caFile != "" {
+		caPem, err := os.ReadFile(caFile)
+		if err != nil {
+			return nil, err
+		}
+
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(caPem) {
+			return nil, fmt.Errorf("failed to parse gRPC client CA: %s", caFile)
+		}
+
+		tlsConfig.ClientCAs = pool
+		tlsConfig.ClientAuth = tls.RequireAndVerifyClientCert
+	}
+
+	return append(opts, grpc.Creds(credentials.NewTLS(tlsConfig))), nil
+}
@@ -0,0 +1,268 @@
+// Copyright 2023 The Casdoor Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rpc
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/casdoor/casdoor/object"
+	pb "github.com/casdoor/casdoor/proto"
+	"github.com/casdoor/casdoor/util"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Server implements pb.CasdoorServiceServer on top of the object layer.
+type Server struct {
+	pb.UnimplementedCasdoorServiceServer
+}
+
+// getQueryObject mirrors how the authz filter picks the object of a GET
+// request: the "id" parameter first, then the "owner" parameter.
+func getQueryObject(id string, owner string) (string, string, error) {
+	if id != "" {
+		if strings.Count(id, "/") != 1 {
+			return "", "", status.Errorf(codes.InvalidArgument, "Invalid id: %s", id)
+		}
+
+		objOwner, objName := util.GetOwnerAndNameFromId(id)
+		return objOwner, objName, nil
+	}
+
+	return owner, "", nil
+}
+
+func (s *Server) GetUser(ctx context.Context, req *pb.GetUserRequest) (*pb.User, error) {
+	objOwner, objName, err := getQueryObject(req.Id, req.Owner)
+	if err != nil {
+		return nil, err
+	}
+	if err = authorize(ctx, http.MethodGet, "/api/get-user", objOwner, objName); err != nil {
+		return nil, err
+	}
+
+	owner := req.Owner
+	if owner == "" {
+		owner = objOwner
+	}
+
+	organization := object.GetOrganization(fmt.Sprintf("%s/%s", "admin", owner))
+	if organization == nil || !organization.IsProfilePublic {
+		hasPermission, err := object.CheckUserPermission(getSessionUsername(ctx), req.Id, owner, false, "en")
+		if !hasPermission {
+			return nil, status.Error(codes.PermissionDenied, err.Error())
+		}
+	}
+
+	var user *object.User
+	switch {
+	case req.Email != "":
+		user = object.GetUserByEmail(owner, req.Email)
+	case req.Phone != "":
+		user = object.GetUserByPhone(owner, req.Phone)
+	case req.UserId != "":
+		user = object.GetUserByUserId(owner, req.UserId)
+	default:
+		user = object.GetUser(req.Id)
+	}
+	if user == nil {
+		return nil, status.Error(codes.NotFound, "The user doesn't exist")
+	}
+
+	object.ExtendUserWithRolesAndPermissions(user)
+	return toPbUser(object.GetMaskedUser(user)), nil
+}
+
+func (s *Server) GetUsers(ctx context.Context, req *pb.GetUsersRequest) (*pb.GetUsersResponse, error) {
+	if err := authorize(ctx, http.MethodGet, "/api/get-users", req.Owner, ""); err != nil {
+		return nil, err
+	}
+
+	if req.PageSize <= 0 || req.Page <= 0 {
+		users := object.GetMaskedUsers(object.GetUsers(req.Owner))
+		return &pb.GetUsersResponse{Users: toPbUsers(users), Total: int64(len(users))}, nil
+	}
+
+	limit := int(req.PageSize)
+	offset := (int(req.Page) - 1) * limit
+	total := object.GetUserCount(req.Owner, req.Field, req.Value)
+	users := object.GetMaskedUsers(object.GetPaginationUsers(req.Owner, offset, limit, req.Field, req.Value, req.SortField, req.SortOrder))
+	return &pb.GetUsersResponse{Users: toPbUsers(users), Total: int64(total)}, nil
+}
+
+func (s *Server) AddUser(ctx context.Context, req *pb.User) (*pb.AffectedResponse, error) {
+	if err := authorize(ctx, http.MethodPost, "/api/add-user", req.Owner, req.Name); err != nil {
+		return nil, err
+	}
+
+	user := fromPbUser(req)
+	msg := object.CheckUsername(user.Name, "en")
+	if msg == "" {
+		msg = object.CheckAdminEmailDomain(user, "en")
+	}
+	if msg != "" {
+		return nil, status.Error(codes.InvalidArgument, msg)
+	}
+
+	err := object.CheckSeatLimit(user.Owner, object.SeatResourceUser, 1, "en")
+	if err != nil {
+		return nil, status.Error(codes.ResourceExhausted, err.Error())
+	}
+
+	return &pb.AffectedResponse{Affected: object.AddUser(user)}, nil
+}
+
+func (s *Server) UpdateUser(ctx context.Context, req *pb.UpdateUserRequest) (*pb.AffectedResponse, error) {
+	user := fromPbUser(req.User)
+	if err := authorize(ctx, http.MethodPost, "/api/update-user", user.Owner, user.Name); err != nil {
+		return nil, err
+	}
+
+	oldUser := object.GetUser(req.Id)
+	if oldUser == nil {
+		return nil, status.Errorf(codes.NotFound, "The user: %s doesn't exist", req.Id)
+	}
+
+	if msg := object.CheckUpdateUser(oldUser, user, "en"); msg != "" {
+		return nil, status.Error(codes.InvalidArgument, msg)
+	}
+	if column := object.GetAdminUserColumn(req.Columns); column != "" && !isAdminOf(ctx, oldUser.Owner) {
+		return nil, status.Errorf(codes.PermissionDenied, "The column: %s can only be updated by admins", column)
+	}
+
+	affected := object.UpdateUser(req.Id, user, req.Columns, isGlobalAdmin(ctx))
+	if affected {
+		object.UpdateUserToOriginalDatabase(user)
+	}
+
+	return &pb.AffectedResponse{Affected: affected}, nil
+}
+
+func (s *Server) DeleteUser(ctx context.Context, req *pb.User) (*pb.AffectedResponse, error) {
+	if err := authorize(ctx, http.MethodPost, "/api/delete-user", req.Owner, req.Name); err != nil {
+		return nil, err
+	}
+
+	return &pb.AffectedResponse{Affected: object.DeleteUser(fromPbUser(req))}, nil
+}
+
+func (s *Server) GetApplication(ctx context.Context, req *pb.GetApplicationRequest) (*pb.Application, error) {
+	objOwner, objName, err := getQueryObject(req.Id, "")
+	if err != nil {
+		return nil, err
+	}
+	if err = authorize(ctx, http.MethodGet, "/api/get-application", objOwner, objName); err != nil {
+		return nil, err
+	}
+
+	application := object.GetMaskedApplication(object.GetApplication(req.Id), getSessionUsername(ctx))
+	if application == nil {
+		return nil, status.Error(codes.NotFound, "The application doesn't exist")
+	}
+
+	return toPbApplication(application), nil
+}
+
+func (s *Server) GetApplications(ctx context.Context, req *pb.GetApplicationsRequest) (*pb.GetApplicationsResponse, error) {
+	if err := authorize(ctx, http.MethodGet, "/api/get-applications", req.Owner, ""); err != nil {
+		return nil, err
+	}
+
+	res := &pb.GetApplicationsResponse{Applications: []*pb.Application{}}
+	for _, application := range object.GetMaskedApplications(object.GetApplications(req.Owner), getSessionUsername(ctx)) {
+		res.Applications = append(res.Applications, toPbApplication(application))
+	}
+	return res, nil
+}
+
+func (s *Server) GetOAuthToken(ctx context.Context, req *pb.GetOAuthTokenRequest) (*pb.TokenResponse, error) {
+	// the password and guest grants sign in, they are refused in maintenance
+	// mode like on the REST token endpoint
+	if (req.GrantType == "password" || req.GrantType == object.GuestGrantType) && object.IsMaintenanceMode() {
+		notice := object.GetMaintenanceNotice()
+		if notice == "" {
+			notice = "The service is under maintenance, please try again later"
+		}
+		return nil, status.Error(codes.Unavailable, notice)
+	}
+
+	res := object.GetOAuthToken(req.GrantType, req.ClientId, req.ClientSecret, req.Code, req.CodeVerifier, "", req.Scope, req.Username, req.Password, "", getClientIp(ctx), req.RefreshToken, req.Tag, req.Avatar, "", nil, nil, "en")
+	return toPbTokenResponse(res), nil
+}
+
+func (s *Server) RefreshToken(ctx context.Context, req *pb.RefreshTokenRequest) (*pb.TokenResponse, error) {
+	res := object.RefreshToken(req.GrantType, req.RefreshToken, req.Scope, req.ClientId, req.ClientSecret, "", nil)
+	return toPbTokenResponse(res), nil
+}
+
+// IntrospectToken requires the client credentials of the application that
+// issued the token, like the REST endpoint does.
+func (s *Server) IntrospectToken(ctx context.Context, req *pb.IntrospectTokenRequest) (*pb.IntrospectTokenResponse, error) {
+	application, err := getClientApplication(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	token := object.GetTokenByTokenAndApplication(req.Token, application.Name)
+	if token == nil {
+		return &pb.IntrospectTokenResponse{Active: false}, nil
+	}
+
+	jwtToken, err := object.ParseJwtTokenByApplication(req.Token, application)
+	if err != nil || jwtToken.Valid() != nil || util.IsTokenExpired(token.CreatedTime, token.ExpiresIn) {
+		return &pb.IntrospectTokenResponse{Active: false}, nil
+	}
+
+	return &pb.IntrospectTokenResponse{
+		Active:    true,
+		Scope:     jwtToken.Scope,
+		ClientId:  application.ClientId,
+		Username:  token.User,
+		TokenType: token.TokenType,
+		Exp:       jwtToken.ExpiresAt.Unix(),
+		Iat:       jwtToken.IssuedAt.Unix(),
+		Nbf:       jwtToken.NotBefore.Unix(),
+		Sub:       jwtToken.Subject,
+		Aud:       jwtToken.Audience,
+		Iss:       jwtToken.Issuer,
+		Jti:       jwtToken.ID,
+		DeviceId:  token.DeviceId,
+	}, nil
+}
+
+func (s *Server) Enforce(ctx context.Context, req *pb.EnforceRequest) (*pb.EnforceResponse, error) {
+	if err := authorize(ctx, http.MethodPost, "/api/enforce", "", ""); err != nil {
+		return nil, err
+	}
+
+	permissionRule := fromPbPermissionRule(req.Rule)
+	return &pb.EnforceResponse{Allowed: object.Enforce(&permissionRule)}, nil
+}
+
+func (s *Server) BatchEnforce(ctx context.Context, req *pb.BatchEnforceRequest) (*pb.BatchEnforceResponse, error) {
+	if err := authorize(ctx, http.MethodPost, "/api/batch-enforce", "", ""); err != nil {
+		return nil, err
+	}
+
+	permissionRules := []object.PermissionRule{}
+	for _, rule := range req.Rules {
+		permissionRules = append(permissionRules, fromPbPermissionRule(rule))
+	}
+
+	return &pb.BatchEnforceResponse{Allowed: object.BatchEnforce(permissionRules)}, nil
+}
@@ -0,0 +1,160 @@
+// Copyright 2023 The Casdoor Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package util
+
+import (
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// Location is where a request comes from, as told by the reverse proxy in
+// front of Casdoor, Casdoor has no IP database of its own.
+type Location struct {
+	Country   string  `json:"country"`
+	Region    string  `json:"region"`
+	City      string  `json:"city"`
+	Latitude  float64 `json:"latitude"`
+	Longitude float64 `json:"longitude"`
+}
+
+// GetLocationFromRequest reads the location headers of the proxy: "cloudflare"
+// for the visitor location headers of Cloudflare, or a prefix such as "X-Geo-"
+// for the "<prefix>Country", "<prefix>Region", "<prefix>City",
+// "<prefix>Latitude" and "<prefix>Longitude" headers. The headers must only
+// be trusted when the proxy sets them, so nothing is read when geoHeaders is
+// empty.
+func GetLocationFromRequest(req *http.Request, geoHeaders string) *Location {
+	if geoHeaders == "" {
+		return nil
+	}
+
+	var country, region, city, latitude, longitude string
+	if geoHeaders == "cloudflare" {
+		country = req.Header.Get("CF-IPCountry")
+		region = req.Header.Get("CF-Region")
+		city = req.Header.Get("CF-IPCity")
+		latitude = req.Header.Get("CF-IPLatitude")
+		longitude = req.Header.Get("CF-IPLongitude")
+	} else {
+		country = req.Header.Get(geoHeaders + "Country")
+		region = req.Header.Get(geoHeaders + "Region")
+		city = req.Header.Get(geoHeaders + "City")
+		latitude = req.Header.Get(geoHeaders + "Latitude")
+		longitude = req.Header.Get(geoHeaders + "Longitude")
+	}
+
+	// "XX" and "T1" are the unknown and Tor countries of Cloudflare
+	if country == "" || country == "XX" || country == "T1" {
+		return nil
+	}
+
+	location := &Location{Country: country}
+	location.Region, _ = url.QueryUnescape(region)
+	location.City, _ = url.QueryUnescape(city)
+	location.Latitude, _ = strconv.ParseFloat(latitude, 64)
+	location.Longitude, _ = strconv.ParseFloat(longitude, 64)
+	return location
+}
+
+func (location *Location) String() string {
+	if location == nil {
+		return ""
+	}
+
+	parts := []string{}
+	for _, part := range []string{location.City, location.Region, location.Country} {
+		if part != "" {
+			parts = append(parts, part)
+		}
+	}
+	return strings.Join(parts, ", ")
+}
+
+var userAgentBrowsers = []struct {
+	token string
+	name  string
+}{
+	// the order matters, Edge and Opera also claim to be Chrome, and Chrome
+	// claims to be Safari
+	{"Edg/", "Edge"},
+	{"OPR/", "Opera"},
+	{"Firefox/", "Firefox"},
+	{"Chrome/", "Chrome"},
+	{"Safari/", "Safari"},
+}
+
+var userAgentSystems = []struct {
+	token string
+	name  string
+}{
+	{"iPhone", "iOS"},
+	{"iPad", "iPadOS"},
+	{"Android", "Android"},
+	{"Windows", "Windows"},
+	{"Mac OS X", "macOS"},
+	{"CrOS", "ChromeOS"},
+	{"Linux", "Linux"},
+}
+
+// GetDeviceTypeFromUserAgent returns "Mobile", "Tablet" or "Desktop", it is
+// empty for API clients.
+func GetDeviceTypeFromUserAgent(userAgent string) string {
+	if !strings.HasPrefix(userAgent, "Mozilla/") {
+		return ""
+	}
+
+	if strings.Contains(userAgent, "iPad") || (strings.Contains(userAgent, "Android") && !strings.Contains(userAgent, "Mobile")) {
+		return "Tablet"
+	} else if strings.Contains(userAgent, "Mobile") || strings.Contains(userAgent, "iPhone") {
+		return "Mobile"
+	}
+	return "Desktop"
+}
+
+// GetDeviceFromUserAgent returns a short name of the browser and the system
+// of a user agent, such as "Chrome on Windows".
+func GetDeviceFromUserAgent(userAgent string) string {
+	if userAgent == "" {
+		return ""
+	}
+
+	browser := ""
+	for _, item := range userAgentBrowsers {
+		if strings.Contains(userAgent, item.token) {
+			browser = item.name
+			break
+		}
+	}
+
+	system := ""
+	for _, item := range userAgentSystems {
+		if strings.Contains(userAgent, item.token) {
+			system = item.name
+			break
+		}
+	}
+
+	if browser == "" && system == "" {
+		// API clients such as "curl/8.0.1" or "okhttp/4.9.0"
+		return strings.SplitN(userAgent, " ", 2)[0]
+	} else if browser == "" {
+		return system
+	} else if system == "" {
+		return browser
+	}
+	return browser + " on " + system
+}
@@ -0,0 +1,87 @@
+// Copyright 2023 The Casdoor Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package util
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetDeviceFromUserAgent(t *testing.T) {
+	scenarios := []struct {
+		description string
+		input       string
+		expected    string
+	}{
+		{"Chrome on Windows", "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/114.0.0.0 Safari/537.36", "Chrome on Windows"},
+		{"Edge on Windows", "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/114.0.0.0 Safari/537.36 Edg/114.0.1823.51", "Edge on Windows"},
+		{"Safari on iOS", "Mozilla/5.0 (iPhone; CPU iPhone OS 16_5 like Mac OS X) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/16.5 Mobile/15E148 Safari/604.1", "Safari on iOS"},
+		{"Firefox on Linux", "Mozilla/5.0 (X11; Linux x86_64; rv:109.0) Gecko/20100101 Firefox/114.0", "Firefox on Linux"},
+		{"Chrome on Android", "Mozilla/5.0 (Linux; Android 13; Pixel 7) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/114.0.0.0 Mobile Safari/537.36", "Chrome on Android"},
+		{"API client", "curl/8.0.1", "curl/8.0.1"},
+		{"Empty", "", ""},
+	}
+
+	for _, scenery := range scenarios {
+		t.Run(scenery.description, func(t *testing.T) {
+			assert.Equal(t, scenery.expected, GetDeviceFromUserAgent(scenery.input))
+		})
+	}
+}
+
+func TestGetDeviceTypeFromUserAgent(t *testing.T) {
+	scenarios := []struct {
+		description string
+		input       string
+		expected    string
+	}{
+		{"Windows", "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/114.0.0.0 Safari/537.36", "Desktop"},
+		{"iPhone", "Mozilla/5.0 (iPhone; CPU iPhone OS 16_5 like Mac OS X) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/16.5 Mobile/15E148 Safari/604.1", "Mobile"},
+		{"Android phone", "Mozilla/5.0 (Linux; Android 13; Pixel 7) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/114.0.0.0 Mobile Safari/537.36", "Mobile"},
+		{"iPad", "Mozilla/5.0 (iPad; CPU OS 16_5 like Mac OS X) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/16.5 Mobile/15E148 Safari/604.1", "Tablet"},
+		{"Android tablet", "Mozilla/5.0 (Linux; Android 13; SM-X700) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/114.0.0.0 Safari/537.36", "Tablet"},
+		{"API client", "curl/8.0.1", ""},
+		{"Empty", "", ""},
+	}
+
+	for _, scenery := range scenarios {
+		t.Run(scenery.description, func(t *testing.T) {
+			assert.Equal(t, scenery.expected, GetDeviceTypeFromUserAgent(scenery.input))
+		})
+	}
+}
+
+func TestGetLocationFromRequest(t *testing.T) {
+	req, _ := http.NewRequest("GET", "/", nil)
+	req.Header.Set("CF-IPCountry", "DE")
+	req.Header.Set("CF-IPCity", "Frankfurt%20am%20Main")
+	req.Header.Set("CF-IPLatitude", "50.11")
+	req.Header.Set("CF-IPLongitude", "8.68")
+
+	assert.Nil(t, GetLocationFromRequest(req, ""), "the headers are not trusted by default")
+
+	location := GetLocationFromRequest(req, "cloudflare")
+	assert.Equal(t, "Frankfurt am Main, DE", location.String())
+	assert.Equal(t, 50.11, location.Latitude)
+	assert.Equal(t, 8.68, location.Longitude)
+
+	req.Header.Set("CF-IPCountry", "XX")
+	assert.Nil(t, GetLocationFromRequest(req, "cloudflare"))
+
+	req.Header.Set("X-Geo-Country", "US")
+	assert.Equal(t, "US", GetLocationFromRequest(req, "X-Geo-").String())
+}
@@ -14,8 +14,6 @@
 
 package util
 
-import "sort"
-
 func DeleteVal(values []string, val string) []string {
 	newValues := []string{}
 	for _, v := range values {
@@ -27,6 +25,10 @@ func DeleteVal(values []string, val string) []string {
 }
 
 func ContainsString(values []string, val string) bool {
-	sort.Strings(values)
-	return sort.SearchStrings(values, val) != len(values)
+	for _, v := range values {
+		if v == val {
+			return true
+		}
+	}
+	return false
 }
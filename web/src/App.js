@@ -47,6 +47,9 @@ import CertEditPage from "./CertEditPage";
 import ProductListPage from "./ProductListPage";
 import ProductEditPage from "./ProductEditPage";
 import ProductBuyPage from "./ProductBuyPage";
+import PlanListPage from "./PlanListPage";
+import PlanEditPage from "./PlanEditPage";
+import SubscriptionListPage from "./SubscriptionListPage";
 import PaymentListPage from "./PaymentListPage";
 import PaymentEditPage from "./PaymentEditPage";
 import PaymentResultPage from "./PaymentResultPage";
@@ -151,6 +154,10 @@ class App extends Component {
       this.setState({selectedMenuKey: "/products"});
     } else if (uri.includes("/payments")) {
       this.setState({selectedMenuKey: "/payments"});
+    } else if (uri.includes("/plans")) {
+      this.setState({selectedMenuKey: "/plans"});
+    } else if (uri.includes("/subscriptions")) {
+      this.setState({selectedMenuKey: "/subscriptions"});
     } else if (uri.includes("/signup")) {
       this.setState({selectedMenuKey: "/signup"});
     } else if (uri.includes("/login")) {
@@ -454,6 +461,14 @@ class App extends Component {
           "/payments"
         ));
 
+        res.push(Setting.getItem(<Link to="/plans">{i18next.t("general:Plans")}</Link>,
+          "/plans"
+        ));
+
+        res.push(Setting.getItem(<Link to="/subscriptions">{i18next.t("general:Subscriptions")}</Link>,
+          "/subscriptions"
+        ));
+
         res.push(Setting.getItem(<Link to="/sysinfo">{i18next.t("general:System Info")}</Link>,
           "/sysinfo"
         ));
@@ -532,6 +547,9 @@ class App extends Component {
         <Route exact path="/products" render={(props) => this.renderLoginIfNotLoggedIn(<ProductListPage account={this.state.account} {...props} />)} />
         <Route exact path="/products/:productName" render={(props) => this.renderLoginIfNotLoggedIn(<ProductEditPage account={this.state.account} {...props} />)} />
         <Route exact path="/products/:productName/buy" render={(props) => this.renderLoginIfNotLoggedIn(<ProductBuyPage account={this.state.account} {...props} />)} />
+        <Route exact path="/plans" render={(props) => this.renderLoginIfNotLoggedIn(<PlanListPage account={this.state.account} {...props} />)} />
+        <Route exact path="/plans/:planName" render={(props) => this.renderLoginIfNotLoggedIn(<PlanEditPage account={this.state.account} {...props} />)} />
+        <Route exact path="/subscriptions" render={(props) => this.renderLoginIfNotLoggedIn(<SubscriptionListPage account={this.state.account} {...props} />)} />
         <Route exact path="/payments" render={(props) => this.renderLoginIfNotLoggedIn(<PaymentListPage account={this.state.account} {...props} />)} />
         <Route exact path="/payments/:paymentName" render={(props) => this.renderLoginIfNotLoggedIn(<PaymentEditPage account={this.state.account} {...props} />)} />
         <Route exact path="/payments/:paymentName/result" render={(props) => this.renderLoginIfNotLoggedIn(<PaymentResultPage account={this.state.account} {...props} />)} />
@@ -639,6 +657,7 @@ class App extends Component {
         window.location.pathname.startsWith("/forget") ||
         window.location.pathname.startsWith("/prompt") ||
         window.location.pathname.startsWith("/cas") ||
+        window.location.pathname.startsWith("/contact-change") ||
         window.location.pathname.startsWith("/auto-signup");
   }
 
@@ -13,7 +13,7 @@
 // limitations under the License.
 
 import React from "react";
-import {Button, Card, Col, ConfigProvider, Input, Popover, Radio, Result, Row, Select, Switch, Upload} from "antd";
+import {Button, Card, Col, ConfigProvider, Input, Popover, Radio, Result, Row, Select, Steps, Switch, Upload} from "antd";
 import {CopyOutlined, LinkOutlined, UploadOutlined} from "@ant-design/icons";
 import * as ApplicationBackend from "./backend/ApplicationBackend";
 import * as CertBackend from "./backend/CertBackend";
@@ -28,12 +28,14 @@ import i18next from "i18next";
 import UrlTable from "./table/UrlTable";
 import ProviderTable from "./table/ProviderTable";
 import SignupTable from "./table/SignupTable";
+import RoutingRuleTable from "./table/RoutingRuleTable";
 import PromptPage from "./auth/PromptPage";
 import copy from "copy-to-clipboard";
 
 import {Controlled as CodeMirror} from "react-codemirror2";
 import "codemirror/lib/codemirror.css";
 import ThemeEditor from "./common/theme/ThemeEditor";
+import BrandingEditor from "./common/BrandingEditor";
 
 require("codemirror/theme/material-darker.css");
 require("codemirror/mode/htmlmixed/htmlmixed");
@@ -105,6 +107,8 @@ class ApplicationEditPage extends React.Component {
       uploading: false,
       mode: props.location.mode !== undefined ? props.location.mode : "edit",
       samlMetadata: null,
+      ssoCheckResult: null,
+      ssoChecking: false,
       isAuthorized: true,
     };
   }
@@ -170,6 +174,49 @@ class ApplicationEditPage extends React.Component {
       });
   }
 
+  checkApplicationSso(type) {
+    this.setState({
+      ssoChecking: true,
+    });
+
+    ApplicationBackend.checkApplicationSso("admin", this.state.applicationName, type)
+      .then((res) => {
+        this.setState({
+          ssoChecking: false,
+        });
+
+        if (res.status === "ok") {
+          this.setState({
+            ssoCheckResult: res.data,
+          });
+          if (res.data.success) {
+            Setting.showMessage("success", i18next.t("application:The SSO test passed"));
+          }
+        } else {
+          this.setState({
+            ssoCheckResult: null,
+          });
+          Setting.showMessage("error", res.msg);
+        }
+      });
+  }
+
+  renderSsoCheckResult() {
+    if (this.state.ssoCheckResult === null) {
+      return null;
+    }
+
+    return (
+      <Steps direction="vertical" size="small" style={{marginTop: "10px"}} items={this.state.ssoCheckResult.steps.map(step => {
+        return {
+          title: step.name,
+          description: step.message,
+          status: step.status === "Ok" ? "finish" : "error",
+        };
+      })} />
+    );
+  }
+
   parseApplicationField(key, value) {
     if (["expireInHours", "refreshExpireInHours", "offset"].includes(key)) {
       value = Setting.myParseInt(value);
@@ -187,6 +234,16 @@ class ApplicationEditPage extends React.Component {
     });
   }
 
+  updateAttestationField(key, value) {
+    const attestation = {...(this.state.application.attestation ?? {}), [key]: value};
+    this.updateApplicationField("attestation", attestation);
+  }
+
+  updateClientCertLoginField(key, value) {
+    const clientCertLogin = {...(this.state.application.clientCertLogin ?? {}), [key]: value};
+    this.updateApplicationField("clientCertLogin", clientCertLogin);
+  }
+
   handleUpload(info) {
     if (info.file.type !== "text/html") {
       Setting.showMessage("error", i18next.t("application:Please select a HTML file"));
@@ -340,6 +397,34 @@ class ApplicationEditPage extends React.Component {
             />
           </Col>
         </Row>
+        <Row style={{marginTop: "20px"}} >
+          <Col style={{marginTop: "5px"}} span={(Setting.isMobile()) ? 22 : 2}>
+            {Setting.getLabel(i18next.t("application:Redirect URL policy"), i18next.t("application:Redirect URL policy - Tooltip"))} :
+          </Col>
+          <Col span={22} >
+            <Select virtual={false} style={{width: "100%"}} value={this.state.application.redirectUriPolicy} onChange={(value => {this.updateApplicationField("redirectUriPolicy", value);})}
+              options={[
+                {id: "", name: i18next.t("application:Legacy (regex)")},
+                {id: "Exact", name: i18next.t("application:Exact")},
+                {id: "Subdomain wildcard", name: i18next.t("application:Subdomain wildcard")},
+                {id: "Path prefix", name: i18next.t("application:Path prefix")},
+              ].map((item) => Setting.getOption(item.name, item.id))}
+            />
+          </Col>
+        </Row>
+        <Row style={{marginTop: "20px"}} >
+          <Col style={{marginTop: "5px"}} span={(Setting.isMobile()) ? 22 : 2}>
+            {Setting.getLabel(i18next.t("application:Routing rules"), i18next.t("application:Routing rules - Tooltip"))} :
+          </Col>
+          <Col span={22} >
+            <RoutingRuleTable
+              title={i18next.t("application:Routing rules")}
+              table={this.state.application.routingRules}
+              tags={this.state.organizations.find(organization => organization.name === this.state.application.organization)?.tags}
+              onUpdateTable={(value) => {this.updateApplicationField("routingRules", value);}}
+            />
+          </Col>
+        </Row>
         <Row style={{marginTop: "20px"}} >
           <Col style={{marginTop: "5px"}} span={(Setting.isMobile()) ? 22 : 2}>
             {Setting.getLabel(i18next.t("application:Token format"), i18next.t("application:Token format - Tooltip"))} :
@@ -390,6 +475,16 @@ class ApplicationEditPage extends React.Component {
             }} />
           </Col>
         </Row>
+        <Row style={{marginTop: "20px"}} >
+          <Col style={{marginTop: "5px"}} span={(Setting.isMobile()) ? 19 : 2}>
+            {Setting.getLabel(i18next.t("application:Welcome email"), i18next.t("application:Welcome email - Tooltip"))} :
+          </Col>
+          <Col span={1} >
+            <Switch checked={this.state.application.enableWelcomeEmail} onChange={checked => {
+              this.updateApplicationField("enableWelcomeEmail", checked);
+            }} />
+          </Col>
+        </Row>
         <Row style={{marginTop: "20px"}} >
           <Col style={{marginTop: "5px"}} span={(Setting.isMobile()) ? 19 : 2}>
             {Setting.getLabel(i18next.t("application:Signin session"), i18next.t("application:Enable signin session - Tooltip"))} :
@@ -440,6 +535,16 @@ class ApplicationEditPage extends React.Component {
             }} />
           </Col>
         </Row>
+        <Row style={{marginTop: "20px"}} >
+          <Col style={{marginTop: "5px"}} span={(Setting.isMobile()) ? 19 : 2}>
+            {Setting.getLabel(i18next.t("application:Enable organization picker"), i18next.t("application:Enable organization picker - Tooltip"))} :
+          </Col>
+          <Col span={1} >
+            <Switch checked={this.state.application.enableOrgPicker} onChange={checked => {
+              this.updateApplicationField("enableOrgPicker", checked);
+            }} />
+          </Col>
+        </Row>
         <Row style={{marginTop: "20px"}} >
           <Col style={{marginTop: "5px"}} span={(Setting.isMobile()) ? 22 : 2}>
             {Setting.getLabel(i18next.t("general:Signup URL"), i18next.t("general:Signup URL - Tooltip"))} :
@@ -556,11 +661,181 @@ class ApplicationEditPage extends React.Component {
                   {id: "token", name: "Token"},
                   {id: "id_token", name: "ID Token"},
                   {id: "refresh_token", name: "Refresh Token"},
+                  {id: "guest", name: "Guest"},
+                  {id: "device_credentials", name: "Device Credentials"},
                 ].map((item, index) => <Option key={index} value={item.id}>{item.name}</Option>)
               }
             </Select>
           </Col>
         </Row>
+        <Row style={{marginTop: "20px"}} >
+          <Col style={{marginTop: "5px"}} span={(Setting.isMobile()) ? 19 : 2}>
+            {Setting.getLabel(i18next.t("application:Require app attestation"), i18next.t("application:Require app attestation - Tooltip"))} :
+          </Col>
+          <Col span={1} >
+            <Switch checked={this.state.application.attestation?.required} onChange={checked => {
+              this.updateAttestationField("required", checked);
+            }} />
+          </Col>
+        </Row>
+        <Row style={{marginTop: "20px"}} >
+          <Col style={{marginTop: "5px"}} span={(Setting.isMobile()) ? 22 : 2}>
+            {Setting.getLabel(i18next.t("application:iOS app IDs"), i18next.t("application:iOS app IDs - Tooltip"))} :
+          </Col>
+          <Col span={22} >
+            <Select virtual={false} mode="tags" style={{width: "100%"}} value={this.state.application.attestation?.appleAppIds ?? []} onChange={value => {
+              this.updateAttestationField("appleAppIds", value);
+            }} />
+          </Col>
+        </Row>
+        <Row style={{marginTop: "20px"}} >
+          <Col style={{marginTop: "5px"}} span={(Setting.isMobile()) ? 19 : 2}>
+            {Setting.getLabel(i18next.t("application:Allow App Attest development"), i18next.t("application:Allow App Attest development - Tooltip"))} :
+          </Col>
+          <Col span={1} >
+            <Switch checked={this.state.application.attestation?.appleDevelopment} onChange={checked => {
+              this.updateAttestationField("appleDevelopment", checked);
+            }} />
+          </Col>
+        </Row>
+        <Row style={{marginTop: "20px"}} >
+          <Col style={{marginTop: "5px"}} span={(Setting.isMobile()) ? 22 : 2}>
+            {Setting.getLabel(i18next.t("application:Android package names"), i18next.t("application:Android package names - Tooltip"))} :
+          </Col>
+          <Col span={22} >
+            <Select virtual={false} mode="tags" style={{width: "100%"}} value={this.state.application.attestation?.playPackageNames ?? []} onChange={value => {
+              this.updateAttestationField("playPackageNames", value);
+            }} />
+          </Col>
+        </Row>
+        <Row style={{marginTop: "20px"}} >
+          <Col style={{marginTop: "5px"}} span={(Setting.isMobile()) ? 22 : 2}>
+            {Setting.getLabel(i18next.t("application:Android certificate digests"), i18next.t("application:Android certificate digests - Tooltip"))} :
+          </Col>
+          <Col span={22} >
+            <Select virtual={false} mode="tags" style={{width: "100%"}} value={this.state.application.attestation?.playCertDigests ?? []} onChange={value => {
+              this.updateAttestationField("playCertDigests", value);
+            }} />
+          </Col>
+        </Row>
+        <Row style={{marginTop: "20px"}} >
+          <Col style={{marginTop: "5px"}} span={(Setting.isMobile()) ? 22 : 2}>
+            {Setting.getLabel(i18next.t("application:Play Integrity decryption key"), i18next.t("application:Play Integrity decryption key - Tooltip"))} :
+          </Col>
+          <Col span={22} >
+            <Input.Password value={this.state.application.attestation?.playDecryptionKey} onChange={e => {
+              this.updateAttestationField("playDecryptionKey", e.target.value);
+            }} />
+          </Col>
+        </Row>
+        <Row style={{marginTop: "20px"}} >
+          <Col style={{marginTop: "5px"}} span={(Setting.isMobile()) ? 22 : 2}>
+            {Setting.getLabel(i18next.t("application:Play Integrity verification key"), i18next.t("application:Play Integrity verification key - Tooltip"))} :
+          </Col>
+          <Col span={22} >
+            <Input value={this.state.application.attestation?.playVerificationKey} onChange={e => {
+              this.updateAttestationField("playVerificationKey", e.target.value);
+            }} />
+          </Col>
+        </Row>
+        <Row style={{marginTop: "20px"}} >
+          <Col style={{marginTop: "5px"}} span={(Setting.isMobile()) ? 19 : 2}>
+            {Setting.getLabel(i18next.t("application:Require device integrity"), i18next.t("application:Require device integrity - Tooltip"))} :
+          </Col>
+          <Col span={1} >
+            <Switch checked={this.state.application.attestation?.playRequireDeviceIntegrity} onChange={checked => {
+              this.updateAttestationField("playRequireDeviceIntegrity", checked);
+            }} />
+          </Col>
+        </Row>
+        <Row style={{marginTop: "20px"}} >
+          <Col style={{marginTop: "5px"}} span={(Setting.isMobile()) ? 19 : 2}>
+            {Setting.getLabel(i18next.t("application:Enable certificate login"), i18next.t("application:Enable certificate login - Tooltip"))} :
+          </Col>
+          <Col span={1} >
+            <Switch checked={this.state.application.clientCertLogin?.enabled} onChange={checked => {
+              this.updateClientCertLoginField("enabled", checked);
+            }} />
+          </Col>
+        </Row>
+        {
+          !this.state.application.clientCertLogin?.enabled ? null : (
+            <React.Fragment>
+              <Row style={{marginTop: "20px"}} >
+                <Col style={{marginTop: "5px"}} span={(Setting.isMobile()) ? 22 : 2}>
+                  {Setting.getLabel(i18next.t("application:Client certificate CAs"), i18next.t("application:Client certificate CAs - Tooltip"))} :
+                </Col>
+                <Col span={22} >
+                  <Input.TextArea rows={6} style={{fontFamily: "monospace"}} value={this.state.application.clientCertLogin?.caCertificates} placeholder={"-----BEGIN CERTIFICATE-----"} onChange={e => {
+                    this.updateClientCertLoginField("caCertificates", e.target.value);
+                  }} />
+                </Col>
+              </Row>
+              <Row style={{marginTop: "20px"}} >
+                <Col style={{marginTop: "5px"}} span={(Setting.isMobile()) ? 22 : 2}>
+                  {Setting.getLabel(i18next.t("application:Client certificate header"), i18next.t("application:Client certificate header - Tooltip"))} :
+                </Col>
+                <Col span={22} >
+                  <Input value={this.state.application.clientCertLogin?.header} placeholder={"X-Ssl-Client-Cert"} onChange={e => {
+                    this.updateClientCertLoginField("header", e.target.value);
+                  }} />
+                </Col>
+              </Row>
+              <Row style={{marginTop: "20px"}} >
+                <Col style={{marginTop: "5px"}} span={(Setting.isMobile()) ? 22 : 2}>
+                  {Setting.getLabel(i18next.t("application:Trusted proxies"), i18next.t("application:Trusted proxies - Tooltip"))} :
+                </Col>
+                <Col span={22} >
+                  <Input value={this.state.application.clientCertLogin?.trustedProxies} placeholder={"10.0.0.1, 192.168.0.0/16"} onChange={e => {
+                    this.updateClientCertLoginField("trustedProxies", e.target.value);
+                  }} />
+                </Col>
+              </Row>
+              <Row style={{marginTop: "20px"}} >
+                <Col style={{marginTop: "5px"}} span={(Setting.isMobile()) ? 22 : 2}>
+                  {Setting.getLabel(i18next.t("application:Certificate field"), i18next.t("application:Certificate field - Tooltip"))} :
+                </Col>
+                <Col span={22} >
+                  <Select virtual={false} style={{width: "100%"}} value={this.state.application.clientCertLogin?.certField || "Common name"} onChange={value => {
+                    this.updateClientCertLoginField("certField", value);
+                  }}
+                  options={["Common name", "Email", "UPN", "Serial number"].map(item => Setting.getOption(item, item))} />
+                </Col>
+              </Row>
+              <Row style={{marginTop: "20px"}} >
+                <Col style={{marginTop: "5px"}} span={(Setting.isMobile()) ? 22 : 2}>
+                  {Setting.getLabel(i18next.t("application:User field"), i18next.t("application:User field - Tooltip"))} :
+                </Col>
+                <Col span={22} >
+                  <Select virtual={false} style={{width: "100%"}} value={this.state.application.clientCertLogin?.userField || "Name"} onChange={value => {
+                    this.updateClientCertLoginField("userField", value);
+                  }}
+                  options={["Name", "Email", "Phone", "ID card"].map(item => Setting.getOption(item, item))} />
+                </Col>
+              </Row>
+              <Row style={{marginTop: "20px"}} >
+                <Col style={{marginTop: "5px"}} span={(Setting.isMobile()) ? 19 : 2}>
+                  {Setting.getLabel(i18next.t("application:Check CRL"), i18next.t("application:Check CRL - Tooltip"))} :
+                </Col>
+                <Col span={1} >
+                  <Switch checked={this.state.application.clientCertLogin?.checkCrl} onChange={checked => {
+                    this.updateClientCertLoginField("checkCrl", checked);
+                  }} />
+                </Col>
+              </Row>
+              <Row style={{marginTop: "20px"}} >
+                <Col style={{marginTop: "5px"}} span={(Setting.isMobile()) ? 19 : 2}>
+                  {Setting.getLabel(i18next.t("application:Check OCSP"), i18next.t("application:Check OCSP - Tooltip"))} :
+                </Col>
+                <Col span={1} >
+                  <Switch checked={this.state.application.clientCertLogin?.checkOcsp} onChange={checked => {
+                    this.updateClientCertLoginField("checkOcsp", checked);
+                  }} />
+                </Col>
+              </Row>
+            </React.Fragment>
+          )
+        }
         <Row style={{marginTop: "20px"}} >
           <Col style={{marginTop: "5px"}} span={(Setting.isMobile()) ? 22 : 2}>
             {Setting.getLabel(i18next.t("application:SAML reply URL"), i18next.t("application:Redirect URL (Assertion Consumer Service POST Binding URL) - Tooltip"))} :
@@ -601,6 +876,35 @@ class ApplicationEditPage extends React.Component {
             </Button>
           </Col>
         </Row>
+        <Row style={{marginTop: "20px"}} >
+          <Col style={{marginTop: "5px"}} span={(Setting.isMobile()) ? 22 : 2}>
+            {Setting.getLabel(i18next.t("application:Forward auth URL"), i18next.t("application:Forward auth URL - Tooltip"))} :
+          </Col>
+          <Col span={22} >
+            <Input prefix={<LinkOutlined />} value={`${window.location.origin}/api/forward-auth?application=admin/${encodeURIComponent(this.state.applicationName)}`} readOnly={true} addonAfter={
+              <CopyOutlined onClick={() => {
+                copy(`${window.location.origin}/api/forward-auth?application=admin/${encodeURIComponent(this.state.applicationName)}`);
+                Setting.showMessage("success", i18next.t("general:Copied to clipboard successfully"));
+              }} />
+            } />
+          </Col>
+        </Row>
+        <Row style={{marginTop: "20px"}} >
+          <Col style={{marginTop: "5px"}} span={(Setting.isMobile()) ? 22 : 2}>
+            {Setting.getLabel(i18next.t("application:SSO test"), i18next.t("application:SSO test - Tooltip"))} :
+          </Col>
+          <Col span={22} >
+            <Button loading={this.state.ssoChecking} onClick={() => this.checkApplicationSso("saml")}>
+              {i18next.t("application:Test SAML")}
+            </Button>
+            <Button loading={this.state.ssoChecking} style={{marginLeft: "10px"}} onClick={() => this.checkApplicationSso("oidc")}>
+              {i18next.t("application:Test OIDC")}
+            </Button>
+            {
+              this.renderSsoCheckResult()
+            }
+          </Col>
+        </Row>
         <Row style={{marginTop: "20px"}} >
           <Col style={{marginTop: "5px"}} span={(Setting.isMobile()) ? 22 : 2}>
             {Setting.getLabel(i18next.t("general:Providers"), i18next.t("general:Providers - Tooltip"))} :
@@ -736,6 +1040,33 @@ class ApplicationEditPage extends React.Component {
             }
           </Col>
         </Row>
+        <Row style={{marginTop: "20px"}} >
+          <Col style={{marginTop: "5px"}} span={(Setting.isMobile()) ? 22 : 2}>
+            {Setting.getLabel(i18next.t("organization:Branding"), i18next.t("organization:Branding - Tooltip"))} :
+          </Col>
+          <Col span={22} >
+            <BrandingEditor branding={this.state.application.branding} onChange={(branding) => {
+              this.updateApplicationField("branding", branding);
+            }} />
+          </Col>
+        </Row>
+        <Row style={{marginTop: "20px"}} >
+          <Col style={{marginTop: "5px"}} span={(Setting.isMobile()) ? 22 : 2}>
+            {Setting.getLabel(i18next.t("application:ReBAC namespaces"), i18next.t("application:ReBAC namespaces - Tooltip"))} :
+          </Col>
+          <Col span={22} >
+            <Input.TextArea rows={6} style={{fontFamily: "monospace"}}
+              placeholder={"[{\"name\": \"doc\", \"relations\": [{\"name\": \"editor\", \"inherits\": []}, {\"name\": \"viewer\", \"inherits\": [\"editor\", \"parent->viewer\"]}]}]"}
+              defaultValue={this.state.application.rebacNamespaces ? JSON.stringify(this.state.application.rebacNamespaces, null, 2) : ""}
+              onChange={e => {
+                try {
+                  this.updateApplicationField("rebacNamespaces", e.target.value === "" ? [] : JSON.parse(e.target.value));
+                } catch (err) {
+                  // keep the last valid namespaces while editing
+                }
+              }} />
+          </Col>
+        </Row>
         {
           !this.state.application.enableSignUp ? null : (
             <Row style={{marginTop: "20px"}} >
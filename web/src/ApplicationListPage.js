@@ -63,6 +63,7 @@ class ApplicationListPage extends BaseListPage {
       ],
       cert: "cert-built-in",
       redirectUris: ["http://localhost:9000/callback"],
+      redirectUriPolicy: "Exact",
       tokenFormat: "JWT",
       expireInHours: 24 * 7,
       formOffset: 2,
@@ -13,8 +13,9 @@
 // limitations under the License.
 
 import React from "react";
-import {Button, Card, Col, Input, InputNumber, Row, Select} from "antd";
+import {Button, Card, Col, Input, InputNumber, Row, Select, Switch} from "antd";
 import * as CertBackend from "./backend/CertBackend";
+import * as OrganizationBackend from "./backend/OrganizationBackend";
 import * as Setting from "./Setting";
 import i18next from "i18next";
 import copy from "copy-to-clipboard";
@@ -30,12 +31,25 @@ class CertEditPage extends React.Component {
       classes: props,
       certName: props.match.params.certName,
       cert: null,
+      csrForm: {commonName: "", organization: "", dnsNames: []},
+      signedCertificate: "",
+      organizations: [],
       mode: props.location.mode !== undefined ? props.location.mode : "edit",
     };
   }
 
   UNSAFE_componentWillMount() {
     this.getCert();
+    this.getOrganizations();
+  }
+
+  getOrganizations() {
+    OrganizationBackend.getOrganizations("admin")
+      .then((res) => {
+        this.setState({
+          organizations: (res.msg === undefined) ? res : [],
+        });
+      });
   }
 
   getCert() {
@@ -43,10 +57,42 @@ class CertEditPage extends React.Component {
       .then((cert) => {
         this.setState({
           cert: cert,
+          csrForm: {...this.state.csrForm, commonName: cert?.name ?? ""},
         });
       });
   }
 
+  updateCsrFormField(key, value) {
+    this.setState({
+      csrForm: {...this.state.csrForm, [key]: value},
+    });
+  }
+
+  generateCsr() {
+    CertBackend.generateCertCsr(this.state.cert.owner, this.state.certName, this.state.csrForm)
+      .then((res) => {
+        if (res.status === "ok") {
+          Setting.showMessage("success", i18next.t("cert:CSR generated successfully"));
+          this.updateCertField("csr", res.data);
+        } else {
+          Setting.showMessage("error", res.msg);
+        }
+      });
+  }
+
+  uploadSignedCertificate() {
+    CertBackend.uploadCertCertificate(this.state.cert.owner, this.state.certName, this.state.signedCertificate)
+      .then((res) => {
+        if (res.status === "ok") {
+          Setting.showMessage("success", i18next.t("cert:Certificate uploaded successfully"));
+          this.setState({signedCertificate: ""});
+          this.getCert();
+        } else {
+          Setting.showMessage("error", res.msg);
+        }
+      });
+  }
+
   parseCertField(key, value) {
     if (["port"].includes(key)) {
       value = Setting.myParseInt(value);
@@ -122,6 +168,8 @@ class CertEditPage extends React.Component {
               {
                 [
                   {id: "x509", name: "x509"},
+                  {id: "SSH CA", name: "SSH CA"},
+                  {id: "Device CA", name: "Device CA"},
                 ].map((item, index) => <Option key={index} value={item.id}>{item.name}</Option>)
               }
             </Select>
@@ -138,6 +186,7 @@ class CertEditPage extends React.Component {
               {
                 [
                   {id: "RS256", name: "RS256"},
+                  ...(this.state.cert.type === "SSH CA" ? [{id: "Ed25519", name: "Ed25519"}] : []),
                 ].map((item, index) => <Option key={index} value={item.id}>{item.name}</Option>)
               }
             </Select>
@@ -153,16 +202,69 @@ class CertEditPage extends React.Component {
             }} />
           </Col>
         </Row>
-        <Row style={{marginTop: "20px"}} >
-          <Col style={{marginTop: "5px"}} span={(Setting.isMobile()) ? 22 : 2}>
-            {Setting.getLabel(i18next.t("cert:Expire in years"), i18next.t("cert:Expire in years - Tooltip"))} :
-          </Col>
-          <Col span={22} >
-            <InputNumber value={this.state.cert.expireInYears} onChange={value => {
-              this.updateCertField("expireInYears", value);
-            }} />
-          </Col>
-        </Row>
+        {
+          this.state.cert.type === "SSH CA" ? (
+            <React.Fragment>
+              <Row style={{marginTop: "20px"}} >
+                <Col style={{marginTop: "5px"}} span={(Setting.isMobile()) ? 22 : 2}>
+                  {Setting.getLabel(i18next.t("cert:Certificate lifetime in minutes"), i18next.t("cert:Certificate lifetime in minutes - Tooltip"))} :
+                </Col>
+                <Col span={22} >
+                  <InputNumber min={0} placeholder={480} value={this.state.cert.sshExpireInMinutes} onChange={value => {
+                    this.updateCertField("sshExpireInMinutes", value ?? 0);
+                  }} />
+                </Col>
+              </Row>
+              <Row style={{marginTop: "20px"}} >
+                <Col style={{marginTop: "5px"}} span={(Setting.isMobile()) ? 22 : 2}>
+                  {Setting.getLabel(i18next.t("cert:Max certificate lifetime in minutes"), i18next.t("cert:Max certificate lifetime in minutes - Tooltip"))} :
+                </Col>
+                <Col span={22} >
+                  <InputNumber min={0} value={this.state.cert.sshMaxExpireInMinutes} onChange={value => {
+                    this.updateCertField("sshMaxExpireInMinutes", value ?? 0);
+                  }} />
+                </Col>
+              </Row>
+              <Row style={{marginTop: "20px"}} >
+                <Col style={{marginTop: "5px"}} span={(Setting.isMobile()) ? 19 : 2}>
+                  {Setting.getLabel(i18next.t("cert:User name as principal"), i18next.t("cert:User name as principal - Tooltip"))} :
+                </Col>
+                <Col span={1} >
+                  <Switch checked={this.state.cert.sshUserPrincipal} onChange={checked => {
+                    this.updateCertField("sshUserPrincipal", checked);
+                  }} />
+                </Col>
+              </Row>
+            </React.Fragment>
+          ) : (
+            <Row style={{marginTop: "20px"}} >
+              <Col style={{marginTop: "5px"}} span={(Setting.isMobile()) ? 22 : 2}>
+                {Setting.getLabel(i18next.t("cert:Expire in years"), i18next.t("cert:Expire in years - Tooltip"))} :
+              </Col>
+              <Col span={22} >
+                <InputNumber value={this.state.cert.expireInYears} onChange={value => {
+                  this.updateCertField("expireInYears", value);
+                }} />
+              </Col>
+            </Row>
+          )
+        }
+        {
+          this.state.cert.type !== "SSH CA" && this.state.cert.type !== "Device CA" ? null : (
+            <Row style={{marginTop: "20px"}} >
+              <Col style={{marginTop: "5px"}} span={(Setting.isMobile()) ? 22 : 2}>
+                {Setting.getLabel(i18next.t("general:Organization"), i18next.t("cert:Organization - Tooltip"))} :
+              </Col>
+              <Col span={22} >
+                <Select virtual={false} style={{width: "100%"}} allowClear value={this.state.cert.organization} onChange={(value => {this.updateCertField("organization", value ?? "");})}>
+                  {
+                    this.state.organizations.map((organization, index) => <Option key={index} value={organization.name}>{organization.name}</Option>)
+                  }
+                </Select>
+              </Col>
+            </Row>
+          )
+        }
         <Row style={{marginTop: "20px"}} >
           <Col style={{marginTop: "5px"}} span={(Setting.isMobile()) ? 22 : 2}>
             {Setting.getLabel(i18next.t("cert:Certificate"), i18next.t("cert:Certificate - Tooltip"))} :
@@ -210,6 +312,57 @@ class CertEditPage extends React.Component {
             }} />
           </Col>
         </Row>
+        <Row style={{marginTop: "20px"}} >
+          <Col style={{marginTop: "5px"}} span={(Setting.isMobile()) ? 22 : 2}>
+            {Setting.getLabel(i18next.t("cert:Expire time"), i18next.t("cert:Expire time - Tooltip"))} :
+          </Col>
+          <Col span={22} >
+            <Input value={this.state.cert.expireTime ? Setting.getFormattedDate(this.state.cert.expireTime) : ""} disabled={true} />
+          </Col>
+        </Row>
+        {
+          this.state.mode === "add" || this.state.cert.type === "SSH CA" ? null : (
+            <Row style={{marginTop: "20px"}} >
+              <Col style={{marginTop: "5px"}} span={(Setting.isMobile()) ? 22 : 2}>
+                {Setting.getLabel(i18next.t("cert:CSR"), i18next.t("cert:CSR - Tooltip"))} :
+              </Col>
+              <Col span={editorWidth} >
+                <Input addonBefore={i18next.t("cert:Common name")} value={this.state.csrForm.commonName} onChange={e => {
+                  this.updateCsrFormField("commonName", e.target.value);
+                }} />
+                <Input style={{marginTop: "10px"}} addonBefore={i18next.t("general:Organization")} value={this.state.csrForm.organization} onChange={e => {
+                  this.updateCsrFormField("organization", e.target.value);
+                }} />
+                <Select virtual={false} mode="tags" style={{width: "100%", marginTop: "10px"}} placeholder={i18next.t("cert:DNS names")} value={this.state.csrForm.dnsNames} onChange={value => {
+                  this.updateCsrFormField("dnsNames", value);
+                }} />
+                <Button style={{marginTop: "10px", marginRight: "10px", marginBottom: "10px"}} onClick={() => this.generateCsr()}>
+                  {i18next.t("cert:Generate CSR")}
+                </Button>
+                <Button type="primary" disabled={!this.state.cert.csr} onClick={() => {
+                  const blob = new Blob([this.state.cert.csr], {type: "text/plain;charset=utf-8"});
+                  FileSaver.saveAs(blob, `${this.state.cert.name}.csr`);
+                }}
+                >
+                  {i18next.t("cert:Download CSR")}
+                </Button>
+                <TextArea autoSize={{minRows: 10, maxRows: 10}} value={this.state.cert.csr} readOnly={true} />
+              </Col>
+              <Col span={1} />
+              <Col style={{marginTop: "5px"}} span={(Setting.isMobile()) ? 22 : 2}>
+                {Setting.getLabel(i18next.t("cert:Signed certificate"), i18next.t("cert:Signed certificate - Tooltip"))} :
+              </Col>
+              <Col span={editorWidth} >
+                <Button type="primary" style={{marginBottom: "10px"}} disabled={this.state.signedCertificate === ""} onClick={() => this.uploadSignedCertificate()}>
+                  {i18next.t("cert:Upload certificate")}
+                </Button>
+                <TextArea autoSize={{minRows: 15, maxRows: 15}} value={this.state.signedCertificate} onChange={e => {
+                  this.setState({signedCertificate: e.target.value});
+                }} />
+              </Col>
+            </Row>
+          )
+        }
       </Card>
     );
   }
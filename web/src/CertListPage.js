@@ -159,6 +159,16 @@ class CertListPage extends BaseListPage {
         sorter: true,
         ...this.getColumnSearchProps("expireInYears"),
       },
+      {
+        title: i18next.t("cert:Expire time"),
+        dataIndex: "expireTime",
+        key: "expireTime",
+        width: "170px",
+        sorter: true,
+        render: (text, record, index) => {
+          return text ? Setting.getFormattedDate(text) : null;
+        },
+      },
       {
         title: i18next.t("general:Action"),
         dataIndex: "",
@@ -25,6 +25,7 @@ import SelfForgetPage from "./auth/SelfForgetPage";
 import ForgetPage from "./auth/ForgetPage";
 import PromptPage from "./auth/PromptPage";
 import CasLogout from "./auth/CasLogout";
+import ContactChangePage from "./auth/ContactChangePage";
 
 class EntryPage extends React.Component {
   constructor(props) {
@@ -85,6 +86,7 @@ class EntryPage extends React.Component {
           <Route exact path="/prompt/:applicationName" render={(props) => this.renderLoginIfNotLoggedIn(<PromptPage {...this.props} application={this.state.application} onUpdateApplication={onUpdateApplication} {...props} />)} />
           <Route exact path="/cas/:owner/:casApplicationName/logout" render={(props) => this.renderHomeIfLoggedIn(<CasLogout {...this.props} application={this.state.application} onUpdateApplication={onUpdateApplication} {...props} />)} />
           <Route exact path="/cas/:owner/:casApplicationName/login" render={(props) => {return (<LoginPage {...this.props} application={this.state.application} type={"cas"} mode={"signup"} onUpdateApplication={onUpdateApplication} {...props} />);}} />
+          <Route exact path="/contact-change" render={(props) => <ContactChangePage {...this.props} application={this.state.application} onUpdateApplication={onUpdateApplication} {...props} />} />
         </Switch>
       </div>
     );
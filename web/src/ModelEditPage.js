@@ -33,6 +33,9 @@ class ModelEditPage extends React.Component {
       organizations: [],
       users: [],
       models: [],
+      templates: [],
+      validation: JSON.stringify({policies: [], groupingPolicies: [], requests: []}, null, 2),
+      report: null,
       mode: props.location.mode !== undefined ? props.location.mode : "edit",
     };
   }
@@ -40,6 +43,77 @@ class ModelEditPage extends React.Component {
   UNSAFE_componentWillMount() {
     this.getModel();
     this.getOrganizations();
+    this.getModelTemplates();
+  }
+
+  getModelTemplates() {
+    ModelBackend.getModelTemplates()
+      .then((res) => {
+        if (res.status === "ok") {
+          this.setState({
+            templates: res.data,
+          });
+        }
+      });
+  }
+
+  applyModelTemplate(name) {
+    const template = this.state.templates.find(template => template.name === name);
+    if (template === undefined) {
+      return;
+    }
+
+    const {modelText, policies, groupingPolicies, requests} = template.validation;
+    this.updateModelField("modelText", modelText);
+    this.setState({
+      validation: JSON.stringify({policies: policies ?? [], groupingPolicies: groupingPolicies ?? [], requests: requests ?? []}, null, 2),
+      report: null,
+    });
+  }
+
+  validateModel() {
+    let validation;
+    try {
+      validation = JSON.parse(this.state.validation);
+    } catch (e) {
+      Setting.showMessage("error", `${i18next.t("model:Invalid policies and requests")}: ${e}`);
+      return;
+    }
+
+    ModelBackend.validateModel({...validation, owner: this.state.model.owner, modelText: this.state.model.modelText})
+      .then((res) => {
+        if (res.status === "ok") {
+          this.setState({
+            report: res.data,
+          });
+        } else {
+          Setting.showMessage("error", res.msg);
+        }
+      });
+  }
+
+  renderValidationReport() {
+    const report = this.state.report;
+    if (report === null) {
+      return null;
+    }
+
+    return (
+      <div style={{marginTop: "10px"}}>
+        <div style={{color: report.isValid ? "green" : "red"}}>
+          {report.isValid ? i18next.t("model:Valid") : `${i18next.t("model:Invalid")} ${report.error}`}
+        </div>
+        {
+          report.results.map((result, index) => {
+            return (
+              <div key={index} style={{color: result.passed ? null : "red"}}>
+                {`${result.request.join(", ")} => ${result.error !== "" ? result.error : result.allowed}${result.explain?.length > 0 ? ` (${result.explain.join(", ")})` : ""}`}
+              </div>
+            );
+          })
+        }
+      </div>
+    );
   }
 
   getModel() {
@@ -135,11 +209,26 @@ class ModelEditPage extends React.Component {
             {Setting.getLabel(i18next.t("model:Model text"), i18next.t("model:Model text - Tooltip"))} :
           </Col>
           <Col span={22}>
+            <Select virtual={false} style={{width: "100%", marginBottom: "10px"}} placeholder={i18next.t("model:Load a template")} value={null} onChange={(value => {this.applyModelTemplate(value);})}
+              options={this.state.templates.map(template => Setting.getOption(`${template.name} - ${template.description}`, template.name))}
+            />
             <TextArea rows={10} value={this.state.model.modelText} onChange={e => {
               this.updateModelField("modelText", e.target.value);
             }} />
           </Col>
         </Row>
+        <Row style={{marginTop: "20px"}} >
+          <Col style={{marginTop: "5px"}} span={(Setting.isMobile()) ? 22 : 2}>
+            {Setting.getLabel(i18next.t("model:Validation"), i18next.t("model:Validation - Tooltip"))} :
+          </Col>
+          <Col span={22}>
+            <TextArea rows={8} value={this.state.validation} onChange={e => {
+              this.setState({validation: e.target.value});
+            }} />
+            <Button style={{marginTop: "10px"}} onClick={() => this.validateModel()}>{i18next.t("model:Validate")}</Button>
+            {this.renderValidationReport()}
+          </Col>
+        </Row>
         <Row style={{marginTop: "20px"}} >
           <Col style={{marginTop: "5px"}} span={(Setting.isMobile()) ? 19 : 2}>
             {Setting.getLabel(i18next.t("general:Is enabled"), i18next.t("general:Is enabled - Tooltip"))} :
@@ -13,17 +13,24 @@
 // limitations under the License.
 
 import React from "react";
-import {Button, Card, Col, Input, InputNumber, Radio, Row, Select, Switch} from "antd";
+import {Button, Card, Col, Input, InputNumber, Popconfirm, Radio, Row, Select, Switch} from "antd";
 import * as OrganizationBackend from "./backend/OrganizationBackend";
 import * as ApplicationBackend from "./backend/ApplicationBackend";
 import * as LdapBackend from "./backend/LdapBackend";
+import * as CertBackend from "./backend/CertBackend";
 import * as Setting from "./Setting";
 import * as Conf from "./Conf";
 import i18next from "i18next";
 import {LinkOutlined} from "@ant-design/icons";
 import LdapTable from "./table/LdapTable";
 import AccountTable from "./table/AccountTable";
+import EmailDomainTable from "./table/EmailDomainTable";
+import ErrorMessageTable from "./table/ErrorMessageTable";
+import RoutingRuleTable from "./table/RoutingRuleTable";
+import SshCertificateTable from "./table/SshCertificateTable";
+import DeviceTable from "./table/DeviceTable";
 import ThemeEditor from "./common/theme/ThemeEditor";
+import BrandingEditor from "./common/BrandingEditor";
 
 const {Option} = Select;
 
@@ -34,16 +41,25 @@ class OrganizationEditPage extends React.Component {
       classes: props,
       organizationName: props.match.params.organizationName,
       organization: null,
+      organizations: [],
       applications: [],
       ldaps: null,
+      errorCodes: [],
+      certs: [],
+      shards: null,
+      targetShard: null,
       mode: props.location.mode !== undefined ? props.location.mode : "edit",
     };
   }
 
   UNSAFE_componentWillMount() {
     this.getOrganization();
+    this.getOrganizations();
     this.getApplications();
     this.getLdaps();
+    this.getErrorCodes();
+    this.getCerts();
+    this.getShards();
   }
 
   getOrganization() {
@@ -55,6 +71,70 @@ class OrganizationEditPage extends React.Component {
       });
   }
 
+  getErrorCodes() {
+    OrganizationBackend.getErrorCodes()
+      .then((res) => {
+        if (res.status === "ok") {
+          this.setState({
+            errorCodes: res.data,
+          });
+        }
+      });
+  }
+
+  getCerts() {
+    CertBackend.getCerts("admin")
+      .then((res) => {
+        this.setState({
+          certs: (res.msg === undefined) ? res : [],
+        });
+      });
+  }
+
+  getShards() {
+    OrganizationBackend.getShards()
+      .then((res) => {
+        // only global admins can see the shards
+        if (res.status === "ok") {
+          this.setState({
+            shards: res.data,
+          });
+        }
+      });
+  }
+
+  moveOrganizationShard() {
+    const shard = this.state.targetShard ?? "";
+    OrganizationBackend.moveOrganizationShard(this.state.organization.owner, this.state.organization.name, shard)
+      .then((res) => {
+        if (res.status === "ok") {
+          Setting.showMessage("success", `${i18next.t("organization:Moved users")}: ${res.data.users}`);
+          this.updateOrganizationField("shard", shard);
+          this.setState({targetShard: null});
+          this.getShards();
+        } else {
+          Setting.showMessage("error", `${i18next.t("organization:Failed to move")}: ${res.msg}`);
+        }
+      })
+      .catch(error => {
+        Setting.showMessage("error", `${i18next.t("general:Failed to connect to server")}: ${error}`);
+      });
+  }
+
+  getShardLabel(shard) {
+    const name = shard.name === "" ? i18next.t("organization:Main database") : shard.name;
+    return shard.region === "" ? name : `${name} (${shard.region})`;
+  }
+
+  getOrganizations() {
+    OrganizationBackend.getOrganizations("admin")
+      .then((res) => {
+        this.setState({
+          organizations: (res.msg === undefined) ? res : [],
+        });
+      });
+  }
+
   getApplications() {
     ApplicationBackend.getApplicationsByOrganization("admin", this.state.organizationName)
       .then((applications) => {
@@ -125,6 +205,16 @@ class OrganizationEditPage extends React.Component {
             }} />
           </Col>
         </Row>
+        <Row style={{marginTop: "20px"}} >
+          <Col style={{marginTop: "5px"}} span={(Setting.isMobile()) ? 22 : 2}>
+            {Setting.getLabel(i18next.t("organization:Parent organization"), i18next.t("organization:Parent organization - Tooltip"))} :
+          </Col>
+          <Col span={22} >
+            <Select virtual={false} style={{width: "100%"}} allowClear value={this.state.organization.parentOrganization || undefined} onChange={(value => {this.updateOrganizationField("parentOrganization", value ?? "");})}
+              options={this.state.organizations.filter(item => item.name !== this.state.organizationName).map((item) => Setting.getOption(item.displayName, item.name))
+              } />
+          </Col>
+        </Row>
         <Row style={{marginTop: "20px"}} >
           <Col style={{marginTop: "5px"}} span={(Setting.isMobile()) ? 22 : 2}>
             {Setting.getLabel(i18next.t("general:Favicon"), i18next.t("general:Favicon - Tooltip"))} :
@@ -162,16 +252,58 @@ class OrganizationEditPage extends React.Component {
             }} />
           </Col>
         </Row>
+        <Row style={{marginTop: "20px"}} >
+          <Col style={{marginTop: "5px"}} span={(Setting.isMobile()) ? 22 : 2}>
+            {Setting.getLabel(i18next.t("organization:Domain"), i18next.t("organization:Domain - Tooltip"))} :
+          </Col>
+          <Col span={22} >
+            <Input value={this.state.organization.domain} placeholder={"login.example.com"} onChange={e => {
+              this.updateOrganizationField("domain", e.target.value);
+            }} addonAfter={this.state.organization.isDomainVerified ? i18next.t("organization:Verified") : i18next.t("organization:Not verified")} />
+            {
+              !this.state.organization.domainToken ? null : (
+                <div style={{marginTop: "10px"}}>
+                  {`${i18next.t("organization:TXT record")}: _casdoor-challenge.${this.state.organization.domain} = ${this.state.organization.domainToken}`}
+                  <Button style={{marginLeft: "20px"}} size="small" onClick={() => this.verifyOrganizationDomain()}>{i18next.t("organization:Verify")}</Button>
+                </div>
+              )
+            }
+          </Col>
+        </Row>
         <Row style={{marginTop: "20px"}} >
           <Col style={{marginTop: "5px"}} span={(Setting.isMobile()) ? 22 : 2}>
             {Setting.getLabel(i18next.t("general:Password type"), i18next.t("general:Password type - Tooltip"))} :
           </Col>
           <Col span={22} >
             <Select virtual={false} style={{width: "100%"}} value={this.state.organization.passwordType} onChange={(value => {this.updateOrganizationField("passwordType", value);})}
-              options={["plain", "salt", "md5-salt", "bcrypt", "pbkdf2-salt", "argon2id"].map(item => Setting.getOption(item, item))}
+              options={[
+                ...(this.state.organization.parentOrganization ? [Setting.getOption(i18next.t("organization:Inherit from parent"), "")] : []),
+                ...["plain", "salt", "md5-salt", "bcrypt", "pbkdf2-salt", "argon2id"].map(item => Setting.getOption(item, item)),
+              ]}
             />
           </Col>
         </Row>
+        {
+          this.state.organization.passwordType !== "argon2id" ? null : (
+            <Row style={{marginTop: "20px"}} >
+              <Col style={{marginTop: "5px"}} span={(Setting.isMobile()) ? 22 : 2}>
+                {Setting.getLabel(i18next.t("organization:Argon2 parameters"), i18next.t("organization:Argon2 parameters - Tooltip"))} :
+              </Col>
+              <Col span={22} >
+                {
+                  [["memory", i18next.t("organization:Memory (KiB)"), 65536], ["iterations", i18next.t("organization:Iterations"), 1], ["parallelism", i18next.t("organization:Parallelism"), 2]].map(([key, label, placeholder]) => (
+                    <span key={key} style={{marginRight: "20px"}}>
+                      {label} :&nbsp;
+                      <InputNumber min={0} placeholder={placeholder} value={this.state.organization.argon2Params?.[key]} onChange={value => {
+                        this.updateOrganizationField("argon2Params", {...this.state.organization.argon2Params, [key]: value ?? 0});
+                      }} />
+                    </span>
+                  ))
+                }
+              </Col>
+            </Row>
+          )
+        }
         <Row style={{marginTop: "20px"}} >
           <Col style={{marginTop: "5px"}} span={(Setting.isMobile()) ? 22 : 2}>
             {Setting.getLabel(i18next.t("general:Password salt"), i18next.t("general:Password salt - Tooltip"))} :
@@ -226,6 +358,41 @@ class OrganizationEditPage extends React.Component {
             </Row>
           </Col>
         </Row>
+        <Row style={{marginTop: "20px"}} >
+          <Col style={{marginTop: "5px"}} span={(Setting.isMobile()) ? 22 : 2}>
+            {Setting.getLabel(i18next.t("organization:Avatar pipeline"), i18next.t("organization:Avatar pipeline - Tooltip"))} :
+          </Col>
+          <Col span={22} >
+            <span style={{marginRight: "20px"}}>
+              <Switch checked={this.state.organization.avatarSettings !== null && this.state.organization.avatarSettings !== undefined} onChange={checked => {
+                this.updateOrganizationField("avatarSettings", checked ? {enableInitials: true, enableGravatar: false, sizes: [64, 128, 256]} : null);
+              }} />
+            </span>
+            {
+              !this.state.organization.avatarSettings ? null : (
+                <React.Fragment>
+                  {
+                    [["enableInitials", i18next.t("organization:Initials avatars")], ["enableGravatar", i18next.t("organization:Gravatar of verified emails")]].map(([key, label]) => (
+                      <span key={key} style={{marginRight: "20px"}}>
+                        {label} :&nbsp;
+                        <Switch checked={this.state.organization.avatarSettings[key]} onChange={checked => {
+                          this.updateOrganizationField("avatarSettings", {...this.state.organization.avatarSettings, [key]: checked});
+                        }} />
+                      </span>
+                    ))
+                  }
+                  {i18next.t("organization:Avatar sizes")} :&nbsp;
+                  <Select virtual={false} mode="tags" style={{width: "200px"}} value={(this.state.organization.avatarSettings.sizes ?? []).map(size => `${size}`)}
+                    onChange={value => {
+                      const sizes = value.map(size => parseInt(size)).filter(size => size > 0);
+                      this.updateOrganizationField("avatarSettings", {...this.state.organization.avatarSettings, sizes: sizes});
+                    }}
+                  />
+                </React.Fragment>
+              )
+            }
+          </Col>
+        </Row>
         <Row style={{marginTop: "20px"}} >
           <Col style={{marginTop: "5px"}} span={(Setting.isMobile()) ? 22 : 2}>
             {Setting.getLabel(i18next.t("general:Default application"), i18next.t("general:Default application - Tooltip"))} :
@@ -274,6 +441,22 @@ class OrganizationEditPage extends React.Component {
             </Select>
           </Col>
         </Row>
+        <Row style={{marginTop: "20px"}} >
+          <Col style={{marginTop: "5px"}} span={(Setting.isMobile()) ? 22 : 2}>
+            {Setting.getLabel(i18next.t("organization:Default language"), i18next.t("organization:Default language - Tooltip"))} :
+          </Col>
+          <Col span={22} >
+            <Select virtual={false} style={{width: "100%"}} allowClear
+              options={Setting.Countries.map((item) => {
+                return Setting.getOption(item.label, item.key);
+              })}
+              value={this.state.organization.defaultLanguage === "" ? undefined : this.state.organization.defaultLanguage}
+              onChange={(value => {
+                this.updateOrganizationField("defaultLanguage", value ?? "");
+              })} >
+            </Select>
+          </Col>
+        </Row>
         <Row style={{marginTop: "20px"}} >
           <Col style={{marginTop: "5px"}} span={(Setting.isMobile()) ? 19 : 2}>
             {Setting.getLabel(i18next.t("organization:Init score"), i18next.t("organization:Init score - Tooltip"))} :
@@ -304,6 +487,208 @@ class OrganizationEditPage extends React.Component {
             }} />
           </Col>
         </Row>
+        <Row style={{marginTop: "20px"}} >
+          <Col style={{marginTop: "5px"}} span={(Setting.isMobile()) ? 22 : 2}>
+            {Setting.getLabel(i18next.t("organization:Email domains"), i18next.t("organization:Email domains - Tooltip"))} :
+          </Col>
+          <Col span={22} >
+            <EmailDomainTable
+              title={i18next.t("organization:Email domains")}
+              table={this.state.organization.emailDomains}
+              onUpdateTable={(value) => {this.updateOrganizationField("emailDomains", value);}}
+              onVerify={(domain) => this.verifyEmailDomain(domain)}
+            />
+          </Col>
+        </Row>
+        <Row style={{marginTop: "20px"}} >
+          <Col style={{marginTop: "5px"}} span={(Setting.isMobile()) ? 19 : 2}>
+            {Setting.getLabel(i18next.t("organization:Require domain for admin"), i18next.t("organization:Require domain for admin - Tooltip"))} :
+          </Col>
+          <Col span={1} >
+            <Switch checked={this.state.organization.requireDomainForAdmin} onChange={checked => {
+              this.updateOrganizationField("requireDomainForAdmin", checked);
+            }} />
+          </Col>
+        </Row>
+        <Row style={{marginTop: "20px"}} >
+          <Col style={{marginTop: "5px"}} span={(Setting.isMobile()) ? 22 : 2}>
+            {Setting.getLabel(i18next.t("organization:Login alerts"), i18next.t("organization:Login alerts - Tooltip"))} :
+          </Col>
+          <Col span={22} >
+            {
+              [["enableNewDevice", i18next.t("organization:New device")], ["enableImpossibleTravel", i18next.t("organization:Impossible travel")]].map(([key, label]) => (
+                <span key={key} style={{marginRight: "20px"}}>
+                  {label} :&nbsp;
+                  <Switch checked={this.state.organization.loginAlert?.[key]} onChange={checked => {
+                    this.updateOrganizationField("loginAlert", {...this.state.organization.loginAlert, [key]: checked});
+                  }} />
+                </span>
+              ))
+            }
+            {
+              [["maxTravelSpeed", i18next.t("organization:Max travel speed (km/h)"), 1000], ["minTravelDistance", i18next.t("organization:Min travel distance (km)"), 500]].map(([key, label, placeholder]) => (
+                <span key={key} style={{marginRight: "20px"}}>
+                  {label} :&nbsp;
+                  <InputNumber min={0} placeholder={placeholder} value={this.state.organization.loginAlert?.[key]} onChange={value => {
+                    this.updateOrganizationField("loginAlert", {...this.state.organization.loginAlert, [key]: value ?? 0});
+                  }} />
+                </span>
+              ))
+            }
+            {i18next.t("organization:Channels")} :&nbsp;
+            <Select virtual={false} mode="multiple" style={{width: "200px"}} value={this.state.organization.loginAlert?.channels ?? []}
+              onChange={value => {
+                this.updateOrganizationField("loginAlert", {...this.state.organization.loginAlert, channels: value});
+              }}
+              options={[
+                {value: "Email", label: i18next.t("general:Email")},
+                {value: "SMS", label: "SMS"},
+              ]}
+            />
+          </Col>
+        </Row>
+        <Row style={{marginTop: "20px"}} >
+          <Col style={{marginTop: "5px"}} span={(Setting.isMobile()) ? 22 : 2}>
+            {Setting.getLabel(i18next.t("organization:Account recovery"), i18next.t("organization:Account recovery - Tooltip"))} :
+          </Col>
+          <Col span={22} >
+            {i18next.t("organization:Recovery methods")} :&nbsp;
+            <Select virtual={false} mode="multiple" style={{width: "300px", marginRight: "20px"}} value={this.state.organization.accountRecovery?.methods ?? ["Email", "SMS"]}
+              onChange={value => {
+                this.updateOrganizationField("accountRecovery", {...this.state.organization.accountRecovery, methods: value});
+              }}
+              options={[
+                {value: "Email", label: i18next.t("general:Email")},
+                {value: "SMS", label: "SMS"},
+                {value: "Security questions", label: i18next.t("organization:Security questions")},
+              ]}
+            />
+            {
+              [["requiredAnswers", i18next.t("organization:Required answers"), 2], ["linkExpireMinutes", i18next.t("organization:Link expire minutes"), 60], ["maxAttempts", i18next.t("organization:Max attempts"), 5]].map(([key, label, placeholder]) => (
+                <span key={key} style={{marginRight: "20px"}}>
+                  {label} :&nbsp;
+                  <InputNumber min={0} placeholder={placeholder} value={this.state.organization.accountRecovery?.[key]} onChange={value => {
+                    this.updateOrganizationField("accountRecovery", {...this.state.organization.accountRecovery, [key]: value ?? 0});
+                  }} />
+                </span>
+              ))
+            }
+          </Col>
+        </Row>
+        <Row style={{marginTop: "20px"}} >
+          <Col style={{marginTop: "5px"}} span={(Setting.isMobile()) ? 22 : 2}>
+            {Setting.getLabel(i18next.t("organization:Security questions"), i18next.t("organization:Security questions - Tooltip"))} :
+          </Col>
+          <Col span={22} >
+            <Select virtual={false} mode="tags" style={{width: "100%"}} value={this.state.organization.accountRecovery?.securityQuestions ?? []}
+              onChange={value => {
+                this.updateOrganizationField("accountRecovery", {...this.state.organization.accountRecovery, securityQuestions: value});
+              }}
+            />
+          </Col>
+        </Row>
+        <Row style={{marginTop: "20px"}} >
+          <Col style={{marginTop: "5px"}} span={(Setting.isMobile()) ? 22 : 2}>
+            {Setting.getLabel(i18next.t("organization:Contact change hold hours"), i18next.t("organization:Contact change hold hours - Tooltip"))} :
+          </Col>
+          <Col span={4} >
+            <InputNumber min={0} placeholder={24} value={this.state.organization.contactChangeHoldHours} onChange={value => {
+              this.updateOrganizationField("contactChangeHoldHours", value ?? 0);
+            }} />
+          </Col>
+        </Row>
+        <Row style={{marginTop: "20px"}} >
+          <Col style={{marginTop: "5px"}} span={(Setting.isMobile()) ? 22 : 2}>
+            {Setting.getLabel(i18next.t("organization:Error messages"), i18next.t("organization:Error messages - Tooltip"))} :
+          </Col>
+          <Col span={22} >
+            <ErrorMessageTable
+              title={i18next.t("organization:Error messages")}
+              table={this.state.organization.errorMessages}
+              errorCodes={this.state.errorCodes}
+              onUpdateTable={(value) => {this.updateOrganizationField("errorMessages", value);}}
+            />
+          </Col>
+        </Row>
+        <Row style={{marginTop: "20px"}} >
+          <Col style={{marginTop: "5px"}} span={(Setting.isMobile()) ? 22 : 2}>
+            {Setting.getLabel(i18next.t("organization:Routing rules"), i18next.t("organization:Routing rules - Tooltip"))} :
+          </Col>
+          <Col span={22} >
+            <RoutingRuleTable
+              title={i18next.t("organization:Routing rules")}
+              table={this.state.organization.routingRules}
+              tags={this.state.organization.tags}
+              onUpdateTable={(value) => {this.updateOrganizationField("routingRules", value);}}
+            />
+          </Col>
+        </Row>
+        <Row style={{marginTop: "20px"}} >
+          <Col style={{marginTop: "5px"}} span={(Setting.isMobile()) ? 22 : 2}>
+            {Setting.getLabel(i18next.t("organization:SSH CA"), i18next.t("organization:SSH CA - Tooltip"))} :
+          </Col>
+          <Col span={22} >
+            <Select virtual={false} style={{width: "100%"}} allowClear value={this.state.organization.sshCa} onChange={(value => {this.updateOrganizationField("sshCa", value ?? "");})}>
+              {
+                this.state.certs.filter(cert => cert.type === "SSH CA" && cert.organization === this.state.organization.name).map((cert, index) => <Option key={index} value={cert.name}>{cert.name}</Option>)
+              }
+            </Select>
+            {
+              !this.state.organization.sshCa ? null : (
+                <div style={{marginTop: "10px"}}>
+                  <SshCertificateTable owner={this.state.organization.name} />
+                </div>
+              )
+            }
+          </Col>
+        </Row>
+        <Row style={{marginTop: "20px"}} >
+          <Col style={{marginTop: "5px"}} span={(Setting.isMobile()) ? 22 : 2}>
+            {Setting.getLabel(i18next.t("organization:Device CA"), i18next.t("organization:Device CA - Tooltip"))} :
+          </Col>
+          <Col span={22} >
+            <Select virtual={false} style={{width: "100%"}} allowClear value={this.state.organization.deviceCa} onChange={(value => {this.updateOrganizationField("deviceCa", value ?? "");})}>
+              {
+                this.state.certs.filter(cert => cert.type === "Device CA" && cert.organization === this.state.organization.name).map((cert, index) => <Option key={index} value={cert.name}>{cert.name}</Option>)
+              }
+            </Select>
+          </Col>
+        </Row>
+        {
+          this.state.mode === "add" ? null : (
+            <Row style={{marginTop: "20px"}} >
+              <Col style={{marginTop: "5px"}} span={(Setting.isMobile()) ? 22 : 2}>
+                {Setting.getLabel(i18next.t("device:Devices"), i18next.t("device:Devices - Tooltip"))} :
+              </Col>
+              <Col span={22} >
+                <DeviceTable owner={this.state.organization.name} applications={this.state.applications} />
+              </Col>
+            </Row>
+          )
+        }
+        {
+          this.state.shards === null || this.state.shards.length < 2 ? null : (
+            <Row style={{marginTop: "20px"}} >
+              <Col style={{marginTop: "5px"}} span={(Setting.isMobile()) ? 22 : 2}>
+                {Setting.getLabel(i18next.t("organization:Shard"), i18next.t("organization:Shard - Tooltip"))} :
+              </Col>
+              <Col span={22} >
+                <Select virtual={false} style={{width: "300px"}} value={this.state.targetShard ?? (this.state.organization.shard ?? "")} onChange={(value => {this.setState({targetShard: value});})}
+                  disabled={this.state.mode === "add"}
+                  options={this.state.shards.map(shard => Setting.getOption(this.getShardLabel(shard), shard.name))} />
+                <Popconfirm
+                  title={i18next.t("organization:Move the users of the organization to the shard?")}
+                  onConfirm={() => this.moveOrganizationShard()}
+                  disabled={this.state.targetShard === null || this.state.targetShard === (this.state.organization.shard ?? "")}
+                >
+                  <Button style={{marginLeft: "10px"}} disabled={this.state.targetShard === null || this.state.targetShard === (this.state.organization.shard ?? "")}>
+                    {i18next.t("organization:Move")}
+                  </Button>
+                </Popconfirm>
+              </Col>
+            </Row>
+          )
+        }
         <Row style={{marginTop: "20px"}} >
           <Col style={{marginTop: "5px"}} span={(Setting.isMobile()) ? 22 : 2}>
             {Setting.getLabel(i18next.t("organization:Account items"), i18next.t("organization:Account items - Tooltip"))} :
@@ -341,6 +726,16 @@ class OrganizationEditPage extends React.Component {
             }
           </Col>
         </Row>
+        <Row style={{marginTop: "20px"}} >
+          <Col style={{marginTop: "5px"}} span={(Setting.isMobile()) ? 22 : 2}>
+            {Setting.getLabel(i18next.t("organization:Branding"), i18next.t("organization:Branding - Tooltip"))} :
+          </Col>
+          <Col span={22} >
+            <BrandingEditor branding={this.state.organization.branding} onChange={(branding) => {
+              this.updateOrganizationField("branding", branding);
+            }} />
+          </Col>
+        </Row>
         <Row style={{marginTop: "20px"}}>
           <Col style={{marginTop: "5px"}} span={(Setting.isMobile()) ? 22 : 2}>
             {Setting.getLabel(i18next.t("general:LDAPs"), i18next.t("general:LDAPs - Tooltip"))} :
@@ -390,6 +785,36 @@ class OrganizationEditPage extends React.Component {
       });
   }
 
+  verifyOrganizationDomain() {
+    OrganizationBackend.verifyOrganizationDomain(this.state.organization.owner, this.state.organizationName)
+      .then((res) => {
+        if (res.status === "ok") {
+          Setting.showMessage("success", i18next.t("organization:Domain verified"));
+          this.getOrganization();
+        } else {
+          Setting.showMessage("error", `${i18next.t("organization:Failed to verify domain")}: ${res.msg}`);
+        }
+      })
+      .catch(error => {
+        Setting.showMessage("error", `${i18next.t("general:Failed to connect to server")}: ${error}`);
+      });
+  }
+
+  verifyEmailDomain(domain) {
+    OrganizationBackend.verifyEmailDomain(this.state.organization.owner, this.state.organizationName, domain)
+      .then((res) => {
+        if (res.status === "ok") {
+          Setting.showMessage("success", i18next.t("organization:Domain verified"));
+          this.getOrganization();
+        } else {
+          Setting.showMessage("error", `${i18next.t("organization:Failed to verify domain")}: ${res.msg}`);
+        }
+      })
+      .catch(error => {
+        Setting.showMessage("error", `${i18next.t("general:Failed to connect to server")}: ${error}`);
+      });
+  }
+
   deleteOrganization() {
     OrganizationBackend.deleteOrganization(this.state.organization)
       .then((res) => {
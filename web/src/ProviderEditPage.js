@@ -20,6 +20,7 @@ import * as Setting from "./Setting";
 import i18next from "i18next";
 import {authConfig} from "./auth/Auth";
 import * as ProviderEditTestEmail from "./common/TestEmailWidget";
+import EmailSuppressionTable from "./table/EmailSuppressionTable";
 import * as ProviderEditTestSms from "./common/TestSmsWidget";
 import copy from "copy-to-clipboard";
 import {CaptchaPreview} from "./common/CaptchaPreview";
@@ -87,7 +88,11 @@ class ProviderEditPage extends React.Component {
   getClientIdLabel(provider) {
     switch (provider.category) {
     case "Email":
-      return Setting.getLabel(i18next.t("signup:Username"), i18next.t("signup:Username - Tooltip"));
+      if (provider.type === "AWS SES") {
+        return Setting.getLabel(i18next.t("provider:Access key"), i18next.t("provider:Access key - Tooltip"));
+      } else {
+        return Setting.getLabel(i18next.t("signup:Username"), i18next.t("signup:Username - Tooltip"));
+      }
     case "SMS":
       if (provider.type === "Volc Engine SMS") {
         return Setting.getLabel(i18next.t("provider:Access key"), i18next.t("provider:Access key - Tooltip"));
@@ -102,6 +107,8 @@ class ProviderEditPage extends React.Component {
       } else {
         return Setting.getLabel(i18next.t("provider:Site key"), i18next.t("provider:Site key - Tooltip"));
       }
+    case "Identity Verification":
+      return Setting.getLabel(i18next.t("provider:API key"), i18next.t("provider:API key - Tooltip"));
     default:
       return Setting.getLabel(i18next.t("provider:Client ID"), i18next.t("provider:Client ID - Tooltip"));
     }
@@ -110,7 +117,13 @@ class ProviderEditPage extends React.Component {
   getClientSecretLabel(provider) {
     switch (provider.category) {
     case "Email":
-      return Setting.getLabel(i18next.t("general:Password"), i18next.t("general:Password - Tooltip"));
+      if (provider.type === "SendGrid" || provider.type === "Mailgun") {
+        return Setting.getLabel(i18next.t("provider:API key"), i18next.t("provider:API key - Tooltip"));
+      } else if (provider.type === "AWS SES") {
+        return Setting.getLabel(i18next.t("provider:Secret access key"), i18next.t("provider:Secret access key - Tooltip"));
+      } else {
+        return Setting.getLabel(i18next.t("general:Password"), i18next.t("general:Password - Tooltip"));
+      }
     case "SMS":
       if (provider.type === "Volc Engine SMS") {
         return Setting.getLabel(i18next.t("provider:Secret access key"), i18next.t("provider:Secret access key - Tooltip"));
@@ -125,11 +138,42 @@ class ProviderEditPage extends React.Component {
       } else {
         return Setting.getLabel(i18next.t("provider:Secret key"), i18next.t("provider:Secret key - Tooltip"));
       }
+    case "Identity Verification":
+      return Setting.getLabel(i18next.t("provider:Shared secret"), i18next.t("provider:Shared secret - Tooltip"));
+    case "Payment":
+      if (provider.type === "Stripe") {
+        return Setting.getLabel(i18next.t("provider:Secret key"), i18next.t("provider:Secret key - Tooltip"));
+      } else {
+        return Setting.getLabel(i18next.t("provider:Client secret"), i18next.t("provider:Client secret - Tooltip"));
+      }
     default:
       return Setting.getLabel(i18next.t("provider:Client secret"), i18next.t("provider:Client secret - Tooltip"));
     }
   }
 
+  isApiEmailProvider(provider) {
+    return provider.category === "Email" && ["SendGrid", "Mailgun", "AWS SES"].includes(provider.type);
+  }
+
+  // getEmailDnsRecords lists the DNS records the sending domain needs, the
+  // DKIM key itself is given by the email provider
+  getEmailDnsRecords(provider) {
+    const fromAddress = provider.fromAddress ? provider.fromAddress : provider.clientId;
+    const domain = provider.domain ? provider.domain : (fromAddress ?? "").split("@")[1];
+    if (!domain) {
+      return "";
+    }
+
+    const records = [];
+    const spf = {"SendGrid": "include:sendgrid.net", "Mailgun": "include:mailgun.org", "AWS SES": "include:amazonses.com"}[provider.type];
+    if (spf) {
+      records.push(`${domain} TXT "v=spf1 ${spf} ~all"`);
+    }
+    records.push(`${provider.dkimSelector ? provider.dkimSelector : "<selector>"}._domainkey.${domain} TXT/CNAME <${i18next.t("provider:The DKIM key given by the email provider")}>`);
+    records.push(`_dmarc.${domain} TXT "v=DMARC1; p=none; rua=mailto:postmaster@${domain}"`);
+    return records.join("\n");
+  }
+
   getProviderSubTypeOptions(type) {
     if (type === "WeCom" || type === "Infoflow") {
       return (
@@ -278,6 +322,8 @@ class ProviderEditPage extends React.Component {
                 this.updateProviderField("type", "Alipay");
               } else if (value === "Captcha") {
                 this.updateProviderField("type", "Default");
+              } else if (value === "Identity Verification") {
+                this.updateProviderField("type", "Veriff");
               }
             })}>
               {
@@ -289,6 +335,7 @@ class ProviderEditPage extends React.Component {
                   {id: "SAML", name: "SAML"},
                   {id: "Payment", name: "Payment"},
                   {id: "Captcha", name: "Captcha"},
+                  {id: "Identity Verification", name: "Identity Verification"},
                 ]
                   .sort((a, b) => a.name.localeCompare(b.name))
                   .map((providerCategory, index) => <Option key={index} value={providerCategory.id}>{providerCategory.name}</Option>)
@@ -437,16 +484,20 @@ class ProviderEditPage extends React.Component {
         {
           this.state.provider.category === "Captcha" && this.state.provider.type === "Default" ? null : (
             <React.Fragment>
-              <Row style={{marginTop: "20px"}} >
-                <Col style={{marginTop: "5px"}} span={(Setting.isMobile()) ? 22 : 2}>
-                  {this.getClientIdLabel(this.state.provider)}
-                </Col>
-                <Col span={22} >
-                  <Input value={this.state.provider.clientId} onChange={e => {
-                    this.updateProviderField("clientId", e.target.value);
-                  }} />
-                </Col>
-              </Row>
+              {
+                this.state.provider.type === "SendGrid" || this.state.provider.type === "Mailgun" ? null : (
+                  <Row style={{marginTop: "20px"}} >
+                    <Col style={{marginTop: "5px"}} span={(Setting.isMobile()) ? 22 : 2}>
+                      {this.getClientIdLabel(this.state.provider)}
+                    </Col>
+                    <Col span={22} >
+                      <Input value={this.state.provider.clientId} onChange={e => {
+                        this.updateProviderField("clientId", e.target.value);
+                      }} />
+                    </Col>
+                  </Row>
+                )
+              }
               <Row style={{marginTop: "20px"}} >
                 <Col style={{marginTop: "5px"}} span={(Setting.isMobile()) ? 22 : 2}>
                   {this.getClientSecretLabel(this.state.provider)}
@@ -490,6 +541,22 @@ class ProviderEditPage extends React.Component {
             </React.Fragment>
           )
         }
+        {
+          this.state.provider.category === "Payment" && (this.state.provider.type === "Stripe" || this.state.provider.type === "PayPal") ? (
+            <Row style={{marginTop: "20px"}} >
+              <Col style={{marginTop: "5px"}} span={(Setting.isMobile()) ? 22 : 2}>
+                {this.state.provider.type === "Stripe"
+                  ? Setting.getLabel(i18next.t("provider:Webhook secret"), i18next.t("provider:Webhook secret - Tooltip"))
+                  : Setting.getLabel(i18next.t("provider:Webhook ID"), i18next.t("provider:Webhook ID - Tooltip"))} :
+              </Col>
+              <Col span={22} >
+                <Input value={this.state.provider.clientSecret2} onChange={e => {
+                  this.updateProviderField("clientSecret2", e.target.value);
+                }} />
+              </Col>
+            </Row>
+          ) : null
+        }
         {
           this.state.provider.type !== "WeChat" ? null : (
             <Row style={{marginTop: "20px"}} >
@@ -587,36 +654,135 @@ class ProviderEditPage extends React.Component {
         {
           this.state.provider.category === "Email" ? (
             <React.Fragment>
+              {
+                this.state.provider.type === "AWS SES" ? (
+                  <Row style={{marginTop: "20px"}} >
+                    <Col style={{marginTop: "5px"}} span={(Setting.isMobile()) ? 22 : 2}>
+                      {Setting.getLabel(i18next.t("provider:Region ID"), i18next.t("provider:Region ID - Tooltip"))} :
+                    </Col>
+                    <Col span={22} >
+                      <Input value={this.state.provider.regionId} onChange={e => {
+                        this.updateProviderField("regionId", e.target.value);
+                      }} />
+                    </Col>
+                  </Row>
+                ) : (
+                  <Row style={{marginTop: "20px"}} >
+                    <Col style={{marginTop: "5px"}} span={(Setting.isMobile()) ? 22 : 2}>
+                      {this.isApiEmailProvider(this.state.provider)
+                        ? Setting.getLabel(i18next.t("provider:Host"), i18next.t("provider:API host - Tooltip"))
+                        : Setting.getLabel(i18next.t("provider:Host"), i18next.t("provider:Host - Tooltip"))} :
+                    </Col>
+                    <Col span={22} >
+                      <Input prefix={<LinkOutlined />} value={this.state.provider.host} onChange={e => {
+                        this.updateProviderField("host", e.target.value);
+                      }} />
+                    </Col>
+                  </Row>
+                )
+              }
+              {
+                this.isApiEmailProvider(this.state.provider) ? null : (
+                  <React.Fragment>
+                    <Row style={{marginTop: "20px"}} >
+                      <Col style={{marginTop: "5px"}} span={(Setting.isMobile()) ? 22 : 2}>
+                        {Setting.getLabel(i18next.t("provider:Port"), i18next.t("provider:Port - Tooltip"))} :
+                      </Col>
+                      <Col span={22} >
+                        <InputNumber value={this.state.provider.port} onChange={value => {
+                          this.updateProviderField("port", value);
+                        }} />
+                      </Col>
+                    </Row>
+                    <Row style={{marginTop: "20px"}} >
+                      <Col style={{marginTop: "5px"}} span={(Setting.isMobile()) ? 22 : 2}>
+                        {Setting.getLabel(i18next.t("provider:Disable SSL"), i18next.t("provider:Disable SSL - Tooltip"))} :
+                      </Col>
+                      <Col span={1} >
+                        <Switch checked={this.state.provider.disableSsl} onChange={checked => {
+                          this.updateProviderField("disableSsl", checked);
+                        }} />
+                      </Col>
+                    </Row>
+                  </React.Fragment>
+                )
+              }
               <Row style={{marginTop: "20px"}} >
                 <Col style={{marginTop: "5px"}} span={(Setting.isMobile()) ? 22 : 2}>
-                  {Setting.getLabel(i18next.t("provider:Host"), i18next.t("provider:Host - Tooltip"))} :
+                  {Setting.getLabel(i18next.t("provider:From address"), i18next.t("provider:From address - Tooltip"))} :
                 </Col>
                 <Col span={22} >
-                  <Input prefix={<LinkOutlined />} value={this.state.provider.host} onChange={e => {
-                    this.updateProviderField("host", e.target.value);
+                  <Input value={this.state.provider.fromAddress} onChange={e => {
+                    this.updateProviderField("fromAddress", e.target.value);
                   }} />
                 </Col>
               </Row>
               <Row style={{marginTop: "20px"}} >
                 <Col style={{marginTop: "5px"}} span={(Setting.isMobile()) ? 22 : 2}>
-                  {Setting.getLabel(i18next.t("provider:Port"), i18next.t("provider:Port - Tooltip"))} :
+                  {Setting.getLabel(i18next.t("provider:Sending domain"), i18next.t("provider:Sending domain - Tooltip"))} :
                 </Col>
                 <Col span={22} >
-                  <InputNumber value={this.state.provider.port} onChange={value => {
-                    this.updateProviderField("port", value);
+                  <Input value={this.state.provider.domain} onChange={e => {
+                    this.updateProviderField("domain", e.target.value);
                   }} />
                 </Col>
               </Row>
               <Row style={{marginTop: "20px"}} >
                 <Col style={{marginTop: "5px"}} span={(Setting.isMobile()) ? 22 : 2}>
-                  {Setting.getLabel(i18next.t("provider:Disable SSL"), i18next.t("provider:Disable SSL - Tooltip"))} :
+                  {Setting.getLabel(i18next.t("provider:DKIM selector"), i18next.t("provider:DKIM selector - Tooltip"))} :
                 </Col>
-                <Col span={1} >
-                  <Switch checked={this.state.provider.disableSsl} onChange={checked => {
-                    this.updateProviderField("disableSsl", checked);
+                <Col span={22} >
+                  <Input value={this.state.provider.dkimSelector} onChange={e => {
+                    this.updateProviderField("dkimSelector", e.target.value);
                   }} />
                 </Col>
               </Row>
+              <Row style={{marginTop: "20px"}} >
+                <Col style={{marginTop: "5px"}} span={(Setting.isMobile()) ? 22 : 2}>
+                  {Setting.getLabel(i18next.t("provider:DNS records"), i18next.t("provider:DNS records - Tooltip"))} :
+                </Col>
+                <Col span={22} >
+                  <TextArea autoSize={{minRows: 1, maxRows: 10}} value={this.getEmailDnsRecords(this.state.provider)} readOnly="readonly" />
+                </Col>
+              </Row>
+              {
+                this.isApiEmailProvider(this.state.provider) ? (
+                  <Row style={{marginTop: "20px"}} >
+                    <Col style={{marginTop: "5px"}} span={(Setting.isMobile()) ? 22 : 2}>
+                      {this.state.provider.type === "SendGrid"
+                        ? Setting.getLabel(i18next.t("provider:Verification key"), i18next.t("provider:Verification key - Tooltip"))
+                        : this.state.provider.type === "Mailgun"
+                          ? Setting.getLabel(i18next.t("provider:Webhook signing key"), i18next.t("provider:Webhook signing key - Tooltip"))
+                          : Setting.getLabel(i18next.t("provider:Topic ARN"), i18next.t("provider:Topic ARN - Tooltip"))} :
+                    </Col>
+                    <Col span={22} >
+                      <Input value={this.state.provider.clientSecret2} onChange={e => {
+                        this.updateProviderField("clientSecret2", e.target.value);
+                      }} />
+                    </Col>
+                  </Row>
+                ) : null
+              }
+              {
+                this.isApiEmailProvider(this.state.provider) ? (
+                  <Row style={{marginTop: "20px"}} >
+                    <Col style={{marginTop: "5px"}} span={(Setting.isMobile()) ? 22 : 2}>
+                      {Setting.getLabel(i18next.t("provider:Webhook URL"), i18next.t("provider:Email webhook URL - Tooltip"))} :
+                    </Col>
+                    <Col span={21} >
+                      <Input value={`${authConfig.serverUrl}/api/email-webhook/${this.state.provider.owner}/${this.state.provider.name}`} readOnly="readonly" />
+                    </Col>
+                    <Col span={1}>
+                      <Button type="primary" onClick={() => {
+                        copy(`${authConfig.serverUrl}/api/email-webhook/${this.state.provider.owner}/${this.state.provider.name}`);
+                        Setting.showMessage("success", i18next.t("provider:Link copied to clipboard successfully"));
+                      }}>
+                        {i18next.t("provider:Copy")}
+                      </Button>
+                    </Col>
+                  </Row>
+                ) : null
+              }
               <Row style={{marginTop: "20px"}} >
                 <Col style={{marginTop: "5px"}} span={(Setting.isMobile()) ? 22 : 2}>
                   {Setting.getLabel(i18next.t("provider:Email title"), i18next.t("provider:Email title - Tooltip"))} :
@@ -646,15 +812,27 @@ class ProviderEditPage extends React.Component {
                     this.updateProviderField("receiver", e.target.value);
                   }} />
                 </Col>
-                <Button style={{marginLeft: "10px", marginBottom: "5px"}} type="primary" onClick={() => ProviderEditTestEmail.connectSmtpServer(this.state.provider)} >
-                  {i18next.t("provider:Test SMTP Connection")}
-                </Button>
+                {
+                  this.isApiEmailProvider(this.state.provider) ? null : (
+                    <Button style={{marginLeft: "10px", marginBottom: "5px"}} type="primary" onClick={() => ProviderEditTestEmail.connectSmtpServer(this.state.provider)} >
+                      {i18next.t("provider:Test SMTP Connection")}
+                    </Button>
+                  )
+                }
                 <Button style={{marginLeft: "10px", marginBottom: "5px"}} type="primary"
                   disabled={!Setting.isValidEmail(this.state.provider.receiver)}
                   onClick={() => ProviderEditTestEmail.sendTestEmail(this.state.provider, this.state.provider.receiver)} >
                   {i18next.t("provider:Send Testing Email")}
                 </Button>
               </Row>
+              <Row style={{marginTop: "20px"}} >
+                <Col style={{marginTop: "5px"}} span={(Setting.isMobile()) ? 22 : 2}>
+                  {Setting.getLabel(i18next.t("provider:Suppression list"), i18next.t("provider:Suppression list - Tooltip"))} :
+                </Col>
+                <Col span={22} >
+                  <EmailSuppressionTable owner={this.state.provider.owner} provider={this.state.provider.name} />
+                </Col>
+              </Row>
             </React.Fragment>
           ) : this.state.provider.category === "SMS" ? (
             <React.Fragment>
@@ -815,6 +993,38 @@ class ProviderEditPage extends React.Component {
             </React.Fragment>
           ) : null
         }
+        {
+          this.state.provider.category === "Identity Verification" ? (
+            <React.Fragment>
+              <Row style={{marginTop: "20px"}} >
+                <Col style={{marginTop: "5px"}} span={(Setting.isMobile()) ? 22 : 2}>
+                  {Setting.getLabel(i18next.t("provider:Endpoint"), i18next.t("provider:Identity verification endpoint - Tooltip"))} :
+                </Col>
+                <Col span={22} >
+                  <Input value={this.state.provider.endpoint} onChange={e => {
+                    this.updateProviderField("endpoint", e.target.value);
+                  }} />
+                </Col>
+              </Row>
+              <Row style={{marginTop: "20px"}} >
+                <Col style={{marginTop: "5px"}} span={(Setting.isMobile()) ? 22 : 2}>
+                  {Setting.getLabel(i18next.t("provider:Webhook URL"), i18next.t("provider:Identity verification webhook URL - Tooltip"))} :
+                </Col>
+                <Col span={21} >
+                  <Input value={`${authConfig.serverUrl}/api/identity-verification-webhook/${this.state.provider.owner}/${this.state.provider.name}`} readOnly="readonly" />
+                </Col>
+                <Col span={1}>
+                  <Button type="primary" onClick={() => {
+                    copy(`${authConfig.serverUrl}/api/identity-verification-webhook/${this.state.provider.owner}/${this.state.provider.name}`);
+                    Setting.showMessage("success", i18next.t("provider:Link copied to clipboard successfully"));
+                  }}>
+                    {i18next.t("provider:Copy")}
+                  </Button>
+                </Col>
+              </Row>
+            </React.Fragment>
+          ) : null
+        }
         {this.getAppIdRow(this.state.provider)}
         <Row style={{marginTop: "20px"}} >
           <Col style={{marginTop: "5px"}} span={(Setting.isMobile()) ? 22 : 2}>
@@ -14,7 +14,7 @@
 
 import React from "react";
 import {Link} from "react-router-dom";
-import {Switch, Table} from "antd";
+import {Button, Switch, Table} from "antd";
 import * as Setting from "./Setting";
 import * as RecordBackend from "./backend/RecordBackend";
 import i18next from "i18next";
@@ -192,6 +192,7 @@ class RecordListPage extends BaseListPage {
           title={() => (
             <div>
               {i18next.t("general:Records")}&nbsp;&nbsp;&nbsp;&nbsp;
+              <Button size="small" onClick={() => Setting.openLink(`${Setting.ServerUrl}/api/export-records?format=csv`)}>{i18next.t("record:Export (.csv)")}</Button>
             </div>
           )}
           loading={this.state.loading}
@@ -19,6 +19,7 @@ import * as OrganizationBackend from "./backend/OrganizationBackend";
 import * as Setting from "./Setting";
 import i18next from "i18next";
 import * as UserBackend from "./backend/UserBackend";
+import RoleAssignmentTable from "./table/RoleAssignmentTable";
 
 class RoleEditPage extends React.Component {
   constructor(props) {
@@ -31,6 +32,7 @@ class RoleEditPage extends React.Component {
       organizations: [],
       users: [],
       roles: [],
+      capabilities: [],
       mode: props.location.mode !== undefined ? props.location.mode : "edit",
     };
   }
@@ -38,6 +40,7 @@ class RoleEditPage extends React.Component {
   UNSAFE_componentWillMount() {
     this.getRole();
     this.getOrganizations();
+    this.getCapabilities();
   }
 
   getRole() {
@@ -70,6 +73,17 @@ class RoleEditPage extends React.Component {
       });
   }
 
+  getCapabilities() {
+    RoleBackend.getCapabilities()
+      .then((res) => {
+        if (res.status === "ok") {
+          this.setState({
+            capabilities: res.data,
+          });
+        }
+      });
+  }
+
   getRoles(organizationName) {
     RoleBackend.getRoles(organizationName)
       .then((res) => {
@@ -147,6 +161,41 @@ class RoleEditPage extends React.Component {
             />
           </Col>
         </Row>
+        <Row style={{marginTop: "20px"}} >
+          <Col style={{marginTop: "5px"}} span={(Setting.isMobile()) ? 22 : 2}>
+            {Setting.getLabel(i18next.t("role:Assignments"), i18next.t("role:Assignments - Tooltip"))} :
+          </Col>
+          <Col span={22} >
+            <RoleAssignmentTable
+              title={i18next.t("role:Assignments")}
+              table={this.state.role.assignments}
+              users={this.state.users}
+              onUpdateTable={(value) => {this.updateRoleField("assignments", value);}}
+            />
+          </Col>
+        </Row>
+        <Row style={{marginTop: "20px"}} >
+          <Col style={{marginTop: "5px"}} span={(Setting.isMobile()) ? 22 : 2}>
+            {Setting.getLabel(i18next.t("role:Access request approvers"), i18next.t("role:Access request approvers - Tooltip"))} :
+          </Col>
+          <Col span={22} >
+            <Select virtual={false} mode="multiple" style={{width: "100%"}} value={this.state.role.approvers ?? []}
+              onChange={(value => {this.updateRoleField("approvers", value);})}
+              options={this.state.users.map((user) => Setting.getOption(`${user.owner}/${user.name}`, `${user.owner}/${user.name}`))}
+            />
+          </Col>
+        </Row>
+        <Row style={{marginTop: "20px"}} >
+          <Col style={{marginTop: "5px"}} span={(Setting.isMobile()) ? 22 : 2}>
+            {Setting.getLabel(i18next.t("role:Capabilities"), i18next.t("role:Capabilities - Tooltip"))} :
+          </Col>
+          <Col span={22} >
+            <Select virtual={false} mode="tags" style={{width: "100%"}} value={this.state.role.capabilities ?? []}
+              onChange={(value => {this.updateRoleField("capabilities", value);})}
+              options={this.state.capabilities.map((capability) => Setting.getOption(capability, capability))}
+            />
+          </Col>
+        </Row>
         <Row style={{marginTop: "20px"}} >
           <Col style={{marginTop: "5px"}} span={(Setting.isMobile()) ? 22 : 2}>
             {Setting.getLabel(i18next.t("role:Sub roles"), i18next.t("role:Sub roles - Tooltip"))} :
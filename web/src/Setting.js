@@ -124,6 +124,18 @@ export const OtherProviderInfo = {
       logo: `${StaticBaseUrl}/img/email_mailtrap.png`,
       url: "https://mailtrap.io",
     },
+    "SendGrid": {
+      logo: `${StaticBaseUrl}/img/email_default.png`,
+      url: "https://sendgrid.com",
+    },
+    "Mailgun": {
+      logo: `${StaticBaseUrl}/img/email_default.png`,
+      url: "https://www.mailgun.com",
+    },
+    "AWS SES": {
+      logo: `${StaticBaseUrl}/img/social_aws.png`,
+      url: "https://aws.amazon.com/ses",
+    },
   },
   Storage: {
     "Local File System": {
@@ -174,11 +186,25 @@ export const OtherProviderInfo = {
       logo: `${StaticBaseUrl}/img/payment_paypal.png`,
       url: "https://www.paypal.com/",
     },
+    "Stripe": {
+      logo: `${StaticBaseUrl}/img/payment_stripe.png`,
+      url: "https://stripe.com/",
+    },
     "GC": {
       logo: `${StaticBaseUrl}/img/payment_gc.png`,
       url: "https://gc.org",
     },
   },
+  "Identity Verification": {
+    "Veriff": {
+      logo: `${StaticBaseUrl}/img/social_default.png`,
+      url: "https://www.veriff.com/",
+    },
+    "Custom HTTP": {
+      logo: `${StaticBaseUrl}/img/social_default.png`,
+      url: "https://casdoor.org/docs/provider/overview",
+    },
+  },
   Captcha: {
     "Default": {
       logo: `${StaticBaseUrl}/img/captcha_default.png`,
@@ -657,6 +683,29 @@ export function getAvatarColor(s) {
   return colorList[hash % 4];
 }
 
+export function getSecurityEventTypeText(type) {
+  switch (type) {
+  case "login":
+    return i18next.t("security:Sign-in");
+  case "signup":
+    return i18next.t("security:Sign-up");
+  case "password-change":
+    return i18next.t("security:Password change");
+  case "mfa-change":
+    return i18next.t("security:MFA change");
+  case "contact-change":
+    return i18next.t("security:Email or phone change");
+  case "app-authorization":
+    return i18next.t("security:New application authorization");
+  case "new-device":
+    return i18next.t("security:New device");
+  case "impossible-travel":
+    return i18next.t("security:Impossible travel");
+  default:
+    return type;
+  }
+}
+
 export function getLanguageText(text) {
   if (!text.includes("|")) {
     return text;
@@ -681,6 +730,17 @@ export function setLanguage(language) {
   i18next.changeLanguage(language);
 }
 
+// applyDefaultLanguage switches to the default language of an organization
+// unless the user has chosen a language or the language is forced.
+export function applyDefaultLanguage(language) {
+  if (!language || Conf.ForceLanguage !== "" || localStorage.getItem("language")) {
+    return;
+  }
+  if (i18next.language !== language) {
+    i18next.changeLanguage(language);
+  }
+}
+
 export function getAcceptLanguage() {
   if (i18next.language === null || i18next.language === "") {
     return "en;q=0.9,en;q=0.8";
@@ -810,6 +870,9 @@ export function getProviderTypeOptions(category) {
         {id: "Default", name: "Default"},
         {id: "SUBMAIL", name: "SUBMAIL"},
         {id: "Mailtrap", name: "Mailtrap"},
+        {id: "SendGrid", name: "SendGrid"},
+        {id: "Mailgun", name: "Mailgun"},
+        {id: "AWS SES", name: "AWS SES"},
       ]
     );
   } else if (category === "SMS") {
@@ -845,8 +908,14 @@ export function getProviderTypeOptions(category) {
       {id: "Alipay", name: "Alipay"},
       {id: "WeChat Pay", name: "WeChat Pay"},
       {id: "PayPal", name: "PayPal"},
+      {id: "Stripe", name: "Stripe"},
       {id: "GC", name: "GC"},
     ]);
+  } else if (category === "Identity Verification") {
+    return ([
+      {id: "Veriff", name: "Veriff"},
+      {id: "Custom HTTP", name: "Custom HTTP"},
+    ]);
   } else if (category === "Captcha") {
     return ([
       {id: "Default", name: "Default"},
@@ -861,20 +930,22 @@ export function getProviderTypeOptions(category) {
   }
 }
 
-export function renderLogo(application) {
+export function renderLogo(application, branding) {
   if (application === null) {
     return null;
   }
 
+  const logo = branding?.logo ? branding.logo : application.logo;
+
   if (application.homepageUrl !== "") {
     return (
       <a target="_blank" rel="noreferrer" href={application.homepageUrl}>
-        <img className="panel-logo" width={250} src={application.logo} alt={application.displayName} />
+        <img className="panel-logo" width={250} src={logo} alt={application.displayName} />
       </a>
     );
   } else {
     return (
-      <img className="panel-logo" width={250} src={application.logo} alt={application.displayName} />
+      <img className="panel-logo" width={250} src={logo} alt={application.displayName} />
     );
   }
 }
@@ -1110,10 +1181,10 @@ export function getRandomNumber() {
   return Math.random().toString(10).slice(-11);
 }
 
-export function getFromLink() {
+export function getFromLink(defaultLink = "/") {
   const from = sessionStorage.getItem("from");
   if (from === null) {
-    return "/";
+    return defaultLink;
   }
   return from;
 }
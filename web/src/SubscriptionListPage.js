@@ -0,0 +1,299 @@
+// Copyright 2023 The Casdoor Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+import React from "react";
+import {Link} from "react-router-dom";
+import {Button, Modal, Popconfirm, Select, Table} from "antd";
+import * as Setting from "./Setting";
+import * as PlanBackend from "./backend/PlanBackend";
+import * as SubscriptionBackend from "./backend/SubscriptionBackend";
+import i18next from "i18next";
+import BaseListPage from "./BaseListPage";
+import PopconfirmModal from "./PopconfirmModal";
+
+const {Option} = Select;
+
+class SubscriptionListPage extends BaseListPage {
+  componentDidMount() {
+    PlanBackend.getPlans("admin")
+      .then((res) => {
+        this.setState({
+          plans: res.filter(plan => plan.isEnabled),
+        });
+      });
+  }
+
+  deleteSubscription(i) {
+    SubscriptionBackend.deleteSubscription(this.state.data[i])
+      .then((res) => {
+        if (res.status === "ok") {
+          Setting.showMessage("success", i18next.t("general:Successfully deleted"));
+          this.setState({
+            data: Setting.deleteRow(this.state.data, i),
+            pagination: {total: this.state.pagination.total - 1},
+          });
+        } else {
+          Setting.showMessage("error", `${i18next.t("general:Failed to delete")}: ${res.msg}`);
+        }
+      })
+      .catch(error => {
+        Setting.showMessage("error", `${i18next.t("general:Failed to connect to server")}: ${error}`);
+      });
+  }
+
+  cancelSubscription(subscription) {
+    SubscriptionBackend.cancelSubscription(subscription.owner, subscription.name)
+      .then((res) => {
+        if (res.status === "ok") {
+          Setting.showMessage("success", i18next.t("subscription:Successfully canceled"));
+          this.fetch({pagination: this.state.pagination});
+        } else {
+          Setting.showMessage("error", `${i18next.t("subscription:Failed to cancel")}: ${res.msg}`);
+        }
+      })
+      .catch(error => {
+        Setting.showMessage("error", `${i18next.t("general:Failed to connect to server")}: ${error}`);
+      });
+  }
+
+  selectPlan(subscription, planId) {
+    SubscriptionBackend.getSubscriptionProration(subscription.owner, subscription.name, planId)
+      .then((res) => {
+        if (res.status !== "ok") {
+          Setting.showMessage("error", res.msg);
+          return;
+        }
+
+        const proration = res.data;
+        Modal.confirm({
+          title: `${i18next.t("subscription:Change plan")}: ${planId}`,
+          content: `${i18next.t("subscription:Credit")}: ${proration.credit} ${proration.currency}, ${i18next.t("subscription:Charge")}: ${proration.charge} ${proration.currency}, ${i18next.t("subscription:Amount due")}: ${proration.amount} ${proration.currency}`,
+          onOk: () => this.changeSubscriptionPlan(subscription, planId),
+        });
+      });
+  }
+
+  changeSubscriptionPlan(subscription, planId) {
+    SubscriptionBackend.changeSubscriptionPlan(subscription.owner, subscription.name, planId)
+      .then((res) => {
+        if (res.status === "ok") {
+          if (res.data !== "") {
+            Setting.goToLink(res.data);
+            return;
+          }
+          Setting.showMessage("success", i18next.t("general:Successfully saved"));
+          this.fetch({pagination: this.state.pagination});
+        } else {
+          Setting.showMessage("error", `${i18next.t("general:Failed to save")}: ${res.msg}`);
+        }
+      })
+      .catch(error => {
+        Setting.showMessage("error", `${i18next.t("general:Failed to connect to server")}: ${error}`);
+      });
+  }
+
+  renderTable(subscriptions) {
+    const columns = [
+      {
+        title: i18next.t("general:Name"),
+        dataIndex: "name",
+        key: "name",
+        width: "160px",
+        fixed: "left",
+        sorter: true,
+        ...this.getColumnSearchProps("name"),
+      },
+      {
+        title: i18next.t("general:Created time"),
+        dataIndex: "createdTime",
+        key: "createdTime",
+        width: "160px",
+        sorter: true,
+        render: (text, record, index) => {
+          return Setting.getFormattedDate(text);
+        },
+      },
+      {
+        title: i18next.t("general:User"),
+        dataIndex: "user",
+        key: "user",
+        width: "140px",
+        sorter: true,
+        ...this.getColumnSearchProps("user"),
+        render: (text, record, index) => {
+          return (
+            <Link to={`/users/${record.organization}/${text}`}>
+              {text}
+            </Link>
+          );
+        },
+      },
+      {
+        title: i18next.t("subscription:Plan"),
+        dataIndex: "plan",
+        key: "plan",
+        width: "140px",
+        sorter: true,
+        ...this.getColumnSearchProps("plan"),
+        render: (text, record, index) => {
+          return (
+            <Link to={`/plans/${text}`}>
+              {text}
+            </Link>
+          );
+        },
+      },
+      {
+        title: i18next.t("general:State"),
+        dataIndex: "state",
+        key: "state",
+        width: "120px",
+        sorter: true,
+        ...this.getColumnSearchProps("state"),
+      },
+      {
+        title: i18next.t("role:Start time"),
+        dataIndex: "startTime",
+        key: "startTime",
+        width: "160px",
+        sorter: true,
+        render: (text, record, index) => {
+          return Setting.getFormattedDate(text);
+        },
+      },
+      {
+        title: i18next.t("role:End time"),
+        dataIndex: "endTime",
+        key: "endTime",
+        width: "160px",
+        sorter: true,
+        render: (text, record, index) => {
+          return Setting.getFormattedDate(text);
+        },
+      },
+      {
+        title: i18next.t("payment:Message"),
+        dataIndex: "message",
+        key: "message",
+        width: "200px",
+        ...this.getColumnSearchProps("message"),
+      },
+      {
+        title: i18next.t("general:Action"),
+        dataIndex: "",
+        key: "op",
+        width: "360px",
+        fixed: (Setting.isMobile()) ? "false" : "right",
+        render: (text, record, index) => {
+          const isActive = record.state === "Active" || record.state === "PastDue";
+          return (
+            <div>
+              <Select virtual={false} style={{width: "140px", marginRight: "10px"}} disabled={!isActive} placeholder={i18next.t("subscription:Change plan")} value={null} onChange={(value => this.selectPlan(record, value))}>
+                {
+                  (this.state.plans ?? []).filter(plan => plan.name !== record.plan).map((plan, index) => <Option key={index} value={`${plan.owner}/${plan.name}`}>{plan.displayName}</Option>)
+                }
+              </Select>
+              <Popconfirm
+                title={i18next.t("subscription:Sure to cancel") + `: ${record.name} ?`}
+                onConfirm={() => this.cancelSubscription(record)}
+                disabled={!isActive}
+                okText={i18next.t("general:OK")}
+                cancelText={i18next.t("general:Cancel")}
+              >
+                <Button style={{marginBottom: "10px", marginRight: "10px"}} disabled={!isActive}>{i18next.t("subscription:Cancel subscription")}</Button>
+              </Popconfirm>
+              {
+                Setting.isAdminUser(this.props.account) ? (
+                  <PopconfirmModal
+                    title={i18next.t("general:Sure to delete") + `: ${record.name} ?`}
+                    onConfirm={() => this.deleteSubscription(index)}
+                  >
+                  </PopconfirmModal>
+                ) : null
+              }
+            </div>
+          );
+        },
+      },
+    ];
+
+    const paginationProps = {
+      total: this.state.pagination.total,
+      showQuickJumper: true,
+      showSizeChanger: true,
+      showTotal: () => i18next.t("general:{total} in total").replace("{total}", this.state.pagination.total),
+    };
+
+    return (
+      <div>
+        <Table scroll={{x: "max-content"}} columns={columns} dataSource={subscriptions} rowKey="name" size="middle" bordered pagination={paginationProps}
+          title={() => (
+            <div>
+              {i18next.t("general:Subscriptions")}&nbsp;&nbsp;&nbsp;&nbsp;
+            </div>
+          )}
+          loading={this.state.loading}
+          onChange={this.handleTableChange}
+        />
+      </div>
+    );
+  }
+
+  fetch = (params = {}) => {
+    const field = params.searchedColumn, value = params.searchText;
+    const sortField = params.sortField, sortOrder = params.sortOrder;
+    this.setState({loading: true});
+    if (!Setting.isAdminUser(this.props.account)) {
+      SubscriptionBackend.getUserSubscriptions("admin")
+        .then((res) => {
+          if (res.status === "ok") {
+            this.setState({
+              loading: false,
+              data: res.data,
+              pagination: {
+                ...params.pagination,
+                total: res.data.length,
+              },
+            });
+          }
+        });
+      return;
+    }
+
+    SubscriptionBackend.getSubscriptions("", params.pagination.current, params.pagination.pageSize, field, value, sortField, sortOrder)
+      .then((res) => {
+        if (res.status === "ok") {
+          this.setState({
+            loading: false,
+            data: res.data,
+            pagination: {
+              ...params.pagination,
+              total: res.data2,
+            },
+            searchText: params.searchText,
+            searchedColumn: params.searchedColumn,
+          });
+        } else {
+          if (Setting.isResponseDenied(res)) {
+            this.setState({
+              loading: false,
+              isAuthorized: false,
+            });
+          }
+        }
+      });
+  };
+}
+
+export default SubscriptionListPage;
@@ -12,7 +12,7 @@
 // See the License for the specific language governing permissions and
 // limitations under the License.
 
-import {Card, Col, Divider, Progress, Row, Spin} from "antd";
+import {Button, Card, Col, Divider, Input, Popconfirm, Progress, Row, Spin, Switch, Tag} from "antd";
 import * as SystemBackend from "./backend/SystemInfo";
 import React from "react";
 import * as Setting from "./Setting";
@@ -27,6 +27,8 @@ class SystemInfo extends React.Component {
       versionInfo: {},
       intervalId: null,
       loading: true,
+      maintenance: null,
+      maintenanceNotice: "",
     };
   }
 
@@ -51,6 +53,8 @@ class SystemInfo extends React.Component {
       Setting.showMessage("error", `System info failed to get: ${error}`);
     });
 
+    this.getMaintenanceStatus();
+
     SystemBackend.getVersionInfo().then(res => {
       this.setState({
         versionInfo: res.data,
@@ -60,6 +64,84 @@ class SystemInfo extends React.Component {
     });
   }
 
+  getMaintenanceStatus() {
+    SystemBackend.getMaintenanceStatus().then(res => {
+      if (res.status === "ok") {
+        this.setState({
+          maintenance: res.data,
+          maintenanceNotice: res.data.notice,
+        });
+      }
+    });
+  }
+
+  setMaintenanceMode(enabled) {
+    SystemBackend.setMaintenanceMode(enabled, this.state.maintenanceNotice).then(res => {
+      if (res.status === "ok") {
+        Setting.showMessage("success", i18next.t("general:Successfully saved"));
+        this.setState({maintenance: res.data});
+      } else {
+        Setting.showMessage("error", `${i18next.t("general:Failed to save")}: ${res.msg}`);
+      }
+    });
+  }
+
+  drainInstance() {
+    SystemBackend.drainInstance().then(res => {
+      if (res.status === "ok") {
+        if (res.data.isDrained) {
+          Setting.showMessage("success", i18next.t("system:The node is drained and can be shut down"));
+        } else {
+          Setting.showMessage("warning", `${i18next.t("system:Requests in flight")}: ${res.data.inFlightRequests}`);
+        }
+        this.setState({maintenance: res.data});
+      } else {
+        Setting.showMessage("error", res.msg);
+      }
+    });
+  }
+
+  renderMaintenance() {
+    const maintenance = this.state.maintenance;
+    if (maintenance === null) {
+      return null;
+    }
+
+    return (
+      <Card title={i18next.t("system:Maintenance")} bordered={true} style={{marginTop: "10px"}}>
+        <Row style={{marginTop: "10px"}}>
+          <Col span={6}>
+            {Setting.getLabel(i18next.t("system:Maintenance mode"), i18next.t("system:Maintenance mode - Tooltip"))} :
+          </Col>
+          <Col span={18}>
+            <Switch checked={maintenance.enabled} disabled={maintenance.isDraining} onChange={checked => this.setMaintenanceMode(checked)} />
+          </Col>
+        </Row>
+        <Row style={{marginTop: "10px"}}>
+          <Col span={6}>
+            {Setting.getLabel(i18next.t("system:Maintenance notice"), i18next.t("system:Maintenance notice - Tooltip"))} :
+          </Col>
+          <Col span={18}>
+            <Input.TextArea rows={2} value={this.state.maintenanceNotice} onChange={e => this.setState({maintenanceNotice: e.target.value})} />
+          </Col>
+        </Row>
+        <Row style={{marginTop: "10px"}}>
+          <Col span={6}>
+            {i18next.t("system:Node")} :
+          </Col>
+          <Col span={18}>
+            {maintenance.node}&nbsp;
+            {maintenance.isDraining ? <Tag color="orange">{i18next.t("system:Draining")}</Tag> : null}
+            {i18next.t("system:Requests in flight")}: {maintenance.inFlightRequests}
+            <Popconfirm title={i18next.t("system:Drain the node? Its readiness probe will fail until it's restarted")} onConfirm={() => this.drainInstance()} disabled={maintenance.isDraining}>
+              <Button style={{marginLeft: "10px"}} size="small" disabled={maintenance.isDraining}>{i18next.t("system:Drain")}</Button>
+            </Popconfirm>
+          </Col>
+        </Row>
+      </Card>
+    );
+  }
+
   componentWillUnmount() {
     if (this.state.intervalId !== null) {
       clearInterval(this.state.intervalId);
@@ -115,6 +197,7 @@ class SystemInfo extends React.Component {
               <br />
               {i18next.t("system:Community")}: <a target="_blank" rel="noreferrer" href="https://casdoor.org/#:~:text=Casdoor%20API-,Community,-GitHub">Get in Touch!</a>
             </Card>
+            {this.renderMaintenance()}
           </Col>
           <Col span={6}></Col>
         </Row>
@@ -143,6 +226,7 @@ class SystemInfo extends React.Component {
               <br />
               {i18next.t("system:Community")}: <a target="_blank" rel="noreferrer" href="https://casdoor.org/#:~:text=Casdoor%20API-,Community,-GitHub">Get in Touch!</a>
             </Card>
+            {this.renderMaintenance()}
           </Col>
         </Row>
       );
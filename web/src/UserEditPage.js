@@ -13,7 +13,7 @@
 // limitations under the License.
 
 import React from "react";
-import {Button, Card, Col, Input, Result, Row, Select, Spin, Switch} from "antd";
+import {Button, Card, Col, Input, Result, Row, Select, Spin, Switch, Table} from "antd";
 import * as UserBackend from "./backend/UserBackend";
 import * as OrganizationBackend from "./backend/OrganizationBackend";
 import * as Setting from "./Setting";
@@ -47,11 +47,16 @@ class UserEditPage extends React.Component {
       mode: props.location.mode !== undefined ? props.location.mode : "edit",
       loading: true,
       returnUrl: null,
+      securityAnswers: {},
+      accountRecoveries: null,
+      recoveryLink: "",
+      contactChanges: [],
     };
   }
 
   UNSAFE_componentWillMount() {
     this.getUser();
+    this.getContactChanges();
     this.getOrganizations();
     this.getApplicationsByOrganization(this.state.organizationName);
     this.getUserApplication();
@@ -116,6 +121,19 @@ class UserEditPage extends React.Component {
     return value;
   }
 
+  refreshUserAvatar() {
+    UserBackend.refreshUserAvatar(this.state.user.owner, this.state.user.name)
+      .then((res) => {
+        if (res.status === "ok") {
+          Setting.showMessage("success", i18next.t("user:The avatar is regenerated"));
+          this.updateUserField("permanentAvatar", res.data);
+          this.updateUserField("avatar", res.data2);
+        } else {
+          Setting.showMessage("error", res.msg);
+        }
+      });
+  }
+
   updateUserField(key, value) {
     value = this.parseUserField(key, value);
 
@@ -254,6 +272,11 @@ class UserEditPage extends React.Component {
             </Row>
             <Row style={{marginTop: "20px"}}>
               <CropperDivModal buttonText={`${i18next.t("user:Upload a photo")}...`} title={i18next.t("user:Upload a photo")} user={this.state.user} organization={this.state.organizations.find(organization => organization.name === this.state.organizationName)} />
+              {
+                !Setting.isAdminUser(this.props.account) || !this.state.organizations.find(organization => organization.name === this.state.organizationName)?.avatarSettings ? null : (
+                  <Button style={{marginLeft: "10px"}} onClick={() => this.refreshUserAvatar()}>{i18next.t("user:Regenerate avatar")}</Button>
+                )
+              }
             </Row>
           </Col>
         </Row>
@@ -301,6 +324,7 @@ class UserEditPage extends React.Component {
           <Col span={Setting.isMobile() ? 22 : 11} >
             {/* backend auto get the current user, so admin can not edit. Just self can reset*/}
             {this.isSelf() ? <ResetModal application={this.state.application} disabled={disabled} buttonText={i18next.t("user:Reset Email...")} destType={"email"} /> : null}
+            {this.renderPendingContactChange("email")}
           </Col>
         </Row>
       );
@@ -331,6 +355,7 @@ class UserEditPage extends React.Component {
           </Col>
           <Col span={Setting.isMobile() ? 24 : 11} >
             {this.isSelf() ? (<ResetModal application={this.state.application} countryCode={this.getCountryCode()} disabled={disabled} buttonText={i18next.t("user:Reset Phone...")} destType={"phone"} />) : null}
+            {this.renderPendingContactChange("phone")}
           </Col>
         </Row>
       );
@@ -586,9 +611,170 @@ class UserEditPage extends React.Component {
           </Col>
         </Row>
       );
+    } else if (accountItem.name === "Identity verification") {
+      return (
+        <Row style={{marginTop: "20px"}} >
+          <Col style={{marginTop: "5px"}} span={(Setting.isMobile()) ? 22 : 2}>
+            {Setting.getLabel(i18next.t("user:Identity verification"), i18next.t("user:Identity verification - Tooltip"))} :
+          </Col>
+          <Col span={this.isSelf() ? 18 : 22} >
+            <Input value={this.getVerificationLevelText()} disabled={true} />
+          </Col>
+          {
+            !this.isSelf() ? null : (
+              <Col span={4} >
+                <Button style={{marginLeft: "20px"}} onClick={() => this.startIdentityVerification()}>{i18next.t("user:Verify identity")}</Button>
+              </Col>
+            )
+          }
+        </Row>
+      );
+    } else if (accountItem.name === "Security questions") {
+      const questions = this.state.application?.organizationObj.accountRecovery?.securityQuestions ?? [];
+      if (!this.isSelf() || questions.length === 0) {
+        return null;
+      }
+
+      return (
+        <Row style={{marginTop: "20px"}} >
+          <Col style={{marginTop: "5px"}} span={(Setting.isMobile()) ? 22 : 2}>
+            {Setting.getLabel(i18next.t("user:Security questions"), i18next.t("user:Security questions - Tooltip"))} :
+          </Col>
+          <Col span={22} >
+            {
+              questions.map(question => {
+                const answered = this.state.user.securityAnswers?.some(answer => answer.question === question);
+                return (
+                  <Row key={question} style={{marginBottom: "10px"}} >
+                    <Col span={10} style={{marginTop: "5px"}}>
+                      {question}
+                    </Col>
+                    <Col span={14} >
+                      <Input.Password placeholder={answered ? i18next.t("user:Answered") : ""} value={this.state.securityAnswers[question]} onChange={e => {
+                        this.setState({securityAnswers: {...this.state.securityAnswers, [question]: e.target.value}});
+                      }} />
+                    </Col>
+                  </Row>
+                );
+              })
+            }
+            <Button type="primary" onClick={() => this.setSecurityAnswers()}>{i18next.t("user:Save answers")}</Button>
+          </Col>
+        </Row>
+      );
+    } else if (accountItem.name === "Account recovery") {
+      if (this.isSelf()) {
+        return null;
+      }
+
+      return (
+        <Row style={{marginTop: "20px"}} >
+          <Col style={{marginTop: "5px"}} span={(Setting.isMobile()) ? 22 : 2}>
+            {Setting.getLabel(i18next.t("user:Account recovery"), i18next.t("user:Account recovery - Tooltip"))} :
+          </Col>
+          <Col span={22} >
+            <Button onClick={() => this.createAccountRecoveryLink()}>{i18next.t("user:Create recovery link")}</Button>
+            <Button style={{marginLeft: "20px"}} onClick={() => this.getAccountRecoveries()}>{i18next.t("user:Show recovery history")}</Button>
+            {
+              this.state.recoveryLink === "" ? null : (
+                <Input style={{marginTop: "10px"}} value={this.state.recoveryLink} readOnly={true} />
+              )
+            }
+            {
+              this.state.accountRecoveries === null ? null : (
+                <Table style={{marginTop: "10px"}} size="small" pagination={false} rowKey="name" dataSource={this.state.accountRecoveries} columns={[
+                  {title: i18next.t("general:Created time"), dataIndex: "createdTime", key: "createdTime", render: text => Setting.getFormattedDate(text)},
+                  {title: i18next.t("user:Recovery method"), dataIndex: "method", key: "method"},
+                  {title: i18next.t("general:State"), dataIndex: "state", key: "state"},
+                  {title: i18next.t("general:Client IP"), dataIndex: "clientIp", key: "clientIp"},
+                  {title: i18next.t("user:Operator"), dataIndex: "operator", key: "operator"},
+                ]} />
+              )
+            }
+          </Col>
+        </Row>
+      );
     }
   }
 
+  setSecurityAnswers() {
+    UserBackend.setSecurityAnswers(this.state.securityAnswers)
+      .then((res) => {
+        if (res.status === "ok") {
+          Setting.showMessage("success", i18next.t("general:Successfully saved"));
+          this.setState({securityAnswers: {}});
+          this.getUser();
+        } else {
+          Setting.showMessage("error", res.msg);
+        }
+      });
+  }
+
+  createAccountRecoveryLink() {
+    UserBackend.createAccountRecoveryLink(this.state.user.owner, this.state.user.name)
+      .then((res) => {
+        if (res.status === "ok") {
+          this.setState({recoveryLink: res.data});
+          Setting.showMessage("success", `${i18next.t("user:The recovery link expires at")}: ${Setting.getFormattedDate(res.data2)}`);
+        } else {
+          Setting.showMessage("error", res.msg);
+        }
+      });
+  }
+
+  getAccountRecoveries() {
+    UserBackend.getAccountRecoveries(this.state.user.owner, this.state.user.name)
+      .then((res) => {
+        if (res.status === "ok") {
+          this.setState({accountRecoveries: res.data});
+        } else {
+          Setting.showMessage("error", res.msg);
+        }
+      });
+  }
+
+  getContactChanges() {
+    UserBackend.getContactChanges(this.state.organizationName, this.state.userName)
+      .then((res) => {
+        if (res.status === "ok") {
+          this.setState({contactChanges: res.data});
+        }
+      });
+  }
+
+  renderPendingContactChange(type) {
+    const contactChange = this.state.contactChanges.find(contactChange => contactChange.type === type && contactChange.state === "Pending");
+    if (contactChange === undefined) {
+      return null;
+    }
+
+    return (
+      <div style={{marginTop: "5px", color: "gray"}}>
+        {`${i18next.t("user:Pending change to")}: ${contactChange.newValue}, ${i18next.t("user:takes effect at")}: ${Setting.getFormattedDate(contactChange.applyTime)}`}
+      </div>
+    );
+  }
+
+  getVerificationLevelText() {
+    const levels = [i18next.t("user:Not verified"), i18next.t("user:ID document"), i18next.t("user:ID document and liveness")];
+    const text = levels[this.state.user.verificationLevel] ?? `${this.state.user.verificationLevel}`;
+    if (!this.state.user.verifiedTime) {
+      return text;
+    }
+    return `${text} (${Setting.getFormattedDate(this.state.user.verifiedTime)})`;
+  }
+
+  startIdentityVerification() {
+    UserBackend.startIdentityVerification(window.location.href)
+      .then((res) => {
+        if (res.status === "ok") {
+          Setting.goToLink(res.data);
+        } else {
+          Setting.showMessage("error", res.msg);
+        }
+      });
+  }
+
   renderUser() {
     return (
       <Card size="small" title={
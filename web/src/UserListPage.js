@@ -116,6 +116,11 @@ class UserListPage extends BaseListPage {
     }
   }
 
+  exportUsers() {
+    const owner = this.props.match.params.organizationName ?? (Setting.isAdminUser(this.props.account) ? "" : this.props.account.owner);
+    Setting.openLink(`${Setting.ServerUrl}/api/export-users?owner=${encodeURIComponent(owner)}&format=csv`);
+  }
+
   renderUpload() {
     const props = {
       name: "file",
@@ -375,6 +380,7 @@ class UserListPage extends BaseListPage {
               {
                 this.renderUpload()
               }
+              <Button style={{marginLeft: "5px"}} size="small" onClick={() => this.exportUsers()}>{i18next.t("user:Export (.csv)")}</Button>
             </div>
           )}
           loading={this.state.loading}
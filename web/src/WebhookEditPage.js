@@ -244,7 +244,7 @@ class WebhookEditPage extends React.Component {
               }} >
               {
                 (
-                  ["signup", "login", "logout", "add-user", "update-user", "add-organization", "update-organization", "add-provider", "update-provider"].map((option, index) => {
+                  ["signup", "login", "logout", "add-user", "update-user", "add-organization", "update-organization", "add-provider", "update-provider", "expire-role-assignment", "add-access-request", "approve-access-request", "deny-access-request", "expire-access-request", "decide-access-review-item", "complete-access-review", "seat-limit-warning", "seat-limit-exceeded", "report-security-event", "login-alert", "identity-verification", "cert-expiring"].map((option, index) => {
                     return (
                       <Option key={option} value={option}>{option}</Option>
                     );
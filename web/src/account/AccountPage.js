@@ -14,11 +14,17 @@
 
 import React from "react";
 import UserEditPage from "../UserEditPage";
+import SecurityActivityPage from "./SecurityActivityPage";
+import LoginAlertsPage from "./LoginAlertsPage";
 
 class AccountPage extends React.Component {
   render() {
     return (
-      <UserEditPage organizationName={this.props.account.owner} userName={this.props.account.name} account={this.props.account} location={this.props.location} />
+      <div>
+        <UserEditPage organizationName={this.props.account.owner} userName={this.props.account.name} account={this.props.account} location={this.props.location} />
+        <LoginAlertsPage account={this.props.account} />
+        <SecurityActivityPage account={this.props.account} />
+      </div>
     );
   }
 }
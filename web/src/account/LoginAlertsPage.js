@@ -0,0 +1,128 @@
+// Copyright 2023 The Casdoor Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+import React from "react";
+import {Button, Card, Table, Tag} from "antd";
+import * as Setting from "../Setting";
+import * as UserBackend from "../backend/UserBackend";
+import i18next from "i18next";
+
+class LoginAlertsPage extends React.Component {
+  constructor(props) {
+    super(props);
+    this.state = {
+      loginAlerts: [],
+      loading: false,
+    };
+  }
+
+  UNSAFE_componentWillMount() {
+    this.getLoginAlerts();
+  }
+
+  getLoginAlerts() {
+    this.setState({loading: true});
+    UserBackend.getLoginAlerts()
+      .then((res) => {
+        if (res.status === "ok") {
+          this.setState({
+            loginAlerts: res.data,
+            loading: false,
+          });
+        } else {
+          this.setState({loading: false});
+          Setting.showMessage("error", res.msg);
+        }
+      });
+  }
+
+  confirmLoginAlert(loginAlert) {
+    UserBackend.confirmLoginAlert(loginAlert)
+      .then((res) => {
+        if (res.status === "ok") {
+          Setting.showMessage("success", i18next.t("security:Confirmed, this device raises no more alerts"));
+          this.getLoginAlerts();
+        } else {
+          Setting.showMessage("error", res.msg);
+        }
+      })
+      .catch(error => {
+        Setting.showMessage("error", `${i18next.t("general:Failed to connect to server")}: ${error}`);
+      });
+  }
+
+  render() {
+    const columns = [
+      {
+        title: i18next.t("general:Created time"),
+        dataIndex: "createdTime",
+        key: "createdTime",
+        width: "160px",
+        render: (text, record, index) => {
+          return Setting.getFormattedDate(text);
+        },
+      },
+      {
+        title: i18next.t("provider:Type"),
+        dataIndex: "type",
+        key: "type",
+        width: "140px",
+        render: (text, record, index) => {
+          return Setting.getSecurityEventTypeText(text);
+        },
+      },
+      {
+        title: i18next.t("security:Device"),
+        dataIndex: "device",
+        key: "device",
+        width: "160px",
+      },
+      {
+        title: i18next.t("general:Client IP"),
+        dataIndex: "clientIp",
+        key: "clientIp",
+        width: "140px",
+      },
+      {
+        title: i18next.t("security:Location"),
+        dataIndex: "location",
+        key: "location",
+        width: "160px",
+      },
+      {
+        title: i18next.t("general:Action"),
+        dataIndex: "",
+        key: "op",
+        width: "160px",
+        render: (text, record, index) => {
+          if (record.state === "Confirmed") {
+            return <Tag color="green">{i18next.t("security:Confirmed")}</Tag>;
+          }
+
+          return (
+            <Button size="small" type="primary" onClick={() => this.confirmLoginAlert(record)}>{i18next.t("security:This was me")}</Button>
+          );
+        },
+      },
+    ];
+
+    return (
+      <Card size="small" title={i18next.t("security:Login alerts")} style={(Setting.isMobile()) ? {margin: "5px"} : {marginTop: "20px"}} type="inner">
+        <Table scroll={{x: "max-content"}} columns={columns} dataSource={this.state.loginAlerts} rowKey="name" size="middle" bordered pagination={{pageSize: 10}} loading={this.state.loading} />
+      </Card>
+    );
+  }
+}
+
+export default LoginAlertsPage;
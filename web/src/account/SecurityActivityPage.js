@@ -0,0 +1,141 @@
+// Copyright 2023 The Casdoor Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+import React from "react";
+import {Button, Card, Popconfirm, Table, Tag} from "antd";
+import * as Setting from "../Setting";
+import * as UserBackend from "../backend/UserBackend";
+import i18next from "i18next";
+
+class SecurityActivityPage extends React.Component {
+  constructor(props) {
+    super(props);
+    this.state = {
+      events: [],
+      loading: false,
+    };
+  }
+
+  UNSAFE_componentWillMount() {
+    this.getSecurityActivity();
+  }
+
+  getSecurityActivity() {
+    this.setState({loading: true});
+    UserBackend.getSecurityActivity()
+      .then((res) => {
+        if (res.status === "ok") {
+          this.setState({
+            events: res.data,
+            loading: false,
+          });
+        } else {
+          this.setState({loading: false});
+          Setting.showMessage("error", res.msg);
+        }
+      });
+  }
+
+  reportSecurityEvent(event) {
+    UserBackend.reportSecurityEvent(event.id)
+      .then((res) => {
+        if (res.status === "ok") {
+          Setting.showMessage("success", i18next.t("security:Reported, you are signed out everywhere, please sign in again and change your password"));
+          Setting.goToLink("/login");
+        } else {
+          Setting.showMessage("error", res.msg);
+        }
+      })
+      .catch(error => {
+        Setting.showMessage("error", `${i18next.t("general:Failed to connect to server")}: ${error}`);
+      });
+  }
+
+  render() {
+    const columns = [
+      {
+        title: i18next.t("general:Created time"),
+        dataIndex: "createdTime",
+        key: "createdTime",
+        width: "160px",
+        render: (text, record, index) => {
+          return Setting.getFormattedDate(text);
+        },
+      },
+      {
+        title: i18next.t("provider:Type"),
+        dataIndex: "type",
+        key: "type",
+        width: "160px",
+        render: (text, record, index) => {
+          return Setting.getSecurityEventTypeText(text);
+        },
+      },
+      {
+        title: i18next.t("general:Application"),
+        dataIndex: "application",
+        key: "application",
+        width: "140px",
+      },
+      {
+        title: i18next.t("general:Client IP"),
+        dataIndex: "clientIp",
+        key: "clientIp",
+        width: "140px",
+      },
+      {
+        title: i18next.t("security:Device"),
+        dataIndex: "device",
+        key: "device",
+        width: "160px",
+      },
+      {
+        title: i18next.t("security:Location"),
+        dataIndex: "location",
+        key: "location",
+        width: "160px",
+      },
+      {
+        title: i18next.t("general:Action"),
+        dataIndex: "",
+        key: "op",
+        width: "160px",
+        render: (text, record, index) => {
+          if (record.isReported) {
+            return <Tag color="red">{i18next.t("security:Reported")}</Tag>;
+          }
+
+          return (
+            <Popconfirm
+              title={i18next.t("security:Report this event? You will be signed out everywhere and the admins will be notified")}
+              onConfirm={() => this.reportSecurityEvent(record)}
+              okText={i18next.t("general:OK")}
+              cancelText={i18next.t("general:Cancel")}
+            >
+              <Button size="small" danger>{i18next.t("security:This wasn't me")}</Button>
+            </Popconfirm>
+          );
+        },
+      },
+    ];
+
+    return (
+      <Card size="small" title={i18next.t("security:Security activity")} style={(Setting.isMobile()) ? {margin: "5px"} : {marginTop: "20px"}} type="inner">
+        <Table scroll={{x: "max-content"}} columns={columns} dataSource={this.state.events} rowKey="id" size="middle" bordered pagination={{pageSize: 10}} loading={this.state.loading} />
+      </Card>
+    );
+  }
+}
+
+export default SecurityActivityPage;
@@ -46,6 +46,31 @@ export function getEmailAndPhone(organization, username) {
   }).then((res) => res.json());
 }
 
+export function verifyAccountRecovery(form) {
+  return fetch(`${authConfig.serverUrl}/api/verify-account-recovery`, {
+    method: "POST",
+    credentials: "include",
+    body: JSON.stringify(form),
+    headers: {
+      "Accept-Language": Setting.getAcceptLanguage(),
+    },
+  }).then((res) => res.json());
+}
+
+export function resetPasswordByRecovery(recoveryToken, newPassword) {
+  const formData = new FormData();
+  formData.append("recoveryToken", recoveryToken);
+  formData.append("newPassword", newPassword);
+  return fetch(`${authConfig.serverUrl}/api/reset-password-by-recovery`, {
+    method: "POST",
+    credentials: "include",
+    body: formData,
+    headers: {
+      "Accept-Language": Setting.getAcceptLanguage(),
+    },
+  }).then((res) => res.json());
+}
+
 export function oAuthParamsToQuery(oAuthParams) {
   // login
   if (oAuthParams === null) {
@@ -77,6 +102,17 @@ export function login(values, oAuthParams) {
   }).then(res => res.json());
 }
 
+export function loginWithCertificate(values, oAuthParams) {
+  return fetch(`${authConfig.serverUrl}/api/certificate-login${oAuthParamsToQuery(oAuthParams)}`, {
+    method: "POST",
+    credentials: "include",
+    body: JSON.stringify(values),
+    headers: {
+      "Accept-Language": Setting.getAcceptLanguage(),
+    },
+  }).then(res => res.json());
+}
+
 export function loginCas(values, params) {
   return fetch(`${authConfig.serverUrl}/api/login?service=${params.service}`, {
     method: "POST",
@@ -139,3 +175,29 @@ export function getWechatMessageEvent() {
     },
   }).then(res => res.json());
 }
+
+export function confirmContactChange(token) {
+  const formData = new FormData();
+  formData.append("token", token);
+  return fetch(`${authConfig.serverUrl}/api/confirm-contact-change`, {
+    method: "POST",
+    credentials: "include",
+    body: formData,
+    headers: {
+      "Accept-Language": Setting.getAcceptLanguage(),
+    },
+  }).then((res) => res.json());
+}
+
+export function undoContactChange(token) {
+  const formData = new FormData();
+  formData.append("token", token);
+  return fetch(`${authConfig.serverUrl}/api/undo-contact-change`, {
+    method: "POST",
+    credentials: "include",
+    body: formData,
+    headers: {
+      "Accept-Language": Setting.getAcceptLanguage(),
+    },
+  }).then((res) => res.json());
+}
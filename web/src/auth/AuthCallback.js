@@ -28,6 +28,8 @@ class AuthCallback extends React.Component {
     this.state = {
       classes: props,
       msg: null,
+      errorCode: "",
+      helpUrl: "",
       samlResponse: "",
       relayState: "",
       redirectUrl: "",
@@ -124,6 +126,10 @@ class AuthCallback extends React.Component {
     if (this.getResponseType() === "cas") {
       // user is using casdoor as cas sso server, and wants the ticket to be acquired
       AuthBackend.loginCas(body, {"service": casService}).then((res) => {
+        if (Util.handleRequiredSteps(res)) {
+          return;
+        }
+
         if (res.status === "ok") {
           let msg = "Logged in successfully.";
           if (casService === "") {
@@ -149,13 +155,17 @@ class AuthCallback extends React.Component {
     const concatChar = oAuthParams?.redirectUri?.includes("?") ? "&" : "?";
     AuthBackend.login(body, oAuthParams)
       .then((res) => {
+        if (Util.handleRequiredSteps(res)) {
+          return;
+        }
+
         if (res.status === "ok") {
           const responseType = this.getResponseType();
           if (responseType === "login") {
             Setting.showMessage("success", "Logged in successfully");
             // Setting.goToLinkSoft(this, "/");
 
-            const link = Setting.getFromLink();
+            const link = Setting.getFromLink(res.data2 || "/");
             Setting.goToLink(link);
           } else if (responseType === "code") {
             const code = res.data;
@@ -183,6 +193,8 @@ class AuthCallback extends React.Component {
         } else {
           this.setState({
             msg: res.msg,
+            errorCode: res.code ?? "",
+            helpUrl: res.helpUrl ?? "",
           });
         }
       });
@@ -199,7 +211,7 @@ class AuthCallback extends React.Component {
           (this.state.msg === null) ? (
             <Spin size="large" tip={i18next.t("login:Signing in...")} style={{paddingTop: "10%"}} />
           ) : (
-            Util.renderMessageLarge(this, this.state.msg)
+            Util.renderMessageLarge(this, this.state.msg, this.state.errorCode, this.state.helpUrl)
           )
         }
       </div>
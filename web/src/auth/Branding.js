@@ -0,0 +1,122 @@
+// Copyright 2023 The Casdoor Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+
+import React from "react";
+import i18next from "i18next";
+import {Helmet} from "react-helmet";
+import * as BrandingBackend from "../backend/BrandingBackend";
+
+// Branding loads the branding of the application from the server, applies its
+// strings to i18next and renders the favicon, the colors, the custom CSS and
+// the footer links. onLoad receives the branding for the parts (like the
+// logo) that the page renders itself.
+class Branding extends React.Component {
+  constructor(props) {
+    super(props);
+    this.state = {
+      branding: null,
+    };
+  }
+
+  componentDidMount() {
+    this.getBranding();
+  }
+
+  componentDidUpdate(prevProps) {
+    if (prevProps.application?.name !== this.props.application?.name) {
+      this.getBranding();
+    }
+  }
+
+  getBranding() {
+    const application = this.props.application;
+    if (application === undefined || application === null) {
+      return;
+    }
+
+    BrandingBackend.getBranding(application)
+      .then((res) => {
+        if (res.status !== "ok") {
+          return;
+        }
+
+        const branding = res.data;
+        Object.entries(branding.strings ?? {}).forEach(([language, strings]) => {
+          Object.entries(strings).forEach(([key, value]) => {
+            const index = key.indexOf(":");
+            if (index !== -1) {
+              i18next.addResource(language, key.slice(0, index), key.slice(index + 1), value);
+            }
+          });
+        });
+
+        this.setState({branding: branding});
+        if (this.props.onLoad) {
+          this.props.onLoad(branding);
+        }
+      });
+  }
+
+  renderStyle(branding) {
+    let css = "";
+    if (branding.backgroundColor) {
+      css += `.login-content { background-color: ${branding.backgroundColor}; }\n`;
+    }
+    if (branding.colorPrimary) {
+      css += `.login-content .ant-btn-primary { background-color: ${branding.colorPrimary}; }\n`;
+      css += `.login-content a { color: ${branding.colorPrimary}; }\n`;
+    }
+    css += branding.customCss ?? "";
+
+    return (
+      <style>{css}</style>
+    );
+  }
+
+  render() {
+    const branding = this.state.branding;
+    if (branding === null) {
+      return null;
+    }
+
+    return (
+      <React.Fragment>
+        {
+          !branding.favicon ? null : (
+            <Helmet>
+              <link rel="icon" href={branding.favicon} />
+            </Helmet>
+          )
+        }
+        {this.renderStyle(branding)}
+        {
+          (branding.footerLinks ?? []).length === 0 ? null : (
+            <div className="login-footer" style={{position: "fixed", bottom: "10px", width: "100%", textAlign: "center"}}>
+              {
+                branding.footerLinks.map((link, index) => {
+                  return (
+                    <a key={index} target="_blank" rel="noreferrer" href={link.url} style={{margin: "0 10px"}}>{link.title}</a>
+                  );
+                })
+              }
+            </div>
+          )
+        }
+      </React.Fragment>
+    );
+  }
+}
+
+export default Branding;
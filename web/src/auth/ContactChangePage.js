@@ -0,0 +1,79 @@
+// Copyright 2021 The Casdoor Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+import React from "react";
+import {Button, Result} from "antd";
+import i18next from "i18next";
+import * as AuthBackend from "./AuthBackend";
+import * as Setting from "../Setting";
+
+class ContactChangePage extends React.Component {
+  constructor(props) {
+    super(props);
+    const params = new URLSearchParams(this.props.location.search);
+    this.state = {
+      classes: props,
+      token: params.get("token") ?? "",
+      action: params.get("action") === "undo" ? "undo" : "confirm",
+      done: false,
+      loading: false,
+    };
+  }
+
+  componentDidMount() {
+    this.props.onUpdateApplication(null);
+  }
+
+  submit() {
+    this.setState({loading: true});
+    const request = this.state.action === "undo" ? AuthBackend.undoContactChange(this.state.token) : AuthBackend.confirmContactChange(this.state.token);
+    request.then((res) => {
+      this.setState({loading: false});
+      if (res.status === "ok") {
+        this.setState({done: true});
+      } else {
+        Setting.showMessage("error", res.msg);
+      }
+    });
+  }
+
+  render() {
+    const isUndo = this.state.action === "undo";
+
+    if (this.state.done) {
+      return (
+        <Result
+          status="success"
+          title={isUndo ? i18next.t("user:The change has been undone") : i18next.t("user:The change has been applied")}
+          subTitle={isUndo ? i18next.t("user:You have been signed out everywhere, please sign in again and change your password") : null}
+        />
+      );
+    }
+
+    return (
+      <Result
+        status={isUndo ? "warning" : "info"}
+        title={isUndo ? i18next.t("user:Undo the change of your email or phone") : i18next.t("user:Confirm the change of your email or phone")}
+        subTitle={isUndo ? i18next.t("user:Undo the change if you didn't request it, the old value will be kept") : i18next.t("user:The new value takes effect immediately after the confirmation")}
+        extra={[
+          <Button type="primary" danger={isUndo} key="submit" loading={this.state.loading} onClick={() => this.submit()}>
+            {isUndo ? i18next.t("user:Undo") : i18next.t("user:Confirm")}
+          </Button>,
+        ]}
+      />
+    );
+  }
+}
+
+export default ContactChangePage;
@@ -20,7 +20,6 @@ import * as Util from "./Util";
 import * as Setting from "../Setting";
 import i18next from "i18next";
 import {SendCodeInput} from "../common/SendCodeInput";
-import * as UserBackend from "../backend/UserBackend";
 import {CheckCircleOutlined, KeyOutlined, LockOutlined, SolutionOutlined, UserOutlined} from "@ant-design/icons";
 import CustomGithubCorner from "../common/CustomGithubCorner";
 import {withRouter} from "react-router-dom";
@@ -29,19 +28,22 @@ const {Option} = Select;
 class ForgetPage extends React.Component {
   constructor(props) {
     super(props);
+    // an admin-assisted recovery starts at the reset step with the token of the link
+    const recoveryToken = new URLSearchParams(window.location.search).get("recoveryToken") ?? "";
     this.state = {
       classes: props,
       applicationName: props.applicationName ?? props.match.params?.applicationName,
       msg: null,
-      userId: "",
+      recoveryToken: recoveryToken,
       username: "",
       name: "",
       phone: "",
       email: "",
+      questions: [],
       dest: "",
       isVerifyTypeFixed: false,
-      verifyType: "", // "email", "phone"
-      current: 0,
+      verifyType: "", // "email", "phone", "questions"
+      current: recoveryToken !== "" ? 2 : 0,
     };
 
     this.form = React.createRef();
@@ -83,35 +85,38 @@ class ForgetPage extends React.Component {
       AuthBackend.getEmailAndPhone(forms.step1.getFieldValue("organization"), username)
         .then((res) => {
           if (res.status === "ok") {
-            const phone = res.data.phone;
-            const email = res.data.email;
+            const phone = res.data.phone ?? "";
+            const email = res.data.email ?? "";
+            const questions = res.data.securityAnswers?.map(securityAnswer => securityAnswer.question) ?? [];
 
-            if (phone === "" && email === "") {
-              Setting.showMessage("error", "no verification method!");
+            if (phone === "" && email === "" && questions.length === 0) {
+              Setting.showMessage("error", i18next.t("forget:No recovery method is available for the account"));
             } else {
               this.setState({
                 name: res.data.name,
                 phone: phone,
                 email: email,
+                questions: questions,
               });
 
               const saveFields = (type, dest, fixed) => {
                 this.setState({
                   verifyType: type,
-                  isVerifyTypeFixed: fixed,
+                  isVerifyTypeFixed: fixed && questions.length === 0,
                   dest: dest,
                 });
               };
 
-              switch (res.data2) {
-              case "email":
+              if (res.data2 === "email" && email !== "") {
                 saveFields("email", email, true);
-                break;
-              case "phone":
+              } else if (res.data2 === "phone" && phone !== "") {
                 saveFields("phone", phone, true);
-                break;
-              case "username":
-                phone !== "" ? saveFields("phone", phone, false) : saveFields("email", email, false);
+              } else if (phone !== "") {
+                saveFields("phone", phone, false);
+              } else if (email !== "") {
+                saveFields("email", email, false);
+              } else {
+                saveFields("questions", "questions", false);
               }
 
               this.setState({
@@ -124,18 +129,17 @@ class ForgetPage extends React.Component {
         });
       break;
     case "step2":
-      const oAuthParams = Util.getOAuthGetParameters();
+      const methods = {email: "Email", phone: "SMS", questions: "Security questions"};
 
-      AuthBackend.login({
-        application: forms.step2.getFieldValue("application"),
+      AuthBackend.verifyAccountRecovery({
         organization: forms.step2.getFieldValue("organization"),
-        username: forms.step2.getFieldValue("dest"),
-        name: this.state.name,
-        code: forms.step2.getFieldValue("code"),
-        type: "login",
-      }, oAuthParams).then(res => {
+        username: this.state.name,
+        method: methods[this.state.verifyType],
+        code: forms.step2.getFieldValue("code") ?? "",
+        answers: forms.step2.getFieldValue("answers") ?? {},
+      }).then(res => {
         if (res.status === "ok") {
-          this.setState({current: 2, userId: res.data});
+          this.setState({current: 2, recoveryToken: res.data});
         } else {
           Setting.showMessage("error", res.msg);
         }
@@ -148,9 +152,7 @@ class ForgetPage extends React.Component {
   }
 
   onFinish(values) {
-    values.username = this.state.name;
-    values.userOwner = this.getApplicationObj()?.organizationObj.name;
-    UserBackend.setPassword(values.userOwner, values.username, "", values?.newPassword).then(res => {
+    AuthBackend.resetPasswordByRecovery(this.state.recoveryToken, values?.newPassword).then(res => {
       if (res.status === "ok") {
         Setting.redirectToLoginPage(this.getApplicationObj(), this.props.history);
       } else {
@@ -180,6 +182,14 @@ class ForgetPage extends React.Component {
       );
     }
 
+    if (this.state.questions.length > 0) {
+      options.push(
+        <Option key={"questions"} value={"questions"} >
+          &nbsp;&nbsp;{i18next.t("forget:Security questions")}
+        </Option>
+      );
+    }
+
     return options;
   }
 
@@ -257,7 +267,14 @@ class ForgetPage extends React.Component {
             )
           }
           onValuesChange={(changedValues, allValues) => {
-            const verifyType = changedValues.dest?.indexOf("@") === -1 ? "phone" : "email";
+            if (changedValues.dest === undefined) {
+              return;
+            }
+
+            let verifyType = changedValues.dest.indexOf("@") === -1 ? "phone" : "email";
+            if (changedValues.dest === "questions") {
+              verifyType = "questions";
+            }
             this.setState({
               dest: changedValues.dest,
               verifyType: verifyType,
@@ -308,21 +325,37 @@ class ForgetPage extends React.Component {
               </Select>
             }
           </Form.Item>
-          <Form.Item
-            name="code"
-            rules={[
-              {
-                required: true,
-                message: i18next.t("code:Please input your verification code!"),
-              },
-            ]}
-          >
-            <SendCodeInput disabled={this.state.dest === ""}
-              method={"forget"}
-              onButtonClickArgs={[this.state.dest, this.state.verifyType, Setting.getApplicationName(this.getApplicationObj()), this.state.name]}
-              application={application}
-            />
-          </Form.Item>
+          {
+            this.state.verifyType === "questions" ? (
+              this.state.questions.map(question => (
+                <Form.Item
+                  key={question}
+                  name={["answers", question]}
+                  label={question}
+                  labelCol={{span: 24}}
+                  style={{textAlign: "left"}}
+                >
+                  <Input placeholder={i18next.t("forget:Answer")} />
+                </Form.Item>
+              ))
+            ) : (
+              <Form.Item
+                name="code"
+                rules={[
+                  {
+                    required: true,
+                    message: i18next.t("code:Please input your verification code!"),
+                  },
+                ]}
+              >
+                <SendCodeInput disabled={this.state.dest === ""}
+                  method={"forget"}
+                  onButtonClickArgs={[this.state.dest, this.state.verifyType, Setting.getApplicationName(this.getApplicationObj()), this.state.name]}
+                  application={application}
+                />
+              </Form.Item>
+            )
+          }
           <br />
           <Form.Item>
             <Button
@@ -387,7 +420,7 @@ class ForgetPage extends React.Component {
               hasFeedback
             >
               <Input.Password
-                disabled={this.state.userId === ""}
+                disabled={this.state.recoveryToken === ""}
                 prefix={<LockOutlined />}
                 placeholder={i18next.t("general:Password")}
               />
@@ -414,14 +447,14 @@ class ForgetPage extends React.Component {
               ]}
             >
               <Input.Password
-                disabled={this.state.userId === ""}
+                disabled={this.state.recoveryToken === ""}
                 prefix={<CheckCircleOutlined />}
                 placeholder={i18next.t("signup:Confirm")}
               />
             </Form.Item>
             <br />
             <Form.Item hidden={this.state.current !== 2}>
-              <Button block type="primary" htmlType="submit" disabled={this.state.userId === ""}>
+              <Button block type="primary" htmlType="submit" disabled={this.state.recoveryToken === ""}>
                 {i18next.t("forget:Change Password")}
               </Button>
             </Form.Item>
@@ -13,13 +13,14 @@
 // limitations under the License.
 
 import React from "react";
-import {Button, Checkbox, Col, Form, Input, Result, Row, Spin, Tabs} from "antd";
+import {Alert, Button, Checkbox, Col, Form, Input, List, Modal, Result, Row, Spin, Tabs} from "antd";
 import {LockOutlined, UserOutlined} from "@ant-design/icons";
 import * as UserWebauthnBackend from "../backend/UserWebauthnBackend";
 import * as Conf from "../Conf";
 import * as AuthBackend from "./AuthBackend";
 import * as OrganizationBackend from "../backend/OrganizationBackend";
 import * as ApplicationBackend from "../backend/ApplicationBackend";
+import * as SystemBackend from "../backend/SystemInfo";
 import * as Provider from "./Provider";
 import * as ProviderButton from "./ProviderButton";
 import * as Util from "./Util";
@@ -28,6 +29,7 @@ import * as AgreementModal from "../common/modal/AgreementModal";
 import SelfLoginButton from "./SelfLoginButton";
 import i18next from "i18next";
 import CustomGithubCorner from "../common/CustomGithubCorner";
+import Branding from "./Branding";
 import {SendCodeInput} from "../common/SendCodeInput";
 import LanguageSelect from "../common/select/LanguageSelect";
 import {CaptchaModal} from "../common/modal/CaptchaModal";
@@ -43,6 +45,8 @@ class LoginPage extends React.Component {
       owner: props.owner ?? (props.match?.params?.owner ?? null),
       mode: props.mode ?? (props.match?.params?.mode ?? null), // "signup" or "signin"
       msg: null,
+      errorCode: "",
+      helpUrl: "",
       username: null,
       validEmailOrPhone: false,
       validEmail: false,
@@ -55,6 +59,8 @@ class LoginPage extends React.Component {
       redirectUrl: "",
       isTermsOfUseVisible: false,
       termsOfUseContent: "",
+      selectableOrganizations: null,
+      maintenanceNotice: "",
     };
 
     if (this.state.type === "cas" && props.match?.params.casApplicationName !== undefined) {
@@ -75,6 +81,12 @@ class LoginPage extends React.Component {
         Setting.showMessage("error", `Unknown authentication type: ${this.state.type}`);
       }
     }
+
+    SystemBackend.getMaintenanceNotice().then(res => {
+      if (res.status === "ok") {
+        this.setState({maintenanceNotice: res.data});
+      }
+    });
   }
 
   componentDidUpdate(prevProps, prevState, snapshot) {
@@ -121,6 +133,8 @@ class LoginPage extends React.Component {
           this.onUpdateApplication(null);
           this.setState({
             msg: res.msg,
+            errorCode: res.code ?? "",
+            helpUrl: res.helpUrl ?? "",
           });
         }
       });
@@ -163,6 +177,7 @@ class LoginPage extends React.Component {
   }
 
   onUpdateApplication(application) {
+    Setting.applyDefaultLanguage(application?.organizationObj?.defaultLanguage);
     this.props.onUpdateApplication(application);
   }
 
@@ -246,6 +261,24 @@ class LoginPage extends React.Component {
   }
 
   onFinish(values) {
+    // home realm discovery: the built-in login sends emails of a verified domain to their organization
+    const application = this.getApplicationObj();
+    if (this.state.type === "login" && application?.organization === "built-in" && values["username"]?.includes("@")) {
+      OrganizationBackend.getEmailDomainApplication(values["username"])
+        .then((res) => {
+          if (res.status === "ok" && res.data !== null && res.data.organization !== application.organization) {
+            Setting.goToLink(res.data2);
+          } else {
+            this.onFinishLogin(values);
+          }
+        });
+      return;
+    }
+
+    this.onFinishLogin(values);
+  }
+
+  onFinishLogin(values) {
     if (this.state.loginMethod === "webAuthn") {
       let username = this.state.username;
       if (username === null || username === "") {
@@ -273,6 +306,10 @@ class LoginPage extends React.Component {
       const casParams = Util.getCasParameters();
       values["type"] = this.state.type;
       AuthBackend.loginCas(values, casParams).then((res) => {
+        if (Util.handleRequiredSteps(res)) {
+          return;
+        }
+
         if (res.status === "ok") {
           let msg = "Logged in successfully. ";
           if (casParams.service === "") {
@@ -295,15 +332,28 @@ class LoginPage extends React.Component {
       // OAuth
       const oAuthParams = Util.getOAuthGetParameters();
       this.populateOauthValues(values);
-      AuthBackend.login(values, oAuthParams)
+      const loginFunc = values["certificateLogin"] ? AuthBackend.loginWithCertificate : AuthBackend.login;
+      loginFunc(values, oAuthParams)
         .then((res) => {
+          if (Util.handleRequiredSteps(res)) {
+            return;
+          }
+
+          if (res.status === "ok" && res.data === "SelectOrganization") {
+            this.setState({
+              values: values,
+              selectableOrganizations: res.data2,
+            });
+            return;
+          }
+
           if (res.status === "ok") {
             const responseType = values["type"];
 
             if (responseType === "login") {
               Setting.showMessage("success", i18next.t("application:Logged in successfully"));
 
-              const link = Setting.getFromLink();
+              const link = Setting.getFromLink(res.data2 || "/");
               Setting.goToLink(link);
             } else if (responseType === "code") {
               this.postCodeLoginAction(res);
@@ -482,9 +532,21 @@ class LoginPage extends React.Component {
                   i18next.t("login:Sign In")
               }
             </Button>
+            {
+              application.clientCertLogin?.enabled ? (
+                <Button style={{width: "100%", marginBottom: "5px"}} onClick={() => {
+                  this.login({application: application.name, certificateLogin: true});
+                }}>
+                  {i18next.t("login:Sign in with certificate")}
+                </Button>
+              ) : null
+            }
             {
               this.renderCaptchaModal(application)
             }
+            {
+              this.renderOrganizationPickerModal()
+            }
             {
               this.renderFooter(application)
             }
@@ -570,6 +632,38 @@ class LoginPage extends React.Component {
     />;
   }
 
+  renderOrganizationPickerModal() {
+    if (this.state.selectableOrganizations === null) {
+      return null;
+    }
+
+    return (
+      <Modal
+        title={i18next.t("login:Select an organization")}
+        open={true}
+        footer={null}
+        onCancel={() => this.setState({selectableOrganizations: null})}
+      >
+        <List
+          dataSource={this.state.selectableOrganizations}
+          renderItem={organization => (
+            <List.Item style={{cursor: "pointer"}} onClick={() => {
+              const values = this.state.values;
+              values["activeOrganization"] = organization.name;
+              this.setState({selectableOrganizations: null});
+              this.login(values);
+            }}>
+              <List.Item.Meta
+                avatar={<img width={40} src={organization.favicon} alt={organization.displayName} />}
+                title={organization.displayName}
+              />
+            </List.Item>
+          )}
+        />
+      </Modal>
+    );
+  }
+
   renderFooter(application) {
     if (this.state.mode === "signup") {
       return (
@@ -693,7 +787,7 @@ class LoginPage extends React.Component {
                 Setting.goToLink(`${oAuthParams.redirectUri}#${responseType}=${accessToken}?state=${oAuthParams.state}&token_type=bearer`);
               } else {
                 Setting.showMessage("success", i18next.t("login:Successfully logged in with WebAuthn credentials"));
-                Setting.goToLink("/");
+                Setting.goToLink(Setting.getFromLink(res.data2 || "/"));
               }
             } else {
               Setting.showMessage("error", res.msg);
@@ -773,7 +867,7 @@ class LoginPage extends React.Component {
       return null;
     }
     if (application === null) {
-      return Util.renderMessageLarge(this, this.state.msg);
+      return Util.renderMessageLarge(this, this.state.msg, this.state.errorCode, this.state.helpUrl);
     }
 
     if (this.state.samlResponse !== "") {
@@ -799,6 +893,7 @@ class LoginPage extends React.Component {
     return (
       <React.Fragment>
         <CustomGithubCorner />
+        <Branding application={application} onLoad={(branding) => this.setState({branding: branding})} />
         <div className="login-content" style={{margin: this.props.preview ?? this.parseOffset(application.formOffset)}}>
           {Setting.inIframe() || Setting.isMobile() ? null : <div dangerouslySetInnerHTML={{__html: application.formCss}} />}
           <div className="login-panel">
@@ -812,12 +907,15 @@ class LoginPage extends React.Component {
                     Setting.renderHelmet(application)
                   }
                   {
-                    Setting.renderLogo(application)
+                    Setting.renderLogo(application, this.state.branding)
                   }
                   <LanguageSelect languages={application.organizationObj.languages} style={{top: "55px", right: "5px", position: "absolute"}} />
                   {
                     this.renderSignedInBox()
                   }
+                  {
+                    this.state.maintenanceNotice ? <Alert style={{margin: "10px"}} type="warning" showIcon message={this.state.maintenanceNotice} /> : null
+                  }
                   {
                     this.renderForm(application)
                   }
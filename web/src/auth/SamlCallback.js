@@ -83,7 +83,7 @@ class SamlCallback extends React.Component {
           const responseType = this.getResponseType(redirectUri);
           if (responseType === "login") {
             Setting.showMessage("success", "Logged in successfully");
-            Setting.goToLink("/");
+            Setting.goToLink(res.data2 || "/");
           } else if (responseType === "code") {
             const code = res.data;
             Setting.goToLink(`${redirectUri}?code=${code}&state=${state}`);
@@ -25,6 +25,7 @@ import * as AgreementModal from "../common/modal/AgreementModal";
 import {SendCodeInput} from "../common/SendCodeInput";
 import RegionSelect from "../common/select/RegionSelect";
 import CustomGithubCorner from "../common/CustomGithubCorner";
+import Branding from "./Branding";
 import LanguageSelect from "../common/select/LanguageSelect";
 import {withRouter} from "react-router-dom";
 import {CountryCodeSelect} from "../common/select/CountryCodeSelect";
@@ -147,10 +148,18 @@ class SignupPage extends React.Component {
 
   onFinish(values) {
     const application = this.getApplicationObj();
+    // a guest of the application keeps its identity when signing up
+    const guestToken = new URLSearchParams(window.location.search).get("guest_token");
+    if (guestToken !== null) {
+      values["guestToken"] = guestToken;
+    }
     AuthBackend.signup(values)
       .then((res) => {
         if (res.status === "ok") {
-          if (Setting.hasPromptPage(application)) {
+          if (res.data2) {
+            // the identity verification comes back to the result or prompt page
+            Setting.goToLink(res.data2);
+          } else if (Setting.hasPromptPage(application)) {
             AuthBackend.getAccount("")
               .then((res) => {
                 let account = null;
@@ -575,6 +584,7 @@ class SignupPage extends React.Component {
     return (
       <React.Fragment>
         <CustomGithubCorner />
+        <Branding application={application} onLoad={(branding) => this.setState({branding: branding})} />
         <div className="login-content" style={{margin: this.props.preview ?? this.parseOffset(application.formOffset)}}>
           {Setting.inIframe() || Setting.isMobile() ? null : <div dangerouslySetInnerHTML={{__html: application.formCss}} />}
           <div className="login-panel" >
@@ -586,7 +596,7 @@ class SignupPage extends React.Component {
                 Setting.renderHelmet(application)
               }
               {
-                Setting.renderLogo(application)
+                Setting.renderLogo(application, this.state.branding)
               }
               <LanguageSelect languages={application.organizationObj.languages} style={{top: "55px", right: "5px", position: "absolute"}} />
               {
@@ -17,6 +17,7 @@ import {Alert, Button, Result} from "antd";
 import {getWechatMessageEvent} from "./AuthBackend";
 import * as Setting from "../Setting";
 import * as Provider from "./Provider";
+import i18next from "i18next";
 
 export function renderMessage(msg) {
   if (msg !== null) {
@@ -40,20 +41,36 @@ export function renderMessage(msg) {
   }
 }
 
-export function renderMessageLarge(ths, msg) {
+export function renderMessageLarge(ths, msg, code, helpUrl) {
   if (msg !== null) {
     return (
       <Result
         style={{margin: "0px auto"}}
         status="error"
         title="There was a problem signing you in.."
-        subTitle={msg}
+        subTitle={
+          <React.Fragment>
+            {msg}
+            {
+              code ? (
+                <div>
+                  {`${i18next.t("login:Error code")}: ${code}`}
+                </div>
+              ) : null
+            }
+          </React.Fragment>
+        }
         extra={[
           <Button type="primary" key="back" onClick={() => {
             window.history.go(-2);
           }}>
               Back
           </Button>,
+          helpUrl ? (
+            <Button key="help" href={helpUrl} target="_blank" rel="noopener noreferrer">
+              {i18next.t("login:Get help")}
+            </Button>
+          ) : null,
         ]}
       >
       </Result>
@@ -147,3 +164,19 @@ export function getEvent(application, provider) {
       }
     });
 }
+
+// A login hook can ask for more steps before the login completes, the user
+// is sent to the page of the hook when it has one.
+export function handleRequiredSteps(res) {
+  if (res.status !== "ok" || res.data !== "RequiredSteps") {
+    return false;
+  }
+
+  const result = res.data2;
+  const message = result.message !== "" ? result.message : (result.steps ?? []).join(", ");
+  Setting.showMessage("warning", `${i18next.t("login:More steps are required to sign in")}: ${message}`);
+  if (result.redirectUrl) {
+    Setting.goToLink(result.redirectUrl);
+  }
+  return true;
+}
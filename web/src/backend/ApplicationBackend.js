@@ -98,3 +98,13 @@ export function getSamlMetadata(owner, name) {
     },
   }).then(res => res.text());
 }
+
+export function checkApplicationSso(owner, name, type) {
+  return fetch(`${Setting.ServerUrl}/api/check-application-sso?id=${owner}/${encodeURIComponent(name)}&type=${type}`, {
+    method: "GET",
+    credentials: "include",
+    headers: {
+      "Accept-Language": Setting.getAcceptLanguage(),
+    },
+  }).then(res => res.json());
+}
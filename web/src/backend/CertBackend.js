@@ -69,3 +69,25 @@ export function deleteCert(cert) {
     },
   }).then(res => res.json());
 }
+
+export function generateCertCsr(owner, name, form) {
+  return fetch(`${Setting.ServerUrl}/api/generate-cert-csr?id=${owner}/${encodeURIComponent(name)}`, {
+    method: "POST",
+    credentials: "include",
+    body: JSON.stringify(form),
+    headers: {
+      "Accept-Language": Setting.getAcceptLanguage(),
+    },
+  }).then(res => res.json());
+}
+
+export function uploadCertCertificate(owner, name, certificate) {
+  return fetch(`${Setting.ServerUrl}/api/upload-cert-certificate?id=${owner}/${encodeURIComponent(name)}`, {
+    method: "POST",
+    credentials: "include",
+    body: certificate,
+    headers: {
+      "Accept-Language": Setting.getAcceptLanguage(),
+    },
+  }).then(res => res.json());
+}
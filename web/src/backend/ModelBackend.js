@@ -69,3 +69,24 @@ export function deleteModel(model) {
     },
   }).then(res => res.json());
 }
+
+export function validateModel(validation) {
+  return fetch(`${Setting.ServerUrl}/api/validate-model`, {
+    method: "POST",
+    credentials: "include",
+    body: JSON.stringify(validation),
+    headers: {
+      "Accept-Language": Setting.getAcceptLanguage(),
+    },
+  }).then(res => res.json());
+}
+
+export function getModelTemplates() {
+  return fetch(`${Setting.ServerUrl}/api/get-model-templates`, {
+    method: "GET",
+    credentials: "include",
+    headers: {
+      "Accept-Language": Setting.getAcceptLanguage(),
+    },
+  }).then(res => res.json());
+}
@@ -79,3 +79,63 @@ export function getDefaultApplication(owner, name) {
     },
   }).then(res => res.json());
 }
+
+export function verifyOrganizationDomain(owner, name) {
+  return fetch(`${Setting.ServerUrl}/api/verify-organization-domain?id=${owner}/${encodeURIComponent(name)}`, {
+    method: "POST",
+    credentials: "include",
+    headers: {
+      "Accept-Language": Setting.getAcceptLanguage(),
+    },
+  }).then(res => res.json());
+}
+
+export function verifyEmailDomain(owner, name, domain) {
+  return fetch(`${Setting.ServerUrl}/api/verify-email-domain?id=${owner}/${encodeURIComponent(name)}&domain=${encodeURIComponent(domain)}`, {
+    method: "POST",
+    credentials: "include",
+    headers: {
+      "Accept-Language": Setting.getAcceptLanguage(),
+    },
+  }).then(res => res.json());
+}
+
+export function getEmailDomainApplication(email) {
+  return fetch(`${Setting.ServerUrl}/api/get-email-domain-application?email=${encodeURIComponent(email)}`, {
+    method: "GET",
+    credentials: "include",
+    headers: {
+      "Accept-Language": Setting.getAcceptLanguage(),
+    },
+  }).then(res => res.json());
+}
+
+export function getShards() {
+  return fetch(`${Setting.ServerUrl}/api/get-shards`, {
+    method: "GET",
+    credentials: "include",
+    headers: {
+      "Accept-Language": Setting.getAcceptLanguage(),
+    },
+  }).then(res => res.json());
+}
+
+export function moveOrganizationShard(owner, name, shard) {
+  return fetch(`${Setting.ServerUrl}/api/move-organization-shard?id=${owner}/${encodeURIComponent(name)}&shard=${encodeURIComponent(shard)}`, {
+    method: "POST",
+    credentials: "include",
+    headers: {
+      "Accept-Language": Setting.getAcceptLanguage(),
+    },
+  }).then(res => res.json());
+}
+
+export function getErrorCodes(organization = "") {
+  return fetch(`${Setting.ServerUrl}/api/get-error-codes?organization=${encodeURIComponent(organization)}`, {
+    method: "GET",
+    credentials: "include",
+    headers: {
+      "Accept-Language": Setting.getAcceptLanguage(),
+    },
+  }).then(res => res.json());
+}
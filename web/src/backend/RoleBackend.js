@@ -69,3 +69,13 @@ export function deleteRole(role) {
     },
   }).then(res => res.json());
 }
+
+export function getCapabilities() {
+  return fetch(`${Setting.ServerUrl}/api/get-capabilities`, {
+    method: "GET",
+    credentials: "include",
+    headers: {
+      "Accept-Language": Setting.getAcceptLanguage(),
+    },
+  }).then(res => res.json());
+}
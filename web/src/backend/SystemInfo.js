@@ -24,6 +24,46 @@ export function getSystemInfo() {
   }).then(res => res.json());
 }
 
+export function getMaintenanceStatus() {
+  return fetch(`${Setting.ServerUrl}/api/get-maintenance-status`, {
+    method: "GET",
+    credentials: "include",
+    headers: {
+      "Accept-Language": Setting.getAcceptLanguage(),
+    },
+  }).then(res => res.json());
+}
+
+export function setMaintenanceMode(enabled, notice) {
+  return fetch(`${Setting.ServerUrl}/api/set-maintenance-mode?enabled=${enabled}&notice=${encodeURIComponent(notice)}`, {
+    method: "POST",
+    credentials: "include",
+    headers: {
+      "Accept-Language": Setting.getAcceptLanguage(),
+    },
+  }).then(res => res.json());
+}
+
+export function drainInstance() {
+  return fetch(`${Setting.ServerUrl}/api/drain-instance`, {
+    method: "POST",
+    credentials: "include",
+    headers: {
+      "Accept-Language": Setting.getAcceptLanguage(),
+    },
+  }).then(res => res.json());
+}
+
+export function getMaintenanceNotice() {
+  return fetch(`${Setting.ServerUrl}/api/get-maintenance-notice`, {
+    method: "GET",
+    credentials: "include",
+    headers: {
+      "Accept-Language": Setting.getAcceptLanguage(),
+    },
+  }).then(res => res.json());
+}
+
 export function getVersionInfo() {
   return fetch(`${Setting.ServerUrl}/api/get-version-info`, {
     method: "GET",
@@ -188,3 +188,104 @@ export function getCaptcha(owner, name, isCurrentProvider) {
     },
   }).then(res => res.json()).then(res => res.data);
 }
+
+export function getSecurityActivity() {
+  return fetch(`${Setting.ServerUrl}/api/get-security-activity`, {
+    method: "GET",
+    credentials: "include",
+    headers: {
+      "Accept-Language": Setting.getAcceptLanguage(),
+    },
+  }).then(res => res.json());
+}
+
+export function reportSecurityEvent(id) {
+  return fetch(`${Setting.ServerUrl}/api/report-security-event?id=${encodeURIComponent(id)}`, {
+    method: "POST",
+    credentials: "include",
+    headers: {
+      "Accept-Language": Setting.getAcceptLanguage(),
+    },
+  }).then(res => res.json());
+}
+
+export function getLoginAlerts() {
+  return fetch(`${Setting.ServerUrl}/api/get-login-alerts`, {
+    method: "GET",
+    credentials: "include",
+    headers: {
+      "Accept-Language": Setting.getAcceptLanguage(),
+    },
+  }).then(res => res.json());
+}
+
+export function confirmLoginAlert(loginAlert) {
+  return fetch(`${Setting.ServerUrl}/api/confirm-login-alert?id=${loginAlert.owner}/${encodeURIComponent(loginAlert.name)}`, {
+    method: "POST",
+    credentials: "include",
+    headers: {
+      "Accept-Language": Setting.getAcceptLanguage(),
+    },
+  }).then(res => res.json());
+}
+
+export function startIdentityVerification(returnUrl) {
+  return fetch(`${Setting.ServerUrl}/api/start-identity-verification?returnUrl=${encodeURIComponent(returnUrl)}`, {
+    method: "POST",
+    credentials: "include",
+    headers: {
+      "Accept-Language": Setting.getAcceptLanguage(),
+    },
+  }).then(res => res.json());
+}
+
+export function setSecurityAnswers(answers) {
+  return fetch(`${Setting.ServerUrl}/api/set-security-answers`, {
+    method: "POST",
+    credentials: "include",
+    body: JSON.stringify(answers),
+    headers: {
+      "Accept-Language": Setting.getAcceptLanguage(),
+    },
+  }).then(res => res.json());
+}
+
+export function createAccountRecoveryLink(owner, name) {
+  return fetch(`${Setting.ServerUrl}/api/create-account-recovery-link?id=${owner}/${encodeURIComponent(name)}`, {
+    method: "POST",
+    credentials: "include",
+    headers: {
+      "Accept-Language": Setting.getAcceptLanguage(),
+    },
+  }).then(res => res.json());
+}
+
+export function getAccountRecoveries(owner, name) {
+  return fetch(`${Setting.ServerUrl}/api/get-account-recoveries?id=${owner}/${encodeURIComponent(name)}`, {
+    method: "GET",
+    credentials: "include",
+    headers: {
+      "Accept-Language": Setting.getAcceptLanguage(),
+    },
+  }).then(res => res.json());
+}
+
+export function getContactChanges(owner, name) {
+  return fetch(`${Setting.ServerUrl}/api/get-contact-changes?id=${owner}/${encodeURIComponent(name)}`, {
+    method: "GET",
+    credentials: "include",
+    headers: {
+      "Accept-Language": Setting.getAcceptLanguage(),
+    },
+  }).then(res => res.json());
+}
+
+export function refreshUserAvatar(owner, name) {
+  return fetch(`${Setting.ServerUrl}/api/refresh-user-avatar?id=${owner}/${encodeURIComponent(name)}`, {
+    method: "POST",
+    credentials: "include",
+    headers: {
+      "Accept-Language": Setting.getAcceptLanguage(),
+    },
+  }).then(res => res.json());
+}
@@ -0,0 +1,115 @@
+// Copyright 2023 The Casdoor Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+
+import React from "react";
+import {Button, Col, Input, Row} from "antd";
+import {DeleteOutlined, LinkOutlined} from "@ant-design/icons";
+import i18next from "i18next";
+import * as Setting from "../Setting";
+
+// BrandingEditor edits a branding object, empty fields fall back to the
+// organization (for applications) or to the built-in defaults.
+class BrandingEditor extends React.Component {
+  constructor(props) {
+    super(props);
+    this.state = {
+      strings: JSON.stringify(props.branding?.strings ?? {}, null, 2),
+      isStringsValid: true,
+    };
+  }
+
+  getBranding() {
+    return this.props.branding ?? {};
+  }
+
+  updateField(key, value) {
+    this.props.onChange({...this.getBranding(), [key]: value});
+  }
+
+  updateStrings(value) {
+    this.setState({strings: value});
+    try {
+      this.updateField("strings", JSON.parse(value === "" ? "{}" : value));
+      this.setState({isStringsValid: true});
+    } catch (e) {
+      this.setState({isStringsValid: false});
+    }
+  }
+
+  updateFooterLink(index, key, value) {
+    const footerLinks = [...(this.getBranding().footerLinks ?? [])];
+    footerLinks[index] = {...footerLinks[index], [key]: value};
+    this.updateField("footerLinks", footerLinks);
+  }
+
+  renderRow(label, tooltip, content) {
+    return (
+      <Row style={{marginTop: "10px"}} >
+        <Col style={{marginTop: "5px"}} span={(Setting.isMobile()) ? 22 : 3}>
+          {Setting.getLabel(label, tooltip)} :
+        </Col>
+        <Col span={21} >
+          {content}
+        </Col>
+      </Row>
+    );
+  }
+
+  render() {
+    const branding = this.getBranding();
+    const footerLinks = branding.footerLinks ?? [];
+
+    return (
+      <div style={{width: "100%"}}>
+        {this.renderRow(i18next.t("general:Logo"), i18next.t("general:Logo - Tooltip"),
+          <Input prefix={<LinkOutlined />} value={branding.logo} onChange={e => this.updateField("logo", e.target.value)} />
+        )}
+        {this.renderRow(i18next.t("general:Favicon"), i18next.t("general:Favicon - Tooltip"),
+          <Input prefix={<LinkOutlined />} value={branding.favicon} onChange={e => this.updateField("favicon", e.target.value)} />
+        )}
+        {this.renderRow(i18next.t("theme:Primary color"), i18next.t("organization:Primary color - Tooltip"),
+          <Input type="color" style={{width: "100px"}} value={branding.colorPrimary || "#000000"} onChange={e => this.updateField("colorPrimary", e.target.value)} />
+        )}
+        {this.renderRow(i18next.t("organization:Background color"), i18next.t("organization:Background color - Tooltip"),
+          <Input type="color" style={{width: "100px"}} value={branding.backgroundColor || "#ffffff"} onChange={e => this.updateField("backgroundColor", e.target.value)} />
+        )}
+        {this.renderRow(i18next.t("organization:Custom CSS"), i18next.t("organization:Custom CSS - Tooltip"),
+          <Input.TextArea rows={4} value={branding.customCss} onChange={e => this.updateField("customCss", e.target.value)} />
+        )}
+        {this.renderRow(i18next.t("organization:Footer links"), i18next.t("organization:Footer links - Tooltip"),
+          <div>
+            {
+              footerLinks.map((link, index) => {
+                return (
+                  <Row key={index} style={{marginBottom: "5px"}}>
+                    <Input style={{width: "30%"}} placeholder={i18next.t("user:Title")} value={link.title} onChange={e => this.updateFooterLink(index, "title", e.target.value)} />
+                    <Input style={{width: "60%", marginLeft: "10px"}} prefix={<LinkOutlined />} value={link.url} onChange={e => this.updateFooterLink(index, "url", e.target.value)} />
+                    <Button style={{marginLeft: "10px"}} icon={<DeleteOutlined />} onClick={() => this.updateField("footerLinks", footerLinks.filter((_, i) => i !== index))} />
+                  </Row>
+                );
+              })
+            }
+            <Button size="small" onClick={() => this.updateField("footerLinks", [...footerLinks, {title: "", url: ""}])}>{i18next.t("general:Add")}</Button>
+          </div>
+        )}
+        {this.renderRow(i18next.t("organization:Localized strings"), i18next.t("organization:Localized strings - Tooltip"),
+          <Input.TextArea rows={6} status={this.state.isStringsValid ? "" : "error"} placeholder={"{\"en\": {\"login:Sign In\": \"Continue\"}}"} value={this.state.strings} onChange={e => this.updateStrings(e.target.value)} />
+        )}
+      </div>
+    );
+  }
+}
+
+export default BrandingEditor;
@@ -50,7 +50,11 @@ export const ResetModal = (props) => {
     }
     setConfirmLoading(true);
     UserBackend.resetEmailOrPhone(dest, destType, code).then(res => {
-      if (res.status === "ok") {
+      if (res.status === "ok" && res.data === "Pending") {
+        Setting.showMessage("success", `${i18next.t("user:A confirmation has been sent to your current email or phone, the change takes effect after it is confirmed or at")}: ${Setting.getFormattedDate(res.data2)}`);
+        setConfirmLoading(false);
+        setVisible(false);
+      } else if (res.status === "ok") {
         Setting.showMessage("success", i18next.t("user:Email/phone reset successfully"));
         window.location.reload();
       } else {
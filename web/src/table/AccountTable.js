@@ -97,6 +97,9 @@ class AccountTable extends React.Component {
             {name: "Is deleted", displayName: i18next.t("user:Is deleted")},
             {name: "WebAuthn credentials", displayName: i18next.t("user:WebAuthn credentials")},
             {name: "Managed accounts", displayName: i18next.t("user:Managed accounts")},
+            {name: "Identity verification", displayName: i18next.t("user:Identity verification")},
+            {name: "Security questions", displayName: i18next.t("user:Security questions")},
+            {name: "Account recovery", displayName: i18next.t("user:Account recovery")},
           ];
 
           const getItemDisplayName = (text) => {
@@ -0,0 +1,290 @@
+// Copyright 2023 The Casdoor Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+import React from "react";
+import {Button, Col, Input, InputNumber, Modal, Popconfirm, Row, Select, Table, Tag} from "antd";
+import FileSaver from "file-saver";
+import i18next from "i18next";
+import * as DeviceBackend from "../backend/DeviceBackend";
+import * as Setting from "../Setting";
+
+const {Option} = Select;
+
+class DeviceTable extends React.Component {
+  constructor(props) {
+    super(props);
+    this.state = {
+      table: [],
+      registerVisible: false,
+      registering: false,
+      form: {
+        prefix: "",
+        count: 10,
+        serialNumbers: [],
+        type: "",
+        application: "",
+        credentialType: "Secret",
+      },
+    };
+  }
+
+  componentDidMount() {
+    this.getDevices();
+  }
+
+  getDevices() {
+    DeviceBackend.getDevices(this.props.owner)
+      .then((res) => {
+        if (res.status === "ok") {
+          this.setState({
+            table: res.data,
+          });
+        }
+      });
+  }
+
+  updateFormField(key, value) {
+    const form = this.state.form;
+    form[key] = value;
+    this.setState({
+      form: form,
+    });
+  }
+
+  // the credentials are only returned once, they are saved as a file for provisioning
+  saveCredentials(credentials, fileName) {
+    const blob = new Blob([JSON.stringify(credentials, null, 2)], {type: "application/json;charset=utf-8"});
+    FileSaver.saveAs(blob, fileName);
+  }
+
+  registerDevices() {
+    this.setState({registering: true});
+    DeviceBackend.registerDevices(this.props.owner, this.state.form)
+      .then((res) => {
+        this.setState({registering: false});
+        if (res.status === "ok") {
+          Setting.showMessage("success", `${i18next.t("device:Registered devices")}: ${res.data.length}`);
+          if (this.state.form.credentialType !== "") {
+            this.saveCredentials(res.data, `${this.props.owner}_devices.json`);
+          }
+          this.setState({registerVisible: false});
+          this.getDevices();
+        } else {
+          Setting.showMessage("error", res.msg);
+        }
+      });
+  }
+
+  issueDeviceCredential(record, credentialType) {
+    DeviceBackend.issueDeviceCredential(record.owner, record.name, credentialType)
+      .then((res) => {
+        if (res.status === "ok") {
+          this.saveCredentials(res.data, `${record.name}.json`);
+          this.getDevices();
+        } else {
+          Setting.showMessage("error", res.msg);
+        }
+      });
+  }
+
+  revokeDevice(record) {
+    DeviceBackend.revokeDevice(record.owner, record.name)
+      .then((res) => {
+        if (res.status === "ok") {
+          Setting.showMessage("success", i18next.t("device:The device is revoked and its tokens have expired"));
+          this.getDevices();
+        } else {
+          Setting.showMessage("error", res.msg);
+        }
+      });
+  }
+
+  deleteDevice(record) {
+    DeviceBackend.deleteDevice(record)
+      .then((res) => {
+        if (res.status === "ok") {
+          Setting.showMessage("success", i18next.t("general:Successfully deleted"));
+          this.getDevices();
+        } else {
+          Setting.showMessage("error", `${i18next.t("general:Failed to delete")}: ${res.msg}`);
+        }
+      });
+  }
+
+  renderRegisterModal() {
+    return (
+      <Modal title={i18next.t("device:Register devices")} open={this.state.registerVisible} confirmLoading={this.state.registering}
+        okText={i18next.t("device:Register")} cancelText={i18next.t("general:Cancel")}
+        onOk={() => this.registerDevices()} onCancel={() => this.setState({registerVisible: false})}>
+        <Row style={{marginTop: "10px"}}>
+          <Col span={8}>
+            {Setting.getLabel(i18next.t("device:Name prefix"), i18next.t("device:Name prefix - Tooltip"))} :
+          </Col>
+          <Col span={16}>
+            <Input value={this.state.form.prefix} onChange={e => this.updateFormField("prefix", e.target.value)} />
+          </Col>
+        </Row>
+        <Row style={{marginTop: "10px"}}>
+          <Col span={8}>
+            {Setting.getLabel(i18next.t("device:Serial numbers"), i18next.t("device:Serial numbers - Tooltip"))} :
+          </Col>
+          <Col span={16}>
+            <Input.TextArea rows={3} value={this.state.form.serialNumbers.join("\n")} onChange={e => this.updateFormField("serialNumbers", e.target.value === "" ? [] : e.target.value.split("\n"))} />
+          </Col>
+        </Row>
+        {
+          this.state.form.serialNumbers.length !== 0 ? null : (
+            <Row style={{marginTop: "10px"}}>
+              <Col span={8}>
+                {i18next.t("device:Count")} :
+              </Col>
+              <Col span={16}>
+                <InputNumber min={1} max={1000} value={this.state.form.count} onChange={value => this.updateFormField("count", value)} />
+              </Col>
+            </Row>
+          )
+        }
+        <Row style={{marginTop: "10px"}}>
+          <Col span={8}>
+            {i18next.t("provider:Type")} :
+          </Col>
+          <Col span={16}>
+            <Input placeholder="IoT, Kiosk" value={this.state.form.type} onChange={e => this.updateFormField("type", e.target.value)} />
+          </Col>
+        </Row>
+        <Row style={{marginTop: "10px"}}>
+          <Col span={8}>
+            {Setting.getLabel(i18next.t("general:Application"), i18next.t("device:Application - Tooltip"))} :
+          </Col>
+          <Col span={16}>
+            <Select virtual={false} style={{width: "100%"}} allowClear value={this.state.form.application} onChange={value => this.updateFormField("application", value ?? "")}>
+              {
+                (this.props.applications ?? []).map((application, index) => <Option key={index} value={application.name}>{application.name}</Option>)
+              }
+            </Select>
+          </Col>
+        </Row>
+        <Row style={{marginTop: "10px"}}>
+          <Col span={8}>
+            {Setting.getLabel(i18next.t("device:Credential type"), i18next.t("device:Credential type - Tooltip"))} :
+          </Col>
+          <Col span={16}>
+            <Select virtual={false} style={{width: "100%"}} value={this.state.form.credentialType} onChange={value => this.updateFormField("credentialType", value)}>
+              {
+                [
+                  {id: "Secret", name: i18next.t("device:Secret")},
+                  {id: "Certificate", name: i18next.t("device:Certificate")},
+                  {id: "", name: i18next.t("device:None")},
+                ].map((item, index) => <Option key={index} value={item.id}>{item.name}</Option>)
+              }
+            </Select>
+          </Col>
+        </Row>
+      </Modal>
+    );
+  }
+
+  render() {
+    const columns = [
+      {
+        title: i18next.t("general:Name"),
+        dataIndex: "name",
+        key: "name",
+        width: "160px",
+      },
+      {
+        title: i18next.t("provider:Type"),
+        dataIndex: "type",
+        key: "type",
+        width: "100px",
+      },
+      {
+        title: i18next.t("general:Application"),
+        dataIndex: "application",
+        key: "application",
+        width: "140px",
+      },
+      {
+        title: i18next.t("device:Serial number"),
+        dataIndex: "serialNumber",
+        key: "serialNumber",
+        width: "140px",
+      },
+      {
+        title: i18next.t("general:State"),
+        dataIndex: "state",
+        key: "state",
+        width: "110px",
+        render: (text, record, index) => {
+          const color = text === "Active" ? "green" : (text === "Revoked" ? "red" : "default");
+          return <Tag color={color}>{text}</Tag>;
+        },
+      },
+      {
+        title: i18next.t("device:Credential type"),
+        dataIndex: "credentialType",
+        key: "credentialType",
+        width: "120px",
+      },
+      {
+        title: i18next.t("device:Last seen time"),
+        dataIndex: "lastSeenTime",
+        key: "lastSeenTime",
+        width: "180px",
+        render: (text, record, index) => {
+          return text === "" ? null : Setting.getFormattedDate(text);
+        },
+      },
+      {
+        title: i18next.t("general:Action"),
+        dataIndex: "",
+        key: "op",
+        width: "300px",
+        render: (text, record, index) => {
+          const revoked = record.state === "Revoked";
+          return (
+            <div>
+              <Popconfirm title={i18next.t("device:Issue a new credential? The current one stops working")} disabled={revoked}
+                onConfirm={() => this.issueDeviceCredential(record, record.credentialType === "" ? "Secret" : record.credentialType)}>
+                <Button size="small" disabled={revoked}>{i18next.t("device:Issue credential")}</Button>
+              </Popconfirm>
+              <Popconfirm title={i18next.t("device:Revoke the device? All its tokens expire")} disabled={revoked} onConfirm={() => this.revokeDevice(record)}>
+                <Button style={{marginLeft: "5px"}} size="small" danger disabled={revoked}>{i18next.t("device:Revoke")}</Button>
+              </Popconfirm>
+              <Popconfirm title={`${i18next.t("general:Sure to delete")}: ${record.name} ?`} onConfirm={() => this.deleteDevice(record)}>
+                <Button style={{marginLeft: "5px"}} size="small" type="primary" danger>{i18next.t("general:Delete")}</Button>
+              </Popconfirm>
+            </div>
+          );
+        },
+      },
+    ];
+
+    return (
+      <React.Fragment>
+        <Table rowKey="name" columns={columns} dataSource={this.state.table} size="middle" bordered pagination={{pageSize: 10}}
+          title={() => (
+            <div>
+              {i18next.t("device:Devices")}&nbsp;&nbsp;&nbsp;&nbsp;
+              <Button type="primary" size="small" onClick={() => this.setState({registerVisible: true})}>{i18next.t("device:Register devices")}</Button>
+            </div>
+          )}
+        />
+        {this.renderRegisterModal()}
+      </React.Fragment>
+    );
+  }
+}
+
+export default DeviceTable;
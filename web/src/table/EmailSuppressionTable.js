@@ -0,0 +1,145 @@
+// Copyright 2022 The Casdoor Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+import React from "react";
+import {Button, Input, Table} from "antd";
+import i18next from "i18next";
+import * as EmailSuppressionBackend from "../backend/EmailSuppressionBackend";
+import * as Setting from "../Setting";
+
+class EmailSuppressionTable extends React.Component {
+  constructor(props) {
+    super(props);
+    this.state = {
+      table: [],
+      email: "",
+    };
+  }
+
+  componentDidMount() {
+    this.getEmailSuppressions();
+  }
+
+  getEmailSuppressions() {
+    EmailSuppressionBackend.getEmailSuppressions(this.props.owner)
+      .then((res) => {
+        if (res.status === "ok") {
+          this.setState({
+            table: res.data,
+          });
+        }
+      });
+  }
+
+  addEmailSuppression() {
+    EmailSuppressionBackend.addEmailSuppression({owner: this.props.owner, name: this.state.email, provider: this.props.provider})
+      .then((res) => {
+        if (res.status === "ok") {
+          Setting.showMessage("success", i18next.t("general:Successfully added"));
+          this.setState({
+            email: "",
+          });
+          this.getEmailSuppressions();
+        } else {
+          Setting.showMessage("error", `${i18next.t("general:Failed to add")}: ${res.msg}`);
+        }
+      })
+      .catch(error => {
+        Setting.showMessage("error", `${i18next.t("general:Failed to connect to server")}: ${error}`);
+      });
+  }
+
+  deleteEmailSuppression(i) {
+    EmailSuppressionBackend.deleteEmailSuppression(this.state.table[i])
+      .then((res) => {
+        if (res.status === "ok") {
+          Setting.showMessage("success", i18next.t("general:Successfully deleted"));
+          this.setState({
+            table: Setting.deleteRow(this.state.table, i),
+          });
+        } else {
+          Setting.showMessage("error", `${i18next.t("general:Failed to delete")}: ${res.msg}`);
+        }
+      })
+      .catch(error => {
+        Setting.showMessage("error", `${i18next.t("general:Failed to connect to server")}: ${error}`);
+      });
+  }
+
+  render() {
+    const columns = [
+      {
+        title: i18next.t("general:Email"),
+        dataIndex: "name",
+        key: "name",
+      },
+      {
+        title: i18next.t("provider:Type"),
+        dataIndex: "type",
+        key: "type",
+        width: "120px",
+      },
+      {
+        title: i18next.t("general:Provider"),
+        dataIndex: "provider",
+        key: "provider",
+        width: "150px",
+      },
+      {
+        title: i18next.t("provider:Reason"),
+        dataIndex: "reason",
+        key: "reason",
+      },
+      {
+        title: i18next.t("general:Created time"),
+        dataIndex: "createdTime",
+        key: "createdTime",
+        width: "180px",
+        render: (text, record, index) => {
+          return Setting.getFormattedDate(text);
+        },
+      },
+      {
+        title: i18next.t("general:Action"),
+        key: "action",
+        width: "100px",
+        render: (text, record, index) => {
+          return (
+            <Button style={{marginTop: "5px", marginBottom: "5px", marginRight: "5px"}} type="primary" danger onClick={() => {this.deleteEmailSuppression(index);}}>
+              {i18next.t("general:Delete")}
+            </Button>
+          );
+        },
+      },
+    ];
+
+    return (
+      <Table rowKey="name" columns={columns} dataSource={this.state.table} size="middle" bordered pagination={{pageSize: 10}}
+        title={() => (
+          <div>
+            {i18next.t("provider:Suppression list")}&nbsp;&nbsp;&nbsp;&nbsp;
+            <Input style={{width: "300px", marginRight: "5px"}} size="small" value={this.state.email} placeholder={i18next.t("user:Input your email")} onChange={e => {
+              this.setState({email: e.target.value});
+            }} />
+            <Button disabled={!Setting.isValidEmail(this.state.email)} type="primary" size="small" onClick={() => {this.addEmailSuppression();}}>
+              {i18next.t("general:Add")}
+            </Button>
+          </div>
+        )}
+      />
+    );
+  }
+}
+
+export default EmailSuppressionTable;
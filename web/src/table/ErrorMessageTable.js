@@ -0,0 +1,152 @@
+// Copyright 2023 The Casdoor Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+import React from "react";
+import {DeleteOutlined} from "@ant-design/icons";
+import {Button, Col, Input, Row, Select, Table, Tooltip} from "antd";
+import * as Setting from "../Setting";
+import i18next from "i18next";
+
+class ErrorMessageTable extends React.Component {
+  constructor(props) {
+    super(props);
+    this.state = {
+      classes: props,
+    };
+  }
+
+  updateTable(table) {
+    this.props.onUpdateTable(table);
+  }
+
+  updateField(table, index, key, value) {
+    table[index][key] = value;
+    this.updateTable(table);
+  }
+
+  addRow(table) {
+    const row = {code: "", language: "", message: "", helpUrl: ""};
+    if (table === undefined || table === null) {
+      table = [];
+    }
+    table = Setting.addRow(table, row);
+    this.updateTable(table);
+  }
+
+  deleteRow(table, i) {
+    table = Setting.deleteRow(table, i);
+    this.updateTable(table);
+  }
+
+  getDefaultMessage(code) {
+    const errorCode = this.props.errorCodes.find(errorCode => errorCode.code === code);
+    return errorCode?.message ?? "";
+  }
+
+  renderTable(table) {
+    const columns = [
+      {
+        title: i18next.t("organization:Error code"),
+        dataIndex: "code",
+        key: "code",
+        width: "250px",
+        render: (text, record, index) => {
+          return (
+            <Select virtual={false} style={{width: "100%"}} value={text} onChange={value => {
+              this.updateField(table, index, "code", value);
+            }}
+            options={this.props.errorCodes.map(errorCode => Setting.getOption(errorCode.code, errorCode.code))} />
+          );
+        },
+      },
+      {
+        title: i18next.t("organization:Language"),
+        dataIndex: "language",
+        key: "language",
+        width: "150px",
+        render: (text, record, index) => {
+          return (
+            <Select virtual={false} style={{width: "100%"}} allowClear value={text === "" ? undefined : text} onChange={value => {
+              this.updateField(table, index, "language", value ?? "");
+            }}
+            options={Setting.Countries.map(item => Setting.getOption(item.label, item.key))} />
+          );
+        },
+      },
+      {
+        title: i18next.t("organization:Message"),
+        dataIndex: "message",
+        key: "message",
+        render: (text, record, index) => {
+          return (
+            <Input value={text} placeholder={this.getDefaultMessage(record.code)} onChange={e => {
+              this.updateField(table, index, "message", e.target.value);
+            }} />
+          );
+        },
+      },
+      {
+        title: i18next.t("organization:Help URL"),
+        dataIndex: "helpUrl",
+        key: "helpUrl",
+        render: (text, record, index) => {
+          return (
+            <Input value={text} placeholder={"https://"} onChange={e => {
+              this.updateField(table, index, "helpUrl", e.target.value);
+            }} />
+          );
+        },
+      },
+      {
+        title: i18next.t("general:Action"),
+        key: "action",
+        width: "80px",
+        render: (text, record, index) => {
+          return (
+            <Tooltip placement="topLeft" title={i18next.t("general:Delete")}>
+              <Button icon={<DeleteOutlined />} size="small" onClick={() => this.deleteRow(table, index)} />
+            </Tooltip>
+          );
+        },
+      },
+    ];
+
+    return (
+      <Table scroll={{x: "max-content"}} rowKey={(record, index) => index} columns={columns} dataSource={table} size="middle" bordered pagination={false}
+        title={() => (
+          <div>
+            {this.props.title}&nbsp;&nbsp;&nbsp;&nbsp;
+            <Button style={{marginRight: "5px"}} type="primary" size="small" onClick={() => this.addRow(table)}>{i18next.t("general:Add")}</Button>
+          </div>
+        )}
+      />
+    );
+  }
+
+  render() {
+    return (
+      <div>
+        <Row style={{marginTop: "20px"}} >
+          <Col span={24}>
+            {
+              this.renderTable(this.props.table)
+            }
+          </Col>
+        </Row>
+      </div>
+    );
+  }
+}
+
+export default ErrorMessageTable;
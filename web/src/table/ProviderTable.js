@@ -110,7 +110,7 @@ class ProviderTable extends React.Component {
         key: "canSignUp",
         width: "120px",
         render: (text, record, index) => {
-          if (record.provider?.category !== "OAuth") {
+          if (record.provider?.category !== "OAuth" && record.provider?.category !== "Identity Verification") {
             return null;
           }
 
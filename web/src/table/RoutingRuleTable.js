@@ -0,0 +1,196 @@
+// Copyright 2023 The Casdoor Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+import React from "react";
+import {DeleteOutlined, DownOutlined, UpOutlined} from "@ant-design/icons";
+import {Button, Col, Input, Row, Select, Switch, Table, Tooltip} from "antd";
+import * as Setting from "../Setting";
+import i18next from "i18next";
+
+class RoutingRuleTable extends React.Component {
+  constructor(props) {
+    super(props);
+    this.state = {
+      classes: props,
+    };
+  }
+
+  updateTable(table) {
+    this.props.onUpdateTable(table);
+  }
+
+  updateField(table, index, key, value) {
+    table[index][key] = value;
+    this.updateTable(table);
+  }
+
+  addRow(table) {
+    const row = {name: `rule_${table?.length ?? 0}`, tags: [], roles: [], deviceTypes: [], isAdmin: false, url: ""};
+    if (table === undefined || table === null) {
+      table = [];
+    }
+    table = Setting.addRow(table, row);
+    this.updateTable(table);
+  }
+
+  deleteRow(table, i) {
+    table = Setting.deleteRow(table, i);
+    this.updateTable(table);
+  }
+
+  upRow(table, i) {
+    table = Setting.swapRow(table, i - 1, i);
+    this.updateTable(table);
+  }
+
+  downRow(table, i) {
+    table = Setting.swapRow(table, i, i + 1);
+    this.updateTable(table);
+  }
+
+  renderTable(table) {
+    const columns = [
+      {
+        title: i18next.t("general:Name"),
+        dataIndex: "name",
+        key: "name",
+        width: "150px",
+        render: (text, record, index) => {
+          return (
+            <Input value={text} onChange={e => {
+              this.updateField(table, index, "name", e.target.value);
+            }} />
+          );
+        },
+      },
+      {
+        title: i18next.t("organization:Tags"),
+        dataIndex: "tags",
+        key: "tags",
+        width: "200px",
+        render: (text, record, index) => {
+          return (
+            <Select virtual={false} mode="tags" style={{width: "100%"}} value={text ?? []} onChange={value => {
+              this.updateField(table, index, "tags", value);
+            }}
+            options={(this.props.tags ?? []).map(tag => Setting.getOption(tag, tag))} />
+          );
+        },
+      },
+      {
+        title: i18next.t("general:Roles"),
+        dataIndex: "roles",
+        key: "roles",
+        width: "200px",
+        render: (text, record, index) => {
+          return (
+            <Select virtual={false} mode="tags" style={{width: "100%"}} value={text ?? []} onChange={value => {
+              this.updateField(table, index, "roles", value);
+            }} />
+          );
+        },
+      },
+      {
+        title: i18next.t("organization:Device types"),
+        dataIndex: "deviceTypes",
+        key: "deviceTypes",
+        width: "200px",
+        render: (text, record, index) => {
+          return (
+            <Select virtual={false} mode="multiple" style={{width: "100%"}} value={text ?? []} onChange={value => {
+              this.updateField(table, index, "deviceTypes", value);
+            }}
+            options={[
+              Setting.getOption(i18next.t("organization:Desktop"), "Desktop"),
+              Setting.getOption(i18next.t("organization:Mobile"), "Mobile"),
+              Setting.getOption(i18next.t("organization:Tablet"), "Tablet"),
+            ]} />
+          );
+        },
+      },
+      {
+        title: i18next.t("user:Is admin"),
+        dataIndex: "isAdmin",
+        key: "isAdmin",
+        width: "100px",
+        render: (text, record, index) => {
+          return (
+            <Switch checked={text} onChange={checked => {
+              this.updateField(table, index, "isAdmin", checked);
+            }} />
+          );
+        },
+      },
+      {
+        title: i18next.t("organization:Landing URL"),
+        dataIndex: "url",
+        key: "url",
+        render: (text, record, index) => {
+          return (
+            <Input value={text} placeholder={"https://"} onChange={e => {
+              this.updateField(table, index, "url", e.target.value);
+            }} />
+          );
+        },
+      },
+      {
+        title: i18next.t("general:Action"),
+        key: "action",
+        width: "110px",
+        render: (text, record, index) => {
+          return (
+            <div>
+              <Tooltip placement="bottomLeft" title={i18next.t("general:Up")}>
+                <Button style={{marginRight: "5px"}} disabled={index === 0} icon={<UpOutlined />} size="small" onClick={() => this.upRow(table, index)} />
+              </Tooltip>
+              <Tooltip placement="topLeft" title={i18next.t("general:Down")}>
+                <Button style={{marginRight: "5px"}} disabled={index === table.length - 1} icon={<DownOutlined />} size="small" onClick={() => this.downRow(table, index)} />
+              </Tooltip>
+              <Tooltip placement="topLeft" title={i18next.t("general:Delete")}>
+                <Button icon={<DeleteOutlined />} size="small" onClick={() => this.deleteRow(table, index)} />
+              </Tooltip>
+            </div>
+          );
+        },
+      },
+    ];
+
+    return (
+      <Table scroll={{x: "max-content"}} rowKey={(record, index) => index} columns={columns} dataSource={table} size="middle" bordered pagination={false}
+        title={() => (
+          <div>
+            {this.props.title}&nbsp;&nbsp;&nbsp;&nbsp;
+            <Button style={{marginRight: "5px"}} type="primary" size="small" onClick={() => this.addRow(table)}>{i18next.t("general:Add")}</Button>
+          </div>
+        )}
+      />
+    );
+  }
+
+  render() {
+    return (
+      <div>
+        <Row style={{marginTop: "20px"}} >
+          <Col span={24}>
+            {
+              this.renderTable(this.props.table)
+            }
+          </Col>
+        </Row>
+      </div>
+    );
+  }
+}
+
+export default RoutingRuleTable;
@@ -0,0 +1,105 @@
+// Copyright 2022 The Casdoor Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+import React from "react";
+import {Table, Tag} from "antd";
+import i18next from "i18next";
+import * as SshCertificateBackend from "../backend/SshCertificateBackend";
+import * as Setting from "../Setting";
+
+class SshCertificateTable extends React.Component {
+  constructor(props) {
+    super(props);
+    this.state = {
+      table: [],
+    };
+  }
+
+  componentDidMount() {
+    this.getSshCertificates();
+  }
+
+  getSshCertificates() {
+    SshCertificateBackend.getSshCertificates(this.props.owner)
+      .then((res) => {
+        if (res.status === "ok") {
+          this.setState({
+            table: res.data,
+          });
+        }
+      });
+  }
+
+  render() {
+    const columns = [
+      {
+        title: i18next.t("general:User"),
+        dataIndex: "user",
+        key: "user",
+        width: "150px",
+      },
+      {
+        title: i18next.t("cert:Principals"),
+        dataIndex: "principals",
+        key: "principals",
+        render: (text, record, index) => {
+          return (text ?? []).map((principal, i) => <Tag key={i}>{principal}</Tag>);
+        },
+      },
+      {
+        title: i18next.t("cert:Serial"),
+        dataIndex: "name",
+        key: "name",
+        width: "200px",
+      },
+      {
+        title: i18next.t("cert:Fingerprint"),
+        dataIndex: "fingerprint",
+        key: "fingerprint",
+      },
+      {
+        title: i18next.t("cert:Valid before"),
+        dataIndex: "validBefore",
+        key: "validBefore",
+        width: "180px",
+        render: (text, record, index) => {
+          return Setting.getFormattedDate(text);
+        },
+      },
+      {
+        title: i18next.t("general:Client IP"),
+        dataIndex: "clientIp",
+        key: "clientIp",
+        width: "140px",
+      },
+      {
+        title: i18next.t("general:Created time"),
+        dataIndex: "createdTime",
+        key: "createdTime",
+        width: "180px",
+        render: (text, record, index) => {
+          return Setting.getFormattedDate(text);
+        },
+      },
+    ];
+
+    return (
+      <Table rowKey="name" columns={columns} dataSource={this.state.table} size="middle" bordered pagination={{pageSize: 10}}
+        title={() => i18next.t("cert:Issued SSH certificates")}
+      />
+    );
+  }
+}
+
+export default SshCertificateTable;